@@ -2,10 +2,12 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"syscall"
@@ -14,6 +16,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
@@ -30,28 +34,45 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
 	"github.com/bookingcom/shipper/pkg/client"
 	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shipperscheme "github.com/bookingcom/shipper/pkg/client/clientset/versioned/scheme"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
 	"github.com/bookingcom/shipper/pkg/controller/capacity"
+	"github.com/bookingcom/shipper/pkg/controller/chartrepository"
 	"github.com/bookingcom/shipper/pkg/controller/installation"
+	pullcontroller "github.com/bookingcom/shipper/pkg/controller/pull"
 	"github.com/bookingcom/shipper/pkg/controller/traffic"
+	"github.com/bookingcom/shipper/pkg/crds"
+	"github.com/bookingcom/shipper/pkg/debug"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
 	"github.com/bookingcom/shipper/pkg/metrics/instrumentedclient"
 	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 	statemetrics "github.com/bookingcom/shipper/pkg/metrics/state"
+	"github.com/bookingcom/shipper/pkg/pull"
+	"github.com/bookingcom/shipper/pkg/registry"
+	"github.com/bookingcom/shipper/pkg/tracing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 var controllers = []string{
 	"installation",
 	"capacity",
 	"traffic",
+	"pull",
+	"chartrepository",
 }
 
 const defaultRESTTimeout time.Duration = 10 * time.Second
 const defaultResync time.Duration = 0 * time.Second
 
+// imageVerificationTTL is how long images stay trusted after their
+// signatures were verified, before they're looked up in their registry again.
+const imageVerificationTTL time.Duration = 10 * time.Minute
+
 var (
 	masterURL           = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	kubeconfig          = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
@@ -61,8 +82,34 @@ var (
 	workers             = flag.Int("workers", 2, "Number of workers to start for each controller.")
 	metricsAddr         = flag.String("metrics-addr", ":8889", "Addr to expose /metrics on.")
 	chartCacheDir       = flag.String("cachedir", filepath.Join(os.TempDir(), "chart-cache"), "location for the local cache of downloaded charts")
+	chartRepoRequests   = flag.Int("chart-repo-max-requests", repo.DefaultConcurrentRequests, "How many requests can be made to chart repositories at once. Unlimited if 0.")
 	resync              = flag.Duration("resync", defaultResync, "Informer's cache re-sync in Go's duration format.")
 	restTimeout         = flag.Duration("rest-timeout", defaultRESTTimeout, "Timeout value for management and target REST clients. Does not affect informer watches.")
+	externalLBURL       = flag.String("external-lb-url", "", "URL of an external load balancer adapter to publish release weights to. Disabled if empty.")
+	knativeTraffic      = flag.Bool("knative-traffic", false, "Enable the knative traffic backend, which shifts traffic by programming the traffic block of Knative Services.")
+	clusterName         = flag.String("cluster-name", "", "Name of the application cluster, as known by the management cluster. Used to identify this cluster to external systems, and in the deprecated .status.clusters of CapacityTargets.")
+	debugAddr           = flag.String("debug-addr", "", "Addr to expose pprof and the /debug endpoints on. Disabled if empty.")
+	shutdownTimeout     = flag.Duration("shutdown-timeout", shutdown.DefaultDrainTimeout, "How long controllers wait for in-flight syncs to finish when shutting down.")
+	managedOnly         = flag.Bool("managed-only", false, "Only watch workload objects (Deployments, Pods, Services, Endpoints) labelled as managed by Shipper.")
+	watchNamespace      = flag.String("watch-namespace", metav1.NamespaceAll, "Only watch workload objects in this namespace. Watches all namespaces if empty.")
+	prePullerPauseImage = flag.String("prepull-pause-image", installation.DefaultPrePullerPauseImage, "Image run by the pods pre-pulling a release's images once they're done pulling.")
+	chartHookTimeout    = flag.Duration("chart-hook-timeout", installation.DefaultChartHookTimeout, "How long Jobs annotated as Helm hooks in charts get to complete before they're considered failed. Never times out if 0.")
+	fullResyncPeriod    = flag.Duration("installation-full-resync-period", 0, "How long InstallationTargets that are ready and healthy can go without their objects being installed again, as long as neither they nor their Deployments and Services change. Objects are installed on every sync if 0.")
+	vaultAddr           = flag.String("vault-addr", "", "Address of a Vault server to resolve chart values from, with the token in $VAULT_TOKEN. Disabled if empty.")
+	vaultPathPrefix     = flag.String("vault-path-prefix", valuesource.DefaultVaultPathPrefix, "Path in Vault that chart values are read from. {namespace} is replaced by the release's namespace.")
+	installCRDs         = flag.Bool("install-crds", false, "Create or update Shipper's CRDs on startup, so their schemas match this version of Shipper.")
+	eventVerbosity      = flag.String("event-verbosity", string(shipperevents.VerbosityAll), "Which events controllers emit: all, warnings or none.")
+	eventDedupInterval  = flag.Duration("event-dedup-interval", shipperevents.DefaultDedupInterval, "How long identical events for the same object are dropped for after the first one. Disabled if 0.")
+	eventQPS            = flag.Float64("event-qps", shipperevents.DefaultQPS, "How many events per second can be written for each object once its burst is used up.")
+	eventBurst          = flag.Int("event-burst", shipperevents.DefaultBurst, "How many events can be written for each object before -event-qps kicks in.")
+	trafficDrainPeriod  = flag.Duration("traffic-drain-period", 0, "How long TrafficTargets wait for in-flight requests to drain from pods they take out of traffic before reporting Ready. Disabled if 0.")
+	allowedRegistries   = flag.String("allowed-registries", "", "Comma-separated list of registries, or repository prefixes such as gcr.io/my-project, that images in charts must come from. Images in Docker Hub are prefixed by docker.io. Any registry is allowed if empty.")
+	cosignPublicKey     = flag.String("cosign-public-key", "", "Path to a PEM encoded ECDSA public key that every image in a chart must have a cosign signature from before anything is installed. Disabled if empty.")
+	isolateContenders   = flag.Bool("isolate-contenders", false, "Create a NetworkPolicy for releases with no traffic weight, so their pods can only be reached from their own namespace.")
+	otlpEndpoint        = flag.String("otlp-endpoint", "", "URL of an OpenTelemetry collector to export traces of controller syncs to over OTLP/HTTP, such as http://otel-collector:4318. Tracing is disabled if empty.")
+	tracingSampleRatio  = flag.Float64("tracing-sample-ratio", 1, "Fraction of controller syncs to trace, between 0 and 1. Only used with -otlp-endpoint.")
+	mgmtKubeconfig      = flag.String("management-kubeconfig", "", "Path to a kubeconfig for the management cluster, to pull the targets for this cluster from when it's in pull mode. Requires -cluster-name. The pull controller is skipped if empty.")
+	lowPriorityMaxWait  = flag.Duration("low-priority-max-wait", shipperworkqueue.DefaultLowPriorityMaxWait, "How long objects that are not rolling out wait for in-flight ones to be synced at most.")
 )
 
 type metricsCfg struct {
@@ -80,20 +127,41 @@ type cfg struct {
 	restCfg     *rest.Config
 	restTimeout *time.Duration
 
-	kubeInformerFactory    informers.SharedInformerFactory
-	shipperInformerFactory shipperinformers.SharedInformerFactory
-	resync                 *time.Duration
+	kubeInformerFactory     informers.SharedInformerFactory
+	workloadInformerFactory informers.SharedInformerFactory
+	appsGroupVersion        schema.GroupVersion
+	shipperInformerFactory  shipperinformers.SharedInformerFactory
+	resync                  *time.Duration
+
+	// Only set for clusters in pull mode, see -management-kubeconfig.
+	mgmtShipperClient   shipperclientset.Interface
+	mgmtInformerFactory shipperinformers.SharedInformerFactory
 
 	recorder func(string) record.EventRecorder
 
 	store *clusterclientstore.Store
 
+	repoCatalog          *repo.Catalog
 	chartVersionResolver repo.ChartVersionResolver
 	chartFetcher         repo.ChartFetcher
 
-	certPath, keyPath string
-	ns                string
-	workers           int
+	certPath, keyPath  string
+	clusterName        string
+	ns                 string
+	workers            int
+	drainTimeout       time.Duration
+	lowPriorityMaxWait time.Duration
+
+	externalLB         traffic.ExternalLoadBalancer
+	knativeClient      dynamic.Interface
+	trafficDrainPeriod time.Duration
+	isolateContenders  bool
+
+	prePullerPauseImage string
+	chartHookTimeout    time.Duration
+	fullResyncPeriod    time.Duration
+	allowedRegistries   []string
+	imageVerifier       registry.ImageVerifier
 
 	wg     *sync.WaitGroup
 	stopCh <-chan struct{}
@@ -105,11 +173,41 @@ func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
+	var imageRegistries []string
+	if *allowedRegistries != "" {
+		imageRegistries = strings.Split(*allowedRegistries, ",")
+	}
+
+	var imageVerifier registry.ImageVerifier
+	if *cosignPublicKey != "" {
+		key, err := ioutil.ReadFile(*cosignPublicKey)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		imageVerifier, err = registry.NewCosignVerifier(key, *restTimeout, imageVerificationTTL)
+		if err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	restCfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
 	if err != nil {
 		klog.Fatal(err)
 	}
 
+	if *installCRDs {
+		klog.V(1).Info("Creating or updating CRDs")
+		apiextensionClient, err := apiextensionclientset.NewForConfig(restCfg)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		if err := crds.Install(apiextensionClient, crds.ApplicationClusterCRDs); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	// These are only used in shared informers. Setting HTTP timeout here would
 	// affect watches which is undesirable. Instead, we leave it to client-go (see
 	// k8s.io/client-go/tools/cache) to govern watch durations.
@@ -119,19 +217,67 @@ func main() {
 	stopCh := setupSignalHandler()
 	metricsReadyCh := make(chan struct{})
 
+	// Workloads are managed through the version of the apps API group
+	// the cluster serves, for clusters that predate apps/v1.
+	appsGroupVersion, err := client.ServedAppsGroupVersion(informerKubeClient.Discovery())
+	if err != nil {
+		klog.Fatal(err)
+	}
+	klog.V(1).Infof("Managing workloads through %s", appsGroupVersion)
+
 	kubeInformerFactory := informers.NewSharedInformerFactory(informerKubeClient, 0*time.Second)
-	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(informerShipperClient, *resync)
+	workloadInformerFactory := newWorkloadInformerFactory(informerKubeClient, *managedOnly, *watchNamespace, appsGroupVersion)
+	// The copies of targets that are kept for clusters in pull mode are
+	// left to the agents in those clusters, even when this cluster is the
+	// management cluster.
+	shipperInformerFactory := shipperinformers.NewSharedInformerFactoryWithOptions(
+		informerShipperClient, *resync,
+		shipperinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = "!" + shipper.PullClusterLabel
+		}),
+	)
+
+	var mgmtShipperClient shipperclientset.Interface
+	var mgmtInformerFactory shipperinformers.SharedInformerFactory
+	if *mgmtKubeconfig != "" {
+		if *clusterName == "" {
+			klog.Fatal("-management-kubeconfig requires -cluster-name")
+		}
+
+		klog.V(1).Infof("Pulling targets for cluster %q from the management cluster", *clusterName)
+		mgmtRestCfg, err := clientcmd.BuildConfigFromFlags("", *mgmtKubeconfig)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		informerMgmtClient := client.NewShipperClientOrDie("shipper-shared-informer", mgmtRestCfg)
+		mgmtInformerFactory = shipperinformers.NewSharedInformerFactory(
+			pull.NewClientset(informerMgmtClient, *clusterName), *resync)
+
+		mgmtRestCfg.Timeout = *restTimeout
+		mgmtShipperClient = pull.NewClientset(
+			client.NewShipperClientOrDie(pullcontroller.AgentName, mgmtRestCfg), *clusterName)
+	}
 
 	shipperscheme.AddToScheme(scheme.Scheme)
 
 	kubeClient := client.NewKubeClientOrDie("event-broadcaster", restCfg)
-	broadcaster := record.NewBroadcaster()
+	verbosity, err := shipperevents.ParseVerbosity(*eventVerbosity)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	broadcaster := shipperevents.NewBroadcaster(float32(*eventQPS), *eventBurst)
 	broadcaster.StartLogging(klog.Infof)
 	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
 		Interface: kubeClient.CoreV1().Events("")})
 
 	recorder := func(component string) record.EventRecorder {
-		return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+		return shipperevents.NewRecorder(
+			broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component}),
+			verbosity,
+			*eventDedupInterval,
+		)
 	}
 
 	enabledControllers := buildEnabledControllers(*enabledControllers, *disabledControllers)
@@ -164,11 +310,20 @@ func main() {
 	klog.V(1).Infof("Chart cache stored at %q", *chartCacheDir)
 	klog.V(1).Infof("REST client timeout is %s", *restTimeout)
 
+	if *vaultAddr != "" {
+		klog.V(1).Infof("Resolving chart values from Vault at %q", *vaultAddr)
+		valuesource.RegisterStore(
+			valuesource.VaultStoreName,
+			valuesource.NewVaultStore(*vaultAddr, os.Getenv("VAULT_TOKEN"), *vaultPathPrefix),
+		)
+	}
+
 	repoCatalog := repo.NewCatalog(
 		repo.DefaultFileCacheFactory(*chartCacheDir),
 		repo.DefaultRemoteFetcher,
 		stopCh,
 	)
+	repoCatalog.LimitConcurrentRequests(*chartRepoRequests)
 
 	ssm := statemetrics.AppMetrics{
 		ItsLister: shipperInformerFactory.Shipper().V1alpha1().InstallationTargets().Lister(),
@@ -176,6 +331,8 @@ func main() {
 		TtsLister: shipperInformerFactory.Shipper().V1alpha1().TrafficTargets().Lister(),
 
 		NssLister: kubeInformerFactory.Core().V1().Namespaces().Lister(),
+
+		ClusterName: *clusterName,
 	}
 
 	controllerRestCfg := rest.CopyConfig(restCfg)
@@ -183,24 +340,59 @@ func main() {
 		controllerRestCfg.Timeout = *restTimeout
 	}
 
+	var externalLB traffic.ExternalLoadBalancer
+	if *externalLBURL != "" {
+		klog.V(1).Infof("Publishing release weights to external load balancer at %q", *externalLBURL)
+		externalLB = traffic.NewHTTPExternalLoadBalancer(*externalLBURL, *clusterName, *restTimeout)
+	}
+
+	var knativeClient dynamic.Interface
+	if *knativeTraffic {
+		klog.V(1).Info("Shifting traffic through Knative Services for clusters using the knative backend")
+		knativeClient, err = dynamic.NewForConfig(controllerRestCfg)
+		if err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	cfg := &cfg{
 		enabledControllers: enabledControllers,
 		restCfg:            controllerRestCfg,
 		restTimeout:        restTimeout,
 
-		kubeInformerFactory:    kubeInformerFactory,
-		shipperInformerFactory: shipperInformerFactory,
-		resync:                 resync,
+		kubeInformerFactory:     kubeInformerFactory,
+		workloadInformerFactory: workloadInformerFactory,
+		appsGroupVersion:        appsGroupVersion,
+		shipperInformerFactory:  shipperInformerFactory,
+		resync:                  resync,
+
+		mgmtShipperClient:   mgmtShipperClient,
+		mgmtInformerFactory: mgmtInformerFactory,
 
 		recorder: recorder,
 
 		store: store,
 
+		repoCatalog:          repoCatalog,
 		chartVersionResolver: repo.ResolveChartVersionFunc(repoCatalog),
 		chartFetcher:         repo.FetchChartFunc(repoCatalog),
 
-		ns:      *ns,
-		workers: *workers,
+		clusterName:        *clusterName,
+		ns:                 *ns,
+		workers:            *workers,
+		drainTimeout:       *shutdownTimeout,
+		lowPriorityMaxWait: *lowPriorityMaxWait,
+
+		externalLB:         externalLB,
+		knativeClient:      knativeClient,
+		trafficDrainPeriod: *trafficDrainPeriod,
+		isolateContenders:  *isolateContenders,
+
+		prePullerPauseImage: *prePullerPauseImage,
+		chartHookTimeout:    *chartHookTimeout,
+		fullResyncPeriod:    *fullResyncPeriod,
+		allowedRegistries:   imageRegistries,
+		imageVerifier:       imageVerifier,
 
 		wg:     wg,
 		stopCh: stopCh,
@@ -214,6 +406,15 @@ func main() {
 		},
 	}
 
+	if *debugAddr != "" {
+		go func() {
+			klog.V(1).Infof("Debug endpoints will listen on %s", *debugAddr)
+			if err := http.ListenAndServe(*debugAddr, debug.NewHandler()); err != nil {
+				klog.Errorf("could not start the debug endpoints: %s", err)
+			}
+		}()
+	}
+
 	go func() {
 		klog.V(1).Infof("Metrics will listen on %s", *metricsAddr)
 		<-metricsReadyCh
@@ -224,7 +425,17 @@ func main() {
 		runMetrics(cfg.metrics)
 	}()
 
+	if *otlpEndpoint != "" {
+		klog.V(1).Infof("Exporting traces to %q", *otlpEndpoint)
+		exporter := tracing.NewOTLPExporter(*otlpEndpoint, "shipper-app", *restTimeout,
+			tracing.String("shipper.cluster", *clusterName))
+		tracer := tracing.NewTracer(exporter, *tracingSampleRatio)
+		tracing.SetTracer(tracer)
+		go tracer.Run()
+	}
+
 	runControllers(cfg)
+	tracing.Flush()
 }
 
 type klogStdLogger struct{}
@@ -240,6 +451,22 @@ func runMetrics(cfg *metricsCfg) {
 	prometheus.MustRegister(cfg.restLatency.Summary, cfg.restResult.Counter)
 	prometheus.MustRegister(instrumentedclient.GetMetrics()...)
 	prometheus.MustRegister(cfg.stateMetrics)
+	prometheus.MustRegister(shippermetrics.SyncErrors)
+	prometheus.MustRegister(
+		shippermetrics.TrafficConvergenceDuration,
+		shippermetrics.CapacityConvergenceDuration,
+	)
+	prometheus.MustRegister(
+		shippermetrics.CacheSyncDuration,
+		shippermetrics.InformerRelists,
+		shippermetrics.InformerWatchErrors,
+	)
+	prometheus.MustRegister(shippermetrics.TracingSpansDropped)
+	prometheus.MustRegister(
+		shippermetrics.ChartCacheLookups,
+		shippermetrics.ChartRepoRequestsInFlight,
+		shippermetrics.ChartRepoRequestsWaiting,
+	)
 
 	srv := http.Server{
 		Addr: *metricsAddr,
@@ -317,8 +544,25 @@ func runControllers(cfg *cfg) {
 	// metrics now.
 	close(cfg.metrics.readyCh)
 
-	go cfg.kubeInformerFactory.Start(cfg.stopCh)
-	go cfg.shipperInformerFactory.Start(cfg.stopCh)
+	informersStarted := time.Now()
+	cfg.kubeInformerFactory.Start(cfg.stopCh)
+	cfg.workloadInformerFactory.Start(cfg.stopCh)
+	cfg.shipperInformerFactory.Start(cfg.stopCh)
+	if cfg.mgmtInformerFactory != nil {
+		cfg.mgmtInformerFactory.Start(cfg.stopCh)
+	}
+
+	go func() {
+		if allSynced(cfg.kubeInformerFactory.WaitForCacheSync(cfg.stopCh)) &&
+			allSynced(cfg.workloadInformerFactory.WaitForCacheSync(cfg.stopCh)) {
+			shippermetrics.ObserveCacheSync(*clusterName, "kubernetes", informersStarted)
+		}
+	}()
+	go func() {
+		if allSynced(cfg.shipperInformerFactory.WaitForCacheSync(cfg.stopCh)) {
+			shippermetrics.ObserveCacheSync(*clusterName, "shipper", informersStarted)
+		}
+	}()
 
 	doneCh := make(chan struct{})
 
@@ -331,6 +575,42 @@ func runControllers(cfg *cfg) {
 	klog.Info("Controllers have shut down")
 }
 
+// newWorkloadInformerFactory builds the informer factory for the workload
+// objects the controllers act on. These are the bulk of the objects in an
+// application cluster, so they can be narrowed down to the ones Shipper
+// manages, or to a single namespace, to keep memory usage in check. Other
+// objects, like Namespaces and Secrets, are watched through an unfiltered
+// factory.
+func newWorkloadInformerFactory(
+	kubeClient kubernetes.Interface,
+	managedOnly bool,
+	namespace string,
+	appsGroupVersion schema.GroupVersion,
+) informers.SharedInformerFactory {
+	var tweakListOptions func(*metav1.ListOptions)
+	if managedOnly {
+		klog.V(1).Infof("Only watching workload objects labelled with %q", shipper.AppLabel)
+		tweakListOptions = func(opts *metav1.ListOptions) {
+			opts.LabelSelector = shipper.AppLabel
+		}
+	}
+
+	if namespace != metav1.NamespaceAll {
+		klog.V(1).Infof("Only watching workload objects in namespace %q", namespace)
+	}
+
+	return client.NewWorkloadInformerFactory(kubeClient, 0*time.Second, namespace, tweakListOptions, appsGroupVersion)
+}
+
+func allSynced(synced map[reflect.Type]bool) bool {
+	for _, ok := range synced {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func setupSignalHandler() <-chan struct{} {
 	stopCh := make(chan struct{})
 
@@ -354,6 +634,8 @@ func buildInitializers() map[string]initFunc {
 	controllers["installation"] = startInstallationController
 	controllers["capacity"] = startCapacityController
 	controllers["traffic"] = startTrafficController
+	controllers["pull"] = startPullController
+	controllers["chartrepository"] = startChartRepositoryController
 	return controllers
 }
 
@@ -369,12 +651,22 @@ func startInstallationController(cfg *cfg) (bool, error) {
 
 	c := installation.NewController(
 		client.NewKubeClientOrDie(installation.AgentName, cfg.restCfg),
-		cfg.kubeInformerFactory,
+		cfg.workloadInformerFactory,
 		client.NewShipperClientOrDie(installation.AgentName, cfg.restCfg),
 		cfg.shipperInformerFactory,
 		dynamicClientBuilderFunc,
 		cfg.chartFetcher,
 		cfg.recorder(installation.AgentName),
+		installation.Options{
+			DrainTimeout:        cfg.drainTimeout,
+			PrePullerPauseImage: cfg.prePullerPauseImage,
+			ChartHookTimeout:    cfg.chartHookTimeout,
+			FullResyncPeriod:    cfg.fullResyncPeriod,
+			LowPriorityMaxWait:  cfg.lowPriorityMaxWait,
+			AllowedRegistries:   cfg.allowedRegistries,
+			ImageVerifier:       cfg.imageVerifier,
+			AppsGroupVersion:    cfg.appsGroupVersion,
+		},
 	)
 
 	cfg.wg.Add(1)
@@ -394,10 +686,17 @@ func startCapacityController(cfg *cfg) (bool, error) {
 
 	c := capacity.NewController(
 		client.NewKubeClientOrDie(capacity.AgentName, cfg.restCfg),
-		cfg.kubeInformerFactory,
+		cfg.workloadInformerFactory,
 		client.NewShipperClientOrDie(capacity.AgentName, cfg.restCfg),
 		cfg.shipperInformerFactory,
 		cfg.recorder(capacity.AgentName),
+		capacity.Options{
+			DrainTimeout:                cfg.drainTimeout,
+			DeprecatedStatusClusterName: cfg.clusterName,
+			ClusterName:                 cfg.clusterName,
+			LowPriorityMaxWait:          cfg.lowPriorityMaxWait,
+			AppsGroupVersion:            cfg.appsGroupVersion,
+		},
 	)
 
 	cfg.wg.Add(1)
@@ -417,10 +716,66 @@ func startTrafficController(cfg *cfg) (bool, error) {
 
 	c := traffic.NewController(
 		client.NewKubeClientOrDie(traffic.AgentName, cfg.restCfg),
-		cfg.kubeInformerFactory,
+		cfg.workloadInformerFactory,
 		client.NewShipperClientOrDie(traffic.AgentName, cfg.restCfg),
 		cfg.shipperInformerFactory,
 		cfg.recorder(traffic.AgentName),
+		traffic.Options{
+			ExternalLB:         cfg.externalLB,
+			KnativeClient:      cfg.knativeClient,
+			DrainTimeout:       cfg.drainTimeout,
+			DrainPeriod:        cfg.trafficDrainPeriod,
+			IsolateContenders:  cfg.isolateContenders,
+			ClusterName:        cfg.clusterName,
+			LowPriorityMaxWait: cfg.lowPriorityMaxWait,
+		},
+	)
+
+	cfg.wg.Add(1)
+	go func() {
+		c.Run(cfg.workers, cfg.stopCh)
+		cfg.wg.Done()
+	}()
+
+	return true, nil
+}
+
+func startPullController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["pull"] && cfg.mgmtInformerFactory != nil
+	if !enabled {
+		return false, nil
+	}
+
+	c := pullcontroller.NewController(
+		client.NewShipperClientOrDie(pullcontroller.AgentName, cfg.restCfg),
+		cfg.shipperInformerFactory,
+		cfg.mgmtShipperClient,
+		cfg.mgmtInformerFactory,
+		cfg.drainTimeout,
+	)
+
+	cfg.wg.Add(1)
+	go func() {
+		c.Run(cfg.workers, cfg.stopCh)
+		cfg.wg.Done()
+	}()
+
+	return true, nil
+}
+
+func startChartRepositoryController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["chartrepository"]
+	if !enabled {
+		return false, nil
+	}
+
+	c := chartrepository.NewController(
+		cfg.shipperInformerFactory,
+		cfg.kubeInformerFactory,
+		cfg.ns,
+		cfg.repoCatalog,
+		cfg.recorder(chartrepository.AgentName),
+		cfg.drainTimeout,
 	)
 
 	cfg.wg.Add(1)
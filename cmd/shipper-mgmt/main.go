@@ -2,10 +2,12 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,6 +17,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/client-go/informers"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -29,23 +32,39 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+	"github.com/bookingcom/shipper/pkg/ciapi"
 	"github.com/bookingcom/shipper/pkg/client"
 	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shipperscheme "github.com/bookingcom/shipper/pkg/client/clientset/versioned/scheme"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
 	"github.com/bookingcom/shipper/pkg/controller/application"
+	"github.com/bookingcom/shipper/pkg/controller/chartrepository"
+	"github.com/bookingcom/shipper/pkg/controller/gitops"
 	"github.com/bookingcom/shipper/pkg/controller/janitor"
 	"github.com/bookingcom/shipper/pkg/controller/release"
 	"github.com/bookingcom/shipper/pkg/controller/rolloutblock"
+	"github.com/bookingcom/shipper/pkg/crds"
+	"github.com/bookingcom/shipper/pkg/debug"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
 	"github.com/bookingcom/shipper/pkg/metrics/instrumentedclient"
 	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 	statemetrics "github.com/bookingcom/shipper/pkg/metrics/state"
+	"github.com/bookingcom/shipper/pkg/policy"
+	"github.com/bookingcom/shipper/pkg/registry"
+	"github.com/bookingcom/shipper/pkg/shipperconfig"
+	"github.com/bookingcom/shipper/pkg/tracing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	"github.com/bookingcom/shipper/pkg/webhook"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 var controllers = []string{
 	"application",
+	"chartrepository",
+	"ciapi",
+	"gitops",
 	"janitor",
 	"release",
 	"rolloutblock",
@@ -64,6 +83,7 @@ var (
 	workers             = flag.Int("workers", 2, "Number of workers to start for each controller.")
 	metricsAddr         = flag.String("metrics-addr", ":8889", "Addr to expose /metrics on.")
 	chartCacheDir       = flag.String("cachedir", filepath.Join(os.TempDir(), "chart-cache"), "location for the local cache of downloaded charts")
+	chartRepoRequests   = flag.Int("chart-repo-max-requests", repo.DefaultConcurrentRequests, "How many requests can be made to chart repositories at once. Unlimited if 0.")
 	resync              = flag.Duration("resync", defaultResync, "Informer's cache re-sync in Go's duration format.")
 	restTimeout         = flag.Duration("rest-timeout", defaultRESTTimeout, "Timeout value for management and target REST clients. Does not affect informer watches.")
 	webhookCertPath     = flag.String("webhook-cert", "", "Path to the TLS certificate for the webhook controller.")
@@ -71,6 +91,37 @@ var (
 	webhookBindAddr     = flag.String("webhook-addr", "0.0.0.0", "Addr to bind the webhook controller.")
 	webhookBindPort     = flag.String("webhook-port", "9443", "Port to bind the webhook controller.")
 	relDurationBuckets  = flag.String("release-duration-buckets", "15,30,45,60,120", "Comma-separated list of buckets for the shipper_objects_release_durations histogram, in seconds")
+	gitopsRepo          = flag.String("gitops-repo", "", "URL of a git repository to sync Applications from. The gitops controller is disabled if empty.")
+	gitopsBranch        = flag.String("gitops-branch", "master", "Branch of the gitops repository to sync Applications from.")
+	gitopsPath          = flag.String("gitops-path", "", "Path inside the gitops repository where Application manifests live.")
+	gitopsCheckoutDir   = flag.String("gitops-checkout-dir", filepath.Join(os.TempDir(), "gitops"), "location for the local checkout of the gitops repository")
+	gitopsInterval      = flag.Duration("gitops-interval", time.Minute, "How often to sync Applications from the gitops repository.")
+	ciAPITokensFile     = flag.String("ci-api-tokens-file", "", "Path to a file with one bearer token per line accepted by the CI API, optionally followed by the namespaces or <namespace>/<application>s it's limited to. The CI API is disabled if empty.")
+	ciAPIAddr           = flag.String("ci-api-addr", ":8890", "Addr to expose the CI API on.")
+	ciAPICertPath       = flag.String("ci-api-cert", "", "Path to the TLS certificate for the CI API.")
+	ciAPIKeyPath        = flag.String("ci-api-key", "", "Path to the TLS private key for the CI API.")
+	ciAPIInsecure       = flag.Bool("ci-api-insecure", false, "Serve the CI API over plain HTTP, even though it accepts bearer tokens. Only meant for when TLS is terminated in front of it.")
+	ciAPIApprover       = flag.String("ci-api-approver", "", "User the CI API records approvals on behalf of, which must be the one shipper-mgmt authenticates as. Defaults to the shipper-mgmt-cluster service account in -namespace.")
+	lazyClusters        = flag.Bool("lazy-cluster-informers", false, "Only watch application clusters that Releases are scheduled on.")
+	clusterIdleGrace    = flag.Duration("cluster-idle-grace-period", 10*time.Minute, "How long to keep watching an application cluster after its last Release is gone. Only used with -lazy-cluster-informers.")
+	breakerFailures     = flag.Int("cluster-breaker-failures", 5, "How many calls in a row to an application cluster can fail before it stops being called for -cluster-breaker-cooldown. Disabled if 0.")
+	breakerCooldown     = flag.Duration("cluster-breaker-cooldown", time.Minute, "How long application clusters stop being called for after -cluster-breaker-failures calls in a row failed.")
+	debugAddr           = flag.String("debug-addr", "", "Addr to expose pprof and the /debug endpoints on. Disabled if empty.")
+	vaultAddr           = flag.String("vault-addr", "", "Address of a Vault server to resolve the chart values of step hooks from, with the token in $VAULT_TOKEN. Disabled if empty.")
+	vaultPathPrefix     = flag.String("vault-path-prefix", valuesource.DefaultVaultPathPrefix, "Path in Vault that chart values are read from. {namespace} is replaced by the release's namespace.")
+	shutdownTimeout     = flag.Duration("shutdown-timeout", shutdown.DefaultDrainTimeout, "How long controllers wait for in-flight syncs to finish when shutting down.")
+	namespaces          = flag.String("namespaces", "", "Comma-separated list of namespaces whose Applications and Releases this instance manages. All namespaces are managed if empty.")
+	installCRDs         = flag.Bool("install-crds", false, "Create or update Shipper's CRDs on startup, so their schemas match this version of Shipper.")
+	eventVerbosity      = flag.String("event-verbosity", string(shipperevents.VerbosityAll), "Which events controllers emit: all, warnings or none.")
+	eventDedupInterval  = flag.Duration("event-dedup-interval", shipperevents.DefaultDedupInterval, "How long identical events for the same object are dropped for after the first one. Disabled if 0.")
+	eventQPS            = flag.Float64("event-qps", shipperevents.DefaultQPS, "How many events per second can be written for each object once its burst is used up.")
+	eventBurst          = flag.Int("event-burst", shipperevents.DefaultBurst, "How many events can be written for each object before -event-qps kicks in.")
+	otlpEndpoint        = flag.String("otlp-endpoint", "", "URL of an OpenTelemetry collector to export traces of controller syncs to over OTLP/HTTP, such as http://otel-collector:4318. Tracing is disabled if empty.")
+	tracingSampleRatio  = flag.Float64("tracing-sample-ratio", 1, "Fraction of controller syncs to trace, between 0 and 1. Only used with -otlp-endpoint.")
+	imagePlatformCheck  = flag.Bool("image-platform-check", false, "Check that the images of Releases asking for platforms in their clusterRequirements have manifests for all of them in their registries before choosing clusters.")
+	opaURL              = flag.String("opa-url", "", "URL of an Open Policy Agent server to evaluate Policies with, such as http://opa:8181. Releases any Policy applies to are held back if empty.")
+	completionWebhook   = flag.String("completion-webhook-url", "", "URL to post releases that complete their rollout to. Disabled if empty.")
+	lowPriorityMaxWait  = flag.Duration("low-priority-max-wait", shipperworkqueue.DefaultLowPriorityMaxWait, "How long objects that are not rolling out wait for in-flight ones to be synced at most.")
 )
 
 type metricsCfg struct {
@@ -96,16 +147,34 @@ type cfg struct {
 
 	store *clusterclientstore.Store
 
+	repoCatalog          *repo.Catalog
 	chartVersionResolver repo.ChartVersionResolver
 	chartFetcher         repo.ChartFetcher
 
-	certPath, keyPath string
-	ns                string
-	workers           int
+	certPath, keyPath  string
+	ns                 string
+	workers            int
+	drainTimeout       time.Duration
+	lowPriorityMaxWait time.Duration
 
 	webhookCertPath, webhookKeyPath  string
 	webhookBindAddr, webhookBindPort string
 
+	gitopsRepo, gitopsBranch, gitopsPath string
+	gitopsCheckoutDir                    string
+	gitopsInterval                       time.Duration
+
+	ciAPITokensFile, ciAPIAddr  string
+	ciAPICertPath, ciAPIKeyPath string
+	ciAPIInsecure               bool
+	ciAPIApprover               string
+
+	imageInspector     registry.PlatformInspector
+	policyEvaluator    policy.Evaluator
+	completionNotifier release.CompletionNotifier
+
+	configStore *shipperconfig.Store
+
 	wg     *sync.WaitGroup
 	stopCh <-chan struct{}
 
@@ -121,6 +190,18 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	if *installCRDs {
+		klog.V(1).Info("Creating or updating CRDs")
+		apiextensionClient, err := apiextensionclientset.NewForConfig(restCfg)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		if err := crds.Install(apiextensionClient, crds.ManagementClusterCRDs); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
 	// These are only used in shared informers. Setting HTTP timeout here would
 	// affect watches which is undesirable. Instead, we leave it to client-go (see
 	// k8s.io/client-go/tools/cache) to govern watch durations.
@@ -131,19 +212,46 @@ func main() {
 	metricsReadyCh := make(chan struct{})
 
 	kubeInformerFactory := informers.NewSharedInformerFactory(informerKubeClient, 0*time.Second)
-	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(informerShipperClient, *resync)
+	shipperInformerFactory := client.NewNamespacedInformerFactory(informerShipperClient, *resync, buildNamespaces(*namespaces))
 
 	shipperscheme.AddToScheme(scheme.Scheme)
 
-	broadcaster := record.NewBroadcaster()
+	verbosity, err := shipperevents.ParseVerbosity(*eventVerbosity)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	broadcaster := shipperevents.NewBroadcaster(float32(*eventQPS), *eventBurst)
 	broadcaster.StartLogging(klog.Infof)
 	func() {
 		kubeClient := client.NewKubeClientOrDie("event-broadcaster", restCfg)
 		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	}()
 
+	// Flags are the defaults of whatever the ShipperConfig leaves out.
+	configStore := shipperconfig.NewStore(
+		shipperconfig.Config{
+			EventVerbosity:       verbosity,
+			EventDedupInterval:   *eventDedupInterval,
+			GitOpsInterval:       *gitopsInterval,
+			ChartRepoMaxRequests: *chartRepoRequests,
+			CompletionWebhookURL: *completionWebhook,
+		},
+		shipperInformerFactory,
+	)
+
 	recorder := func(component string) record.EventRecorder {
-		return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+		config := configStore.Get()
+		r := shipperevents.NewRecorder(
+			broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component}),
+			config.EventVerbosity,
+			config.EventDedupInterval,
+		)
+		configStore.AddListener(func(config shipperconfig.Config) {
+			r.Configure(config.EventVerbosity, config.EventDedupInterval)
+		})
+
+		return r
 	}
 
 	enabledControllers := buildEnabledControllers(*enabledControllers, *disabledControllers)
@@ -163,6 +271,24 @@ func main() {
 		restTimeout,
 	)
 
+	if *lazyClusters {
+		klog.V(1).Infof("Only watching application clusters with Releases, for up to %s after the last one is gone", *clusterIdleGrace)
+		store.EnableLazyStart(*clusterIdleGrace)
+	}
+
+	if *breakerFailures > 0 {
+		klog.V(1).Infof("Application clusters stop being called for %s after %d calls in a row fail", *breakerCooldown, *breakerFailures)
+		store.EnableCircuitBreaker(
+			client.NewShipperClientOrDie("shipper-cluster-breaker", restCfg),
+			*breakerFailures,
+			*breakerCooldown,
+		)
+	}
+
+	// Clusters in pull mode have their targets kept in this cluster, for
+	// the agents running in them to pull.
+	store.EnablePullMode(restCfg)
+
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
@@ -173,11 +299,29 @@ func main() {
 	klog.V(1).Infof("Chart cache stored at %q", *chartCacheDir)
 	klog.V(1).Infof("REST client timeout is %s", *restTimeout)
 
+	if *vaultAddr != "" {
+		klog.V(1).Infof("Resolving chart values from Vault at %q", *vaultAddr)
+		valuesource.RegisterStore(
+			valuesource.VaultStoreName,
+			valuesource.NewVaultStore(*vaultAddr, os.Getenv("VAULT_TOKEN"), *vaultPathPrefix),
+		)
+	}
+
 	repoCatalog := repo.NewCatalog(
 		repo.DefaultFileCacheFactory(*chartCacheDir),
 		repo.DefaultRemoteFetcher,
 		stopCh,
 	)
+	chartRepoMaxRequests := configStore.Get().ChartRepoMaxRequests
+	repoCatalog.LimitConcurrentRequests(chartRepoMaxRequests)
+	configStore.AddListener(func(config shipperconfig.Config) {
+		if config.ChartRepoMaxRequests == chartRepoMaxRequests {
+			return
+		}
+
+		chartRepoMaxRequests = config.ChartRepoMaxRequests
+		repoCatalog.LimitConcurrentRequests(chartRepoMaxRequests)
+	})
 
 	ssm := statemetrics.MgmtMetrics{
 		AppsLister:     shipperInformerFactory.Shipper().V1alpha1().Applications().Lister(),
@@ -198,6 +342,20 @@ func main() {
 		controllerRestCfg.Timeout = *restTimeout
 	}
 
+	var imageInspector registry.PlatformInspector
+	if *imagePlatformCheck {
+		imageInspector = registry.NewHTTPInspector(*restTimeout)
+	}
+
+	var policyEvaluator policy.Evaluator
+	if *opaURL != "" {
+		policyEvaluator = policy.NewOPAEvaluator(*opaURL, *restTimeout)
+	}
+
+	completionNotifier := release.NewHTTPCompletionNotifier(func() string {
+		return configStore.Get().CompletionWebhookURL
+	})
+
 	cfg := &cfg{
 		enabledControllers: enabledControllers,
 		restCfg:            controllerRestCfg,
@@ -211,17 +369,39 @@ func main() {
 
 		store: store,
 
+		repoCatalog:          repoCatalog,
 		chartVersionResolver: repo.ResolveChartVersionFunc(repoCatalog),
 		chartFetcher:         repo.FetchChartFunc(repoCatalog),
 
-		ns:      *ns,
-		workers: *workers,
+		ns:                 *ns,
+		workers:            *workers,
+		drainTimeout:       *shutdownTimeout,
+		lowPriorityMaxWait: *lowPriorityMaxWait,
 
 		webhookCertPath: *webhookCertPath,
 		webhookKeyPath:  *webhookKeyPath,
 		webhookBindAddr: *webhookBindAddr,
 		webhookBindPort: *webhookBindPort,
 
+		gitopsRepo:        *gitopsRepo,
+		gitopsBranch:      *gitopsBranch,
+		gitopsPath:        *gitopsPath,
+		gitopsCheckoutDir: *gitopsCheckoutDir,
+		gitopsInterval:    *gitopsInterval,
+
+		ciAPITokensFile: *ciAPITokensFile,
+		ciAPIAddr:       *ciAPIAddr,
+		ciAPICertPath:   *ciAPICertPath,
+		ciAPIKeyPath:    *ciAPIKeyPath,
+		ciAPIInsecure:   *ciAPIInsecure,
+		ciAPIApprover:   *ciAPIApprover,
+
+		imageInspector:     imageInspector,
+		policyEvaluator:    policyEvaluator,
+		completionNotifier: completionNotifier,
+
+		configStore: configStore,
+
 		wg:     wg,
 		stopCh: stopCh,
 
@@ -234,6 +414,15 @@ func main() {
 		},
 	}
 
+	if *debugAddr != "" {
+		go func() {
+			klog.V(1).Infof("Debug endpoints will listen on %s", *debugAddr)
+			if err := http.ListenAndServe(*debugAddr, debug.NewHandler()); err != nil {
+				klog.Errorf("could not start the debug endpoints: %s", err)
+			}
+		}()
+	}
+
 	go func() {
 		klog.V(1).Infof("Metrics will listen on %s", *metricsAddr)
 		<-metricsReadyCh
@@ -244,7 +433,16 @@ func main() {
 		runMetrics(cfg.metrics)
 	}()
 
+	if *otlpEndpoint != "" {
+		klog.V(1).Infof("Exporting traces to %q", *otlpEndpoint)
+		exporter := tracing.NewOTLPExporter(*otlpEndpoint, "shipper-mgmt", *restTimeout)
+		tracer := tracing.NewTracer(exporter, *tracingSampleRatio)
+		tracing.SetTracer(tracer)
+		go tracer.Run()
+	}
+
 	runControllers(cfg)
+	tracing.Flush()
 }
 
 type klogStdLogger struct{}
@@ -260,6 +458,18 @@ func runMetrics(cfg *metricsCfg) {
 	prometheus.MustRegister(cfg.restLatency.Summary, cfg.restResult.Counter)
 	prometheus.MustRegister(instrumentedclient.GetMetrics()...)
 	prometheus.MustRegister(cfg.stateMetrics)
+	prometheus.MustRegister(shippermetrics.SyncErrors)
+	prometheus.MustRegister(
+		shippermetrics.CacheSyncDuration,
+		shippermetrics.InformerRelists,
+		shippermetrics.InformerWatchErrors,
+	)
+	prometheus.MustRegister(shippermetrics.TracingSpansDropped)
+	prometheus.MustRegister(
+		shippermetrics.ChartCacheLookups,
+		shippermetrics.ChartRepoRequestsInFlight,
+		shippermetrics.ChartRepoRequestsWaiting,
+	)
 
 	srv := http.Server{
 		Addr: *metricsAddr,
@@ -325,7 +535,7 @@ func runControllers(cfg *cfg) {
 		started, err := initializer(cfg)
 		// TODO make it visible when some controller's aren't starting properly; all of the initializers return 'nil' ATM
 		if err != nil {
-			klog.Fatalf("%q failed to initialize", name)
+			klog.Fatalf("%q failed to initialize: %s", name, err)
 		}
 
 		if !started {
@@ -337,8 +547,20 @@ func runControllers(cfg *cfg) {
 	// metrics now.
 	close(cfg.metrics.readyCh)
 
-	go cfg.kubeInformerFactory.Start(cfg.stopCh)
-	go cfg.shipperInformerFactory.Start(cfg.stopCh)
+	informersStarted := time.Now()
+	cfg.kubeInformerFactory.Start(cfg.stopCh)
+	cfg.shipperInformerFactory.Start(cfg.stopCh)
+
+	go func() {
+		if allSynced(cfg.kubeInformerFactory.WaitForCacheSync(cfg.stopCh)) {
+			shippermetrics.ObserveCacheSync("management", "kubernetes", informersStarted)
+		}
+	}()
+	go func() {
+		if allSynced(cfg.shipperInformerFactory.WaitForCacheSync(cfg.stopCh)) {
+			shippermetrics.ObserveCacheSync("management", "shipper", informersStarted)
+		}
+	}()
 
 	doneCh := make(chan struct{})
 
@@ -351,6 +573,15 @@ func runControllers(cfg *cfg) {
 	klog.Info("Controllers have shut down")
 }
 
+func allSynced(synced map[reflect.Type]bool) bool {
+	for _, ok := range synced {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func setupSignalHandler() <-chan struct{} {
 	stopCh := make(chan struct{})
 
@@ -367,11 +598,33 @@ func setupSignalHandler() <-chan struct{} {
 	return stopCh
 }
 
+// buildNamespaces parses the namespaces allowlist. The namespace of global
+// RolloutBlocks is always part of a non-empty allowlist, as they apply to
+// every namespace.
+func buildNamespaces(namespaces string) []string {
+	if namespaces == "" {
+		return nil
+	}
+
+	allowed := []string{shipper.GlobalRolloutBlockNamespace}
+	for _, ns := range strings.Split(namespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns != "" && ns != shipper.GlobalRolloutBlockNamespace {
+			allowed = append(allowed, ns)
+		}
+	}
+
+	return allowed
+}
+
 type initFunc func(*cfg) (bool, error)
 
 func buildInitializers() map[string]initFunc {
 	controllers := map[string]initFunc{}
 	controllers["application"] = startApplicationController
+	controllers["chartrepository"] = startChartRepositoryController
+	controllers["ciapi"] = startCIAPI
+	controllers["gitops"] = startGitOpsController
 	controllers["janitor"] = startJanitorController
 	controllers["release"] = startReleaseController
 	controllers["rolloutblock"] = startRolloutBlockController
@@ -390,6 +643,8 @@ func startApplicationController(cfg *cfg) (bool, error) {
 		cfg.shipperInformerFactory,
 		cfg.chartVersionResolver,
 		cfg.recorder(application.AgentName),
+		cfg.drainTimeout,
+		cfg.configStore,
 	)
 
 	cfg.wg.Add(1)
@@ -401,6 +656,69 @@ func startApplicationController(cfg *cfg) (bool, error) {
 	return true, nil
 }
 
+func startCIAPI(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["ciapi"] && cfg.ciAPITokensFile != ""
+	if !enabled {
+		return false, nil
+	}
+
+	if (cfg.ciAPICertPath == "" || cfg.ciAPIKeyPath == "") && !cfg.ciAPIInsecure {
+		return false, fmt.Errorf("refusing to accept CI API tokens over plain HTTP: set -ci-api-cert and -ci-api-key, or -ci-api-insecure if TLS is terminated in front of it")
+	}
+
+	tokens, err := ciapi.ReadTokens(cfg.ciAPITokensFile)
+	if err != nil {
+		return false, err
+	}
+
+	approver := cfg.ciAPIApprover
+	if approver == "" {
+		approver = fmt.Sprintf("system:serviceaccount:%s:%s", cfg.ns, shipper.ShipperManagementServiceAccount)
+	}
+
+	c := ciapi.NewServer(
+		cfg.ciAPIAddr,
+		tokens,
+		approver,
+		cfg.ciAPICertPath,
+		cfg.ciAPIKeyPath,
+		client.NewShipperClientOrDie(ciapi.AgentName, cfg.restCfg),
+		cfg.shipperInformerFactory,
+	)
+
+	cfg.wg.Add(1)
+	go func() {
+		c.Run(cfg.stopCh)
+		cfg.wg.Done()
+	}()
+
+	return true, nil
+}
+
+func startGitOpsController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["gitops"] && cfg.gitopsRepo != ""
+	if !enabled {
+		return false, nil
+	}
+
+	c := gitops.NewController(
+		client.NewShipperClientOrDie(gitops.AgentName, cfg.restCfg),
+		cfg.shipperInformerFactory,
+		gitops.NewGitSource(cfg.gitopsRepo, cfg.gitopsBranch, cfg.gitopsPath, cfg.gitopsCheckoutDir),
+		cfg.gitopsInterval,
+		cfg.recorder(gitops.AgentName),
+		cfg.configStore,
+	)
+
+	cfg.wg.Add(1)
+	go func() {
+		c.Run(cfg.stopCh)
+		cfg.wg.Done()
+	}()
+
+	return true, nil
+}
+
 func startJanitorController(cfg *cfg) (bool, error) {
 	enabled := cfg.enabledControllers["janitor"]
 	if !enabled {
@@ -412,6 +730,7 @@ func startJanitorController(cfg *cfg) (bool, error) {
 		cfg.store,
 		cfg.shipperInformerFactory,
 		cfg.recorder(janitor.AgentName),
+		cfg.drainTimeout,
 	)
 
 	cfg.wg.Add(1)
@@ -435,6 +754,38 @@ func startReleaseController(cfg *cfg) (bool, error) {
 		cfg.shipperInformerFactory,
 		cfg.chartFetcher,
 		cfg.recorder(release.AgentName),
+		release.Options{
+			DrainTimeout:       cfg.drainTimeout,
+			LowPriorityMaxWait: cfg.lowPriorityMaxWait,
+			ImageInspector:     cfg.imageInspector,
+			PolicyEvaluator:    cfg.policyEvaluator,
+			CompletionNotifier: cfg.completionNotifier,
+			CompletionTimeout:  *cfg.restTimeout,
+		},
+	)
+
+	cfg.wg.Add(1)
+	go func() {
+		c.Run(cfg.workers, cfg.stopCh)
+		cfg.wg.Done()
+	}()
+
+	return true, nil
+}
+
+func startChartRepositoryController(cfg *cfg) (bool, error) {
+	enabled := cfg.enabledControllers["chartrepository"]
+	if !enabled {
+		return false, nil
+	}
+
+	c := chartrepository.NewController(
+		cfg.shipperInformerFactory,
+		cfg.kubeInformerFactory,
+		cfg.ns,
+		cfg.repoCatalog,
+		cfg.recorder(chartrepository.AgentName),
+		cfg.drainTimeout,
 	)
 
 	cfg.wg.Add(1)
@@ -456,6 +807,7 @@ func startRolloutBlockController(cfg *cfg) (bool, error) {
 		client.NewShipperClientOrDie(rolloutblock.AgentName, cfg.restCfg),
 		cfg.shipperInformerFactory,
 		cfg.recorder(rolloutblock.AgentName),
+		cfg.drainTimeout,
 	)
 
 	cfg.wg.Add(1)
@@ -478,6 +830,7 @@ func startWebhook(cfg *cfg) (bool, error) {
 		cfg.webhookBindPort,
 		cfg.webhookKeyPath,
 		cfg.webhookCertPath,
+		client.NewKubeClientOrDie(webhook.AgentName, cfg.restCfg),
 		client.NewShipperClientOrDie(webhook.AgentName, cfg.restCfg),
 		cfg.shipperInformerFactory)
 
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/configurator"
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/backup"
+	shipperclient "github.com/bookingcom/shipper/pkg/client"
+)
+
+const (
+	outputFlagName        = "output"
+	skipInventoryFlagName = "skip-inventory"
+)
+
+var (
+	backupFile    string
+	backupFormat  string
+	skipInventory bool
+
+	BackupCmd = &cobra.Command{
+		Use:   "backup [-f backup.yaml]",
+		Short: "export all Shipper objects and what they installed in application clusters",
+		Args:  cobra.NoArgs,
+		RunE:  runBackupCommand,
+	}
+
+	RestoreCmd = &cobra.Command{
+		Use:   "restore -f backup.yaml",
+		Short: "rebuild the management cluster from a backup, without touching application clusters",
+		Args:  cobra.NoArgs,
+		RunE:  runRestoreCommand,
+	}
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{BackupCmd, RestoreCmd} {
+		cmd.Flags().StringVar(&kubeConfigFile, kubeConfigFlagName, "~/.kube/config", "the path to the Kubernetes configuration file")
+		if err := cmd.MarkFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+			cmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+		}
+
+		cmd.Flags().StringVar(&managementClusterContext, "management-cluster-context", "", "the name of the context to use to communicate with the management cluster. defaults to the current one")
+		cmd.Flags().StringVarP(&backupFile, fileFlagName, "f", "", "the path to the backup file")
+		if err := cmd.MarkFlagFilename(fileFlagName, "yaml", "json"); err != nil {
+			cmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", fileFlagName, err)
+		}
+	}
+
+	BackupCmd.Flags().StringVarP(&shipperNamespace, "namespace", "n", shipper.ShipperNamespace, "the namespace where Shipper is running")
+	BackupCmd.Flags().StringVarP(&backupFormat, outputFlagName, "o", "yaml", "the format to write the backup in, yaml or json")
+	BackupCmd.Flags().BoolVar(&skipInventory, skipInventoryFlagName, false, "don't inventory application clusters")
+
+	if err := RestoreCmd.MarkFlagRequired(fileFlagName); err != nil {
+		RestoreCmd.Printf("warning: could not mark %q as required: %s\n", fileFlagName, err)
+	}
+}
+
+func runBackupCommand(cmd *cobra.Command, args []string) error {
+	if backupFormat != "yaml" && backupFormat != "json" {
+		return fmt.Errorf("unknown output format %q, expected yaml or json", backupFormat)
+	}
+
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	var clusterClients backup.ClusterClientsFunc
+	if !skipInventory {
+		clusterClients = buildClusterClientsFunc(mgmt)
+	}
+
+	b, err := backup.Export(mgmt.ShipperClient, clusterClients)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if backupFormat == "json" {
+		data, err = json.MarshalIndent(b, "", "  ")
+	} else {
+		data, err = yaml.Marshal(b)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, inventory := range b.Inventories {
+		if inventory.Error != "" {
+			cmd.Printf("warning: could not inventory cluster %q: %s\n", inventory.Cluster, inventory.Error)
+		}
+	}
+
+	if backupFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := ioutil.WriteFile(backupFile, data, 0600); err != nil {
+		return err
+	}
+
+	cmd.Printf("Backed up %d applications and %d releases to %q\n", len(b.Applications), len(b.Releases), backupFile)
+
+	return nil
+}
+
+func runRestoreCommand(cmd *cobra.Command, args []string) error {
+	data, err := ioutil.ReadFile(backupFile)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, so this reads backups in either format.
+	b := &backup.Backup{}
+	if err := yaml.Unmarshal(data, b); err != nil {
+		return err
+	}
+
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	result, err := backup.Restore(mgmt.ShipperClient, b)
+	if result != nil {
+		for _, name := range result.Created {
+			cmd.Printf("created %s\n", name)
+		}
+		for _, name := range result.Existing {
+			cmd.Printf("%s already exists, skipping\n", name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Restored %d objects, %d already existed\n", len(result.Created), len(result.Existing))
+
+	return nil
+}
+
+// buildClusterClientsFunc returns a backup.ClusterClientsFunc that builds
+// clients for application clusters from the secrets Shipper uses to talk
+// to them.
+func buildClusterClientsFunc(mgmt *configurator.Cluster) backup.ClusterClientsFunc {
+	return func(cluster *shipper.Cluster) (backup.ClusterClients, error) {
+		if cluster.Spec.PullMode {
+			return backup.ClusterClients{}, fmt.Errorf("cluster is in pull mode, and isn't reachable from here")
+		}
+
+		secret, err := mgmt.FetchSecret(cluster.Name, shipperNamespace)
+		if err != nil {
+			return backup.ClusterClients{}, err
+		}
+
+		config := shipperclient.BuildConfigFromClusterAndSecret(cluster, secret)
+
+		shipperClient, err := shipperclient.NewShipperClient(configurator.AgentName, config)
+		if err != nil {
+			return backup.ClusterClients{}, err
+		}
+
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			return backup.ClusterClients{}, err
+		}
+
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return backup.ClusterClients{}, err
+		}
+
+		return backup.ClusterClients{
+			Shipper:   shipperClient,
+			Discovery: discoveryClient,
+			Dynamic:   dynamicClient,
+		}, nil
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
-	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -290,13 +289,7 @@ func joinClusters(
 func createOrUpdateApplicationCrds(cmd *cobra.Command, configurator *configurator.Cluster) error {
 	cmd.Print("Registering or updating custom resource definitions... ")
 
-	crds := []*apiextensionv1beta1.CustomResourceDefinition{
-		crds.InstallationTarget,
-		crds.CapacityTarget,
-		crds.TrafficTarget,
-	}
-
-	for _, crd := range crds {
+	for _, crd := range crds.ApplicationClusterCRDs {
 		err := configurator.CreateOrUpdateCRD(crd)
 		if err != nil {
 			return err
@@ -311,14 +304,7 @@ func createOrUpdateApplicationCrds(cmd *cobra.Command, configurator *configurato
 func createOrUpdateManagementCrds(cmd *cobra.Command, configurator *configurator.Cluster) error {
 	cmd.Print("Registering or updating custom resource definitions... ")
 
-	crds := []*apiextensionv1beta1.CustomResourceDefinition{
-		crds.Cluster,
-		crds.RolloutBlock,
-		crds.Application,
-		crds.Release,
-	}
-
-	for _, crd := range crds {
+	for _, crd := range crds.ManagementClusterCRDs {
 		err := configurator.CreateOrUpdateCRD(crd)
 		if err != nil {
 			return err
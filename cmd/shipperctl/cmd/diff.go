@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/configurator"
+	"github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/controller/installation"
+)
+
+var (
+	releaseNamespace string
+	chartCacheDir    string
+
+	DiffCmd = &cobra.Command{
+		Use:   "diff release-a release-b",
+		Short: "show what rolling out from one release to another changes in each cluster",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runDiffCommand,
+	}
+)
+
+func init() {
+	DiffCmd.Flags().StringVar(&kubeConfigFile, kubeConfigFlagName, "~/.kube/config", "the path to the Kubernetes configuration file")
+	if err := DiffCmd.MarkFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+		DiffCmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+	}
+
+	DiffCmd.Flags().StringVar(&managementClusterContext, "management-cluster-context", "", "the name of the context to use to communicate with the management cluster. defaults to the current one")
+	DiffCmd.Flags().StringVarP(&releaseNamespace, "namespace", "n", "default", "the namespace of the releases")
+	DiffCmd.Flags().StringVar(&chartCacheDir, "cachedir", filepath.Join(os.TempDir(), "chart-cache"), "location for the local cache of downloaded charts")
+}
+
+func runDiffCommand(cmd *cobra.Command, args []string) error {
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	from, err := mgmt.FetchRelease(releaseNamespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	to, err := mgmt.FetchRelease(releaseNamespace, args[1])
+	if err != nil {
+		return err
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	catalog := repo.NewCatalog(
+		repo.DefaultFileCacheFactory(chartCacheDir),
+		repo.DefaultRemoteFetcher,
+		stopCh,
+	)
+
+	diffs, err := installation.DiffReleases(repo.FetchChartFunc(catalog), from, to)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, diff := range diffs {
+		if diff.Diff == "" {
+			fmt.Fprintf(out, "# %s: no changes\n", diff.Cluster)
+			continue
+		}
+		fmt.Fprint(out, diff.Diff)
+	}
+
+	return nil
+}
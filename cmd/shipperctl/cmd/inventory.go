@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/configurator"
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+var (
+	inventoryNamespace string
+	inventoryApp       string
+	inventoryFormat    string
+
+	InventoryCmd = &cobra.Command{
+		Use:   "inventory [-n namespace] [--app application]",
+		Short: "show which release of each application serves traffic in each cluster",
+		Args:  cobra.NoArgs,
+		RunE:  runInventoryCommand,
+	}
+)
+
+func init() {
+	InventoryCmd.Flags().StringVar(&kubeConfigFile, kubeConfigFlagName, "~/.kube/config", "the path to the Kubernetes configuration file")
+	if err := InventoryCmd.MarkFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+		InventoryCmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+	}
+
+	InventoryCmd.Flags().StringVar(&managementClusterContext, "management-cluster-context", "", "the name of the context to use to communicate with the management cluster. defaults to the current one")
+	InventoryCmd.Flags().StringVarP(&inventoryNamespace, "namespace", "n", metav1.NamespaceAll, "the namespace of the applications. defaults to all of them")
+	InventoryCmd.Flags().StringVar(&inventoryApp, "app", "", "the name of the application. defaults to all of them")
+	InventoryCmd.Flags().StringVarP(&inventoryFormat, outputFlagName, "o", "table", "the format to write the inventory in, table, yaml or json")
+}
+
+func runInventoryCommand(cmd *cobra.Command, args []string) error {
+	if inventoryFormat != "table" && inventoryFormat != "yaml" && inventoryFormat != "json" {
+		return fmt.Errorf("unknown output format %q, expected table, yaml or json", inventoryFormat)
+	}
+
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	clusterList, err := mgmt.ListClusters()
+	if err != nil {
+		return err
+	}
+
+	clusters := make([]*shipper.Cluster, 0, len(clusterList.Items))
+	for i := range clusterList.Items {
+		clusters = append(clusters, &clusterList.Items[i])
+	}
+
+	releaseList, err := mgmt.ListReleases(inventoryNamespace)
+	if err != nil {
+		return err
+	}
+
+	releases := make([]*shipper.Release, 0, len(releaseList.Items))
+	for i := range releaseList.Items {
+		rel := &releaseList.Items[i]
+		if inventoryApp == "" || rel.Labels[shipper.AppLabel] == inventoryApp {
+			releases = append(releases, rel)
+		}
+	}
+
+	inventory := releaseutil.Inventory(releases, clusters)
+
+	out := cmd.OutOrStdout()
+	switch inventoryFormat {
+	case "json":
+		data, err := json.MarshalIndent(inventory, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(inventory)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tAPPLICATION\tRELEASE\tCHART VERSION\tREGION\tCLUSTER\tWEIGHT")
+	for _, entry := range inventory {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			entry.Namespace, entry.Application, entry.Release, entry.ChartVersion,
+			entry.Region, entry.Cluster, entry.Weight)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/configurator"
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+const toFlagName = "to"
+
+var (
+	rollbackTarget  string
+	instantRollback bool
+
+	RollbackCmd = &cobra.Command{
+		Use:   "rollback --to release",
+		Short: "roll an application back to any of its previous releases",
+		Args:  cobra.NoArgs,
+		RunE:  runRollbackCommand,
+	}
+)
+
+func init() {
+	RollbackCmd.Flags().StringVar(&kubeConfigFile, kubeConfigFlagName, "~/.kube/config", "the path to the Kubernetes configuration file")
+	if err := RollbackCmd.MarkFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+		RollbackCmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+	}
+
+	RollbackCmd.Flags().StringVar(&managementClusterContext, "management-cluster-context", "", "the name of the context to use to communicate with the management cluster. defaults to the current one")
+	RollbackCmd.Flags().StringVarP(&releaseNamespace, "namespace", "n", "default", "the namespace of the release")
+	RollbackCmd.Flags().StringVar(&rollbackTarget, toFlagName, "", "the name of the release to roll back to")
+	if err := RollbackCmd.MarkFlagRequired(toFlagName); err != nil {
+		RollbackCmd.Printf("warning: could not mark %q as required: %s\n", toFlagName, err)
+	}
+	RollbackCmd.Flags().BoolVar(&instantRollback, "instant", false, "move traffic back to the release the latest one replaced, which must still be on standby, all at once")
+}
+
+func runRollbackCommand(cmd *cobra.Command, args []string) error {
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	rel, err := mgmt.FetchRelease(releaseNamespace, rollbackTarget)
+	if err != nil {
+		return err
+	}
+
+	appName, ok := rel.Labels[shipper.AppLabel]
+	if !ok {
+		return fmt.Errorf("release %q does not belong to any application", rel.Name)
+	}
+
+	if instantRollback {
+		return runInstantRollback(cmd, mgmt, rel, appName)
+	}
+
+	app, err := mgmt.FetchApplication(rel.Namespace, appName)
+	if err != nil {
+		return err
+	}
+
+	if err := apputil.RollbackToRelease(app, rel); err != nil {
+		return err
+	}
+
+	if _, err := mgmt.UpdateApplication(app); err != nil {
+		return err
+	}
+
+	cmd.Printf("Rolling application %q back to release %q\n", app.Name, rel.Name)
+
+	return nil
+}
+
+// runInstantRollback rolls the latest release of the application back to
+// its first step, annotated so that the release controller scales rel up
+// and moves all the traffic back to it at once, instead of going through
+// the steps of the strategy.
+func runInstantRollback(cmd *cobra.Command, mgmt *configurator.Cluster, rel *shipper.Release, appName string) error {
+	releaseList, err := mgmt.ListReleases(rel.Namespace)
+	if err != nil {
+		return err
+	}
+
+	releases := make([]*shipper.Release, 0, len(releaseList.Items))
+	for i := range releaseList.Items {
+		if releaseList.Items[i].Labels[shipper.AppLabel] == appName {
+			releases = append(releases, &releaseList.Items[i])
+		}
+	}
+
+	_, contender, err := releaseutil.GetSiblingReleases(rel, releases)
+	if err != nil {
+		return err
+	}
+	if contender == nil {
+		return fmt.Errorf("release %q is the latest release of application %q", rel.Name, appName)
+	}
+	_, succ, err := releaseutil.GetSiblingReleases(contender, releases)
+	if err != nil {
+		return err
+	}
+	if succ != nil {
+		return fmt.Errorf("release %q is not the release right before the latest one of application %q", rel.Name, appName)
+	}
+
+	if err := releaseutil.ValidateInstantRollback(rel, contender); err != nil {
+		return err
+	}
+
+	contender = contender.DeepCopy()
+	contender.Spec.TargetStep = 0
+	if contender.Annotations == nil {
+		contender.Annotations = map[string]string{}
+	}
+	contender.Annotations[shipper.InstantRollbackAnnotation] = rel.Name
+
+	if _, err := mgmt.UpdateRelease(contender); err != nil {
+		return err
+	}
+
+	cmd.Printf("Rolling release %q back to release %q instantly\n", contender.Name, rel.Name)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/bookingcom/shipper/cmd/shipperctl/configurator"
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/controller/release"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+var (
+	applicationYaml string
+
+	SimulateCmd = &cobra.Command{
+		Use:   "simulate -f application.yaml",
+		Short: "show where and how an application would be rolled out, without changing anything",
+		Args:  cobra.NoArgs,
+		RunE:  runSimulateCommand,
+	}
+)
+
+func init() {
+	SimulateCmd.Flags().StringVar(&kubeConfigFile, kubeConfigFlagName, "~/.kube/config", "the path to the Kubernetes configuration file")
+	if err := SimulateCmd.MarkFlagFilename(kubeConfigFlagName, "yaml"); err != nil {
+		SimulateCmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", kubeConfigFlagName, err)
+	}
+
+	SimulateCmd.Flags().StringVar(&managementClusterContext, "management-cluster-context", "", "the name of the context to use to communicate with the management cluster. defaults to the current one")
+	SimulateCmd.Flags().StringVarP(&releaseNamespace, "namespace", "n", "default", "the namespace of the application, if not set in its manifest")
+	SimulateCmd.Flags().StringVar(&chartCacheDir, "cachedir", filepath.Join(os.TempDir(), "chart-cache"), "location for the local cache of downloaded charts")
+	SimulateCmd.Flags().StringVarP(&applicationYaml, fileFlagName, "f", "", "the path to a YAML file containing the application")
+	if err := SimulateCmd.MarkFlagFilename(fileFlagName, "yaml"); err != nil {
+		SimulateCmd.Printf("warning: could not mark %q for filename autocompletion: %s\n", fileFlagName, err)
+	}
+	if err := SimulateCmd.MarkFlagRequired(fileFlagName); err != nil {
+		SimulateCmd.Printf("warning: could not mark %q as required: %s\n", fileFlagName, err)
+	}
+}
+
+func runSimulateCommand(cmd *cobra.Command, args []string) error {
+	appBytes, err := ioutil.ReadFile(applicationYaml)
+	if err != nil {
+		return err
+	}
+
+	app := &shipper.Application{}
+	if err := yaml.Unmarshal(appBytes, app); err != nil {
+		return err
+	}
+
+	if app.Namespace == "" {
+		app.Namespace = releaseNamespace
+	}
+
+	mgmt, err := configurator.NewClusterConfiguratorFromKubeConfig(kubeConfigFile, managementClusterContext)
+	if err != nil {
+		return err
+	}
+
+	clusterList, err := mgmt.ListClusters()
+	if err != nil {
+		return err
+	}
+
+	clusters := make([]*shipper.Cluster, 0, len(clusterList.Items))
+	for i := range clusterList.Items {
+		clusters = append(clusters, &clusterList.Items[i])
+	}
+
+	releaseList, err := mgmt.ListReleases(app.Namespace)
+	if err != nil {
+		return err
+	}
+
+	releases := make([]*shipper.Release, 0, len(releaseList.Items))
+	for i := range releaseList.Items {
+		releases = append(releases, &releaseList.Items[i])
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	catalog := repo.NewCatalog(
+		repo.DefaultFileCacheFactory(chartCacheDir),
+		repo.DefaultRemoteFetcher,
+		stopCh,
+	)
+
+	rel, err := buildSimulatedRelease(app, repo.ResolveChartVersionFunc(catalog))
+	if err != nil {
+		return err
+	}
+
+	incumbent := findIncumbent(app, releases)
+
+	simulation, err := release.Simulate(repo.FetchChartFunc(catalog), rel, incumbent, clusters, releases)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "clusters: %s\n", strings.Join(simulation.Clusters, ", "))
+	if incumbent != nil {
+		fmt.Fprintf(out, "incumbent: %s\n", incumbent.Name)
+	}
+
+	for i, step := range simulation.Steps {
+		fmt.Fprintf(out, "\nstep %d %q: traffic weight contender %d, incumbent %d\n",
+			i, step.Name, step.ContenderWeight, step.IncumbentWeight)
+		for _, cluster := range step.Clusters {
+			fmt.Fprintf(out, "  %s: contender %d replicas, incumbent %d replicas\n",
+				cluster.Name, cluster.ContenderReplicas, cluster.IncumbentReplicas)
+		}
+	}
+
+	return nil
+}
+
+// buildSimulatedRelease returns the release the application controller
+// would create for app, without creating it.
+func buildSimulatedRelease(app *shipper.Application, resolveVersion repo.ChartVersionResolver) (*shipper.Release, error) {
+	rel := &shipper.Release{}
+	rel.Name = fmt.Sprintf("%s-simulated", app.Name)
+	rel.Namespace = app.Namespace
+	rel.Labels = map[string]string{}
+	for k, v := range app.Labels {
+		rel.Labels[k] = v
+	}
+	rel.Labels[shipper.AppLabel] = app.Name
+	rel.Labels[shipper.ReleaseLabel] = rel.Name
+	rel.Annotations = map[string]string{}
+	rel.Spec.Environment = *app.Spec.Template.DeepCopy()
+
+	if rel.Spec.Environment.Strategy == nil {
+		return nil, fmt.Errorf("application %q has no strategy", app.Name)
+	}
+
+	if err := releaseutil.ResolveStrategyPreset(rel.Spec.Environment.Strategy); err != nil {
+		return nil, err
+	}
+
+	cv, err := resolveVersion(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+	rel.Spec.Environment.Chart.Version = cv.Version
+
+	return rel, nil
+}
+
+// findIncumbent returns the latest complete release of app, if any.
+func findIncumbent(app *shipper.Application, releases []*shipper.Release) *shipper.Release {
+	var appReleases []*shipper.Release
+	for _, rel := range releases {
+		if rel.Labels[shipper.AppLabel] == app.Name {
+			appReleases = append(appReleases, rel)
+		}
+	}
+
+	for _, rel := range releaseutil.SortByGenerationDescending(appReleases) {
+		if releaseutil.ReleaseComplete(rel) {
+			return rel
+		}
+	}
+
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	client "github.com/bookingcom/shipper/pkg/client"
 	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	"github.com/bookingcom/shipper/pkg/crds"
 	"github.com/mitchellh/go-homedir"
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -99,6 +100,11 @@ func (c *Cluster) CreateClusterRole(domain, name string) error {
 				APIGroups: []string{""},
 				Resources: []string{"events"},
 			},
+			rbacv1.PolicyRule{
+				Verbs:     []string{"create"},
+				APIGroups: []string{"authorization.k8s.io"},
+				Resources: []string{"subjectaccessreviews"},
+			},
 		},
 	}
 
@@ -189,6 +195,26 @@ func (c *Cluster) FetchCluster(clusterName string) (*shipper.Cluster, error) {
 	return c.ShipperClient.ShipperV1alpha1().Clusters().Get(clusterName, metav1.GetOptions{})
 }
 
+func (c *Cluster) FetchRelease(namespace, name string) (*shipper.Release, error) {
+	return c.ShipperClient.ShipperV1alpha1().Releases(namespace).Get(name, metav1.GetOptions{})
+}
+
+func (c *Cluster) ListReleases(namespace string) (*shipper.ReleaseList, error) {
+	return c.ShipperClient.ShipperV1alpha1().Releases(namespace).List(metav1.ListOptions{})
+}
+
+func (c *Cluster) UpdateRelease(rel *shipper.Release) (*shipper.Release, error) {
+	return c.ShipperClient.ShipperV1alpha1().Releases(rel.Namespace).Update(rel)
+}
+
+func (c *Cluster) FetchApplication(namespace, name string) (*shipper.Application, error) {
+	return c.ShipperClient.ShipperV1alpha1().Applications(namespace).Get(name, metav1.GetOptions{})
+}
+
+func (c *Cluster) UpdateApplication(app *shipper.Application) (*shipper.Application, error) {
+	return c.ShipperClient.ShipperV1alpha1().Applications(app.Namespace).Update(app)
+}
+
 func (c *Cluster) CopySecret(cluster *shipper.Cluster, newNamespace string, secret *corev1.Secret) error {
 	newSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -228,19 +254,7 @@ func (c *Cluster) CreateOrUpdateCluster(cluster *shipper.Cluster) error {
 }
 
 func (c *Cluster) CreateOrUpdateCRD(crd *apiextensionv1beta1.CustomResourceDefinition) error {
-	existingCrd, err := c.ApiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(crd.Name, metav1.GetOptions{})
-	if err != nil {
-		if errors.IsNotFound(err) {
-			_, err := c.ApiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
-			return err
-		} else {
-			return err
-		}
-	}
-
-	existingCrd.Spec = crd.Spec
-	_, err = c.ApiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Update(existingCrd)
-	return err
+	return crds.Install(c.ApiExtensionClient, []*apiextensionv1beta1.CustomResourceDefinition{crd})
 }
 
 func NewClusterConfiguratorFromKubeConfig(kubeConfigFile, context string) (*Cluster, error) {
@@ -417,6 +431,16 @@ func (c *Cluster) CreateOrUpdateValidatingWebhookConfiguration(caBundle []byte,
 							Resources:   []string{"*"},
 						},
 					},
+					admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{shipper.SchemeGroupVersion.Group},
+							APIVersions: []string{shipper.SchemeGroupVersion.Version},
+							Resources:   []string{"releases"},
+						},
+					},
 				},
 			},
 		},
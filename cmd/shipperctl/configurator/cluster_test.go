@@ -171,6 +171,16 @@ func (f *fixture) newValidatingWebhookConfiguration(caBundle []byte, namespace s
 							Resources:   []string{"*"},
 						},
 					},
+					admissionregistrationv1beta1.RuleWithOperations{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Delete,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{shipper.SchemeGroupVersion.Group},
+							APIVersions: []string{shipper.SchemeGroupVersion.Version},
+							Resources:   []string{"releases"},
+						},
+					},
 				},
 			},
 		},
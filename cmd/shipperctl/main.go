@@ -17,7 +17,13 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.AddCommand(cmd.BackupCmd)
 	rootCmd.AddCommand(cmd.ClustersCmd)
+	rootCmd.AddCommand(cmd.DiffCmd)
+	rootCmd.AddCommand(cmd.InventoryCmd)
+	rootCmd.AddCommand(cmd.RestoreCmd)
+	rootCmd.AddCommand(cmd.RollbackCmd)
+	rootCmd.AddCommand(cmd.SimulateCmd)
 }
 
 func main() {
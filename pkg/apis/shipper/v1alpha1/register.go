@@ -47,6 +47,22 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TrafficTargetList{},
 		&RolloutBlock{},
 		&RolloutBlockList{},
+		&FleetCapacityOverride{},
+		&FleetCapacityOverrideList{},
+		&Policy{},
+		&PolicyList{},
+		&ApplicationTemplate{},
+		&ApplicationTemplateList{},
+		&ApplicationDefault{},
+		&ApplicationDefaultList{},
+		&Strategy{},
+		&StrategyList{},
+		&ShipperConfig{},
+		&ShipperConfigList{},
+		&ChartRepository{},
+		&ChartRepositoryList{},
+		&RolloutQuota{},
+		&RolloutQuotaList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
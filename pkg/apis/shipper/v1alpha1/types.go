@@ -3,38 +3,143 @@ package v1alpha1
 import (
 	"encoding/json"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	ShipperNamespace            = "shipper-system"
 	GlobalRolloutBlockNamespace = "rollout-blocks-global"
+	GlobalPolicyNamespace       = "policies-global"
+	// GlobalApplicationTemplateNamespace holds the ApplicationTemplates
+	// applications in any namespace can follow.
+	GlobalApplicationTemplateNamespace = "application-templates-global"
+	// ApplicationDefaultName is the name of the ApplicationDefault
+	// applications in its namespace fall back to.
+	ApplicationDefaultName = "default"
+	// GlobalStrategyNamespace holds the Strategies applications in any
+	// namespace can refer to, if they're allowed to "use" them.
+	GlobalStrategyNamespace = "strategies-global"
+	// ShipperConfigName is the name of the ShipperConfig the management
+	// controllers take their configuration from.
+	ShipperConfigName = "shipper"
 
 	ShipperManagementServiceAccount  = "shipper-mgmt-cluster"
 	ShipperApplicationServiceAccount = "shipper-app-cluster"
 
+	// ReleaseLabel, AppLabel, ReleaseEnvironmentHashLabel and
+	// InstallationTargetOwnerLabel identify the release an object belongs
+	// to. Shipper sets them on every object it installs, and on the pods
+	// of every Deployment, overriding whatever the chart says. Traffic
+	// backends and selectors can rely on them.
 	ReleaseLabel                 = "shipper-release"
 	AppLabel                     = "shipper-app"
 	ReleaseEnvironmentHashLabel  = "shipper-release-hash"
-	PodTrafficStatusLabel        = "shipper-traffic-status"
 	InstallationTargetOwnerLabel = "shipper-owned-by"
-	MigrationLabel               = "shipper-target-object-migration-0.9-completed"
+	// PodTrafficStatusLabel is set on pods by Shipper to decide which of
+	// them get traffic. Charts can't set it themselves, nor select pods
+	// with it in Deployments.
+	PodTrafficStatusLabel = "shipper-traffic-status"
+	MigrationLabel        = "shipper-target-object-migration-0.9-completed"
+	// PullClusterLabel is set on the copies of targets that Shipper keeps
+	// in the management cluster for clusters in pull mode, and names the
+	// cluster they're for.
+	PullClusterLabel = "shipper-pull-cluster"
 
 	AppHighestObservedGenerationAnnotation = "shipper.booking.com/app.highestObservedGeneration"
 
+	// PulledAnnotation is set on targets a Shipper agent created from
+	// their copies in the management cluster.
+	PulledAnnotation = "shipper.booking.com/pulled"
+
 	AppChartNameAnnotation            = "shipper.booking.com/app.chart.name"
 	AppChartVersionResolvedAnnotation = "shipper.booking.com/app.chart.version.resolved"
 	AppChartVersionRawAnnotation      = "shipper.booking.com/app.chart.version.raw"
 
+	AppGitCommitAnnotation = "shipper.booking.com/app.git.commit"
+
 	ReleaseGenerationAnnotation        = "shipper.booking.com/release.generation"
 	ReleaseTemplateIterationAnnotation = "shipper.booking.com/release.template.iteration"
 	ReleaseClustersAnnotation          = "shipper.booking.com/release.clusters"
+	// ReleaseDefaultedAnnotation lists the fields of a release's
+	// environment that were taken from an ApplicationDefault when it was
+	// created, separated by commas.
+	ReleaseDefaultedAnnotation = "shipper.booking.com/release.defaulted"
+	// Deprecated: use ReleaseSpec.ClusterReplicas. Only read for releases
+	// scheduled before it existed.
+	ReleaseClusterReplicasAnnotation = "shipper.booking.com/release.clusters.replicas"
+	ReleaseGitCommitAnnotation       = "shipper.booking.com/release.git.commit"
+	// ReleaseForceDeleteAnnotation lets a contender be deleted before its
+	// rollout completes or is rolled back.
+	ReleaseForceDeleteAnnotation = "shipper.booking.com/release.force-delete"
+	// AdoptedAnnotation is set on the objects a release took over
+	// instead of installing them, and names the release.
+	AdoptedAnnotation = "shipper.booking.com/adopted"
+	// CompletedReleaseAnnotation, CompletedVersionAnnotation and
+	// CompletedAtAnnotation are set once a release completes its rollout,
+	// on the release, its Application and its Deployments, for systems
+	// outside of Shipper to tell what's rolled out. The version is the
+	// release's chart version, and the time is in RFC 3339 format.
+	CompletedReleaseAnnotation = "shipper.booking.com/completed.release"
+	CompletedVersionAnnotation = "shipper.booking.com/completed.version"
+	CompletedAtAnnotation      = "shipper.booking.com/completed.at"
+	// StandbyEndedAnnotation is set by the janitor on incumbents kept on
+	// standby once their retention is over, to when it ended, in RFC 3339
+	// format.
+	StandbyEndedAnnotation = "shipper.booking.com/standby.ended"
+	// InstantRollbackAnnotation is set on a release rolled back to its first
+	// step by `shipperctl rollback --instant`, and names the incumbent it's
+	// rolled back to. The incumbent is then scaled up before any traffic
+	// moves, and traffic moves back to it all at once.
+	InstantRollbackAnnotation = "shipper.booking.com/rollback.instant"
+	// CutOverToAnnotation is set on the installation targets of the
+	// releases a release was cut over from, and names it. Their Ingresses
+	// aren't installed again for as long as its installation target is
+	// around.
+	CutOverToAnnotation = "shipper.booking.com/cut-over-to"
+	// ExternalDNSHostnameAnnotation holds the comma-separated hostnames
+	// external-dns points DNS records at a Service for.
+	ExternalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
 
 	SecretClusterSkipTlsVerifyAnnotation = "shipper.booking.com/cluster-secret.insecure-tls-skip-verify"
 
+	ClusterTrafficBackendAnnotation = "shipper.booking.com/cluster.traffic-backend"
+	ClusterTrafficBackendCapability = "traffic-backend/"
+
+	// ClusterPlatformCapability prefixes the capabilities naming the
+	// platforms, as "<os>/<arch>", the nodes of a cluster run. Clusters
+	// without any are taken to run DefaultPlatform.
+	ClusterPlatformCapability = "platform/"
+	DefaultPlatform           = "linux/amd64"
+
+	// ClusterNamespacesAnnotation restricts which namespaces can have
+	// releases scheduled on a cluster. It holds a comma-separated list of
+	// glob patterns, as understood by path.Match.
+	ClusterNamespacesAnnotation = "shipper.booking.com/cluster.namespaces"
+
+	// ZoneLabel and DeprecatedZoneLabel are the node labels Kubernetes
+	// sets to the zone of nodes, in newer and older versions.
+	ZoneLabel           = "topology.kubernetes.io/zone"
+	DeprecatedZoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
 	RolloutBlocksOverrideAnnotation = "shipper.booking.com/rollout-block.override"
 
+	// RolloutQuotaReplicasAnnotation is set on releases a RolloutQuota let
+	// roll out, to the replicas they asked for across all of their
+	// clusters.
+	RolloutQuotaReplicasAnnotation = "shipper.booking.com/rollout-quota.replicas"
+
+	ConfigChecksumAnnotation = "shipper.booking.com/config-checksum"
+
+	// StepHookAnnotation marks Jobs in a chart as strategy step hooks.
+	// They're only run as hooks, and never installed with the rest of the
+	// chart.
+	StepHookAnnotation = "shipper.booking.com/step-hook"
+	StepHookLabel      = "shipper-step-hook"
+
 	LBLabel         = "shipper-lb"
 	LBForProduction = "production"
 
@@ -44,9 +149,17 @@ const (
 	True  = "true"
 	False = "false"
 
+	TrafficBackendPodLabel   = "pod-label"
+	TrafficBackendExternalLB = "external-lb"
+	TrafficBackendIstio      = "istio"
+	TrafficBackendGatewayAPI = "gateway-api"
+	TrafficBackendKnative    = "knative"
+
 	HelmReleaseLabel    = "release"
 	HelmWorkaroundLabel = "enable-helm-release-workaround"
 
+	ConfigChecksumLabel = "enable-config-checksum"
+
 	RBACDomainLabel       = "shipper-rbac-domain"
 	RBACManagementDomain  = "management"
 	RBACApplicationDomain = "application"
@@ -79,13 +192,64 @@ type ApplicationList struct {
 type ApplicationSpec struct {
 	RevisionHistoryLimit *int32             `json:"revisionHistoryLimit"`
 	Template             ReleaseEnvironment `json:"template"`
+
+	// TemplateRef makes the application follow an ApplicationTemplate.
+	// Its releases then get the template's environment, with Template
+	// layered over it.
+	TemplateRef *ApplicationTemplateRef `json:"templateRef,omitempty"`
+
+	// Adopt names objects already in the application clusters that the
+	// first release of the application takes over, instead of installing
+	// its chart. It's ignored once the application has releases.
+	Adopt *Adoption `json:"adopt,omitempty"`
+}
+
+// Adoption names the objects a release takes over in its clusters. They're
+// labeled and owned like the ones Shipper installs, but otherwise left as
+// they are, so the release can be the incumbent of the next one.
+type Adoption struct {
+	// Deployment is the name of the Deployment running the
+	// application.
+	Deployment string `json:"deployment"`
+	// Services are the names of the Services sending traffic to the
+	// Deployment's pods. They're taken over as production LB Services.
+	Services []string `json:"services,omitempty"`
+}
+
+type ApplicationTemplateRef struct {
+	// Name is the name of the ApplicationTemplate, looked up in the
+	// application's namespace and then in the global application
+	// template namespace.
+	Name string `json:"name"`
+	// Parameters are the values of the template's parameters.
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 type ApplicationStatus struct {
 	Conditions []ApplicationCondition `json:"conditions,omitempty"`
 	History    []string               `json:"history,omitempty"`
+	// Phase sums up how far the application's latest release is in
+	// being shipped.
+	Phase ApplicationPhase `json:"phase,omitempty"`
 }
 
+type ApplicationPhase string
+
+const (
+	// ApplicationPhasePending means the latest release hasn't been
+	// created, or hasn't been given clusters yet.
+	ApplicationPhasePending ApplicationPhase = "Pending"
+	// ApplicationPhaseShipping means the latest release is going through
+	// its strategy.
+	ApplicationPhaseShipping ApplicationPhase = "Shipping"
+	// ApplicationPhaseShipped means the latest release has completed its
+	// strategy.
+	ApplicationPhaseShipped ApplicationPhase = "Shipped"
+	// ApplicationPhaseFailed means the latest release couldn't be
+	// created, or can't make progress without someone changing it.
+	ApplicationPhaseFailed ApplicationPhase = "Failed"
+)
+
 type ApplicationConditionType string
 
 const (
@@ -108,6 +272,11 @@ type Chart struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 	RepoURL string `json:"repoUrl"`
+	// Digest is the digest the chart repository's index had for Version
+	// when the release was created. Shipper refuses to install a chart
+	// the index has a different digest for, e.g. because it was
+	// republished.
+	Digest string `json:"digest,omitempty"`
 }
 
 type ChartValues map[string]interface{}
@@ -150,18 +319,69 @@ type ClusterSpec struct {
 	Region       string                   `json:"region"`
 	APIMaster    string                   `json:"apiMaster"`
 	Scheduler    ClusterSchedulerSettings `json:"scheduler"`
+
+	// TrafficDisabled takes the cluster out of traffic for maintenance,
+	// without touching the capacity of any release in it. Traffic
+	// targets in the cluster publish no weight to external load
+	// balancers until it's cleared.
+	TrafficDisabled bool `json:"trafficDisabled,omitempty"`
+
+	// PullMode means a Shipper agent runs in the cluster and pulls its
+	// targets from the management cluster, so Shipper needs no
+	// credentials for it, nor to reach its API server.
+	PullMode bool `json:"pullMode,omitempty"`
+
+	// OperationTimeout is how long Shipper waits for each call to the
+	// cluster's API server, other than watches. Defaults to the
+	// -rest-timeout of shipper-mgmt.
+	OperationTimeout *metav1.Duration `json:"operationTimeout,omitempty"`
+
+	// MaxTotalCapacityPercent caps the capacity the incumbent and the
+	// contender of an application have in the cluster together, as a
+	// percentage of the capacity of one release. The contender is then
+	// scaled up, and given traffic, only as far as the incumbent has been
+	// scaled down.
+	MaxTotalCapacityPercent *int32 `json:"maxTotalCapacityPercent,omitempty"`
 }
 
 type ClusterSchedulerSettings struct {
 	Unschedulable bool    `json:"unschedulable"`
 	Weight        *int32  `json:"weight,omitempty"`
 	Identity      *string `json:"identity,omitempty"`
+
+	// CostTier and PricePerReplicaHour are hints for the CostAware
+	// scheduler: clusters with a lower tier, and then a lower price, are
+	// preferred. Clusters without hints are considered the most
+	// expensive.
+	CostTier            *int32             `json:"costTier,omitempty"`
+	PricePerReplicaHour *resource.Quantity `json:"pricePerReplicaHour,omitempty"`
+
+	// RolloutPriority orders clusters for Releases rolling out
+	// sequentially: lower priorities go first, and clusters without one
+	// go last.
+	RolloutPriority *int32 `json:"rolloutPriority,omitempty"`
 }
 
 // NOTE(btyler) when we introduce capacity based scheduling, the capacity can
 // be collected by a cluster controller and stored in cluster.status
 type ClusterStatus struct {
-	InService bool `json:"inService"`
+	InService  bool               `json:"inService"`
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+}
+
+const (
+	// ClusterConditionTypeOperational is False while Shipper has stopped
+	// calling the cluster's API server after too many of the calls
+	// failed.
+	ClusterConditionTypeOperational ClusterConditionType = "Operational"
+)
+
+type ClusterCondition struct {
+	Type               ClusterConditionType   `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // +genclient
@@ -190,6 +410,40 @@ type ReleaseList struct {
 type ReleaseSpec struct {
 	TargetStep  int32              `json:"targetStep"`
 	Environment ReleaseEnvironment `json:"environment"`
+
+	// Approvals records who approved which strategy steps, and when. It
+	// can only be appended to.
+	Approvals []ReleaseApproval `json:"approvals,omitempty"`
+
+	// ClusterReplicas records how many replicas each cluster runs, as
+	// split by the cluster spread when the release was scheduled.
+	// Clusters not listed run the chart's replica count.
+	ClusterReplicas []ClusterReplicas `json:"clusterReplicas,omitempty"`
+
+	// ClusterValues records the values the chart is rendered with in each
+	// cluster, once the environment's values overlays are merged, when
+	// the release was scheduled.
+	ClusterValues []ClusterValues `json:"clusterValues,omitempty"`
+
+	// Adopt is set on the first release of an application adopting
+	// objects already in its clusters.
+	Adopt *Adoption `json:"adopt,omitempty"`
+}
+
+type ClusterReplicas struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+type ClusterValues struct {
+	Name   string      `json:"name"`
+	Values ChartValues `json:"values"`
+}
+
+type ReleaseApproval struct {
+	Step       int32       `json:"step"`
+	Approver   string      `json:"approver"`
+	ApprovedAt metav1.Time `json:"approvedAt"`
 }
 
 // this will likely grow into a struct with interesting fields
@@ -197,6 +451,50 @@ type ReleaseStatus struct {
 	AchievedStep *AchievedStep          `json:"achievedStep,omitempty"`
 	Strategy     *ReleaseStrategyStatus `json:"strategy,omitempty"`
 	Conditions   []ReleaseCondition     `json:"conditions,omitempty"`
+	ETA          *ReleaseETA            `json:"eta,omitempty"`
+	Scheduling   *ReleaseScheduling     `json:"scheduling,omitempty"`
+	// Probes holds the latest results of the probes of the step the
+	// release is moving to, for every pod probed.
+	Probes []ProbeResult `json:"probes,omitempty"`
+	// FullTrafficSince is when the release, as the head of its
+	// application, got all the traffic in every cluster. It's only
+	// recorded for strategies with an IncumbentFloor.
+	FullTrafficSince *metav1.Time `json:"fullTrafficSince,omitempty"`
+}
+
+type ProbeResult struct {
+	Cluster string `json:"cluster"`
+	Step    int32  `json:"step"`
+	Name    string `json:"name"`
+	Pod     string `json:"pod,omitempty"`
+	Passed  bool   `json:"passed"`
+	// StatusCode is the HTTP status code the pod responded with, if it
+	// responded at all.
+	StatusCode int32  `json:"statusCode,omitempty"`
+	Latency    string `json:"latency,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// ReleaseScheduling records why a non-default scheduler picked the
+// release's clusters.
+type ReleaseScheduling struct {
+	Mode      ClusterSchedulerMode `json:"mode"`
+	Rationale string               `json:"rationale"`
+}
+
+// ReleaseETA estimates when a release will finish the step it is working
+// towards, and the whole rollout, based on how fast it has been converging
+// so far. It is only present while a step is in progress.
+type ReleaseETA struct {
+	Step          int32       `json:"step"`
+	StepStartedAt metav1.Time `json:"stepStartedAt"`
+	// InitialStepProgress and StepProgress are how far along the step was
+	// when it started and is now, in percent.
+	InitialStepProgress int32 `json:"initialStepProgress"`
+	StepProgress        int32 `json:"stepProgress"`
+
+	StepCompletion    *metav1.Time `json:"stepCompletion,omitempty"`
+	RolloutCompletion *metav1.Time `json:"rolloutCompletion,omitempty"`
 }
 
 type AchievedStep struct {
@@ -211,6 +509,10 @@ const (
 	ReleaseConditionTypeStrategyExecuted ReleaseConditionType = "StrategyExecuted"
 	ReleaseConditionTypeComplete         ReleaseConditionType = "Complete"
 	ReleaseConditionTypeBlocked          ReleaseConditionType = "Blocked"
+	ReleaseConditionTypeCapacityOverride ReleaseConditionType = "CapacityOverridden"
+	// ReleaseConditionTypeQueued is True while a release waits to start
+	// rolling out, with the reason it's waiting for.
+	ReleaseConditionTypeQueued ReleaseConditionType = "Queued"
 )
 
 type ReleaseCondition struct {
@@ -232,29 +534,413 @@ type ReleaseEnvironment struct {
 	ClusterRequirements ClusterRequirements `json:"clusterRequirements"`
 
 	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+
+	// overrides the image of containers in the chart's Deployment, for
+	// hotfixes where only the container image changes
+	ImageOverride *ImageOverride `json:"imageOverride,omitempty"`
+
+	// PrePullImages has Shipper pull the chart's images on every node of
+	// the target clusters before giving the release any capacity, so the
+	// first capacity step doesn't wait on cold image pulls.
+	PrePullImages bool `json:"prePullImages,omitempty"`
+
+	// TopologySpread has Shipper spread the pods of the chart's
+	// Deployments across the zones of each target cluster, so charts
+	// don't need to.
+	TopologySpread *TopologySpread `json:"topologySpread,omitempty"`
+
+	// ReadinessBarriers are kinds of objects in the chart that must be
+	// ready before Shipper installs any object of a kind that comes
+	// after them in the install order, such as a Job running database
+	// migrations before the Deployment.
+	ReadinessBarriers []string `json:"readinessBarriers,omitempty"`
+
+	// ValuesOverlays layer values over Values for an environment and for
+	// each cluster the chart is rendered in.
+	ValuesOverlays *ValuesOverlays `json:"valuesOverlays,omitempty"`
+
+	// ValuesFrom sets chart values from secret stores when the chart is
+	// rendered in each cluster, so they are never stored in Shipper's
+	// objects.
+	ValuesFrom []ValueSource `json:"valuesFrom,omitempty"`
+
+	// AdditionalCharts are installed along with Chart, in the same
+	// clusters and as part of the same release, so they are rolled out
+	// together with it by its strategy.
+	AdditionalCharts []AdditionalChart `json:"additionalCharts,omitempty"`
+
+	// DependsOn names Applications in the same namespace whose latest
+	// release must be complete before this release's strategy can move
+	// past the step it has achieved.
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// ScaleDownPolicy decides which pods go when the release's capacity
+	// is lowered. Empty means ScaleDownPolicyReplicaCount.
+	ScaleDownPolicy ScaleDownPolicy `json:"scaleDownPolicy,omitempty"`
+
+	// WorkloadKind is what the release runs. Empty means
+	// WorkloadKindService.
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+}
+
+type WorkloadKind string
+
+const (
+	// WorkloadKindService releases run long-lived Deployments, whose
+	// replicas are scaled by capacity and get traffic.
+	WorkloadKindService WorkloadKind = "Service"
+	// WorkloadKindJob releases run a single Job to completion. Capacity
+	// scales its parallelism, the last step is only achieved once it
+	// completes, and traffic is left alone.
+	WorkloadKindJob WorkloadKind = "Job"
+)
+
+type ScaleDownPolicy string
+
+const (
+	// ScaleDownPolicyReplicaCount only lowers the replica count of a
+	// Deployment, leaving the choice of pods to its ReplicaSet.
+	ScaleDownPolicyReplicaCount ScaleDownPolicy = "ReplicaCount"
+	// ScaleDownPolicyUnhealthyFirst gives the pods that should go a low
+	// pod deletion cost before lowering the replica count: pods that
+	// aren't ready first, and then the oldest ones.
+	ScaleDownPolicyUnhealthyFirst ScaleDownPolicy = "UnhealthyFirst"
+)
+
+// AdditionalChart is a chart installed along with a release's main chart.
+// Its capacity and traffic are those of the main chart, so it can't have
+// Deployments or production LB Services of its own.
+type AdditionalChart struct {
+	// Name identifies the chart in the release and in installation
+	// statuses. It must be unique among the release's charts.
+	Name   string      `json:"name"`
+	Chart  Chart       `json:"chart"`
+	Values ChartValues `json:"values,omitempty"`
+}
+
+// ValueSource sets the chart value at Path from exactly one of its sources.
+type ValueSource struct {
+	// Path is the dot-separated path of the value to set, like
+	// "database.password".
+	Path string `json:"path"`
+	// SecretKeyRef reads the value from a key of a Secret in the
+	// release's namespace, in the cluster the chart is rendered in.
+	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty"`
+	// External reads the value from an external secret store that
+	// shipper-app was configured with.
+	External *ExternalValueRef `json:"external,omitempty"`
+}
+
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+type ExternalValueRef struct {
+	// Store is the name of the store, like "vault".
+	Store string `json:"store"`
+	// Key identifies the value in the store, in the store's own format.
+	Key string `json:"key"`
+}
+
+// ValuesOverlays are merged over the base values in order: first the overlay
+// for Environment, then the overlay for the cluster the chart is rendered in.
+// Maps are merged key by key, anything else is replaced.
+type ValuesOverlays struct {
+	// Environment picks the overlay from Environments that applies, for
+	// example "staging" or "production".
+	Environment  string          `json:"environment,omitempty"`
+	Environments []ValuesOverlay `json:"environments,omitempty"`
+	Clusters     []ValuesOverlay `json:"clusters,omitempty"`
+}
+
+type ValuesOverlay struct {
+	Name   string      `json:"name"`
+	Values ChartValues `json:"values"`
+}
+
+// TopologySpread spreads the pods of a release's Deployments across the
+// zones of every cluster it's installed in, by preferring not to schedule
+// them in zones that have more of them than others already.
+type TopologySpread struct {
+	// TopologyKey is the node label zones are told apart by. Defaults to
+	// whichever of ZoneLabel and DeprecatedZoneLabel the cluster's nodes
+	// have.
+	TopologyKey string `json:"topologyKey,omitempty"`
+}
+
+type ImageOverride struct {
+	// Container is the name of the container in the Deployment whose image
+	// should be overridden. If empty, only the first container is.
+	Container string `json:"container,omitempty"`
+	Image     string `json:"image"`
 }
 
 type ClusterRequirements struct {
 	// it is an error to not specify any regions
 	Regions      []RegionRequirement `json:"regions"`
 	Capabilities []string            `json:"capabilities,omitempty"`
+	// Scheduler selects how Shipper picks among the clusters that satisfy
+	// the region and capability requirements. Empty means the default
+	// preference list.
+	Scheduler ClusterSchedulerMode `json:"scheduler,omitempty"`
+	// Spread splits the chart's replica count across the chosen clusters
+	// of each region. When absent, every cluster runs the full replica
+	// count.
+	Spread *ClusterSpread `json:"spread,omitempty"`
+	// ApplicationAffinity lists Applications this one must share clusters
+	// with, and ApplicationAntiAffinity those it must never share a
+	// cluster with. Entries are Application names, optionally prefixed
+	// with "<namespace>/" for Applications in other namespaces.
+	ApplicationAffinity     []string `json:"applicationAffinity,omitempty"`
+	ApplicationAntiAffinity []string `json:"applicationAntiAffinity,omitempty"`
+	// RolloutOrder sets how the chosen clusters move through each step of
+	// the strategy. When absent, all of them move at the same time.
+	RolloutOrder *ClusterRolloutOrder `json:"rolloutOrder,omitempty"`
+	// Platforms lists the platforms, as "<os>/<arch>", the release's
+	// images can run on. When present, only clusters whose nodes all run
+	// one of them are chosen.
+	Platforms []string `json:"platforms,omitempty"`
 }
 
+type ClusterRolloutOrder struct {
+	Mode ClusterRolloutMode `json:"mode"`
+	// Clusters lists the clusters to roll out to first, in order. The
+	// others follow by their scheduler rolloutPriority, and then by name.
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+type ClusterRolloutMode string
+
+const (
+	// ClusterRolloutParallel moves all clusters through a step at the
+	// same time.
+	ClusterRolloutParallel ClusterRolloutMode = "Parallel"
+	// ClusterRolloutSequential only moves a cluster through a step once
+	// the clusters before it have achieved it.
+	ClusterRolloutSequential ClusterRolloutMode = "Sequential"
+)
+
+type ClusterSpread struct {
+	Strategy ClusterSpreadStrategy `json:"strategy"`
+	// MaxReplicasPerCluster is how many replicas the BinPack strategy puts
+	// in a cluster before moving on to the next one.
+	MaxReplicasPerCluster *int32 `json:"maxReplicasPerCluster,omitempty"`
+	// Pinned lists the clusters used by the Pinned strategy.
+	Pinned []PinnedCluster `json:"pinned,omitempty"`
+}
+
+type ClusterSpreadStrategy string
+
+const (
+	// ClusterSpreadEven splits replicas evenly between clusters.
+	ClusterSpreadEven ClusterSpreadStrategy = "Even"
+	// ClusterSpreadWeighted splits replicas in proportion to each
+	// cluster's scheduler weight.
+	ClusterSpreadWeighted ClusterSpreadStrategy = "Weighted"
+	// ClusterSpreadBinPack fills clusters up to MaxReplicasPerCluster in
+	// preference order, leaving out clusters it does not need.
+	ClusterSpreadBinPack ClusterSpreadStrategy = "BinPack"
+	// ClusterSpreadPinned uses exactly the clusters and replica counts
+	// listed in Pinned.
+	ClusterSpreadPinned ClusterSpreadStrategy = "Pinned"
+)
+
+type PinnedCluster struct {
+	Name string `json:"name"`
+	// Replicas defaults to the chart's replica count.
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+type ClusterSchedulerMode string
+
+const (
+	// ClusterSchedulerModeCostAware prefers the cheapest clusters
+	// according to their costTier and pricePerReplicaHour hints, falling
+	// back to the preference list between equally priced clusters.
+	ClusterSchedulerModeCostAware ClusterSchedulerMode = "CostAware"
+)
+
 type RegionRequirement struct {
 	Name     string `json:"name"`
 	Replicas *int32 `json:"replicas,omitempty"`
 }
 
 type RolloutStrategy struct {
-	Steps []RolloutStrategyStep `json:"steps"`
+	// Preset names one of Shipper's built-in strategies. Its steps are
+	// filled in by Shipper when Steps is empty.
+	Preset string                `json:"preset,omitempty"`
+	Steps  []RolloutStrategyStep `json:"steps,omitempty"`
+
+	// StrategyRef makes the strategy the one of a Strategy object.
+	// Releases get a copy of it when they're created, so it can be
+	// changed without affecting them.
+	StrategyRef *StrategyRef `json:"strategyRef,omitempty"`
+
+	// PartialFinalStep allows the last step to leave capacity or traffic
+	// to the incumbent, or to not give all of it to the contender.
+	// Strategies are otherwise required to end with the contender taking
+	// over completely.
+	PartialFinalStep bool `json:"partialFinalStep,omitempty"`
+
+	// MaxUnavailableClusters is how many of a release's clusters, as a
+	// count or a percentage rounded down, may fail to converge without
+	// keeping a step from being achieved. Defaults to 0.
+	MaxUnavailableClusters *intstr.IntOrString `json:"maxUnavailableClusters,omitempty"`
+
+	// IncumbentFloor keeps the incumbent from being scaled down below a
+	// minimum capacity until the contender has had all the traffic for a
+	// while.
+	IncumbentFloor *IncumbentFloor `json:"incumbentFloor,omitempty"`
+
+	// IncumbentStandby keeps the incumbent installed at a small capacity,
+	// with no traffic, for a while after the contender completes, so that
+	// rolling back to it is near instant.
+	IncumbentStandby *IncumbentStandby `json:"incumbentStandby,omitempty"`
+
+	// Experiment makes the rollout a long-running A/B experiment: the
+	// traffic split of the last step stays for as long as the release
+	// is the latest one, with every user sticking to the release they
+	// were bucketed into, and the release never completes.
+	Experiment *RolloutExperiment `json:"experiment,omitempty"`
+
+	// Cutover points Ingresses and DNS records at the release's own
+	// Service once it has all the traffic, for charts with a Service per
+	// release rather than one shared by all of them.
+	Cutover *RolloutCutover `json:"cutover,omitempty"`
+}
+
+type RolloutCutover struct {
+	// Ingresses names Ingresses, kept outside of the chart, whose
+	// backends are pointed at the release's Service.
+	Ingresses []string `json:"ingresses,omitempty"`
+	// Hostnames are moved to the release's Service from the ones of the
+	// releases before it, for external-dns to point DNS records at.
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+type RolloutExperiment struct {
+	// Bucketing is how requests are assigned to a release.
+	Bucketing ExperimentBucketing `json:"bucketing"`
+}
+
+// ExperimentBucketing assigns requests to a release by hashing one of their
+// headers or cookies, so that the same user is always sent to the same
+// release. Exactly one of Header or Cookie must be set.
+type ExperimentBucketing struct {
+	Header string `json:"header,omitempty"`
+	Cookie string `json:"cookie,omitempty"`
+}
+
+type StrategyRef struct {
+	// Name is the name of the Strategy, looked up in the namespace of
+	// the application and then in GlobalStrategyNamespace.
+	Name string `json:"name"`
+	// Namespace is the namespace a release's strategy was copied from.
+	// It's set by Shipper.
+	Namespace string `json:"namespace,omitempty"`
+	// Generation is the generation of the Strategy a release's strategy
+	// was copied from. It's set by Shipper.
+	Generation int64 `json:"generation,omitempty"`
+}
+
+type IncumbentFloor struct {
+	// Capacity is the least capacity the incumbent keeps, either as a
+	// percentage or as an absolute number of replicas.
+	Capacity intstr.IntOrString `json:"capacity"`
+	// SoakDuration is how long the contender needs to have had all the
+	// traffic before the incumbent can go below Capacity.
+	SoakDuration metav1.Duration `json:"soakDuration"`
+}
+
+type IncumbentStandby struct {
+	// Capacity is the capacity the incumbent is kept at, either as a
+	// percentage or as an absolute number of replicas.
+	Capacity intstr.IntOrString `json:"capacity"`
+	// Retention is how long the incumbent stays on standby once the
+	// contender completes, after which the janitor scales it to zero.
+	Retention metav1.Duration `json:"retention"`
 }
 
 type RolloutStrategyStep struct {
 	Name     string                   `json:"name"`
 	Capacity RolloutStrategyStepValue `json:"capacity"`
 	Traffic  RolloutStrategyStepValue `json:"traffic"`
+
+	// ApprovalRequired prevents Shipper from advancing a release to this
+	// step until someone in Approvers has approved it.
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
+	// Approvers lists the users, or groups prefixed with "group:", allowed
+	// to approve this step. Anyone can approve it if empty.
+	Approvers []string `json:"approvers,omitempty"`
+	// Order controls whether the contender gets capacity or traffic
+	// first during this step. Defaults to RolloutStrategyStepCapacityFirst.
+	Order RolloutStrategyStepOrder `json:"order,omitempty"`
+
+	// PreHooks are Jobs run in every target cluster before Shipper
+	// changes capacity or traffic for this step, and PostHooks Jobs run
+	// once the step has been achieved. The step is only achieved once
+	// all of them have succeeded.
+	PreHooks  []StepHook `json:"preHooks,omitempty"`
+	PostHooks []StepHook `json:"postHooks,omitempty"`
+
+	// Probes are HTTP checks run against the contender's pods in every
+	// target cluster. Shipper only gives the contender more traffic in
+	// this step once they pass.
+	Probes []StepProbe `json:"probes,omitempty"`
+
+	// ShadowTraffic is the percentage of the application's production
+	// traffic mirrored to the contender during this step. Responses to
+	// mirrored requests are thrown away, so the contender can be
+	// validated with real requests before it serves any. It needs a
+	// traffic backend that can mirror requests.
+	ShadowTraffic int32 `json:"shadowTraffic,omitempty"`
+
+	// MaxUnavailable is how much of the incumbent's capacity, as a count
+	// of replicas or a percentage, the available replicas of both
+	// releases together may fall short of during this step. The
+	// incumbent is only scaled down as far as the available replicas of
+	// the contender make up for it.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
+type StepProbe struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Port is the container port to send requests to.
+	Port int32 `json:"port"`
+	// Scheme is either "http", the default, or "https".
+	Scheme string `json:"scheme,omitempty"`
+	// ExpectedStatus is the HTTP status code the contender must respond
+	// with. Defaults to 200.
+	ExpectedStatus int32 `json:"expectedStatus,omitempty"`
+	// LatencySLO is how long the contender may take to respond, if set.
+	LatencySLO *metav1.Duration `json:"latencySLO,omitempty"`
+}
+
+type StepHook struct {
+	Name string `json:"name"`
+	// Template is the name of a Job in the release's chart, annotated
+	// with StepHookAnnotation. Exactly one of Template or Job must be set.
+	Template string `json:"template,omitempty"`
+	// Job is the spec of the Job to run.
+	Job *batchv1.JobSpec `json:"job,omitempty"`
+}
+
+type RolloutStrategyStepOrder string
+
+const (
+	// RolloutStrategyStepCapacityFirst waits for the contender to achieve
+	// its capacity before shifting any traffic to it.
+	RolloutStrategyStepCapacityFirst RolloutStrategyStepOrder = "capacityFirst"
+	// RolloutStrategyStepTrafficFirst shifts traffic to the contender
+	// before scaling it up, so it warms up with the pods it already has.
+	RolloutStrategyStepTrafficFirst RolloutStrategyStepOrder = "trafficFirst"
+	// RolloutStrategyStepParallel changes capacity and traffic for both
+	// releases at the same time.
+	RolloutStrategyStepParallel RolloutStrategyStepOrder = "parallel"
+)
+
 type RolloutStrategyStepValue struct {
 	Incumbent int32 `json:"incumbent"`
 	Contender int32 `json:"contender"`
@@ -265,8 +951,24 @@ type TargetConditionType string
 const (
 	TargetConditionTypeOperational TargetConditionType = "Operational"
 	TargetConditionTypeReady       TargetConditionType = "Ready"
+	// TargetConditionTypeHealthy tells if the objects a target manages
+	// are ready in the cluster. It's informational: targets can be Ready
+	// while their objects are still coming up.
+	TargetConditionTypeHealthy TargetConditionType = "Healthy"
+	// TargetConditionTypeProgressing tells if a target is on its way to
+	// being Ready, and how far along it is.
+	TargetConditionTypeProgressing TargetConditionType = "Progressing"
 )
 
+// TargetSyncStatus tells when a target controller last looked at a target
+// object, and when that last changed its status. A recent LastSyncTime with
+// an old LastTransitionTime means the target is stuck; an old LastSyncTime
+// means its status is stale.
+type TargetSyncStatus struct {
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	LastSyncTime       *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
 type TargetCondition struct {
 	Type               TargetConditionType    `json:"type"`
 	Status             corev1.ConditionStatus `json:"status"`
@@ -299,12 +1001,46 @@ type InstallationTargetList struct {
 }
 
 type InstallationTargetStatus struct {
+	TargetSyncStatus `json:",inline"`
+
 	Conditions []TargetCondition `json:"conditions,omitempty"`
 
+	// ImagesPrePulled is set once the chart's images have been pulled on
+	// every node, for targets with PrePullImages.
+	ImagesPrePulled bool `json:"imagesPrePulled,omitempty"`
+
+	// Charts has the installation status of every chart of the target,
+	// the main chart first.
+	Charts []ChartInstallationStatus `json:"charts,omitempty"`
+
+	// Hooks has the outcome of every Helm hook of the target's charts
+	// that has run to completion, so they're only ever run once.
+	Hooks []ChartHookStatus `json:"hooks,omitempty"`
+
 	// Deprecated
 	Clusters []*ClusterInstallationStatus `json:"clusters,omitempty"`
 }
 
+type ChartInstallationStatus struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Installed is set once every object the chart rendered to has been
+	// installed.
+	Installed bool   `json:"installed"`
+	Message   string `json:"message,omitempty"`
+}
+
+// ChartHookStatus is the outcome of an object in a chart annotated as a Helm
+// hook.
+type ChartHookStatus struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Hook is the Helm hook the object ran for, e.g. pre-install.
+	Hook      string `json:"hook"`
+	Succeeded bool   `json:"succeeded"`
+	Message   string `json:"message,omitempty"`
+}
+
 // Deprecated
 type ClusterInstallationStatus struct {
 	Name       string                         `json:"name"`
@@ -321,9 +1057,24 @@ type ClusterInstallationCondition struct {
 }
 
 type InstallationTargetSpec struct {
-	CanOverride bool        `json:"canOverride"`
-	Chart       Chart       `json:"chart"`
-	Values      ChartValues `json:"values,omitempty"`
+	CanOverride   bool           `json:"canOverride"`
+	Chart         Chart          `json:"chart"`
+	Values        ChartValues    `json:"values,omitempty"`
+	ImageOverride *ImageOverride `json:"imageOverride,omitempty"`
+	PrePullImages bool           `json:"prePullImages,omitempty"`
+	ValuesFrom    []ValueSource  `json:"valuesFrom,omitempty"`
+
+	TopologySpread *TopologySpread `json:"topologySpread,omitempty"`
+
+	ReadinessBarriers []string `json:"readinessBarriers,omitempty"`
+
+	AdditionalCharts []AdditionalChart `json:"additionalCharts,omitempty"`
+
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+
+	// Adopt makes the installation target take over the objects it
+	// names instead of installing its chart.
+	Adopt *Adoption `json:"adopt,omitempty"`
 
 	// Deprecated
 	Clusters []string `json:"clusters,omitempty"`
@@ -356,10 +1107,38 @@ type CapacityTargetSpec struct {
 	Percent           int32 `json:"percent"`
 	TotalReplicaCount int32 `json:"totalReplicaCount"`
 
+	// Application and Release name the release whose Deployments are
+	// scaled. Deployments are selected by their AppLabel and ReleaseLabel
+	// matching them, so the target's own labels never change what it
+	// scales. Targets created before these existed have their labels used
+	// instead.
+	Application string `json:"application,omitempty"`
+	Release     string `json:"release,omitempty"`
+
+	// Workloads lists the Deployments of a release that are scaled
+	// together, each to Percent of its own total replica count.
+	// TotalReplicaCount is then the sum of theirs. When empty, the release
+	// must have exactly one Deployment, scaled to Percent of
+	// TotalReplicaCount.
+	Workloads []CapacityTargetWorkload `json:"workloads,omitempty"`
+
+	// ScaleDownPolicy decides which pods go when Percent is lowered.
+	ScaleDownPolicy ScaleDownPolicy `json:"scaleDownPolicy,omitempty"`
+
+	// WorkloadKind is WorkloadKindJob for targets that scale the
+	// parallelism of their release's Job instead of Deployments.
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+
 	// Deprecated
 	Clusters []ClusterCapacityTarget `json:"clusters,omitempty"`
 }
 
+type CapacityTargetWorkload struct {
+	// Name is the name of the Deployment.
+	Name              string `json:"name"`
+	TotalReplicaCount int32  `json:"totalReplicaCount"`
+}
+
 // Deprecated
 type ClusterCapacityTarget struct {
 	Name              string `json:"name"`
@@ -368,17 +1147,35 @@ type ClusterCapacityTarget struct {
 }
 
 type CapacityTargetStatus struct {
+	TargetSyncStatus `json:",inline"`
+
 	ObservedGeneration int64             `json:"observedGeneration,omitempty"`
 	AvailableReplicas  int32             `json:"availableReplicas"`
 	AchievedPercent    int32             `json:"achievedPercent"`
 	SadPods            []PodStatus       `json:"sadPods,omitempty"`
 	Conditions         []TargetCondition `json:"conditions,omitempty"`
 
-	// Deprecated
+	// Workloads has the status of each of the spec's workloads. The
+	// fields above add them up: AchievedPercent is the lowest of theirs,
+	// and the target is only Ready once all of them are.
+	Workloads []WorkloadCapacityStatus `json:"workloads,omitempty"`
+
+	// Deprecated: use the fields above. Only filled in by shipper-app
+	// started with -cluster-name, and will be removed in the next release.
 	Clusters []ClusterCapacityStatus `json:"clusters,omitempty"`
 }
 
-// Deprecated
+type WorkloadCapacityStatus struct {
+	Name              string            `json:"name"`
+	AvailableReplicas int32             `json:"availableReplicas"`
+	AchievedPercent   int32             `json:"achievedPercent"`
+	Conditions        []TargetCondition `json:"conditions,omitempty"`
+}
+
+// Deprecated: ClusterCapacityStatus is the per-cluster status CapacityTargets
+// had when they lived in the management cluster. Its fields mirror
+// AvailableReplicas, AchievedPercent, SadPods and Conditions in
+// CapacityTargetStatus.
 type ClusterCapacityStatus struct {
 	Name              string                     `json:"name"`
 	AvailableReplicas int32                      `json:"availableReplicas"`
@@ -428,10 +1225,17 @@ type TrafficTargetList struct {
 }
 
 type TrafficTargetStatus struct {
+	TargetSyncStatus `json:",inline"`
+
 	ObservedGeneration int64             `json:"observedGeneration,omitempty"`
 	AchievedTraffic    uint32            `json:"achievedTraffic"`
 	Conditions         []TargetCondition `json:"conditions"`
 
+	// DrainingSince is when pods of the release were last taken out of
+	// traffic. The target isn't Ready until the traffic controller's
+	// drain period has passed since then.
+	DrainingSince *metav1.Time `json:"drainingSince,omitempty"`
+
 	// Deprecated
 	Clusters []*ClusterTrafficStatus `json:"clusters,omitempty"`
 }
@@ -443,7 +1247,8 @@ type ClusterTrafficStatus struct {
 	Conditions      []ClusterTrafficCondition `json:"conditions"`
 }
 
-// Deprecated
+// ClusterConditionType is the type of the conditions of Clusters, as well
+// as of the deprecated per-cluster conditions of targets.
 type ClusterConditionType string
 
 // Deprecated
@@ -459,6 +1264,26 @@ type TrafficTargetSpec struct {
 	// apimachinery intstr for percentages?
 	Weight uint32 `json:"weight"`
 
+	// Backend is the traffic shifting backend to be used in the
+	// application cluster. It is defined by the Cluster the target was
+	// scheduled on. Defaults to pod-label.
+	Backend string `json:"backend,omitempty"`
+
+	// ShadowWeight is the percentage of the application's traffic
+	// mirrored to the release. It's not omitted when empty, so that
+	// patching weights takes the mirroring away too.
+	ShadowWeight uint32 `json:"shadowWeight"`
+
+	// Bucketing makes the traffic split sticky while the release is part
+	// of an experiment. It's not omitted when empty either.
+	Bucketing *ExperimentBucketing `json:"bucketing"`
+
+	// TrafficDisabled is set while the cluster the target was scheduled
+	// on is out of traffic for maintenance. Its weight is still shifted
+	// inside the cluster, but none of it is published to external load
+	// balancers, so they send the traffic to other clusters.
+	TrafficDisabled bool `json:"trafficDisabled,omitempty"`
+
 	// Deprecated
 	Clusters []ClusterTrafficTarget `json:"clusters,omitempty"`
 }
@@ -473,14 +1298,44 @@ type ClusterTrafficTarget struct {
 type ReleaseStrategyStatus struct {
 	State    ReleaseStrategyState    `json:"state,omitempty"`
 	Clusters []ClusterStrategyStatus `json:"clusters,omitempty"`
+	// UnavailableClusters lists the clusters that haven't converged to
+	// the current step, but were tolerated by MaxUnavailableClusters.
+	UnavailableClusters []string `json:"unavailableClusters,omitempty"`
+	// ClustersConverged tells, for each kind of target, in how many of
+	// the release's clusters it has converged to the current step.
+	ClustersConverged *ClustersConverged `json:"clustersConverged,omitempty"`
+
+	// StepHistory records when each step the release was asked to move
+	// to was started and achieved, in the order they were visited.
+	StepHistory []ReleaseStepHistory `json:"stepHistory,omitempty"`
 
 	// Deprecated
 	Conditions []ReleaseStrategyCondition `json:"conditions,omitempty"`
 }
 
+// ClustersConverged counts the clusters each kind of target has converged
+// in, out of all of a release's clusters, as in "7/12".
+type ClustersConverged struct {
+	Installation string `json:"installation"`
+	Capacity     string `json:"capacity"`
+	Traffic      string `json:"traffic"`
+}
+
+type ReleaseStepHistory struct {
+	Step       int32        `json:"step"`
+	Name       string       `json:"name"`
+	StartedAt  metav1.Time  `json:"startedAt"`
+	AchievedAt *metav1.Time `json:"achievedAt,omitempty"`
+}
+
 type ClusterStrategyStatus struct {
 	Name       string                     `json:"name"`
 	Conditions []ReleaseStrategyCondition `json:"conditions"`
+
+	// AchievedTraffic is the traffic weight the release's traffic target
+	// last reported serving in the cluster. It's zero while the cluster is
+	// out of traffic, and missing until the target was seen at all.
+	AchievedTraffic *uint32 `json:"achievedTraffic,omitempty"`
 }
 
 type ReleaseStrategyState struct {
@@ -562,6 +1417,175 @@ const (
 	RolloutBlockReason = "RolloutsBlocked"
 )
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A FleetCapacityOverride scales every release of some applications, or of a
+// whole namespace, to the same capacity in all clusters, no matter what their
+// strategy says. It's meant for incident response: deleting it hands capacity
+// back to the strategy.
+type FleetCapacityOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FleetCapacityOverrideSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type FleetCapacityOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []FleetCapacityOverride `json:"items"`
+}
+
+type FleetCapacityOverrideSpec struct {
+	// Percent is the capacity overridden releases are scaled to.
+	Percent int32 `json:"percent"`
+
+	// Applications lists the applications whose releases are
+	// overridden. Every application in the namespace is when it's empty.
+	Applications []string `json:"applications,omitempty"`
+
+	Message string             `json:"message"`
+	Author  RolloutBlockAuthor `json:"author"`
+}
+
+const (
+	FleetCapacityOverrideReason = "FleetCapacityOverride"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A Policy has a policy engine evaluate the releases in its namespace, or in
+// every namespace when it's in the global policy namespace, before they're
+// installed and before they move to each step. Releases violating it are
+// blocked until they don't anymore.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type PolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Policy `json:"items"`
+}
+
+type PolicySpec struct {
+	// Stages are the points of a rollout the policy is evaluated at.
+	// It's evaluated at all of them when empty.
+	Stages []PolicyStage `json:"stages,omitempty"`
+
+	// Applications lists the applications whose releases are evaluated.
+	// Every application in the namespace is when it's empty.
+	Applications []string `json:"applications,omitempty"`
+
+	OPA *OPAPolicy `json:"opa"`
+}
+
+// OPAPolicy is a rule loaded into Open Policy Agent, through a bundle or
+// otherwise.
+type OPAPolicy struct {
+	// Path is the path of the rule in OPA's data API, such as
+	// "shipper/deny".
+	Path string `json:"path"`
+}
+
+type PolicyStage string
+
+const (
+	// PolicyStageInstallation is before a release is installed, with
+	// the objects rendered from its chart.
+	PolicyStageInstallation PolicyStage = "Installation"
+	// PolicyStageStepAdvancement is before a release moves past step 0
+	// to each of its steps.
+	PolicyStageStepAdvancement PolicyStage = "StepAdvancement"
+
+	PolicyViolationReason = "PolicyViolation"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// An ApplicationTemplate is a rollout shape, like a strategy, cluster
+// requirements and default values, that applications follow by referencing
+// it. Applications in its namespace can follow it, and applications in
+// every namespace when it's in the global application template namespace.
+type ApplicationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApplicationTemplateSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ApplicationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ApplicationTemplate `json:"items"`
+}
+
+type ApplicationTemplateSpec struct {
+	// Parameters are what applications following the template fill in.
+	// Every "$(name)" in the strings of Template is replaced with the
+	// value of parameter name.
+	Parameters []ApplicationTemplateParameter `json:"parameters,omitempty"`
+
+	// Template is the environment of the applications following the
+	// template, before their own template is layered over it. It doesn't
+	// need to be complete, as long as they fill in the rest.
+	Template ReleaseEnvironment `json:"template"`
+}
+
+type ApplicationTemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Default is the value of the parameter for applications that don't
+	// set it. Applications must set parameters without one.
+	Default *string `json:"default,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// An ApplicationDefault holds what applications in its namespace ship with
+// when neither they nor the ApplicationTemplate they follow say otherwise.
+// Only the one named ApplicationDefaultName is used.
+type ApplicationDefault struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ApplicationDefaultSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ApplicationDefaultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ApplicationDefault `json:"items"`
+}
+
+type ApplicationDefaultSpec struct {
+	// Strategy is the strategy of releases of applications that don't
+	// have one.
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+	// ClusterRequirements are the cluster requirements of releases of
+	// applications that don't ask for any region.
+	ClusterRequirements *ClusterRequirements `json:"clusterRequirements,omitempty"`
+}
+
 func (ss *StrategyState) UnmarshalJSON(b []byte) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
@@ -574,3 +1598,162 @@ func (ss *StrategyState) UnmarshalJSON(b []byte) error {
 	}
 	return nil
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A Strategy is a rollout strategy applications can share by referring to
+// it in theirs.
+type Strategy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RolloutStrategy `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type StrategyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Strategy `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A ShipperConfig holds tunables of the management controllers, that they
+// pick up as soon as it changes, without being restarted. Fields it leaves
+// out keep the value of the flag they override. Only the one named
+// ShipperConfigName is used.
+type ShipperConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ShipperConfigSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ShipperConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ShipperConfig `json:"items"`
+}
+
+type ShipperConfigSpec struct {
+	// Events decides which events controllers emit.
+	Events *EventsConfig `json:"events,omitempty"`
+	// DefaultStrategy is the strategy of releases of applications that
+	// have none, and whose namespace has no ApplicationDefault with one.
+	DefaultStrategy *RolloutStrategy `json:"defaultStrategy,omitempty"`
+	// GitOpsInterval is how often Applications are synced from the gitops
+	// repository. Overrides -gitops-interval.
+	GitOpsInterval *metav1.Duration `json:"gitopsInterval,omitempty"`
+	// ChartRepositories decides how charts are fetched.
+	ChartRepositories *ChartRepositoriesConfig `json:"chartRepositories,omitempty"`
+	// Notifications decides who's told about releases.
+	Notifications *NotificationsConfig `json:"notifications,omitempty"`
+}
+
+type ChartRepositoriesConfig struct {
+	// MaxConcurrentRequests is how many requests can be made to chart
+	// repositories at once, or 0 for no limit. Overrides
+	// -chart-repo-max-requests.
+	MaxConcurrentRequests *int32 `json:"maxConcurrentRequests,omitempty"`
+}
+
+type NotificationsConfig struct {
+	// CompletionWebhookURL is the URL to post releases that complete
+	// their rollout to, or empty to post them nowhere. Overrides
+	// -completion-webhook-url.
+	CompletionWebhookURL *string `json:"completionWebhookURL,omitempty"`
+}
+
+type EventsConfig struct {
+	// Verbosity is one of "all", "warnings" or "none". Overrides
+	// -event-verbosity.
+	Verbosity string `json:"verbosity,omitempty"`
+	// DedupInterval is how long identical events for the same object are
+	// dropped for after the first one. Overrides -event-dedup-interval.
+	DedupInterval *metav1.Duration `json:"dedupInterval,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A ChartRepository tells Shipper how to fetch charts from a chart
+// repository that needs credentials, or a certificate authority of its own.
+// Charts from repositories without one are fetched anonymously.
+type ChartRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ChartRepositorySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ChartRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ChartRepository `json:"items"`
+}
+
+type ChartRepositorySpec struct {
+	// URL is the repoURL of the charts this applies to.
+	URL string `json:"url"`
+	// CABundle is a PEM encoded bundle of certificate authorities the
+	// repository's certificate is verified with, on top of the system's.
+	CABundle string `json:"caBundle,omitempty"`
+	// SecretName is the name of a Secret in Shipper's namespace holding
+	// the credentials to fetch charts with: a username and a password for
+	// basic authentication, and a tls.crt and tls.key for a client
+	// certificate. Either of them can be left out.
+	SecretName string `json:"secretName,omitempty"`
+	// IndexRefreshInterval is how often the repository's index is
+	// fetched again. Defaults to every 10 seconds.
+	IndexRefreshInterval *metav1.Duration `json:"indexRefreshInterval,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// A RolloutQuota limits how many applications of its namespace roll out at
+// once, and how many replicas their releases ask for together. Releases
+// over it wait, without being installed, until enough of the rollouts in
+// the namespace complete.
+type RolloutQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RolloutQuotaSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type RolloutQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []RolloutQuota `json:"items"`
+}
+
+type RolloutQuotaSpec struct {
+	// MaxConcurrentRollouts is how many applications in the namespace
+	// can have a release rolling out at once.
+	MaxConcurrentRollouts *int32 `json:"maxConcurrentRollouts,omitempty"`
+	// MaxReplicas is how many replicas the releases rolling out in the
+	// namespace can ask for, across all of their clusters together.
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+const (
+	RolloutQuotaExceededReason      = "RolloutQuotaExceeded"
+	RolloutQuotaUnsatisfiableReason = "RolloutQuotaUnsatisfiable"
+)
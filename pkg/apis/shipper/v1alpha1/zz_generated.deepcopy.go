@@ -5,8 +5,11 @@
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -25,6 +28,45 @@ func (in *AchievedStep) DeepCopy() *AchievedStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalChart) DeepCopyInto(out *AdditionalChart) {
+	*out = *in
+	out.Chart = in.Chart
+	out.Values = in.Values.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalChart.
+func (in *AdditionalChart) DeepCopy() *AdditionalChart {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalChart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Adoption) DeepCopyInto(out *Adoption) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Adoption.
+func (in *Adoption) DeepCopy() *Adoption {
+	if in == nil {
+		return nil
+	}
+	out := new(Adoption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Application) DeepCopyInto(out *Application) {
 	*out = *in
@@ -70,6 +112,92 @@ func (in *ApplicationCondition) DeepCopy() *ApplicationCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationDefault) DeepCopyInto(out *ApplicationDefault) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationDefault.
+func (in *ApplicationDefault) DeepCopy() *ApplicationDefault {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationDefault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationDefault) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationDefaultList) DeepCopyInto(out *ApplicationDefaultList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationDefault, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationDefaultList.
+func (in *ApplicationDefaultList) DeepCopy() *ApplicationDefaultList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationDefaultList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationDefaultList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationDefaultSpec) DeepCopyInto(out *ApplicationDefaultSpec) {
+	*out = *in
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterRequirements != nil {
+		in, out := &in.ClusterRequirements, &out.ClusterRequirements
+		*out = new(ClusterRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationDefaultSpec.
+func (in *ApplicationDefaultSpec) DeepCopy() *ApplicationDefaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationDefaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationList) DeepCopyInto(out *ApplicationList) {
 	*out = *in
@@ -112,6 +240,16 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 		**out = **in
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(ApplicationTemplateRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(Adoption)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -154,27 +292,26 @@ func (in *ApplicationStatus) DeepCopy() *ApplicationStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CapacityTarget) DeepCopyInto(out *CapacityTarget) {
+func (in *ApplicationTemplate) DeepCopyInto(out *ApplicationTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTarget.
-func (in *CapacityTarget) DeepCopy() *CapacityTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTemplate.
+func (in *ApplicationTemplate) DeepCopy() *ApplicationTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(CapacityTarget)
+	out := new(ApplicationTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CapacityTarget) DeepCopyObject() runtime.Object {
+func (in *ApplicationTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -182,13 +319,13 @@ func (in *CapacityTarget) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CapacityTargetList) DeepCopyInto(out *CapacityTargetList) {
+func (in *ApplicationTemplateList) DeepCopyInto(out *ApplicationTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CapacityTarget, len(*in))
+		*out = make([]ApplicationTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -196,18 +333,18 @@ func (in *CapacityTargetList) DeepCopyInto(out *CapacityTargetList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetList.
-func (in *CapacityTargetList) DeepCopy() *CapacityTargetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTemplateList.
+func (in *ApplicationTemplateList) DeepCopy() *ApplicationTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(CapacityTargetList)
+	out := new(ApplicationTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CapacityTargetList) DeepCopyObject() runtime.Object {
+func (in *ApplicationTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -215,111 +352,128 @@ func (in *CapacityTargetList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CapacityTargetSpec) DeepCopyInto(out *CapacityTargetSpec) {
+func (in *ApplicationTemplateParameter) DeepCopyInto(out *ApplicationTemplateParameter) {
 	*out = *in
-	if in.Clusters != nil {
-		in, out := &in.Clusters, &out.Clusters
-		*out = make([]ClusterCapacityTarget, len(*in))
-		copy(*out, *in)
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetSpec.
-func (in *CapacityTargetSpec) DeepCopy() *CapacityTargetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTemplateParameter.
+func (in *ApplicationTemplateParameter) DeepCopy() *ApplicationTemplateParameter {
 	if in == nil {
 		return nil
 	}
-	out := new(CapacityTargetSpec)
+	out := new(ApplicationTemplateParameter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CapacityTargetStatus) DeepCopyInto(out *CapacityTargetStatus) {
+func (in *ApplicationTemplateRef) DeepCopyInto(out *ApplicationTemplateRef) {
 	*out = *in
-	if in.SadPods != nil {
-		in, out := &in.SadPods, &out.SadPods
-		*out = make([]PodStatus, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]TargetCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTemplateRef.
+func (in *ApplicationTemplateRef) DeepCopy() *ApplicationTemplateRef {
+	if in == nil {
+		return nil
 	}
-	if in.Clusters != nil {
-		in, out := &in.Clusters, &out.Clusters
-		*out = make([]ClusterCapacityStatus, len(*in))
+	out := new(ApplicationTemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationTemplateSpec) DeepCopyInto(out *ApplicationTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]ApplicationTemplateParameter, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.Template.DeepCopyInto(&out.Template)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetStatus.
-func (in *CapacityTargetStatus) DeepCopy() *CapacityTargetStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationTemplateSpec.
+func (in *ApplicationTemplateSpec) DeepCopy() *ApplicationTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CapacityTargetStatus)
+	out := new(ApplicationTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Chart) DeepCopyInto(out *Chart) {
+func (in *CapacityTarget) DeepCopyInto(out *CapacityTarget) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Chart.
-func (in *Chart) DeepCopy() *Chart {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTarget.
+func (in *CapacityTarget) DeepCopy() *CapacityTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(Chart)
+	out := new(CapacityTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartValues.
-func (in ChartValues) DeepCopy() ChartValues {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(ChartValues)
-	in.DeepCopyInto(out)
-	return *out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Cluster) DeepCopyInto(out *Cluster) {
+func (in *CapacityTargetList) DeepCopyInto(out *CapacityTargetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CapacityTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cluster.
-func (in *Cluster) DeepCopy() *Cluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetList.
+func (in *CapacityTargetList) DeepCopy() *CapacityTargetList {
 	if in == nil {
 		return nil
 	}
-	out := new(Cluster)
+	out := new(CapacityTargetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Cluster) DeepCopyObject() runtime.Object {
+func (in *CapacityTargetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -327,25 +481,35 @@ func (in *Cluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterCapacityCondition) DeepCopyInto(out *ClusterCapacityCondition) {
+func (in *CapacityTargetSpec) DeepCopyInto(out *CapacityTargetSpec) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]CapacityTargetWorkload, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterCapacityTarget, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityCondition.
-func (in *ClusterCapacityCondition) DeepCopy() *ClusterCapacityCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetSpec.
+func (in *CapacityTargetSpec) DeepCopy() *CapacityTargetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterCapacityCondition)
+	out := new(CapacityTargetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterCapacityStatus) DeepCopyInto(out *ClusterCapacityStatus) {
+func (in *CapacityTargetStatus) DeepCopyInto(out *CapacityTargetStatus) {
 	*out = *in
+	in.TargetSyncStatus.DeepCopyInto(&out.TargetSyncStatus)
 	if in.SadPods != nil {
 		in, out := &in.SadPods, &out.SadPods
 		*out = make([]PodStatus, len(*in))
@@ -355,7 +519,21 @@ func (in *ClusterCapacityStatus) DeepCopyInto(out *ClusterCapacityStatus) {
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ClusterCapacityCondition, len(*in))
+		*out = make([]TargetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadCapacityStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterCapacityStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -363,99 +541,122 @@ func (in *ClusterCapacityStatus) DeepCopyInto(out *ClusterCapacityStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityStatus.
-func (in *ClusterCapacityStatus) DeepCopy() *ClusterCapacityStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetStatus.
+func (in *CapacityTargetStatus) DeepCopy() *CapacityTargetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterCapacityStatus)
+	out := new(CapacityTargetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterCapacityTarget) DeepCopyInto(out *ClusterCapacityTarget) {
+func (in *CapacityTargetWorkload) DeepCopyInto(out *CapacityTargetWorkload) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityTarget.
-func (in *ClusterCapacityTarget) DeepCopy() *ClusterCapacityTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityTargetWorkload.
+func (in *CapacityTargetWorkload) DeepCopy() *CapacityTargetWorkload {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterCapacityTarget)
+	out := new(CapacityTargetWorkload)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterInstallationCondition) DeepCopyInto(out *ClusterInstallationCondition) {
+func (in *Chart) DeepCopyInto(out *Chart) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstallationCondition.
-func (in *ClusterInstallationCondition) DeepCopy() *ClusterInstallationCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Chart.
+func (in *Chart) DeepCopy() *Chart {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterInstallationCondition)
+	out := new(Chart)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterInstallationStatus) DeepCopyInto(out *ClusterInstallationStatus) {
+func (in *ChartHookStatus) DeepCopyInto(out *ChartHookStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ClusterInstallationCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstallationStatus.
-func (in *ClusterInstallationStatus) DeepCopy() *ClusterInstallationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartHookStatus.
+func (in *ChartHookStatus) DeepCopy() *ChartHookStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterInstallationStatus)
+	out := new(ChartHookStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterList) DeepCopyInto(out *ClusterList) {
+func (in *ChartInstallationStatus) DeepCopyInto(out *ChartInstallationStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Cluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartInstallationStatus.
+func (in *ChartInstallationStatus) DeepCopy() *ChartInstallationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartInstallationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartRepositoriesConfig) DeepCopyInto(out *ChartRepositoriesConfig) {
+	*out = *in
+	if in.MaxConcurrentRequests != nil {
+		in, out := &in.MaxConcurrentRequests, &out.MaxConcurrentRequests
+		*out = new(int32)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
-func (in *ClusterList) DeepCopy() *ClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartRepositoriesConfig.
+func (in *ChartRepositoriesConfig) DeepCopy() *ChartRepositoriesConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterList)
+	out := new(ChartRepositoriesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartRepository) DeepCopyInto(out *ChartRepository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartRepository.
+func (in *ChartRepository) DeepCopy() *ChartRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterList) DeepCopyObject() runtime.Object {
+func (in *ChartRepository) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -463,103 +664,127 @@ func (in *ClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterRequirements) DeepCopyInto(out *ClusterRequirements) {
+func (in *ChartRepositoryList) DeepCopyInto(out *ChartRepositoryList) {
 	*out = *in
-	if in.Regions != nil {
-		in, out := &in.Regions, &out.Regions
-		*out = make([]RegionRequirement, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ChartRepository, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Capabilities != nil {
-		in, out := &in.Capabilities, &out.Capabilities
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRequirements.
-func (in *ClusterRequirements) DeepCopy() *ClusterRequirements {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartRepositoryList.
+func (in *ChartRepositoryList) DeepCopy() *ChartRepositoryList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterRequirements)
+	out := new(ChartRepositoryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChartRepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterSchedulerSettings) DeepCopyInto(out *ClusterSchedulerSettings) {
+func (in *ChartRepositorySpec) DeepCopyInto(out *ChartRepositorySpec) {
 	*out = *in
-	if in.Weight != nil {
-		in, out := &in.Weight, &out.Weight
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Identity != nil {
-		in, out := &in.Identity, &out.Identity
-		*out = new(string)
+	if in.IndexRefreshInterval != nil {
+		in, out := &in.IndexRefreshInterval, &out.IndexRefreshInterval
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSchedulerSettings.
-func (in *ClusterSchedulerSettings) DeepCopy() *ClusterSchedulerSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartRepositorySpec.
+func (in *ChartRepositorySpec) DeepCopy() *ChartRepositorySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterSchedulerSettings)
+	out := new(ChartRepositorySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartValues.
+func (in ChartValues) DeepCopy() ChartValues {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartValues)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
+func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
-	if in.Capabilities != nil {
-		in, out := &in.Capabilities, &out.Capabilities
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	in.Scheduler.DeepCopyInto(&out.Scheduler)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
-func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cluster.
+func (in *Cluster) DeepCopy() *Cluster {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterSpec)
+	out := new(Cluster)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Cluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+func (in *ClusterCapacityCondition) DeepCopyInto(out *ClusterCapacityCondition) {
 	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
-func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityCondition.
+func (in *ClusterCapacityCondition) DeepCopy() *ClusterCapacityCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStatus)
+	out := new(ClusterCapacityCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStrategyStatus) DeepCopyInto(out *ClusterStrategyStatus) {
+func (in *ClusterCapacityStatus) DeepCopyInto(out *ClusterCapacityStatus) {
 	*out = *in
+	if in.SadPods != nil {
+		in, out := &in.SadPods, &out.SadPods
+		*out = make([]PodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ReleaseStrategyCondition, len(*in))
+		*out = make([]ClusterCapacityCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -567,108 +792,97 @@ func (in *ClusterStrategyStatus) DeepCopyInto(out *ClusterStrategyStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStrategyStatus.
-func (in *ClusterStrategyStatus) DeepCopy() *ClusterStrategyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityStatus.
+func (in *ClusterCapacityStatus) DeepCopy() *ClusterCapacityStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStrategyStatus)
+	out := new(ClusterCapacityStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterTrafficCondition) DeepCopyInto(out *ClusterTrafficCondition) {
+func (in *ClusterCapacityTarget) DeepCopyInto(out *ClusterCapacityTarget) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficCondition.
-func (in *ClusterTrafficCondition) DeepCopy() *ClusterTrafficCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCapacityTarget.
+func (in *ClusterCapacityTarget) DeepCopy() *ClusterCapacityTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterTrafficCondition)
+	out := new(ClusterCapacityTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterTrafficStatus) DeepCopyInto(out *ClusterTrafficStatus) {
+func (in *ClusterCondition) DeepCopyInto(out *ClusterCondition) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ClusterTrafficCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficStatus.
-func (in *ClusterTrafficStatus) DeepCopy() *ClusterTrafficStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCondition.
+func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterTrafficStatus)
+	out := new(ClusterCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterTrafficTarget) DeepCopyInto(out *ClusterTrafficTarget) {
+func (in *ClusterInstallationCondition) DeepCopyInto(out *ClusterInstallationCondition) {
 	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficTarget.
-func (in *ClusterTrafficTarget) DeepCopy() *ClusterTrafficTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstallationCondition.
+func (in *ClusterInstallationCondition) DeepCopy() *ClusterInstallationCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterTrafficTarget)
+	out := new(ClusterInstallationCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationTarget) DeepCopyInto(out *InstallationTarget) {
+func (in *ClusterInstallationStatus) DeepCopyInto(out *ClusterInstallationStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterInstallationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTarget.
-func (in *InstallationTarget) DeepCopy() *InstallationTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstallationStatus.
+func (in *ClusterInstallationStatus) DeepCopy() *ClusterInstallationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationTarget)
+	out := new(ClusterInstallationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InstallationTarget) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationTargetList) DeepCopyInto(out *InstallationTargetList) {
+func (in *ClusterList) DeepCopyInto(out *ClusterList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]InstallationTarget, len(*in))
+		*out = make([]Cluster, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -676,18 +890,18 @@ func (in *InstallationTargetList) DeepCopyInto(out *InstallationTargetList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetList.
-func (in *InstallationTargetList) DeepCopy() *InstallationTargetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
+func (in *ClusterList) DeepCopy() *ClusterList {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationTargetList)
+	out := new(ClusterList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InstallationTargetList) DeepCopyObject() runtime.Object {
+func (in *ClusterList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -695,136 +909,1368 @@ func (in *InstallationTargetList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationTargetSpec) DeepCopyInto(out *InstallationTargetSpec) {
+func (in *ClusterReplicas) DeepCopyInto(out *ClusterReplicas) {
 	*out = *in
-	out.Chart = in.Chart
-	out.Values = in.Values.DeepCopy()
-	if in.Clusters != nil {
-		in, out := &in.Clusters, &out.Clusters
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetSpec.
-func (in *InstallationTargetSpec) DeepCopy() *InstallationTargetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReplicas.
+func (in *ClusterReplicas) DeepCopy() *ClusterReplicas {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationTargetSpec)
+	out := new(ClusterReplicas)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationTargetStatus) DeepCopyInto(out *InstallationTargetStatus) {
+func (in *ClusterRequirements) DeepCopyInto(out *ClusterRequirements) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]TargetCondition, len(*in))
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]RegionRequirement, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Clusters != nil {
-		in, out := &in.Clusters, &out.Clusters
-		*out = make([]*ClusterInstallationStatus, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(ClusterInstallationStatus)
-				(*in).DeepCopyInto(*out)
-			}
-		}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetStatus.
-func (in *InstallationTargetStatus) DeepCopy() *InstallationTargetStatus {
-	if in == nil {
-		return nil
+	if in.Spread != nil {
+		in, out := &in.Spread, &out.Spread
+		*out = new(ClusterSpread)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(InstallationTargetStatus)
-	in.DeepCopyInto(out)
-	return out
-}
+	if in.ApplicationAffinity != nil {
+		in, out := &in.ApplicationAffinity, &out.ApplicationAffinity
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApplicationAntiAffinity != nil {
+		in, out := &in.ApplicationAntiAffinity, &out.ApplicationAntiAffinity
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutOrder != nil {
+		in, out := &in.RolloutOrder, &out.RolloutOrder
+		*out = new(ClusterRolloutOrder)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRequirements.
+func (in *ClusterRequirements) DeepCopy() *ClusterRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRolloutOrder) DeepCopyInto(out *ClusterRolloutOrder) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRolloutOrder.
+func (in *ClusterRolloutOrder) DeepCopy() *ClusterRolloutOrder {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRolloutOrder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSchedulerSettings) DeepCopyInto(out *ClusterSchedulerSettings) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Identity != nil {
+		in, out := &in.Identity, &out.Identity
+		*out = new(string)
+		**out = **in
+	}
+	if in.CostTier != nil {
+		in, out := &in.CostTier, &out.CostTier
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PricePerReplicaHour != nil {
+		in, out := &in.PricePerReplicaHour, &out.PricePerReplicaHour
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RolloutPriority != nil {
+		in, out := &in.RolloutPriority, &out.RolloutPriority
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSchedulerSettings.
+func (in *ClusterSchedulerSettings) DeepCopy() *ClusterSchedulerSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSchedulerSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
+	*out = *in
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Scheduler.DeepCopyInto(&out.Scheduler)
+	if in.OperationTimeout != nil {
+		in, out := &in.OperationTimeout, &out.OperationTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxTotalCapacityPercent != nil {
+		in, out := &in.MaxTotalCapacityPercent, &out.MaxTotalCapacityPercent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
+func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSpread) DeepCopyInto(out *ClusterSpread) {
+	*out = *in
+	if in.MaxReplicasPerCluster != nil {
+		in, out := &in.MaxReplicasPerCluster, &out.MaxReplicasPerCluster
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Pinned != nil {
+		in, out := &in.Pinned, &out.Pinned
+		*out = make([]PinnedCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpread.
+func (in *ClusterSpread) DeepCopy() *ClusterSpread {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStrategyStatus) DeepCopyInto(out *ClusterStrategyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReleaseStrategyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AchievedTraffic != nil {
+		in, out := &in.AchievedTraffic, &out.AchievedTraffic
+		*out = new(uint32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStrategyStatus.
+func (in *ClusterStrategyStatus) DeepCopy() *ClusterStrategyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStrategyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTrafficCondition) DeepCopyInto(out *ClusterTrafficCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficCondition.
+func (in *ClusterTrafficCondition) DeepCopy() *ClusterTrafficCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTrafficCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTrafficStatus) DeepCopyInto(out *ClusterTrafficStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterTrafficCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficStatus.
+func (in *ClusterTrafficStatus) DeepCopy() *ClusterTrafficStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTrafficStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTrafficTarget) DeepCopyInto(out *ClusterTrafficTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficTarget.
+func (in *ClusterTrafficTarget) DeepCopy() *ClusterTrafficTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTrafficTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterValues) DeepCopyInto(out *ClusterValues) {
+	*out = *in
+	out.Values = in.Values.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterValues.
+func (in *ClusterValues) DeepCopy() *ClusterValues {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterValues)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClustersConverged) DeepCopyInto(out *ClustersConverged) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClustersConverged.
+func (in *ClustersConverged) DeepCopy() *ClustersConverged {
+	if in == nil {
+		return nil
+	}
+	out := new(ClustersConverged)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventsConfig) DeepCopyInto(out *EventsConfig) {
+	*out = *in
+	if in.DedupInterval != nil {
+		in, out := &in.DedupInterval, &out.DedupInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventsConfig.
+func (in *EventsConfig) DeepCopy() *EventsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EventsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExperimentBucketing) DeepCopyInto(out *ExperimentBucketing) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExperimentBucketing.
+func (in *ExperimentBucketing) DeepCopy() *ExperimentBucketing {
+	if in == nil {
+		return nil
+	}
+	out := new(ExperimentBucketing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalValueRef) DeepCopyInto(out *ExternalValueRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalValueRef.
+func (in *ExternalValueRef) DeepCopy() *ExternalValueRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalValueRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetCapacityOverride) DeepCopyInto(out *FleetCapacityOverride) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetCapacityOverride.
+func (in *FleetCapacityOverride) DeepCopy() *FleetCapacityOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetCapacityOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FleetCapacityOverride) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetCapacityOverrideList) DeepCopyInto(out *FleetCapacityOverrideList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FleetCapacityOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetCapacityOverrideList.
+func (in *FleetCapacityOverrideList) DeepCopy() *FleetCapacityOverrideList {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetCapacityOverrideList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FleetCapacityOverrideList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetCapacityOverrideSpec) DeepCopyInto(out *FleetCapacityOverrideSpec) {
+	*out = *in
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Author = in.Author
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetCapacityOverrideSpec.
+func (in *FleetCapacityOverrideSpec) DeepCopy() *FleetCapacityOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetCapacityOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageOverride) DeepCopyInto(out *ImageOverride) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageOverride.
+func (in *ImageOverride) DeepCopy() *ImageOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncumbentFloor) DeepCopyInto(out *IncumbentFloor) {
+	*out = *in
+	out.Capacity = in.Capacity
+	out.SoakDuration = in.SoakDuration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncumbentFloor.
+func (in *IncumbentFloor) DeepCopy() *IncumbentFloor {
+	if in == nil {
+		return nil
+	}
+	out := new(IncumbentFloor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncumbentStandby) DeepCopyInto(out *IncumbentStandby) {
+	*out = *in
+	out.Capacity = in.Capacity
+	out.Retention = in.Retention
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncumbentStandby.
+func (in *IncumbentStandby) DeepCopy() *IncumbentStandby {
+	if in == nil {
+		return nil
+	}
+	out := new(IncumbentStandby)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationTarget) DeepCopyInto(out *InstallationTarget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTarget.
+func (in *InstallationTarget) DeepCopy() *InstallationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstallationTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationTargetList) DeepCopyInto(out *InstallationTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InstallationTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetList.
+func (in *InstallationTargetList) DeepCopy() *InstallationTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstallationTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationTargetSpec) DeepCopyInto(out *InstallationTargetSpec) {
+	*out = *in
+	out.Chart = in.Chart
+	out.Values = in.Values.DeepCopy()
+	if in.ImageOverride != nil {
+		in, out := &in.ImageOverride, &out.ImageOverride
+		*out = new(ImageOverride)
+		**out = **in
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValueSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(TopologySpread)
+		**out = **in
+	}
+	if in.ReadinessBarriers != nil {
+		in, out := &in.ReadinessBarriers, &out.ReadinessBarriers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalCharts != nil {
+		in, out := &in.AdditionalCharts, &out.AdditionalCharts
+		*out = make([]AdditionalChart, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(Adoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetSpec.
+func (in *InstallationTargetSpec) DeepCopy() *InstallationTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationTargetStatus) DeepCopyInto(out *InstallationTargetStatus) {
+	*out = *in
+	in.TargetSyncStatus.DeepCopyInto(&out.TargetSyncStatus)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TargetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Charts != nil {
+		in, out := &in.Charts, &out.Charts
+		*out = make([]ChartInstallationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]ChartHookStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]*ClusterInstallationStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ClusterInstallationStatus)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationTargetStatus.
+func (in *InstallationTargetStatus) DeepCopy() *InstallationTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationsConfig) DeepCopyInto(out *NotificationsConfig) {
+	*out = *in
+	if in.CompletionWebhookURL != nil {
+		in, out := &in.CompletionWebhookURL, &out.CompletionWebhookURL
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationsConfig.
+func (in *NotificationsConfig) DeepCopy() *NotificationsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OPAPolicy) DeepCopyInto(out *OPAPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OPAPolicy.
+func (in *OPAPolicy) DeepCopy() *OPAPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OPAPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedCluster) DeepCopyInto(out *PinnedCluster) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedCluster.
+func (in *PinnedCluster) DeepCopy() *PinnedCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodStatus) DeepCopyInto(out *PodStatus) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.ContainerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.ContainerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Condition.DeepCopyInto(&out.Condition)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodStatus.
+func (in *PodStatus) DeepCopy() *PodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Policy) DeepCopyInto(out *Policy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Policy.
+func (in *Policy) DeepCopy() *Policy {
+	if in == nil {
+		return nil
+	}
+	out := new(Policy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Policy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyList) DeepCopyInto(out *PolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Policy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyList.
+func (in *PolicyList) DeepCopy() *PolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]PolicyStage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OPA != nil {
+		in, out := &in.OPA, &out.OPA
+		*out = new(OPAPolicy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySpec.
+func (in *PolicySpec) DeepCopy() *PolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionRequirement) DeepCopyInto(out *RegionRequirement) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionRequirement.
+func (in *RegionRequirement) DeepCopy() *RegionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Release) DeepCopyInto(out *Release) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Release.
+func (in *Release) DeepCopy() *Release {
+	if in == nil {
+		return nil
+	}
+	out := new(Release)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Release) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseApproval) DeepCopyInto(out *ReleaseApproval) {
+	*out = *in
+	in.ApprovedAt.DeepCopyInto(&out.ApprovedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseApproval.
+func (in *ReleaseApproval) DeepCopy() *ReleaseApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseCondition) DeepCopyInto(out *ReleaseCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseCondition.
+func (in *ReleaseCondition) DeepCopy() *ReleaseCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseETA) DeepCopyInto(out *ReleaseETA) {
+	*out = *in
+	in.StepStartedAt.DeepCopyInto(&out.StepStartedAt)
+	if in.StepCompletion != nil {
+		in, out := &in.StepCompletion, &out.StepCompletion
+		*out = (*in).DeepCopy()
+	}
+	if in.RolloutCompletion != nil {
+		in, out := &in.RolloutCompletion, &out.RolloutCompletion
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseETA.
+func (in *ReleaseETA) DeepCopy() *ReleaseETA {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseETA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseEnvironment) DeepCopyInto(out *ReleaseEnvironment) {
+	*out = *in
+	out.Chart = in.Chart
+	out.Values = in.Values.DeepCopy()
+	in.ClusterRequirements.DeepCopyInto(&out.ClusterRequirements)
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageOverride != nil {
+		in, out := &in.ImageOverride, &out.ImageOverride
+		*out = new(ImageOverride)
+		**out = **in
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(TopologySpread)
+		**out = **in
+	}
+	if in.ReadinessBarriers != nil {
+		in, out := &in.ReadinessBarriers, &out.ReadinessBarriers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesOverlays != nil {
+		in, out := &in.ValuesOverlays, &out.ValuesOverlays
+		*out = new(ValuesOverlays)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValueSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalCharts != nil {
+		in, out := &in.AdditionalCharts, &out.AdditionalCharts
+		*out = make([]AdditionalChart, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseEnvironment.
+func (in *ReleaseEnvironment) DeepCopy() *ReleaseEnvironment {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseEnvironment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseList) DeepCopyInto(out *ReleaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Release, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseList.
+func (in *ReleaseList) DeepCopy() *ReleaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReleaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseScheduling) DeepCopyInto(out *ReleaseScheduling) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseScheduling.
+func (in *ReleaseScheduling) DeepCopy() *ReleaseScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseSpec) DeepCopyInto(out *ReleaseSpec) {
+	*out = *in
+	in.Environment.DeepCopyInto(&out.Environment)
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]ReleaseApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterReplicas != nil {
+		in, out := &in.ClusterReplicas, &out.ClusterReplicas
+		*out = make([]ClusterReplicas, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterValues != nil {
+		in, out := &in.ClusterValues, &out.ClusterValues
+		*out = make([]ClusterValues, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adopt != nil {
+		in, out := &in.Adopt, &out.Adopt
+		*out = new(Adoption)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSpec.
+func (in *ReleaseSpec) DeepCopy() *ReleaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStatus) DeepCopyInto(out *ReleaseStatus) {
+	*out = *in
+	if in.AchievedStep != nil {
+		in, out := &in.AchievedStep, &out.AchievedStep
+		*out = new(AchievedStep)
+		**out = **in
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ReleaseStrategyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReleaseCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ETA != nil {
+		in, out := &in.ETA, &out.ETA
+		*out = new(ReleaseETA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(ReleaseScheduling)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.FullTrafficSince != nil {
+		in, out := &in.FullTrafficSince, &out.FullTrafficSince
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatus.
+func (in *ReleaseStatus) DeepCopy() *ReleaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStepHistory) DeepCopyInto(out *ReleaseStepHistory) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.AchievedAt != nil {
+		in, out := &in.AchievedAt, &out.AchievedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStepHistory.
+func (in *ReleaseStepHistory) DeepCopy() *ReleaseStepHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStepHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStrategyCondition) DeepCopyInto(out *ReleaseStrategyCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyCondition.
+func (in *ReleaseStrategyCondition) DeepCopy() *ReleaseStrategyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStrategyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseStrategyState) DeepCopyInto(out *ReleaseStrategyState) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyState.
+func (in *ReleaseStrategyState) DeepCopy() *ReleaseStrategyState {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseStrategyState)
+	in.DeepCopyInto(out)
+	return out
+}
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodStatus) DeepCopyInto(out *PodStatus) {
+func (in *ReleaseStrategyStatus) DeepCopyInto(out *ReleaseStrategyStatus) {
 	*out = *in
-	if in.Containers != nil {
-		in, out := &in.Containers, &out.Containers
-		*out = make([]v1.ContainerStatus, len(*in))
+	out.State = in.State
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterStrategyStatus, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.ContainerStatus, len(*in))
+	if in.UnavailableClusters != nil {
+		in, out := &in.UnavailableClusters, &out.UnavailableClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClustersConverged != nil {
+		in, out := &in.ClustersConverged, &out.ClustersConverged
+		*out = new(ClustersConverged)
+		**out = **in
+	}
+	if in.StepHistory != nil {
+		in, out := &in.StepHistory, &out.StepHistory
+		*out = make([]ReleaseStepHistory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ReleaseStrategyCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Condition.DeepCopyInto(&out.Condition)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodStatus.
-func (in *PodStatus) DeepCopy() *PodStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyStatus.
+func (in *ReleaseStrategyStatus) DeepCopy() *ReleaseStrategyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PodStatus)
+	out := new(ReleaseStrategyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegionRequirement) DeepCopyInto(out *RegionRequirement) {
+func (in *RolloutBlock) DeepCopyInto(out *RolloutBlock) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlock.
+func (in *RolloutBlock) DeepCopy() *RolloutBlock {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlock)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RolloutBlock) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutBlockAuthor) DeepCopyInto(out *RolloutBlockAuthor) {
+	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionRequirement.
-func (in *RegionRequirement) DeepCopy() *RegionRequirement {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockAuthor.
+func (in *RolloutBlockAuthor) DeepCopy() *RolloutBlockAuthor {
 	if in == nil {
 		return nil
 	}
-	out := new(RegionRequirement)
+	out := new(RolloutBlockAuthor)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Release) DeepCopyInto(out *Release) {
+func (in *RolloutBlockList) DeepCopyInto(out *RolloutBlockList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RolloutBlock, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Release.
-func (in *Release) DeepCopy() *Release {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockList.
+func (in *RolloutBlockList) DeepCopy() *RolloutBlockList {
 	if in == nil {
 		return nil
 	}
-	out := new(Release)
+	out := new(RolloutBlockList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Release) DeepCopyObject() runtime.Object {
+func (in *RolloutBlockList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -832,54 +2278,133 @@ func (in *Release) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseCondition) DeepCopyInto(out *ReleaseCondition) {
+func (in *RolloutBlockOverrides) DeepCopyInto(out *RolloutBlockOverrides) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseCondition.
-func (in *ReleaseCondition) DeepCopy() *ReleaseCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockOverrides.
+func (in *RolloutBlockOverrides) DeepCopy() *RolloutBlockOverrides {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseCondition)
+	out := new(RolloutBlockOverrides)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseEnvironment) DeepCopyInto(out *ReleaseEnvironment) {
+func (in *RolloutBlockSpec) DeepCopyInto(out *RolloutBlockSpec) {
 	*out = *in
-	out.Chart = in.Chart
-	out.Values = in.Values.DeepCopy()
-	in.ClusterRequirements.DeepCopyInto(&out.ClusterRequirements)
-	if in.Strategy != nil {
-		in, out := &in.Strategy, &out.Strategy
-		*out = new(RolloutStrategy)
-		(*in).DeepCopyInto(*out)
+	out.Author = in.Author
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockSpec.
+func (in *RolloutBlockSpec) DeepCopy() *RolloutBlockSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlockSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutBlockStatus) DeepCopyInto(out *RolloutBlockStatus) {
+	*out = *in
+	out.Overrides = in.Overrides
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockStatus.
+func (in *RolloutBlockStatus) DeepCopy() *RolloutBlockStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutBlockStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutCutover) DeepCopyInto(out *RolloutCutover) {
+	*out = *in
+	if in.Ingresses != nil {
+		in, out := &in.Ingresses, &out.Ingresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseEnvironment.
-func (in *ReleaseEnvironment) DeepCopy() *ReleaseEnvironment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutCutover.
+func (in *RolloutCutover) DeepCopy() *RolloutCutover {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseEnvironment)
+	out := new(RolloutCutover)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseList) DeepCopyInto(out *ReleaseList) {
+func (in *RolloutExperiment) DeepCopyInto(out *RolloutExperiment) {
+	*out = *in
+	out.Bucketing = in.Bucketing
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutExperiment.
+func (in *RolloutExperiment) DeepCopy() *RolloutExperiment {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutExperiment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutQuota) DeepCopyInto(out *RolloutQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutQuota.
+func (in *RolloutQuota) DeepCopy() *RolloutQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RolloutQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutQuotaList) DeepCopyInto(out *RolloutQuotaList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Release, len(*in))
+		*out = make([]RolloutQuota, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -887,18 +2412,18 @@ func (in *ReleaseList) DeepCopyInto(out *ReleaseList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseList.
-func (in *ReleaseList) DeepCopy() *ReleaseList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutQuotaList.
+func (in *RolloutQuotaList) DeepCopy() *RolloutQuotaList {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseList)
+	out := new(RolloutQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ReleaseList) DeepCopyObject() runtime.Object {
+func (in *RolloutQuotaList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -906,141 +2431,219 @@ func (in *ReleaseList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseSpec) DeepCopyInto(out *ReleaseSpec) {
+func (in *RolloutQuotaSpec) DeepCopyInto(out *RolloutQuotaSpec) {
 	*out = *in
-	in.Environment.DeepCopyInto(&out.Environment)
+	if in.MaxConcurrentRollouts != nil {
+		in, out := &in.MaxConcurrentRollouts, &out.MaxConcurrentRollouts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseSpec.
-func (in *ReleaseSpec) DeepCopy() *ReleaseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutQuotaSpec.
+func (in *RolloutQuotaSpec) DeepCopy() *RolloutQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseSpec)
+	out := new(RolloutQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseStatus) DeepCopyInto(out *ReleaseStatus) {
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
 	*out = *in
-	if in.AchievedStep != nil {
-		in, out := &in.AchievedStep, &out.AchievedStep
-		*out = new(AchievedStep)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RolloutStrategyStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StrategyRef != nil {
+		in, out := &in.StrategyRef, &out.StrategyRef
+		*out = new(StrategyRef)
+		**out = **in
+	}
+	if in.MaxUnavailableClusters != nil {
+		in, out := &in.MaxUnavailableClusters, &out.MaxUnavailableClusters
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.IncumbentFloor != nil {
+		in, out := &in.IncumbentFloor, &out.IncumbentFloor
+		*out = new(IncumbentFloor)
+		**out = **in
+	}
+	if in.IncumbentStandby != nil {
+		in, out := &in.IncumbentStandby, &out.IncumbentStandby
+		*out = new(IncumbentStandby)
+		**out = **in
+	}
+	if in.Experiment != nil {
+		in, out := &in.Experiment, &out.Experiment
+		*out = new(RolloutExperiment)
 		**out = **in
 	}
-	if in.Strategy != nil {
-		in, out := &in.Strategy, &out.Strategy
-		*out = new(ReleaseStrategyStatus)
-		(*in).DeepCopyInto(*out)
+	if in.Cutover != nil {
+		in, out := &in.Cutover, &out.Cutover
+		*out = new(RolloutCutover)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategyStep) DeepCopyInto(out *RolloutStrategyStep) {
+	*out = *in
+	out.Capacity = in.Capacity
+	out.Traffic = in.Traffic
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreHooks != nil {
+		in, out := &in.PreHooks, &out.PreHooks
+		*out = make([]StepHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostHooks != nil {
+		in, out := &in.PostHooks, &out.PostHooks
+		*out = make([]StepHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ReleaseCondition, len(*in))
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]StepProbe, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStatus.
-func (in *ReleaseStatus) DeepCopy() *ReleaseStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategyStep.
+func (in *RolloutStrategyStep) DeepCopy() *RolloutStrategyStep {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseStatus)
+	out := new(RolloutStrategyStep)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseStrategyCondition) DeepCopyInto(out *ReleaseStrategyCondition) {
+func (in *RolloutStrategyStepValue) DeepCopyInto(out *RolloutStrategyStepValue) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyCondition.
-func (in *ReleaseStrategyCondition) DeepCopy() *ReleaseStrategyCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategyStepValue.
+func (in *RolloutStrategyStepValue) DeepCopy() *RolloutStrategyStepValue {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseStrategyCondition)
+	out := new(RolloutStrategyStepValue)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseStrategyState) DeepCopyInto(out *ReleaseStrategyState) {
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyState.
-func (in *ReleaseStrategyState) DeepCopy() *ReleaseStrategyState {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseStrategyState)
+	out := new(SecretKeyRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ReleaseStrategyStatus) DeepCopyInto(out *ReleaseStrategyStatus) {
+func (in *ShipperConfig) DeepCopyInto(out *ShipperConfig) {
 	*out = *in
-	out.State = in.State
-	if in.Clusters != nil {
-		in, out := &in.Clusters, &out.Clusters
-		*out = make([]ClusterStrategyStatus, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ReleaseStrategyCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseStrategyStatus.
-func (in *ReleaseStrategyStatus) DeepCopy() *ReleaseStrategyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipperConfig.
+func (in *ShipperConfig) DeepCopy() *ShipperConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ReleaseStrategyStatus)
+	out := new(ShipperConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ShipperConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlock) DeepCopyInto(out *RolloutBlock) {
+func (in *ShipperConfigList) DeepCopyInto(out *ShipperConfigList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ShipperConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlock.
-func (in *RolloutBlock) DeepCopy() *RolloutBlock {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipperConfigList.
+func (in *ShipperConfigList) DeepCopy() *ShipperConfigList {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlock)
+	out := new(ShipperConfigList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RolloutBlock) DeepCopyObject() runtime.Object {
+func (in *ShipperConfigList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1048,172 +2651,217 @@ func (in *RolloutBlock) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlockAuthor) DeepCopyInto(out *RolloutBlockAuthor) {
+func (in *ShipperConfigSpec) DeepCopyInto(out *ShipperConfigSpec) {
 	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = new(EventsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultStrategy != nil {
+		in, out := &in.DefaultStrategy, &out.DefaultStrategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitOpsInterval != nil {
+		in, out := &in.GitOpsInterval, &out.GitOpsInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ChartRepositories != nil {
+		in, out := &in.ChartRepositories, &out.ChartRepositories
+		*out = new(ChartRepositoriesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockAuthor.
-func (in *RolloutBlockAuthor) DeepCopy() *RolloutBlockAuthor {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipperConfigSpec.
+func (in *ShipperConfigSpec) DeepCopy() *ShipperConfigSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlockAuthor)
+	out := new(ShipperConfigSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlockList) DeepCopyInto(out *RolloutBlockList) {
+func (in *StepHook) DeepCopyInto(out *StepHook) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]RolloutBlock, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(batchv1.JobSpec)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockList.
-func (in *RolloutBlockList) DeepCopy() *RolloutBlockList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepHook.
+func (in *StepHook) DeepCopy() *StepHook {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlockList)
+	out := new(StepHook)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RolloutBlockList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlockOverrides) DeepCopyInto(out *RolloutBlockOverrides) {
+func (in *StepProbe) DeepCopyInto(out *StepProbe) {
 	*out = *in
+	if in.LatencySLO != nil {
+		in, out := &in.LatencySLO, &out.LatencySLO
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockOverrides.
-func (in *RolloutBlockOverrides) DeepCopy() *RolloutBlockOverrides {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepProbe.
+func (in *StepProbe) DeepCopy() *StepProbe {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlockOverrides)
+	out := new(StepProbe)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlockSpec) DeepCopyInto(out *RolloutBlockSpec) {
+func (in *Strategy) DeepCopyInto(out *Strategy) {
 	*out = *in
-	out.Author = in.Author
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockSpec.
-func (in *RolloutBlockSpec) DeepCopy() *RolloutBlockSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Strategy.
+func (in *Strategy) DeepCopy() *Strategy {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlockSpec)
+	out := new(Strategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Strategy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutBlockStatus) DeepCopyInto(out *RolloutBlockStatus) {
+func (in *StrategyList) DeepCopyInto(out *StrategyList) {
 	*out = *in
-	out.Overrides = in.Overrides
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Strategy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBlockStatus.
-func (in *RolloutBlockStatus) DeepCopy() *RolloutBlockStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyList.
+func (in *StrategyList) DeepCopy() *StrategyList {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutBlockStatus)
+	out := new(StrategyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StrategyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+func (in *StrategyRef) DeepCopyInto(out *StrategyRef) {
 	*out = *in
-	if in.Steps != nil {
-		in, out := &in.Steps, &out.Steps
-		*out = make([]RolloutStrategyStep, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
-func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyRef.
+func (in *StrategyRef) DeepCopy() *StrategyRef {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutStrategy)
+	out := new(StrategyRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutStrategyStep) DeepCopyInto(out *RolloutStrategyStep) {
+func (in *TargetCondition) DeepCopyInto(out *TargetCondition) {
 	*out = *in
-	out.Capacity = in.Capacity
-	out.Traffic = in.Traffic
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategyStep.
-func (in *RolloutStrategyStep) DeepCopy() *RolloutStrategyStep {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetCondition.
+func (in *TargetCondition) DeepCopy() *TargetCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutStrategyStep)
+	out := new(TargetCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RolloutStrategyStepValue) DeepCopyInto(out *RolloutStrategyStepValue) {
+func (in *TargetSyncStatus) DeepCopyInto(out *TargetSyncStatus) {
 	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategyStepValue.
-func (in *RolloutStrategyStepValue) DeepCopy() *RolloutStrategyStepValue {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSyncStatus.
+func (in *TargetSyncStatus) DeepCopy() *TargetSyncStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RolloutStrategyStepValue)
+	out := new(TargetSyncStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TargetCondition) DeepCopyInto(out *TargetCondition) {
+func (in *TopologySpread) DeepCopyInto(out *TopologySpread) {
 	*out = *in
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetCondition.
-func (in *TargetCondition) DeepCopy() *TargetCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpread.
+func (in *TopologySpread) DeepCopy() *TopologySpread {
 	if in == nil {
 		return nil
 	}
-	out := new(TargetCondition)
+	out := new(TopologySpread)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1282,6 +2930,11 @@ func (in *TrafficTargetList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficTargetSpec) DeepCopyInto(out *TrafficTargetSpec) {
 	*out = *in
+	if in.Bucketing != nil {
+		in, out := &in.Bucketing, &out.Bucketing
+		*out = new(ExperimentBucketing)
+		**out = **in
+	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]ClusterTrafficTarget, len(*in))
@@ -1303,6 +2956,7 @@ func (in *TrafficTargetSpec) DeepCopy() *TrafficTargetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficTargetStatus) DeepCopyInto(out *TrafficTargetStatus) {
 	*out = *in
+	in.TargetSyncStatus.DeepCopyInto(&out.TargetSyncStatus)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TargetCondition, len(*in))
@@ -1310,6 +2964,10 @@ func (in *TrafficTargetStatus) DeepCopyInto(out *TrafficTargetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DrainingSince != nil {
+		in, out := &in.DrainingSince, &out.DrainingSince
+		*out = (*in).DeepCopy()
+	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]*ClusterTrafficStatus, len(*in))
@@ -1333,3 +2991,99 @@ func (in *TrafficTargetStatus) DeepCopy() *TrafficTargetStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueSource) DeepCopyInto(out *ValueSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalValueRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueSource.
+func (in *ValueSource) DeepCopy() *ValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesOverlay) DeepCopyInto(out *ValuesOverlay) {
+	*out = *in
+	out.Values = in.Values.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesOverlay.
+func (in *ValuesOverlay) DeepCopy() *ValuesOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesOverlays) DeepCopyInto(out *ValuesOverlays) {
+	*out = *in
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]ValuesOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ValuesOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesOverlays.
+func (in *ValuesOverlays) DeepCopy() *ValuesOverlays {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesOverlays)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadCapacityStatus) DeepCopyInto(out *WorkloadCapacityStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TargetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadCapacityStatus.
+func (in *WorkloadCapacityStatus) DeepCopy() *WorkloadCapacityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadCapacityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,285 @@
+// Package backup exports the state of a Shipper installation, and rebuilds
+// the management cluster from it after it's lost.
+//
+// Application clusters are only ever read from: the targets and objects
+// Shipper installed in them keep running while the management cluster is
+// rebuilt, and releases pick them up again once it is.
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+)
+
+// FormatVersion is the version of the format backups are written in.
+// Restore refuses backups in any other.
+const FormatVersion = "1"
+
+// Backup is the state of a Shipper installation: every Shipper object in
+// the management cluster, and what Shipper installed in each application
+// cluster.
+type Backup struct {
+	Version   string      `json:"version"`
+	CreatedAt metav1.Time `json:"createdAt"`
+
+	ShipperConfigs         []shipper.ShipperConfig         `json:"shipperConfigs,omitempty"`
+	ChartRepositories      []shipper.ChartRepository       `json:"chartRepositories,omitempty"`
+	Clusters               []shipper.Cluster               `json:"clusters,omitempty"`
+	Strategies             []shipper.Strategy              `json:"strategies,omitempty"`
+	Policies               []shipper.Policy                `json:"policies,omitempty"`
+	ApplicationDefaults    []shipper.ApplicationDefault    `json:"applicationDefaults,omitempty"`
+	ApplicationTemplates   []shipper.ApplicationTemplate   `json:"applicationTemplates,omitempty"`
+	RolloutBlocks          []shipper.RolloutBlock          `json:"rolloutBlocks,omitempty"`
+	FleetCapacityOverrides []shipper.FleetCapacityOverride `json:"fleetCapacityOverrides,omitempty"`
+	RolloutQuotas          []shipper.RolloutQuota          `json:"rolloutQuotas,omitempty"`
+	Applications           []shipper.Application           `json:"applications,omitempty"`
+	Releases               []shipper.Release               `json:"releases,omitempty"`
+
+	Inventories []ClusterInventory `json:"inventories,omitempty"`
+}
+
+// ClusterInventory is what Shipper installed in an application cluster.
+type ClusterInventory struct {
+	Cluster string `json:"cluster"`
+	// Error is set when the cluster couldn't be inventoried, in which
+	// case the rest is left empty.
+	Error string `json:"error,omitempty"`
+
+	InstallationTargets []shipper.InstallationTarget `json:"installationTargets,omitempty"`
+	CapacityTargets     []shipper.CapacityTarget     `json:"capacityTargets,omitempty"`
+	TrafficTargets      []shipper.TrafficTarget      `json:"trafficTargets,omitempty"`
+
+	Objects []AppliedObject `json:"objects,omitempty"`
+}
+
+// AppliedObject is an object an installation target installed.
+type AppliedObject struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	InstallationTarget string `json:"installationTarget"`
+}
+
+// ClusterClients are the clients an application cluster is inventoried
+// with.
+type ClusterClients struct {
+	Shipper   shipperclientset.Interface
+	Discovery discovery.DiscoveryInterface
+	Dynamic   dynamic.Interface
+}
+
+// ClusterClientsFunc returns the clients for an application cluster.
+type ClusterClientsFunc func(cluster *shipper.Cluster) (ClusterClients, error)
+
+// Export returns the state of the Shipper installation managed through
+// client. Application clusters are inventoried with the clients
+// clusterClients returns, and the ones that can't be are recorded with
+// their error instead of failing the whole backup. A nil clusterClients
+// skips inventories altogether.
+func Export(client shipperclientset.Interface, clusterClients ClusterClientsFunc) (*Backup, error) {
+	b := &Backup{
+		Version:   FormatVersion,
+		CreatedAt: metav1.NewTime(time.Now()),
+	}
+
+	v1alpha1 := client.ShipperV1alpha1()
+	opts := metav1.ListOptions{}
+
+	configs, err := v1alpha1.ShipperConfigs().List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing shipper configs: %s", err)
+	}
+	b.ShipperConfigs = configs.Items
+
+	chartRepos, err := v1alpha1.ChartRepositories().List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing chart repositories: %s", err)
+	}
+	b.ChartRepositories = chartRepos.Items
+
+	clusters, err := v1alpha1.Clusters().List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing clusters: %s", err)
+	}
+	b.Clusters = clusters.Items
+
+	strategies, err := v1alpha1.Strategies(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing strategies: %s", err)
+	}
+	b.Strategies = strategies.Items
+
+	policies, err := v1alpha1.Policies(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing policies: %s", err)
+	}
+	b.Policies = policies.Items
+
+	defaults, err := v1alpha1.ApplicationDefaults(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing application defaults: %s", err)
+	}
+	b.ApplicationDefaults = defaults.Items
+
+	templates, err := v1alpha1.ApplicationTemplates(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing application templates: %s", err)
+	}
+	b.ApplicationTemplates = templates.Items
+
+	rbs, err := v1alpha1.RolloutBlocks(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing rollout blocks: %s", err)
+	}
+	b.RolloutBlocks = rbs.Items
+
+	overrides, err := v1alpha1.FleetCapacityOverrides(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing fleet capacity overrides: %s", err)
+	}
+	b.FleetCapacityOverrides = overrides.Items
+
+	quotas, err := v1alpha1.RolloutQuotas(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing rollout quotas: %s", err)
+	}
+	b.RolloutQuotas = quotas.Items
+
+	apps, err := v1alpha1.Applications(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing applications: %s", err)
+	}
+	b.Applications = apps.Items
+
+	rels, err := v1alpha1.Releases(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error listing releases: %s", err)
+	}
+	b.Releases = rels.Items
+
+	if clusterClients == nil {
+		return b, nil
+	}
+
+	for i := range b.Clusters {
+		cluster := &b.Clusters[i]
+
+		inventory := ClusterInventory{Cluster: cluster.Name}
+		clients, err := clusterClients(cluster)
+		if err == nil {
+			err = inventoryCluster(clients, &inventory)
+		}
+
+		if err != nil {
+			inventory = ClusterInventory{
+				Cluster: cluster.Name,
+				Error:   err.Error(),
+			}
+		}
+
+		b.Inventories = append(b.Inventories, inventory)
+	}
+
+	return b, nil
+}
+
+// inventoryCluster fills in inventory with the targets in the application
+// cluster behind clients, and the objects they installed, as told by their
+// InstallationTargetOwnerLabel.
+func inventoryCluster(clients ClusterClients, inventory *ClusterInventory) error {
+	v1alpha1 := clients.Shipper.ShipperV1alpha1()
+	opts := metav1.ListOptions{}
+
+	its, err := v1alpha1.InstallationTargets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return fmt.Errorf("error listing installation targets: %s", err)
+	}
+	inventory.InstallationTargets = its.Items
+
+	cts, err := v1alpha1.CapacityTargets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return fmt.Errorf("error listing capacity targets: %s", err)
+	}
+	inventory.CapacityTargets = cts.Items
+
+	tts, err := v1alpha1.TrafficTargets(metav1.NamespaceAll).List(opts)
+	if err != nil {
+		return fmt.Errorf("error listing traffic targets: %s", err)
+	}
+	inventory.TrafficTargets = tts.Items
+
+	// Aggregated APIs that are down don't keep the rest from being
+	// inventoried.
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(clients.Discovery)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return fmt.Errorf("error discovering resources: %s", err)
+	}
+
+	ownedOpts := metav1.ListOptions{LabelSelector: shipper.InstallationTargetOwnerLabel}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if !canList(resource) {
+				continue
+			}
+
+			objects, err := clients.Dynamic.Resource(gv.WithResource(resource.Name)).
+				Namespace(metav1.NamespaceAll).List(ownedOpts)
+			if err != nil {
+				return fmt.Errorf("error listing %s: %s", resource.Name, err)
+			}
+
+			for _, obj := range objects.Items {
+				inventory.Objects = append(inventory.Objects, AppliedObject{
+					APIVersion:         gv.String(),
+					Kind:               resource.Kind,
+					Namespace:          obj.GetNamespace(),
+					Name:               obj.GetName(),
+					InstallationTarget: obj.GetLabels()[shipper.InstallationTargetOwnerLabel],
+				})
+			}
+		}
+	}
+
+	sort.Slice(inventory.Objects, func(i, j int) bool {
+		a, b := inventory.Objects[i], inventory.Objects[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		} else if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	return nil
+}
+
+// canList tells whether resource can be listed. Subresources never can.
+func canList(resource metav1.APIResource) bool {
+	if strings.Contains(resource.Name, "/") {
+		return false
+	}
+
+	for _, verb := range resource.Verbs {
+		if verb == "list" {
+			return true
+		}
+	}
+
+	return false
+}
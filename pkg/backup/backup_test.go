@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	kubetesting "k8s.io/client-go/testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildObjects() (*shipper.Cluster, *shipper.Application, *shipper.Release) {
+	cluster := &shipper.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-a"},
+	}
+
+	app := &shipper.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shippertesting.TestApp,
+			Namespace: shippertesting.TestNamespace,
+			UID:       "lost-app-uid",
+		},
+	}
+
+	rel := &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-app-deadbeef-0",
+			Namespace:       shippertesting.TestNamespace,
+			UID:             "lost-release-uid",
+			ResourceVersion: "42",
+			Labels:          map[string]string{shipper.AppLabel: shippertesting.TestApp},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: shipper.SchemeGroupVersion.String(),
+					Kind:       "Application",
+					Name:       app.Name,
+					UID:        app.UID,
+				},
+			},
+		},
+		Spec: shipper.ReleaseSpec{TargetStep: 2},
+	}
+
+	return cluster, app, rel
+}
+
+// TestExport verifies that backups have every Shipper object of the
+// management cluster, and an inventory of what was installed in each
+// application cluster that could be reached.
+func TestExport(t *testing.T) {
+	cluster, app, rel := buildObjects()
+	unreachable := &shipper.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-b"},
+	}
+	mgmt := shipperfake.NewSimpleClientset(cluster, unreachable, app, rel)
+
+	it := &shipper.InstallationTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rel.Name,
+			Namespace: rel.Namespace,
+		},
+	}
+	appClient := shipperfake.NewSimpleClientset(it)
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace(shippertesting.TestNamespace)
+	deployment.SetName("test-app-deadbeef-0")
+	deployment.SetLabels(map[string]string{shipper.InstallationTargetOwnerLabel: it.Name})
+
+	unmanaged := deployment.DeepCopy()
+	unmanaged.SetName("not-shipped")
+	unmanaged.SetLabels(nil)
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(kubescheme.Scheme, deployment, unmanaged)
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &kubetesting.Fake{}}
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Kind: "Deployment", Name: "deployments", Namespaced: true, Verbs: []string{"get", "list"}},
+				{Kind: "Deployment", Name: "deployments/scale", Namespaced: true, Verbs: []string{"get"}},
+			},
+		},
+	}
+
+	clusterClients := func(cluster *shipper.Cluster) (ClusterClients, error) {
+		if cluster.Name != "kube-a" {
+			return ClusterClients{}, errors.New("cluster unreachable")
+		}
+
+		return ClusterClients{
+			Shipper:   appClient,
+			Discovery: discoveryClient,
+			Dynamic:   dynamicClient,
+		}, nil
+	}
+
+	b, err := Export(mgmt, clusterClients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b.Clusters) != 2 || len(b.Applications) != 1 || len(b.Releases) != 1 {
+		t.Fatalf("expected 2 clusters, 1 application and 1 release, got %d, %d and %d",
+			len(b.Clusters), len(b.Applications), len(b.Releases))
+	}
+
+	if len(b.Inventories) != 2 {
+		t.Fatalf("expected 2 inventories, got %d", len(b.Inventories))
+	}
+
+	for _, inventory := range b.Inventories {
+		switch inventory.Cluster {
+		case "kube-a":
+			if inventory.Error != "" {
+				t.Fatalf("expected cluster %q to be inventoried, got %s", inventory.Cluster, inventory.Error)
+			}
+
+			if len(inventory.InstallationTargets) != 1 {
+				t.Errorf("expected 1 installation target, got %d", len(inventory.InstallationTargets))
+			}
+
+			expected := AppliedObject{
+				APIVersion:         "apps/v1",
+				Kind:               "Deployment",
+				Namespace:          shippertesting.TestNamespace,
+				Name:               deployment.GetName(),
+				InstallationTarget: it.Name,
+			}
+			if len(inventory.Objects) != 1 || inventory.Objects[0] != expected {
+				t.Errorf("expected objects %v, got %v", []AppliedObject{expected}, inventory.Objects)
+			}
+		case "kube-b":
+			if inventory.Error == "" {
+				t.Errorf("expected cluster %q to have an error", inventory.Cluster)
+			}
+		}
+	}
+}
+
+// TestRestore verifies that restoring a backup creates its objects without
+// the fields the API server sets, makes releases belong to their restored
+// applications again, and leaves objects that already exist alone.
+func TestRestore(t *testing.T) {
+	cluster, app, rel := buildObjects()
+	b := &Backup{
+		Version:      FormatVersion,
+		Clusters:     []shipper.Cluster{*cluster},
+		Applications: []shipper.Application{*app},
+		Releases:     []shipper.Release{*rel},
+	}
+
+	client := shipperfake.NewSimpleClientset()
+	client.PrependReactor("create", "applications", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		created := action.(kubetesting.CreateAction).GetObject().(*shipper.Application)
+		if created.UID != "" {
+			t.Errorf("expected application to be created without a UID, got %q", created.UID)
+		}
+
+		created.UID = "restored-app-uid"
+		return false, nil, nil
+	})
+
+	result, err := Restore(client, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Created) != 3 || len(result.Existing) != 0 {
+		t.Fatalf("expected 3 objects to be created, got %v and %v existing", result.Created, result.Existing)
+	}
+
+	restored, err := client.ShipperV1alpha1().Releases(rel.Namespace).Get(rel.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Spec.TargetStep != rel.Spec.TargetStep {
+		t.Errorf("expected release to keep its target step %d, got %d", rel.Spec.TargetStep, restored.Spec.TargetStep)
+	}
+
+	refs := restored.OwnerReferences
+	if len(refs) != 1 || refs[0].Name != app.Name || refs[0].UID != "restored-app-uid" {
+		t.Errorf("expected release to belong to the restored application, got %v", refs)
+	}
+
+	result, err = Restore(client, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Created) != 0 || len(result.Existing) != 3 {
+		t.Fatalf("expected all 3 objects to exist already, got %v and %v created", result.Existing, result.Created)
+	}
+
+	b.Version = "0"
+	if _, err := Restore(client, b); err == nil {
+		t.Fatalf("expected backups in another format version to be refused")
+	}
+}
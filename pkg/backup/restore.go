@@ -0,0 +1,285 @@
+package backup
+
+import (
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+)
+
+// RestoreResult lists the objects Restore created, and the ones it left
+// alone because they already existed, as "Kind namespace/name".
+type RestoreResult struct {
+	Created  []string
+	Existing []string
+}
+
+// Restore creates the Shipper objects in b in the management cluster
+// behind client. Objects that already exist are left as they are, so a
+// restore that failed halfway can be run again. Inventories are never
+// restored: application clusters keep what Shipper installed in them.
+//
+// Releases are created before the applications they belong to, so the
+// application controller finds them instead of rolling out new ones, and
+// are made to belong to them again once they're created.
+func Restore(client shipperclientset.Interface, b *Backup) (*RestoreResult, error) {
+	if b.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %q, expected %q", b.Version, FormatVersion)
+	}
+
+	result := &RestoreResult{}
+	v1alpha1 := client.ShipperV1alpha1()
+
+	for _, obj := range b.ShipperConfigs {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("ShipperConfig", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.ShipperConfigs().Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.ChartRepositories {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("ChartRepository", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.ChartRepositories().Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.Clusters {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("Cluster", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.Clusters().Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.Strategies {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("Strategy", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.Strategies(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.Policies {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("Policy", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.Policies(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.ApplicationDefaults {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("ApplicationDefault", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.ApplicationDefaults(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.ApplicationTemplates {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("ApplicationTemplate", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.ApplicationTemplates(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.RolloutBlocks {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("RolloutBlock", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.RolloutBlocks(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.FleetCapacityOverrides {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("FleetCapacityOverride", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.FleetCapacityOverrides(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for _, obj := range b.RolloutQuotas {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("RolloutQuota", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.RolloutQuotas(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// The UIDs of applications change when they're created again, so
+	// releases can't be created with their owner references, or the
+	// garbage collector would take them for orphans.
+	owners := make(map[string]string)
+	for _, obj := range b.Releases {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+
+		var ownerReferences []metav1.OwnerReference
+		for _, ref := range obj.OwnerReferences {
+			if ref.Kind == "Application" {
+				owners[key(obj.Namespace, obj.Name)] = ref.Name
+			} else {
+				ownerReferences = append(ownerReferences, ref)
+			}
+		}
+		obj.OwnerReferences = ownerReferences
+
+		err := result.create("Release", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.Releases(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	uids := make(map[string]types.UID)
+	for _, obj := range b.Applications {
+		obj := obj
+		resetObjectMeta(&obj.ObjectMeta)
+		err := result.create("Application", &obj.ObjectMeta, func() error {
+			_, err := v1alpha1.Applications(obj.Namespace).Create(&obj)
+			return err
+		})
+		if err != nil {
+			return result, err
+		}
+
+		app, err := v1alpha1.Applications(obj.Namespace).Get(obj.Name, metav1.GetOptions{})
+		if err != nil {
+			return result, fmt.Errorf("error fetching Application %s/%s: %s", obj.Namespace, obj.Name, err)
+		}
+		uids[key(app.Namespace, app.Name)] = app.UID
+	}
+
+	for _, obj := range b.Releases {
+		appName, ok := owners[key(obj.Namespace, obj.Name)]
+		if !ok {
+			continue
+		}
+
+		uid, ok := uids[key(obj.Namespace, appName)]
+		if !ok {
+			continue
+		}
+
+		if err := setApplicationOwner(client, obj.Namespace, obj.Name, appName, uid); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// create calls createFunc, and records the object it creates as created
+// or existing.
+func (r *RestoreResult) create(kind string, meta *metav1.ObjectMeta, createFunc func() error) error {
+	name := fmt.Sprintf("%s %s", kind, key(meta.Namespace, meta.Name))
+
+	err := createFunc()
+	if kerrors.IsAlreadyExists(err) {
+		r.Existing = append(r.Existing, name)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error creating %s: %s", name, err)
+	}
+
+	r.Created = append(r.Created, name)
+
+	return nil
+}
+
+// setApplicationOwner makes the release belong to the application with the
+// given name and UID again, unless it already belongs to an application.
+func setApplicationOwner(client shipperclientset.Interface, namespace, name, appName string, uid types.UID) error {
+	rel, err := client.ShipperV1alpha1().Releases(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error fetching Release %s/%s: %s", namespace, name, err)
+	}
+
+	for _, ref := range rel.OwnerReferences {
+		if ref.Kind == "Application" {
+			return nil
+		}
+	}
+
+	rel.OwnerReferences = append(rel.OwnerReferences, metav1.OwnerReference{
+		APIVersion: shipper.SchemeGroupVersion.String(),
+		Kind:       "Application",
+		Name:       appName,
+		UID:        uid,
+	})
+
+	if _, err := client.ShipperV1alpha1().Releases(namespace).Update(rel); err != nil {
+		return fmt.Errorf("error updating Release %s/%s: %s", namespace, name, err)
+	}
+
+	return nil
+}
+
+// resetObjectMeta clears the fields the API server sets on creation.
+func resetObjectMeta(meta *metav1.ObjectMeta) {
+	meta.UID = ""
+	meta.ResourceVersion = ""
+	meta.SelfLink = ""
+	meta.Generation = 0
+	meta.CreationTimestamp = metav1.Time{}
+	meta.DeletionTimestamp = nil
+	meta.DeletionGracePeriodSeconds = nil
+}
+
+func key(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
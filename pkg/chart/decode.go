@@ -0,0 +1,32 @@
+package chart
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Decode decodes a rendered manifest. Kinds the Kubernetes client doesn't
+// know about, such as CustomResourceDefinitions and the custom resources
+// charts create with them, are decoded as unstructured objects.
+func Decode(manifest string) (runtime.Object, *schema.GroupVersionKind, error) {
+	obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode([]byte(manifest), nil, nil)
+	if err == nil || !runtime.IsNotRegisteredError(err) {
+		return obj, gvk, err
+	}
+
+	json, err := yaml.ToJSON([]byte(manifest))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return unstructured.UnstructuredJSONScheme.Decode(json, nil, nil)
+}
+
+// IsCustomResourceDefinition returns whether gvk is the kind of a
+// CustomResourceDefinition, in any version.
+func IsCustomResourceDefinition(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
@@ -6,7 +6,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/kubernetes/scheme"
 )
 
 // SortOrder is an ordering of Kinds.
@@ -15,31 +14,38 @@ type SortOrder []string
 // InstallOrder is the order in which manifests should be installed (by Kind).
 //
 // Those occurring earlier in the list get installed before those occurring later in the list.
+// Jobs go before long-running workloads so a readiness barrier on them can
+// hold those back until, say, database migrations are done.
 var InstallOrder SortOrder = []string{
 	"Namespace",
 	"ResourceQuota",
 	"LimitRange",
-	"Secret",
-	"ConfigMap",
-	"StorageClass",
-	"PersistentVolume",
-	"PersistentVolumeClaim",
-	"ServiceAccount",
 	"CustomResourceDefinition",
+	"ServiceAccount",
 	"ClusterRole",
 	"ClusterRoleBinding",
 	"Role",
 	"RoleBinding",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
 	"Service",
+	"Job",
 	"DaemonSet",
 	"Pod",
 	"ReplicationController",
 	"ReplicaSet",
 	"Deployment",
 	"StatefulSet",
-	"Job",
 	"CronJob",
+	"HorizontalPodAutoscaler",
+	"PodDisruptionBudget",
 	"Ingress",
+	"Gateway",
+	"VirtualService",
+	"DestinationRule",
 	"APIService",
 }
 
@@ -48,32 +54,49 @@ var InstallOrder SortOrder = []string{
 // Those occurring earlier in the list get uninstalled before those occurring later in the list.
 var UninstallOrder SortOrder = []string{
 	"APIService",
+	"DestinationRule",
+	"VirtualService",
+	"Gateway",
 	"Ingress",
+	"PodDisruptionBudget",
+	"HorizontalPodAutoscaler",
 	"Service",
 	"CronJob",
-	"Job",
 	"StatefulSet",
 	"Deployment",
 	"ReplicaSet",
 	"ReplicationController",
 	"Pod",
 	"DaemonSet",
-	"RoleBinding",
-	"Role",
-	"ClusterRoleBinding",
-	"ClusterRole",
-	"CustomResourceDefinition",
-	"ServiceAccount",
+	"Job",
 	"PersistentVolumeClaim",
 	"PersistentVolume",
 	"StorageClass",
 	"ConfigMap",
 	"Secret",
+	"RoleBinding",
+	"Role",
+	"ClusterRoleBinding",
+	"ClusterRole",
+	"ServiceAccount",
+	"CustomResourceDefinition",
 	"LimitRange",
 	"ResourceQuota",
 	"Namespace",
 }
 
+// Index returns the position of kind in s. Kinds s doesn't know about go
+// after every kind it does.
+func (s SortOrder) Index(kind string) int {
+	for i, k := range s {
+		if k == kind {
+			return i
+		}
+	}
+
+	return len(s)
+}
+
 type extendedManifest struct {
 	manifest        string
 	gvk             *schema.GroupVersionKind
@@ -94,7 +117,7 @@ func newKindSorter(m []string, s SortOrder) (*kindSorter, error) {
 
 	var ems []extendedManifest
 	for _, s := range m {
-		if decodedManifest, gvk, err := scheme.Codecs.UniversalDeserializer().Decode([]byte(s), nil, nil); err != nil {
+		if decodedManifest, gvk, err := Decode(s); err != nil {
 			return nil, fmt.Errorf("could not decode manifest: %s", err)
 		} else if object, ok := decodedManifest.(metav1.Object); !ok {
 			return nil, fmt.Errorf("object does not implement metaV1.Object")
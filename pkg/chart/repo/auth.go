@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/bookingcom/shipper/pkg/metrics/instrumentedclient"
+)
+
+// Credentials are what a chart repository needs charts to be fetched with.
+// Any of them can be left out.
+type Credentials struct {
+	// CABundle is a PEM encoded bundle of certificate authorities
+	// trusted on top of the system's.
+	CABundle []byte
+	// ClientCert and ClientKey are a PEM encoded client certificate and
+	// its key.
+	ClientCert []byte
+	ClientKey  []byte
+	// Username and Password are used for basic authentication.
+	Username string
+	Password string
+}
+
+// NewRemoteFetcher returns a RemoteFetcher for the chart repository at
+// repoURL that fetches with creds. Basic authentication is only sent to the
+// host of repoURL, so that charts downloaded from anywhere else don't get
+// the repository's credentials.
+func NewRemoteFetcher(repoURL string, creds Credentials) (RemoteFetcher, error) {
+	parsed, err := url.ParseRequestURI(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repo URL: %v", err)
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if len(creds.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(creds.CABundle) {
+			return nil, fmt.Errorf("CA bundle has no valid PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(creds.ClientCert) > 0 || len(creds.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(creds.ClientCert, creds.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	client := instrumentedclient.NewTLSClient(tlsConfig)
+
+	return func(fetchURL string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, fetchURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if creds.Username != "" || creds.Password != "" {
+			if req.URL.Host == parsed.Host {
+				req.SetBasicAuth(creds.Username, creds.Password)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("bad response code: %s (%d)", resp.Status, resp.StatusCode)
+		}
+
+		return ioutil.ReadAll(resp.Body)
+	}, nil
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRemoteFetcherAuthenticates verifies that fetchers trust the CA bundle
+// they're given, and only send their credentials to their repository.
+func TestRemoteFetcherAuthenticates(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "shipper" || password != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte("index"))
+	})
+
+	repoServer := httptest.NewTLSServer(handler)
+	defer repoServer.Close()
+
+	otherServer := httptest.NewTLSServer(handler)
+	defer otherServer.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: repoServer.Certificate().Raw})
+	caBundle = append(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherServer.Certificate().Raw})...)
+
+	anonymous, err := NewRemoteFetcher(repoServer.URL, Credentials{CABundle: caBundle})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := anonymous(repoServer.URL + "/index.yaml"); err == nil {
+		t.Fatalf("expected fetching without credentials to fail")
+	}
+
+	authenticated, err := NewRemoteFetcher(repoServer.URL, Credentials{
+		CABundle: caBundle,
+		Username: "shipper",
+		Password: "s3cr3t",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := authenticated(repoServer.URL + "/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "index" {
+		t.Fatalf("expected to fetch %q, got %q", "index", data)
+	}
+
+	if _, err := authenticated(otherServer.URL + "/chart.tgz"); err == nil {
+		t.Fatalf("expected credentials not to be sent to another host")
+	}
+
+	untrusted, err := NewRemoteFetcher(repoServer.URL, Credentials{Username: "shipper", Password: "s3cr3t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := untrusted(repoServer.URL + "/index.yaml"); err == nil {
+		t.Fatalf("expected the repository's certificate not to be trusted without the CA bundle")
+	}
+}
+
+func TestRemoteFetcherRejectsInvalidCredentials(t *testing.T) {
+	if _, err := NewRemoteFetcher("https://charts.example.com", Credentials{CABundle: []byte("not a certificate")}); err == nil {
+		t.Errorf("expected an invalid CA bundle to be rejected")
+	}
+
+	if _, err := NewRemoteFetcher("https://charts.example.com", Credentials{ClientCert: []byte("not a certificate")}); err == nil {
+		t.Errorf("expected an invalid client certificate to be rejected")
+	}
+}
@@ -6,12 +6,19 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	"github.com/bookingcom/shipper/pkg/metrics/instrumentedclient"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 )
 
+// DefaultConcurrentRequests is how many requests Shipper makes to chart
+// repositories at once by default.
+const DefaultConcurrentRequests = 10
+
 type RemoteFetcher func(url string) ([]byte, error)
 
 func DefaultRemoteFetcher(url string) ([]byte, error) {
@@ -40,11 +47,23 @@ func DefaultFileCacheFactory(cacheDir string) CacheFactory {
 	}
 }
 
+// RepoOptions are how a Repo talks to its chart repository, when it's
+// not the way every other one does.
+type RepoOptions struct {
+	Fetcher            RemoteFetcher
+	IndexRefreshPeriod time.Duration
+}
+
 type Catalog struct {
 	factory CacheFactory
 	repos   map[string]*Repo
 	fetcher RemoteFetcher
 	stopCh  <-chan struct{}
+	// options are keyed by the names of the repos they apply to.
+	options map[string]RepoOptions
+	// limiter holds a token for every request being made to chart
+	// repositories, when there's a limit to how many can be.
+	limiter chan struct{}
 	sync.Mutex
 }
 
@@ -54,6 +73,87 @@ func NewCatalog(factory CacheFactory, fetcher RemoteFetcher, stopCh <-chan struc
 		repos:   make(map[string]*Repo),
 		fetcher: fetcher,
 		stopCh:  stopCh,
+		options: make(map[string]RepoOptions),
+	}
+}
+
+// Configure makes the repo at repoURL use opts, whether it's already in
+// the catalog or not. Trailing slashes in repoURL don't matter. A nil
+// opts makes it go back to the catalog's fetcher and the default refresh
+// period.
+func (c *Catalog) Configure(repoURL string, opts *RepoOptions) {
+	c.Lock()
+	defer c.Unlock()
+
+	name := optionsName(repoURL)
+	if opts == nil {
+		delete(c.options, name)
+	} else {
+		c.options[name] = *opts
+	}
+
+	for _, repo := range c.repos {
+		if optionsName(repo.repoURL) == name {
+			repo.Configure(c.repoOptions(repo.repoURL))
+		}
+	}
+}
+
+// LimitConcurrentRequests makes the repos in the catalog make at most n
+// requests to chart repositories at once, between all of them. Requests
+// over the limit wait for others to finish. An n of 0 lifts the limit.
+func (c *Catalog) LimitConcurrentRequests(n int) {
+	c.Lock()
+	defer c.Unlock()
+
+	if n > 0 {
+		c.limiter = make(chan struct{}, n)
+	} else {
+		c.limiter = nil
+	}
+
+	for _, repo := range c.repos {
+		repo.Configure(c.repoOptions(repo.repoURL))
+	}
+}
+
+// repoOptions returns the fetcher and refresh period of the repo at
+// repoURL. The catalog must be locked.
+func (c *Catalog) repoOptions(repoURL string) (RemoteFetcher, time.Duration) {
+	fetcher, refreshPeriod := c.fetcher, RepoIndexRefreshPeriod
+
+	if opts, ok := c.options[optionsName(repoURL)]; ok {
+		if opts.Fetcher != nil {
+			fetcher = opts.Fetcher
+		}
+		if opts.IndexRefreshPeriod > 0 {
+			refreshPeriod = opts.IndexRefreshPeriod
+		}
+	}
+
+	return limitRequests(fetcher, c.limiter), refreshPeriod
+}
+
+// limitRequests returns a RemoteFetcher that fetches with fetcher once it
+// gets a token from limiter, if it's not nil, and gives it back when it's
+// done.
+func limitRequests(fetcher RemoteFetcher, limiter chan struct{}) RemoteFetcher {
+	return func(url string) ([]byte, error) {
+		if limiter != nil {
+			select {
+			case limiter <- struct{}{}:
+			default:
+				shippermetrics.ChartRepoRequestsWaiting.Inc()
+				limiter <- struct{}{}
+				shippermetrics.ChartRepoRequestsWaiting.Dec()
+			}
+			defer func() { <-limiter }()
+		}
+
+		shippermetrics.ChartRepoRequestsInFlight.Inc()
+		defer shippermetrics.ChartRepoRequestsInFlight.Dec()
+
+		return fetcher(url)
 	}
 }
 
@@ -74,13 +174,19 @@ func (c *Catalog) CreateRepoIfNotExist(repoURL string) (*Repo, error) {
 				fmt.Errorf("failed to create cache: %v", err),
 			)
 		}
-		repo, err = NewRepo(repoURL, cache, c.fetcher)
+		fetcher, refreshPeriod := c.repoOptions(repoURL)
+		repo, err = NewRepo(repoURL, cache, fetcher)
 		if err != nil {
 			return nil, err
 		}
+		repo.Configure(fetcher, refreshPeriod)
 		c.repos[name] = repo
 		go repo.Start(c.stopCh)
 	}
 
 	return repo, nil
 }
+
+func optionsName(repoURL string) string {
+	return url2name(strings.TrimSuffix(repoURL, "/"))
+}
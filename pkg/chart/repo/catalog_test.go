@@ -5,6 +5,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 )
 
 type TestCache struct {
@@ -96,3 +97,113 @@ func TestCreateRepoIfNotExist(t *testing.T) {
 		})
 	}
 }
+
+// TestCatalogConfigure verifies that repos fetch with the options they're
+// configured with, whether they're configured before or after they're
+// created, until they're configured back to the defaults.
+func TestCatalogConfigure(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	testCacheFactory := func(name string) (Cache, error) {
+		return NewTestCache(name), nil
+	}
+	defaultFetcher := func(_ string) ([]byte, error) {
+		return []byte("default"), nil
+	}
+	configuredFetcher := func(_ string) ([]byte, error) {
+		return []byte("configured"), nil
+	}
+
+	c := NewCatalog(testCacheFactory, defaultFetcher, stopCh)
+	c.Configure("https://charts.example.com/", &RepoOptions{Fetcher: configuredFetcher})
+
+	repo, err := c.CreateRepoIfNotExist("https://charts.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectFetched := func(expected string) {
+		t.Helper()
+
+		fetcher, refreshPeriod := repo.remote()
+		if refreshPeriod != RepoIndexRefreshPeriod {
+			t.Errorf("expected the default refresh period, got %s", refreshPeriod)
+		}
+
+		data, _ := fetcher("https://charts.example.com/index.yaml")
+		if string(data) != expected {
+			t.Errorf("expected repo to fetch with the %s fetcher, got the %s one", expected, data)
+		}
+	}
+
+	expectFetched("configured")
+
+	c.Configure("https://charts.example.com", nil)
+	expectFetched("default")
+}
+
+// TestCatalogLimitsConcurrentRequests verifies that repos don't make more
+// requests to chart repositories at once than the catalog allows.
+func TestCatalogLimitsConcurrentRequests(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	const limit = 2
+
+	var mutex sync.Mutex
+	var active, maxActive int
+	release := make(chan struct{})
+	fetcher := func(_ string) ([]byte, error) {
+		mutex.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+
+		<-release
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+
+		return nil, nil
+	}
+
+	c := NewCatalog(func(name string) (Cache, error) {
+		return NewTestCache(name), nil
+	}, fetcher, stopCh)
+	c.LimitConcurrentRequests(limit)
+
+	repos := make([]*Repo, 0, limit+1)
+	for _, repoURL := range []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"} {
+		repo, err := c.CreateRepoIfNotExist(repoURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, repo)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, repo := range repos {
+		fetch, _ := repo.remote()
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fetch("chart.tgz")
+			}()
+		}
+	}
+
+	// Give the requests over the limit a chance to go through if they
+	// could, before letting any of them finish.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if maxActive != limit {
+		t.Fatalf("expected at most %d requests at once, got %d", limit, maxActive)
+	}
+}
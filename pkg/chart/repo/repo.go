@@ -20,7 +20,6 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/Masterminds/semver"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/repo"
@@ -28,6 +27,7 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 )
 
 const (
@@ -41,12 +41,29 @@ type Repo struct {
 	repoURL  string
 	indexURL string
 	cache    Cache
-	fetcher  RemoteFetcher
 	mutex    sync.RWMutex
 	index    *repo.IndexFile
 	lastErr  error
 	resolved chan struct{}
 	once     sync.Once
+
+	// fetcher and refreshPeriod can be changed with Configure, and
+	// are guarded by mutex.
+	fetcher       RemoteFetcher
+	refreshPeriod time.Duration
+
+	// inflight are the charts being downloaded, by their file name in
+	// the cache, so that concurrent fetches of the same chart download
+	// it only once.
+	inflight   map[string]*chartDownload
+	inflightMu sync.Mutex
+}
+
+// chartDownload is a chart being downloaded. done is closed once err is
+// set.
+type chartDownload struct {
+	done chan struct{}
+	err  error
 }
 
 func NewRepo(repoURL string, cache Cache, fetcher RemoteFetcher) (*Repo, error) {
@@ -63,19 +80,47 @@ func NewRepo(repoURL string, cache Cache, fetcher RemoteFetcher) (*Repo, error)
 		repoURL:  repoURL,
 		indexURL: indexURL,
 		cache:    cache,
-		fetcher:  fetcher,
 		resolved: make(chan struct{}),
+
+		fetcher:       fetcher,
+		refreshPeriod: RepoIndexRefreshPeriod,
+
+		inflight: make(map[string]*chartDownload),
 	}
 
 	return r, nil
 }
 
+// Configure makes r fetch its index and charts with fetcher from now on,
+// and refresh its index every refreshPeriod after the next refresh.
+func (r *Repo) Configure(fetcher RemoteFetcher, refreshPeriod time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.fetcher = fetcher
+	r.refreshPeriod = refreshPeriod
+}
+
+func (r *Repo) remote() (RemoteFetcher, time.Duration) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.fetcher, r.refreshPeriod
+}
+
 func (r *Repo) Start(stopCh <-chan struct{}) {
-	wait.Until(func() {
+	for {
 		if err := r.refreshIndex(); err != nil {
 			klog.Errorf("failed to refresh repo %q index: %s", r.repoURL, err)
 		}
-	}, RepoIndexRefreshPeriod, stopCh)
+
+		_, refreshPeriod := r.remote()
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(refreshPeriod):
+		}
+	}
 }
 
 func (r *Repo) refreshIndex() error {
@@ -83,7 +128,8 @@ func (r *Repo) refreshIndex() error {
 	var err error
 	var index *repo.IndexFile
 
-	data, err = r.fetcher(r.indexURL)
+	fetcher, _ := r.remote()
+	data, err = fetcher(r.indexURL)
 	if err != nil {
 		_, cacheErr := r.cache.Fetch("index.yaml")
 		if cacheErr != nil {
@@ -260,7 +306,8 @@ func (r *Repo) FetchRemote(cv *repo.ChartVersion) (*chart.Chart, error) {
 	}
 
 	url := chartURL.String()
-	data, err := r.fetcher(url)
+	fetcher, _ := r.remote()
+	data, err := fetcher(url)
 	if err != nil {
 		chart, convErr := newChart(cv)
 		if convErr != nil {
@@ -299,11 +346,55 @@ func (r *Repo) Fetch(chartspec *shipper.Chart) (*chart.Chart, error) {
 
 	chartver := versions[ix]
 
+	// Releases pin the digest of their chart, so a chart republished
+	// under the same version doesn't change them behind their back.
+	if chartspec.Digest != "" && chartver.Digest != chartspec.Digest {
+		return nil, shippererrors.NewChartDigestMismatchError(chartspec, chartver.Digest)
+	}
+
 	if chart, err := r.LoadCached(chartver); err == nil {
+		shippermetrics.ChartCacheLookups.WithLabelValues("hit").Inc()
 		return chart, nil
 	}
 
-	return r.FetchRemote(chartver)
+	return r.fetchRemoteOnce(chartver)
+}
+
+// fetchRemoteOnce is FetchRemote, except that when the chart is already
+// being downloaded it waits for that download and loads the chart from
+// the cache instead of downloading it again. Every caller gets a chart of
+// its own, as charts are modified while they're rendered.
+func (r *Repo) fetchRemoteOnce(cv *repo.ChartVersion) (*chart.Chart, error) {
+	filename := chart2file(cv)
+
+	r.inflightMu.Lock()
+	if download, ok := r.inflight[filename]; ok {
+		r.inflightMu.Unlock()
+		shippermetrics.ChartCacheLookups.WithLabelValues("coalesced").Inc()
+
+		<-download.done
+		if download.err != nil {
+			return nil, download.err
+		}
+
+		return r.LoadCached(cv)
+	}
+
+	download := &chartDownload{done: make(chan struct{})}
+	r.inflight[filename] = download
+	r.inflightMu.Unlock()
+	shippermetrics.ChartCacheLookups.WithLabelValues("miss").Inc()
+
+	chart, err := r.FetchRemote(cv)
+
+	r.inflightMu.Lock()
+	delete(r.inflight, filename)
+	r.inflightMu.Unlock()
+
+	download.err = err
+	close(download.done)
+
+	return chart, err
 }
 
 func loadIndexData(data []byte) (*repo.IndexFile, error) {
@@ -8,7 +8,9 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
@@ -400,6 +402,43 @@ func TestFetch(t *testing.T) {
 	}
 }
 
+func TestFetchChecksDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		digest  string
+		wanterr bool
+	}{
+		{"no pinned digest", "", false},
+		{"matching digest", "99c76e403d752c84ead610644d4b1c2f2b453a74b921f422b9dcb8a7c8b559cd", false},
+		{"republished chart", "0000000000000000000000000000000000000000000000000000000000000000", true},
+	}
+
+	for _, tt := range tests {
+		repo, err := NewRepo(
+			"https://chart.example.com",
+			NewTestCache("test-cache-digest"),
+			localFetch(t),
+		)
+		if err != nil {
+			t.Fatalf("failed to initialize repo: %s", err)
+		}
+		if err := repo.refreshIndex(); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		_, err = repo.Fetch(&shipper.Chart{
+			Name:    "simple",
+			Version: "0.0.1",
+			RepoURL: repo.repoURL,
+			Digest:  tt.digest,
+		})
+
+		if _, ok := err.(shippererrors.ChartDigestMismatchError); ok != tt.wanterr {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+	}
+}
+
 func TestFetchChartVersionsTimesOut(t *testing.T) {
 	cache := NewTestCache("test-cache")
 	repo, err := NewRepo(
@@ -503,3 +542,68 @@ func equivalent(err1, err2 error) bool {
 	}
 	return false
 }
+
+func TestConcurrentFetchDownloadsChartOnce(t *testing.T) {
+	var downloads int32
+	release := make(chan struct{})
+	fetch := localFetch(t)
+
+	repo, err := NewRepo(
+		"https://chart.example.com",
+		NewTestCache("test-cache"),
+		func(url string) ([]byte, error) {
+			if strings.HasSuffix(url, ".tgz") {
+				atomic.AddInt32(&downloads, 1)
+				<-release
+			}
+			return fetch(url)
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize repo: %s", err)
+	}
+	if err := repo.refreshIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	chartspec := &shipper.Chart{
+		Name:    "simple",
+		Version: "0.0.1",
+		RepoURL: repo.repoURL,
+	}
+
+	fetchChart := func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		if _, err := repo.Fetch(chartspec); err != nil {
+			t.Errorf("unexpected error fetching chart: %s", err)
+		}
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go fetchChart(wg)
+
+	// Everyone else starts once the first download is under way, so
+	// they either wait for it or find the chart in the cache.
+	for {
+		repo.inflightMu.Lock()
+		n := len(repo.inflight)
+		repo.inflightMu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go fetchChart(wg)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if downloads != 1 {
+		t.Fatalf("expected chart to be downloaded once, got %d downloads", downloads)
+	}
+}
@@ -2,8 +2,11 @@ package chart
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 )
 
 func GetDeployments(rawRendered []string) []appsv1.Deployment {
@@ -33,3 +36,32 @@ func GetDeployments(rawRendered []string) []appsv1.Deployment {
 
 	return deployments
 }
+
+// GetJobs returns the Jobs among rawRendered objects, leaving out step hooks,
+// which are only templates for Jobs run by the strategy.
+func GetJobs(rawRendered []string) []batchv1.Job {
+	var jobs []batchv1.Job
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+
+	for _, raw := range rawRendered {
+		var j batchv1.Job
+		obj, _, err := decoder.Decode([]byte(raw), nil, &j)
+		if err != nil {
+			klog.Warningf("failed to unmarshal a job: %s", err)
+			continue
+		}
+
+		if obj.GetObjectKind().GroupVersionKind().Kind != "Job" {
+			continue
+		}
+
+		if _, ok := j.Annotations[shipper.StepHookAnnotation]; ok {
+			continue
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs
+}
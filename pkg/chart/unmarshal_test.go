@@ -36,6 +36,38 @@ spec:
   type: ClusterIP
 `
 
+const jobText = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: my-batch-job
+  namespace: default
+spec:
+  parallelism: 4
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: my-batch-job
+          image: "busybox:stable"
+`
+
+const stepHookText = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: my-smoke-test
+  annotations:
+    shipper.booking.com/step-hook: smoke-test
+spec:
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: smoke-test
+          image: "busybox:stable"
+`
+
 const garbage = `
 apiVersion: huh?
 kind: What
@@ -65,3 +97,17 @@ func TestGetDeploymentsValid(t *testing.T) {
 		t.Errorf("expected %d replicas but got %d", expectedReplicas, *d.Spec.Replicas)
 	}
 }
+
+func TestGetJobs(t *testing.T) {
+	jobs := GetJobs([]string{deploymentText, jobText, stepHookText, garbage})
+	if len(jobs) != 1 {
+		t.Fatalf("expected exactly one Job but got %d", len(jobs))
+	}
+
+	if name := jobs[0].GetName(); name != "my-batch-job" {
+		t.Errorf("expected name %q but got %q", "my-batch-job", name)
+	}
+	if parallelism := *jobs[0].Spec.Parallelism; parallelism != 4 {
+		t.Errorf("expected parallelism 4 but got %d", parallelism)
+	}
+}
@@ -0,0 +1,180 @@
+// Package valuesource resolves the chart values a release reads from secret
+// stores when its chart is rendered in a cluster, so they never need to be
+// stored in Shipper's own objects.
+package valuesource
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// Placeholder is what values read from secret stores are set to when a
+// chart is rendered outside of a cluster, e.g. to find out its replica
+// count.
+const Placeholder = "<resolved-at-install>"
+
+// Store reads values from an external secret store. Keys are in the store's
+// own format.
+type Store interface {
+	Get(namespace, key string) (string, error)
+}
+
+var (
+	storesMu sync.RWMutex
+	stores   = map[string]Store{}
+)
+
+// RegisterStore makes a store available to ExternalValueRefs under name.
+// Registering a store twice under the same name replaces it.
+func RegisterStore(name string, store Store) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	stores[name] = store
+}
+
+func getStore(name string) (Store, bool) {
+	storesMu.RLock()
+	defer storesMu.RUnlock()
+	store, ok := stores[name]
+	return store, ok
+}
+
+// Resolver resolves value sources in a single cluster.
+type Resolver struct {
+	kubeClient kubernetes.Interface
+}
+
+func NewResolver(kubeClient kubernetes.Interface) *Resolver {
+	return &Resolver{kubeClient: kubeClient}
+}
+
+// Resolve reads every source and returns values with them set, leaving
+// values untouched.
+func (r *Resolver) Resolve(
+	namespace string,
+	values shipper.ChartValues,
+	sources []shipper.ValueSource,
+) (shipper.ChartValues, error) {
+	return setValues(values, sources, func(source shipper.ValueSource) (string, error) {
+		return r.resolve(namespace, source)
+	})
+}
+
+func (r *Resolver) resolve(namespace string, source shipper.ValueSource) (string, error) {
+	if err := Validate(source); err != nil {
+		return "", err
+	}
+
+	if ref := source.SecretKeyRef; ref != nil {
+		secret, err := r.kubeClient.CoreV1().Secrets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", shippererrors.NewValueSourceError(source.Path, err)
+		}
+
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", shippererrors.NewValueSourceError(source.Path,
+				fmt.Errorf("secret %s/%s has no key %q", namespace, ref.Name, ref.Key))
+		}
+
+		return string(data), nil
+	}
+
+	ref := source.External
+	store, ok := getStore(ref.Store)
+	if !ok {
+		return "", shippererrors.NewInvalidValueSourceError(source.Path,
+			"no external secret store %q is configured", ref.Store)
+	}
+
+	value, err := store.Get(namespace, ref.Key)
+	if err != nil {
+		return "", shippererrors.NewValueSourceError(source.Path, err)
+	}
+
+	return value, nil
+}
+
+// WithPlaceholders returns values with every source set to Placeholder, so
+// charts that require them can be rendered without reading any secret.
+func WithPlaceholders(values shipper.ChartValues, sources []shipper.ValueSource) shipper.ChartValues {
+	withPlaceholders, _ := setValues(values, sources, func(shipper.ValueSource) (string, error) {
+		return Placeholder, nil
+	})
+	return withPlaceholders
+}
+
+// Validate ensures that a value source has a path and exactly one source.
+func Validate(source shipper.ValueSource) error {
+	if source.Path == "" || strings.HasPrefix(source.Path, ".") ||
+		strings.HasSuffix(source.Path, ".") || strings.Contains(source.Path, "..") {
+		return shippererrors.NewInvalidValueSourceError(source.Path, "path must be a dot-separated list of keys")
+	}
+
+	if (source.SecretKeyRef == nil) == (source.External == nil) {
+		return shippererrors.NewInvalidValueSourceError(source.Path,
+			"exactly one of secretKeyRef and external must be set")
+	}
+
+	return nil
+}
+
+func setValues(
+	values shipper.ChartValues,
+	sources []shipper.ValueSource,
+	resolve func(shipper.ValueSource) (string, error),
+) (shipper.ChartValues, error) {
+	if len(sources) == 0 {
+		return values, nil
+	}
+
+	var resolved shipper.ChartValues
+	if values != nil {
+		resolved = values.DeepCopy()
+	} else {
+		resolved = shipper.ChartValues{}
+	}
+
+	for _, source := range sources {
+		value, err := resolve(source)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setPath(resolved, source.Path, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+func setPath(values map[string]interface{}, path string, value string) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := values[key]
+		if !ok {
+			child := map[string]interface{}{}
+			values[key] = child
+			values = child
+			continue
+		}
+
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return shippererrors.NewInvalidValueSourceError(path, "%q is already set to something other than a map", key)
+		}
+		values = child
+	}
+
+	values[keys[len(keys)-1]] = value
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package valuesource
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const testNamespace = "test-namespace"
+
+type fakeStore map[string]string
+
+func (s fakeStore) Get(namespace, key string) (string, error) {
+	value, ok := s[namespace+"/"+key]
+	if !ok {
+		return "", fmt.Errorf("no key %q", key)
+	}
+	return value, nil
+}
+
+func newTestResolver() *Resolver {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: testNamespace,
+		},
+		Data: map[string][]byte{
+			"password": []byte("hunter2"),
+		},
+	}
+
+	return NewResolver(kubefake.NewSimpleClientset(secret))
+}
+
+func TestResolve(t *testing.T) {
+	RegisterStore("fake", fakeStore{testNamespace + "/api-token": "s3cr3t"})
+
+	values := shipper.ChartValues{
+		"replicaCount": float64(2),
+		"database": map[string]interface{}{
+			"host": "db.example.com",
+		},
+	}
+	original := values.DeepCopy()
+
+	sources := []shipper.ValueSource{
+		{
+			Path:         "database.password",
+			SecretKeyRef: &shipper.SecretKeyRef{Name: "db", Key: "password"},
+		},
+		{
+			Path:     "api.token",
+			External: &shipper.ExternalValueRef{Store: "fake", Key: "api-token"},
+		},
+	}
+
+	resolved, err := newTestResolver().Resolve(testNamespace, values, sources)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := shipper.ChartValues{
+		"replicaCount": float64(2),
+		"database": map[string]interface{}{
+			"host":     "db.example.com",
+			"password": "hunter2",
+		},
+		"api": map[string]interface{}{
+			"token": "s3cr3t",
+		},
+	}
+
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("expected %v, got %v", expected, resolved)
+	}
+
+	if !reflect.DeepEqual(values, original) {
+		t.Errorf("resolving must not modify the values it was given")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values shipper.ChartValues
+		source shipper.ValueSource
+		retry  bool
+	}{
+		{
+			name: "missing secret",
+			source: shipper.ValueSource{
+				Path:         "password",
+				SecretKeyRef: &shipper.SecretKeyRef{Name: "missing", Key: "password"},
+			},
+			retry: true,
+		},
+		{
+			name: "missing key",
+			source: shipper.ValueSource{
+				Path:         "password",
+				SecretKeyRef: &shipper.SecretKeyRef{Name: "db", Key: "username"},
+			},
+			retry: true,
+		},
+		{
+			name: "unknown store",
+			source: shipper.ValueSource{
+				Path:     "password",
+				External: &shipper.ExternalValueRef{Store: "unknown", Key: "password"},
+			},
+			retry: false,
+		},
+		{
+			name:   "path through a value that is not a map",
+			values: shipper.ChartValues{"database": "db.example.com"},
+			source: shipper.ValueSource{
+				Path:         "database.password",
+				SecretKeyRef: &shipper.SecretKeyRef{Name: "db", Key: "password"},
+			},
+			retry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := newTestResolver().Resolve(testNamespace, tt.values, []shipper.ValueSource{tt.source})
+		if _, ok := err.(shippererrors.ValueSourceError); !ok {
+			t.Errorf("%s: expected a ValueSourceError, got %v", tt.name, err)
+			continue
+		}
+
+		if shippererrors.ShouldRetry(err) != tt.retry {
+			t.Errorf("%s: expected retry to be %t for %s", tt.name, tt.retry, err)
+		}
+	}
+}
+
+func TestWithPlaceholders(t *testing.T) {
+	sources := []shipper.ValueSource{
+		{
+			Path:         "database.password",
+			SecretKeyRef: &shipper.SecretKeyRef{Name: "db", Key: "password"},
+		},
+	}
+
+	values := WithPlaceholders(nil, sources)
+	expected := shipper.ChartValues{
+		"database": map[string]interface{}{
+			"password": Placeholder,
+		},
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secretRef := &shipper.SecretKeyRef{Name: "db", Key: "password"}
+	externalRef := &shipper.ExternalValueRef{Store: "vault", Key: "db#password"}
+
+	tests := []struct {
+		name   string
+		source shipper.ValueSource
+		valid  bool
+	}{
+		{"secret", shipper.ValueSource{Path: "db.password", SecretKeyRef: secretRef}, true},
+		{"external", shipper.ValueSource{Path: "db.password", External: externalRef}, true},
+		{"no path", shipper.ValueSource{SecretKeyRef: secretRef}, false},
+		{"empty key in path", shipper.ValueSource{Path: "db..password", SecretKeyRef: secretRef}, false},
+		{"no source", shipper.ValueSource{Path: "db.password"}, false},
+		{"two sources", shipper.ValueSource{Path: "db.password", SecretKeyRef: secretRef, External: externalRef}, false},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.source); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package valuesource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// VaultStoreName is the name ExternalValueRefs use for the Vault
+	// store.
+	VaultStoreName = "vault"
+
+	// DefaultVaultPathPrefix keeps every namespace to its own secrets in
+	// the default KV v2 mount.
+	DefaultVaultPathPrefix = "secret/data/{namespace}"
+
+	vaultRequestTimeout = 10 * time.Second
+)
+
+// VaultStore reads values from HashiCorp Vault's HTTP API. Keys are
+// "<path>#<field>", with path relative to the store's path prefix, in which
+// "{namespace}" is replaced by the release's namespace. Secrets in both
+// version 1 and version 2 KV engines can be read.
+type VaultStore struct {
+	addr       string
+	token      string
+	pathPrefix string
+	client     *http.Client
+}
+
+func NewVaultStore(addr, token, pathPrefix string) *VaultStore {
+	return &VaultStore{
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		pathPrefix: pathPrefix,
+		client:     &http.Client{Timeout: vaultRequestTimeout},
+	}
+}
+
+func (s *VaultStore) Get(namespace, key string) (string, error) {
+	parts := strings.SplitN(key, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("vault key %q is not in the <path>#<field> format", key)
+	}
+
+	secretPath, field := parts[0], parts[1]
+	for _, segment := range strings.Split(secretPath, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("vault key %q can not refer to parent paths", key)
+		}
+	}
+
+	prefix := strings.Replace(s.pathPrefix, "{namespace}", namespace, -1)
+	u, err := url.Parse(s.addr + path.Join("/v1", prefix, secretPath))
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded to %q with %s", u.Path, resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode vault response for %q: %s", u.Path, err)
+	}
+
+	data := secret.Data
+	// KV version 2 secrets nest their fields under data.data.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, isMetadata := data["metadata"]; isMetadata {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %q has no field %q", u.Path, field)
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	return fmt.Sprintf("%v", value), nil
+}
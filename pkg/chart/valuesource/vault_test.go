@@ -0,0 +1,64 @@
+package valuesource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/test-namespace/db":
+			w.Write([]byte(`{"data": {"data": {"password": "hunter2"}, "metadata": {"version": 3}}}`))
+		case "/v1/secret/data/test-namespace/legacy":
+			w.Write([]byte(`{"data": {"password": "hunter3", "port": 5432}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := NewVaultStore(server.URL, "test-token", DefaultVaultPathPrefix)
+
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+		wantErr  bool
+	}{
+		{name: "kv version 2", key: "db#password", expected: "hunter2"},
+		{name: "kv version 1", key: "legacy#password", expected: "hunter3"},
+		{name: "non-string field", key: "legacy#port", expected: "5432"},
+		{name: "missing field", key: "db#username", wantErr: true},
+		{name: "missing secret", key: "other#password", wantErr: true},
+		{name: "no field", key: "db", wantErr: true},
+		{name: "parent path", key: "../other-namespace/db#password", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		value, err := store.Get("test-namespace", tt.key)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got value %q", tt.name, value)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		} else if value != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, value)
+		}
+	}
+
+	// Other namespaces are kept to their own secrets.
+	if _, err := store.Get("other-namespace", "db#password"); err == nil {
+		t.Errorf("expected a namespace to be unable to read another namespace's secrets")
+	}
+}
@@ -0,0 +1,517 @@
+package ciapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+const (
+	AgentName = "ci-api"
+
+	releasesPrefix = "/releases/"
+	bearerPrefix   = "Bearer "
+
+	defaultWaitTimeout = 5 * time.Minute
+	maxWaitTimeout     = 30 * time.Minute
+	pollInterval       = time.Second
+
+	// Requests that wait for a step are only answered once it's achieved
+	// or they time out, so they get maxWaitTimeout to be written on top of
+	// how long it takes to read them.
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = readTimeout + maxWaitTimeout + time.Minute
+	idleTimeout       = 2 * time.Minute
+)
+
+type tokenContextKey struct{}
+
+// Server is a small HTTP API meant to be called from CI pipelines. It
+// allows advancing a Release to a given strategy step and waiting until that
+// step is achieved, so pipelines don't have to poll the Kubernetes API
+// themselves.
+//
+//	GET  /releases/<namespace>/<name>?step=<n>&timeout=<duration>
+//	POST /releases/<namespace>/<name>/advance {"step": <n>}
+//	POST /releases/<namespace>/<name>/approve {"step": <n>}
+//
+// All endpoints return the state of the Release. When a step is given, the
+// request blocks until the step is achieved or the timeout expires.
+// Requests must carry one of the configured tokens as a bearer token, so
+// the API is served over TLS whenever a certificate and key are given.
+type Server struct {
+	shipperClientset clientset.Interface
+	releaseLister    listers.ReleaseLister
+	releasesSynced   cache.InformerSynced
+
+	addr   string
+	tokens []Token
+
+	// approver is who approvals are recorded on behalf of. It has to be
+	// the user shipperClientset authenticates as, for the admission
+	// webhook to accept them.
+	approver string
+
+	tlsCertFile       string
+	tlsPrivateKeyFile string
+}
+
+// ReleaseState is the representation of a Release returned by the API.
+type ReleaseState struct {
+	Namespace        string                     `json:"namespace"`
+	Name             string                     `json:"name"`
+	TargetStep       int32                      `json:"targetStep"`
+	AchievedStep     *int32                     `json:"achievedStep,omitempty"`
+	AchievedStepName string                     `json:"achievedStepName,omitempty"`
+	Achieved         bool                       `json:"achieved"`
+	Complete         bool                       `json:"complete"`
+	Conditions       []shipper.ReleaseCondition `json:"conditions,omitempty"`
+}
+
+// Token is a bearer token the API accepts, for releases in Scopes only, or
+// for every release if it has none.
+type Token struct {
+	Value  string
+	Scopes []TokenScope
+}
+
+// TokenScope is a namespace, or a single application in it if Application
+// is set.
+type TokenScope struct {
+	Namespace   string
+	Application string
+}
+
+type stepRequest struct {
+	Step *int32 `json:"step"`
+}
+
+func NewServer(
+	addr string,
+	tokens []Token,
+	approver string,
+	tlsCertFile, tlsPrivateKeyFile string,
+	shipperClientset clientset.Interface,
+	shipperInformerFactory informers.SharedInformerFactory,
+) *Server {
+	releaseInformer := shipperInformerFactory.Shipper().V1alpha1().Releases()
+
+	return &Server{
+		shipperClientset: shipperClientset,
+		releaseLister:    releaseInformer.Lister(),
+		releasesSynced:   releaseInformer.Informer().HasSynced,
+
+		addr:     addr,
+		tokens:   tokens,
+		approver: approver,
+
+		tlsCertFile:       tlsCertFile,
+		tlsPrivateKeyFile: tlsPrivateKeyFile,
+	}
+}
+
+// ReadTokens reads a file containing one token per line, optionally
+// followed by the namespaces, or <namespace>/<application>s, it's limited
+// to, separated by spaces. Empty lines and lines starting with # are
+// ignored.
+func ReadTokens(path string) ([]Token, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []Token
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		token := Token{Value: fields[0]}
+		for _, field := range fields[1:] {
+			parts := strings.Split(field, "/")
+			if len(parts) > 2 || parts[0] == "" || (len(parts) == 2 && parts[1] == "") {
+				return nil, fmt.Errorf("invalid scope %q on line %d of %q: expected <namespace> or <namespace>/<application>", field, i+1, path)
+			}
+
+			scope := TokenScope{Namespace: parts[0]}
+			if len(parts) == 2 {
+				scope.Application = parts[1]
+			}
+			token.Scopes = append(token.Scopes, scope)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens found in %q", path)
+	}
+
+	return tokens, nil
+}
+
+func (s *Server) Run(stopCh <-chan struct{}) {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.authenticate(http.HandlerFunc(s.handleRelease)),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	if !cache.WaitForCacheSync(stopCh, s.releasesSynced) {
+		klog.Fatalf("failed to wait for caches to sync")
+		return
+	}
+
+	go func() {
+		var err error
+		if s.tlsCertFile == "" || s.tlsPrivateKeyFile == "" {
+			err = server.ListenAndServe()
+		} else {
+			err = server.ListenAndServeTLS(s.tlsCertFile, s.tlsPrivateKeyFile)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			klog.Fatalf("failed to start CI API: %v", err)
+		}
+	}()
+
+	klog.V(2).Infof("Started the CI API on %s", s.addr)
+
+	<-stopCh
+
+	klog.V(2).Info("Shutting down the CI API")
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		klog.Errorf(`HTTP server Shutdown: %v`, err)
+	}
+}
+
+// authenticate only lets requests with one of s.tokens as a bearer token
+// through, with the token in their context.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, bearerPrefix) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		token := strings.TrimPrefix(header, bearerPrefix)
+		for _, t := range s.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t.Value)) == 1 {
+				t := t
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, &t)))
+				return
+			}
+		}
+
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// allowed returns whether token can be used for releases of application in
+// namespace. An empty application only checks the namespace.
+func allowed(token *Token, namespace, application string) bool {
+	if len(token.Scopes) == 0 {
+		return true
+	}
+
+	for _, scope := range token.Scopes {
+		if scope.Namespace != namespace {
+			continue
+		}
+
+		if scope.Application == "" || application == "" || scope.Application == application {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) handleRelease(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, releasesPrefix), "/")
+	if !strings.HasPrefix(r.URL.Path, releasesPrefix) || len(parts) < 2 || len(parts) > 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	namespace, name := parts[0], parts[1]
+
+	token, _ := r.Context().Value(tokenContextKey{}).(*Token)
+	if token == nil || !allowed(token, namespace, "") {
+		http.Error(w, fmt.Sprintf("token is not allowed in namespace %q", namespace), http.StatusForbidden)
+		return
+	}
+
+	rel, err := s.releaseLister.Releases(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		http.Error(w, fmt.Sprintf("release %s/%s not found", namespace, name), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if app := rel.Labels[shipper.AppLabel]; !allowed(token, namespace, app) {
+		http.Error(w, fmt.Sprintf("token is not allowed for application %q", app), http.StatusForbidden)
+		return
+	}
+
+	var step *int32
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		if v := r.URL.Query().Get("step"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid step %q", v), http.StatusBadRequest)
+				return
+			}
+			n32 := int32(n)
+			step = &n32
+		}
+	case len(parts) == 3 && parts[2] == "advance" && r.Method == http.MethodPost:
+		step = decodeStep(w, r, rel)
+		if step == nil {
+			return
+		}
+
+		rel, err = s.advance(rel, *step)
+		if err != nil {
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+	case len(parts) == 3 && parts[2] == "approve" && r.Method == http.MethodPost:
+		step = decodeStep(w, r, rel)
+		if step == nil {
+			return
+		}
+
+		if status, err := s.validateApproval(rel, *step); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		rel, err = s.approve(rel, *step)
+		if err != nil {
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+
+		// Approving a step the release isn't moving to has nothing to
+		// wait for yet.
+		if rel.Spec.TargetStep != *step {
+			step = nil
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if step != nil {
+		timeout, err := parseTimeout(r.URL.Query().Get("timeout"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rel = s.waitForStep(r.Context(), rel, *step, timeout)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildReleaseState(rel, step))
+}
+
+// decodeStep decodes the step of a request for rel, and responds to it
+// with an error and returns nil if there's none or rel's strategy doesn't
+// have it.
+func decodeStep(w http.ResponseWriter, r *http.Request, rel *shipper.Release) *int32 {
+	var req stepRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Step == nil {
+		http.Error(w, `expected a body like {"step": <n>}`, http.StatusBadRequest)
+		return nil
+	}
+
+	if err := validateStep(rel, *req.Step); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	return req.Step
+}
+
+// validateStep returns an error unless step is one of the steps of rel's
+// strategy.
+func validateStep(rel *shipper.Release, step int32) error {
+	if rel.Spec.Environment.Strategy == nil {
+		return fmt.Errorf("release %s/%s has no strategy", rel.Namespace, rel.Name)
+	}
+
+	numSteps := int32(len(rel.Spec.Environment.Strategy.Steps))
+	if step < 0 || step >= numSteps {
+		return fmt.Errorf("step %d out of range, release has %d steps", step, numSteps)
+	}
+
+	return nil
+}
+
+func (s *Server) advance(rel *shipper.Release, step int32) (*shipper.Release, error) {
+	if rel.Spec.TargetStep == step {
+		return rel, nil
+	}
+
+	rel = rel.DeepCopy()
+	rel.Spec.TargetStep = step
+
+	return s.shipperClientset.ShipperV1alpha1().Releases(rel.Namespace).Update(rel)
+}
+
+// validateApproval returns an error, and the HTTP status to respond with,
+// unless step of rel requires approval and s.approver is allowed to give
+// it. The admission webhook checks this too, but its errors don't tell
+// these cases apart.
+func (s *Server) validateApproval(rel *shipper.Release, step int32) (int, error) {
+	strategyStep := rel.Spec.Environment.Strategy.Steps[step]
+	if !strategyStep.ApprovalRequired {
+		return http.StatusBadRequest, fmt.Errorf("step %d does not require approval", step)
+	}
+
+	if !releaseutil.CanApproveStep(strategyStep, s.approver, nil) {
+		return http.StatusForbidden, fmt.Errorf("%q is not allowed to approve step %d", s.approver, step)
+	}
+
+	return 0, nil
+}
+
+func (s *Server) approve(rel *shipper.Release, step int32) (*shipper.Release, error) {
+	for _, approval := range rel.Spec.Approvals {
+		if approval.Step == step {
+			return rel, nil
+		}
+	}
+
+	rel = rel.DeepCopy()
+	rel.Spec.Approvals = append(rel.Spec.Approvals, shipper.ReleaseApproval{
+		Step:       step,
+		Approver:   s.approver,
+		ApprovedAt: metav1.Now(),
+	})
+
+	return s.shipperClientset.ShipperV1alpha1().Releases(rel.Namespace).Update(rel)
+}
+
+// waitForStep blocks until the release achieves a given step, the timeout
+// expires or the client goes away, and returns the last observed state of
+// the release.
+func (s *Server) waitForStep(ctx context.Context, rel *shipper.Release, step int32, timeout time.Duration) *shipper.Release {
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	go func() {
+		defer close(stopCh)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		case <-doneCh:
+		}
+	}()
+
+	wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		latest, err := s.releaseLister.Releases(rel.Namespace).Get(rel.Name)
+		if err != nil {
+			return false, err
+		}
+		rel = latest
+		return stepAchieved(rel, step), nil
+	}, stopCh)
+
+	return rel
+}
+
+// errorStatus returns the HTTP status to respond with when updating a
+// release fails. Conflicts mean the release changed under us, and the
+// request can be retried as is.
+func errorStatus(err error) int {
+	if errors.IsConflict(err) {
+		return http.StatusConflict
+	}
+
+	if errors.IsForbidden(err) {
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
+
+func stepAchieved(rel *shipper.Release, step int32) bool {
+	return rel.Status.AchievedStep != nil && rel.Status.AchievedStep.Step == step
+}
+
+func parseTimeout(v string) (time.Duration, error) {
+	if v == "" {
+		return defaultWaitTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q", v)
+	}
+
+	if timeout > maxWaitTimeout {
+		timeout = maxWaitTimeout
+	}
+
+	return timeout, nil
+}
+
+func buildReleaseState(rel *shipper.Release, step *int32) ReleaseState {
+	state := ReleaseState{
+		Namespace:  rel.Namespace,
+		Name:       rel.Name,
+		TargetStep: rel.Spec.TargetStep,
+		Complete:   releaseutil.ReleaseComplete(rel),
+		Conditions: rel.Status.Conditions,
+	}
+
+	if rel.Status.AchievedStep != nil {
+		achieved := rel.Status.AchievedStep.Step
+		state.AchievedStep = &achieved
+		state.AchievedStepName = rel.Status.AchievedStep.Name
+	}
+
+	if step == nil {
+		state.Achieved = releaseutil.ReleaseAchievedTargetStep(rel)
+	} else {
+		state.Achieved = stepAchieved(rel, *step)
+	}
+
+	return state
+}
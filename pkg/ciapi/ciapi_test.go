@@ -0,0 +1,360 @@
+package ciapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+const (
+	testToken    = "s3cr3t"
+	testApprover = "ci-bot"
+	releaseName  = "test-release"
+)
+
+func TestUnauthorized(t *testing.T) {
+	srv, _ := newTestServer(t, buildRelease(0, nil))
+	defer srv.Close()
+
+	for _, token := range []string{"", "wrong"} {
+		res := doRequest(t, srv, http.MethodGet, "/releases/"+shippertesting.TestNamespace+"/"+releaseName, token, "")
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d for token %q, got %d", http.StatusUnauthorized, token, res.StatusCode)
+		}
+	}
+
+	// The token alone, or with any other scheme, isn't a bearer token.
+	for _, header := range []string{testToken, "Basic " + testToken, "bearer" + testToken} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/releases/"+shippertesting.TestNamespace+"/"+releaseName, nil)
+		if err != nil {
+			t.Fatalf("could not build request: %s", err)
+		}
+		req.Header.Set("Authorization", header)
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected status %d for header %q, got %d", http.StatusUnauthorized, header, res.StatusCode)
+		}
+	}
+}
+
+// TestTokenScopes checks that tokens limited to namespaces or applications
+// can only be used for releases in them.
+func TestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []TokenScope
+		status int
+	}{
+		{
+			name:   "global",
+			status: http.StatusOK,
+		},
+		{
+			name:   "namespace",
+			scopes: []TokenScope{{Namespace: "other"}, {Namespace: shippertesting.TestNamespace}},
+			status: http.StatusOK,
+		},
+		{
+			name:   "application",
+			scopes: []TokenScope{{Namespace: shippertesting.TestNamespace, Application: shippertesting.TestApp}},
+			status: http.StatusOK,
+		},
+		{
+			name:   "other namespace",
+			scopes: []TokenScope{{Namespace: "other"}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "other application",
+			scopes: []TokenScope{{Namespace: shippertesting.TestNamespace, Application: "other"}},
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		srv, _ := newTestServerWithTokens(t, buildRelease(0, nil), []Token{{Value: testToken, Scopes: tt.scopes}})
+
+		res := doRequest(t, srv, http.MethodGet, "/releases/"+shippertesting.TestNamespace+"/"+releaseName, testToken, "")
+		if res.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, res.StatusCode)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestReadTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected []Token
+		err      bool
+	}{
+		{
+			name:     "global and scoped tokens",
+			contents: "# CI tokens\ns3cr3t\n\nt0k3n frontend backend/api\n",
+			expected: []Token{
+				{Value: "s3cr3t"},
+				{Value: "t0k3n", Scopes: []TokenScope{
+					{Namespace: "frontend"},
+					{Namespace: "backend", Application: "api"},
+				}},
+			},
+		},
+		{
+			name:     "invalid scope",
+			contents: "t0k3n backend/api/v1\n",
+			err:      true,
+		},
+		{
+			name:     "no tokens",
+			contents: "# nothing to see here\n",
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		f, err := ioutil.TempFile("", "ci-tokens")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+
+		if _, err := f.WriteString(tt.contents); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		tokens, err := ReadTokens(f.Name())
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got tokens %+v", tt.name, tokens)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		} else if !reflect.DeepEqual(tokens, tt.expected) {
+			t.Errorf("%s: expected tokens %+v, got %+v", tt.name, tt.expected, tokens)
+		}
+	}
+}
+
+// TestWaitForAchievedStep checks that waiting for a step that was already
+// achieved returns immediately with the state of the release.
+func TestWaitForAchievedStep(t *testing.T) {
+	srv, _ := newTestServer(t, buildRelease(1, &shipper.AchievedStep{Step: 1, Name: "full on"}))
+	defer srv.Close()
+
+	res := doRequest(t, srv, http.MethodGet, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"?step=1&timeout=10s", testToken, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var state ReleaseState
+	if err := json.NewDecoder(res.Body).Decode(&state); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if !state.Achieved || state.AchievedStep == nil || *state.AchievedStep != 1 || state.AchievedStepName != "full on" {
+		t.Fatalf("expected step 1 to be achieved, got %+v", state)
+	}
+}
+
+// TestAdvance checks that advancing a release updates its target step, and
+// that a pending step is reported as not achieved once the timeout expires.
+func TestAdvance(t *testing.T) {
+	srv, f := newTestServer(t, buildRelease(0, &shipper.AchievedStep{Step: 0, Name: "staging"}))
+	defer srv.Close()
+
+	res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/advance?timeout=10ms", testToken, `{"step": 1}`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var state ReleaseState
+	if err := json.NewDecoder(res.Body).Decode(&state); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if state.Achieved {
+		t.Fatalf("expected step 1 not to be achieved, got %+v", state)
+	}
+
+	rel, err := f.ShipperClient.ShipperV1alpha1().Releases(shippertesting.TestNamespace).Get(releaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting release: %s", err)
+	}
+
+	if rel.Spec.TargetStep != 1 {
+		t.Fatalf("expected target step to be 1, got %d", rel.Spec.TargetStep)
+	}
+}
+
+func TestAdvanceOutOfRange(t *testing.T) {
+	srv, _ := newTestServer(t, buildRelease(0, nil))
+	defer srv.Close()
+
+	res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/advance", testToken, `{"step": 2}`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+// TestAdvanceErrors checks that failing to update a release is reported as
+// a conflict when it changed in the meantime, and as a server error
+// otherwise.
+func TestAdvanceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "conflict",
+			err:    errors.NewConflict(shipper.Resource("releases"), releaseName, fmt.Errorf("object was modified")),
+			status: http.StatusConflict,
+		},
+		{
+			name:   "server error",
+			err:    fmt.Errorf("etcdserver: request timed out"),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		srv, f := newTestServer(t, buildRelease(0, nil))
+		f.InjectError("update", "releases", tt.err)
+
+		res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/advance", testToken, `{"step": 1}`)
+		if res.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, res.StatusCode)
+		}
+
+		srv.Close()
+	}
+}
+
+// TestApprove checks that approving a step records an approval on behalf
+// of the approver once, and that steps the approver can't approve are
+// rejected.
+func TestApprove(t *testing.T) {
+	rel := buildRelease(1, &shipper.AchievedStep{Step: 0, Name: "staging"})
+	rel.Spec.Environment.Strategy.Steps[1].ApprovalRequired = true
+	rel.Spec.Environment.Strategy.Steps[1].Approvers = []string{"alice", testApprover}
+	srv, f := newTestServer(t, rel)
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/approve?timeout=10ms", testToken, `{"step": 1}`)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+		}
+	}
+
+	rel, err := f.ShipperClient.ShipperV1alpha1().Releases(shippertesting.TestNamespace).Get(releaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting release: %s", err)
+	}
+
+	if len(rel.Spec.Approvals) != 1 || rel.Spec.Approvals[0].Step != 1 || rel.Spec.Approvals[0].Approver != testApprover {
+		t.Fatalf("expected step 1 to be approved once by %q, got %+v", testApprover, rel.Spec.Approvals)
+	}
+
+	res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/approve", testToken, `{"step": 0}`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d for a step without approval, got %d", http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestApproveNotAllowed(t *testing.T) {
+	rel := buildRelease(1, nil)
+	rel.Spec.Environment.Strategy.Steps[1].ApprovalRequired = true
+	rel.Spec.Environment.Strategy.Steps[1].Approvers = []string{"alice"}
+	srv, _ := newTestServer(t, rel)
+	defer srv.Close()
+
+	res := doRequest(t, srv, http.MethodPost, "/releases/"+shippertesting.TestNamespace+"/"+releaseName+"/approve", testToken, `{"step": 1}`)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.StatusCode)
+	}
+}
+
+func newTestServer(t *testing.T, rel *shipper.Release) (*httptest.Server, *shippertesting.ControllerTestFixture) {
+	return newTestServerWithTokens(t, rel, []Token{{Value: testToken}})
+}
+
+func newTestServerWithTokens(t *testing.T, rel *shipper.Release, tokens []Token) (*httptest.Server, *shippertesting.ControllerTestFixture) {
+	f := shippertesting.NewControllerTestFixture()
+	f.ShipperClient.Tracker().Add(rel)
+
+	s := NewServer("", tokens, testApprover, "", "", f.ShipperClient, f.ShipperInformerFactory)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	return httptest.NewServer(s.authenticate(http.HandlerFunc(s.handleRelease))), f
+}
+
+func doRequest(t *testing.T, srv *httptest.Server, method, path, token, body string) *http.Response {
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not build request: %s", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+
+	return res
+}
+
+func buildRelease(targetStep int32, achieved *shipper.AchievedStep) *shipper.Release {
+	return &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      releaseName,
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel: shippertesting.TestApp,
+			},
+		},
+		Spec: shipper.ReleaseSpec{
+			TargetStep: targetStep,
+			Environment: shipper.ReleaseEnvironment{
+				Strategy: &shipper.RolloutStrategy{
+					Steps: []shipper.RolloutStrategyStep{
+						{Name: "staging"},
+						{Name: "full on"},
+					},
+				},
+			},
+		},
+		Status: shipper.ReleaseStatus{
+			AchievedStep: achieved,
+		},
+	}
+}
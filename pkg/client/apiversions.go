@@ -0,0 +1,105 @@
+package client
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// appsGroupVersions are the versions of the apps API group Shipper can
+// manage workloads through, in order of preference. Clusters older than
+// Kubernetes 1.9 only serve apps/v1beta2, which shares its schema with
+// apps/v1 for the kinds in appsKinds.
+var appsGroupVersions = []schema.GroupVersion{
+	appsv1.SchemeGroupVersion,
+	appsv1beta2.SchemeGroupVersion,
+}
+
+var appsKinds = map[string]struct{}{
+	"Deployment":  {},
+	"DaemonSet":   {},
+	"StatefulSet": {},
+	"ReplicaSet":  {},
+}
+
+// CompatibleGroupVersions returns the group versions the kind of gvk can be
+// served in with the same schema, starting with gvk's own.
+func CompatibleGroupVersions(gvk schema.GroupVersionKind) []schema.GroupVersion {
+	gv := gvk.GroupVersion()
+	if _, ok := appsKinds[gvk.Kind]; !ok || gv.Group != appsv1.GroupName {
+		return []schema.GroupVersion{gv}
+	}
+
+	versions := []schema.GroupVersion{gv}
+	for _, compatible := range appsGroupVersions {
+		if compatible != gv {
+			versions = append(versions, compatible)
+		}
+	}
+
+	return versions
+}
+
+// ServedGroupVersionKind returns gvk, or else the first of its compatible
+// group versions, as served by the API server behind client. ok is false if
+// the API server serves its kind in none of them.
+func ServedGroupVersionKind(client discovery.DiscoveryInterface, gvk schema.GroupVersionKind) (schema.GroupVersionKind, bool, error) {
+	served, err := ServedGroupVersions(client)
+	if err != nil {
+		return gvk, false, err
+	}
+
+	for _, gv := range CompatibleGroupVersions(gvk) {
+		if _, ok := served[gv]; !ok {
+			continue
+		}
+
+		resources, err := client.ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			return gvk, false, err
+		}
+
+		for _, resource := range resources.APIResources {
+			if resource.Kind == gvk.Kind {
+				return gv.WithKind(gvk.Kind), true, nil
+			}
+		}
+	}
+
+	return gvk, false, nil
+}
+
+// ServedGroupVersions returns the group versions the API server behind
+// client serves.
+func ServedGroupVersions(client discovery.DiscoveryInterface) (map[schema.GroupVersion]struct{}, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	served := make(map[schema.GroupVersion]struct{})
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served[schema.GroupVersion{Group: group.Name, Version: version.Version}] = struct{}{}
+		}
+	}
+
+	return served, nil
+}
+
+// ServedAppsGroupVersion returns the version of the apps API group that
+// Deployments and DaemonSets are managed through in the cluster behind
+// client.
+func ServedAppsGroupVersion(client discovery.DiscoveryInterface) (schema.GroupVersion, error) {
+	gvk, ok, err := ServedGroupVersionKind(client, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err != nil {
+		return schema.GroupVersion{}, err
+	} else if !ok {
+		return schema.GroupVersion{}, fmt.Errorf("cluster serves Deployments in none of %v", appsGroupVersions)
+	}
+
+	return gvk.GroupVersion(), nil
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newDiscovery(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+	discovery := kubefake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.Resources = resources
+	return discovery
+}
+
+func TestServedGroupVersionKind(t *testing.T) {
+	legacyApps := &metav1.APIResourceList{
+		GroupVersion: appsv1beta2.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+	}
+	core := &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "services", Kind: "Service", Namespaced: true}},
+	}
+	discovery := newDiscovery(legacyApps, core)
+
+	tests := []struct {
+		name     string
+		gvk      schema.GroupVersionKind
+		expected schema.GroupVersionKind
+		ok       bool
+	}{
+		{
+			name:     "served as asked",
+			gvk:      schema.GroupVersionKind{Version: "v1", Kind: "Service"},
+			expected: schema.GroupVersionKind{Version: "v1", Kind: "Service"},
+			ok:       true,
+		},
+		{
+			name:     "falls back to a compatible version",
+			gvk:      appsv1.SchemeGroupVersion.WithKind("Deployment"),
+			expected: appsv1beta2.SchemeGroupVersion.WithKind("Deployment"),
+			ok:       true,
+		},
+		{
+			name: "kind not served in any version",
+			gvk:  appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+		},
+		{
+			name: "group not served",
+			gvk:  schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+		},
+	}
+
+	for _, tt := range tests {
+		gvk, ok, err := ServedGroupVersionKind(discovery, tt.gvk)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if ok != tt.ok {
+			t.Errorf("%s: expected served to be %t, got %t", tt.name, tt.ok, ok)
+		} else if ok && gvk != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, gvk)
+		}
+	}
+}
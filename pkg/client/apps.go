@@ -0,0 +1,182 @@
+package client
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AppsClient manages Deployments and DaemonSets through the version of the
+// apps API group a cluster serves, as returned by ServedAppsGroupVersion.
+// Objects are always converted to and from apps/v1, so controllers don't
+// need to care about the version.
+type AppsClient struct {
+	client  kubernetes.Interface
+	version schema.GroupVersion
+}
+
+// NewAppsClient returns an AppsClient for client that goes through version.
+// An empty version is apps/v1.
+func NewAppsClient(client kubernetes.Interface, version schema.GroupVersion) AppsClient {
+	return AppsClient{
+		client:  client,
+		version: version,
+	}
+}
+
+func (c AppsClient) legacy() bool {
+	return c.version == appsv1beta2.SchemeGroupVersion
+}
+
+func (c AppsClient) ListDeployments(namespace string, options metav1.ListOptions) (runtime.Object, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().Deployments(namespace).List(options)
+	}
+
+	list, err := c.client.AppsV1beta2().Deployments(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1.DeploymentList{}
+	return converted, convertApps(list, converted)
+}
+
+func (c AppsClient) WatchDeployments(namespace string, options metav1.ListOptions) (watch.Interface, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().Deployments(namespace).Watch(options)
+	}
+
+	w, err := c.client.AppsV1beta2().Deployments(namespace).Watch(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertingWatch(w, func() runtime.Object { return &appsv1.Deployment{} }), nil
+}
+
+func (c AppsClient) PatchDeployment(namespace, name string, pt types.PatchType, data []byte) (*appsv1.Deployment, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().Deployments(namespace).Patch(name, pt, data)
+	}
+
+	patched, err := c.client.AppsV1beta2().Deployments(namespace).Patch(name, pt, data)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1.Deployment{}
+	return converted, convertApps(patched, converted)
+}
+
+func (c AppsClient) ListDaemonSets(namespace string, options metav1.ListOptions) (runtime.Object, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().DaemonSets(namespace).List(options)
+	}
+
+	list, err := c.client.AppsV1beta2().DaemonSets(namespace).List(options)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1.DaemonSetList{}
+	return converted, convertApps(list, converted)
+}
+
+func (c AppsClient) WatchDaemonSets(namespace string, options metav1.ListOptions) (watch.Interface, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().DaemonSets(namespace).Watch(options)
+	}
+
+	w, err := c.client.AppsV1beta2().DaemonSets(namespace).Watch(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertingWatch(w, func() runtime.Object { return &appsv1.DaemonSet{} }), nil
+}
+
+func (c AppsClient) GetDaemonSet(namespace, name string) (*appsv1.DaemonSet, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().DaemonSets(namespace).Get(name, metav1.GetOptions{})
+	}
+
+	ds, err := c.client.AppsV1beta2().DaemonSets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1.DaemonSet{}
+	return converted, convertApps(ds, converted)
+}
+
+func (c AppsClient) CreateDaemonSet(ds *appsv1.DaemonSet) (*appsv1.DaemonSet, error) {
+	if !c.legacy() {
+		return c.client.AppsV1().DaemonSets(ds.Namespace).Create(ds)
+	}
+
+	legacy := &appsv1beta2.DaemonSet{}
+	if err := convertApps(ds, legacy); err != nil {
+		return nil, err
+	}
+
+	created, err := c.client.AppsV1beta2().DaemonSets(ds.Namespace).Create(legacy)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1.DaemonSet{}
+	return converted, convertApps(created, converted)
+}
+
+func (c AppsClient) DeleteDaemonSet(namespace, name string, options *metav1.DeleteOptions) error {
+	if !c.legacy() {
+		return c.client.AppsV1().DaemonSets(namespace).Delete(name, options)
+	}
+
+	return c.client.AppsV1beta2().DaemonSets(namespace).Delete(name, options)
+}
+
+// convertApps converts between the apps/v1 and apps/v1beta2 versions of an
+// object, which only differ in their API version. The result is left
+// without one, like the objects typed clients return.
+func convertApps(in runtime.Object, out runtime.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in)
+	if err != nil {
+		return err
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(content, out)
+	if err != nil {
+		return err
+	}
+
+	out.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+
+	return nil
+}
+
+// convertingWatch converts the objects w sends to the ones newObject
+// returns. Objects that fail to convert are dropped.
+func convertingWatch(w watch.Interface, newObject func() runtime.Object) watch.Interface {
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if event.Type == watch.Error {
+			return event, true
+		}
+
+		converted := newObject()
+		if err := convertApps(event.Object, converted); err != nil {
+			utilruntime.HandleError(err)
+			return event, false
+		}
+
+		event.Object = converted
+		return event, true
+	})
+}
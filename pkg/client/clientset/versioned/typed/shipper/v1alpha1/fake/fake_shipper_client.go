@@ -16,18 +16,38 @@ func (c *FakeShipperV1alpha1) Applications(namespace string) v1alpha1.Applicatio
 	return &FakeApplications{c, namespace}
 }
 
+func (c *FakeShipperV1alpha1) ApplicationDefaults(namespace string) v1alpha1.ApplicationDefaultInterface {
+	return &FakeApplicationDefaults{c, namespace}
+}
+
+func (c *FakeShipperV1alpha1) ApplicationTemplates(namespace string) v1alpha1.ApplicationTemplateInterface {
+	return &FakeApplicationTemplates{c, namespace}
+}
+
 func (c *FakeShipperV1alpha1) CapacityTargets(namespace string) v1alpha1.CapacityTargetInterface {
 	return &FakeCapacityTargets{c, namespace}
 }
 
+func (c *FakeShipperV1alpha1) ChartRepositories() v1alpha1.ChartRepositoryInterface {
+	return &FakeChartRepositories{c}
+}
+
 func (c *FakeShipperV1alpha1) Clusters() v1alpha1.ClusterInterface {
 	return &FakeClusters{c}
 }
 
+func (c *FakeShipperV1alpha1) FleetCapacityOverrides(namespace string) v1alpha1.FleetCapacityOverrideInterface {
+	return &FakeFleetCapacityOverrides{c, namespace}
+}
+
 func (c *FakeShipperV1alpha1) InstallationTargets(namespace string) v1alpha1.InstallationTargetInterface {
 	return &FakeInstallationTargets{c, namespace}
 }
 
+func (c *FakeShipperV1alpha1) Policies(namespace string) v1alpha1.PolicyInterface {
+	return &FakePolicies{c, namespace}
+}
+
 func (c *FakeShipperV1alpha1) Releases(namespace string) v1alpha1.ReleaseInterface {
 	return &FakeReleases{c, namespace}
 }
@@ -36,6 +56,18 @@ func (c *FakeShipperV1alpha1) RolloutBlocks(namespace string) v1alpha1.RolloutBl
 	return &FakeRolloutBlocks{c, namespace}
 }
 
+func (c *FakeShipperV1alpha1) RolloutQuotas(namespace string) v1alpha1.RolloutQuotaInterface {
+	return &FakeRolloutQuotas{c, namespace}
+}
+
+func (c *FakeShipperV1alpha1) ShipperConfigs() v1alpha1.ShipperConfigInterface {
+	return &FakeShipperConfigs{c}
+}
+
+func (c *FakeShipperV1alpha1) Strategies(namespace string) v1alpha1.StrategyInterface {
+	return &FakeStrategies{c, namespace}
+}
+
 func (c *FakeShipperV1alpha1) TrafficTargets(namespace string) v1alpha1.TrafficTargetInterface {
 	return &FakeTrafficTargets{c, namespace}
 }
@@ -4,14 +4,30 @@ package v1alpha1
 
 type ApplicationExpansion interface{}
 
+type ApplicationDefaultExpansion interface{}
+
+type ApplicationTemplateExpansion interface{}
+
 type CapacityTargetExpansion interface{}
 
+type ChartRepositoryExpansion interface{}
+
 type ClusterExpansion interface{}
 
+type FleetCapacityOverrideExpansion interface{}
+
 type InstallationTargetExpansion interface{}
 
+type PolicyExpansion interface{}
+
 type ReleaseExpansion interface{}
 
 type RolloutBlockExpansion interface{}
 
+type RolloutQuotaExpansion interface{}
+
+type ShipperConfigExpansion interface{}
+
+type StrategyExpansion interface{}
+
 type TrafficTargetExpansion interface{}
@@ -11,11 +11,19 @@ import (
 type ShipperV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ApplicationsGetter
+	ApplicationDefaultsGetter
+	ApplicationTemplatesGetter
 	CapacityTargetsGetter
+	ChartRepositoriesGetter
 	ClustersGetter
+	FleetCapacityOverridesGetter
 	InstallationTargetsGetter
+	PoliciesGetter
 	ReleasesGetter
 	RolloutBlocksGetter
+	RolloutQuotasGetter
+	ShipperConfigsGetter
+	StrategiesGetter
 	TrafficTargetsGetter
 }
 
@@ -28,18 +36,38 @@ func (c *ShipperV1alpha1Client) Applications(namespace string) ApplicationInterf
 	return newApplications(c, namespace)
 }
 
+func (c *ShipperV1alpha1Client) ApplicationDefaults(namespace string) ApplicationDefaultInterface {
+	return newApplicationDefaults(c, namespace)
+}
+
+func (c *ShipperV1alpha1Client) ApplicationTemplates(namespace string) ApplicationTemplateInterface {
+	return newApplicationTemplates(c, namespace)
+}
+
 func (c *ShipperV1alpha1Client) CapacityTargets(namespace string) CapacityTargetInterface {
 	return newCapacityTargets(c, namespace)
 }
 
+func (c *ShipperV1alpha1Client) ChartRepositories() ChartRepositoryInterface {
+	return newChartRepositories(c)
+}
+
 func (c *ShipperV1alpha1Client) Clusters() ClusterInterface {
 	return newClusters(c)
 }
 
+func (c *ShipperV1alpha1Client) FleetCapacityOverrides(namespace string) FleetCapacityOverrideInterface {
+	return newFleetCapacityOverrides(c, namespace)
+}
+
 func (c *ShipperV1alpha1Client) InstallationTargets(namespace string) InstallationTargetInterface {
 	return newInstallationTargets(c, namespace)
 }
 
+func (c *ShipperV1alpha1Client) Policies(namespace string) PolicyInterface {
+	return newPolicies(c, namespace)
+}
+
 func (c *ShipperV1alpha1Client) Releases(namespace string) ReleaseInterface {
 	return newReleases(c, namespace)
 }
@@ -48,6 +76,18 @@ func (c *ShipperV1alpha1Client) RolloutBlocks(namespace string) RolloutBlockInte
 	return newRolloutBlocks(c, namespace)
 }
 
+func (c *ShipperV1alpha1Client) RolloutQuotas(namespace string) RolloutQuotaInterface {
+	return newRolloutQuotas(c, namespace)
+}
+
+func (c *ShipperV1alpha1Client) ShipperConfigs() ShipperConfigInterface {
+	return newShipperConfigs(c)
+}
+
+func (c *ShipperV1alpha1Client) Strategies(namespace string) StrategyInterface {
+	return newStrategies(c, namespace)
+}
+
 func (c *ShipperV1alpha1Client) TrafficTargets(namespace string) TrafficTargetInterface {
 	return newTrafficTargets(c, namespace)
 }
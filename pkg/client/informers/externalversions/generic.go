@@ -39,16 +39,32 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=shipper.booking.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("applications"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().Applications().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("applicationdefaults"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().ApplicationDefaults().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("applicationtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().ApplicationTemplates().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("capacitytargets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().CapacityTargets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("chartrepositories"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().ChartRepositories().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("clusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().Clusters().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("fleetcapacityoverrides"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().FleetCapacityOverrides().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("installationtargets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().InstallationTargets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("policies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().Policies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("releases"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().Releases().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("rolloutblocks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().RolloutBlocks().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("rolloutquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().RolloutQuotas().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("shipperconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().ShipperConfigs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("strategies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().Strategies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("traffictargets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Shipper().V1alpha1().TrafficTargets().Informer()}, nil
 
@@ -10,16 +10,32 @@ import (
 type Interface interface {
 	// Applications returns a ApplicationInformer.
 	Applications() ApplicationInformer
+	// ApplicationDefaults returns a ApplicationDefaultInformer.
+	ApplicationDefaults() ApplicationDefaultInformer
+	// ApplicationTemplates returns a ApplicationTemplateInformer.
+	ApplicationTemplates() ApplicationTemplateInformer
 	// CapacityTargets returns a CapacityTargetInformer.
 	CapacityTargets() CapacityTargetInformer
+	// ChartRepositories returns a ChartRepositoryInformer.
+	ChartRepositories() ChartRepositoryInformer
 	// Clusters returns a ClusterInformer.
 	Clusters() ClusterInformer
+	// FleetCapacityOverrides returns a FleetCapacityOverrideInformer.
+	FleetCapacityOverrides() FleetCapacityOverrideInformer
 	// InstallationTargets returns a InstallationTargetInformer.
 	InstallationTargets() InstallationTargetInformer
+	// Policies returns a PolicyInformer.
+	Policies() PolicyInformer
 	// Releases returns a ReleaseInformer.
 	Releases() ReleaseInformer
 	// RolloutBlocks returns a RolloutBlockInformer.
 	RolloutBlocks() RolloutBlockInformer
+	// RolloutQuotas returns a RolloutQuotaInformer.
+	RolloutQuotas() RolloutQuotaInformer
+	// ShipperConfigs returns a ShipperConfigInformer.
+	ShipperConfigs() ShipperConfigInformer
+	// Strategies returns a StrategyInformer.
+	Strategies() StrategyInformer
 	// TrafficTargets returns a TrafficTargetInformer.
 	TrafficTargets() TrafficTargetInformer
 }
@@ -40,21 +56,46 @@ func (v *version) Applications() ApplicationInformer {
 	return &applicationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ApplicationDefaults returns a ApplicationDefaultInformer.
+func (v *version) ApplicationDefaults() ApplicationDefaultInformer {
+	return &applicationDefaultInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ApplicationTemplates returns a ApplicationTemplateInformer.
+func (v *version) ApplicationTemplates() ApplicationTemplateInformer {
+	return &applicationTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // CapacityTargets returns a CapacityTargetInformer.
 func (v *version) CapacityTargets() CapacityTargetInformer {
 	return &capacityTargetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ChartRepositories returns a ChartRepositoryInformer.
+func (v *version) ChartRepositories() ChartRepositoryInformer {
+	return &chartRepositoryInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Clusters returns a ClusterInformer.
 func (v *version) Clusters() ClusterInformer {
 	return &clusterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// FleetCapacityOverrides returns a FleetCapacityOverrideInformer.
+func (v *version) FleetCapacityOverrides() FleetCapacityOverrideInformer {
+	return &fleetCapacityOverrideInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // InstallationTargets returns a InstallationTargetInformer.
 func (v *version) InstallationTargets() InstallationTargetInformer {
 	return &installationTargetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// Policies returns a PolicyInformer.
+func (v *version) Policies() PolicyInformer {
+	return &policyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Releases returns a ReleaseInformer.
 func (v *version) Releases() ReleaseInformer {
 	return &releaseInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -65,6 +106,21 @@ func (v *version) RolloutBlocks() RolloutBlockInformer {
 	return &rolloutBlockInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// RolloutQuotas returns a RolloutQuotaInformer.
+func (v *version) RolloutQuotas() RolloutQuotaInformer {
+	return &rolloutQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ShipperConfigs returns a ShipperConfigInformer.
+func (v *version) ShipperConfigs() ShipperConfigInformer {
+	return &shipperConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// Strategies returns a StrategyInformer.
+func (v *version) Strategies() StrategyInformer {
+	return &strategyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TrafficTargets returns a TrafficTargetInformer.
 func (v *version) TrafficTargets() TrafficTargetInformer {
 	return &trafficTargetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -10,6 +10,22 @@ type ApplicationListerExpansion interface{}
 // ApplicationNamespaceLister.
 type ApplicationNamespaceListerExpansion interface{}
 
+// ApplicationDefaultListerExpansion allows custom methods to be added to
+// ApplicationDefaultLister.
+type ApplicationDefaultListerExpansion interface{}
+
+// ApplicationDefaultNamespaceListerExpansion allows custom methods to be added to
+// ApplicationDefaultNamespaceLister.
+type ApplicationDefaultNamespaceListerExpansion interface{}
+
+// ApplicationTemplateListerExpansion allows custom methods to be added to
+// ApplicationTemplateLister.
+type ApplicationTemplateListerExpansion interface{}
+
+// ApplicationTemplateNamespaceListerExpansion allows custom methods to be added to
+// ApplicationTemplateNamespaceLister.
+type ApplicationTemplateNamespaceListerExpansion interface{}
+
 // CapacityTargetListerExpansion allows custom methods to be added to
 // CapacityTargetLister.
 type CapacityTargetListerExpansion interface{}
@@ -18,10 +34,22 @@ type CapacityTargetListerExpansion interface{}
 // CapacityTargetNamespaceLister.
 type CapacityTargetNamespaceListerExpansion interface{}
 
+// ChartRepositoryListerExpansion allows custom methods to be added to
+// ChartRepositoryLister.
+type ChartRepositoryListerExpansion interface{}
+
 // ClusterListerExpansion allows custom methods to be added to
 // ClusterLister.
 type ClusterListerExpansion interface{}
 
+// FleetCapacityOverrideListerExpansion allows custom methods to be added to
+// FleetCapacityOverrideLister.
+type FleetCapacityOverrideListerExpansion interface{}
+
+// FleetCapacityOverrideNamespaceListerExpansion allows custom methods to be added to
+// FleetCapacityOverrideNamespaceLister.
+type FleetCapacityOverrideNamespaceListerExpansion interface{}
+
 // InstallationTargetListerExpansion allows custom methods to be added to
 // InstallationTargetLister.
 type InstallationTargetListerExpansion interface{}
@@ -30,6 +58,14 @@ type InstallationTargetListerExpansion interface{}
 // InstallationTargetNamespaceLister.
 type InstallationTargetNamespaceListerExpansion interface{}
 
+// PolicyListerExpansion allows custom methods to be added to
+// PolicyLister.
+type PolicyListerExpansion interface{}
+
+// PolicyNamespaceListerExpansion allows custom methods to be added to
+// PolicyNamespaceLister.
+type PolicyNamespaceListerExpansion interface{}
+
 // RolloutBlockListerExpansion allows custom methods to be added to
 // RolloutBlockLister.
 type RolloutBlockListerExpansion interface{}
@@ -38,6 +74,26 @@ type RolloutBlockListerExpansion interface{}
 // RolloutBlockNamespaceLister.
 type RolloutBlockNamespaceListerExpansion interface{}
 
+// RolloutQuotaListerExpansion allows custom methods to be added to
+// RolloutQuotaLister.
+type RolloutQuotaListerExpansion interface{}
+
+// RolloutQuotaNamespaceListerExpansion allows custom methods to be added to
+// RolloutQuotaNamespaceLister.
+type RolloutQuotaNamespaceListerExpansion interface{}
+
+// ShipperConfigListerExpansion allows custom methods to be added to
+// ShipperConfigLister.
+type ShipperConfigListerExpansion interface{}
+
+// StrategyListerExpansion allows custom methods to be added to
+// StrategyLister.
+type StrategyListerExpansion interface{}
+
+// StrategyNamespaceListerExpansion allows custom methods to be added to
+// StrategyNamespaceLister.
+type StrategyNamespaceListerExpansion interface{}
+
 // TrafficTargetListerExpansion allows custom methods to be added to
 // TrafficTargetLister.
 type TrafficTargetListerExpansion interface{}
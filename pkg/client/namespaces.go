@@ -0,0 +1,281 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	"github.com/bookingcom/shipper/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// NewNamespacedInformerFactory returns a shared informer factory whose
+// Application, Release and RolloutBlock informers only list and watch
+// objects in namespaces, so nothing outside of them ever makes it to the
+// caches of the controllers using it. Every other informer it makes covers
+// all namespaces. If namespaces is empty, it's the same as a plain shared
+// informer factory.
+func NewNamespacedInformerFactory(
+	client shipperclientset.Interface,
+	resync time.Duration,
+	namespaces []string,
+) shipperinformers.SharedInformerFactory {
+	factory := shipperinformers.NewSharedInformerFactory(client, resync)
+	if len(namespaces) == 0 {
+		return factory
+	}
+
+	factory.InformerFor(&shipper.Application{}, namespacedInformer(namespaces, &shipper.Application{},
+		func(client shipperclientset.Interface, namespace string) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return client.ShipperV1alpha1().Applications(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return client.ShipperV1alpha1().Applications(namespace).Watch(options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&shipper.Release{}, namespacedInformer(namespaces, &shipper.Release{},
+		func(client shipperclientset.Interface, namespace string) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return client.ShipperV1alpha1().Releases(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return client.ShipperV1alpha1().Releases(namespace).Watch(options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&shipper.RolloutBlock{}, namespacedInformer(namespaces, &shipper.RolloutBlock{},
+		func(client shipperclientset.Interface, namespace string) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return client.ShipperV1alpha1().RolloutBlocks(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return client.ShipperV1alpha1().RolloutBlocks(namespace).Watch(options)
+				},
+			}
+		}))
+
+	return factory
+}
+
+func namespacedInformer(
+	namespaces []string,
+	obj runtime.Object,
+	listWatchFor func(shipperclientset.Interface, string) cache.ListerWatcher,
+) internalinterfaces.NewInformerFunc {
+	return func(client shipperclientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+		listWatches := make(map[string]cache.ListerWatcher, len(namespaces))
+		for _, ns := range namespaces {
+			listWatches[ns] = listWatchFor(client, ns)
+		}
+
+		return cache.NewSharedIndexInformer(
+			newMultiNamespaceListWatch(listWatches),
+			obj,
+			resync,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	}
+}
+
+// multiNamespaceListWatch lists and watches several namespaces as if they
+// were one. Resource versions are only meaningful within a single list or
+// watch, so it keeps track of the one of each namespace, and resumes every
+// namespace's watch from where it left off.
+type multiNamespaceListWatch struct {
+	namespaces  []string
+	listWatches map[string]cache.ListerWatcher
+
+	resourceVersions   map[string]string
+	resourceVersionsMu sync.Mutex
+}
+
+var _ cache.ListerWatcher = (*multiNamespaceListWatch)(nil)
+
+func newMultiNamespaceListWatch(listWatches map[string]cache.ListerWatcher) *multiNamespaceListWatch {
+	namespaces := make([]string, 0, len(listWatches))
+	for ns := range listWatches {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	return &multiNamespaceListWatch{
+		namespaces:       namespaces,
+		listWatches:      listWatches,
+		resourceVersions: make(map[string]string),
+	}
+}
+
+// List returns the objects in every namespace as a single list. The list
+// is never paginated, as there's no single continue token for all of them.
+func (lw *multiNamespaceListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	options.Limit = 0
+	options.Continue = ""
+
+	var (
+		merged runtime.Object
+		items  []runtime.Object
+	)
+
+	resourceVersions := make(map[string]string, len(lw.namespaces))
+	for _, ns := range lw.namespaces {
+		list, err := lw.listWatches[ns].List(options)
+		if err != nil {
+			return nil, err
+		}
+
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return nil, err
+		}
+		resourceVersions[ns] = listMeta.GetResourceVersion()
+
+		nsItems, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nsItems...)
+
+		if merged == nil {
+			merged = list
+		}
+	}
+
+	if err := meta.SetList(merged, items); err != nil {
+		return nil, err
+	}
+
+	lw.resourceVersionsMu.Lock()
+	lw.resourceVersions = resourceVersions
+	lw.resourceVersionsMu.Unlock()
+
+	return merged, nil
+}
+
+// Watch watches every namespace from the last resource version seen in it.
+// The resource version in options is only used for namespaces that were
+// never listed.
+func (lw *multiNamespaceListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w := &multiNamespaceWatch{
+		result: make(chan watch.Event),
+		stopCh: make(chan struct{}),
+	}
+
+	watches := make(map[string]watch.Interface, len(lw.namespaces))
+	for _, ns := range lw.namespaces {
+		nsOptions := options
+		if rv, ok := lw.resourceVersion(ns); ok {
+			nsOptions.ResourceVersion = rv
+		}
+
+		nsWatch, err := lw.listWatches[ns].Watch(nsOptions)
+		if err != nil {
+			for _, started := range watches {
+				started.Stop()
+			}
+			return nil, err
+		}
+
+		watches[ns] = nsWatch
+		w.watches = append(w.watches, nsWatch)
+	}
+
+	var wg sync.WaitGroup
+	for ns, nsWatch := range watches {
+		wg.Add(1)
+		go func(ns string, nsWatch watch.Interface) {
+			defer wg.Done()
+			lw.forward(ns, nsWatch, w)
+		}(ns, nsWatch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(w.result)
+	}()
+
+	return w, nil
+}
+
+// forward sends the events of a namespace's watch to w until either of them
+// stops. The watch of a single namespace ending ends w, so that the
+// informer starts watching all namespaces again.
+func (lw *multiNamespaceListWatch) forward(ns string, nsWatch watch.Interface, w *multiNamespaceWatch) {
+	defer w.Stop()
+
+	for {
+		select {
+		case event, ok := <-nsWatch.ResultChan():
+			if !ok {
+				return
+			}
+
+			select {
+			case w.result <- event:
+			case <-w.stopCh:
+				return
+			}
+
+			if event.Type == watch.Error {
+				continue
+			}
+
+			if objMeta, err := meta.Accessor(event.Object); err == nil {
+				lw.setResourceVersion(ns, objMeta.GetResourceVersion())
+			}
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+func (lw *multiNamespaceListWatch) resourceVersion(ns string) (string, bool) {
+	lw.resourceVersionsMu.Lock()
+	defer lw.resourceVersionsMu.Unlock()
+
+	rv, ok := lw.resourceVersions[ns]
+	return rv, ok
+}
+
+func (lw *multiNamespaceListWatch) setResourceVersion(ns, rv string) {
+	lw.resourceVersionsMu.Lock()
+	defer lw.resourceVersionsMu.Unlock()
+
+	lw.resourceVersions[ns] = rv
+}
+
+// multiNamespaceWatch is the watch of several namespaces at once.
+type multiNamespaceWatch struct {
+	watches []watch.Interface
+	result  chan watch.Event
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *multiNamespaceWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *multiNamespaceWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		for _, nsWatch := range w.watches {
+			nsWatch.Stop()
+		}
+	})
+}
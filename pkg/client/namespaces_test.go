@@ -0,0 +1,150 @@
+package client
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+)
+
+func newApplication(namespace, name string) *shipper.Application {
+	return &shipper.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
+
+// TestNamespacedInformerFactory verifies that informers only ever see the
+// objects in the namespaces they're limited to, both when listing and when
+// watching.
+func TestNamespacedInformerFactory(t *testing.T) {
+	client := shipperfake.NewSimpleClientset(
+		newApplication("payments", "checkout"),
+		newApplication("search", "indexer"),
+		newApplication("reviews", "reviews-api"),
+	)
+
+	factory := NewNamespacedInformerFactory(client, 0, []string{"payments", "search"})
+	lister := factory.Shipper().V1alpha1().Applications().Lister()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	expectApplications(t, lister.List, []string{"payments/checkout", "search/indexer"})
+
+	for _, app := range []*shipper.Application{
+		newApplication("search", "crawler"),
+		newApplication("reviews", "reviews-ui"),
+	} {
+		if _, err := client.ShipperV1alpha1().Applications(app.Namespace).Create(app); err != nil {
+			t.Fatalf("unexpected error creating application: %s", err)
+		}
+	}
+
+	expectApplications(t, lister.List, []string{"payments/checkout", "search/crawler", "search/indexer"})
+}
+
+func expectApplications(t *testing.T, list func(labels.Selector) ([]*shipper.Application, error), expected []string) {
+	var keys []string
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		apps, err := list(labels.Everything())
+		if err != nil {
+			return false, err
+		}
+
+		keys = make([]string, 0, len(apps))
+		for _, app := range apps {
+			keys = append(keys, app.Namespace+"/"+app.Name)
+		}
+		sort.Strings(keys)
+
+		return len(keys) == len(expected), nil
+	})
+	if err != nil {
+		t.Fatalf("expected applications %v, got %v", expected, keys)
+	}
+
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected applications %v, got %v", expected, keys)
+		}
+	}
+}
+
+// TestMultiNamespaceListWatchResourceVersions verifies that every namespace
+// is watched from the last resource version seen in it, rather than from
+// one of another namespace.
+func TestMultiNamespaceListWatchResourceVersions(t *testing.T) {
+	watchedFrom := make(map[string]string)
+	watchers := make(map[string]*watch.FakeWatcher)
+	listWatchFor := func(ns, listRV string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				list := &shipper.ApplicationList{}
+				list.ResourceVersion = listRV
+				return list, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				watchedFrom[ns] = options.ResourceVersion
+				watchers[ns] = watch.NewFake()
+				return watchers[ns], nil
+			},
+		}
+	}
+
+	lw := newMultiNamespaceListWatch(map[string]cache.ListerWatcher{
+		"payments": listWatchFor("payments", "10"),
+		"search":   listWatchFor("search", "20"),
+	})
+
+	if _, err := lw.List(metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error listing: %s", err)
+	}
+
+	w, err := lw.Watch(metav1.ListOptions{ResourceVersion: "20"})
+	if err != nil {
+		t.Fatalf("unexpected error watching: %s", err)
+	}
+
+	expected := map[string]string{"payments": "10", "search": "20"}
+	for ns, rv := range expected {
+		if watchedFrom[ns] != rv {
+			t.Fatalf("expected namespace %q to be watched from %q, got %q", ns, rv, watchedFrom[ns])
+		}
+	}
+
+	app := newApplication("payments", "checkout")
+	app.ResourceVersion = "30"
+	watchers["payments"].Add(app)
+	<-w.ResultChan()
+
+	// A namespace's watch ending ends the watch of all of them.
+	watchers["search"].Stop()
+	for range w.ResultChan() {
+	}
+
+	if _, err := lw.Watch(metav1.ListOptions{ResourceVersion: "30"}); err != nil {
+		t.Fatalf("unexpected error watching: %s", err)
+	}
+
+	expected = map[string]string{"payments": "30", "search": "20"}
+	for ns, rv := range expected {
+		if watchedFrom[ns] != rv {
+			t.Fatalf("expected namespace %q to be watched again from %q, got %q", ns, rv, watchedFrom[ns])
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/informers/internalinterfaces"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// maxCachedAnnotationSize is the size past which annotations of workload
+// objects are left out of informer caches, unless they're Shipper's own.
+const maxCachedAnnotationSize = 1024
+
+const shipperAnnotationPrefix = "shipper.booking.com/"
+
+// NewWorkloadInformerFactory returns a shared informer factory for the
+// workload objects in namespace, with their list options tweaked by
+// tweakListOptions, if set. The Deployments, DaemonSets, Pods, Services and
+// Endpoints it caches are stripped of their managed fields and large
+// annotations, such as kubectl's last applied configuration. No controller
+// reads them, but they can make up most of the size of those objects.
+// Deployments and DaemonSets are watched through appsGroupVersion, and
+// cached as apps/v1 whatever it is.
+func NewWorkloadInformerFactory(
+	client kubernetes.Interface,
+	resync time.Duration,
+	namespace string,
+	tweakListOptions internalinterfaces.TweakListOptionsFunc,
+	appsGroupVersion schema.GroupVersion,
+) kubeinformers.SharedInformerFactory {
+	if tweakListOptions == nil {
+		tweakListOptions = func(*metav1.ListOptions) {}
+	}
+
+	factory := kubeinformers.NewSharedInformerFactoryWithOptions(client, resync,
+		kubeinformers.WithNamespace(namespace),
+		kubeinformers.WithTweakListOptions(tweakListOptions))
+
+	factory.InformerFor(&appsv1.Deployment{}, strippedInformer(&appsv1.Deployment{},
+		func(client kubernetes.Interface) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					tweakListOptions(&options)
+					return NewAppsClient(client, appsGroupVersion).ListDeployments(namespace, options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&options)
+					return NewAppsClient(client, appsGroupVersion).WatchDeployments(namespace, options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&appsv1.DaemonSet{}, strippedInformer(&appsv1.DaemonSet{},
+		func(client kubernetes.Interface) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					tweakListOptions(&options)
+					return NewAppsClient(client, appsGroupVersion).ListDaemonSets(namespace, options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&options)
+					return NewAppsClient(client, appsGroupVersion).WatchDaemonSets(namespace, options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&corev1.Pod{}, strippedInformer(&corev1.Pod{},
+		func(client kubernetes.Interface) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Pods(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Pods(namespace).Watch(options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&corev1.Service{}, strippedInformer(&corev1.Service{},
+		func(client kubernetes.Interface) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Services(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Services(namespace).Watch(options)
+				},
+			}
+		}))
+
+	factory.InformerFor(&corev1.Endpoints{}, strippedInformer(&corev1.Endpoints{},
+		func(client kubernetes.Interface) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Endpoints(namespace).List(options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&options)
+					return client.CoreV1().Endpoints(namespace).Watch(options)
+				},
+			}
+		}))
+
+	return factory
+}
+
+func strippedInformer(
+	obj runtime.Object,
+	listWatchFor func(kubernetes.Interface) cache.ListerWatcher,
+) internalinterfaces.NewInformerFunc {
+	return func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(
+			strippingListWatch{listWatchFor(client)},
+			obj,
+			resync,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	}
+}
+
+// strippingListWatch strips every object it lists or watches before it
+// makes it to an informer's cache.
+type strippingListWatch struct {
+	cache.ListerWatcher
+}
+
+func (lw strippingListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := lw.ListerWatcher.List(options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = meta.EachListItem(list, func(obj runtime.Object) error {
+		stripObject(obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (lw strippingListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := lw.ListerWatcher.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if event.Type != watch.Error {
+			stripObject(event.Object)
+		}
+		return event, true
+	}), nil
+}
+
+// stripObject drops the managed fields of obj, along with any annotation
+// larger than maxCachedAnnotationSize that isn't Shipper's.
+func stripObject(obj runtime.Object) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	objMeta.SetManagedFields(nil)
+
+	annotations := objMeta.GetAnnotations()
+	for key, value := range annotations {
+		if len(value) > maxCachedAnnotationSize && !strings.HasPrefix(key, shipperAnnotationPrefix) {
+			delete(annotations, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": strings.Repeat("x", maxCachedAnnotationSize+1),
+				"shipper.booking.com/large":                        strings.Repeat("x", maxCachedAnnotationSize+1),
+				"controller.kubernetes.io/pod-deletion-cost":       "100",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply},
+			},
+		},
+	}
+}
+
+// TestWorkloadInformerFactoryStripsObjects verifies that workload objects
+// lose their managed fields and large annotations before they're cached,
+// whether they were listed or watched, and that only the ones in the
+// factory's namespace are.
+func TestWorkloadInformerFactoryStripsObjects(t *testing.T) {
+	client := kubefake.NewSimpleClientset(
+		newPod("payments", "listed"),
+		newPod("search", "elsewhere"),
+	)
+
+	factory := NewWorkloadInformerFactory(client, 0, "payments", nil, appsv1.SchemeGroupVersion)
+	lister := factory.Core().V1().Pods().Lister()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	if _, err := client.CoreV1().Pods("payments").Create(newPod("payments", "watched")); err != nil {
+		t.Fatalf("unexpected error creating pod: %s", err)
+	}
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		pods, err := lister.List(labels.Everything())
+		return err == nil && len(pods) == 2, nil
+	})
+	if err != nil {
+		pods, _ := lister.List(labels.Everything())
+		t.Fatalf("expected 2 pods in the cache, got %d", len(pods))
+	}
+
+	for _, name := range []string{"listed", "watched"} {
+		pod, err := lister.Pods("payments").Get(name)
+		if err != nil {
+			t.Fatalf("expected pod %q to be cached: %s", name, err)
+		}
+
+		if len(pod.ManagedFields) > 0 {
+			t.Errorf("expected pod %q to have no managed fields, got %v", name, pod.ManagedFields)
+		}
+
+		if _, ok := pod.Annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+			t.Errorf("expected pod %q to have no large annotations", name)
+		}
+
+		for _, annotation := range []string{"shipper.booking.com/large", "controller.kubernetes.io/pod-deletion-cost"} {
+			if _, ok := pod.Annotations[annotation]; !ok {
+				t.Errorf("expected pod %q to keep annotation %q", name, annotation)
+			}
+		}
+	}
+}
+
+// TestWorkloadInformerFactoryLegacyApps verifies that Deployments in
+// clusters only serving apps/v1beta2 are cached as apps/v1 ones.
+func TestWorkloadInformerFactoryLegacyApps(t *testing.T) {
+	replicas := int32(3)
+	client := kubefake.NewSimpleClientset(&appsv1beta2.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews-api", Namespace: "payments"},
+		Spec:       appsv1beta2.DeploymentSpec{Replicas: &replicas},
+	})
+
+	factory := NewWorkloadInformerFactory(client, 0, "payments", nil, appsv1beta2.SchemeGroupVersion)
+	lister := factory.Apps().V1().Deployments().Lister()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	deployment, err := lister.Deployments("payments").Get("reviews-api")
+	if err != nil {
+		t.Fatalf("expected deployment to be cached: %s", err)
+	}
+
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != replicas {
+		t.Fatalf("expected deployment to have %d replicas, got %v", replicas, deployment.Spec.Replicas)
+	}
+}
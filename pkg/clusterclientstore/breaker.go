@@ -0,0 +1,257 @@
+package clusterclientstore
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	// CircuitOpen is the reason of the Operational condition of clusters
+	// Shipper stopped calling after too many failed calls.
+	CircuitOpen = "CircuitOpen"
+	// CircuitClosed is the reason of the Operational condition of
+	// clusters that answer calls again.
+	CircuitClosed = "CircuitClosed"
+)
+
+// breaker is a circuit breaker for the API server of an application
+// cluster. It opens after threshold calls in a row fail, and fails calls
+// right away until cooldown is over. Calls are let through again after
+// that, and a single one failing opens it again until one succeeds.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	// onChange is called with whether the breaker is open whenever it
+	// opens or closes.
+	onChange func(open bool)
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	open      bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration, onChange func(bool)) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		onChange:  onChange,
+	}
+}
+
+// allow returns the time calls are let through again from, and whether
+// they are now.
+func (b *breaker) allow(now time.Time) (time.Time, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.openUntil, !now.Before(b.openUntil)
+}
+
+// isOpen tells whether the breaker opened and hasn't seen a call succeed
+// since, even if its cooldown is over.
+func (b *breaker) isOpen() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.open
+}
+
+// record counts a call that failed or succeeded at now.
+func (b *breaker) record(failed bool, now time.Time) {
+	b.mutex.Lock()
+
+	wasOpen := b.open
+	if failed {
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = now.Add(b.cooldown)
+			b.open = true
+		}
+	} else {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.open = false
+	}
+	changed := wasOpen != b.open
+
+	b.mutex.Unlock()
+
+	if changed && b.onChange != nil {
+		b.onChange(!wasOpen)
+	}
+}
+
+// wrap returns a RoundTripper for clusterName that fails calls right away
+// while b is open, and records how the others went.
+func (b *breaker) wrap(clusterName string, rt http.RoundTripper) http.RoundTripper {
+	return breakerRoundTripper{
+		clusterName: clusterName,
+		breaker:     b,
+		rt:          rt,
+	}
+}
+
+type breakerRoundTripper struct {
+	clusterName string
+	breaker     *breaker
+	rt          http.RoundTripper
+}
+
+func (t breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if until, ok := t.breaker.allow(time.Now()); !ok {
+		return nil, shippererrors.NewClusterCircuitOpenError(t.clusterName, until)
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+
+	// Requests the API server refuses are answers all the same. Only
+	// the ones it can't answer count against it.
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	t.breaker.record(failed, time.Now())
+
+	return resp, err
+}
+
+// EnableCircuitBreaker makes the store stop calling clusters that failed
+// threshold calls in a row for cooldown, instead of waiting for each call
+// to time out. Their Operational condition is set to False through client
+// while they're not called, and to True again once a call succeeds.
+// Clusters in pull mode are never cut off, as they're called through the
+// management cluster.
+func (s *Store) EnableCircuitBreaker(client shipperclientset.Interface, threshold int, cooldown time.Duration) {
+	s.breakerClient = client
+	s.breakerThreshold = threshold
+	s.breakerCooldown = cooldown
+	s.breakers = make(map[string]*breaker)
+}
+
+// getBreaker returns the breaker of clusterName, creating it if needed, or
+// nil if circuit breaking is not enabled.
+func (s *Store) getBreaker(clusterName string) *breaker {
+	if s.breakers == nil {
+		return nil
+	}
+
+	s.breakersMutex.Lock()
+	defer s.breakersMutex.Unlock()
+
+	b, ok := s.breakers[clusterName]
+	if !ok {
+		b = newBreaker(s.breakerThreshold, s.breakerCooldown, func(bool) {
+			// The condition is updated by syncCluster, out of
+			// the way of the call that changed it.
+			s.clusterWorkqueue.Add(clusterName)
+		})
+		s.breakers[clusterName] = b
+	}
+
+	return b
+}
+
+// checkBreaker returns an error if clusterName is not being called.
+func (s *Store) checkBreaker(clusterName string) error {
+	if s.breakers == nil {
+		return nil
+	}
+
+	s.breakersMutex.Lock()
+	b, ok := s.breakers[clusterName]
+	s.breakersMutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	if until, ok := b.allow(time.Now()); !ok {
+		return shippererrors.NewClusterCircuitOpenError(clusterName, until)
+	}
+
+	return nil
+}
+
+func (s *Store) removeBreaker(clusterName string) {
+	if s.breakers == nil {
+		return
+	}
+
+	s.breakersMutex.Lock()
+	defer s.breakersMutex.Unlock()
+
+	delete(s.breakers, clusterName)
+}
+
+// syncOperationalCondition makes the Operational condition of cluster
+// match its breaker, if it has one.
+func (s *Store) syncOperationalCondition(cluster *shipper.Cluster) error {
+	if s.breakers == nil {
+		return nil
+	}
+
+	s.breakersMutex.Lock()
+	b, ok := s.breakers[cluster.Name]
+	s.breakersMutex.Unlock()
+
+	open := ok && b.isOpen()
+
+	// Clusters only get the condition once their breaker opens, so
+	// that the ones that never failed are left alone.
+	var existing *shipper.ClusterCondition
+	for i := range cluster.Status.Conditions {
+		if cluster.Status.Conditions[i].Type == shipper.ClusterConditionTypeOperational {
+			existing = &cluster.Status.Conditions[i]
+		}
+	}
+	if !open && existing == nil {
+		return nil
+	}
+
+	condition := shipper.ClusterCondition{
+		Type:   shipper.ClusterConditionTypeOperational,
+		Status: corev1.ConditionTrue,
+		Reason: CircuitClosed,
+	}
+	if open {
+		until, _ := b.allow(time.Now())
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = CircuitOpen
+		condition.Message = shippererrors.NewClusterCircuitOpenError(cluster.Name, until).Error()
+	}
+
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason {
+		return nil
+	}
+
+	condition.LastTransitionTime = metav1.Now()
+
+	cluster = cluster.DeepCopy()
+	conditions := []shipper.ClusterCondition{condition}
+	for _, c := range cluster.Status.Conditions {
+		if c.Type != shipper.ClusterConditionTypeOperational {
+			conditions = append(conditions, c)
+		}
+	}
+	cluster.Status.Conditions = conditions
+
+	klog.Infof("Cluster %q is now Operational=%s (%s)", cluster.Name, condition.Status, condition.Reason)
+
+	// Clusters have no status subresource, so their status is updated
+	// along with the rest of them.
+	_, err := s.breakerClient.ShipperV1alpha1().Clusters().Update(cluster)
+	if err != nil && !errors.IsNotFound(err) {
+		return shippererrors.NewKubeclientUpdateError(cluster, err).
+			WithShipperKind("Cluster")
+	}
+
+	return nil
+}
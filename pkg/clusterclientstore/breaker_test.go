@@ -0,0 +1,154 @@
+package clusterclientstore
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestBreaker verifies that breakers open after enough calls in a row
+// fail, fail calls right away during their cooldown, open again as soon as
+// a call fails after it, and close once one succeeds.
+func TestBreaker(t *testing.T) {
+	var changes []bool
+	b := newBreaker(3, time.Hour, func(open bool) {
+		changes = append(changes, open)
+	})
+
+	calls := 0
+	var respErr error
+	status := http.StatusOK
+	rt := b.wrap(testClusterName, roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		if respErr != nil {
+			return nil, respErr
+		}
+		return &http.Response{StatusCode: status}, nil
+	}))
+
+	req, _ := http.NewRequest("GET", "https://localhost/api", nil)
+
+	respErr = errors.New("connection refused")
+	rt.RoundTrip(req)
+	respErr = nil
+	status = http.StatusNotFound
+	rt.RoundTrip(req)
+
+	// Refused calls are answers, so this takes three more failures.
+	respErr = errors.New("connection refused")
+	rt.RoundTrip(req)
+	rt.RoundTrip(req)
+	if b.isOpen() {
+		t.Fatalf("expected breaker to stay closed after 2 failures in a row")
+	}
+	respErr = nil
+	status = http.StatusServiceUnavailable
+	rt.RoundTrip(req)
+	if !b.isOpen() {
+		t.Fatalf("expected breaker to open after 3 failures in a row")
+	}
+
+	calls = 0
+	_, err := rt.RoundTrip(req)
+	if !shippererrors.IsClusterCircuitOpenError(err) {
+		t.Fatalf("expected calls to fail right away while the breaker is open, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no calls to reach the cluster, got %d", calls)
+	}
+
+	// Once the cooldown is over, a single failure is enough to open
+	// it again.
+	b.openUntil = time.Now().Add(-time.Second)
+	rt.RoundTrip(req)
+	if _, ok := b.allow(time.Now()); ok {
+		t.Fatalf("expected breaker to open again after a failure past its cooldown")
+	}
+
+	b.openUntil = time.Now().Add(-time.Second)
+	status = http.StatusOK
+	rt.RoundTrip(req)
+	if b.isOpen() {
+		t.Fatalf("expected breaker to close after a call succeeded")
+	}
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("expected breaker to open and then close, got %v", changes)
+	}
+}
+
+// TestOperationalCondition verifies that clusters get Operational=False
+// while their breaker is open, and True again once it closes.
+func TestOperationalCondition(t *testing.T) {
+	cluster := &shipper.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: testClusterName},
+	}
+	client := shipperfake.NewSimpleClientset(cluster)
+
+	s := &Store{
+		clusterWorkqueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+	}
+	defer s.clusterWorkqueue.ShutDown()
+	s.EnableCircuitBreaker(client, 1, time.Hour)
+
+	expectCondition := func(status corev1.ConditionStatus, reason string) {
+		t.Helper()
+
+		var err error
+		cluster, err = client.ShipperV1alpha1().Clusters().Get(testClusterName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conditions := cluster.Status.Conditions
+		if status == "" {
+			if len(conditions) != 0 {
+				t.Fatalf("expected no conditions, got %v", conditions)
+			}
+			return
+		}
+
+		if len(conditions) != 1 || conditions[0].Status != status || conditions[0].Reason != reason {
+			t.Fatalf("expected Operational=%s with reason %s, got %v", status, reason, conditions)
+		}
+	}
+
+	b := s.getBreaker(testClusterName)
+	if err := s.syncOperationalCondition(cluster); err != nil {
+		t.Fatal(err)
+	}
+	expectCondition("", "")
+
+	b.record(true, time.Now())
+	if s.clusterWorkqueue.Len() != 1 {
+		t.Fatalf("expected the cluster to be synced when its breaker opens")
+	}
+	if err := s.checkBreaker(testClusterName); !shippererrors.IsClusterCircuitOpenError(err) {
+		t.Fatalf("expected clientsets not to be handed out while the breaker is open, got %v", err)
+	}
+	if err := s.syncOperationalCondition(cluster); err != nil {
+		t.Fatal(err)
+	}
+	expectCondition(corev1.ConditionFalse, CircuitOpen)
+
+	b.record(false, time.Now())
+	if err := s.syncOperationalCondition(cluster); err != nil {
+		t.Fatal(err)
+	}
+	expectCondition(corev1.ConditionTrue, CircuitClosed)
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"time"
 
 	kubeinformers "k8s.io/client-go/informers"
 	kubernetes "k8s.io/client-go/kubernetes"
@@ -10,6 +11,7 @@ import (
 	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 )
 
 const (
@@ -181,6 +183,7 @@ func (c *Cluster) WaitForInformerCache() {
 	c.state = StateWaitingForSync
 	c.stateMut.Unlock()
 
+	start := time.Now()
 	c.kubeInformerFactory.Start(c.stopCh)
 	c.shipperInformerFactory.Start(c.stopCh)
 
@@ -189,11 +192,17 @@ func (c *Cluster) WaitForInformerCache() {
 	for _, synced := range syncedKubeInformers {
 		ok = ok && synced
 	}
+	if ok {
+		shippermetrics.ObserveCacheSync(c.name, "kubernetes", start)
+	}
 
 	syncedShipperInformers := c.shipperInformerFactory.WaitForCacheSync(c.stopCh)
 	for _, synced := range syncedShipperInformers {
 		ok = ok && synced
 	}
+	if ok {
+		shippermetrics.ObserveCacheSync(c.name, "shipper", start)
+	}
 
 	if ok {
 		// No defer unlock here because I don't want the lock scope to cover the
@@ -0,0 +1,58 @@
+package clusterclientstore
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	"github.com/bookingcom/shipper/pkg/clusterclientstore/cache"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/pull"
+)
+
+// pullChecksum stands in for the checksum of the secret of clusters in pull
+// mode, as they have none.
+const pullChecksum = "pull"
+
+// EnablePullMode makes the store serve clusters in pull mode. Their target
+// objects are copies kept in the management cluster, which config is for,
+// for the agents running in them to sync. Their kube clients are for the
+// management cluster too, so they mustn't be used. It must be called before
+// Run.
+func (s *Store) EnablePullMode(config *rest.Config) {
+	s.pullConfig = config
+}
+
+func (s *Store) syncPullCluster(cluster *shipper.Cluster) error {
+	if s.pullConfig == nil {
+		klog.Infof("Cluster %q is in pull mode, but pull mode is not enabled", cluster.Name)
+		s.cache.Remove(cluster.Name)
+		return nil
+	}
+
+	if cachedCluster, ok := s.cache.Fetch(cluster.Name); ok && isPullCluster(cachedCluster) {
+		klog.V(4).Infof("Cluster %q syncing, but we already have a pull mode client in the cache", cluster.Name)
+		return nil
+	}
+
+	buildShipperClient := func(clusterName, ua string, config *rest.Config) (shipperclientset.Interface, error) {
+		client, err := s.buildShipperClient(clusterName, ua, config)
+		if err != nil {
+			return nil, err
+		}
+
+		return pull.NewClientset(client, clusterName), nil
+	}
+
+	return s.createFromConfig(cluster, rest.CopyConfig(s.pullConfig), pullChecksum, buildShipperClient, nil)
+}
+
+func isPullCluster(cluster *cache.Cluster) bool {
+	checksum, err := cluster.GetChecksum()
+	if err != nil && !shippererrors.IsClusterNotReadyError(err) {
+		return false
+	}
+
+	return checksum == pullChecksum
+}
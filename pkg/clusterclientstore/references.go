@@ -0,0 +1,203 @@
+package clusterclientstore
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/runtime"
+	kubecache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperinformer "github.com/bookingcom/shipper/pkg/client/informers/externalversions/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// clusterReferences counts how many Releases are scheduled on each cluster,
+// so the store only keeps informers running for clusters that have targets
+// on them.
+type clusterReferences struct {
+	gracePeriod time.Duration
+
+	mut sync.Mutex
+	// Keyed by release key, so we know what to release when a Release
+	// changes clusters or goes away.
+	releaseClusters map[string][]string
+	counts          map[string]int
+	// When each cluster lost its last reference.
+	unreferencedSince map[string]time.Time
+}
+
+func newClusterReferences(gracePeriod time.Duration) *clusterReferences {
+	return &clusterReferences{
+		gracePeriod:       gracePeriod,
+		releaseClusters:   map[string][]string{},
+		counts:            map[string]int{},
+		unreferencedSince: map[string]time.Time{},
+	}
+}
+
+// set records the clusters a Release is scheduled on, returning the clusters
+// that gained their first reference and the ones that lost their last one.
+func (r *clusterReferences) set(key string, clusters []string) (referenced, unreferenced []string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	// New references are counted before old ones are dropped, so
+	// clusters the Release stays on never drop to zero.
+	for _, cluster := range clusters {
+		r.counts[cluster]++
+		if r.counts[cluster] == 1 {
+			delete(r.unreferencedSince, cluster)
+			referenced = append(referenced, cluster)
+		}
+	}
+
+	for _, cluster := range r.releaseClusters[key] {
+		r.counts[cluster]--
+		if r.counts[cluster] == 0 {
+			delete(r.counts, cluster)
+			r.unreferencedSince[cluster] = time.Now()
+			unreferenced = append(unreferenced, cluster)
+		}
+	}
+
+	if len(clusters) > 0 {
+		r.releaseClusters[key] = clusters
+	} else {
+		delete(r.releaseClusters, key)
+	}
+
+	return referenced, unreferenced
+}
+
+// idleFor returns how much longer a cluster needs to stay unreferenced before
+// its informers can be stopped. A cluster that is referenced returns false.
+func (r *clusterReferences) idleFor(cluster string) (time.Duration, bool) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.counts[cluster] > 0 {
+		return 0, false
+	}
+
+	since, ok := r.unreferencedSince[cluster]
+	if !ok {
+		// Clusters no Release has ever referenced are idle from the
+		// start, there's nothing to wait for.
+		return 0, true
+	}
+
+	remaining := r.gracePeriod - time.Since(since)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, true
+}
+
+// EnableLazyStart makes the store only start informers for clusters that
+// Releases are scheduled on. Informers for clusters that no Release refers
+// to anymore are stopped, and their caches dropped, after gracePeriod. It
+// must be called before Run.
+func (s *Store) EnableLazyStart(gracePeriod time.Duration) {
+	s.references = newClusterReferences(gracePeriod)
+	s.releaseInformer = s.shipperInformerFactory.Shipper().V1alpha1().Releases()
+	s.bindReleaseEventHandlers(s.releaseInformer)
+}
+
+func (s *Store) bindReleaseEventHandlers(releaseInformer shipperinformer.ReleaseInformer) {
+	updateReferences := func(obj interface{}, deleted bool) {
+		rel, ok := obj.(*shipper.Release)
+		if !ok {
+			tombstone, ok := obj.(kubecache.DeletedFinalStateUnknown)
+			if !ok {
+				runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+				return
+			}
+			rel, ok = tombstone.Obj.(*shipper.Release)
+			if !ok {
+				runtime.HandleError(fmt.Errorf("tombstone contained object that is not a Release %#v", obj))
+				return
+			}
+		}
+
+		key, err := kubecache.MetaNamespaceKeyFunc(rel)
+		if err != nil {
+			runtime.HandleError(err)
+			return
+		}
+
+		var clusters []string
+		if !deleted {
+			clusters = releaseutil.GetSelectedClusters(rel)
+		}
+
+		referenced, unreferenced := s.references.set(key, clusters)
+		for _, cluster := range referenced {
+			klog.V(4).Infof("Cluster %q has targets now, starting its informers", cluster)
+			s.clusterWorkqueue.Add(cluster)
+		}
+		for _, cluster := range unreferenced {
+			klog.V(4).Infof("Cluster %q has no targets left, stopping its informers in %s", cluster, s.references.gracePeriod)
+			s.clusterWorkqueue.AddAfter(cluster, s.references.gracePeriod)
+		}
+	}
+
+	releaseInformer.Informer().AddEventHandler(kubecache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			updateReferences(obj, false)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			updateReferences(newObj, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			updateReferences(obj, true)
+		},
+	})
+}
+
+// isIdle returns whether a cluster's informers should not be running. If the
+// cluster is still within its grace period, it is enqueued to be checked
+// again once it expires.
+func (s *Store) isIdle(name string) bool {
+	if s.references == nil {
+		return false
+	}
+
+	// Until the release informer has synced, a cluster without references
+	// might just be one whose Releases we haven't seen yet. Check it again
+	// later rather than stop informers that are about to be needed.
+	if !s.releaseInformer.Informer().HasSynced() {
+		s.clusterWorkqueue.AddRateLimited(name)
+		return false
+	}
+
+	remaining, idle := s.references.idleFor(name)
+	if !idle {
+		return false
+	}
+
+	if remaining > 0 {
+		s.clusterWorkqueue.AddAfter(name, remaining)
+		return false
+	}
+
+	return true
+}
+
+// stopIfIdle drops an idle cluster from the cache, stopping its informers,
+// and returns whether the cluster was idle.
+func (s *Store) stopIfIdle(name string) bool {
+	if !s.isIdle(name) {
+		return false
+	}
+
+	if _, ok := s.cache.Fetch(name); ok {
+		klog.Infof("Cluster %q has no targets; stopping its informers", name)
+		s.cache.Remove(name)
+	}
+
+	return true
+}
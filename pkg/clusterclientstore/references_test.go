@@ -0,0 +1,54 @@
+package clusterclientstore
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClusterReferences(t *testing.T) {
+	r := newClusterReferences(time.Hour)
+
+	referenced, unreferenced := r.set("ns/app-0", []string{"a", "b"})
+	expectClusters(t, "first release", referenced, []string{"a", "b"}, unreferenced, nil)
+
+	referenced, unreferenced = r.set("ns/app-1", []string{"b", "c"})
+	expectClusters(t, "second release", referenced, []string{"c"}, unreferenced, nil)
+
+	referenced, unreferenced = r.set("ns/app-0", []string{"b"})
+	expectClusters(t, "first release moves off a", referenced, nil, unreferenced, []string{"a"})
+
+	if remaining, idle := r.idleFor("a"); !idle || remaining <= 0 {
+		t.Errorf("expected a to be idle and within its grace period, got idle=%t remaining=%s", idle, remaining)
+	}
+
+	if _, idle := r.idleFor("b"); idle {
+		t.Errorf("expected b to still be referenced")
+	}
+
+	if remaining, idle := r.idleFor("never-referenced"); !idle || remaining != 0 {
+		t.Errorf("expected a cluster nothing refers to to be idle right away, got idle=%t remaining=%s", idle, remaining)
+	}
+
+	referenced, unreferenced = r.set("ns/app-0", nil)
+	expectClusters(t, "first release deleted", referenced, nil, unreferenced, nil)
+
+	referenced, unreferenced = r.set("ns/app-1", nil)
+	expectClusters(t, "second release deleted", referenced, nil, unreferenced, []string{"b", "c"})
+
+	referenced, unreferenced = r.set("ns/app-2", []string{"a"})
+	expectClusters(t, "a is back", referenced, []string{"a"}, unreferenced, nil)
+
+	if _, idle := r.idleFor("a"); idle {
+		t.Errorf("expected a to be referenced again")
+	}
+}
+
+func expectClusters(t *testing.T, name string, referenced, expectedReferenced, unreferenced, expectedUnreferenced []string) {
+	if !reflect.DeepEqual(referenced, expectedReferenced) {
+		t.Errorf("%s: expected referenced clusters %v, got %v", name, expectedReferenced, referenced)
+	}
+	if !reflect.DeepEqual(unreferenced, expectedUnreferenced) {
+		t.Errorf("%s: expected unreferenced clusters %v, got %v", name, expectedUnreferenced, unreferenced)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash/crc32"
+	"net/http"
 	"sort"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +18,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	kubecache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/transport"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
@@ -26,6 +29,7 @@ import (
 	shipperinformer "github.com/bookingcom/shipper/pkg/client/informers/externalversions/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/clusterclientstore/cache"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
 )
 
 const (
@@ -45,6 +49,21 @@ type Store struct {
 	secretInformer  corev1informer.SecretInformer
 	clusterInformer shipperinformer.ClusterInformer
 
+	// Only set when informers are started lazily, see EnableLazyStart.
+	releaseInformer shipperinformer.ReleaseInformer
+	references      *clusterReferences
+
+	// Only set when clusters in pull mode are served, see EnablePullMode.
+	pullConfig *rest.Config
+
+	// Only set when calls to failing clusters are cut off, see
+	// EnableCircuitBreaker.
+	breakerClient    shipperclientset.Interface
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	breakers         map[string]*breaker
+	breakersMutex    sync.Mutex
+
 	secretWorkqueue  workqueue.RateLimitingInterface
 	clusterWorkqueue workqueue.RateLimitingInterface
 
@@ -99,11 +118,15 @@ func (s *Store) Run(stopCh <-chan struct{}) {
 
 	klog.Info("Waiting for client store informer caches to sync")
 
-	ok := kubecache.WaitForCacheSync(
-		stopCh,
+	cacheSyncs := []kubecache.InformerSynced{
 		s.secretInformer.Informer().HasSynced,
 		s.clusterInformer.Informer().HasSynced,
-	)
+	}
+	if s.releaseInformer != nil {
+		cacheSyncs = append(cacheSyncs, s.releaseInformer.Informer().HasSynced)
+	}
+
+	ok := kubecache.WaitForCacheSync(stopCh, cacheSyncs...)
 
 	if !ok {
 		runtime.HandleError(fmt.Errorf("failed to sync caches for the ClusterClientStore"))
@@ -131,6 +154,10 @@ func (s *Store) AddEventHandlerCallback(eventHandler EventHandlerRegisterFunc) {
 }
 
 func (s *Store) GetApplicationClusterClientset(clusterName, userAgent string) (ClientsetInterface, error) {
+	if err := s.checkBreaker(clusterName); err != nil {
+		return nil, err
+	}
+
 	cluster, ok := s.cache.Fetch(clusterName)
 	if !ok {
 		return nil, shippererrors.NewClusterNotInStoreError(clusterName)
@@ -177,6 +204,7 @@ func (s *Store) syncCluster(name string) error {
 		if errors.IsNotFound(err) {
 			klog.Infof("Cluster %q has been deleted; purging it from client store", name)
 			s.cache.Remove(name)
+			s.removeBreaker(name)
 			return nil
 		}
 
@@ -184,8 +212,20 @@ func (s *Store) syncCluster(name string) error {
 			WithShipperKind("Cluster")
 	}
 
+	if err := s.syncOperationalCondition(clusterObj); err != nil {
+		return err
+	}
+
+	if s.stopIfIdle(name) {
+		return nil
+	}
+
+	if clusterObj.Spec.PullMode {
+		return s.syncPullCluster(clusterObj)
+	}
+
 	cachedCluster, ok := s.cache.Fetch(name)
-	if ok {
+	if ok && !isPullCluster(cachedCluster) {
 		var config *rest.Config
 		config, err = cachedCluster.GetConfig()
 		// We don't want to regenerate the client if we already have one with the
@@ -195,7 +235,7 @@ func (s *Store) syncCluster(name string) error {
 		// cache to fill takes longer than the resync period, and resync resets the
 		// informer.
 		if err == nil || shippererrors.IsClusterNotReadyError(err) {
-			if config != nil && config.Host == clusterObj.Spec.APIMaster {
+			if config != nil && config.Host == clusterObj.Spec.APIMaster && config.Timeout == s.operationTimeout(clusterObj) {
 				klog.Infof("Cluster %q syncing, but we already have a client with the right host in the cache", name)
 				return nil
 			}
@@ -252,6 +292,15 @@ func (s *Store) syncSecret(key string) error {
 			WithShipperKind("Cluster")
 	}
 
+	if clusterObj.Spec.PullMode {
+		klog.V(4).Infof("Secret %q belongs to cluster in pull mode; ignoring", key)
+		return nil
+	}
+
+	if s.stopIfIdle(secret.Name) {
+		return nil
+	}
+
 	if cachedCluster, ok := s.cache.Fetch(secret.Name); ok {
 		secretChecksum := computeSecretChecksum(secret)
 		clusterChecksum, err := cachedCluster.GetChecksum()
@@ -274,23 +323,62 @@ func (s *Store) syncSecret(key string) error {
 
 func (s *Store) create(cluster *shipper.Cluster, secret *corev1.Secret) error {
 	config := shipperclient.BuildConfigFromClusterAndSecret(cluster, secret)
-	if s.restTimeout != nil {
-		config.Timeout = *s.restTimeout
+	checksum := computeSecretChecksum(secret)
+	return s.createFromConfig(cluster, config, checksum, s.buildShipperClient, s.getBreaker(cluster.Name))
+}
+
+// operationTimeout returns the timeout of calls to the API server of
+// cluster, other than watches.
+func (s *Store) operationTimeout(cluster *shipper.Cluster) time.Duration {
+	if cluster.Spec.OperationTimeout != nil {
+		return cluster.Spec.OperationTimeout.Duration
+	} else if s.restTimeout != nil {
+		return *s.restTimeout
 	}
 
+	return noTimeout
+}
+
+// createFromConfig caches clients and informers for cluster built from
+// config. Calls made with the clients go through breaker, unless it's nil.
+func (s *Store) createFromConfig(
+	cluster *shipper.Cluster,
+	config *rest.Config,
+	checksum string,
+	buildShipperClient ShipperClientBuilderFunc,
+	breaker *breaker,
+) error {
+	config.Timeout = s.operationTimeout(cluster)
+
 	// These are only used in shared informers. Setting HTTP timeout here
 	// would affect watches which is undesirable. Instead, we leave it to
 	// client-go (see k8s.io/client-go/tools/cache) to govern watch
 	// durations.
 	informerConfig := rest.CopyConfig(config)
 	informerConfig.Timeout = noTimeout
+	informerConfig.WrapTransport = transport.Wrappers(
+		informerConfig.WrapTransport,
+		shippermetrics.InstrumentInformerTransport(cluster.Name),
+	)
+
+	// Watches are left out of the breaker: they're expected to be cut
+	// off every now and then, and informers back off on their own.
+	if breaker != nil {
+		clusterName := cluster.Name
+		config.WrapTransport = transport.Wrappers(
+			config.WrapTransport,
+			func(rt http.RoundTripper) http.RoundTripper {
+				return breaker.wrap(clusterName, rt)
+			},
+		)
+	}
 
 	kubeInformerClient, err := s.buildKubeClient(cluster.Name, AgentName, informerConfig)
 	if err != nil {
 		return shippererrors.NewClusterClientBuild(cluster.Name, err)
 	}
 
-	shipperInformerClient, err := s.buildShipperClient(cluster.Name, AgentName, informerConfig)
+	shipperInformerClient, err := buildShipperClient(cluster.Name, AgentName, informerConfig)
 	if err != nil {
 		return shippererrors.NewClusterClientBuild(cluster.Name, err)
 	}
@@ -305,11 +393,10 @@ func (s *Store) create(cluster *shipper.Cluster, secret *corev1.Secret) error {
 	}
 
 	clusterName := cluster.Name
-	checksum := computeSecretChecksum(secret)
 	newCachedCluster := cache.NewCluster(
 		clusterName, checksum, config,
 		kubeInformerFactory, shipperInformerFactory,
-		s.buildKubeClient, s.buildShipperClient,
+		s.buildKubeClient, buildShipperClient,
 		func() {
 			// If/when the informer cache finishes syncing, bind all of the event handler
 			// callbacks from the controllers if it does not finish (because the cluster
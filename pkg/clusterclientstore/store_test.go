@@ -207,6 +207,125 @@ func TestReCacheClusterOnSecretUpdate(t *testing.T) {
 	}
 }
 
+// TestPullModeClient tests that clusters in pull mode are reached through
+// the management cluster, and that their secrets, if any, are ignored.
+func TestPullModeClient(t *testing.T) {
+	f := newFixture(t)
+	f.pullConfig = &rest.Config{Host: "management"}
+
+	f.addCluster(testClusterName)
+	f.shipperObjects[0].(*shipper.Cluster).Spec.PullMode = true
+	f.addSecret(newValidSecret(testClusterName))
+
+	store := f.run()
+
+	wait.PollUntil(
+		10*time.Millisecond,
+		func() (bool, error) {
+			cluster, ok := store.cache.Fetch(testClusterName)
+			return ok && cluster.IsReady(), nil
+		},
+		stopAfter(3*time.Second),
+	)
+
+	clientset, err := store.GetApplicationClusterClientset(testClusterName, AgentName)
+	if err != nil {
+		t.Fatalf("unexpected error getting clientset: %s", err)
+	}
+
+	if host := clientset.GetConfig().Host; host != f.pullConfig.Host {
+		t.Errorf("expected client for host %q, got %q", f.pullConfig.Host, host)
+	}
+
+	key := fmt.Sprintf("%s/%s", store.ns, testClusterName)
+	if err := store.syncSecret(key); err != nil {
+		t.Fatalf("unexpected error returned by `store.syncSecret/1`: %s", err)
+	}
+
+	cluster, ok := store.cache.Fetch(testClusterName)
+	if !ok {
+		t.Fatalf("expected to fetch a cluster from the cache")
+	}
+
+	if checksum, _ := cluster.GetChecksum(); checksum != pullChecksum {
+		t.Errorf("expected cluster to be kept in pull mode, got checksum %q", checksum)
+	}
+}
+
+// TestLazyStartReleaseAfterCluster tests that the informers of a cluster no
+// Release is scheduled on are only started once one is.
+func TestLazyStartReleaseAfterCluster(t *testing.T) {
+	f := newFixture(t)
+	gracePeriod := time.Hour
+	f.lazyStart = &gracePeriod
+
+	f.addCluster(testClusterName)
+	f.addSecret(newValidSecret(testClusterName))
+
+	store := f.run()
+
+	// Give the store a chance to sync the cluster, which it should leave
+	// alone as no Release is scheduled on it.
+	wait.PollUntil(
+		10*time.Millisecond,
+		func() (bool, error) { return store.clusterWorkqueue.Len() == 0, nil },
+		stopAfter(3*time.Second),
+	)
+
+	if _, ok := store.cache.Fetch(testClusterName); ok {
+		t.Fatalf("did not expect cluster %q without releases in the cache", testClusterName)
+	}
+
+	rel := &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app-deadbeef-0",
+			Namespace: shipper.ShipperNamespace,
+			Annotations: map[string]string{
+				shipper.ReleaseClustersAnnotation: testClusterName,
+			},
+		},
+	}
+	_, err := f.shipperClient.ShipperV1alpha1().Releases(rel.Namespace).Create(rel)
+	if err != nil {
+		t.Fatalf("failed to create release: %s", err)
+	}
+
+	err = wait.PollUntil(
+		10*time.Millisecond,
+		func() (bool, error) {
+			cluster, ok := store.cache.Fetch(testClusterName)
+			return ok && cluster.IsReady(), nil
+		},
+		stopAfter(3*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("expected cluster %q to be started once a release was scheduled on it", testClusterName)
+	}
+}
+
+// TestLazyStartWaitsForReleases tests that clusters are never considered idle
+// before the store has seen all Releases.
+func TestLazyStartWaitsForReleases(t *testing.T) {
+	f := newFixture(t)
+	gracePeriod := time.Hour
+	f.lazyStart = &gracePeriod
+
+	f.addCluster(testClusterName)
+
+	// The release informer is never started, so it never syncs.
+	store, _, _ := f.newStore()
+	go store.cache.Serve()
+	defer store.cache.Stop()
+
+	if store.stopIfIdle(testClusterName) {
+		t.Fatalf("expected cluster %q not to be idle before releases have synced", testClusterName)
+	}
+
+	if store.clusterWorkqueue.NumRequeues(testClusterName) != 1 {
+		t.Fatalf("expected cluster %q to be checked again later", testClusterName)
+	}
+}
+
 type fixture struct {
 	t              *testing.T
 	s              *Store
@@ -215,6 +334,8 @@ type fixture struct {
 	kubeObjects    []runtime.Object
 	shipperObjects []runtime.Object
 	restTimeout    *time.Duration
+	pullConfig     *rest.Config
+	lazyStart      *time.Duration
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -257,6 +378,14 @@ func (f *fixture) newStore() (*Store, kubeinformers.SharedInformerFactory, shipp
 		f.restTimeout,
 	)
 
+	if f.pullConfig != nil {
+		store.EnablePullMode(f.pullConfig)
+	}
+
+	if f.lazyStart != nil {
+		store.EnableLazyStart(*f.lazyStart)
+	}
+
 	return store, kubeInformerFactory, shipperInformerFactory
 }
 
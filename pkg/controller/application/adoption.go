@@ -0,0 +1,18 @@
+package application
+
+import (
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// adoptInRelease makes rel take over the objects adopt names. They're
+// already running the application and getting all of its traffic, so rel
+// starts out at the last step of its strategy instead of rolling out from
+// the first one.
+func adoptInRelease(rel *shipper.Release, adopt *shipper.Adoption) {
+	rel.Spec.Adopt = adopt.DeepCopy()
+
+	strategy := rel.Spec.Environment.Strategy
+	if strategy != nil && len(strategy.Steps) > 0 {
+		rel.Spec.TargetStep = int32(len(strategy.Steps) - 1)
+	}
+}
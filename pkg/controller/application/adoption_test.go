@@ -0,0 +1,77 @@
+package application
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
+)
+
+// TestCreateFirstReleaseAdopting verifies that the first release of an
+// application adopting existing objects names them, and starts out at the
+// last step of its strategy.
+func TestCreateFirstReleaseAdopting(t *testing.T) {
+	f := newFixture(t)
+	app := newApplication(testAppName)
+	app.Spec.Adopt = &shipper.Adoption{
+		Deployment: "legacy-app",
+		Services:   []string{"legacy-app"},
+	}
+
+	f.objects = append(f.objects, app)
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+
+	envHash := hashReleaseEnvironment(expectedApp.Spec.Template)
+	expectedRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
+
+	expectedRelease := newRelease(expectedRelName, expectedApp)
+	expectedRelease.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	expectedRelease.Annotations[shipper.ReleaseTemplateIterationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+	expectedRelease.Spec.Adopt = app.Spec.Adopt.DeepCopy()
+	expectedRelease.Spec.TargetStep = int32(len(vanguard.Steps) - 1)
+
+	f.expectReleaseCreate(expectedRelease)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True Rolling out initial release "%s"]`, expectedRelease.Name),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
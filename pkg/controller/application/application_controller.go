@@ -10,7 +10,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -21,12 +20,19 @@ import (
 	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/shipperconfig"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	apputil "github.com/bookingcom/shipper/pkg/util/application"
 	"github.com/bookingcom/shipper/pkg/util/conditions"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 	"github.com/bookingcom/shipper/pkg/util/rolloutblock"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
@@ -52,9 +58,26 @@ type Controller struct {
 	rbLister listers.RolloutBlockLister
 	rbSynced cache.InformerSynced
 
+	templateLister listers.ApplicationTemplateLister
+	templateSynced cache.InformerSynced
+
+	defaultLister listers.ApplicationDefaultLister
+	defaultSynced cache.InformerSynced
+
+	strategyLister listers.StrategyLister
+	strategySynced cache.InformerSynced
+
 	versionResolver shipperrepo.ChartVersionResolver
 
 	recorder record.EventRecorder
+
+	// config holds the default strategy of applications that get none
+	// from their ApplicationDefault. Nil if there's no ShipperConfig.
+	config *shipperconfig.Store
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
 }
 
 // NewController returns a new Application controller.
@@ -63,17 +86,22 @@ func NewController(
 	shipperInformerFactory informers.SharedInformerFactory,
 	versionResolver shipperrepo.ChartVersionResolver,
 	recorder record.EventRecorder,
+	drainTimeout time.Duration,
+	config *shipperconfig.Store,
 ) *Controller {
 	appInformer := shipperInformerFactory.Shipper().V1alpha1().Applications()
 	relInformer := shipperInformerFactory.Shipper().V1alpha1().Releases()
 	rbInformer := shipperInformerFactory.Shipper().V1alpha1().RolloutBlocks()
+	templateInformer := shipperInformerFactory.Shipper().V1alpha1().ApplicationTemplates()
+	defaultInformer := shipperInformerFactory.Shipper().V1alpha1().ApplicationDefaults()
+	strategyInformer := shipperInformerFactory.Shipper().V1alpha1().Strategies()
 
 	c := &Controller{
 		shipperClientset: shipperClientset,
 
 		appLister: appInformer.Lister(),
 		appSynced: appInformer.Informer().HasSynced,
-		workqueue: workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "application_controller_applications"),
+		workqueue: shipperworkqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "application_controller_applications"),
 
 		relLister: relInformer.Lister(),
 		relSynced: relInformer.Informer().HasSynced,
@@ -81,8 +109,20 @@ func NewController(
 		rbLister: rbInformer.Lister(),
 		rbSynced: rbInformer.Informer().HasSynced,
 
+		templateLister: templateInformer.Lister(),
+		templateSynced: templateInformer.Informer().HasSynced,
+
+		defaultLister: defaultInformer.Lister(),
+		defaultSynced: defaultInformer.Informer().HasSynced,
+
+		strategyLister: strategyInformer.Lister(),
+		strategySynced: strategyInformer.Informer().HasSynced,
+
 		versionResolver: versionResolver,
 		recorder:        recorder,
+
+		drainTimeout: drainTimeout,
+		config:       config,
 	}
 
 	appInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -90,7 +130,7 @@ func NewController(
 		UpdateFunc: func(_, new interface{}) {
 			c.enqueueApp(new)
 		},
-		DeleteFunc: c.enqueueApp,
+		DeleteFunc: objectutil.OnDelete(c.enqueueApp),
 	})
 
 	relInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -98,11 +138,33 @@ func NewController(
 		UpdateFunc: func(old, new interface{}) {
 			c.enqueueRel(new)
 		},
-		DeleteFunc: c.enqueueRel,
+		DeleteFunc: objectutil.OnDelete(c.enqueueRel),
 	})
 
 	rbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		DeleteFunc: c.enqueueAppFromRolloutBlock,
+		DeleteFunc: objectutil.OnDelete(c.enqueueAppFromRolloutBlock),
+	})
+
+	templateInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueAppsFromTemplate,
+		UpdateFunc: func(_, new interface{}) {
+			c.enqueueAppsFromTemplate(new)
+		},
+		DeleteFunc: objectutil.OnDelete(c.enqueueAppsFromTemplate),
+	})
+
+	defaultInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueAppsFromDefault,
+		UpdateFunc: func(_, new interface{}) {
+			c.enqueueAppsFromDefault(new)
+		},
+	})
+
+	strategyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueAppsFromStrategy,
+		UpdateFunc: func(_, new interface{}) {
+			c.enqueueAppsFromStrategy(new)
+		},
 	})
 
 	return c
@@ -117,23 +179,19 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 	klog.V(2).Info("Starting Application controller")
 	defer klog.V(2).Info("Shutting down Application controller")
 
-	if !cache.WaitForCacheSync(stopCh, c.appSynced, c.relSynced, c.rbSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.appSynced, c.relSynced, c.rbSynced, c.templateSynced, c.defaultSynced, c.strategySynced) {
 		runtime.HandleError(fmt.Errorf("failed to sync caches for the Application controller"))
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.applicationWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Application controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(2).Info("Started Application controller")
 
 	<-stopCh
-}
 
-func (c *Controller) applicationWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -156,11 +214,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "Application", key)
 	err := c.syncApplication(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Application", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing Application %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -329,9 +391,12 @@ func (c *Controller) wrapUpApplicationConditions(app *shipper.Application, rels
 		// There's no contender release yet, so RollingOut condition is
 		// Unknown, with error as message.
 		rollingOutCond.Message = err.Error()
+		app.Status.Phase = shipper.ApplicationPhasePending
 		goto End
 	}
 
+	app.Status.Phase = applicationPhase(contenderRel)
+
 	if incumbentRel, err = apputil.GetIncumbent(app.Name, rels); err != nil && !shippererrors.IsIncumbentNotFoundError(err) {
 		// Errors other than incumbent release not found bail out to not
 		// report inconsistent status.
@@ -358,7 +423,7 @@ End:
 
 func (c *Controller) reportApplicationConditionChange(app *shipper.Application, diff diffutil.Diff) {
 	if !diff.IsEmpty() {
-		c.recorder.Event(app, corev1.EventTypeNormal, "ApplicationConditionChanged", diff.String())
+		c.recorder.Event(app, corev1.EventTypeNormal, shipperevents.ApplicationConditionChanged, diff.String())
 	}
 }
 
@@ -373,6 +438,8 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 	var (
 		appReleases     []*shipper.Release
 		contender       *shipper.Release
+		env             *shipper.ReleaseEnvironment
+		defaulted       []string
 		err             error
 		generation      int
 		highestObserved int
@@ -390,11 +457,30 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 	// Required by subsequent calls to GetContender and GetIncumbent.
 	appReleases = releaseutil.SortByGenerationDescending(appReleases)
 
+	// Applications following an ApplicationTemplate get an environment
+	// of their own, that's rendered every time and never saved in them.
+	if env, err = c.environmentForApplication(app); err != nil {
+		cond := apputil.NewApplicationCondition(
+			shipper.ApplicationConditionTypeRollingOut,
+			corev1.ConditionFalse,
+			conditions.TemplateRenderFailed,
+			err.Error(),
+		)
+
+		diff.Append(apputil.SetApplicationCondition(&app.Status, *cond))
+		app.Status.Phase = shipper.ApplicationPhaseFailed
+
+		if _, updErr := c.shipperClientset.ShipperV1alpha1().Applications(app.Namespace).Update(app); updErr != nil {
+			return shippererrors.NewKubeclientUpdateError(app, updErr).WithShipperKind("Application")
+		}
+		return err
+	}
+
 	// Check if application chart spec is resolved: the original version
 	// might contain either a specific version or a semver constraint.
 	// If a semver constraint is found, it would be resolved in-place.
-	if !apputil.ChartVersionResolved(app) {
-		if _, err := apputil.ResolveChartVersion(app, c.versionResolver); err != nil {
+	if !apputil.ChartVersionResolved(app, &env.Chart) {
+		if _, err := apputil.ResolveChartVersion(app, &env.Chart, c.versionResolver); err != nil {
 			cond := apputil.NewApplicationCondition(
 				shipper.ApplicationConditionTypeRollingOut,
 				corev1.ConditionFalse,
@@ -403,12 +489,45 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 			)
 
 			diff.Append(apputil.SetApplicationCondition(&app.Status, *cond))
+			app.Status.Phase = shipper.ApplicationPhaseFailed
 
 			if _, updErr := c.shipperClientset.ShipperV1alpha1().Applications(app.Namespace).Update(app); updErr != nil {
 				return shippererrors.NewKubeclientUpdateError(app, updErr).WithShipperKind("Application")
 			}
 			return err
 		}
+
+		// Versions applications ask for are pinned like in their
+		// own environments, while the ones their template asks for
+		// are resolved again until it's changed.
+		if app.Spec.TemplateRef != nil && app.Spec.Template.Chart.Version != "" {
+			app.Spec.Template.Chart.Version = env.Chart.Version
+		}
+	}
+
+	// Applications can leave their strategy and cluster requirements to
+	// the ApplicationDefault of their namespace.
+	if env, defaulted, err = c.applyApplicationDefault(app, env); err != nil {
+		return err
+	}
+
+	// Strategies referring to a Strategy get a copy of it, that releases
+	// keep even when it's changed.
+	if env, err = c.applyStrategyRef(app, env); err != nil {
+		cond := apputil.NewApplicationCondition(
+			shipper.ApplicationConditionTypeRollingOut,
+			corev1.ConditionFalse,
+			conditions.StrategyResolutionFailed,
+			err.Error(),
+		)
+
+		diff.Append(apputil.SetApplicationCondition(&app.Status, *cond))
+		app.Status.Phase = shipper.ApplicationPhaseFailed
+
+		if _, updErr := c.shipperClientset.ShipperV1alpha1().Applications(app.Namespace).Update(app); updErr != nil {
+			return shippererrors.NewKubeclientUpdateError(app, updErr).WithShipperKind("Application")
+		}
+		return err
 	}
 
 	rolloutBlocked, events, err := rolloutblock.BlocksRollout(c.rbLister, app)
@@ -447,16 +566,18 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 		}
 
 		// Contender doesn't exist, so we are covering the case where Shipper
-		// is creating the first release for this application.
-		if releaseName, iteration, err := c.releaseNameForApplication(app); err != nil {
+		// is creating the first release for this application. Only this
+		// one can adopt objects already in the application clusters.
+		if releaseName, iteration, err := c.releaseNameForApplication(app, env); err != nil {
 			return err
-		} else if rel, err := c.createReleaseForApplication(app, releaseName, iteration, generation); err != nil {
+		} else if rel, err := c.createReleaseForApplication(app, env, defaulted, app.Spec.Adopt, releaseName, iteration, generation); err != nil {
 			releaseSyncedCond := apputil.NewApplicationCondition(
 				shipper.ApplicationConditionTypeReleaseSynced,
 				corev1.ConditionFalse,
 				conditions.CreateReleaseFailed,
 				fmt.Sprintf("could not create a new release: %q", err))
 			diff.Append(apputil.SetApplicationCondition(&app.Status, *releaseSyncedCond))
+			app.Status.Phase = shipper.ApplicationPhaseFailed
 			return err
 		} else {
 			appReleases = append(appReleases, rel)
@@ -500,6 +621,8 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 		// created and deleted. As side-effect of this, the contender's
 		// environment will be copied back to the application.
 		apputil.CopyEnvironment(app, contender)
+		unsetStrategySnapshot(app, contender)
+		unsetApplicationDefault(app, contender)
 		// keeping app annotations consistent with the new "old" release
 		apputil.UpdateChartVersionResolvedAnnotation(app, contender.Spec.Environment.Chart.Version)
 		apputil.SetHighestObservedGeneration(app, generation)
@@ -531,14 +654,15 @@ func (c *Controller) processApplication(app *shipper.Application) error {
 		highestObserved = generation
 	}
 
-	if !identicalEnvironments(app.Spec.Template, contender.Spec.Environment) {
+	pinned := pinStrategyRef(pinApplicationDefault(env, defaulted, contender), contender)
+	if !identicalEnvironments(*pinned, contender.Spec.Environment) {
 		// The application's template has been modified and is different than
 		// the contender's environment. This means that a new release should
 		// be created with the new template.
 		highestObserved = highestObserved + 1
-		if releaseName, iteration, err := c.releaseNameForApplication(app); err != nil {
+		if releaseName, iteration, err := c.releaseNameForApplication(app, env); err != nil {
 			return err
-		} else if rel, err := c.createReleaseForApplication(app, releaseName, iteration, highestObserved); err != nil {
+		} else if rel, err := c.createReleaseForApplication(app, env, defaulted, nil, releaseName, iteration, highestObserved); err != nil {
 			releaseSyncedCond := apputil.NewApplicationCondition(
 				shipper.ApplicationConditionTypeReleaseSynced,
 				corev1.ConditionFalse,
@@ -21,10 +21,12 @@ import (
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	"github.com/bookingcom/shipper/pkg/errors"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/shipperconfig"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
 	apputil "github.com/bookingcom/shipper/pkg/util/application"
 	"github.com/bookingcom/shipper/pkg/util/conditions"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 )
 
 const (
@@ -70,6 +72,11 @@ func TestHashReleaseEnv(t *testing.T) {
 		t.Errorf("two identical environments should have hashed to the same value, but they did not: app %q and rel %q", appHash, relHash)
 	}
 
+	rel.Spec.Environment.Chart.Digest = "99c76e403d752c84ead610644d4b1c2f2b453a74b921f422b9dcb8a7c8b559cd"
+	if pinnedHash := hashReleaseEnvironment(rel.Spec.Environment); pinnedHash != appHash {
+		t.Errorf("a pinned chart digest should not change the hash, but it did: app %q and rel %q", appHash, pinnedHash)
+	}
+
 	distinctApp := newApplication(testAppName)
 	distinctApp.Spec.Template.Strategy = &shipper.RolloutStrategy{}
 	distinctHash := hashReleaseEnvironment(distinctApp.Spec.Template)
@@ -119,6 +126,7 @@ func TestCreateFirstRelease(t *testing.T) {
 		},
 	}
 	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 
 	// We do not expect entries in the history or 'RollingOut: true' in the state
 	// because the testing client does not update listers after Create actions.
@@ -181,6 +189,7 @@ func TestCreateFirstReleaseWithChartVersionResolve(t *testing.T) {
 		},
 	}
 	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 
 	// We do not expect entries in the history or 'RollingOut: true' in the state
 	// because the testing client does not update listers after Create actions.
@@ -247,6 +256,7 @@ func TestCreateFirstReleaseWithRolloutBlockOverride(t *testing.T) {
 		},
 	}
 	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 
 	// We do not expect entries in the history or 'RollingOut: true' in the state
 	// because the testing client does not update listers after Create actions.
@@ -307,6 +317,7 @@ func TestCreateFirstReleaseWithNonExistingRolloutBlockOverride(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 
 	f.expectApplicationUpdate(expectedApp)
 
@@ -357,6 +368,7 @@ func TestBlockApplication(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 
 	f.expectApplicationUpdate(expectedApp)
 
@@ -413,6 +425,7 @@ func TestStatusStableState(t *testing.T) {
 		expectedRelNameA,
 		expectedRelNameB,
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseShipped
 	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
 		{
 			Type:   shipper.ApplicationConditionTypeAborting,
@@ -495,6 +508,7 @@ func TestRevisionHistoryLimit(t *testing.T) {
 	// lister.
 
 	//expectedApp.Status.History = []string{"baz"}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseShipped
 	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
 		{
 			Type:   shipper.ApplicationConditionTypeAborting,
@@ -518,6 +532,7 @@ func TestRevisionHistoryLimit(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseShipped
 
 	f.expectReleaseDelete(releaseFoo)
 	f.expectApplicationUpdate(expectedApp)
@@ -581,6 +596,7 @@ func TestCreateThirdRelease(t *testing.T) {
 		incumbentRelName,
 		expectedContenderRelName,
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
 	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
 	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
@@ -663,6 +679,7 @@ func TestCreateSecondRelease(t *testing.T) {
 		incumbentRelName,
 		contenderRelName,
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
 	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
 	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
@@ -768,6 +785,7 @@ func TestCreateSecondReleaseWithUpdatedChartVersionResolve(t *testing.T) {
 		incumbentRelName,
 		contenderRelName,
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
 	expectedApp.Spec.Template.Chart.Version = "0.0.2"
 	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.2")
 
@@ -1008,6 +1026,7 @@ func TestStateRollingOut(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}
+	appRollingOut.Status.Phase = shipper.ApplicationPhasePending
 
 	f.expectApplicationUpdate(appRollingOut)
 
@@ -1071,6 +1090,7 @@ func TestDeletingAbortedReleases(t *testing.T) {
 			Status: corev1.ConditionTrue,
 		},
 	}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseShipped
 
 	f.expectReleaseDelete(releaseFoo)
 	f.expectApplicationUpdate(expectedApp)
@@ -1111,6 +1131,7 @@ func TestHandleChartNotFound(t *testing.T) {
 		},
 	}
 	expectedApp.Status.History = []string{}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseFailed
 
 	f.expectApplicationUpdate(expectedApp)
 
@@ -1121,6 +1142,58 @@ func TestHandleChartNotFound(t *testing.T) {
 	f.run()
 }
 
+func TestApplicationPhase(t *testing.T) {
+	app := newApplication(testAppName)
+
+	tests := []struct {
+		name       string
+		conditions []shipper.ReleaseCondition
+		expected   shipper.ApplicationPhase
+	}{
+		{"not scheduled", nil, shipper.ApplicationPhasePending},
+		{
+			"unschedulable",
+			[]shipper.ReleaseCondition{
+				{Type: shipper.ReleaseConditionTypeClustersChosen, Status: corev1.ConditionFalse},
+			},
+			shipper.ApplicationPhaseFailed,
+		},
+		{
+			"rolling out",
+			[]shipper.ReleaseCondition{
+				{Type: shipper.ReleaseConditionTypeClustersChosen, Status: corev1.ConditionTrue},
+				{Type: shipper.ReleaseConditionTypeStrategyExecuted, Status: corev1.ConditionFalse, Reason: "StrategyExecutionFailed"},
+			},
+			shipper.ApplicationPhaseShipping,
+		},
+		{
+			"hook failed",
+			[]shipper.ReleaseCondition{
+				{Type: shipper.ReleaseConditionTypeClustersChosen, Status: corev1.ConditionTrue},
+				{Type: shipper.ReleaseConditionTypeStrategyExecuted, Status: corev1.ConditionFalse, Reason: "HookFailed"},
+			},
+			shipper.ApplicationPhaseFailed,
+		},
+		{
+			"complete",
+			[]shipper.ReleaseCondition{
+				{Type: shipper.ReleaseConditionTypeClustersChosen, Status: corev1.ConditionTrue},
+				{Type: shipper.ReleaseConditionTypeComplete, Status: corev1.ConditionTrue},
+			},
+			shipper.ApplicationPhaseShipped,
+		},
+	}
+
+	for _, tt := range tests {
+		rel := newRelease("phase", app)
+		rel.Status.Conditions = tt.conditions
+
+		if phase := applicationPhase(rel); phase != tt.expected {
+			t.Errorf("%s: expected phase %q, got %q", tt.name, tt.expected, phase)
+		}
+	}
+}
+
 func newRelease(releaseName string, app *shipper.Application) *shipper.Release {
 	return &shipper.Release{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1218,6 +1291,8 @@ type fixture struct {
 	expectedEvents []string
 
 	resolveChartVersion shipperrepo.ChartVersionResolver
+
+	config *shipperconfig.Store
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -1237,7 +1312,7 @@ func (f *fixture) newController() (*Controller, shipperinformers.SharedInformerF
 	const noResyncPeriod time.Duration = 0
 	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(f.client, noResyncPeriod)
 
-	c := NewController(f.client, shipperInformerFactory, f.resolveChartVersion, f.recorder)
+	c := NewController(f.client, shipperInformerFactory, f.resolveChartVersion, f.recorder, shutdown.DefaultDrainTimeout, f.config)
 
 	return c, shipperInformerFactory
 }
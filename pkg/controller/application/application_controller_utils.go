@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"hash/fnv"
 	"strconv"
+	"strings"
 
 	"k8s.io/klog"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -15,9 +17,10 @@ import (
 	"github.com/bookingcom/shipper/pkg/errors"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
-func (c *Controller) createReleaseForApplication(app *shipper.Application, releaseName string, iteration, generation int) (*shipper.Release, error) {
+func (c *Controller) createReleaseForApplication(app *shipper.Application, env *shipper.ReleaseEnvironment, defaulted []string, adopt *shipper.Adoption, releaseName string, iteration, generation int) (*shipper.Release, error) {
 	// Label releases with their hash; select by that label and increment if needed
 	// appname-hash-of-template-iteration.
 
@@ -30,7 +33,7 @@ func (c *Controller) createReleaseForApplication(app *shipper.Application, relea
 			Labels: map[string]string{
 				shipper.ReleaseLabel:                releaseName,
 				shipper.AppLabel:                    app.Name,
-				shipper.ReleaseEnvironmentHashLabel: hashReleaseEnvironment(app.Spec.Template),
+				shipper.ReleaseEnvironmentHashLabel: hashReleaseEnvironment(*env),
 			},
 			Annotations: map[string]string{
 				shipper.ReleaseTemplateIterationAnnotation: strconv.Itoa(iteration),
@@ -42,21 +45,48 @@ func (c *Controller) createReleaseForApplication(app *shipper.Application, relea
 			},
 		},
 		Spec: shipper.ReleaseSpec{
-			Environment: *(app.Spec.Template.DeepCopy()),
+			Environment: *(env.DeepCopy()),
 		},
 		Status: shipper.ReleaseStatus{},
 	}
 
+	if err := releaseutil.ResolveStrategyPreset(newRelease.Spec.Environment.Strategy); err != nil {
+		return nil, err
+	}
+
+	if adopt != nil {
+		adoptInRelease(newRelease, adopt)
+	}
+
 	for k, v := range app.GetLabels() {
 		newRelease.Labels[k] = v
 	}
 
+	if len(defaulted) > 0 {
+		newRelease.Annotations[shipper.ReleaseDefaultedAnnotation] = strings.Join(defaulted, ",")
+	}
+
+	if commit, ok := app.Annotations[shipper.AppGitCommitAnnotation]; ok {
+		newRelease.Annotations[shipper.ReleaseGitCommitAnnotation] = commit
+	}
+
 	// application may contain semver range, need to convert it into a specific version
 	cv, err := c.versionResolver(&newRelease.Spec.Environment.Chart)
 	if err != nil {
 		return nil, err
 	}
 	newRelease.Spec.Environment.Chart.Version = cv.Version
+	newRelease.Spec.Environment.Chart.Digest = cv.Digest
+
+	additionalCharts := newRelease.Spec.Environment.AdditionalCharts
+	for i := range additionalCharts {
+		cv, err := c.versionResolver(&additionalCharts[i].Chart)
+		if err != nil {
+			return nil, err
+		}
+		additionalCharts[i].Chart.Version = cv.Version
+		additionalCharts[i].Chart.Digest = cv.Digest
+	}
 
 	rel, err := c.shipperClientset.ShipperV1alpha1().Releases(app.Namespace).Create(newRelease)
 	if err != nil {
@@ -67,8 +97,8 @@ func (c *Controller) createReleaseForApplication(app *shipper.Application, relea
 	return rel, nil
 }
 
-func (c *Controller) releaseNameForApplication(app *shipper.Application) (string, int, error) {
-	hash := hashReleaseEnvironment(app.Spec.Template)
+func (c *Controller) releaseNameForApplication(app *shipper.Application, env *shipper.ReleaseEnvironment) (string, int, error) {
+	hash := hashReleaseEnvironment(*env)
 	// TODO(asurikov): move the hash to annotations.
 	selector := labels.Set{
 		shipper.AppLabel:                    app.GetName(),
@@ -127,6 +157,16 @@ func identicalEnvironments(envs ...shipper.ReleaseEnvironment) bool {
 
 func hashReleaseEnvironment(env shipper.ReleaseEnvironment) string {
 	copy := env.DeepCopy()
+	// Releases get the steps of their strategy's preset filled in, so
+	// they must be hashed the same way to be found identical. Unknown
+	// presets are caught when creating the Release.
+	releaseutil.ResolveStrategyPreset(copy.Strategy)
+	// Only releases get their chart's digest pinned, so it must not
+	// count towards the environment being different.
+	copy.Chart.Digest = ""
+	for i := range copy.AdditionalCharts {
+		copy.AdditionalCharts[i].Chart.Digest = ""
+	}
 	b, err := json.Marshal(copy)
 	if err != nil {
 		// TODO(btyler) ???
@@ -150,3 +190,31 @@ func createOwnerRefFromApplication(app *shipper.Application) metav1.OwnerReferen
 		UID:        app.GetUID(),
 	}
 }
+
+// applicationPhase works out the phase of an application from its latest
+// release.
+func applicationPhase(contender *shipper.Release) shipper.ApplicationPhase {
+	if releaseutil.ReleaseComplete(contender) {
+		return shipper.ApplicationPhaseShipped
+	}
+
+	chosen := releaseutil.GetReleaseCondition(contender.Status, shipper.ReleaseConditionTypeClustersChosen)
+	if chosen == nil {
+		return shipper.ApplicationPhasePending
+	} else if chosen.Status == corev1.ConditionFalse {
+		return shipper.ApplicationPhaseFailed
+	}
+
+	// Most strategy execution errors go away on their own, but these
+	// need someone to change the release.
+	executed := releaseutil.GetReleaseCondition(contender.Status, shipper.ReleaseConditionTypeStrategyExecuted)
+	if executed != nil && executed.Status == corev1.ConditionFalse {
+		switch executed.Reason {
+		case shippererrors.StepHookFailedError{}.Reason(),
+			shippererrors.InvalidRolloutStrategyError{}.Reason():
+			return shipper.ApplicationPhaseFailed
+		}
+	}
+
+	return shipper.ApplicationPhaseShipping
+}
@@ -0,0 +1,146 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// The fields of a release environment an ApplicationDefault can fill in, as
+// listed in ReleaseDefaultedAnnotation.
+const (
+	defaultedStrategy            = "strategy"
+	defaultedClusterRequirements = "clusterRequirements"
+)
+
+// applyApplicationDefault returns env with the strategy and cluster
+// requirements it's missing taken from the ApplicationDefault of app's
+// namespace, if there's one, along with the fields it took. Strategies
+// ApplicationDefaults don't have are taken from the ShipperConfig. env
+// itself is never modified, as it can be app's own template.
+func (c *Controller) applyApplicationDefault(app *shipper.Application, env *shipper.ReleaseEnvironment) (*shipper.ReleaseEnvironment, []string, error) {
+	needsStrategy := env.Strategy == nil
+	needsClusterRequirements := len(env.ClusterRequirements.Regions) == 0
+	if !needsStrategy && !needsClusterRequirements {
+		return env, nil, nil
+	}
+
+	var defaults shipper.ApplicationDefaultSpec
+	appDefault, err := c.defaultLister.ApplicationDefaults(app.Namespace).Get(shipper.ApplicationDefaultName)
+	if err == nil {
+		defaults = appDefault.Spec
+	} else if !kerrors.IsNotFound(err) {
+		return nil, nil, shippererrors.NewKubeclientGetError(app.Namespace, shipper.ApplicationDefaultName, err).
+			WithShipperKind("ApplicationDefault")
+	}
+
+	if defaults.Strategy == nil && c.config != nil {
+		defaults.Strategy = c.config.Get().DefaultStrategy
+	}
+
+	if defaults.Strategy == nil && defaults.ClusterRequirements == nil {
+		return env, nil, nil
+	}
+
+	env = env.DeepCopy()
+
+	var defaulted []string
+	if needsStrategy && defaults.Strategy != nil {
+		env.Strategy = defaults.Strategy.DeepCopy()
+		defaulted = append(defaulted, defaultedStrategy)
+	}
+
+	if needsClusterRequirements && defaults.ClusterRequirements != nil {
+		env.ClusterRequirements = *defaults.ClusterRequirements.DeepCopy()
+		defaulted = append(defaulted, defaultedClusterRequirements)
+	}
+
+	return env, defaulted, nil
+}
+
+// pinApplicationDefault returns env with the fields it took from an
+// ApplicationDefault replaced by the ones rel took from one when it was
+// created. Defaults are pinned in the releases they were resolved for, so
+// changing them doesn't roll out a new release of every application using
+// them: they're only picked up by the next one.
+func pinApplicationDefault(env *shipper.ReleaseEnvironment, defaulted []string, rel *shipper.Release) *shipper.ReleaseEnvironment {
+	relDefaulted := releaseDefaulted(rel)
+
+	pinned := env.DeepCopy()
+	for _, field := range defaulted {
+		if !relDefaulted[field] {
+			continue
+		}
+
+		switch field {
+		case defaultedStrategy:
+			pinned.Strategy = rel.Spec.Environment.Strategy.DeepCopy()
+		case defaultedClusterRequirements:
+			pinned.ClusterRequirements = *rel.Spec.Environment.ClusterRequirements.DeepCopy()
+		}
+	}
+
+	return pinned
+}
+
+// unsetApplicationDefault clears the fields of app's template that rel took
+// from an ApplicationDefault, so that an application that had its template
+// copied from rel keeps following its namespace's defaults.
+func unsetApplicationDefault(app *shipper.Application, rel *shipper.Release) {
+	relDefaulted := releaseDefaulted(rel)
+
+	if relDefaulted[defaultedStrategy] {
+		app.Spec.Template.Strategy = nil
+	}
+
+	if relDefaulted[defaultedClusterRequirements] {
+		app.Spec.Template.ClusterRequirements = shipper.ClusterRequirements{}
+	}
+}
+
+func releaseDefaulted(rel *shipper.Release) map[string]bool {
+	defaulted := make(map[string]bool)
+
+	annotation := rel.Annotations[shipper.ReleaseDefaultedAnnotation]
+	if annotation == "" {
+		return defaulted
+	}
+
+	for _, field := range strings.Split(annotation, ",") {
+		defaulted[field] = true
+	}
+
+	return defaulted
+}
+
+// enqueueAppsFromDefault enqueues every application in the namespace of an
+// ApplicationDefault. Their releases keep the defaults they were created
+// with, but applications that are failing to get a release created might
+// need them.
+func (c *Controller) enqueueAppsFromDefault(obj interface{}) {
+	defaults, ok := obj.(*shipper.ApplicationDefault)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.ApplicationDefault: %#v", obj))
+		return
+	}
+
+	if defaults.Name != shipper.ApplicationDefaultName {
+		return
+	}
+
+	apps, err := c.appLister.Applications(defaults.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching applications: %s", err))
+		return
+	}
+
+	for _, app := range apps {
+		c.enqueueApp(app)
+	}
+}
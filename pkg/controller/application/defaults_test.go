@@ -0,0 +1,218 @@
+package application
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	"github.com/bookingcom/shipper/pkg/shipperconfig"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
+)
+
+func newApplicationDefault(strategy *shipper.RolloutStrategy, region string) *shipper.ApplicationDefault {
+	return &shipper.ApplicationDefault{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shipper.ApplicationDefaultName,
+			Namespace: shippertesting.TestNamespace,
+		},
+		Spec: shipper.ApplicationDefaultSpec{
+			Strategy: strategy,
+			ClusterRequirements: &shipper.ClusterRequirements{
+				Regions: []shipper.RegionRequirement{{Name: region}},
+			},
+		},
+	}
+}
+
+// TestCreateFirstReleaseWithApplicationDefault verifies that applications
+// without a strategy or cluster requirements get releases with the ones of
+// their namespace's ApplicationDefault, without them being saved in them.
+func TestCreateFirstReleaseWithApplicationDefault(t *testing.T) {
+	f := newFixture(t)
+	app := newApplication(testAppName)
+	app.Spec.Template.Strategy = nil
+	defaults := newApplicationDefault(&vanguard, "eu-west")
+
+	f.objects = append(f.objects, app, defaults)
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+
+	env := app.Spec.Template.DeepCopy()
+	env.Strategy = defaults.Spec.Strategy
+	env.ClusterRequirements = *defaults.Spec.ClusterRequirements
+
+	envHash := hashReleaseEnvironment(*env)
+	expectedRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
+
+	expectedRelease := newRelease(expectedRelName, expectedApp)
+	expectedRelease.Spec.Environment = *env
+	expectedRelease.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	expectedRelease.Annotations[shipper.ReleaseTemplateIterationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+	expectedRelease.Annotations[shipper.ReleaseDefaultedAnnotation] = "strategy,clusterRequirements"
+
+	f.expectReleaseCreate(expectedRelease)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True %s]`,
+			fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName)),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
+// TestCreateFirstReleaseWithShipperConfigStrategy verifies that
+// applications without a strategy, in namespaces without an
+// ApplicationDefault, get releases with the default strategy of the
+// ShipperConfig.
+func TestCreateFirstReleaseWithShipperConfigStrategy(t *testing.T) {
+	f := newFixture(t)
+	app := newApplication(testAppName)
+	app.Spec.Template.Strategy = nil
+
+	f.objects = append(f.objects, app)
+	f.config = shipperconfig.NewStore(
+		shipperconfig.Config{DefaultStrategy: &vanguard},
+		shipperinformers.NewSharedInformerFactory(shipperfake.NewSimpleClientset(), 0),
+	)
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+
+	env := app.Spec.Template.DeepCopy()
+	env.Strategy = &vanguard
+
+	envHash := hashReleaseEnvironment(*env)
+	expectedRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
+
+	expectedRelease := newRelease(expectedRelName, expectedApp)
+	expectedRelease.Spec.Environment = *env
+	expectedRelease.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	expectedRelease.Annotations[shipper.ReleaseTemplateIterationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+	expectedRelease.Annotations[shipper.ReleaseDefaultedAnnotation] = "strategy"
+
+	f.expectReleaseCreate(expectedRelease)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True %s]`,
+			fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName)),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
+// TestPinApplicationDefault verifies that releases keep the defaults they
+// were created with, so changing an ApplicationDefault doesn't roll out new
+// releases on its own.
+func TestPinApplicationDefault(t *testing.T) {
+	app := newApplication(testAppName)
+	app.Spec.Template.Strategy = nil
+
+	rel := newRelease("test-app-deadbeef-0", app)
+	rel.Spec.Environment.Strategy = vanguard.DeepCopy()
+	rel.Spec.Environment.ClusterRequirements = shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: "eu-west"}},
+	}
+	rel.Annotations[shipper.ReleaseDefaultedAnnotation] = "strategy,clusterRequirements"
+
+	// The ApplicationDefault has since moved to another region and
+	// strategy.
+	env := app.Spec.Template.DeepCopy()
+	env.Strategy = &shipper.RolloutStrategy{Preset: "big-bang"}
+	env.ClusterRequirements = shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: "us-east"}},
+	}
+	defaulted := []string{defaultedStrategy, defaultedClusterRequirements}
+
+	pinned := pinApplicationDefault(env, defaulted, rel)
+	if !identicalEnvironments(*pinned, rel.Spec.Environment) {
+		t.Fatalf("expected environment to be pinned to the defaults of the release, got %#v", pinned)
+	}
+
+	// Applications setting their own strategy still get a new release.
+	env.Strategy = &shipper.RolloutStrategy{Preset: "big-bang"}
+	pinned = pinApplicationDefault(env, []string{defaultedClusterRequirements}, rel)
+	if identicalEnvironments(*pinned, rel.Spec.Environment) {
+		t.Fatalf("expected environment with a strategy of its own not to be pinned to the release's")
+	}
+
+	// Aborting to the release leaves the application following its
+	// namespace's defaults.
+	apputil.CopyEnvironment(app, rel)
+	unsetApplicationDefault(app, rel)
+	if app.Spec.Template.Strategy != nil || len(app.Spec.Template.ClusterRequirements.Regions) != 0 {
+		t.Fatalf("expected the defaults of the release not to be copied into the application, got %#v", app.Spec.Template)
+	}
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+)
+
+// applyStrategyRef returns env with the strategy of the Strategy it refers
+// to copied in, along with the generation it was copied from. env itself is
+// never modified, as it can be app's own template.
+func (c *Controller) applyStrategyRef(app *shipper.Application, env *shipper.ReleaseEnvironment) (*shipper.ReleaseEnvironment, error) {
+	ref := strategyRef(env.Strategy)
+	if ref == nil {
+		return env, nil
+	}
+
+	strategy, err := c.strategyFor(app, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy.Spec.StrategyRef != nil {
+		return nil, shippererrors.NewStrategyRefError(objectutil.MetaKey(app), ref.Name,
+			fmt.Errorf("strategies can not refer to other strategies"))
+	}
+
+	env = env.DeepCopy()
+	env.Strategy = strategy.Spec.DeepCopy()
+	env.Strategy.StrategyRef = &shipper.StrategyRef{
+		Name:       ref.Name,
+		Namespace:  strategy.Namespace,
+		Generation: strategy.Generation,
+	}
+
+	return env, nil
+}
+
+// strategyFor returns the Strategy named name, from app's namespace or else
+// from the global strategy namespace. Whether app is allowed to use the
+// latter is checked when it's made to refer to it.
+func (c *Controller) strategyFor(app *shipper.Application, name string) (*shipper.Strategy, error) {
+	namespaces := []string{app.Namespace}
+	if app.Namespace != shipper.GlobalStrategyNamespace {
+		namespaces = append(namespaces, shipper.GlobalStrategyNamespace)
+	}
+
+	for _, namespace := range namespaces {
+		strategy, err := c.strategyLister.Strategies(namespace).Get(name)
+		if err == nil {
+			return strategy, nil
+		} else if !kerrors.IsNotFound(err) {
+			return nil, shippererrors.NewKubeclientGetError(namespace, name, err).
+				WithShipperKind("Strategy")
+		}
+	}
+
+	return nil, shippererrors.NewStrategyRefError(objectutil.MetaKey(app), name,
+		fmt.Errorf("no such strategy in namespaces %s", strings.Join(namespaces, ", ")))
+}
+
+// pinStrategyRef returns env with the strategy it copied from a Strategy
+// replaced by the one rel copied from the same Strategy when it was created.
+// Like defaults, changes to a Strategy are only picked up by the next
+// release of the applications referring to it, instead of rolling out a new
+// one for each of them. A Strategy of the application's namespace taking
+// over a global one is a change of strategy, though.
+func pinStrategyRef(env *shipper.ReleaseEnvironment, rel *shipper.Release) *shipper.ReleaseEnvironment {
+	ref := strategyRef(env.Strategy)
+	relRef := strategyRef(rel.Spec.Environment.Strategy)
+	if ref == nil || relRef == nil || ref.Name != relRef.Name || ref.Namespace != relRef.Namespace {
+		return env
+	}
+
+	pinned := env.DeepCopy()
+	pinned.Strategy = rel.Spec.Environment.Strategy.DeepCopy()
+
+	return pinned
+}
+
+// unsetStrategySnapshot leaves only the reference to a Strategy in the
+// strategy of app's template, if rel's strategy was copied from one, so that
+// an application that had its template copied from rel keeps referring to
+// it.
+func unsetStrategySnapshot(app *shipper.Application, rel *shipper.Release) {
+	ref := strategyRef(rel.Spec.Environment.Strategy)
+	if ref == nil {
+		return
+	}
+
+	app.Spec.Template.Strategy = &shipper.RolloutStrategy{
+		StrategyRef: &shipper.StrategyRef{Name: ref.Name},
+	}
+}
+
+func strategyRef(strategy *shipper.RolloutStrategy) *shipper.StrategyRef {
+	if strategy == nil {
+		return nil
+	}
+
+	return strategy.StrategyRef
+}
+
+// enqueueAppsFromStrategy enqueues every application in the namespace of a
+// Strategy, or in every namespace for global ones. Their releases keep the
+// strategy they were created with, but applications that are failing to get
+// a release created might need it.
+func (c *Controller) enqueueAppsFromStrategy(obj interface{}) {
+	strategy, ok := obj.(*shipper.Strategy)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.Strategy: %#v", obj))
+		return
+	}
+
+	namespace := strategy.Namespace
+	if namespace == shipper.GlobalStrategyNamespace {
+		namespace = ""
+	}
+
+	apps, err := c.appLister.Applications(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching applications: %s", err))
+		return
+	}
+
+	for _, app := range apps {
+		c.enqueueApp(app)
+	}
+}
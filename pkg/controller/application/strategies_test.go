@@ -0,0 +1,164 @@
+package application
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
+)
+
+func newStrategy(namespace, name string, generation int64, spec shipper.RolloutStrategy) *shipper.Strategy {
+	return &shipper.Strategy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			Generation: generation,
+		},
+		Spec: spec,
+	}
+}
+
+// TestCreateFirstReleaseWithStrategyRef verifies that releases of
+// applications referring to a Strategy, of their namespace or a global one,
+// get a copy of it, along with where it was copied from.
+func TestCreateFirstReleaseWithStrategyRef(t *testing.T) {
+	for _, namespace := range []string{shippertesting.TestNamespace, shipper.GlobalStrategyNamespace} {
+		t.Run(namespace, func(t *testing.T) {
+			testCreateFirstReleaseWithStrategyRef(t, namespace)
+		})
+	}
+}
+
+func testCreateFirstReleaseWithStrategyRef(t *testing.T, namespace string) {
+	f := newFixture(t)
+	app := newApplication(testAppName)
+	app.Spec.Template.Strategy = &shipper.RolloutStrategy{
+		StrategyRef: &shipper.StrategyRef{Name: "slow"},
+	}
+	strategy := newStrategy(namespace, "slow", 3, vanguard)
+
+	f.objects = append(f.objects, app, strategy)
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+
+	env := app.Spec.Template.DeepCopy()
+	env.Strategy = vanguard.DeepCopy()
+	env.Strategy.StrategyRef = &shipper.StrategyRef{Name: "slow", Namespace: namespace, Generation: 3}
+
+	envHash := hashReleaseEnvironment(*env)
+	expectedRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
+
+	expectedRelease := newRelease(expectedRelName, expectedApp)
+	expectedRelease.Spec.Environment = *env
+	expectedRelease.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	expectedRelease.Annotations[shipper.ReleaseTemplateIterationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+
+	f.expectReleaseCreate(expectedRelease)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True %s]`,
+			fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName)),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
+// TestPinStrategyRef verifies that releases keep the copy of the Strategy
+// they were created with, so changing a Strategy doesn't roll out new
+// releases on its own.
+func TestPinStrategyRef(t *testing.T) {
+	app := newApplication(testAppName)
+	app.Spec.Template.Strategy = &shipper.RolloutStrategy{
+		StrategyRef: &shipper.StrategyRef{Name: "slow"},
+	}
+
+	rel := newRelease("test-app-deadbeef-0", app)
+	rel.Spec.Environment.Strategy = vanguard.DeepCopy()
+	rel.Spec.Environment.Strategy.StrategyRef = &shipper.StrategyRef{
+		Name:       "slow",
+		Namespace:  shipper.GlobalStrategyNamespace,
+		Generation: 1,
+	}
+
+	// The Strategy has since been changed.
+	env := app.Spec.Template.DeepCopy()
+	env.Strategy = &shipper.RolloutStrategy{
+		Preset: "big-bang",
+		StrategyRef: &shipper.StrategyRef{
+			Name:       "slow",
+			Namespace:  shipper.GlobalStrategyNamespace,
+			Generation: 2,
+		},
+	}
+
+	pinned := pinStrategyRef(env, rel)
+	if !identicalEnvironments(*pinned, rel.Spec.Environment) {
+		t.Fatalf("expected environment to be pinned to the strategy of the release, got %#v", pinned)
+	}
+
+	// Applications switching to another Strategy still get a new release,
+	// even if it's only one of their namespace with the same name.
+	local := env.DeepCopy()
+	local.Strategy.StrategyRef.Namespace = shippertesting.TestNamespace
+	pinned = pinStrategyRef(local, rel)
+	if identicalEnvironments(*pinned, rel.Spec.Environment) {
+		t.Fatalf("expected environment referring to a strategy of another namespace not to be pinned to the release's")
+	}
+
+	env.Strategy.StrategyRef.Name = "fast"
+	pinned = pinStrategyRef(env, rel)
+	if identicalEnvironments(*pinned, rel.Spec.Environment) {
+		t.Fatalf("expected environment referring to another strategy not to be pinned to the release's")
+	}
+
+	// Aborting to the release leaves the application referring to the
+	// Strategy.
+	apputil.CopyEnvironment(app, rel)
+	unsetStrategySnapshot(app, rel)
+	expected := &shipper.RolloutStrategy{
+		StrategyRef: &shipper.StrategyRef{Name: "slow"},
+	}
+	if !reflect.DeepEqual(app.Spec.Template.Strategy, expected) {
+		t.Fatalf("expected only the reference to the strategy to be copied into the application, got %#v", app.Spec.Template.Strategy)
+	}
+}
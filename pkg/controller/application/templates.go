@@ -0,0 +1,277 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+var templateParameterRegexp = regexp.MustCompile(`\$\(([^()]*)\)`)
+
+// environmentForApplication returns the environment app's releases get: its
+// own template, or the template of the ApplicationTemplate it follows with
+// its own layered over it. Only in the former case is it part of app.
+func (c *Controller) environmentForApplication(app *shipper.Application) (*shipper.ReleaseEnvironment, error) {
+	if app.Spec.TemplateRef == nil {
+		return &app.Spec.Template, nil
+	}
+
+	tmpl, err := c.applicationTemplateFor(app)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := renderApplicationTemplate(tmpl, app)
+	if err != nil {
+		return nil, shippererrors.NewApplicationTemplateError(objectutil.MetaKey(app), objectutil.MetaKey(tmpl), err)
+	}
+
+	return env, nil
+}
+
+// applicationTemplateFor returns the ApplicationTemplate app follows, from
+// app's namespace or else from the global application template namespace.
+func (c *Controller) applicationTemplateFor(app *shipper.Application) (*shipper.ApplicationTemplate, error) {
+	name := app.Spec.TemplateRef.Name
+
+	namespaces := []string{app.Namespace}
+	if app.Namespace != shipper.GlobalApplicationTemplateNamespace {
+		namespaces = append(namespaces, shipper.GlobalApplicationTemplateNamespace)
+	}
+
+	for _, namespace := range namespaces {
+		tmpl, err := c.templateLister.ApplicationTemplates(namespace).Get(name)
+		if err == nil {
+			return tmpl, nil
+		} else if !kerrors.IsNotFound(err) {
+			return nil, shippererrors.NewKubeclientGetError(namespace, name, err).
+				WithShipperKind("ApplicationTemplate")
+		}
+	}
+
+	return nil, shippererrors.NewApplicationTemplateError(objectutil.MetaKey(app), name,
+		fmt.Errorf("no such template in namespaces %s", strings.Join(namespaces, ", ")))
+}
+
+// renderApplicationTemplate substitutes the parameters app sets in tmpl's
+// template, and layers app's own template over the result.
+func renderApplicationTemplate(tmpl *shipper.ApplicationTemplate, app *shipper.Application) (*shipper.ReleaseEnvironment, error) {
+	params, err := templateParameters(tmpl.Spec.Parameters, app.Spec.TemplateRef.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := toJSONValue(tmpl.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err = substituteParameters(base, params)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay, err := toJSONValue(app.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(layer(base, overlay))
+	if err != nil {
+		return nil, err
+	}
+
+	env := &shipper.ReleaseEnvironment{}
+	if err := json.Unmarshal(b, env); err != nil {
+		return nil, fmt.Errorf("rendered environment is invalid: %s", err)
+	}
+
+	// Values are merged like values overlays instead, so applications
+	// can set any value, even an empty one.
+	env.Values = releaseutil.MergeValues(valuesOf(base), app.Spec.Template.Values)
+
+	return env, nil
+}
+
+// templateParameters returns the value of every parameter in params: the
+// one set in values, or its default. It's an error for values to set
+// parameters not in params, or to miss any without a default.
+func templateParameters(params []shipper.ApplicationTemplateParameter, values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(params))
+	for _, p := range params {
+		if v, ok := values[p.Name]; ok {
+			resolved[p.Name] = v
+		} else if p.Default != nil {
+			resolved[p.Name] = *p.Default
+		} else {
+			return nil, fmt.Errorf("parameter %q is required", p.Name)
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("template has no parameters %s", strings.Join(unknown, ", "))
+	}
+
+	return resolved, nil
+}
+
+// substituteParameters replaces every "$(name)" in the strings of v, map
+// keys included, with the value of parameter name.
+func substituteParameters(v interface{}, params map[string]string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return substituteString(v, params)
+	case []interface{}:
+		for i := range v {
+			s, err := substituteParameters(v[i], params)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = s
+		}
+		return v, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			key, err := substituteString(k, params)
+			if err != nil {
+				return nil, err
+			}
+
+			m[key], err = substituteParameters(e, params)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	default:
+		return v, nil
+	}
+}
+
+func substituteString(s string, params map[string]string) (string, error) {
+	var err error
+	s = templateParameterRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		name := templateParameterRegexp.FindStringSubmatch(ref)[1]
+		v, ok := params[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("template refers to unknown parameter %q", name)
+		}
+		return v
+	})
+
+	return s, err
+}
+
+// layer returns overlay layered over base, both decoded from JSON. Objects
+// are layered field by field, and anything else in overlay replaces what's
+// in base. Fields of overlay that are null, empty strings, or empty lists
+// or objects are not set, as the templates of applications following an
+// ApplicationTemplate leave most of their fields out.
+func layer(base, overlay interface{}) interface{} {
+	if unset(overlay) {
+		return base
+	}
+
+	overlayMap, ok := overlay.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+
+	baseMap, ok := base.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+
+	layered := make(map[string]interface{}, len(baseMap))
+	for k, v := range baseMap {
+		layered[k] = v
+	}
+
+	for k, v := range overlayMap {
+		layered[k] = layer(baseMap[k], v)
+	}
+
+	return layered
+}
+
+func unset(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func toJSONValue(env shipper.ReleaseEnvironment) (interface{}, error) {
+	b, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func valuesOf(env interface{}) shipper.ChartValues {
+	m, _ := env.(map[string]interface{})
+	values, _ := m["values"].(map[string]interface{})
+	return shipper.ChartValues(values)
+}
+
+// enqueueAppsFromTemplate enqueues every application following an
+// ApplicationTemplate, so they pick up its changes right away.
+func (c *Controller) enqueueAppsFromTemplate(obj interface{}) {
+	tmpl, ok := obj.(*shipper.ApplicationTemplate)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.ApplicationTemplate: %#v", obj))
+		return
+	}
+
+	namespace := tmpl.Namespace
+	if namespace == shipper.GlobalApplicationTemplateNamespace {
+		namespace = ""
+	}
+
+	apps, err := c.appLister.Applications(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching applications: %s", err))
+		return
+	}
+
+	for _, app := range apps {
+		if ref := app.Spec.TemplateRef; ref != nil && ref.Name == tmpl.Name {
+			c.enqueueApp(app)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package application
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	apputil "github.com/bookingcom/shipper/pkg/util/application"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+const testTemplateName = "test-template"
+
+func newApplicationTemplate(namespace string) *shipper.ApplicationTemplate {
+	team := "platform"
+	return &shipper.ApplicationTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testTemplateName,
+			Namespace: namespace,
+		},
+		Spec: shipper.ApplicationTemplateSpec{
+			Parameters: []shipper.ApplicationTemplateParameter{
+				{Name: "region"},
+				{Name: "team", Default: &team},
+			},
+			Template: shipper.ReleaseEnvironment{
+				Chart: shipper.Chart{
+					Name:    "simple",
+					RepoURL: "https://charts.example.com",
+				},
+				Values: shipper.ChartValues{
+					"owner":    "$(team)",
+					"replicas": float64(2),
+					"image":    map[string]interface{}{"tag": "latest"},
+				},
+				ClusterRequirements: shipper.ClusterRequirements{
+					Regions: []shipper.RegionRequirement{{Name: "$(region)"}},
+				},
+				Strategy: &vanguard,
+			},
+		},
+	}
+}
+
+func newTemplatedApplication(name string) *shipper.Application {
+	app := newApplication(name)
+	app.Spec.Template = shipper.ReleaseEnvironment{
+		Chart: shipper.Chart{Version: "0.0.1"},
+		Values: shipper.ChartValues{
+			"image": map[string]interface{}{"tag": "v1"},
+		},
+	}
+	app.Spec.TemplateRef = &shipper.ApplicationTemplateRef{
+		Name:       testTemplateName,
+		Parameters: map[string]string{"region": "eu-west"},
+	}
+	return app
+}
+
+// TestRenderApplicationTemplate verifies that templates get their parameters
+// substituted, and the application's template layered over them.
+func TestRenderApplicationTemplate(t *testing.T) {
+	tmpl := newApplicationTemplate(shippertesting.TestNamespace)
+	app := newTemplatedApplication(testAppName)
+
+	env, err := renderApplicationTemplate(tmpl, app)
+	if err != nil {
+		t.Fatalf("unexpected error rendering template: %s", err)
+	}
+
+	expected := &shipper.ReleaseEnvironment{
+		Chart: shipper.Chart{
+			Name:    "simple",
+			Version: "0.0.1",
+			RepoURL: "https://charts.example.com",
+		},
+		Values: shipper.ChartValues{
+			"owner":    "platform",
+			"replicas": float64(2),
+			"image":    map[string]interface{}{"tag": "v1"},
+		},
+		ClusterRequirements: shipper.ClusterRequirements{
+			Regions: []shipper.RegionRequirement{{Name: "eu-west"}},
+		},
+		Strategy: &vanguard,
+	}
+
+	if eq, diff := shippertesting.DeepEqualDiff(expected, env); !eq {
+		t.Fatalf("rendered environment differs from expected:\n%s", diff)
+	}
+
+	if tmpl.Spec.Template.ClusterRequirements.Regions[0].Name != "$(region)" {
+		t.Errorf("expected template not to be modified by rendering")
+	}
+}
+
+// TestRenderApplicationTemplateErrors verifies that parameters must match
+// the ones the template has.
+func TestRenderApplicationTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		template func(*shipper.ApplicationTemplate)
+		err      string
+	}{
+		{
+			name:   "missing required parameter",
+			params: map[string]string{},
+			err:    `parameter "region" is required`,
+		},
+		{
+			name:   "unknown parameter",
+			params: map[string]string{"region": "eu-west", "zone": "a", "env": "prod"},
+			err:    "template has no parameters env, zone",
+		},
+		{
+			name:   "undeclared parameter in template",
+			params: map[string]string{"region": "eu-west"},
+			template: func(tmpl *shipper.ApplicationTemplate) {
+				tmpl.Spec.Template.Values["cell"] = "$(cell)"
+			},
+			err: `template refers to unknown parameter "cell"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tmpl := newApplicationTemplate(shippertesting.TestNamespace)
+		if tt.template != nil {
+			tt.template(tmpl)
+		}
+
+		app := newTemplatedApplication(testAppName)
+		app.Spec.TemplateRef.Parameters = tt.params
+
+		_, err := renderApplicationTemplate(tmpl, app)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+// TestCreateFirstReleaseFromTemplate verifies that applications following a
+// global template get releases with its environment, without it being saved
+// in them.
+func TestCreateFirstReleaseFromTemplate(t *testing.T) {
+	f := newFixture(t)
+	tmpl := newApplicationTemplate(shipper.GlobalApplicationTemplateNamespace)
+	app := newTemplatedApplication(testAppName)
+
+	f.objects = append(f.objects, tmpl, app)
+
+	env, err := renderApplicationTemplate(tmpl, app)
+	if err != nil {
+		t.Fatalf("unexpected error rendering template: %s", err)
+	}
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Annotations[shipper.AppHighestObservedGenerationAnnotation] = "0"
+	apputil.UpdateChartNameAnnotation(expectedApp, "simple")
+	apputil.UpdateChartVersionRawAnnotation(expectedApp, "0.0.1")
+	apputil.UpdateChartVersionResolvedAnnotation(expectedApp, "0.0.1")
+
+	envHash := hashReleaseEnvironment(*env)
+	expectedRelName := fmt.Sprintf("%s-%s-0", testAppName, envHash)
+
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:   shipper.ApplicationConditionTypeAborting,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeBlocked,
+			Status: corev1.ConditionFalse,
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeReleaseSynced,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionTrue,
+			Message: fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName),
+		},
+		{
+			Type:   shipper.ApplicationConditionTypeValidHistory,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	expectedApp.Status.History = []string{expectedRelName}
+	expectedApp.Status.Phase = shipper.ApplicationPhasePending
+
+	expectedRelease := newRelease(expectedRelName, expectedApp)
+	expectedRelease.Spec.Environment = *env
+	expectedRelease.Labels[shipper.ReleaseEnvironmentHashLabel] = envHash
+	expectedRelease.Annotations[shipper.ReleaseTemplateIterationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+	expectedRelease.Annotations[shipper.RolloutBlocksOverrideAnnotation] = ""
+
+	f.expectReleaseCreate(expectedRelease)
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [Aborting False], [] -> [ValidHistory True], [] -> [ReleaseSynced True], [] -> [RollingOut True %s]`,
+			fmt.Sprintf(InitialReleaseMessageFormat, expectedRelName)),
+		"Normal ApplicationConditionChanged [] -> [Blocked False]",
+	}
+
+	f.run()
+}
+
+// TestMissingApplicationTemplate verifies that applications following a
+// template that doesn't exist fail without creating releases.
+func TestMissingApplicationTemplate(t *testing.T) {
+	f := newFixture(t)
+	app := newTemplatedApplication(testAppName)
+
+	f.objects = append(f.objects, app)
+
+	msg := fmt.Sprintf(
+		`could not render template %q for application %q: no such template in namespaces %s, %s`,
+		testTemplateName, shippertesting.TestNamespace+"/"+testAppName,
+		shippertesting.TestNamespace, shipper.GlobalApplicationTemplateNamespace)
+
+	expectedApp := app.DeepCopy()
+	expectedApp.Status.Conditions = []shipper.ApplicationCondition{
+		{
+			Type:    shipper.ApplicationConditionTypeRollingOut,
+			Status:  corev1.ConditionFalse,
+			Reason:  conditions.TemplateRenderFailed,
+			Message: msg,
+		},
+	}
+	expectedApp.Status.Phase = shipper.ApplicationPhaseFailed
+
+	f.expectApplicationUpdate(expectedApp)
+
+	f.expectedEvents = []string{
+		fmt.Sprintf(`Normal ApplicationConditionChanged [] -> [RollingOut False TemplateRenderFailed %s]`, msg),
+	}
+
+	f.run()
+}
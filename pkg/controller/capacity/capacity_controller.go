@@ -3,6 +3,7 @@ package capacity
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -10,27 +11,34 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	appslisters "k8s.io/client-go/listers/apps/v1"
-	corelisters "k8s.io/client-go/listers/core/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/client"
 	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
 	"github.com/bookingcom/shipper/pkg/util/filters"
+	"github.com/bookingcom/shipper/pkg/util/index"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 	"github.com/bookingcom/shipper/pkg/util/replicas"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
@@ -39,12 +47,16 @@ const (
 	AgentName   = "capacity-controller"
 	SadPodLimit = 5
 
-	InProgress      = "InProgress"
-	InternalError   = "InternalError"
-	PodsNotReady    = "PodsNotReady"
-	DeploymentStuck = "DeploymentStuck"
+	// staleCacheRequeueDelay is how long to wait before looking at a
+	// Deployment again when the informer cache or the Deployment's status
+	// haven't caught up with our last patch to it.
+	staleCacheRequeueDelay = 2 * time.Second
 
-	CapacityTargetConditionChanged = "CapacityTargetConditionChanged"
+	InProgress        = "InProgress"
+	InternalError     = "InternalError"
+	PodsNotReady      = "PodsNotReady"
+	DeploymentStuck   = "DeploymentStuck"
+	ForeignDeployment = "ForeignDeployment"
 )
 
 // Controller is the controller implementation for CapacityTarget resources
@@ -52,18 +64,64 @@ type Controller struct {
 	shipperClient shipperclient.Interface
 	kubeClient    kubernetes.Interface
 
+	// appsClient patches Deployments through the version of the apps
+	// API group the cluster serves.
+	appsClient client.AppsClient
+
 	capacityTargetsLister listers.CapacityTargetLister
 	capacityTargetsSynced cache.InformerSynced
 
 	deploymentsLister appslisters.DeploymentLister
 	deploymentsSynced cache.InformerSynced
 
-	podsLister corelisters.PodLister
-	podsSynced cache.InformerSynced
+	jobsLister batchlisters.JobLister
+	jobsSynced cache.InformerSynced
+
+	podsIndexer cache.Indexer
+	podsSynced  cache.InformerSynced
 
 	workqueue workqueue.RateLimitingInterface
 
 	recorder record.EventRecorder
+
+	// patchedGenerations holds the generation Deployments had right
+	// after we last patched them, keyed by namespace/name, so we can tell
+	// when the informer cache still holds an older copy.
+	patchedGenerations      map[string]int64
+	patchedGenerationsMutex *sync.Mutex
+
+	convergingSince      map[string]time.Time
+	convergingSinceMutex *sync.Mutex
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+
+	// deprecatedStatusClusterName is the name of the application cluster the
+	// controller runs in. When set, the controller keeps filling in the
+	// deprecated .status.clusters of CapacityTargets with a copy of their status,
+	// so consumers that haven't moved to the top-level status fields yet keep
+	// working. It will be removed, along with .status.clusters, in the next
+	// release.
+	deprecatedStatusClusterName string
+
+	// clusterName labels the convergence metrics of this cluster.
+	clusterName string
+}
+
+// Options are how a CapacityTarget controller scales releases, on top of
+// the clients and informers it works with. They're described on the
+// fields of Controller they end up in.
+type Options struct {
+	DrainTimeout                time.Duration
+	DeprecatedStatusClusterName string
+	ClusterName                 string
+	// LowPriorityMaxWait is how long capacity targets that aren't rolling
+	// out wait for in-flight ones to be synced at most.
+	LowPriorityMaxWait time.Duration
+	// AppsGroupVersion is the version of the apps API group the cluster
+	// serves.
+	AppsGroupVersion schema.GroupVersion
 }
 
 // NewController returns a new CapacityTarget controller.
@@ -73,14 +131,17 @@ func NewController(
 	shipperClient shipperclient.Interface,
 	shipperInformerFactory informers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	opts Options,
 ) *Controller {
 	capacityTargetInformer := shipperInformerFactory.Shipper().V1alpha1().CapacityTargets()
 	deploymentsInformer := kubeInformerFactory.Apps().V1().Deployments()
+	jobsInformer := kubeInformerFactory.Batch().V1().Jobs()
 	podsInformer := kubeInformerFactory.Core().V1().Pods()
 
 	controller := &Controller{
 		shipperClient: shipperClient,
 		kubeClient:    kubeClient,
+		appsClient:    client.NewAppsClient(kubeClient, opts.AppsGroupVersion),
 
 		capacityTargetsLister: capacityTargetInformer.Lister(),
 		capacityTargetsSynced: capacityTargetInformer.Informer().HasSynced,
@@ -88,15 +149,37 @@ func NewController(
 		deploymentsLister: deploymentsInformer.Lister(),
 		deploymentsSynced: deploymentsInformer.Informer().HasSynced,
 
-		podsLister: podsInformer.Lister(),
-		podsSynced: podsInformer.Informer().HasSynced,
+		jobsLister: jobsInformer.Lister(),
+		jobsSynced: jobsInformer.Informer().HasSynced,
 
-		workqueue: workqueue.NewNamedRateLimitingQueue(
-			shipperworkqueue.NewDefaultControllerRateLimiter(),
-			"capacity_controller_capacitytargets",
+		podsIndexer: podsInformer.Informer().GetIndexer(),
+		podsSynced:  podsInformer.Informer().HasSynced,
+
+		workqueue: shipperworkqueue.NewPriorityQueue(
+			shipperworkqueue.NewNamedRateLimitingQueue(
+				shipperworkqueue.NewDefaultControllerRateLimiter(),
+				"capacity_controller_capacitytargets",
+			),
+			capacityTargetInFlight(capacityTargetInformer.Lister()),
+			opts.LowPriorityMaxWait,
 		),
 
 		recorder: recorder,
+
+		patchedGenerations:      make(map[string]int64),
+		patchedGenerationsMutex: &sync.Mutex{},
+		convergingSince:         make(map[string]time.Time),
+		convergingSinceMutex:    &sync.Mutex{},
+
+		drainTimeout: opts.DrainTimeout,
+
+		deprecatedStatusClusterName: opts.DeprecatedStatusClusterName,
+
+		clusterName: opts.ClusterName,
+	}
+
+	if err := index.AddIndexers(podsInformer.Informer()); err != nil {
+		runtime.HandleError(fmt.Errorf("failed to add pod indexers: %s", err))
 	}
 
 	capacityTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -104,19 +187,31 @@ func NewController(
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueCapacityTarget(new)
 		},
+		DeleteFunc: objectutil.OnDelete(controller.enqueueCapacityTarget),
 	})
 
 	deploymentsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: filters.BelongsToRelease,
 		Handler: cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.enqueueCapacityTargetFromDeployment,
-			DeleteFunc: controller.enqueueCapacityTargetFromDeployment,
+			DeleteFunc: objectutil.OnDelete(controller.onDeleteDeployment),
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.enqueueCapacityTargetFromDeployment(newObj)
 			},
 		},
 	})
 
+	jobsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: filters.BelongsToRelease,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    controller.enqueueCapacityTargetFromJob,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueCapacityTargetFromJob),
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				controller.enqueueCapacityTargetFromJob(newObj)
+			},
+		},
+	})
+
 	return controller
 }
 
@@ -135,24 +230,21 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 		stopCh,
 		c.capacityTargetsSynced,
 		c.deploymentsSynced,
+		c.jobsSynced,
 		c.podsSynced,
 	) {
 		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Capacity controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(4).Info("Started Capacity controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -175,11 +267,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "CapacityTarget", key)
 	err := c.capacityTargetSyncHandler(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "CapacityTarget", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing CapacityTarget %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -204,6 +300,7 @@ func (c *Controller) capacityTargetSyncHandler(key string) error {
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			klog.V(3).Infof("CapacityTarget %q has been deleted", key)
+			c.forgetConvergence(key)
 			return nil
 		}
 
@@ -211,9 +308,19 @@ func (c *Controller) capacityTargetSyncHandler(key string) error {
 			WithShipperKind("CapacityTarget")
 	}
 
+	// Visit the target again later even if nothing changes, so its
+	// status shows it is not stale.
+	c.workqueue.AddAfter(key, targetutil.SyncHeartbeatPeriod)
+
 	ct, err := c.processCapacityTarget(initialCT.DeepCopy())
+	if _, inProgress := err.(shippererrors.CapacityInProgressError); err != nil && !inProgress {
+		c.recorder.Event(ct, corev1.EventTypeWarning, shipperevents.CapacityChangeFailed, err.Error())
+	}
+
+	c.observeConvergence(key, initialCT, ct)
 
-	if !reflect.DeepEqual(initialCT, ct) {
+	changed := !reflect.DeepEqual(initialCT, ct)
+	if targetutil.RecordSync(&ct.Status.TargetSyncStatus, changed) {
 		_, err := c.shipperClient.ShipperV1alpha1().CapacityTargets(namespace).
 			UpdateStatus(ct)
 		if err != nil {
@@ -241,6 +348,11 @@ func (c *Controller) processCapacityTarget(ct *shipper.CapacityTarget) (*shipper
 	var (
 		availableReplicas int32
 		sadPods           []shipper.PodStatus
+		workloadStatuses  []shipper.WorkloadCapacityStatus
+
+		// jobCompletedPercent is what a Job achieved, which is how
+		// much of it completed rather than how many pods it runs.
+		jobCompletedPercent *int32
 	)
 
 	defer func() {
@@ -257,18 +369,77 @@ func (c *Controller) processCapacityTarget(ct *shipper.CapacityTarget) (*shipper
 		ct.Status.AvailableReplicas = availableReplicas
 		ct.Status.AchievedPercent = c.calculatePercentageFromAmount(
 			ct.Spec.TotalReplicaCount, availableReplicas)
+		ct.Status.Workloads = workloadStatuses
+		for i, status := range workloadStatuses {
+			if i == 0 || status.AchievedPercent < ct.Status.AchievedPercent {
+				ct.Status.AchievedPercent = status.AchievedPercent
+			}
+		}
+		if jobCompletedPercent != nil {
+			ct.Status.AchievedPercent = *jobCompletedPercent
+		}
+		ct.Status.Conditions = targetutil.SetProgressing(ct.Status.Conditions,
+			fmt.Sprintf("achieved %d%% of %d%% capacity", ct.Status.AchievedPercent, ct.Spec.Percent))
+		if c.deprecatedStatusClusterName != "" {
+			ct.Status.Clusters = deprecatedClusterStatuses(c.deprecatedStatusClusterName, ct.Status)
+		}
 
 		if !diff.IsEmpty() {
-			c.recorder.Event(ct, corev1.EventTypeNormal, CapacityTargetConditionChanged, diff.String())
+			c.recorder.Event(ct, corev1.EventTypeNormal, shipperevents.CapacityTargetConditionChanged, diff.String())
 		}
 	}()
 
-	deployment, pods, err := c.getClusterObjects(ct)
+	// The webhook rejects invalid specs, but they can still be
+	// written straight into an application cluster.
+	if err := targetutil.ValidateCapacityTargetSpec(&ct.Spec, nil); err != nil {
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionFalse,
+			shippererrors.Reason(err),
+			err.Error())
+
+		return ct, err
+	}
+
+	if ct.Spec.WorkloadKind == shipper.WorkloadKindJob {
+		job, err := c.getJob(ct)
+		if err != nil {
+			operationalCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeOperational,
+				corev1.ConditionFalse,
+				InternalError,
+				err.Error())
+
+			return ct, err
+		}
+
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionTrue,
+			"",
+			"")
+
+		// availableReplicas, jobCompletedPercent and readyCond will
+		// be used by the defer at the top of this func
+		progress, completedPercent := c.processJob(ct, job)
+		availableReplicas = progress.availableReplicas
+		jobCompletedPercent = &completedPercent
+		readyCond = progress.readyCond
+
+		return ct, progress.err
+	}
+
+	workloads, err := c.getWorkloads(ct)
 	if err != nil {
+		reason := InternalError
+		if shippererrors.IsForeignDeploymentError(err) {
+			reason = ForeignDeployment
+		}
+
 		operationalCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeOperational,
 			corev1.ConditionFalse,
-			InternalError,
+			reason,
 			err.Error())
 
 		return ct, err
@@ -280,69 +451,197 @@ func (c *Controller) processCapacityTarget(ct *shipper.CapacityTarget) (*shipper
 		"",
 		"")
 
-	// availableReplicas will be used by the defer at the top of this func
-	availableReplicas = deployment.Status.AvailableReplicas
+	if len(ct.Spec.Workloads) == 0 {
+		prevReadyCond := targetutil.GetTargetCondition(ct.Status.Conditions, shipper.TargetConditionTypeReady)
+		progress := c.processWorkload(ct, workloads[0], ct.Status.AvailableReplicas, ct.Status.SadPods, prevReadyCond)
+
+		// availableReplicas, sadPods and readyCond will be used by
+		// the defer at the top of this func
+		availableReplicas = progress.availableReplicas
+		sadPods = progress.sadPods
+		readyCond = progress.readyCond
+
+		return ct, progress.err
+	}
+
+	prevStatuses := make(map[string]shipper.WorkloadCapacityStatus, len(ct.Status.Workloads))
+	for _, status := range ct.Status.Workloads {
+		prevStatuses[status.Name] = status
+	}
+
+	readyCond = targetutil.NewTargetCondition(
+		shipper.TargetConditionTypeReady,
+		corev1.ConditionTrue,
+		"",
+		"")
+	workloadStatuses = make([]shipper.WorkloadCapacityStatus, 0, len(workloads))
+	for _, w := range workloads {
+		prevStatus := prevStatuses[w.name]
+		prevReadyCond := targetutil.GetTargetCondition(prevStatus.Conditions, shipper.TargetConditionTypeReady)
+		progress := c.processWorkload(ct, w, prevStatus.AvailableReplicas, nil, prevReadyCond)
+
+		workloadStatuses = append(workloadStatuses, shipper.WorkloadCapacityStatus{
+			Name:              w.name,
+			AvailableReplicas: progress.availableReplicas,
+			AchievedPercent:   c.calculatePercentageFromAmount(w.totalReplicaCount, progress.availableReplicas),
+			Conditions:        []shipper.TargetCondition{progress.readyCond},
+		})
+
+		availableReplicas += progress.availableReplicas
+		sadPods = append(sadPods, progress.sadPods...)
+
+		// The target takes after the first workload that isn't
+		// ready, and fails with the first error that isn't just
+		// capacity still being in progress.
+		if progress.readyCond.Status != corev1.ConditionTrue && readyCond.Status == corev1.ConditionTrue {
+			readyCond = progress.readyCond
+			readyCond.Message = fmt.Sprintf("deployment %q", w.name)
+			if progress.readyCond.Message != "" {
+				readyCond.Message += ": " + progress.readyCond.Message
+			}
+		}
+
+		if _, inProgress := err.(shippererrors.CapacityInProgressError); err == nil || inProgress {
+			if progress.err != nil {
+				err = progress.err
+			}
+		}
+	}
+
+	if len(sadPods) > SadPodLimit {
+		sadPods = sadPods[:SadPodLimit]
+	}
+
+	return ct, err
+}
+
+// workloadProgress is how far a single Deployment got towards its share of
+// a capacity target.
+type workloadProgress struct {
+	availableReplicas int32
+	sadPods           []shipper.PodStatus
+	readyCond         shipper.TargetCondition
+	err               error
+}
+
+// processWorkload scales w to ct's percentage of its total replica count,
+// and works out how far it got. The previous values are what was published
+// for w before, and are kept if its Deployment is stale in the informer
+// cache.
+func (c *Controller) processWorkload(
+	ct *shipper.CapacityTarget,
+	w workload,
+	prevAvailableReplicas int32,
+	prevSadPods []shipper.PodStatus,
+	prevReadyCond *shipper.TargetCondition,
+) workloadProgress {
+	deployment := w.deployment
+
+	if c.isStaleDeployment(deployment) {
+		// The informer cache or the Deployment controller haven't
+		// seen our last patch yet, so this Deployment's status belongs
+		// to a spec we already changed.
+		// Publishing it would make achievedPercent jump back and
+		// forth, so we keep what we published before and look again
+		// shortly.
+		progress := workloadProgress{
+			availableReplicas: prevAvailableReplicas,
+			sadPods:           prevSadPods,
+			readyCond: targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionUnknown,
+				"",
+				""),
+		}
+		if prevReadyCond != nil {
+			progress.readyCond = *prevReadyCond
+		}
+
+		c.workqueue.AddAfter(objectutil.MetaKey(ct), staleCacheRequeueDelay)
 
-	desiredReplicas := int32(replicas.CalculateDesiredReplicaCount(uint(ct.Spec.TotalReplicaCount), float64(ct.Spec.Percent)))
+		return progress
+	}
+
+	progress := workloadProgress{
+		availableReplicas: deployment.Status.AvailableReplicas,
+	}
+
+	desiredReplicas := int32(replicas.CalculateDesiredReplicaCount(uint(w.totalReplicaCount), float64(ct.Spec.Percent)))
 	if deployment.Spec.Replicas == nil || desiredReplicas != *deployment.Spec.Replicas {
-		_, err = c.patchDeploymentWithReplicaCount(deployment, desiredReplicas)
+		var err error
+		if ct.Spec.ScaleDownPolicy == shipper.ScaleDownPolicyUnhealthyFirst &&
+			deployment.Spec.Replicas != nil && desiredReplicas < *deployment.Spec.Replicas {
+			// The pods have to be marked before the ReplicaSet
+			// picks which ones to delete.
+			err = c.markPodsForScaleDown(w.pods, int(*deployment.Spec.Replicas-desiredReplicas))
+		}
+
+		var patchedDeployment *appsv1.Deployment
+		if err == nil {
+			patchedDeployment, err = c.patchDeploymentWithReplicaCount(deployment, desiredReplicas)
+		}
 		if err != nil {
-			readyCond = targetutil.NewTargetCondition(
+			progress.readyCond = targetutil.NewTargetCondition(
 				shipper.TargetConditionTypeReady,
 				corev1.ConditionFalse,
 				InternalError,
 				err.Error(),
 			)
+			progress.err = err
 
-			return ct, err
-		} else {
-			readyCond = targetutil.NewTargetCondition(
-				shipper.TargetConditionTypeReady,
-				corev1.ConditionFalse,
-				InProgress,
-				"",
-			)
-
-			return ct, shippererrors.NewCapacityInProgressError(ct.Name)
+			return progress
 		}
+
+		c.setPatchedGeneration(patchedDeployment)
+
+		progress.readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			InProgress,
+			"",
+		)
+		progress.err = shippererrors.NewCapacityInProgressError(ct.Name)
+
+		return progress
 	}
 
 	// Deployment was successfully updated, but the update hasn't been
 	// observed by the deployment controller yet, so our change is still in
 	// flight, and we can't trust the status yet.
 	if deployment.Generation > deployment.Status.ObservedGeneration {
-		readyCond = targetutil.NewTargetCondition(
+		progress.readyCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeReady,
 			corev1.ConditionFalse,
 			InProgress,
 			"",
 		)
+		progress.err = shippererrors.NewCapacityInProgressError(ct.Name)
 
-		return ct, shippererrors.NewCapacityInProgressError(ct.Name)
+		return progress
 	}
 
 	// If the number of available replicas matches what we want, the
-	// CapacityTarget is Ready and there's nothing left to check.
-	if replicas.AchievedDesiredReplicaPercentage(ct.Spec.TotalReplicaCount, availableReplicas, ct.Spec.Percent) {
-		readyCond = targetutil.NewTargetCondition(
+	// workload is Ready and there's nothing left to check.
+	if replicas.AchievedDesiredReplicaPercentage(w.totalReplicaCount, progress.availableReplicas, ct.Spec.Percent) {
+		progress.readyCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeReady,
 			corev1.ConditionTrue,
 			"",
 			"",
 		)
 
-		return ct, nil
+		return progress
 	}
 
-	// Not all pods are availble, so we know for sure this cluster isn't
+	// Not all pods are availble, so we know for sure this workload isn't
 	// ready. From here on out we just try to figure out why to give users
 	// a good place to start looking.
 
-	// sadPods will be used by the defer at the top of this func
-	sadPods = c.getSadPods(pods)
+	sadPods := c.getSadPods(w.pods)
 	if len(sadPods) > SadPodLimit {
 		sadPods = sadPods[:SadPodLimit]
 	}
+	progress.sadPods = sadPods
 
 	replicaFailureCond := getDeploymentCondition(deployment.Status, appsv1.DeploymentReplicaFailure)
 	progressingCond := getDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
@@ -378,7 +677,7 @@ func (c *Controller) processCapacityTarget(ct *shipper.CapacityTarget) (*shipper
 		reason = InProgress
 	}
 
-	readyCond = targetutil.NewTargetCondition(
+	progress.readyCond = targetutil.NewTargetCondition(
 		shipper.TargetConditionTypeReady,
 		corev1.ConditionFalse,
 		reason,
@@ -386,10 +685,10 @@ func (c *Controller) processCapacityTarget(ct *shipper.CapacityTarget) (*shipper
 	)
 
 	if reason == InProgress {
-		return ct, shippererrors.NewCapacityInProgressError(ct.Name)
+		progress.err = shippererrors.NewCapacityInProgressError(ct.Name)
 	}
 
-	return ct, nil
+	return progress
 }
 
 func (c *Controller) enqueueCapacityTarget(obj interface{}) {
@@ -402,15 +701,22 @@ func (c *Controller) enqueueCapacityTarget(obj interface{}) {
 	c.workqueue.Add(key)
 }
 
-func (c Controller) getClusterObjects(ct *shipper.CapacityTarget) (*appsv1.Deployment, []*corev1.Pod, error) {
-	appName, err := objectutil.GetApplicationLabel(ct)
-	if err != nil {
-		return nil, nil, err
-	}
+// workload is a Deployment scaled by a capacity target, along with its
+// pods.
+type workload struct {
+	name              string
+	totalReplicaCount int32
+	deployment        *appsv1.Deployment
+	pods              []*corev1.Pod
+}
 
-	releaseName, err := objectutil.GetReleaseLabel(ct)
+// getWorkloads returns the Deployments of ct's release that it scales: the
+// ones listed in its workloads, or the release's only Deployment when there
+// are none.
+func (c Controller) getWorkloads(ct *shipper.CapacityTarget) ([]workload, error) {
+	appName, releaseName, err := capacityTargetRelease(ct)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	deploymentSelector := labels.Set{
@@ -421,31 +727,137 @@ func (c Controller) getClusterObjects(ct *shipper.CapacityTarget) (*appsv1.Deplo
 	deployments, err := c.deploymentsLister.
 		Deployments(ct.Namespace).List(deploymentSelector)
 	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
+		return nil, shippererrors.NewKubeclientListError(
 			deploymentGVK, ct.Namespace, deploymentSelector, err)
 	}
 
-	if l := len(deployments); l != 1 {
-		return nil, nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
-			deploymentSelector, deploymentGVK, 1, l)
+	// Anyone can label a Deployment after a release, but only the ones
+	// Shipper installed for it are ever scaled.
+	for _, deployment := range deployments {
+		if !installedForRelease(deployment, releaseName) {
+			return nil, shippererrors.NewForeignDeploymentError(
+				objectutil.MetaKey(deployment), releaseName)
+		}
+	}
+
+	expected := len(ct.Spec.Workloads)
+	if expected == 0 {
+		expected = 1
+	}
+
+	if l := len(deployments); l != expected {
+		return nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
+			deploymentSelector, deploymentGVK, expected, l)
+	}
+
+	if len(ct.Spec.Workloads) == 0 {
+		w, err := c.buildWorkload(deployments[0], ct.Spec.TotalReplicaCount, releaseName)
+		if err != nil {
+			return nil, err
+		}
+
+		return []workload{w}, nil
+	}
+
+	deploymentsByName := make(map[string]*appsv1.Deployment, len(deployments))
+	for _, deployment := range deployments {
+		deploymentsByName[deployment.Name] = deployment
+	}
+
+	workloads := make([]workload, 0, len(ct.Spec.Workloads))
+	for _, spec := range ct.Spec.Workloads {
+		deployment, ok := deploymentsByName[spec.Name]
+		if !ok {
+			return nil, shippererrors.NewKubeclientGetError(ct.Namespace, spec.Name,
+				kerrors.NewNotFound(appsv1.Resource("deployments"), spec.Name)).
+				WithKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		}
+
+		w, err := c.buildWorkload(deployment, spec.TotalReplicaCount, releaseName)
+		if err != nil {
+			return nil, err
+		}
+
+		workloads = append(workloads, w)
+	}
+
+	return workloads, nil
+}
+
+// capacityTargetRelease returns the application and release names whose
+// Deployments ct scales, falling back to its labels for targets created
+// before they were part of the spec.
+func capacityTargetRelease(ct *shipper.CapacityTarget) (string, string, error) {
+	if ct.Spec.Application != "" && ct.Spec.Release != "" {
+		return ct.Spec.Application, ct.Spec.Release, nil
 	}
 
-	deployment := deployments[0]
+	appName, err := objectutil.GetApplicationLabel(ct)
+	if err != nil {
+		return "", "", err
+	}
 
+	releaseName, err := objectutil.GetReleaseLabel(ct)
+	if err != nil {
+		return "", "", err
+	}
+
+	return appName, releaseName, nil
+}
+
+func (c Controller) buildWorkload(deployment *appsv1.Deployment, totalReplicaCount int32, releaseName string) (workload, error) {
 	podSelector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
 	if err != nil {
-		return nil, nil, shippererrors.NewUnrecoverableError(fmt.Errorf("failed to transform label selector %v into a selector: %s", deployment.Spec.Selector, err))
+		return workload{}, shippererrors.NewUnrecoverableError(fmt.Errorf("failed to transform label selector %v into a selector: %s", deployment.Spec.Selector, err))
 	}
 
-	pods, err := c.podsLister.
-		Pods(deployment.Namespace).List(podSelector)
+	pods, err := index.PodsForRelease(c.podsIndexer, deployment.Namespace, releaseName, podSelector)
 	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientListError(
+		return workload{}, shippererrors.NewKubeclientListError(
 			corev1.SchemeGroupVersion.WithKind("Pod"),
 			deployment.Namespace, podSelector, err)
 	}
 
-	return deployment, pods, nil
+	return workload{
+		name:              deployment.Name,
+		totalReplicaCount: totalReplicaCount,
+		deployment:        deployment,
+		pods:              pods,
+	}, nil
+}
+
+// isStaleDeployment returns whether a Deployment from the informer cache is
+// older than the one we got back when we last patched it, or whether its
+// status is, as the Deployment controller hasn't observed the patch yet.
+// Generations only grow, so unlike resource versions they can be safely
+// compared.
+func (c *Controller) isStaleDeployment(deployment *appsv1.Deployment) bool {
+	key := objectutil.MetaKey(deployment)
+
+	c.patchedGenerationsMutex.Lock()
+	defer c.patchedGenerationsMutex.Unlock()
+
+	generation, ok := c.patchedGenerations[key]
+	if !ok {
+		return false
+	}
+
+	if deployment.Generation < generation || deployment.Status.ObservedGeneration < generation {
+		return true
+	}
+
+	// Both the cache and the Deployment's status have caught up, so
+	// there is nothing left to wait for.
+	delete(c.patchedGenerations, key)
+
+	return false
+}
+
+func (c *Controller) setPatchedGeneration(deployment *appsv1.Deployment) {
+	c.patchedGenerationsMutex.Lock()
+	defer c.patchedGenerationsMutex.Unlock()
+
+	c.patchedGenerations[objectutil.MetaKey(deployment)] = deployment.Generation
 }
 
 func (c *Controller) patchDeploymentWithReplicaCount(
@@ -454,9 +866,8 @@ func (c *Controller) patchDeploymentWithReplicaCount(
 ) (*appsv1.Deployment, error) {
 	patch := []byte(fmt.Sprintf(`{"spec": {"replicas": %d}}`, replicaCount))
 
-	updatedDeployment, err := c.kubeClient.AppsV1().
-		Deployments(deployment.Namespace).
-		Patch(deployment.Name, types.StrategicMergePatchType, patch)
+	updatedDeployment, err := c.appsClient.PatchDeployment(
+		deployment.Namespace, deployment.Name, types.StrategicMergePatchType, patch)
 	if err != nil {
 		return nil, shippererrors.NewKubeclientUpdateError(deployment, err)
 	}
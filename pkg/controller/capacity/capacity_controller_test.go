@@ -6,12 +6,18 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubetesting "k8s.io/client-go/testing"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 const (
@@ -41,6 +47,157 @@ func TestSuccess(t *testing.T) {
 		ct,
 		buildSuccessStatus(ct.Spec),
 		expectedReplicaCount,
+		"",
+	)
+}
+
+// TestSelectsDeploymentByReleaseInSpec verifies that the capacity controller
+// scales the Deployment of the release named in a CapacityTarget's spec, even
+// if the target's own labels point at a different one.
+func TestSelectsDeploymentByReleaseInSpec(t *testing.T) {
+	decoyName := ctName + "-copy"
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Application:       shippertesting.TestApp,
+		Release:           ctName,
+		Percent:           50,
+		TotalReplicaCount: 10,
+	})
+	ct.Labels[shipper.ReleaseLabel] = decoyName
+
+	runCapacityControllerTest(t,
+		[]runtime.Object{
+			buildDeployment(shippertesting.TestApp, ctName, 0, 5),
+			buildDeployment(shippertesting.TestApp, decoyName, 0, 0),
+		},
+		ct,
+		buildSuccessStatus(ct.Spec),
+		5,
+		"",
+	)
+}
+
+// TestMultipleWorkloads verifies that the capacity controller scales each
+// Deployment listed in a CapacityTarget's workloads on its own, and reports
+// the target as ready only when all of them are.
+func TestMultipleWorkloads(t *testing.T) {
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           50,
+		TotalReplicaCount: 14,
+		Workloads: []shipper.CapacityTargetWorkload{
+			{Name: "foobar-web", TotalReplicaCount: 10},
+			{Name: "foobar-worker", TotalReplicaCount: 4},
+		},
+	})
+
+	web := buildDeployment(shippertesting.TestApp, ctName, 5, 5)
+	web.Name = "foobar-web"
+	worker := buildDeployment(shippertesting.TestApp, ctName, 0, 0)
+	worker.Name = "foobar-worker"
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(web)
+	f.KubeClient.Tracker().Add(worker)
+	f.ShipperClient.Tracker().Add(ct)
+
+	runController(f, "")
+
+	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+	object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+	if err != nil {
+		t.Fatalf("could not Get CapacityTarget: %s", err)
+	}
+
+	inProgress := shipper.TargetCondition{
+		Type:   shipper.TargetConditionTypeReady,
+		Status: corev1.ConditionFalse,
+		Reason: InProgress,
+	}
+	expected := shipper.CapacityTargetStatus{
+		AvailableReplicas: 5,
+		AchievedPercent:   0,
+		Conditions: []shipper.TargetCondition{
+			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 0, 50),
+			{
+				Type:    shipper.TargetConditionTypeReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  InProgress,
+				Message: `deployment "foobar-worker"`,
+			},
+		},
+		Workloads: []shipper.WorkloadCapacityStatus{
+			{
+				Name:              "foobar-web",
+				AvailableReplicas: 5,
+				AchievedPercent:   50,
+				Conditions:        []shipper.TargetCondition{TargetConditionReady},
+			},
+			{
+				Name:       "foobar-worker",
+				Conditions: []shipper.TargetCondition{inProgress},
+			},
+		},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, object.(*shipper.CapacityTarget).Status)
+	if !eq {
+		t.Fatalf("CapacityTarget has Status different from expected:\n%s", diff)
+	}
+
+	deploymentGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+	for name, replicas := range map[string]int32{"foobar-web": 5, "foobar-worker": 2} {
+		object, err := f.KubeClient.Tracker().Get(deploymentGVR, ct.Namespace, name)
+		if err != nil {
+			t.Fatalf("could not Get Deployment %q: %s", name, err)
+		}
+
+		if actual := *object.(*appsv1.Deployment).Spec.Replicas; actual != replicas {
+			t.Errorf("expected Deployment %q to have %d replicas, got %d", name, replicas, actual)
+		}
+	}
+}
+
+// TestDeprecatedClusterStatus verifies that the capacity controller keeps
+// filling in the deprecated .status.clusters when it knows the name of its
+// cluster.
+func TestDeprecatedClusterStatus(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: totalReplicaCount,
+	})
+
+	status := buildSuccessStatus(ct.Spec)
+	status.Clusters = []shipper.ClusterCapacityStatus{
+		{
+			Name:              "test-cluster",
+			AvailableReplicas: status.AvailableReplicas,
+			AchievedPercent:   status.AchievedPercent,
+			Conditions: []shipper.ClusterCapacityCondition{
+				{
+					Type:   shipper.ClusterConditionType(shipper.TargetConditionTypeOperational),
+					Status: corev1.ConditionTrue,
+				},
+				{
+					Type:    shipper.ClusterConditionType(shipper.TargetConditionTypeProgressing),
+					Status:  corev1.ConditionFalse,
+					Reason:  targetutil.ProgressingReasonConverged,
+					Message: "achieved 100% of 100% capacity",
+				},
+				{
+					Type:   shipper.ClusterConditionType(shipper.TargetConditionTypeReady),
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+
+	runCapacityControllerTest(t,
+		[]runtime.Object{buildDeployment(shippertesting.TestApp, ctName, 0, totalReplicaCount)},
+		ct,
+		status,
+		totalReplicaCount,
+		"test-cluster",
 	)
 }
 
@@ -61,6 +218,7 @@ func TestCapacityShiftingPodsNotSadButNotAvailable(t *testing.T) {
 		AvailableReplicas: availableReplicaCount,
 		Conditions: []shipper.TargetCondition{
 			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 50, 100),
 			{
 				Type:   shipper.TargetConditionTypeReady,
 				Status: corev1.ConditionFalse,
@@ -74,6 +232,7 @@ func TestCapacityShiftingPodsNotSadButNotAvailable(t *testing.T) {
 		ct,
 		status,
 		totalReplicaCount,
+		"",
 	)
 }
 
@@ -104,6 +263,7 @@ func TestCapacityShiftingSadPods(t *testing.T) {
 		},
 		Conditions: []shipper.TargetCondition{
 			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 50, 100),
 			{
 				Type:    shipper.TargetConditionTypeReady,
 				Status:  corev1.ConditionFalse,
@@ -118,15 +278,464 @@ func TestCapacityShiftingSadPods(t *testing.T) {
 		ct,
 		status,
 		totalReplicaCount,
+		"",
 	)
 }
 
+// TestScaleDownUnhealthyFirst verifies that the capacity controller marks
+// the pods that aren't ready, and then the oldest ones, for deletion before
+// scaling a Deployment down, and unmarks pods it marked before that should
+// now stay.
+func TestScaleDownUnhealthyFirst(t *testing.T) {
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           50,
+		TotalReplicaCount: 4,
+		ScaleDownPolicy:   shipper.ScaleDownPolicyUnhealthyFirst,
+	})
+
+	deployment := buildDeployment(shippertesting.TestApp, ctName, 4, 4)
+	now := time.Now()
+	buildPod := func(name string, age time.Duration, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         deployment.Namespace,
+				Name:              name,
+				Labels:            deployment.Spec.Selector.MatchLabels,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Annotations:       map[string]string{},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	pods := []*corev1.Pod{
+		buildPod("oldest", 3*time.Hour, corev1.ConditionTrue),
+		buildPod("old", 2*time.Hour, corev1.ConditionTrue),
+		buildPod("new", time.Hour, corev1.ConditionTrue),
+		buildPod("sick", time.Minute, corev1.ConditionFalse),
+	}
+	pods[1].Annotations[podDeletionCostAnnotation] = scaleDownDeletionCost
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(deployment)
+	for _, pod := range pods {
+		f.KubeClient.Tracker().Add(pod)
+	}
+	f.ShipperClient.Tracker().Add(ct)
+
+	runController(f, "")
+
+	// The fake clientset can't remove annotations through patches, so
+	// we look at the patches themselves.
+	patches := map[string]string{}
+	for _, action := range f.KubeClient.Actions() {
+		if patch, ok := action.(kubetesting.PatchAction); ok && action.GetResource().Resource == "pods" {
+			patches[patch.GetName()] = string(patch.GetPatch())
+		}
+	}
+
+	markPatch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, podDeletionCostAnnotation, scaleDownDeletionCost)
+	unmarkPatch := fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, podDeletionCostAnnotation)
+	expected := map[string]string{"oldest": markPatch, "old": unmarkPatch, "sick": markPatch}
+	eq, diff := shippertesting.DeepEqualDiff(expected, patches)
+	if !eq {
+		t.Fatalf("Pods were patched differently from expected:\n%s", diff)
+	}
+
+	deploymentGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+	object, err := f.KubeClient.Tracker().Get(deploymentGVR, deployment.Namespace, deployment.Name)
+	if err != nil {
+		t.Fatalf("could not Get Deployment: %s", err)
+	}
+
+	if replicas := *object.(*appsv1.Deployment).Spec.Replicas; replicas != 2 {
+		t.Fatalf("expected Deployment to be scaled down to 2 replicas, got %d", replicas)
+	}
+}
+
+// TestJobWorkload verifies that the capacity controller scales the
+// parallelism of a release's Job, leaving its step hooks alone, and only
+// reports all of its capacity as achieved once it completes.
+func TestJobWorkload(t *testing.T) {
+	tests := []struct {
+		name             string
+		percent          int32
+		parallelism      int32
+		status           batchv1.JobStatus
+		readyStatus      corev1.ConditionStatus
+		readyReason      string
+		achievedPercent  int32
+		finalParallelism int32
+	}{
+		{
+			name:             "parallelism patched",
+			percent:          50,
+			parallelism:      0,
+			readyStatus:      corev1.ConditionFalse,
+			readyReason:      InProgress,
+			finalParallelism: 2,
+		},
+		{
+			name:             "partial capacity running",
+			percent:          50,
+			parallelism:      2,
+			status:           batchv1.JobStatus{Active: 2, Succeeded: 2},
+			readyStatus:      corev1.ConditionTrue,
+			achievedPercent:  25,
+			finalParallelism: 2,
+		},
+		{
+			name:             "full capacity still running",
+			percent:          100,
+			parallelism:      4,
+			status:           batchv1.JobStatus{Active: 4, Succeeded: 4},
+			readyStatus:      corev1.ConditionFalse,
+			readyReason:      InProgress,
+			achievedPercent:  50,
+			finalParallelism: 4,
+		},
+		{
+			name:        "complete",
+			percent:     100,
+			parallelism: 4,
+			status: batchv1.JobStatus{
+				Succeeded:  8,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+			readyStatus:      corev1.ConditionTrue,
+			achievedPercent:  100,
+			finalParallelism: 4,
+		},
+		{
+			name:        "failed",
+			percent:     100,
+			parallelism: 4,
+			status: batchv1.JobStatus{
+				Failed:     6,
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+			},
+			readyStatus:      corev1.ConditionFalse,
+			readyReason:      JobFailed,
+			finalParallelism: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+			Percent:           tt.percent,
+			TotalReplicaCount: 4,
+			WorkloadKind:      shipper.WorkloadKindJob,
+		})
+
+		job := buildJob(shippertesting.TestApp, ctName, tt.parallelism, 8)
+		job.Status = tt.status
+		hook := buildJob(shippertesting.TestApp, ctName, 1, 1)
+		hook.Name = ctName + "-0-migrate"
+		hook.Labels[shipper.StepHookLabel] = "migrate"
+
+		f := shippertesting.NewControllerTestFixture()
+		f.KubeClient.Tracker().Add(job)
+		f.KubeClient.Tracker().Add(hook)
+		f.ShipperClient.Tracker().Add(ct)
+
+		runController(f, "")
+
+		ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+		object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+		if err != nil {
+			t.Fatalf("%s: could not Get CapacityTarget: %s", tt.name, err)
+		}
+
+		status := object.(*shipper.CapacityTarget).Status
+		readyCond := targetutil.GetTargetCondition(status.Conditions, shipper.TargetConditionTypeReady)
+		if readyCond == nil || readyCond.Status != tt.readyStatus || readyCond.Reason != tt.readyReason {
+			t.Errorf("%s: expected Ready condition to be %s with reason %q, got %+v",
+				tt.name, tt.readyStatus, tt.readyReason, readyCond)
+		}
+
+		if status.AchievedPercent != tt.achievedPercent {
+			t.Errorf("%s: expected achievedPercent %d, got %d", tt.name, tt.achievedPercent, status.AchievedPercent)
+		}
+
+		jobGVR := batchv1.SchemeGroupVersion.WithResource("jobs")
+		object, err = f.KubeClient.Tracker().Get(jobGVR, job.Namespace, job.Name)
+		if err != nil {
+			t.Fatalf("%s: could not Get Job: %s", tt.name, err)
+		}
+
+		if parallelism := *object.(*batchv1.Job).Spec.Parallelism; parallelism != tt.finalParallelism {
+			t.Errorf("%s: expected Job to have a parallelism of %d, got %d", tt.name, tt.finalParallelism, parallelism)
+		}
+	}
+}
+
+// TestDeploymentPatchFailure verifies that the capacity controller reports
+// failures to scale the Deployment in the Ready condition.
+func TestDeploymentPatchFailure(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: totalReplicaCount,
+	})
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(buildDeployment(shippertesting.TestApp, ctName, 0, 0))
+	f.ShipperClient.Tracker().Add(ct)
+	f.InjectError("patch", "deployments", fmt.Errorf("etcdserver: request timed out"))
+
+	runController(f, "")
+
+	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+	object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+	if err != nil {
+		t.Fatalf("could not Get CapacityTarget: %s", err)
+	}
+
+	readyCond := targetutil.GetTargetCondition(
+		object.(*shipper.CapacityTarget).Status.Conditions,
+		shipper.TargetConditionTypeReady)
+	if readyCond == nil || readyCond.Status != corev1.ConditionFalse || readyCond.Reason != InternalError {
+		t.Fatalf("expected Ready condition to be False with reason %q, got %+v", InternalError, readyCond)
+	}
+}
+
+// TestForeignDeployment verifies that the capacity controller doesn't scale
+// a Deployment with the labels of a release that Shipper didn't install for
+// it, and reports why.
+func TestForeignDeployment(t *testing.T) {
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: 10,
+	})
+
+	deployment := buildDeployment(shippertesting.TestApp, ctName, 3, 3)
+	deployment.OwnerReferences = nil
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(deployment)
+	f.ShipperClient.Tracker().Add(ct)
+
+	runController(f, "")
+
+	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
+	object, err := f.ShipperClient.Tracker().Get(ctGVR, ct.Namespace, ct.Name)
+	if err != nil {
+		t.Fatalf("could not Get CapacityTarget: %s", err)
+	}
+
+	operationalCond := targetutil.GetTargetCondition(
+		object.(*shipper.CapacityTarget).Status.Conditions,
+		shipper.TargetConditionTypeOperational)
+	if operationalCond == nil || operationalCond.Status != corev1.ConditionFalse || operationalCond.Reason != ForeignDeployment {
+		t.Fatalf("expected Operational condition to be False with reason %q, got %+v", ForeignDeployment, operationalCond)
+	}
+
+	deploymentGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+	object, err = f.KubeClient.Tracker().Get(deploymentGVR, deployment.Namespace, deployment.Name)
+	if err != nil {
+		t.Fatalf("could not Get Deployment: %s", err)
+	}
+	if replicas := *object.(*appsv1.Deployment).Spec.Replicas; replicas != 3 {
+		t.Fatalf("expected foreign Deployment to be left at 3 replicas, got %d", replicas)
+	}
+}
+
+// TestStaleDeploymentCache verifies that the capacity controller doesn't
+// publish the status of a Deployment from the informer cache that is older
+// than its last patch to it.
+func TestStaleDeploymentCache(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: totalReplicaCount,
+	})
+	ct.Status = buildSuccessStatus(ct.Spec)
+
+	// The cached Deployment still reports the replicas from before it
+	// was scaled down.
+	deployment := buildDeployment(shippertesting.TestApp, ctName, totalReplicaCount, 0)
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(deployment)
+	controller := NewController(
+		f.KubeClient,
+		f.KubeInformerFactory,
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		f.Recorder,
+		Options{
+			DrainTimeout:       shutdown.DefaultDrainTimeout,
+			LowPriorityMaxWait: shipperworkqueue.DefaultLowPriorityMaxWait,
+			AppsGroupVersion:   appsv1.SchemeGroupVersion,
+		},
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Run(stopCh)
+
+	controller.setPatchedGeneration(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  deployment.Namespace,
+			Name:       deployment.Name,
+			Generation: deployment.Generation + 1,
+		},
+	})
+
+	processedCT, err := controller.processCapacityTarget(ct.DeepCopy())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(ct.Status, processedCT.Status)
+	if !eq {
+		t.Fatalf("expected status of stale Deployment not to be published:\n%s", diff)
+	}
+
+	if !controller.isStaleDeployment(deployment) {
+		t.Fatalf("expected Deployment to be stale until the cache catches up")
+	}
+
+	deployment.Generation++
+	deployment.Status.ObservedGeneration = deployment.Generation
+	if controller.isStaleDeployment(deployment) {
+		t.Fatalf("expected Deployment not to be stale once the cache catches up")
+	}
+}
+
+// TestUnobservedDeploymentStatus verifies that the capacity controller
+// doesn't publish the status of a Deployment the Deployment controller
+// hasn't caught up with since its last patch, even when the informer cache
+// already has.
+func TestUnobservedDeploymentStatus(t *testing.T) {
+	totalReplicaCount := int32(10)
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: totalReplicaCount,
+	})
+	ct.Status = buildSuccessStatus(ct.Spec)
+
+	// The cached Deployment has the generation of our last patch, but
+	// its status still reports the replicas of the one before it.
+	deployment := buildDeployment(shippertesting.TestApp, ctName, totalReplicaCount, 0)
+	deployment.Generation = 2
+	deployment.Status.ObservedGeneration = 1
+
+	f := shippertesting.NewControllerTestFixture()
+	f.KubeClient.Tracker().Add(deployment)
+	controller := NewController(
+		f.KubeClient,
+		f.KubeInformerFactory,
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		f.Recorder,
+		Options{
+			DrainTimeout:       shutdown.DefaultDrainTimeout,
+			LowPriorityMaxWait: shipperworkqueue.DefaultLowPriorityMaxWait,
+			AppsGroupVersion:   appsv1.SchemeGroupVersion,
+		},
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Run(stopCh)
+
+	controller.setPatchedGeneration(deployment)
+
+	processedCT, err := controller.processCapacityTarget(ct.DeepCopy())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(ct.Status, processedCT.Status)
+	if !eq {
+		t.Fatalf("expected status of unobserved Deployment not to be published:\n%s", diff)
+	}
+
+	deployment.Status.ObservedGeneration = deployment.Generation
+	if controller.isStaleDeployment(deployment) {
+		t.Fatalf("expected Deployment not to be stale once its status catches up")
+	}
+}
+
+// TestDeletedCapacityTarget verifies that the capacity controller forgets
+// about capacity targets and Deployments once they're deleted.
+func TestDeletedCapacityTarget(t *testing.T) {
+	ct := buildCapacityTarget(shippertesting.TestApp, ctName, shipper.CapacityTargetSpec{
+		Percent:           100,
+		TotalReplicaCount: 10,
+	})
+	deployment := buildDeployment(shippertesting.TestApp, ctName, 10, 10)
+
+	f := shippertesting.NewControllerTestFixture()
+	f.ShipperClient.Tracker().Add(ct)
+	f.KubeClient.Tracker().Add(deployment)
+	controller := NewController(
+		f.KubeClient,
+		f.KubeInformerFactory,
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		f.Recorder,
+		Options{
+			DrainTimeout:       shutdown.DefaultDrainTimeout,
+			LowPriorityMaxWait: shipperworkqueue.DefaultLowPriorityMaxWait,
+			AppsGroupVersion:   appsv1.SchemeGroupVersion,
+		},
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Run(stopCh)
+
+	for controller.workqueue.Len() > 0 {
+		controller.processNextWorkItem()
+	}
+
+	key := fmt.Sprintf("%s/%s", ct.Namespace, ct.Name)
+	controller.convergingSince[key] = time.Now()
+	controller.setPatchedGeneration(deployment)
+
+	err := f.ShipperClient.ShipperV1alpha1().CapacityTargets(ct.Namespace).Delete(ct.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error deleting capacity target: %s", err)
+	}
+
+	err = wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return controller.workqueue.Len() > 0, nil
+	})
+	if err != nil {
+		t.Fatalf("expected deleted capacity target to be enqueued")
+	}
+
+	controller.processNextWorkItem()
+	if _, ok := controller.convergingSince[key]; ok {
+		t.Errorf("expected convergence of deleted capacity target to be forgotten")
+	}
+
+	err = f.KubeClient.AppsV1().Deployments(deployment.Namespace).Delete(deployment.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error deleting deployment: %s", err)
+	}
+
+	err = wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		controller.patchedGenerationsMutex.Lock()
+		defer controller.patchedGenerationsMutex.Unlock()
+		return len(controller.patchedGenerations) == 0, nil
+	})
+	if err != nil {
+		t.Errorf("expected patched generation of deleted deployment to be forgotten")
+	}
+}
+
 func runCapacityControllerTest(
 	t *testing.T,
 	objects []runtime.Object,
 	ct *shipper.CapacityTarget,
 	status shipper.CapacityTargetStatus,
 	replicas int32,
+	deprecatedStatusClusterName string,
 ) {
 	f := shippertesting.NewControllerTestFixture()
 
@@ -136,7 +745,7 @@ func runCapacityControllerTest(
 
 	f.ShipperClient.Tracker().Add(ct)
 
-	runController(f)
+	runController(f, deprecatedStatusClusterName)
 
 	ctGVR := shipper.SchemeGroupVersion.WithResource("capacitytargets")
 	ctKey := fmt.Sprintf("%s/%s", ct.Namespace, ct.Name)
@@ -170,13 +779,19 @@ func runCapacityControllerTest(
 	}
 }
 
-func runController(f *shippertesting.ControllerTestFixture) {
+func runController(f *shippertesting.ControllerTestFixture, deprecatedStatusClusterName string) {
 	controller := NewController(
 		f.KubeClient,
 		f.KubeInformerFactory,
 		f.ShipperClient,
 		f.ShipperInformerFactory,
 		f.Recorder,
+		Options{
+			DrainTimeout:                shutdown.DefaultDrainTimeout,
+			DeprecatedStatusClusterName: deprecatedStatusClusterName,
+			LowPriorityMaxWait:          shipperworkqueue.DefaultLowPriorityMaxWait,
+			AppsGroupVersion:            appsv1.SchemeGroupVersion,
+		},
 	)
 
 	stopCh := make(chan struct{})
@@ -0,0 +1,40 @@
+package capacity
+
+import (
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+// observeConvergence starts the clock when a capacity target's spec changes,
+// and records how long it took to achieve once it's ready. Further changes
+// while it's converging don't restart the clock, so a target that keeps
+// changing counts as slow.
+func (c *Controller) observeConvergence(key string, initialCT, ct *shipper.CapacityTarget) {
+	c.convergingSinceMutex.Lock()
+	defer c.convergingSinceMutex.Unlock()
+
+	since, converging := c.convergingSince[key]
+	if !converging && initialCT.Generation != initialCT.Status.ObservedGeneration {
+		since, converging = time.Now(), true
+		c.convergingSince[key] = since
+	}
+
+	if !converging {
+		return
+	}
+
+	if ready, _ := targetutil.IsReady(ct.Status.Conditions); ready {
+		shippermetrics.CapacityConvergenceDuration.WithLabelValues(c.clusterName).
+			Observe(time.Since(since).Seconds())
+		delete(c.convergingSince, key)
+	}
+}
+
+func (c *Controller) forgetConvergence(key string) {
+	c.convergingSinceMutex.Lock()
+	delete(c.convergingSince, key)
+	c.convergingSinceMutex.Unlock()
+}
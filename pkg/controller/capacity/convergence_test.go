@@ -0,0 +1,75 @@
+package capacity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// TestObserveConvergence verifies that the time capacity targets take to
+// achieve a new spec is recorded once they do, and that targets that become
+// not ready without their spec changing aren't counted.
+func TestObserveConvergence(t *testing.T) {
+	c := &Controller{
+		convergingSince:      make(map[string]time.Time),
+		convergingSinceMutex: &sync.Mutex{},
+		clusterName:          "convergence-test",
+	}
+
+	ct := buildCapacityTarget(shippertesting.TestApp, "convergence", shipper.CapacityTargetSpec{
+		TotalReplicaCount: 10,
+		Percent:           50,
+	})
+	key := ct.Namespace + "/" + ct.Name
+
+	steps := []struct {
+		generation int64
+		ready      corev1.ConditionStatus
+	}{
+		// Not ready, but nothing changed.
+		{1, corev1.ConditionFalse},
+		{1, corev1.ConditionTrue},
+		// The spec changes, and is achieved two syncs later.
+		{2, corev1.ConditionFalse},
+		{2, corev1.ConditionFalse},
+		{2, corev1.ConditionTrue},
+	}
+
+	observedGeneration := int64(1)
+	for _, step := range steps {
+		initialCT := ct.DeepCopy()
+		initialCT.Generation = step.generation
+		initialCT.Status.ObservedGeneration = observedGeneration
+
+		syncedCT := initialCT.DeepCopy()
+		syncedCT.Status.ObservedGeneration = step.generation
+		syncedCT.Status.Conditions = []shipper.TargetCondition{
+			{Type: shipper.TargetConditionTypeOperational, Status: corev1.ConditionTrue},
+			{Type: shipper.TargetConditionTypeReady, Status: step.ready},
+		}
+
+		c.observeConvergence(key, initialCT, syncedCT)
+		observedGeneration = step.generation
+	}
+
+	if _, ok := c.convergingSince[key]; ok {
+		t.Errorf("expected converged CapacityTarget to be forgotten")
+	}
+
+	metric := &dto.Metric{}
+	observer := shippermetrics.CapacityConvergenceDuration.WithLabelValues(c.clusterName)
+	if err := observer.(interface{ Write(*dto.Metric) error }).Write(metric); err != nil {
+		t.Fatalf("could not read histogram: %s", err)
+	}
+
+	if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected 1 convergence to be observed, got %d", count)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -38,6 +39,49 @@ func (c *Controller) enqueueCapacityTargetFromDeployment(obj interface{}) {
 	c.enqueueCapacityTarget(ct)
 }
 
+// onDeleteDeployment forgets the generation a deleted Deployment was last
+// patched to, as its key can be taken by an unrelated Deployment starting
+// from scratch.
+func (c *Controller) onDeleteDeployment(obj interface{}) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a Deployment: %#v", obj))
+		return
+	}
+
+	c.patchedGenerationsMutex.Lock()
+	delete(c.patchedGenerations, objectutil.MetaKey(deployment))
+	c.patchedGenerationsMutex.Unlock()
+
+	c.enqueueCapacityTargetFromDeployment(deployment)
+}
+
+// installedForRelease returns whether deployment was installed by Shipper
+// for the release named releaseName: whether it's owned by the release's
+// installation target, or by the anchor ConfigMap that older versions of
+// Shipper made own the objects they installed.
+func installedForRelease(deployment *appsv1.Deployment, releaseName string) bool {
+	for _, ref := range deployment.OwnerReferences {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case gv.Group == shipper.SchemeGroupVersion.Group && ref.Kind == "InstallationTarget":
+			if ref.Name == releaseName {
+				return true
+			}
+		case gv.Group == corev1.GroupName && ref.Kind == "ConfigMap":
+			if ref.Name == releaseName+"-anchor" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (c Controller) getCapacityTargetForReleaseAndNamespace(release, namespace string) (*shipper.CapacityTarget, error) {
 	selector := labels.Set{shipper.ReleaseLabel: release}.AsSelector()
 	gvk := shipper.SchemeGroupVersion.WithKind("CapacityTarget")
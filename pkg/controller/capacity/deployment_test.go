@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
 )
 
 func TestSummarizeSadPods(t *testing.T) {
@@ -85,3 +87,41 @@ func TestSummarizeSadPods(t *testing.T) {
 			expected, actual)
 	}
 }
+
+func TestInstalledForRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		owner     metav1.OwnerReference
+		installed bool
+	}{
+		{
+			"installation target",
+			metav1.OwnerReference{APIVersion: shipper.SchemeGroupVersion.String(), Kind: "InstallationTarget", Name: ctName},
+			true,
+		},
+		{
+			"anchor",
+			metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: ctName + "-anchor"},
+			true,
+		},
+		{
+			"other release",
+			metav1.OwnerReference{APIVersion: shipper.SchemeGroupVersion.String(), Kind: "InstallationTarget", Name: "other"},
+			false,
+		},
+		{
+			"other kind",
+			metav1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "InstallationTarget", Name: ctName},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		deployment := buildDeployment(shippertesting.TestApp, ctName, 0, 0)
+		deployment.OwnerReferences = []metav1.OwnerReference{tt.owner}
+
+		if installed := installedForRelease(deployment, ctName); installed != tt.installed {
+			t.Errorf("%s: expected installed to be %t, got %t", tt.name, tt.installed, installed)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package capacity
+
+import (
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// deprecatedClusterStatuses returns the .status.clusters a CapacityTarget
+// would have had before its status was moved to the top level.
+func deprecatedClusterStatuses(clusterName string, status shipper.CapacityTargetStatus) []shipper.ClusterCapacityStatus {
+	conditions := make([]shipper.ClusterCapacityCondition, 0, len(status.Conditions))
+	for _, cond := range status.Conditions {
+		conditions = append(conditions, shipper.ClusterCapacityCondition{
+			Type:               shipper.ClusterConditionType(cond.Type),
+			Status:             cond.Status,
+			LastTransitionTime: cond.LastTransitionTime,
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+		})
+	}
+
+	return []shipper.ClusterCapacityStatus{
+		{
+			Name:              clusterName,
+			AvailableReplicas: status.AvailableReplicas,
+			AchievedPercent:   status.AchievedPercent,
+			SadPods:           status.SadPods,
+			Conditions:        conditions,
+		},
+	}
+}
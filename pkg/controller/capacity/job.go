@@ -0,0 +1,212 @@
+package capacity
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	"github.com/bookingcom/shipper/pkg/util/replicas"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+const JobFailed = "JobFailed"
+
+func (c *Controller) enqueueCapacityTargetFromJob(obj interface{}) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a Job: %#v", obj))
+		return
+	}
+
+	// Step hooks come and go with the strategy, and have nothing to do
+	// with capacity.
+	if _, ok := job.Labels[shipper.StepHookLabel]; ok {
+		return
+	}
+
+	rel, err := objectutil.GetReleaseLabel(job)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("cannot get release from job %q: %#v", objectutil.MetaKey(job), err))
+		return
+	}
+
+	ct, err := c.getCapacityTargetForReleaseAndNamespace(rel, job.GetNamespace())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("cannot get capacity target for job %q: %#v", objectutil.MetaKey(job), err))
+		return
+	}
+
+	c.enqueueCapacityTarget(ct)
+}
+
+// getJob returns the Job run by ct's release, leaving step hooks out.
+func (c Controller) getJob(ct *shipper.CapacityTarget) (*batchv1.Job, error) {
+	appName, releaseName, err := capacityTargetRelease(ct)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Set{
+		shipper.AppLabel:     appName,
+		shipper.ReleaseLabel: releaseName,
+	}.AsSelector()
+	gvk := batchv1.SchemeGroupVersion.WithKind("Job")
+	allJobs, err := c.jobsLister.Jobs(ct.Namespace).List(selector)
+	if err != nil {
+		return nil, shippererrors.NewKubeclientListError(gvk, ct.Namespace, selector, err)
+	}
+
+	var jobs []*batchv1.Job
+	for _, job := range allJobs {
+		if _, ok := job.Labels[shipper.StepHookLabel]; !ok {
+			jobs = append(jobs, job)
+		}
+	}
+
+	if l := len(jobs); l != 1 {
+		return nil, shippererrors.NewUnexpectedObjectCountFromSelectorError(
+			selector, gvk, 1, l)
+	}
+
+	return jobs[0], nil
+}
+
+// processJob sets the parallelism of job to ct's percentage of its total
+// replica count, and works out how far it got. A Job is ready once it has as
+// many pods running as it should, or once it's complete, which is the only
+// way to achieve all of its capacity. The percentage it achieved is how much
+// of it completed.
+func (c *Controller) processJob(ct *shipper.CapacityTarget, job *batchv1.Job) (workloadProgress, int32) {
+	progress := workloadProgress{
+		availableReplicas: job.Status.Active,
+	}
+
+	complete, failed, msg := jobOutcome(job)
+	completedPercent := jobCompletedPercent(job, complete)
+
+	if failed {
+		progress.readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			JobFailed,
+			msg,
+		)
+
+		return progress, completedPercent
+	}
+
+	if complete {
+		progress.readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionTrue,
+			"",
+			"",
+		)
+
+		return progress, completedPercent
+	}
+
+	desiredParallelism := int32(replicas.CalculateDesiredReplicaCount(uint(ct.Spec.TotalReplicaCount), float64(ct.Spec.Percent)))
+	if job.Spec.Parallelism == nil || desiredParallelism != *job.Spec.Parallelism {
+		if err := c.patchJobWithParallelism(job, desiredParallelism); err != nil {
+			progress.readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				InternalError,
+				err.Error(),
+			)
+			progress.err = err
+
+			return progress, completedPercent
+		}
+
+		progress.readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			InProgress,
+			"",
+		)
+		progress.err = shippererrors.NewCapacityInProgressError(ct.Name)
+
+		return progress, completedPercent
+	}
+
+	if ct.Spec.Percent < 100 && job.Status.Active >= desiredParallelism {
+		progress.readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionTrue,
+			"",
+			"",
+		)
+
+		return progress, completedPercent
+	}
+
+	progress.readyCond = targetutil.NewTargetCondition(
+		shipper.TargetConditionTypeReady,
+		corev1.ConditionFalse,
+		InProgress,
+		fmt.Sprintf("%d pods running, %d succeeded", job.Status.Active, job.Status.Succeeded),
+	)
+	progress.err = shippererrors.NewCapacityInProgressError(ct.Name)
+
+	return progress, completedPercent
+}
+
+// jobCompletedPercent returns how much of job completed. Jobs without a
+// completion count are done as soon as one of their pods succeeds, so they
+// are either not done at all or entirely.
+func jobCompletedPercent(job *batchv1.Job, complete bool) int32 {
+	if complete {
+		return 100
+	}
+
+	completions := job.Spec.Completions
+	if completions == nil || *completions <= 0 {
+		return 0
+	}
+
+	percent := job.Status.Succeeded * 100 / *completions
+	if percent > 100 {
+		percent = 100
+	}
+
+	return percent
+}
+
+func jobOutcome(job *batchv1.Job) (complete, failed bool, msg string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return true, false, ""
+		case batchv1.JobFailed:
+			return false, true, cond.Message
+		}
+	}
+
+	return false, false, ""
+}
+
+func (c *Controller) patchJobWithParallelism(job *batchv1.Job, parallelism int32) error {
+	patch := []byte(fmt.Sprintf(`{"spec": {"parallelism": %d}}`, parallelism))
+
+	_, err := c.kubeClient.BatchV1().
+		Jobs(job.Namespace).
+		Patch(job.Name, types.StrategicMergePatchType, patch)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(job, err)
+	}
+
+	return nil
+}
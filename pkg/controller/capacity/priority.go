@@ -0,0 +1,30 @@
+package capacity
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+// capacityTargetInFlight tells capacity targets that are being scaled, and
+// need to be synced as soon as possible for their release to move on, from
+// the ones that are only being revisited.
+func capacityTargetInFlight(lister listers.CapacityTargetLister) func(string) bool {
+	return func(key string) bool {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return true
+		}
+
+		ct, err := lister.CapacityTargets(namespace).Get(name)
+		if err != nil {
+			// Deleted targets are quick to sync, and better
+			// forgotten soon.
+			return true
+		}
+
+		ready, _ := targetutil.IsReady(ct.Status.Conditions)
+		return !ready || ct.Generation != ct.Status.ObservedGeneration
+	}
+}
@@ -0,0 +1,108 @@
+package capacity
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// podDeletionCostAnnotation tells ReplicaSets which pods to delete first
+// when scaled down: the ones with the lowest cost. Clusters older than
+// Kubernetes 1.22 ignore it.
+const podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+
+// scaleDownDeletionCost is the cost given to the pods Shipper wants gone,
+// low enough to come before any cost set by users.
+var scaleDownDeletionCost = strconv.Itoa(math.MinInt32)
+
+// podsForScaleDown returns the count pods that should be deleted first when
+// scaling down: the ones that aren't ready, and then the oldest. Pods that
+// are already terminating are left out, as they're going anyway.
+func podsForScaleDown(pods []*corev1.Pod, count int) []*corev1.Pod {
+	candidates := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil {
+			candidates = append(candidates, pod)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if ra, rb := isPodReady(a), isPodReady(b); ra != rb {
+			return !ra
+		}
+
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+
+		return a.Name < b.Name
+	})
+
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	return candidates[:count]
+}
+
+// markPodsForScaleDown gives the count pods chosen by podsForScaleDown the
+// lowest deletion cost, so the ReplicaSet deletes them once the Deployment's
+// replica count is lowered. Pods marked by earlier scale downs that are
+// still around are unmarked, so they're not picked over sicker ones.
+func (c *Controller) markPodsForScaleDown(pods []*corev1.Pod, count int) error {
+	marked := make(map[string]bool, count)
+	for _, pod := range podsForScaleDown(pods, count) {
+		marked[pod.Name] = true
+	}
+
+	for _, pod := range pods {
+		cost, ok := pod.Annotations[podDeletionCostAnnotation]
+		isMarked := ok && cost == scaleDownDeletionCost
+
+		// A nil value removes the annotation.
+		var value *string
+		switch {
+		case marked[pod.Name] && !isMarked:
+			value = &scaleDownDeletionCost
+		case !marked[pod.Name] && isMarked:
+		default:
+			continue
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]*string{
+					podDeletionCostAnnotation: value,
+				},
+			},
+		})
+		if err != nil {
+			return shippererrors.NewUnrecoverableError(err)
+		}
+
+		_, err = c.kubeClient.CoreV1().Pods(pod.Namespace).
+			Patch(pod.Name, types.StrategicMergePatchType, patch)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(pod, err)
+		}
+	}
+
+	return nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 )
 
 var (
@@ -42,11 +44,21 @@ func buildSuccessStatus(spec shipper.CapacityTargetSpec) shipper.CapacityTargetS
 		AvailableReplicas: spec.TotalReplicaCount * spec.Percent / 100,
 		Conditions: []shipper.TargetCondition{
 			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonConverged, spec.Percent, spec.Percent),
 			TargetConditionReady,
 		},
 	}
 }
 
+func buildProgressingCondition(status corev1.ConditionStatus, reason string, achieved, percent int32) shipper.TargetCondition {
+	return shipper.TargetCondition{
+		Type:    shipper.TargetConditionTypeProgressing,
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf("achieved %d%% of %d%% capacity", achieved, percent),
+	}
+}
+
 func buildDeployment(app, release string, replicas int32, availableReplicas int32) *appsv1.Deployment {
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -56,6 +68,13 @@ func buildDeployment(app, release string, replicas int32, availableReplicas int3
 				shipper.AppLabel:     app,
 				shipper.ReleaseLabel: release,
 			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: shipper.SchemeGroupVersion.String(),
+					Kind:       "InstallationTarget",
+					Name:       release,
+				},
+			},
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -72,6 +91,23 @@ func buildDeployment(app, release string, replicas int32, availableReplicas int3
 	}
 }
 
+func buildJob(app, release string, parallelism, completions int32) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      release,
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel:     app,
+				shipper.ReleaseLabel: release,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &parallelism,
+			Completions: &completions,
+		},
+	}
+}
+
 func buildSadPodForDeployment(deployment *appsv1.Deployment) *corev1.Pod {
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
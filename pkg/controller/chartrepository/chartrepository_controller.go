@@ -0,0 +1,292 @@
+package chartrepository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/bookingcom/shipper/pkg/chart/repo"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
+)
+
+const (
+	AgentName = "chartrepository-controller"
+)
+
+// Controller is a Kubernetes controller that keeps a chart repo catalog
+// fetching charts the way ChartRepository objects say. Changes to them,
+// and to the Secrets they refer to, are picked up without a restart.
+type Controller struct {
+	catalog  *repo.Catalog
+	recorder record.EventRecorder
+	ns       string
+
+	chartRepositoryLister   shipperlisters.ChartRepositoryLister
+	chartRepositoriesSynced cache.InformerSynced
+
+	secretLister  corev1listers.SecretLister
+	secretsSynced cache.InformerSynced
+
+	workqueue workqueue.RateLimitingInterface
+
+	// urls are the URLs ChartRepositories were last applied to, by
+	// name, so the repos they configured can be reset once they're
+	// gone or point somewhere else.
+	urls   map[string]string
+	urlsMu sync.Mutex
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+}
+
+// NewController returns a new ChartRepository controller. Secrets are
+// read from ns.
+func NewController(
+	shipperInformerFactory shipperinformers.SharedInformerFactory,
+	kubeInformerFactory kubeinformers.SharedInformerFactory,
+	ns string,
+	catalog *repo.Catalog,
+	recorder record.EventRecorder,
+	drainTimeout time.Duration,
+) *Controller {
+	chartRepositoryInformer := shipperInformerFactory.Shipper().V1alpha1().ChartRepositories()
+	secretInformer := corev1informers.New(kubeInformerFactory, ns, nil).Secrets()
+
+	klog.Info("Building a ChartRepository controller")
+
+	c := &Controller{
+		catalog:  catalog,
+		recorder: recorder,
+		ns:       ns,
+
+		chartRepositoryLister:   chartRepositoryInformer.Lister(),
+		chartRepositoriesSynced: chartRepositoryInformer.Informer().HasSynced,
+
+		secretLister:  secretInformer.Lister(),
+		secretsSynced: secretInformer.Informer().HasSynced,
+
+		workqueue: shipperworkqueue.NewNamedRateLimitingQueue(
+			shipperworkqueue.NewDefaultControllerRateLimiter(),
+			"chartrepository_controller_chartrepositories",
+		),
+
+		urls: make(map[string]string),
+
+		drainTimeout: drainTimeout,
+	}
+
+	klog.Info("Setting up event handlers")
+
+	chartRepositoryInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueChartRepository,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueueChartRepository(newObj)
+			},
+			DeleteFunc: objectutil.OnDelete(c.enqueueChartRepository),
+		})
+
+	secretInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueueChartRepositoriesForSecret,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.enqueueChartRepositoriesForSecret(newObj)
+			},
+			DeleteFunc: objectutil.OnDelete(c.enqueueChartRepositoriesForSecret),
+		})
+
+	return c
+}
+
+// Run starts ChartRepository controller workers and blocks until stopCh is
+// closed.
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.V(2).Info("Starting ChartRepository controller")
+	defer klog.V(2).Info("Shutting down ChartRepository controller")
+
+	if ok := cache.WaitForCacheSync(
+		stopCh,
+		c.chartRepositoriesSynced,
+		c.secretsSynced,
+	); !ok {
+		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
+		return
+	}
+
+	workers := shutdown.NewWorkers("ChartRepository controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
+
+	klog.V(4).Info("Started ChartRepository controller")
+
+	<-stopCh
+
+	workers.Drain(c.drainTimeout)
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer c.workqueue.Done(obj)
+
+	var (
+		ok  bool
+		key string
+	)
+
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("invalid object key (will retry: false): %#v", obj))
+		return true
+	}
+
+	shouldRetry := false
+	span := tracing.StartSync(AgentName, "ChartRepository", key)
+	err := c.syncChartRepository(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "ChartRepository", key, err)
+
+	if err != nil {
+		shouldRetry = shippererrors.ShouldRetry(err)
+		runtime.HandleError(fmt.Errorf("error syncing ChartRepository %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
+	}
+
+	if shouldRetry {
+		c.workqueue.AddRateLimited(key)
+
+		return true
+	}
+
+	klog.V(4).Infof("Successfully synced ChartRepository %q", key)
+	c.workqueue.Forget(obj)
+
+	return true
+}
+
+func (c *Controller) enqueueChartRepository(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	c.workqueue.Add(key)
+}
+
+func (c *Controller) enqueueChartRepositoriesForSecret(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a corev1.Secret: %#v", obj))
+		return
+	}
+
+	chartRepositories, err := c.chartRepositoryLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	for _, chartRepository := range chartRepositories {
+		if chartRepository.Spec.SecretName == secret.Name {
+			c.enqueueChartRepository(chartRepository)
+		}
+	}
+}
+
+// syncChartRepository configures the catalog's repo for the URL of the
+// ChartRepository called key. A ChartRepository that can't be applied
+// leaves the repo the way it was, so a bad rotation doesn't stop charts
+// from being fetched with the credentials that worked so far.
+func (c *Controller) syncChartRepository(key string) error {
+	chartRepository, err := c.chartRepositoryLister.Get(key)
+	if kerrors.IsNotFound(err) {
+		klog.V(4).Infof("ChartRepository %q has been deleted", key)
+		c.setURL(key, "")
+		return nil
+	} else if err != nil {
+		return shippererrors.NewKubeclientGetError("", key, err).
+			WithShipperKind("ChartRepository")
+	}
+
+	creds := repo.Credentials{
+		CABundle: []byte(chartRepository.Spec.CABundle),
+	}
+
+	if secretName := chartRepository.Spec.SecretName; secretName != "" {
+		secret, err := c.secretLister.Secrets(c.ns).Get(secretName)
+		if err != nil {
+			return shippererrors.NewKubeclientGetError(c.ns, secretName, err).
+				WithCoreV1Kind("Secret")
+		}
+
+		creds.Username = string(secret.Data[corev1.BasicAuthUsernameKey])
+		creds.Password = string(secret.Data[corev1.BasicAuthPasswordKey])
+		creds.ClientCert = secret.Data[corev1.TLSCertKey]
+		creds.ClientKey = secret.Data[corev1.TLSPrivateKeyKey]
+	}
+
+	fetcher, err := repo.NewRemoteFetcher(chartRepository.Spec.URL, creds)
+	if err != nil {
+		err = shippererrors.NewUnrecoverableError(fmt.Errorf("invalid ChartRepository %q: %s", key, err))
+		c.recorder.Event(chartRepository, corev1.EventTypeWarning, shipperevents.ChartRepositoryInvalid, err.Error())
+		return err
+	}
+
+	opts := &repo.RepoOptions{Fetcher: fetcher}
+	if interval := chartRepository.Spec.IndexRefreshInterval; interval != nil {
+		opts.IndexRefreshPeriod = interval.Duration
+	}
+
+	c.setURL(key, chartRepository.Spec.URL)
+	c.catalog.Configure(chartRepository.Spec.URL, opts)
+
+	klog.V(4).Infof("Charts from %q are now fetched as ChartRepository %q says", chartRepository.Spec.URL, key)
+
+	return nil
+}
+
+// setURL records that the ChartRepository called name applies to url, and
+// resets the repo it applied to before, if any other. An empty url means
+// it doesn't apply to any.
+func (c *Controller) setURL(name, url string) {
+	c.urlsMu.Lock()
+	defer c.urlsMu.Unlock()
+
+	if oldURL, ok := c.urls[name]; ok && oldURL != url {
+		c.catalog.Configure(oldURL, nil)
+	}
+
+	if url == "" {
+		delete(c.urls, name)
+	} else {
+		c.urls[name] = url
+	}
+}
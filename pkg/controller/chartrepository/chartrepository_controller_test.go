@@ -0,0 +1,188 @@
+package chartrepository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/chart/repo"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+)
+
+const (
+	testChartRepositoryName = "private-charts"
+	testSecretName          = "private-charts-credentials"
+)
+
+const testIndex = `apiVersion: v1
+entries:
+  nginx:
+  - name: nginx
+    version: 0.1.0
+    urls:
+    - nginx-0.1.0.tgz
+`
+
+func newController(
+	catalog *repo.Catalog,
+	recorder record.EventRecorder,
+	stopCh <-chan struct{},
+	shipperObjects []runtime.Object,
+	kubeObjects []runtime.Object,
+) *Controller {
+	const noResyncPeriod time.Duration = 0
+	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(
+		shipperfake.NewSimpleClientset(shipperObjects...), noResyncPeriod)
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(
+		kubefake.NewSimpleClientset(kubeObjects...), noResyncPeriod)
+
+	c := NewController(
+		shipperInformerFactory,
+		kubeInformerFactory,
+		shippertesting.TestNamespace,
+		catalog,
+		recorder,
+		shutdown.DefaultDrainTimeout,
+	)
+
+	shipperInformerFactory.Start(stopCh)
+	kubeInformerFactory.Start(stopCh)
+	shipperInformerFactory.WaitForCacheSync(stopCh)
+	kubeInformerFactory.WaitForCacheSync(stopCh)
+
+	return c
+}
+
+func newCatalog(t *testing.T, stopCh <-chan struct{}) (*repo.Catalog, func()) {
+	dir, err := ioutil.TempDir("", "chart-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	catalog := repo.NewCatalog(repo.DefaultFileCacheFactory(dir), repo.DefaultRemoteFetcher, stopCh)
+
+	return catalog, func() { os.RemoveAll(dir) }
+}
+
+func newChartRepository(url string) *shipper.ChartRepository {
+	return &shipper.ChartRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testChartRepositoryName,
+		},
+		Spec: shipper.ChartRepositorySpec{
+			URL:        url,
+			SecretName: testSecretName,
+		},
+	}
+}
+
+// TestChartRepositoryCredentials verifies that charts are fetched with the
+// credentials in the Secret a ChartRepository refers to.
+func TestChartRepositoryCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "shipper" || password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, testIndex)
+	}))
+	defer srv.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testSecretName,
+			Namespace: shippertesting.TestNamespace,
+		},
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("shipper"),
+			corev1.BasicAuthPasswordKey: []byte("hunter2"),
+		},
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	catalog, cleanup := newCatalog(t, stopCh)
+	defer cleanup()
+
+	c := newController(
+		catalog,
+		record.NewFakeRecorder(42),
+		stopCh,
+		[]runtime.Object{newChartRepository(srv.URL)},
+		[]runtime.Object{secret},
+	)
+
+	if err := c.syncChartRepository(testChartRepositoryName); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := catalog.CreateRepoIfNotExist(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chart := &shipper.Chart{Name: "nginx", Version: "0.1.0", RepoURL: srv.URL}
+	if _, err := r.ResolveVersion(chart); err != nil {
+		t.Fatalf("expected chart to be resolved with the ChartRepository's credentials, got %s", err)
+	}
+}
+
+// TestInvalidChartRepository verifies that ChartRepositories that can't be
+// applied aren't retried, and are told about in an event.
+func TestInvalidChartRepository(t *testing.T) {
+	chartRepository := newChartRepository("https://charts.example.com")
+	chartRepository.Spec.SecretName = ""
+	chartRepository.Spec.CABundle = "not a certificate"
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	catalog, cleanup := newCatalog(t, stopCh)
+	defer cleanup()
+
+	recorder := record.NewFakeRecorder(42)
+	c := newController(
+		catalog,
+		recorder,
+		stopCh,
+		[]runtime.Object{chartRepository},
+		nil,
+	)
+
+	err := c.syncChartRepository(testChartRepositoryName)
+	if err == nil {
+		t.Fatal("expected an invalid CA bundle to fail the sync")
+	}
+
+	if shippererrors.ShouldRetry(err) {
+		t.Errorf("expected invalid ChartRepositories not to be retried")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, shipperevents.ChartRepositoryInvalid) {
+			t.Errorf("expected a %s event, got %q", shipperevents.ChartRepositoryInvalid, event)
+		}
+	default:
+		t.Errorf("expected a %s event, got none", shipperevents.ChartRepositoryInvalid)
+	}
+}
@@ -0,0 +1,264 @@
+package gitops
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shipperscheme "github.com/bookingcom/shipper/pkg/client/clientset/versioned/scheme"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/shipperconfig"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+)
+
+const (
+	AgentName = "gitops-controller"
+)
+
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// Controller is a Kubernetes controller that reconciles Application objects
+// from manifests stored in a git repository, so teams can drive their
+// rollouts purely through pull requests. The commit an Application was last
+// synced from is recorded in an annotation, and carried over to the Releases
+// created from it.
+//
+// Applications removed from the repository are left alone: deleting an
+// Application deletes all of its Releases, and that is too destructive to
+// happen as a side effect of a bad merge.
+type Controller struct {
+	shipperClientset clientset.Interface
+	recorder         record.EventRecorder
+
+	applicationLister  shipperlisters.ApplicationLister
+	applicationsSynced cache.InformerSynced
+
+	source   Source
+	interval time.Duration
+
+	// config overrides interval when it has a ShipperConfig. Nil if
+	// there's none.
+	config *shipperconfig.Store
+}
+
+// NewController returns a new GitOps controller.
+func NewController(
+	shipperClientset clientset.Interface,
+	informerFactory shipperinformers.SharedInformerFactory,
+	source Source,
+	interval time.Duration,
+	recorder record.EventRecorder,
+	config *shipperconfig.Store,
+) *Controller {
+	applicationInformer := informerFactory.Shipper().V1alpha1().Applications()
+
+	return &Controller{
+		shipperClientset: shipperClientset,
+		recorder:         recorder,
+
+		applicationLister:  applicationInformer.Lister(),
+		applicationsSynced: applicationInformer.Informer().HasSynced,
+
+		source:   source,
+		interval: interval,
+		config:   config,
+	}
+}
+
+// Run waits for the informer caches to sync, and then periodically syncs
+// Applications from the source until stopCh is closed.
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+
+	klog.V(2).Info("Starting GitOps controller")
+	defer klog.V(2).Info("Shutting down GitOps controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, c.applicationsSynced); !ok {
+		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
+		return
+	}
+
+	klog.V(4).Info("Started GitOps controller")
+
+	// The interval is looked up again after every sync, so changing it
+	// in the ShipperConfig takes effect from the next one.
+	for {
+		if err := c.sync(); err != nil {
+			runtime.HandleError(fmt.Errorf("error syncing Applications from git: %s", err))
+			shippermetrics.ObserveSyncError(AgentName, err)
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(c.syncInterval()):
+		}
+	}
+}
+
+func (c *Controller) syncInterval() time.Duration {
+	if c.config != nil {
+		return c.config.Get().GitOpsInterval
+	}
+
+	return c.interval
+}
+
+func (c *Controller) sync() error {
+	dir, revision, err := c.source.Fetch()
+	if err != nil {
+		return err
+	}
+
+	apps, err := readApplications(dir)
+	if err != nil {
+		return err
+	}
+
+	errs := shippererrors.NewMultiError()
+	for _, app := range apps {
+		if err := c.syncApplication(app, revision); err != nil {
+			errs.Append(err)
+		}
+	}
+
+	if errs.Any() {
+		return errs.Flatten()
+	}
+
+	klog.V(4).Infof("Synced %d Applications from revision %s", len(apps), revision)
+
+	return nil
+}
+
+func (c *Controller) syncApplication(desired *shipper.Application, revision string) error {
+	if desired.Namespace == "" {
+		return fmt.Errorf("Application %q has no namespace", desired.Name)
+	}
+
+	existing, err := c.applicationLister.Applications(desired.Namespace).Get(desired.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(desired.Namespace, desired.Name, err).
+			WithShipperKind("Application")
+	}
+
+	if errors.IsNotFound(err) {
+		app := desired.DeepCopy()
+		setGitCommit(app, revision)
+
+		created, err := c.shipperClientset.ShipperV1alpha1().Applications(app.Namespace).Create(app)
+		if err != nil {
+			return shippererrors.NewKubeclientCreateError(app, err).
+				WithShipperKind("Application")
+		}
+
+		c.recorder.Eventf(created, corev1.EventTypeNormal, shipperevents.ApplicationSyncedFromGit,
+			"Created Application from revision %s", revision)
+
+		return nil
+	}
+
+	if reflect.DeepEqual(existing.Spec, desired.Spec) {
+		return nil
+	}
+
+	app := existing.DeepCopy()
+	app.Spec = desired.Spec
+	for k, v := range desired.Labels {
+		if app.Labels == nil {
+			app.Labels = make(map[string]string)
+		}
+		app.Labels[k] = v
+	}
+	for k, v := range desired.Annotations {
+		if app.Annotations == nil {
+			app.Annotations = make(map[string]string)
+		}
+		app.Annotations[k] = v
+	}
+	setGitCommit(app, revision)
+
+	updated, err := c.shipperClientset.ShipperV1alpha1().Applications(app.Namespace).Update(app)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(app, err).
+			WithShipperKind("Application")
+	}
+
+	c.recorder.Eventf(updated, corev1.EventTypeNormal, shipperevents.ApplicationSyncedFromGit,
+		"Updated Application %q from revision %s", objectutil.MetaKey(updated), revision)
+
+	return nil
+}
+
+func setGitCommit(app *shipper.Application, revision string) {
+	if app.Annotations == nil {
+		app.Annotations = make(map[string]string)
+	}
+	app.Annotations[shipper.AppGitCommitAnnotation] = revision
+}
+
+// readApplications walks dir and decodes every Application found in YAML
+// files in it. Other kinds of objects are ignored.
+func readApplications(dir string) ([]*shipper.Application, error) {
+	var apps []*shipper.Application
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(path)
+		if info.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range yamlDocumentSeparator.Split(string(data), -1) {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+
+			obj, _, err := shipperscheme.Codecs.UniversalDeserializer().Decode([]byte(doc), nil, nil)
+			if err != nil {
+				// Not a shipper object, or not an object at
+				// all. Either way, not ours to handle.
+				klog.V(6).Infof("Skipping document in %q: %s", path, err)
+				continue
+			}
+
+			if app, ok := obj.(*shipper.Application); ok {
+				apps = append(apps, app)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,151 @@
+package gitops
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+const (
+	revision = "deadbeef"
+
+	appManifest = `apiVersion: shipper.booking.com/v1alpha1
+kind: Application
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  revisionHistoryLimit: 2
+  template:
+    chart:
+      name: nginx
+      repoUrl: https://charts.example.com
+      version: %s
+    clusterRequirements:
+      regions:
+      - name: local
+    strategy:
+      steps:
+      - name: full on
+        capacity:
+          contender: 100
+          incumbent: 0
+        traffic:
+          contender: 100
+          incumbent: 0
+    values: {}
+`
+	otherManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-an-application
+`
+)
+
+type fakeSource struct {
+	dir string
+}
+
+func (s fakeSource) Fetch() (string, string, error) {
+	return s.dir, revision, nil
+}
+
+// TestSyncCreatesApplications checks that Applications found in the source
+// are created, that other objects are ignored, and that the revision they
+// were synced from is recorded.
+func TestSyncCreatesApplications(t *testing.T) {
+	dir := writeManifests(t, fmt.Sprintf(appManifest, shippertesting.TestApp, shippertesting.TestNamespace, "0.0.1")+
+		"---\n"+otherManifest)
+	defer os.RemoveAll(dir)
+
+	f := shippertesting.NewControllerTestFixture()
+	c := runController(t, f, dir)
+	if err := c.sync(); err != nil {
+		t.Fatalf("unexpected error syncing: %s", err)
+	}
+
+	app, err := f.ShipperClient.ShipperV1alpha1().Applications(shippertesting.TestNamespace).Get(shippertesting.TestApp, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected Application to be created: %s", err)
+	}
+
+	if commit := app.Annotations[shipper.AppGitCommitAnnotation]; commit != revision {
+		t.Fatalf("expected Application to be annotated with commit %q, got %q", revision, commit)
+	}
+}
+
+// TestSyncUpdatesApplications checks that Applications whose spec differs
+// from the one in the source are updated.
+func TestSyncUpdatesApplications(t *testing.T) {
+	dir := writeManifests(t, fmt.Sprintf(appManifest, shippertesting.TestApp, shippertesting.TestNamespace, "0.0.2"))
+	defer os.RemoveAll(dir)
+
+	existingDir := writeManifests(t, fmt.Sprintf(appManifest, shippertesting.TestApp, shippertesting.TestNamespace, "0.0.1"))
+	defer os.RemoveAll(existingDir)
+
+	existing, err := readApplications(existingDir)
+	if err != nil {
+		t.Fatalf("unexpected error reading manifests: %s", err)
+	}
+
+	f := shippertesting.NewControllerTestFixture()
+	f.ShipperClient.Tracker().Add(existing[0])
+
+	c := runController(t, f, dir)
+	if err := c.sync(); err != nil {
+		t.Fatalf("unexpected error syncing: %s", err)
+	}
+
+	app, err := f.ShipperClient.ShipperV1alpha1().Applications(shippertesting.TestNamespace).Get(shippertesting.TestApp, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected Application to exist: %s", err)
+	}
+
+	if version := app.Spec.Template.Chart.Version; version != "0.0.2" {
+		t.Fatalf("expected Application to be updated to chart version %q, got %q", "0.0.2", version)
+	}
+
+	if commit := app.Annotations[shipper.AppGitCommitAnnotation]; commit != revision {
+		t.Fatalf("expected Application to be annotated with commit %q, got %q", revision, commit)
+	}
+}
+
+func runController(t *testing.T, f *shippertesting.ControllerTestFixture, dir string) *Controller {
+	c := NewController(
+		f.ShipperClient,
+		f.ShipperInformerFactory,
+		fakeSource{dir: dir},
+		time.Second,
+		f.Recorder,
+		nil,
+	)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	return c
+}
+
+func writeManifests(t *testing.T, manifests string) string {
+	dir, err := ioutil.TempDir("", "gitops")
+	if err != nil {
+		t.Fatalf("could not create temporary directory: %s", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "app.yaml"), []byte(manifests), 0644)
+	if err != nil {
+		t.Fatalf("could not write manifests: %s", err)
+	}
+
+	return dir
+}
@@ -0,0 +1,80 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Source provides a checkout of the manifests the GitOps controller should
+// reconcile.
+type Source interface {
+	// Fetch brings the checkout up to date, and returns the directory
+	// where manifests can be read from and the revision it points to.
+	Fetch() (dir string, revision string, err error)
+}
+
+// GitSource is a Source backed by a git repository. It shells out to the git
+// binary, so it works with any transport and credentials git itself is
+// configured for.
+type GitSource struct {
+	repoURL  string
+	branch   string
+	path     string
+	checkout string
+}
+
+var _ Source = (*GitSource)(nil)
+
+func NewGitSource(repoURL, branch, path, checkout string) *GitSource {
+	return &GitSource{
+		repoURL:  repoURL,
+		branch:   branch,
+		path:     path,
+		checkout: checkout,
+	}
+}
+
+func (s *GitSource) Fetch() (string, string, error) {
+	if _, err := os.Stat(filepath.Join(s.checkout, ".git")); os.IsNotExist(err) {
+		_, err := git("", "clone", "--quiet", "--branch", s.branch, "--single-branch", s.repoURL, s.checkout)
+		if err != nil {
+			return "", "", err
+		}
+	} else {
+		_, err := git(s.checkout, "fetch", "--quiet", "origin", s.branch)
+		if err != nil {
+			return "", "", err
+		}
+
+		_, err = git(s.checkout, "reset", "--quiet", "--hard", "FETCH_HEAD")
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	revision, err := git(s.checkout, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+
+	return filepath.Join(s.checkout, s.path), revision, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
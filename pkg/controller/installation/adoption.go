@@ -0,0 +1,140 @@
+package installation
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// adopt takes over the objects adoption names instead of installing a
+// chart. They get the labels and owner reference of the objects the
+// installer creates, but are otherwise left as they are. The pods of the
+// Deployment get the labels too, so capacity and traffic can be shifted
+// away from them, which rolls them out once.
+func (i *Installer) adopt(
+	client kubernetes.Interface,
+	dynamicClientBuilderFunc DynamicClientBuilderFunc,
+	adoption *shipper.Adoption,
+) error {
+	ownerReference := i.ownerReference()
+	i.installed = nil
+
+	objects := []*unstructured.Unstructured{
+		adoptedObject(appsv1.SchemeGroupVersion.WithKind("Deployment"), adoption.Deployment),
+	}
+	for _, name := range adoption.Services {
+		objects = append(objects, adoptedObject(corev1.SchemeGroupVersion.WithKind("Service"), name))
+	}
+
+	getResourceClient := i.resourceClientGetter(client, dynamicClientBuilderFunc, nil)
+	for _, obj := range objects {
+		if err := useServedVersion(client, obj); err != nil {
+			return err
+		}
+
+		adopted, err := i.adoptObject(getResourceClient, obj, ownerReference)
+		if err != nil {
+			return err
+		}
+
+		i.installed = append(i.installed, adopted)
+	}
+
+	return nil
+}
+
+func adoptedObject(gvk schema.GroupVersionKind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+
+	return obj
+}
+
+// adoptObject labels the object in the application cluster obj names as
+// belonging to the installation target, and returns it as it is in the
+// cluster. Objects already belonging to another installation target or
+// application are never taken over.
+func (i *Installer) adoptObject(
+	getResourceClient func(schema.GroupVersionKind) (dynamic.ResourceInterface, error),
+	obj *unstructured.Unstructured,
+	ownerReference metav1.OwnerReference,
+) (*unstructured.Unstructured, error) {
+	it := i.installationTarget
+	gvk := obj.GroupVersionKind()
+
+	resourceClient, err := getResourceClient(gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, shippererrors.NewKubeclientGetError(it.Namespace, obj.GetName(), err).
+			WithKind(gvk)
+	}
+
+	existingLabels := existing.GetLabels()
+	if owner, ok := existingLabels[shipper.InstallationTargetOwnerLabel]; ok {
+		if owner == it.Name {
+			return existing, nil
+		}
+
+		return nil, shippererrors.NewInstallationTargetOwnershipError(existing)
+	}
+
+	if app, ok := existingLabels[shipper.AppLabel]; ok && app != it.Labels[shipper.AppLabel] {
+		return nil, shippererrors.NewInstallationTargetOwnershipError(existing)
+	}
+
+	shipperLabels := labels.Merge(labels.Set(it.Labels), labels.Set{
+		shipper.InstallationTargetOwnerLabel: it.Name,
+	})
+
+	adoptedLabels := labels.Merge(labels.Set(existingLabels), shipperLabels)
+	if gvk.Kind == "Service" {
+		adoptedLabels[shipper.LBLabel] = shipper.LBForProduction
+	}
+	existing.SetLabels(adoptedLabels)
+
+	annotations := existing.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[shipper.AdoptedAnnotation] = it.Name
+	existing.SetAnnotations(annotations)
+
+	existing.SetOwnerReferences(append(existing.GetOwnerReferences(), ownerReference))
+
+	if gvk.Kind == "Deployment" {
+		// The Deployment's selector can't be changed, so its pods
+		// keep matching it with the labels they had.
+		podLabels, _, err := unstructured.NestedStringMap(existing.Object, "spec", "template", "metadata", "labels")
+		if err != nil {
+			return nil, err
+		}
+
+		err = unstructured.SetNestedStringMap(existing.Object,
+			labels.Merge(labels.Set(podLabels), shipperLabels),
+			"spec", "template", "metadata", "labels")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	adopted, err := resourceClient.Update(existing, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, shippererrors.NewKubeclientUpdateError(existing, err).
+			WithKind(gvk)
+	}
+
+	return adopted, nil
+}
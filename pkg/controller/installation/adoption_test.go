@@ -0,0 +1,128 @@
+package installation
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildUnmanagedObject(apiVersion, kind, name string, objLabels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(shippertesting.TestNamespace)
+	obj.SetName(name)
+	obj.SetLabels(objLabels)
+
+	return obj
+}
+
+// TestInstallerAdopt verifies that adopting objects labels them, and the
+// pods of the Deployment, as belonging to the installation target without
+// touching their selectors.
+func TestInstallerAdopt(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+	it.Spec.Adopt = &shipper.Adoption{
+		Deployment: "legacy",
+		Services:   []string{"legacy"},
+	}
+
+	deployment := buildUnmanagedObject("apps/v1", "Deployment", "legacy", map[string]string{"app": "legacy"})
+	unstructured.SetNestedStringMap(deployment.Object, map[string]string{"app": "legacy"}, "spec", "selector", "matchLabels")
+	unstructured.SetNestedStringMap(deployment.Object, map[string]string{"app": "legacy"}, "spec", "template", "metadata", "labels")
+
+	service := buildUnmanagedObject("v1", "Service", "legacy", nil)
+	unstructured.SetNestedStringMap(service.Object, map[string]string{"app": "legacy"}, "spec", "selector")
+
+	f := newFixture([]runtime.Object{deployment, service})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	installer := NewInstaller(it, nil)
+	if err := installer.adopt(f.KubeClient, f.DynamicClientBuilder, it.Spec.Adopt); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(installer.installed) != 2 {
+		t.Fatalf("expected 2 adopted objects, got %d", len(installer.installed))
+	}
+
+	adoptedDeployment, err := f.DynamicClient.Resource(deploymentGVR).Namespace(shippertesting.TestNamespace).Get("legacy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owner := adoptedDeployment.GetLabels()[shipper.InstallationTargetOwnerLabel]; owner != it.Name {
+		t.Errorf("expected Deployment to be owned by %q, got %q", it.Name, owner)
+	}
+	if adopted := adoptedDeployment.GetAnnotations()[shipper.AdoptedAnnotation]; adopted != it.Name {
+		t.Errorf("expected Deployment to be adopted by %q, got %q", it.Name, adopted)
+	}
+	if refs := adoptedDeployment.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != it.UID || refs[0].Name != it.Name {
+		t.Errorf("expected Deployment to be owned by the installation target, got %v", refs)
+	}
+
+	podLabels, _, _ := unstructured.NestedStringMap(adoptedDeployment.Object, "spec", "template", "metadata", "labels")
+	if podLabels["app"] != "legacy" || podLabels[shipper.AppLabel] != shippertesting.TestApp {
+		t.Errorf("expected pods to keep their labels and get the application's, got %v", podLabels)
+	}
+
+	selector, _, _ := unstructured.NestedStringMap(adoptedDeployment.Object, "spec", "selector", "matchLabels")
+	if len(selector) != 1 {
+		t.Errorf("expected Deployment selector to be left alone, got %v", selector)
+	}
+
+	adoptedService, err := f.DynamicClient.Resource(svcGVR).Namespace(shippertesting.TestNamespace).Get("legacy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lb := adoptedService.GetLabels()[shipper.LBLabel]; lb != shipper.LBForProduction {
+		t.Errorf("expected Service to be a production LB, got %q", lb)
+	}
+
+	// Adopting again leaves the objects alone.
+	installer = NewInstaller(it, nil)
+	if err := installer.adopt(f.KubeClient, f.DynamicClientBuilder, it.Spec.Adopt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestInstallerAdoptOwnedObject verifies that objects belonging to another
+// application are never adopted.
+func TestInstallerAdoptOwnedObject(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+	it.Spec.Adopt = &shipper.Adoption{Deployment: "legacy"}
+
+	deployment := buildUnmanagedObject("apps/v1", "Deployment", "legacy", map[string]string{
+		shipper.AppLabel: "another-app",
+	})
+
+	f := newFixture([]runtime.Object{deployment})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	installer := NewInstaller(it, nil)
+	err := installer.adopt(f.KubeClient, f.DynamicClientBuilder, it.Spec.Adopt)
+	if _, ok := err.(shippererrors.InstallationTargetOwnershipError); !ok {
+		t.Fatalf("expected an InstallationTargetOwnershipError, got %v", err)
+	}
+}
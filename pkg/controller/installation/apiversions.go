@@ -0,0 +1,32 @@
+package installation
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/bookingcom/shipper/pkg/client"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// useServedVersion changes the API version of obj to the one the
+// application cluster serves its kind in, when that isn't obj's own but a
+// compatible one, such as apps/v1beta2 for the Deployments of clusters
+// older than Kubernetes 1.9. Kinds without compatible versions are left to
+// fail when they're installed, as always.
+func useServedVersion(kubeClient kubernetes.Interface, obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	if len(client.CompatibleGroupVersions(gvk)) == 1 {
+		return nil
+	}
+
+	served, ok, err := client.ServedGroupVersionKind(kubeClient.Discovery(), gvk)
+	if err != nil {
+		return shippererrors.NewKubeclientDiscoverError(gvk.GroupVersion(), err)
+	} else if !ok {
+		return shippererrors.NewAPIVersionNotServedError(gvk)
+	}
+
+	obj.SetAPIVersion(served.GroupVersion().String())
+
+	return nil
+}
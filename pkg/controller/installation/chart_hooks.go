@@ -0,0 +1,287 @@
+package installation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	HelmHookAnnotation             = "helm.sh/hook"
+	HelmHookWeightAnnotation       = "helm.sh/hook-weight"
+	HelmHookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+
+	HookPreInstall  = "pre-install"
+	HookPostInstall = "post-install"
+	HookPreUpgrade  = "pre-upgrade"
+	HookPostUpgrade = "post-upgrade"
+
+	// Helm 2 installed objects annotated with crd-install before the rest
+	// of the chart. Shipper installs CustomResourceDefinitions first
+	// anyway, so they're installed like any other object.
+	hookCRDInstall = "crd-install"
+
+	hookPolicyBeforeCreation = "before-hook-creation"
+	hookPolicySucceeded      = "hook-succeeded"
+	hookPolicyFailed         = "hook-failed"
+)
+
+// DefaultChartHookTimeout is how long hook Jobs get to complete before
+// they're considered failed, unless configured otherwise.
+const DefaultChartHookTimeout = 5 * time.Minute
+
+// splitChartHooks separates the objects annotated as Helm hooks from the
+// ones to install. Hooks Shipper doesn't run, like test and delete hooks,
+// are dropped.
+func splitChartHooks(objects []runtime.Object) ([]runtime.Object, []runtime.Object) {
+	var installable, hooks []runtime.Object
+	for _, obj := range objects {
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			installable = append(installable, obj)
+			continue
+		}
+
+		objHooks := helmHooks(objMeta)
+		switch {
+		case len(objHooks) == 0:
+			installable = append(installable, obj)
+		case objHooks[hookCRDInstall]:
+			installable = append(installable, obj)
+		case objHooks[HookPreInstall], objHooks[HookPostInstall],
+			objHooks[HookPreUpgrade], objHooks[HookPostUpgrade]:
+			hooks = append(hooks, obj)
+		}
+	}
+
+	return installable, hooks
+}
+
+func helmHooks(obj metav1.Object) map[string]bool {
+	return annotationSet(obj, HelmHookAnnotation)
+}
+
+func hookDeletePolicies(obj metav1.Object) map[string]bool {
+	return annotationSet(obj, HelmHookDeletePolicyAnnotation)
+}
+
+func annotationSet(obj metav1.Object, annotation string) map[string]bool {
+	value, ok := obj.GetAnnotations()[annotation]
+	if !ok {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			set[v] = true
+		}
+	}
+
+	return set
+}
+
+func hookWeight(obj metav1.Object) int {
+	weight, err := strconv.Atoi(obj.GetAnnotations()[HelmHookWeightAnnotation])
+	if err != nil {
+		return 0
+	}
+
+	return weight
+}
+
+// runHooks runs the objects among hooks annotated with hook one at a time,
+// by weight like Helm does, and waits for every Job to complete before moving
+// on to the next one. It returns a ChartHookPendingError while any of them
+// is still running, and considers Jobs that don't complete within timeout
+// failed, unless it's zero. The outcome of every hook that ran to completion
+// is recorded in the installation target's status, so they only ever run
+// once.
+func (i *Installer) runHooks(
+	client kubernetes.Interface,
+	dynamicClientBuilderFunc DynamicClientBuilderFunc,
+	hooks []runtime.Object,
+	hook string,
+	timeout time.Duration,
+) error {
+	it := i.installationTarget
+
+	var objects []*unstructured.Unstructured
+	for _, hookObj := range hooks {
+		obj, err := toUnstructured(hookObj)
+		if err != nil {
+			return err
+		}
+
+		if helmHooks(obj)[hook] {
+			objects = append(objects, obj)
+		}
+	}
+
+	sort.SliceStable(objects, func(a, b int) bool {
+		wa, wb := hookWeight(objects[a]), hookWeight(objects[b])
+		if wa != wb {
+			return wa < wb
+		}
+
+		oa := shipperchart.InstallOrder.Index(objects[a].GetKind())
+		ob := shipperchart.InstallOrder.Index(objects[b].GetKind())
+		if oa != ob {
+			return oa < ob
+		}
+
+		return objects[a].GetName() < objects[b].GetName()
+	})
+
+	getResourceClient := i.resourceClientGetter(client, dynamicClientBuilderFunc, nil)
+	for _, obj := range objects {
+		kind, name := obj.GetKind(), obj.GetName()
+
+		if status := findHookStatus(it.Status.Hooks, kind, name); status != nil {
+			if !status.Succeeded {
+				return shippererrors.NewChartHookFailedError(kind, name, status.Hook, status.Message)
+			}
+
+			continue
+		}
+
+		done, failure, err := i.runHook(getResourceClient, obj, timeout)
+		if err != nil {
+			return err
+		}
+
+		if failure != "" {
+			it.Status.Hooks = append(it.Status.Hooks, shipper.ChartHookStatus{
+				Kind:    kind,
+				Name:    name,
+				Hook:    hook,
+				Message: failure,
+			})
+
+			return shippererrors.NewChartHookFailedError(kind, name, hook, failure)
+		}
+
+		if !done {
+			return shippererrors.NewChartHookPendingError(kind, name, hook)
+		}
+
+		it.Status.Hooks = append(it.Status.Hooks, shipper.ChartHookStatus{
+			Kind:      kind,
+			Name:      name,
+			Hook:      hook,
+			Succeeded: true,
+		})
+	}
+
+	return nil
+}
+
+// runHook creates a hook object, and reports whether it's done or why it
+// failed. Jobs are done once they complete, and any other object as soon as
+// it's created.
+func (i *Installer) runHook(
+	getResourceClient func(schema.GroupVersionKind) (dynamic.ResourceInterface, error),
+	obj *unstructured.Unstructured,
+	timeout time.Duration,
+) (bool, string, error) {
+	it := i.installationTarget
+	gvk := obj.GroupVersionKind()
+	name := obj.GetName()
+
+	resourceClient, err := getResourceClient(gvk)
+	if err != nil {
+		return false, "", err
+	}
+
+	existing, err := resourceClient.Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		obj.SetOwnerReferences([]metav1.OwnerReference{i.ownerReference()})
+		if _, err := resourceClient.Create(obj, metav1.CreateOptions{}); err != nil {
+			return false, "", shippererrors.NewKubeclientCreateError(obj, err).
+				WithKind(gvk)
+		}
+
+		return gvk.Kind != "Job", "", nil
+	} else if err != nil {
+		return false, "", shippererrors.NewKubeclientGetError(obj.GetNamespace(), name, err).
+			WithKind(gvk)
+	}
+
+	policies := hookDeletePolicies(obj)
+
+	if existing.GetLabels()[shipper.InstallationTargetOwnerLabel] != it.Name {
+		// This was left behind by the same hook of an earlier
+		// release. Helm replaces those unless told otherwise.
+		if len(policies) > 0 && !policies[hookPolicyBeforeCreation] {
+			return false, "", shippererrors.NewInstallationTargetOwnershipError(existing)
+		}
+
+		return false, "", deleteHook(resourceClient, existing)
+	}
+
+	if gvk.Kind != "Job" {
+		return true, "", nil
+	}
+
+	var failure string
+	if cond := findCondition(existing, "Failed"); cond != nil && cond["status"] == "True" {
+		failure, _ = cond["message"].(string)
+		if failure == "" {
+			failure = "Job failed"
+		}
+	} else if !conditionTrue(existing, "Complete") {
+		created := existing.GetCreationTimestamp()
+		if timeout == 0 || created.IsZero() || time.Since(created.Time) < timeout {
+			return false, "", nil
+		}
+
+		failure = fmt.Sprintf("Job did not complete within %s", timeout)
+	}
+
+	if (failure == "" && policies[hookPolicySucceeded]) || (failure != "" && policies[hookPolicyFailed]) {
+		if err := deleteHook(resourceClient, existing); err != nil {
+			return false, "", err
+		}
+	}
+
+	return failure == "", failure, nil
+}
+
+func deleteHook(resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	// Jobs leave their pods behind unless told otherwise.
+	propagationPolicy := metav1.DeletePropagationBackground
+	err := resourceClient.Delete(obj.GetName(), &metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return shippererrors.NewKubeclientDeleteError(obj.GetNamespace(), obj.GetName(), err).
+			WithKind(obj.GroupVersionKind())
+	}
+
+	return nil
+}
+
+func findHookStatus(statuses []shipper.ChartHookStatus, kind, name string) *shipper.ChartHookStatus {
+	for i := range statuses {
+		if statuses[i].Kind == kind && statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+
+	return nil
+}
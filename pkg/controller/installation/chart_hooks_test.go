@@ -0,0 +1,249 @@
+package installation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestSplitChartHooks(t *testing.T) {
+	newConfigMap := func(name, hook string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if hook != "" {
+			cm.Annotations = map[string]string{HelmHookAnnotation: hook}
+		}
+		return cm
+	}
+
+	objects := []runtime.Object{
+		newConfigMap("regular", ""),
+		newConfigMap("pre-install", "pre-install"),
+		newConfigMap("upgrade", "pre-upgrade, post-upgrade"),
+		newConfigMap("crd-install", "crd-install"),
+		newConfigMap("test", "test-success"),
+		newConfigMap("pre-delete", "pre-delete"),
+	}
+
+	installable, hooks := splitChartHooks(objects)
+
+	names := func(objs []runtime.Object) []string {
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.(*corev1.ConfigMap).Name)
+		}
+		return names
+	}
+
+	expectedInstallable := []string{"regular", "crd-install"}
+	if got := names(installable); !equalStrings(got, expectedInstallable) {
+		t.Errorf("expected %v to be installed, got %v", expectedInstallable, got)
+	}
+
+	expectedHooks := []string{"pre-install", "upgrade"}
+	if got := names(hooks); !equalStrings(got, expectedHooks) {
+		t.Errorf("expected %v to be hooks, got %v", expectedHooks, got)
+	}
+}
+
+// TestRunHooksInOrder verifies that hooks run one at a time, by weight, and
+// that the installer waits for each Job to complete.
+func TestRunHooksInOrder(t *testing.T) {
+	it := buildHookInstallationTarget()
+
+	first := buildHookJob(it, "first", HookPreInstall, "-1", "")
+	second := buildHookJob(it, "second", HookPreInstall, "0", "")
+	post := buildHookJob(it, "post", HookPostInstall, "-5", "")
+
+	f := newHookFixture(nil)
+	installer := NewInstaller(it, nil)
+	hooks := []runtime.Object{second.DeepCopy(), post.DeepCopy(), first.DeepCopy()}
+
+	err := installer.runHooks(f.KubeClient, f.DynamicClientBuilder, hooks, HookPreInstall, DefaultChartHookTimeout)
+	if _, ok := err.(shippererrors.ChartHookPendingError); !ok {
+		t.Fatalf("expected a ChartHookPendingError, got %v", err)
+	}
+
+	first.SetOwnerReferences([]metav1.OwnerReference{buildInstallationTargetOwnerRef(it)})
+	expectedDynamicActions := []kubetesting.Action{
+		kubetesting.NewCreateAction(jobGVR, shippertesting.TestNamespace, first),
+	}
+	shippertesting.CheckActions(expectedDynamicActions, shippertesting.FilterActions(f.DynamicClient.Actions()), t)
+
+	if len(it.Status.Hooks) != 0 {
+		t.Errorf("expected no hooks to have completed, got %v", it.Status.Hooks)
+	}
+}
+
+// TestRunHooksDeletePolicies verifies that hooks are deleted according to
+// their hook-delete-policy once they're done, and that their outcome is
+// recorded so they don't run again.
+func TestRunHooksDeletePolicies(t *testing.T) {
+	tests := []struct {
+		name           string
+		condition      string
+		policy         string
+		expectedStatus shipper.ChartHookStatus
+		expectDeletion bool
+	}{
+		{
+			name:      "succeeded",
+			condition: "Complete",
+			policy:    hookPolicySucceeded,
+			expectedStatus: shipper.ChartHookStatus{
+				Kind: "Job", Name: "migrate", Hook: HookPreInstall, Succeeded: true,
+			},
+			expectDeletion: true,
+		},
+		{
+			name:      "succeeded without a policy",
+			condition: "Complete",
+			policy:    "",
+			expectedStatus: shipper.ChartHookStatus{
+				Kind: "Job", Name: "migrate", Hook: HookPreInstall, Succeeded: true,
+			},
+			expectDeletion: false,
+		},
+		{
+			name:      "failed",
+			condition: "Failed",
+			policy:    hookPolicyFailed,
+			expectedStatus: shipper.ChartHookStatus{
+				Kind: "Job", Name: "migrate", Hook: HookPreInstall, Message: "Job failed",
+			},
+			expectDeletion: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := buildHookInstallationTarget()
+			job := buildHookJob(it, "migrate", HookPreInstall, "", tt.policy)
+
+			existing := job.DeepCopy()
+			unstructured.SetNestedSlice(existing.Object, []interface{}{
+				map[string]interface{}{
+					"type":   tt.condition,
+					"status": "True",
+				},
+			}, "status", "conditions")
+
+			f := newHookFixture([]runtime.Object{existing})
+			installer := NewInstaller(it, nil)
+			hooks := []runtime.Object{job}
+
+			err := installer.runHooks(f.KubeClient, f.DynamicClientBuilder, hooks, HookPreInstall, DefaultChartHookTimeout)
+			if tt.expectedStatus.Succeeded && err != nil {
+				t.Fatal(err)
+			} else if _, ok := err.(shippererrors.ChartHookFailedError); !tt.expectedStatus.Succeeded && !ok {
+				t.Fatalf("expected a ChartHookFailedError, got %v", err)
+			}
+
+			expectedStatuses := []shipper.ChartHookStatus{tt.expectedStatus}
+			if len(it.Status.Hooks) != 1 || it.Status.Hooks[0] != tt.expectedStatus {
+				t.Fatalf("expected hook statuses %v, got %v", expectedStatuses, it.Status.Hooks)
+			}
+
+			expectedDynamicActions := []kubetesting.Action{}
+			if tt.expectDeletion {
+				expectedDynamicActions = append(expectedDynamicActions,
+					kubetesting.NewDeleteAction(jobGVR, shippertesting.TestNamespace, job.GetName()))
+			}
+			shippertesting.CheckActions(expectedDynamicActions, shippertesting.FilterActions(f.DynamicClient.Actions()), t)
+
+			// Running the hooks again doesn't touch the Job.
+			f.DynamicClient.ClearActions()
+			installer.runHooks(f.KubeClient, f.DynamicClientBuilder, hooks, HookPreInstall, DefaultChartHookTimeout)
+			shippertesting.CheckActions([]kubetesting.Action{}, shippertesting.FilterActions(f.DynamicClient.Actions()), t)
+		})
+	}
+}
+
+// TestRunHooksReplacesPreviousHook verifies that a hook left behind by an
+// earlier release is deleted before the hook runs again.
+func TestRunHooksReplacesPreviousHook(t *testing.T) {
+	it := buildHookInstallationTarget()
+	job := buildHookJob(it, "migrate", HookPreUpgrade, "", "")
+
+	previous := job.DeepCopy()
+	previous.SetLabels(map[string]string{
+		shipper.AppLabel:                     shippertesting.TestApp,
+		shipper.InstallationTargetOwnerLabel: "previous-release",
+	})
+
+	f := newHookFixture([]runtime.Object{previous})
+	installer := NewInstaller(it, nil)
+
+	err := installer.runHooks(f.KubeClient, f.DynamicClientBuilder, []runtime.Object{job}, HookPreUpgrade, DefaultChartHookTimeout)
+	if _, ok := err.(shippererrors.ChartHookPendingError); !ok {
+		t.Fatalf("expected a ChartHookPendingError, got %v", err)
+	}
+
+	expectedDynamicActions := []kubetesting.Action{
+		kubetesting.NewDeleteAction(jobGVR, shippertesting.TestNamespace, job.GetName()),
+	}
+	shippertesting.CheckActions(expectedDynamicActions, shippertesting.FilterActions(f.DynamicClient.Actions()), t)
+}
+
+func buildHookInstallationTarget() *shipper.InstallationTarget {
+	return buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+}
+
+func buildHookJob(it *shipper.InstallationTarget, name, hook, weight, policy string) *unstructured.Unstructured {
+	annotations := map[string]interface{}{
+		HelmHookAnnotation: hook,
+	}
+	if weight != "" {
+		annotations[HelmHookWeightAnnotation] = weight
+	}
+	if policy != "" {
+		annotations[HelmHookDeletePolicyAnnotation] = policy
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": jobGVR.GroupVersion().String(),
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   shippertesting.TestNamespace,
+				"annotations": annotations,
+				"labels": map[string]interface{}{
+					shipper.AppLabel:                     shippertesting.TestApp,
+					shipper.InstallationTargetOwnerLabel: it.Name,
+				},
+			},
+		},
+	}
+}
+
+func newHookFixture(objects []runtime.Object) *shippertesting.ControllerTestFixture {
+	f := newFixture(objects)
+	f.InitializeDiscovery(append(apiResourceList, jobAPIResourceList))
+
+	return f
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
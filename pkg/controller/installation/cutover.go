@@ -0,0 +1,37 @@
+package installation
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// dropCutOverIngresses leaves the Ingresses out of objects if it was cut
+// over from, for as long as the installation target of the release it was
+// cut over to is around, so that Ingresses torn down by the cutover aren't
+// put back.
+func (c *Controller) dropCutOverIngresses(it *shipper.InstallationTarget, objects []runtime.Object) []runtime.Object {
+	cutOverTo, ok := it.Annotations[shipper.CutOverToAnnotation]
+	if !ok {
+		return objects
+	}
+
+	_, err := c.installationTargetsLister.InstallationTargets(it.Namespace).Get(cutOverTo)
+	if err != nil {
+		return objects
+	}
+
+	installable := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		if obj.GetObjectKind().GroupVersionKind().Kind == "Ingress" {
+			klog.V(4).Infof("Not installing Ingress of InstallationTarget %s/%s, as it was cut over to %q",
+				it.Namespace, it.Name, cutOverTo)
+			continue
+		}
+
+		installable = append(installable, obj)
+	}
+
+	return installable
+}
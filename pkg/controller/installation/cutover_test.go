@@ -0,0 +1,48 @@
+package installation
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// TestDropCutOverIngresses verifies that installation targets that were cut
+// over from don't get their Ingresses back, unless the one they were cut
+// over to is gone.
+func TestDropCutOverIngresses(t *testing.T) {
+	chart := buildChart(shippertesting.TestApp, "0.0.1")
+	contender := buildInstallationTarget(shippertesting.TestNamespace, "test-app-contender", chart)
+	it := buildInstallationTarget(shippertesting.TestNamespace, "test-app-incumbent", chart)
+	it.Annotations = map[string]string{shipper.CutOverToAnnotation: contender.Name}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	c := &Controller{installationTargetsLister: shipperlisters.NewInstallationTargetLister(indexer)}
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: it.Name},
+	}
+	ingress := &networkingv1beta1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: it.Name},
+	}
+	objects := []runtime.Object{deployment, ingress}
+
+	if installable := c.dropCutOverIngresses(it, objects); len(installable) != 2 {
+		t.Errorf("expected Ingresses to be installed once the release cut over to is gone, got %v", installable)
+	}
+
+	indexer.Add(contender)
+	installable := c.dropCutOverIngresses(it, objects)
+	if len(installable) != 1 || installable[0] != deployment {
+		t.Errorf("expected only the Deployment to be installed, got %v", installable)
+	}
+}
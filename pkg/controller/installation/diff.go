@@ -0,0 +1,157 @@
+package installation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// ClusterDiff is the difference between the objects two releases install in
+// a single application cluster, as a unified diff. Diff is empty if both
+// releases install the same objects.
+type ClusterDiff struct {
+	Cluster string
+	Diff    string
+}
+
+// RenderRelease renders the objects the installation controller installs in
+// an application cluster for a release, using its pinned chart and the
+// values for that cluster. Values read from secret stores are rendered as
+// valuesource.Placeholder.
+func RenderRelease(chartFetcher shipperrepo.ChartFetcher, rel *shipper.Release, clusterName string) ([]runtime.Object, error) {
+	values := valuesource.WithPlaceholders(
+		releaseutil.GetClusterValues(rel, clusterName),
+		rel.Spec.Environment.ValuesFrom)
+
+	it := &shipper.InstallationTarget{}
+	it.Name = rel.Name
+	it.Namespace = rel.Namespace
+	it.Labels = rel.Labels
+	it.Spec = shipper.InstallationTargetSpec{
+		Chart:         rel.Spec.Environment.Chart,
+		Values:        values,
+		ImageOverride: rel.Spec.Environment.ImageOverride,
+		PrePullImages: rel.Spec.Environment.PrePullImages,
+		CanOverride:   true,
+
+		AdditionalCharts: rel.Spec.Environment.AdditionalCharts,
+	}
+
+	return FetchAndRenderChart(chartFetcher, it)
+}
+
+// DiffReleases renders both releases and returns, for every cluster either
+// of them is scheduled on, the changes rolling out to would make. A cluster
+// only one of the releases is scheduled on diffs against nothing.
+func DiffReleases(chartFetcher shipperrepo.ChartFetcher, from, to *shipper.Release) ([]ClusterDiff, error) {
+	fromClusters := clusterSet(from)
+	toClusters := clusterSet(to)
+
+	clusters := make([]string, 0, len(fromClusters)+len(toClusters))
+	for cluster := range fromClusters {
+		clusters = append(clusters, cluster)
+	}
+	for cluster := range toClusters {
+		if !fromClusters[cluster] {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+
+	diffs := make([]ClusterDiff, 0, len(clusters))
+	for _, cluster := range clusters {
+		var a, b string
+		var err error
+		if fromClusters[cluster] {
+			a, err = renderManifest(chartFetcher, from, cluster)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if toClusters[cluster] {
+			b, err = renderManifest(chartFetcher, to, cluster)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(a),
+			B:        difflib.SplitLines(b),
+			FromFile: fmt.Sprintf("%s/%s (%s)", from.Namespace, from.Name, cluster),
+			ToFile:   fmt.Sprintf("%s/%s (%s)", to.Namespace, to.Name, cluster),
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		diffs = append(diffs, ClusterDiff{Cluster: cluster, Diff: diff})
+	}
+
+	return diffs, nil
+}
+
+func clusterSet(rel *shipper.Release) map[string]bool {
+	clusters := map[string]bool{}
+	for _, cluster := range releaseutil.GetSelectedClusters(rel) {
+		clusters[cluster] = true
+	}
+	return clusters
+}
+
+// renderManifest renders a release for a cluster into a single YAML document
+// stream, with objects sorted by kind and name so the same objects always
+// come out the same way.
+func renderManifest(chartFetcher shipperrepo.ChartFetcher, rel *shipper.Release, clusterName string) (string, error) {
+	objects, err := RenderRelease(chartFetcher, rel, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	type document struct {
+		key  string
+		yaml []byte
+	}
+
+	documents := make([]document, 0, len(objects))
+	for _, obj := range objects {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return "", err
+		}
+
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return "", err
+		}
+
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		documents = append(documents, document{
+			key:  fmt.Sprintf("%s/%s", kind, m.GetName()),
+			yaml: b,
+		})
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool {
+		return documents[i].key < documents[j].key
+	})
+
+	var manifest strings.Builder
+	for _, doc := range documents {
+		fmt.Fprintf(&manifest, "---\n# %s\n", doc.key)
+		manifest.Write(doc.yaml)
+	}
+
+	return manifest.String(), nil
+}
@@ -0,0 +1,85 @@
+package installation
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildDiffRelease(name, clusters, tag string) *shipper.Release {
+	return &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: shippertesting.TestNamespace,
+			Labels: map[string]string{
+				shipper.AppLabel:            shippertesting.TestApp,
+				shipper.ReleaseLabel:        name,
+				shipper.HelmWorkaroundLabel: shipper.True,
+			},
+			Annotations: map[string]string{
+				shipper.ReleaseClustersAnnotation: clusters,
+			},
+		},
+		Spec: shipper.ReleaseSpec{
+			Environment: shipper.ReleaseEnvironment{
+				Chart: buildChart(reviewsChartName, "0.0.1"),
+				Values: shipper.ChartValues{
+					"image": map[string]interface{}{
+						"repository": "nginx",
+						"tag":        tag,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDiffReleases(t *testing.T) {
+	from := buildDiffRelease("test-app-a", "cluster-a,cluster-b", "stable")
+	to := buildDiffRelease("test-app-b", "cluster-b,cluster-c", "mainline")
+
+	diffs, err := DiffReleases(shippertesting.LocalFetchChart, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(diffs) != 3 {
+		t.Fatalf("expected a diff for 3 clusters, got %d: %v", len(diffs), diffs)
+	}
+
+	for i, cluster := range []string{"cluster-a", "cluster-b", "cluster-c"} {
+		if diffs[i].Cluster != cluster {
+			t.Errorf("expected diff %d to be for cluster %q, got %q", i, cluster, diffs[i].Cluster)
+		}
+	}
+
+	if removed := diffs[0].Diff; !strings.Contains(removed, "-  name: test-app-a") ||
+		strings.Contains(removed, "nginx:mainline") {
+		t.Errorf("expected cluster-a to only remove the incumbent's objects, got:\n%s", removed)
+	}
+
+	if changed := diffs[1].Diff; !strings.Contains(changed, "nginx:stable") ||
+		!strings.Contains(changed, "nginx:mainline") {
+		t.Errorf("expected cluster-b to change the image, got:\n%s", changed)
+	}
+
+	if added := diffs[2].Diff; !strings.Contains(added, "+  name: test-app-b") ||
+		strings.Contains(added, "nginx:stable") {
+		t.Errorf("expected cluster-c to only add the contender's objects, got:\n%s", added)
+	}
+
+	diffs, err = DiffReleases(shippertesting.LocalFetchChart, to, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, diff := range diffs {
+		if diff.Diff != "" {
+			t.Errorf("expected no changes between a release and itself in cluster %q, got:\n%s", diff.Cluster, diff.Diff)
+		}
+	}
+}
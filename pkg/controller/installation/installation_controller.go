@@ -3,16 +3,19 @@ package installation
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -21,13 +24,21 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+	"github.com/bookingcom/shipper/pkg/client"
 	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/registry"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
 	"github.com/bookingcom/shipper/pkg/util/filters"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
@@ -41,8 +52,6 @@ const (
 	ClustersNotReady = "ClustersNotReady"
 	InternalError    = "InternalError"
 	UnknownError     = "UnknownError"
-
-	InstallationTargetConditionChanged = "InstallationTargetConditionChanged"
 )
 
 // Controller is a Kubernetes controller that processes InstallationTarget
@@ -54,13 +63,86 @@ type Controller struct {
 	installationTargetsLister shipperlisters.InstallationTargetLister
 	installationTargetsSynced cache.InformerSynced
 
+	// appsClient manages pre-puller DaemonSets through the version of
+	// the apps API group the cluster serves.
+	appsClient client.AppsClient
+
+	deploymentsLister appslisters.DeploymentLister
+	deploymentsSynced cache.InformerSynced
+
+	servicesLister corelisters.ServiceLister
+	servicesSynced cache.InformerSynced
+
+	// nodesLister tells the zones of the cluster apart, for targets
+	// spreading their pods across them.
+	nodesLister corelisters.NodeLister
+	nodesSynced cache.InformerSynced
+
 	dynamicClientBuilderFunc DynamicClientBuilderFunc
 
 	workqueue workqueue.RateLimitingInterface
 
 	chartFetcher shipperrepo.ChartFetcher
 
+	// manifestCache spares installation targets that only override the
+	// image of their incumbent from fetching and rendering its chart.
+	manifestCache *manifestCache
+
+	valuesResolver *valuesource.Resolver
+
 	recorder record.EventRecorder
+
+	// syncedStates has the state hash of the last full sync of every
+	// target, to skip heartbeat syncs that wouldn't change anything.
+	syncedStates   map[string]syncedState
+	syncedStatesMu sync.Mutex
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+
+	// prePullerPauseImage is the image pre-puller pods keep running once
+	// they've pulled the images of a target.
+	prePullerPauseImage string
+
+	// chartHookTimeout is how long hook Jobs get to complete before they're
+	// considered failed. Zero waits for them forever.
+	chartHookTimeout time.Duration
+
+	// fullResyncPeriod is how long the controller can go without installing an
+	// InstallationTarget's objects again, as long as neither the target nor
+	// the Deployments and Services it installed change. Heartbeat syncs in
+	// between only record that the target was looked at. Zero installs the
+	// objects on every sync.
+	fullResyncPeriod time.Duration
+
+	// allowedRegistries lists the registries, or repository prefixes within
+	// them, such as "gcr.io/my-project", that images in charts can come from.
+	// Images in Docker Hub are prefixed by "docker.io". Any registry is allowed
+	// if empty.
+	allowedRegistries []string
+
+	// imageVerifier is optional. When set, every image in a chart must pass
+	// its verification before any of the chart's objects are installed.
+	imageVerifier registry.ImageVerifier
+}
+
+// Options are how an Installation controller installs targets, on top of
+// the clients and informers it works with. They're described on the
+// fields of Controller they end up in.
+type Options struct {
+	DrainTimeout        time.Duration
+	PrePullerPauseImage string
+	ChartHookTimeout    time.Duration
+	FullResyncPeriod    time.Duration
+	// LowPriorityMaxWait is how long targets that aren't rolling out wait
+	// for in-flight ones to be synced at most.
+	LowPriorityMaxWait time.Duration
+	AllowedRegistries  []string
+	ImageVerifier      registry.ImageVerifier
+	// AppsGroupVersion is the version of the apps API group the cluster
+	// serves.
+	AppsGroupVersion schema.GroupVersion
 }
 
 // NewController returns a new Installation controller.
@@ -72,19 +154,49 @@ func NewController(
 	dynamicClientBuilderFunc DynamicClientBuilderFunc,
 	chartFetcher shipperrepo.ChartFetcher,
 	recorder record.EventRecorder,
+	opts Options,
 ) *Controller {
 
 	itInformer := shipperInformerFactory.Shipper().V1alpha1().InstallationTargets()
+	deploymentInformer := kubeInformerFactory.Apps().V1().Deployments()
+	serviceInformer := kubeInformerFactory.Core().V1().Services()
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
 
 	controller := &Controller{
 		shipperClient:             shipperClient,
 		kubeClient:                kubeClient,
+		appsClient:                client.NewAppsClient(kubeClient, opts.AppsGroupVersion),
 		installationTargetsLister: itInformer.Lister(),
 		installationTargetsSynced: itInformer.Informer().HasSynced,
+		deploymentsLister:         deploymentInformer.Lister(),
+		deploymentsSynced:         deploymentInformer.Informer().HasSynced,
+		servicesLister:            serviceInformer.Lister(),
+		servicesSynced:            serviceInformer.Informer().HasSynced,
+		nodesLister:               nodeInformer.Lister(),
+		nodesSynced:               nodeInformer.Informer().HasSynced,
 		dynamicClientBuilderFunc:  dynamicClientBuilderFunc,
-		workqueue:                 workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "installation_controller_installationtargets"),
-		chartFetcher:              chartFetcher,
-		recorder:                  recorder,
+		workqueue: shipperworkqueue.NewPriorityQueue(
+			shipperworkqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "installation_controller_installationtargets"),
+			installationTargetInFlight(itInformer.Lister()),
+			opts.LowPriorityMaxWait,
+		),
+		chartFetcher:   chartFetcher,
+		manifestCache:  newManifestCache(),
+		valuesResolver: valuesource.NewResolver(kubeClient),
+		recorder:       recorder,
+		syncedStates:   make(map[string]syncedState),
+
+		drainTimeout: opts.DrainTimeout,
+
+		prePullerPauseImage: opts.PrePullerPauseImage,
+
+		chartHookTimeout: opts.ChartHookTimeout,
+
+		fullResyncPeriod: opts.FullResyncPeriod,
+
+		allowedRegistries: opts.AllowedRegistries,
+
+		imageVerifier: opts.ImageVerifier,
 	}
 
 	itInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -92,20 +204,23 @@ func NewController(
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			controller.enqueueInstallationTarget(newObj)
 		},
+		DeleteFunc: objectutil.OnDelete(controller.enqueueInstallationTarget),
 	})
 
 	handler := cache.FilteringResourceEventHandler{
 		FilterFunc: filters.BelongsToRelease,
 		Handler: cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.enqueueInstallationTargetFromObject,
-			DeleteFunc: controller.enqueueInstallationTargetFromObject,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueInstallationTargetFromObject),
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.enqueueInstallationTargetFromObject(newObj)
 			},
 		},
 	}
-	kubeInformerFactory.Apps().V1().Deployments().Informer().AddEventHandler(handler)
-	kubeInformerFactory.Core().V1().Services().Informer().AddEventHandler(handler)
+	deploymentInformer.Informer().AddEventHandler(handler)
+	serviceInformer.Informer().AddEventHandler(handler)
+	kubeInformerFactory.Apps().V1().DaemonSets().Informer().AddEventHandler(handler)
+	kubeInformerFactory.Batch().V1().Jobs().Informer().AddEventHandler(handler)
 
 	return controller
 }
@@ -117,23 +232,19 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 	klog.V(2).Info("Starting Installation controller")
 	defer klog.V(2).Info("Shutting down Installation controller")
 
-	if !cache.WaitForCacheSync(stopCh, c.installationTargetsSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.installationTargetsSynced, c.deploymentsSynced, c.servicesSynced, c.nodesSynced) {
 		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Installation controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(4).Info("Started Installation controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -156,11 +267,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "InstallationTarget", key)
 	err := c.syncHandler(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "InstallationTarget", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing InstallationTarget%q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -185,6 +300,7 @@ func (c *Controller) syncHandler(key string) error {
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			klog.V(3).Infof("InstallationTarget %q has been deleted", key)
+			c.recordSyncedState(key, "")
 			return nil
 		}
 
@@ -192,9 +308,41 @@ func (c *Controller) syncHandler(key string) error {
 			WithShipperKind("InstallationTarget")
 	}
 
+	// Visit the target again later even if nothing changes, so its
+	// status shows it is not stale.
+	c.workqueue.AddAfter(key, targetutil.SyncHeartbeatPeriod)
+
+	hash, err := c.stateHash(initialIT)
+	if err != nil {
+		// Without a hash, every sync is a full one.
+		runtime.HandleError(fmt.Errorf("cannot hash the state of InstallationTarget %q: %s", key, err))
+	}
+
+	if c.canSkipSync(key, hash, initialIT) {
+		klog.V(4).Infof("Nothing changed for InstallationTarget %q since its last full sync", key)
+
+		it := initialIT.DeepCopy()
+		if targetutil.RecordSync(&it.Status.TargetSyncStatus, false) {
+			_, err := c.shipperClient.ShipperV1alpha1().InstallationTargets(namespace).Update(it)
+			if err != nil {
+				return shippererrors.NewKubeclientUpdateError(it, err).
+					WithShipperKind("InstallationTarget")
+			}
+		}
+
+		return nil
+	}
+
 	it, err := c.processInstallationTarget(initialIT.DeepCopy())
+	if err != nil {
+		c.recorder.Event(it, corev1.EventTypeWarning, shipperevents.InstallationFailed, err.Error())
+		c.recordSyncedState(key, "")
+	} else {
+		c.recordSyncedState(key, hash)
+	}
 
-	if !reflect.DeepEqual(initialIT, it) {
+	changed := !reflect.DeepEqual(initialIT, it)
+	if targetutil.RecordSync(&it.Status.TargetSyncStatus, changed) {
 		// NOTE(jgreff): we can't use .UpdateStatus() because we also
 		// need to update .Spec.CanOverride
 		_, err := c.shipperClient.ShipperV1alpha1().InstallationTargets(namespace).Update(it)
@@ -273,6 +421,11 @@ func (c *Controller) processInstallationTarget(it *shipper.InstallationTarget) (
 		corev1.ConditionUnknown,
 		"",
 		"")
+	healthyCond := targetutil.NewTargetCondition(
+		shipper.TargetConditionTypeHealthy,
+		corev1.ConditionUnknown,
+		"",
+		"")
 
 	defer func() {
 		var d diffutil.Diff
@@ -283,12 +436,69 @@ func (c *Controller) processInstallationTarget(it *shipper.InstallationTarget) (
 		it.Status.Conditions, d = targetutil.SetTargetCondition(it.Status.Conditions, readyCond)
 		diff.Append(d)
 
+		it.Status.Conditions, d = targetutil.SetTargetCondition(it.Status.Conditions, healthyCond)
+		diff.Append(d)
+
+		it.Status.Conditions = targetutil.SetProgressing(it.Status.Conditions, chartProgress(it.Status.Charts))
+
 		if !diff.IsEmpty() {
-			c.recorder.Event(it, corev1.EventTypeNormal, InstallationTargetConditionChanged, diff.String())
+			c.recorder.Event(it, corev1.EventTypeNormal, shipperevents.InstallationTargetConditionChanged, diff.String())
 		}
 	}()
 
-	objects, err := FetchAndRenderChart(c.chartFetcher, it)
+	// Targets adopting objects already in the cluster have no chart to
+	// install, nor hooks to run.
+	if it.Spec.Adopt != nil {
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionTrue,
+			"",
+			"")
+
+		installer := NewInstaller(it, nil)
+		if err := installer.adopt(c.kubeClient, c.dynamicClientBuilderFunc, it.Spec.Adopt); err != nil {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				reasonForReadyCondition(err),
+				err.Error())
+
+			return it, err
+		}
+
+		it.Spec.CanOverride = false
+
+		healthyCond = c.healthyCondition(installer)
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionTrue,
+			"",
+			"")
+
+		return it, nil
+	}
+
+	renderIt := it
+	if len(it.Spec.ValuesFrom) > 0 {
+		// Values from secret stores are only ever resolved in memory,
+		// so they never make it to the installation target itself.
+		values, err := c.valuesResolver.Resolve(it.Namespace, it.Spec.Values, it.Spec.ValuesFrom)
+		if err != nil {
+			operationalCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeOperational,
+				corev1.ConditionFalse,
+				shippererrors.Reason(err),
+				err.Error())
+
+			return it, err
+		}
+
+		renderIt = it.DeepCopy()
+		renderIt.Spec.Values = values
+	}
+
+	charts, err := renderCharts(c.manifestCache.renderer(c.chartFetcher), renderIt)
+	it.Status.Charts = buildChartStatuses(it, charts, err)
 	if err != nil {
 		operationalCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeOperational,
@@ -299,25 +509,108 @@ func (c *Controller) processInstallationTarget(it *shipper.InstallationTarget) (
 		return it, err
 	}
 
+	objects, hooks := splitChartHooks(chartObjects(charts))
+	objects = c.dropCutOverIngresses(it, objects)
+
+	if it.Spec.TopologySpread != nil {
+		if err := c.spreadAcrossZones(it, objects); err != nil {
+			operationalCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeOperational,
+				corev1.ConditionFalse,
+				shippererrors.Reason(err),
+				err.Error())
+
+			return it, err
+		}
+	}
+
 	operationalCond = targetutil.NewTargetCondition(
 		shipper.TargetConditionTypeOperational,
 		corev1.ConditionTrue,
 		"",
 		"")
 
+	preHook, postHook, err := c.chartHooksFor(it)
+	if err != nil {
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			reasonForReadyCondition(err),
+			err.Error())
+
+		return it, err
+	}
+
+	// Nothing is installed, hooks included, unless all of the chart's
+	// images can be trusted.
+	if err := c.verifyImages(append(objects, hooks...)); err != nil {
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			reasonForReadyCondition(err),
+			err.Error())
+
+		return it, err
+	}
+
 	installer := NewInstaller(it, objects)
-	installerErr := installer.install(c.kubeClient, c.dynamicClientBuilderFunc)
+	installerErr := installer.runHooks(c.kubeClient, c.dynamicClientBuilderFunc, hooks, preHook, c.chartHookTimeout)
+	if installerErr == nil {
+		installerErr = installer.install(c.kubeClient, c.dynamicClientBuilderFunc)
+	}
 	if installerErr != nil {
 		readyCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeReady,
 			corev1.ConditionFalse,
-			reasonForReadyCondition(err),
+			reasonForReadyCondition(installerErr),
 			installerErr.Error())
 
-		return it, err
+		return it, installerErr
 	}
 
 	it.Spec.CanOverride = false
+
+	for i := range it.Status.Charts {
+		it.Status.Charts[i].Installed = true
+	}
+
+	healthyCond = c.healthyCondition(installer)
+
+	if err := installer.runHooks(c.kubeClient, c.dynamicClientBuilderFunc, hooks, postHook, c.chartHookTimeout); err != nil {
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			reasonForReadyCondition(err),
+			err.Error())
+
+		return it, err
+	}
+
+	if it.Spec.PrePullImages && !it.Status.ImagesPrePulled {
+		pulled, msg, err := prePullImages(c.appsClient, it, objects, c.prePullerPauseImage)
+		if err != nil {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				reasonForReadyCondition(err),
+				err.Error())
+
+			return it, err
+		}
+
+		if !pulled {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				WarmingUp,
+				msg)
+
+			return it, nil
+		}
+
+		it.Status.ImagesPrePulled = true
+	}
+
 	readyCond = targetutil.NewTargetCondition(
 		shipper.TargetConditionTypeReady,
 		corev1.ConditionTrue,
@@ -327,6 +620,98 @@ func (c *Controller) processInstallationTarget(it *shipper.InstallationTarget) (
 	return it, nil
 }
 
+// healthyCondition returns the Healthy condition of the installation target
+// whose objects installer has just installed.
+func (c *Controller) healthyCondition(installer *Installer) shipper.TargetCondition {
+	unhealthy, err := installer.unhealthyObjects(c.kubeClient)
+	if err != nil {
+		return targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeHealthy,
+			corev1.ConditionUnknown,
+			reasonForReadyCondition(err),
+			err.Error())
+	} else if len(unhealthy) > 0 {
+		return targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeHealthy,
+			corev1.ConditionFalse,
+			ObjectsNotReady,
+			healthMessage(unhealthy))
+	}
+
+	return targetutil.NewTargetCondition(
+		shipper.TargetConditionTypeHealthy,
+		corev1.ConditionTrue,
+		"",
+		"")
+}
+
+// chartHooksFor returns the Helm hooks to run before and after installing
+// the objects of an installation target. It's an upgrade if any other
+// release of the same application has been installed in this cluster, and a
+// fresh install otherwise.
+func (c *Controller) chartHooksFor(it *shipper.InstallationTarget) (string, string, error) {
+	appName, ok := it.Labels[shipper.AppLabel]
+	if !ok {
+		return HookPreInstall, HookPostInstall, nil
+	}
+
+	selector := labels.Set{shipper.AppLabel: appName}.AsSelector()
+	installationTargets, err := c.installationTargetsLister.InstallationTargets(it.Namespace).List(selector)
+	if err != nil {
+		return "", "", shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("InstallationTarget"),
+			it.Namespace, selector, err)
+	}
+
+	for _, other := range installationTargets {
+		if other.Name != it.Name {
+			return HookPreUpgrade, HookPostUpgrade, nil
+		}
+	}
+
+	return HookPreInstall, HookPostInstall, nil
+}
+
+// buildChartStatuses returns the status of every chart of an installation
+// target from the result of rendering them. The chart after the last one
+// rendered is the one that failed, if any.
+func buildChartStatuses(
+	it *shipper.InstallationTarget,
+	rendered []RenderedChart,
+	renderErr error,
+) []shipper.ChartInstallationStatus {
+	statuses := make([]shipper.ChartInstallationStatus, 0, len(it.Spec.AdditionalCharts)+1)
+	statuses = append(statuses, shipper.ChartInstallationStatus{
+		Name:    it.Spec.Chart.Name,
+		Version: it.Spec.Chart.Version,
+	})
+	for _, additional := range it.Spec.AdditionalCharts {
+		statuses = append(statuses, shipper.ChartInstallationStatus{
+			Name:    additional.Name,
+			Version: additional.Chart.Version,
+		})
+	}
+
+	if renderErr != nil && len(rendered) < len(statuses) {
+		statuses[len(rendered)].Message = renderErr.Error()
+	}
+
+	return statuses
+}
+
+// chartProgress tells how many of the charts of an installation target have
+// been installed.
+func chartProgress(charts []shipper.ChartInstallationStatus) string {
+	installed := 0
+	for _, chart := range charts {
+		if chart.Installed {
+			installed++
+		}
+	}
+
+	return fmt.Sprintf("%d/%d charts installed", installed, len(charts))
+}
+
 func reasonForReadyCondition(err error) string {
 	if shippererrors.IsKubeclientError(err) {
 		return InternalError
@@ -336,5 +721,9 @@ func reasonForReadyCondition(err error) string {
 		return ChartError
 	}
 
+	if reason := shippererrors.Reason(err); reason != shippererrors.UnknownReason {
+		return reason
+	}
+
 	return UnknownError
 }
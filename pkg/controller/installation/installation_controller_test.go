@@ -7,13 +7,18 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 type object struct {
@@ -34,7 +39,7 @@ func TestSuccess(t *testing.T) {
 		buildChart(nginxChartName, "0.1.0"))
 
 	runInstallationControllerTest(t, it, SuccessStatus,
-		buildExpectedObjects(it))
+		buildExpectedObjects(it), nil)
 }
 
 // TestInvalidChart verifies that the installation controller updates the
@@ -47,17 +52,283 @@ func TestInvalidChart(t *testing.T) {
 
 	status := shipper.InstallationTargetStatus{
 		Conditions: []shipper.TargetCondition{
+			TargetConditionHealthyUnknown,
 			{
 				Type:    shipper.TargetConditionTypeOperational,
 				Status:  corev1.ConditionFalse,
 				Reason:  ChartError,
 				Message: fmt.Sprintf(`Deployment %q has invalid name. The name of the Deployment should be templated with {{.Release.Name}}.`, reviewsChartName),
 			},
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonNotOperational, 0, 1),
 			TargetConditionReadyUnknown,
 		},
 	}
+	status.Charts = []shipper.ChartInstallationStatus{
+		{
+			Name:    reviewsChartName,
+			Version: "invalid-deployment-name",
+			Message: status.Conditions[1].Message,
+		},
+	}
+
+	f := runInstallationControllerTest(t, it, status, nil, nil)
+
+	expectedEvent := fmt.Sprintf("Warning %s %s", shipperevents.InstallationFailed, status.Conditions[1].Message)
+	found := false
+	for len(f.Recorder.Events) > 0 {
+		if <-f.Recorder.Events == expectedEvent {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected event %q to be emitted", expectedEvent)
+	}
+}
+
+// TestInvalidAdditionalChart verifies that the installation controller
+// installs nothing when an additional chart is invalid, and reports the
+// error in that chart's status.
+func TestInvalidAdditionalChart(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+	it.Spec.AdditionalCharts = []shipper.AdditionalChart{
+		{Name: "reviews", Chart: buildChart(reviewsChartName, "0.0.1")},
+	}
+
+	msg := fmt.Sprintf(`additional chart "reviews" has Service "%s-%s" with label %s=%s, but only the release's main chart can have production LB Services`,
+		shippertesting.TestApp, reviewsChartName, shipper.LBLabel, shipper.LBForProduction)
+	status := shipper.InstallationTargetStatus{
+		Conditions: []shipper.TargetCondition{
+			TargetConditionHealthyUnknown,
+			{
+				Type:    shipper.TargetConditionTypeOperational,
+				Status:  corev1.ConditionFalse,
+				Reason:  ChartError,
+				Message: msg,
+			},
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonNotOperational, 0, 2),
+			TargetConditionReadyUnknown,
+		},
+		Charts: []shipper.ChartInstallationStatus{
+			{Name: nginxChartName, Version: "0.1.0"},
+			{Name: "reviews", Version: "0.0.1", Message: msg},
+		},
+	}
+
+	runInstallationControllerTest(t, it, status, nil, nil)
+}
+
+// TestPrePullImages verifies that the installation controller doesn't report
+// readiness for targets with PrePullImages until the chart's images have been
+// pulled by a DaemonSet.
+func TestPrePullImages(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+	it.Spec.PrePullImages = true
+
+	status := shipper.InstallationTargetStatus{
+		Conditions: []shipper.TargetCondition{
+			TargetConditionHealthy,
+			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 1, 1),
+			{
+				Type:    shipper.TargetConditionTypeReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  WarmingUp,
+				Message: "pulled images on 0/0 nodes",
+			},
+		},
+		Charts: SuccessStatus.Charts,
+	}
+
+	f := runInstallationControllerTest(t, it, status, buildExpectedObjects(it), nil)
+
+	ds, err := f.KubeClient.AppsV1().DaemonSets(it.Namespace).
+		Get(fmt.Sprintf("%s-prepull", it.Name), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected a pre-puller DaemonSet, got error instead: %s", err)
+	}
+
+	initContainers := ds.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 1 || initContainers[0].Image != "nginx:stable" {
+		t.Fatalf("expected the pre-puller to pull %q, got %+v", "nginx:stable", initContainers)
+	}
+}
+
+// TestImageRegistryNotAllowed verifies that the installation controller
+// installs nothing when an image in the chart doesn't come from one of the
+// allowed registries.
+func TestImageRegistryNotAllowed(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+
+	status := shipper.InstallationTargetStatus{
+		Conditions: []shipper.TargetCondition{
+			TargetConditionHealthyUnknown,
+			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 0, 1),
+			{
+				Type:    shipper.TargetConditionTypeReady,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ImageRegistryNotAllowed",
+				Message: `image "nginx:stable" does not come from an allowed registry`,
+			},
+		},
+		Charts: []shipper.ChartInstallationStatus{
+			{Name: nginxChartName, Version: "0.1.0"},
+		},
+	}
+
+	f := runInstallationControllerTest(t, it, status, nil, []string{"gcr.io/trusted"})
+
+	deployments, err := f.DynamicClient.
+		Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).
+		Namespace(it.Namespace).
+		List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("could not list Deployments: %s", err)
+	}
+	if len(deployments.Items) > 0 {
+		t.Fatalf("expected nothing to be installed, got %d Deployments", len(deployments.Items))
+	}
+}
+
+// TestPrePullImagesDone verifies that the installation controller reports
+// readiness and removes the pre-puller DaemonSet once it's ready everywhere.
+func TestPrePullImagesDone(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+	it.Spec.PrePullImages = true
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-prepull", it.Name),
+			Namespace: it.Namespace,
+			Labels:    it.Labels,
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     1,
+			DesiredNumberScheduled: 3,
+			NumberReady:            3,
+		},
+	}
+
+	status := SuccessStatus.DeepCopy()
+	status.ImagesPrePulled = true
+
+	f := newFixture([]runtime.Object{})
+	f.KubeClient.Tracker().Add(ds)
+	f.ShipperClient.Tracker().Add(it)
+	addNginxEndpoints(f)
+
+	runController(f, 0, nil)
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	object, err := f.ShipperClient.Tracker().Get(itGVR, it.Namespace, it.Name)
+	if err != nil {
+		t.Fatalf("could not Get InstallationTarget: %s", err)
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(*status, object.(*shipper.InstallationTarget).Status)
+	if !eq {
+		t.Fatalf("InstallationTarget has Status different from expected:\n%s", diff)
+	}
 
-	runInstallationControllerTest(t, it, status, nil)
+	_, err = f.KubeClient.AppsV1().DaemonSets(it.Namespace).Get(ds.Name, metav1.GetOptions{})
+	if !kerrors.IsNotFound(err) {
+		t.Fatalf("expected the pre-puller DaemonSet to be deleted, got %v", err)
+	}
+}
+
+// TestUnhealthyObjects verifies that the installation controller reports the
+// objects that aren't ready in the Healthy condition, without holding back
+// readiness.
+func TestUnhealthyObjects(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+
+	status := SuccessStatus.DeepCopy()
+	status.Conditions[0] = shipper.TargetCondition{
+		Type:    shipper.TargetConditionTypeHealthy,
+		Status:  corev1.ConditionFalse,
+		Reason:  ObjectsNotReady,
+		Message: `Service "nginx": it has no endpoints; Service "nginx-staging": it has no endpoints`,
+	}
+
+	f := newFixture([]runtime.Object{})
+	f.ShipperClient.Tracker().Add(it)
+
+	runController(f, 0, nil)
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	object, err := f.ShipperClient.Tracker().Get(itGVR, it.Namespace, it.Name)
+	if err != nil {
+		t.Fatalf("could not Get InstallationTarget: %s", err)
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(*status, object.(*shipper.InstallationTarget).Status)
+	if !eq {
+		t.Fatalf("InstallationTarget has Status different from expected:\n%s", diff)
+	}
+}
+
+// TestSkipUnchangedSync verifies that the installation controller doesn't
+// install the objects of a ready and healthy target again when nothing
+// changed since its last full sync.
+func TestSkipUnchangedSync(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(nginxChartName, "0.1.0"))
+
+	f := newFixture([]runtime.Object{})
+	f.ShipperClient.Tracker().Add(it)
+	addNginxEndpoints(f)
+
+	controller := runController(f, time.Hour, nil)
+	key := fmt.Sprintf("%s/%s", it.Namespace, it.Name)
+
+	// The first sync updated the target's status, so wait for the
+	// informer to see it.
+	err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		cached, err := controller.installationTargetsLister.InstallationTargets(it.Namespace).Get(it.Name)
+		if err != nil {
+			return false, err
+		}
+		ready, _ := targetutil.IsReady(cached.Status.Conditions)
+		return ready, nil
+	})
+	if err != nil {
+		t.Fatalf("InstallationTarget never became ready: %s", err)
+	}
+
+	f.DynamicClient.ClearActions()
+	if err := controller.syncHandler(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if actions := f.DynamicClient.Actions(); len(actions) != 0 {
+		t.Fatalf("expected no calls to the cluster, got %d", len(actions))
+	}
+
+	controller.fullResyncPeriod = 0
+	if err := controller.syncHandler(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if actions := f.DynamicClient.Actions(); len(actions) == 0 {
+		t.Fatalf("expected a full sync once the full resync period is disabled")
+	}
 }
 
 // buildExpectedObjects returns a list of the objects we expect from
@@ -74,16 +345,34 @@ func buildExpectedObjects(it *shipper.InstallationTarget) []object {
 	}
 }
 
+// addNginxEndpoints gives the Services in `nginxChartName` endpoints, so the
+// objects installed from it are healthy.
+func addNginxEndpoints(f *shippertesting.ControllerTestFixture) {
+	for _, name := range []string{nginxChartName, fmt.Sprintf("%s-%s", nginxChartName, "staging")} {
+		f.KubeClient.Tracker().Add(&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: shippertesting.TestNamespace,
+			},
+			Subsets: []corev1.EndpointSubset{
+				{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+			},
+		})
+	}
+}
+
 func runInstallationControllerTest(
 	t *testing.T,
 	it *shipper.InstallationTarget,
 	status shipper.InstallationTargetStatus,
 	objects []object,
-) {
+	allowedRegistries []string,
+) *shippertesting.ControllerTestFixture {
 	f := newFixture([]runtime.Object{})
 	f.ShipperClient.Tracker().Add(it)
+	addNginxEndpoints(f)
 
-	runController(f)
+	runController(f, 0, allowedRegistries)
 
 	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
 	itKey := fmt.Sprintf("%s/%s", it.Namespace, it.Name)
@@ -115,9 +404,11 @@ func runInstallationControllerTest(
 				gvr.Resource, name, err)
 		}
 	}
+
+	return f
 }
 
-func runController(f *shippertesting.ControllerTestFixture) {
+func runController(f *shippertesting.ControllerTestFixture, fullResyncPeriod time.Duration, allowedRegistries []string) *Controller {
 	controller := NewController(
 		f.KubeClient,
 		f.KubeInformerFactory,
@@ -126,6 +417,15 @@ func runController(f *shippertesting.ControllerTestFixture) {
 		f.DynamicClientBuilder,
 		shippertesting.LocalFetchChart,
 		f.Recorder,
+		Options{
+			DrainTimeout:        shutdown.DefaultDrainTimeout,
+			PrePullerPauseImage: DefaultPrePullerPauseImage,
+			ChartHookTimeout:    DefaultChartHookTimeout,
+			FullResyncPeriod:    fullResyncPeriod,
+			LowPriorityMaxWait:  shipperworkqueue.DefaultLowPriorityMaxWait,
+			AllowedRegistries:   allowedRegistries,
+			AppsGroupVersion:    appsv1.SchemeGroupVersion,
+		},
 	)
 
 	stopCh := make(chan struct{})
@@ -138,7 +438,9 @@ func runController(f *shippertesting.ControllerTestFixture) {
 			time.Sleep(20 * time.Millisecond)
 		}
 		if controller.workqueue.Len() == 0 {
-			return
+			return controller
 		}
 	}
+
+	return controller
 }
@@ -15,9 +15,14 @@ import (
 	kubescheme "k8s.io/client-go/kubernetes/scheme"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 )
 
+// knativeServingGroup is the API group of Knative Services, whose traffic
+// the installer leaves to the traffic controller.
+const knativeServingGroup = "serving.knative.dev"
+
 type DynamicClientBuilderFunc func(gvk *schema.GroupVersionKind) (dynamic.Interface, error)
 
 // Installer is an object that knows how to install objects into Kubernetes
@@ -25,6 +30,10 @@ type DynamicClientBuilderFunc func(gvk *schema.GroupVersionKind) (dynamic.Interf
 type Installer struct {
 	installationTarget *shipper.InstallationTarget
 	objects            []runtime.Object
+
+	// installed has the objects the last install left in the cluster,
+	// as they were in the cluster.
+	installed []*unstructured.Unstructured
 }
 
 // NewInstaller returns a new Installer.
@@ -38,6 +47,18 @@ func NewInstaller(
 	}
 }
 
+// ownerReference returns the owner reference of the objects the installer
+// creates.
+func (i *Installer) ownerReference() metav1.OwnerReference {
+	it := i.installationTarget
+	return metav1.OwnerReference{
+		APIVersion: shipper.SchemeGroupVersion.String(),
+		Kind:       "InstallationTarget",
+		Name:       it.Name,
+		UID:        it.UID,
+	}
+}
+
 // buildResourceClient returns a ResourceClient suitable to manipulate the kind
 // of resource represented by the given GroupVersionKind at the given Cluster.
 func (i *Installer) buildResourceClient(
@@ -94,13 +115,8 @@ func (i *Installer) install(
 	dynamicClientBuilderFunc DynamicClientBuilderFunc,
 ) error {
 	it := i.installationTarget
-
-	ownerReference := metav1.OwnerReference{
-		APIVersion: shipper.SchemeGroupVersion.String(),
-		Kind:       "InstallationTarget",
-		Name:       it.Name,
-		UID:        it.UID,
-	}
+	ownerReference := i.ownerReference()
+	i.installed = nil
 
 	anchorName := fmt.Sprintf("%s-anchor", it.Name)
 	anchorConfigMap, err := client.CoreV1().
@@ -119,118 +135,308 @@ func (i *Installer) install(
 		}
 	}
 
-	resourceClients := make(map[string]dynamic.ResourceInterface)
+	// CustomResourceDefinitions go in first, and the custom resources
+	// the chart creates with them have to wait until they're
+	// established, or the API server won't know about their kinds.
+	crds, objects, err := splitCustomResourceDefinitions(i.objects)
+	if err != nil {
+		return err
+	}
 
-	for _, preparedObj := range i.objects {
-		obj := &unstructured.Unstructured{}
-		err = kubescheme.Scheme.Convert(preparedObj, obj, nil)
-		if err != nil {
-			return shippererrors.NewConvertUnstructuredError("error converting object to unstructured: %s", err)
+	customKinds := make(map[schema.GroupKind]string)
+	getResourceClient := i.resourceClientGetter(client, dynamicClientBuilderFunc, customKinds)
+
+	// CustomResourceDefinitions are cluster-scoped and can't be owned by
+	// the namespaced anchor, and we wouldn't want the garbage collector to
+	// take every custom resource in the cluster with them anyway.
+	for _, crd := range crds {
+		if _, err := i.installObject(getResourceClient, crd, nil); err != nil {
+			return err
 		}
 
-		name := obj.GetName()
-		namespace := obj.GetNamespace()
-		gvk := obj.GroupVersionKind()
-
-		resourceClient, ok := resourceClients[gvk.String()]
-		if !ok {
-			var err error
-			resourceClient, err = i.buildResourceClient(
-				client,
-				dynamicClientBuilderFunc,
-				&gvk,
-			)
-			if err != nil {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		customKinds[schema.GroupKind{Group: group, Kind: kind}] = crd.GetName()
+	}
+
+	for _, crd := range crds {
+		if err := checkEstablished(getResourceClient, crd); err != nil {
+			return err
+		}
+	}
+
+	// Objects from every chart go in by kind, in install order, so
+	// additional charts don't get to create, say, a Deployment before
+	// the main chart's ConfigMaps.
+	sort.SliceStable(objects, func(a, b int) bool {
+		return shipperchart.InstallOrder.Index(objects[a].GetKind()) <
+			shipperchart.InstallOrder.Index(objects[b].GetKind())
+	})
+
+	barriers := make(map[string]struct{}, len(it.Spec.ReadinessBarriers))
+	for _, kind := range it.Spec.ReadinessBarriers {
+		barriers[kind] = struct{}{}
+	}
+
+	var waitingOn []*unstructured.Unstructured
+	for _, obj := range objects {
+		if err := useServedVersion(client, obj); err != nil {
+			return err
+		}
+
+		order := shipperchart.InstallOrder.Index(obj.GetKind())
+		for len(waitingOn) > 0 && shipperchart.InstallOrder.Index(waitingOn[0].GetKind()) < order {
+			if err := checkReady(getResourceClient, waitingOn[0]); err != nil {
 				return err
 			}
 
-			resourceClients[gvk.String()] = resourceClient
+			waitingOn = waitingOn[1:]
 		}
 
-		// "fetch-and-create-or-update" strategy in here; this is required to
-		// overcome an issue in Kubernetes where a "create-or-update" strategy
-		// leads to exceeding quotas when those are enabled very quickly,
-		// since Kubernetes machinery first increase quota usage and then
-		// attempts to create the resource, taking some time to re-sync
-		// the quota information when objects can't be created since they
-		// already exist.
-		existingObj, err := resourceClient.Get(name, metav1.GetOptions{})
-
-		// Any error other than NotFound is not recoverable from this point on.
-		if err != nil && !errors.IsNotFound(err) {
-			return shippererrors.
-				NewKubeclientGetError(namespace, name, err).
-				WithKind(gvk)
+		installed, err := i.installObject(getResourceClient, obj, &ownerReference)
+		if err != nil {
+			return err
+		}
+
+		i.installed = append(i.installed, installed)
+
+		if _, ok := barriers[obj.GetKind()]; ok {
+			waitingOn = append(waitingOn, obj)
+		}
+	}
+
+	return nil
+}
+
+// resourceClientGetter returns a function that builds resource clients for
+// the kinds of objects the installer comes across, reusing them for objects
+// of the same kind. customKinds has the names of the chart's
+// CustomResourceDefinitions by the kind of their custom resources.
+func (i *Installer) resourceClientGetter(
+	client kubernetes.Interface,
+	dynamicClientBuilderFunc DynamicClientBuilderFunc,
+	customKinds map[schema.GroupKind]string,
+) func(schema.GroupVersionKind) (dynamic.ResourceInterface, error) {
+	resourceClients := make(map[string]dynamic.ResourceInterface)
+	return func(gvk schema.GroupVersionKind) (dynamic.ResourceInterface, error) {
+		if resourceClient, ok := resourceClients[gvk.String()]; ok {
+			return resourceClient, nil
 		}
 
-		// If have an error here, it means it is NotFound, so proceed to
-		// create the object on the application cluster.
+		resourceClient, err := i.buildResourceClient(client, dynamicClientBuilderFunc, &gvk)
 		if err != nil {
-			obj.SetOwnerReferences([]metav1.OwnerReference{ownerReference})
-			_, err = resourceClient.Create(obj, metav1.CreateOptions{})
-			if err != nil {
-				return shippererrors.
-					NewKubeclientCreateError(obj, err).
-					WithKind(gvk)
+			// Discovery can take a moment to catch up with a
+			// CustomResourceDefinition that was just established.
+			if crdName, ok := customKinds[gvk.GroupKind()]; ok {
+				return nil, shippererrors.NewCustomResourceDefinitionNotEstablishedError(crdName)
 			}
-			continue
-		}
 
-		// We inject a Namespace object in the objects to be installed
-		// for a particular InstallationTarget; we don't want to
-		// continue if the Namespace already exists.
-		if gvk.Kind == "Namespace" {
-			continue
+			return nil, err
 		}
 
-		shouldUpdate, err := shouldUpdateObject(it, existingObj)
+		resourceClients[gvk.String()] = resourceClient
+
+		return resourceClient, nil
+	}
+}
+
+// splitCustomResourceDefinitions converts the objects to install to
+// unstructured, and splits the CustomResourceDefinitions from the rest.
+func splitCustomResourceDefinitions(preparedObjs []runtime.Object) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	var crds, objects []*unstructured.Unstructured
+	for _, preparedObj := range preparedObjs {
+		obj, err := toUnstructured(preparedObj)
 		if err != nil {
-			return err
-		} else if !shouldUpdate {
-			continue
+			return nil, nil, err
 		}
 
-		ownerReferenceFound := false
-		for _, o := range existingObj.GetOwnerReferences() {
-			if reflect.DeepEqual(o, ownerReference) {
-				ownerReferenceFound = true
-			}
+		if shipperchart.IsCustomResourceDefinition(obj.GroupVersionKind()) {
+			crds = append(crds, obj)
+		} else {
+			objects = append(objects, obj)
 		}
-		if !ownerReferenceFound {
-			ownerReferences := append(existingObj.GetOwnerReferences(), ownerReference)
-			sort.Slice(ownerReferences, func(i, j int) bool {
-				return ownerReferences[i].Name < ownerReferences[j].Name
-			})
-			existingObj.SetOwnerReferences(ownerReferences)
+	}
+
+	return crds, objects, nil
+}
+
+func toUnstructured(preparedObj runtime.Object) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	err := kubescheme.Scheme.Convert(preparedObj, obj, nil)
+	if err != nil {
+		return nil, shippererrors.NewConvertUnstructuredError("error converting object to unstructured: %s", err)
+	}
+
+	return obj, nil
+}
+
+// checkEstablished returns an error unless the API server serves the custom
+// resources of crd.
+func checkEstablished(
+	getResourceClient func(schema.GroupVersionKind) (dynamic.ResourceInterface, error),
+	crd *unstructured.Unstructured,
+) error {
+	gvk := crd.GroupVersionKind()
+	resourceClient, err := getResourceClient(gvk)
+	if err != nil {
+		return err
+	}
+
+	existing, err := resourceClient.Get(crd.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return shippererrors.NewKubeclientGetError("", crd.GetName(), err).
+			WithKind(gvk)
+	}
+
+	if !conditionTrue(existing, "Established") {
+		return shippererrors.NewCustomResourceDefinitionNotEstablishedError(crd.GetName())
+	}
+
+	return nil
+}
+
+// checkReady returns an error unless obj, as it is in the application
+// cluster, is ready.
+func checkReady(
+	getResourceClient func(schema.GroupVersionKind) (dynamic.ResourceInterface, error),
+	obj *unstructured.Unstructured,
+) error {
+	gvk := obj.GroupVersionKind()
+	resourceClient, err := getResourceClient(gvk)
+	if err != nil {
+		return err
+	}
+
+	existing, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return shippererrors.NewKubeclientGetError(obj.GetNamespace(), obj.GetName(), err).
+			WithKind(gvk)
+	}
+
+	if ready, msg := objectReady(existing); !ready {
+		return shippererrors.NewReadinessBarrierError(gvk.Kind, obj.GetName(), msg)
+	}
+
+	return nil
+}
+
+// installObject creates obj in the application cluster, or updates the
+// existing one if the installation target is allowed to, and returns the
+// object as it is in the cluster. A nil ownerReference leaves the object's
+// owner references alone.
+func (i *Installer) installObject(
+	getResourceClient func(schema.GroupVersionKind) (dynamic.ResourceInterface, error),
+	obj *unstructured.Unstructured,
+	ownerReference *metav1.OwnerReference,
+) (*unstructured.Unstructured, error) {
+	it := i.installationTarget
+
+	name := obj.GetName()
+	namespace := obj.GetNamespace()
+	gvk := obj.GroupVersionKind()
+
+	resourceClient, err := getResourceClient(gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	// "fetch-and-create-or-update" strategy in here; this is required to
+	// overcome an issue in Kubernetes where a "create-or-update" strategy
+	// leads to exceeding quotas when those are enabled very quickly,
+	// since Kubernetes machinery first increase quota usage and then
+	// attempts to create the resource, taking some time to re-sync
+	// the quota information when objects can't be created since they
+	// already exist.
+	existingObj, err := resourceClient.Get(name, metav1.GetOptions{})
+
+	// Any error other than NotFound is not recoverable from this point on.
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, shippererrors.
+			NewKubeclientGetError(namespace, name, err).
+			WithKind(gvk)
+	}
+
+	// If have an error here, it means it is NotFound, so proceed to
+	// create the object on the application cluster.
+	if err != nil {
+		if ownerReference != nil {
+			obj.SetOwnerReferences([]metav1.OwnerReference{*ownerReference})
 		}
+		createdObj, err := resourceClient.Create(obj, metav1.CreateOptions{})
+		if err != nil {
+			return nil, shippererrors.
+				NewKubeclientCreateError(obj, err).
+				WithKind(gvk)
+		}
+		return createdObj, nil
+	}
 
-		existingObj.SetLabels(obj.GetLabels())
-		existingObj.SetAnnotations(obj.GetAnnotations())
-		existingUnstructuredObj := existingObj.UnstructuredContent()
-		newUnstructuredObj := obj.UnstructuredContent()
+	// We inject a Namespace object in the objects to be installed
+	// for a particular InstallationTarget; we don't want to
+	// continue if the Namespace already exists.
+	if gvk.Kind == "Namespace" {
+		return existingObj, nil
+	}
 
-		if gvk.Kind == "Service" {
-			// Copy over clusterIP from existing object's .spec to
-			// the rendered one.
-			if clusterIP, ok, err := unstructured.NestedString(existingUnstructuredObj, "spec", "clusterIP"); ok {
-				if err != nil {
-					return err
-				}
+	shouldUpdate, err := shouldUpdateObject(it, existingObj)
+	if err != nil {
+		return nil, err
+	} else if !shouldUpdate {
+		return existingObj, nil
+	}
 
-				unstructured.SetNestedField(newUnstructuredObj, clusterIP, "spec", "clusterIP")
-			}
+	ownerReferenceFound := ownerReference == nil
+	for _, o := range existingObj.GetOwnerReferences() {
+		if ownerReference != nil && reflect.DeepEqual(o, *ownerReference) {
+			ownerReferenceFound = true
 		}
+	}
+	if !ownerReferenceFound {
+		ownerReferences := append(existingObj.GetOwnerReferences(), *ownerReference)
+		sort.Slice(ownerReferences, func(i, j int) bool {
+			return ownerReferences[i].Name < ownerReferences[j].Name
+		})
+		existingObj.SetOwnerReferences(ownerReferences)
+	}
 
-		unstructured.SetNestedField(existingUnstructuredObj, newUnstructuredObj["spec"], "spec")
-		existingObj.SetUnstructuredContent(existingUnstructuredObj)
+	existingObj.SetLabels(obj.GetLabels())
+	existingObj.SetAnnotations(keepReleaseAnnotations(existingObj.GetAnnotations(), obj.GetAnnotations()))
+	existingUnstructuredObj := existingObj.UnstructuredContent()
+	newUnstructuredObj := obj.UnstructuredContent()
 
-		if _, err := resourceClient.Update(existingObj, metav1.UpdateOptions{}); err != nil {
-			return shippererrors.NewKubeclientUpdateError(obj, err).
-				WithKind(gvk)
+	if gvk.Group == knativeServingGroup && gvk.Kind == "Service" {
+		// Traffic between the revisions of Knative Services is
+		// shifted by the traffic controller, so the rendered
+		// traffic block would undo its work.
+		if traffic, ok, err := unstructured.NestedSlice(existingUnstructuredObj, "spec", "traffic"); ok {
+			if err != nil {
+				return nil, err
+			}
+
+			unstructured.SetNestedSlice(newUnstructuredObj, traffic, "spec", "traffic")
+		}
+	} else if gvk.Kind == "Service" {
+		// Copy over clusterIP from existing object's .spec to
+		// the rendered one.
+		if clusterIP, ok, err := unstructured.NestedString(existingUnstructuredObj, "spec", "clusterIP"); ok {
+			if err != nil {
+				return nil, err
+			}
+
+			unstructured.SetNestedField(newUnstructuredObj, clusterIP, "spec", "clusterIP")
 		}
 	}
 
-	return nil
+	unstructured.SetNestedField(existingUnstructuredObj, newUnstructuredObj["spec"], "spec")
+	existingObj.SetUnstructuredContent(existingUnstructuredObj)
+
+	updatedObj, err := resourceClient.Update(existingObj, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, shippererrors.NewKubeclientUpdateError(obj, err).
+			WithKind(gvk)
+	}
+
+	return updatedObj, nil
 }
 
 // shouldUpdateObject detects whether the current iteration of the installer
@@ -274,3 +480,36 @@ func shouldUpdateObject(it *shipper.InstallationTarget, obj *unstructured.Unstru
 
 	return true, nil
 }
+
+// releaseAnnotations are set by the release controller on installed
+// objects once their release completes its rollout or is cut over to.
+var releaseAnnotations = []string{
+	shipper.CompletedReleaseAnnotation,
+	shipper.CompletedVersionAnnotation,
+	shipper.CompletedAtAnnotation,
+	shipper.ExternalDNSHostnameAnnotation,
+}
+
+// keepReleaseAnnotations returns the annotations of a rendered object,
+// along with the release annotations of the existing one, so that
+// installing it again doesn't erase them.
+func keepReleaseAnnotations(existing, rendered map[string]string) map[string]string {
+	annotations := make(map[string]string, len(rendered))
+	for k, v := range rendered {
+		annotations[k] = v
+	}
+
+	for _, k := range releaseAnnotations {
+		if v, ok := existing[k]; ok {
+			if _, ok := annotations[k]; !ok {
+				annotations[k] = v
+			}
+		}
+	}
+
+	if len(annotations) == 0 {
+		return rendered
+	}
+
+	return annotations
+}
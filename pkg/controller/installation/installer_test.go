@@ -14,6 +14,7 @@ import (
 	kubetesting "k8s.io/client-go/testing"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
 )
 
@@ -261,3 +262,372 @@ func runInstallerTest(
 
 	return f
 }
+
+// TestInstallerCustomResourceDefinitionNotEstablished verifies that the
+// installer creates a chart's CustomResourceDefinitions first, and doesn't
+// create any custom resources until the API server serves them.
+func TestInstallerCustomResourceDefinitionNotEstablished(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+
+	crd := buildWidgetCRD(it, false)
+	installer := NewInstaller(it, []runtime.Object{crd.DeepCopy(), buildWidget()})
+
+	f := newFixture(nil)
+	f.InitializeDiscovery(append(apiResourceList, crdAPIResourceList))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	err := installer.install(f.KubeClient, f.DynamicClientBuilder)
+	if _, ok := err.(shippererrors.CustomResourceDefinitionNotEstablishedError); !ok {
+		t.Fatalf("expected a CustomResourceDefinitionNotEstablishedError, got %v", err)
+	}
+
+	expectedDynamicActions := []kubetesting.Action{
+		kubetesting.NewRootCreateAction(crdGVR, crd),
+	}
+
+	filteredActions := shippertesting.FilterActions(f.DynamicClient.Actions())
+	shippertesting.CheckActions(expectedDynamicActions, filteredActions, t)
+}
+
+// TestInstallerCustomResourceDefinitionEstablished verifies that the
+// installer creates custom resources once their CustomResourceDefinition has
+// been established.
+func TestInstallerCustomResourceDefinitionEstablished(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+
+	crd := buildWidgetCRD(it, true)
+	widget := buildWidget()
+	installer := NewInstaller(it, []runtime.Object{crd.DeepCopy(), widget.DeepCopy()})
+
+	f := newFixture([]runtime.Object{crd})
+	f.InitializeDiscovery(append(apiResourceList, crdAPIResourceList, widgetAPIResourceList))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	if err := installer.install(f.KubeClient, f.DynamicClientBuilder); err != nil {
+		t.Fatal(err)
+	}
+
+	widget.SetOwnerReferences([]metav1.OwnerReference{buildInstallationTargetOwnerRef(it)})
+	expectedDynamicActions := []kubetesting.Action{
+		kubetesting.NewCreateAction(widgetGVR, shippertesting.TestNamespace, widget),
+	}
+
+	filteredActions := shippertesting.FilterActions(f.DynamicClient.Actions())
+	shippertesting.CheckActions(expectedDynamicActions, filteredActions, t)
+}
+
+// TestInstallerLegacyAppsVersion verifies that apps/v1 objects are installed
+// through apps/v1beta2 in clusters that don't serve apps/v1 yet, and that
+// objects the cluster serves in no compatible version fail to install.
+func TestInstallerLegacyAppsVersion(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetKind("Deployment")
+	deployment.SetNamespace(shippertesting.TestNamespace)
+	deployment.SetName("reviews-api")
+
+	legacyAppsResourceList := &metav1.APIResourceList{
+		GroupVersion: "apps/v1beta2",
+		APIResources: []metav1.APIResource{
+			{
+				Kind:       "Deployment",
+				Namespaced: true,
+				Name:       "deployments",
+			},
+		},
+	}
+
+	installer := NewInstaller(it, []runtime.Object{deployment.DeepCopy()})
+
+	f := newFixture(nil)
+	f.InitializeDiscovery([]*metav1.APIResourceList{apiResourceList[0], legacyAppsResourceList})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	f.Run(stopCh)
+
+	if err := installer.install(f.KubeClient, f.DynamicClientBuilder); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := deployment.DeepCopy()
+	expected.SetAPIVersion("apps/v1beta2")
+	expected.SetOwnerReferences([]metav1.OwnerReference{buildInstallationTargetOwnerRef(it)})
+	legacyDeploymentGVR := schema.GroupVersionResource{Group: "apps", Version: "v1beta2", Resource: "deployments"}
+	expectedDynamicActions := []kubetesting.Action{
+		kubetesting.NewCreateAction(legacyDeploymentGVR, shippertesting.TestNamespace, expected),
+	}
+
+	filteredActions := shippertesting.FilterActions(f.DynamicClient.Actions())
+	shippertesting.CheckActions(expectedDynamicActions, filteredActions, t)
+
+	f.InitializeDiscovery([]*metav1.APIResourceList{apiResourceList[0]})
+	installer = NewInstaller(it, []runtime.Object{deployment.DeepCopy()})
+	err := installer.install(f.KubeClient, f.DynamicClientBuilder)
+	if _, ok := err.(shippererrors.APIVersionNotServedError); !ok {
+		t.Fatalf("expected an APIVersionNotServedError, got %v", err)
+	}
+}
+
+var (
+	crdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1beta1",
+		Resource: "customresourcedefinitions",
+	}
+	widgetGVR = schema.GroupVersionResource{
+		Group:    "example.com",
+		Version:  "v1",
+		Resource: "widgets",
+	}
+
+	crdAPIResourceList = &metav1.APIResourceList{
+		GroupVersion: crdGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{
+			{
+				Kind:       "CustomResourceDefinition",
+				Namespaced: false,
+				Name:       crdGVR.Resource,
+			},
+		},
+	}
+	widgetAPIResourceList = &metav1.APIResourceList{
+		GroupVersion: widgetGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{
+			{
+				Kind:       "Widget",
+				Namespaced: true,
+				Name:       widgetGVR.Resource,
+			},
+		},
+	}
+)
+
+func buildWidgetCRD(it *shipper.InstallationTarget, established bool) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": crdGVR.GroupVersion().String(),
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "widgets.example.com",
+				"labels": map[string]interface{}{
+					shipper.AppLabel:                     shippertesting.TestApp,
+					shipper.InstallationTargetOwnerLabel: it.Name,
+				},
+			},
+			"spec": map[string]interface{}{
+				"group":   widgetGVR.Group,
+				"version": widgetGVR.Version,
+				"scope":   "Namespaced",
+				"names": map[string]interface{}{
+					"kind":   "Widget",
+					"plural": widgetGVR.Resource,
+				},
+			},
+		},
+	}
+
+	if established {
+		unstructured.SetNestedSlice(crd.Object, []interface{}{
+			map[string]interface{}{
+				"type":   "Established",
+				"status": "True",
+			},
+		}, "status", "conditions")
+	}
+
+	return crd
+}
+
+func buildWidget() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": widgetGVR.GroupVersion().String(),
+			"kind":       "Widget",
+			"metadata": map[string]interface{}{
+				"name":      "test-widget",
+				"namespace": shippertesting.TestNamespace,
+			},
+		},
+	}
+}
+
+// TestInstallerReadinessBarrier verifies that the installer doesn't install
+// anything after a readiness barrier kind until its objects are ready.
+func TestInstallerReadinessBarrier(t *testing.T) {
+	tests := []struct {
+		name            string
+		jobComplete     bool
+		expectedActions func(it *shipper.InstallationTarget, job, deployment *unstructured.Unstructured) []kubetesting.Action
+	}{
+		{
+			name:        "job not complete",
+			jobComplete: false,
+			expectedActions: func(it *shipper.InstallationTarget, job, _ *unstructured.Unstructured) []kubetesting.Action {
+				job.SetOwnerReferences([]metav1.OwnerReference{buildInstallationTargetOwnerRef(it)})
+				return []kubetesting.Action{
+					kubetesting.NewCreateAction(jobGVR, shippertesting.TestNamespace, job),
+				}
+			},
+		},
+		{
+			name:        "job complete",
+			jobComplete: true,
+			expectedActions: func(it *shipper.InstallationTarget, _, deployment *unstructured.Unstructured) []kubetesting.Action {
+				deployment.SetOwnerReferences([]metav1.OwnerReference{buildInstallationTargetOwnerRef(it)})
+				return []kubetesting.Action{
+					kubetesting.NewCreateAction(deploymentGVR, shippertesting.TestNamespace, deployment),
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := buildInstallationTarget(
+				shippertesting.TestNamespace,
+				shippertesting.TestApp,
+				buildChart(reviewsChartName, "0.0.1"))
+			it.Spec.ReadinessBarriers = []string{"Job"}
+
+			job := buildMigrationJob(it)
+			deployment := buildDeployment(it)
+
+			// The Deployment comes first, so this also checks that
+			// objects get installed in order.
+			installer := NewInstaller(it, []runtime.Object{deployment.DeepCopy(), job.DeepCopy()})
+
+			var existing []runtime.Object
+			if tt.jobComplete {
+				completedJob := job.DeepCopy()
+				unstructured.SetNestedSlice(completedJob.Object, []interface{}{
+					map[string]interface{}{
+						"type":   "Complete",
+						"status": "True",
+					},
+				}, "status", "conditions")
+				existing = append(existing, completedJob)
+			}
+
+			f := newFixture(existing)
+			f.InitializeDiscovery(append(apiResourceList, jobAPIResourceList))
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			f.Run(stopCh)
+
+			err := installer.install(f.KubeClient, f.DynamicClientBuilder)
+			if tt.jobComplete && err != nil {
+				t.Fatal(err)
+			} else if _, ok := err.(shippererrors.ReadinessBarrierError); !tt.jobComplete && !ok {
+				t.Fatalf("expected a ReadinessBarrierError, got %v", err)
+			}
+
+			expectedDynamicActions := tt.expectedActions(it, job, deployment)
+			filteredActions := shippertesting.FilterActions(f.DynamicClient.Actions())
+			shippertesting.CheckActions(expectedDynamicActions, filteredActions, t)
+		})
+	}
+}
+
+var (
+	jobGVR        = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	jobAPIResourceList = &metav1.APIResourceList{
+		GroupVersion: jobGVR.GroupVersion().String(),
+		APIResources: []metav1.APIResource{
+			{
+				Kind:       "Job",
+				Namespaced: true,
+				Name:       jobGVR.Resource,
+			},
+		},
+	}
+)
+
+func buildMigrationJob(it *shipper.InstallationTarget) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": jobGVR.GroupVersion().String(),
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      "test-migration",
+				"namespace": shippertesting.TestNamespace,
+				"labels": map[string]interface{}{
+					shipper.AppLabel:                     shippertesting.TestApp,
+					shipper.InstallationTargetOwnerLabel: it.Name,
+				},
+			},
+		},
+	}
+}
+
+func buildDeployment(it *shipper.InstallationTarget) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": deploymentGVR.GroupVersion().String(),
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      it.Name,
+				"namespace": shippertesting.TestNamespace,
+				"labels": map[string]interface{}{
+					shipper.AppLabel:                     shippertesting.TestApp,
+					shipper.InstallationTargetOwnerLabel: it.Name,
+				},
+			},
+		},
+	}
+}
+
+// TestKeepReleaseAnnotations verifies that installing objects again keeps
+// the annotations the release controller set on them, and drops any other
+// annotation the rendered objects don't have.
+func TestKeepReleaseAnnotations(t *testing.T) {
+	existing := map[string]string{
+		shipper.CompletedReleaseAnnotation:    "test-app-deadbeef-0",
+		shipper.CompletedAtAnnotation:         "2020-01-01T00:00:00Z",
+		shipper.ExternalDNSHostnameAnnotation: "test-app.example.com",
+		"edited-by-hand":                      "true",
+	}
+	rendered := map[string]string{
+		"from-chart": "true",
+	}
+
+	expected := map[string]string{
+		shipper.CompletedReleaseAnnotation:    "test-app-deadbeef-0",
+		shipper.CompletedAtAnnotation:         "2020-01-01T00:00:00Z",
+		shipper.ExternalDNSHostnameAnnotation: "test-app.example.com",
+		"from-chart":                          "true",
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, keepReleaseAnnotations(existing, rendered))
+	if !eq {
+		t.Errorf("unexpected annotations:\n%s", diff)
+	}
+
+	if keepReleaseAnnotations(nil, nil) != nil {
+		t.Errorf("expected objects without annotations to be left without any")
+	}
+}
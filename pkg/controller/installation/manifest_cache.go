@@ -0,0 +1,99 @@
+package installation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
+)
+
+// manifestRenderer renders a chart of an installation target with values.
+type manifestRenderer func(chartspec *shipper.Chart, it *shipper.InstallationTarget, values shipper.ChartValues) ([]string, error)
+
+// renderedManifests are the manifests a chart rendered to for a release.
+type renderedManifests struct {
+	release   string
+	chart     shipper.Chart
+	values    shipper.ChartValues
+	manifests []string
+}
+
+// manifestCache keeps the manifests each chart of an application last
+// rendered to, so that releases that only override the image of their
+// incumbent can be installed without fetching and rendering its chart
+// again.
+type manifestCache struct {
+	mu      sync.Mutex
+	entries map[string]renderedManifests
+}
+
+func newManifestCache() *manifestCache {
+	return &manifestCache{
+		entries: make(map[string]renderedManifests),
+	}
+}
+
+// renderer returns a manifestRenderer that reuses cached manifests for
+// installation targets with an image override, and fetches and renders
+// charts with chartFetcher otherwise.
+func (mc *manifestCache) renderer(chartFetcher shipperrepo.ChartFetcher) manifestRenderer {
+	return func(chartspec *shipper.Chart, it *shipper.InstallationTarget, values shipper.ChartValues) ([]string, error) {
+		key := manifestCacheKey(chartspec, it)
+
+		if it.Spec.ImageOverride != nil {
+			if manifests, ok := mc.get(key, chartspec, it, values); ok {
+				return manifests, nil
+			}
+		}
+
+		manifests, err := fetchAndRenderManifests(chartFetcher, chartspec, it, values)
+		if err != nil {
+			return nil, err
+		}
+
+		mc.set(key, renderedManifests{
+			release:   it.Name,
+			chart:     *chartspec,
+			values:    values.DeepCopy(),
+			manifests: manifests,
+		})
+
+		return manifests, nil
+	}
+}
+
+// get returns the manifests last rendered for the chart of it's application,
+// if they were rendered from the same chart and values. Charts name
+// everything after the release they render for, so that's all that differs
+// from the manifests the chart would render for it.
+func (mc *manifestCache) get(key string, chartspec *shipper.Chart, it *shipper.InstallationTarget, values shipper.ChartValues) ([]string, bool) {
+	mc.mu.Lock()
+	entry, ok := mc.entries[key]
+	mc.mu.Unlock()
+
+	if !ok || entry.chart != *chartspec || !equality.Semantic.DeepEqual(entry.values, values) {
+		return nil, false
+	}
+
+	manifests := make([]string, 0, len(entry.manifests))
+	for _, manifest := range entry.manifests {
+		manifests = append(manifests, strings.Replace(manifest, entry.release, it.Name, -1))
+	}
+
+	return manifests, true
+}
+
+func (mc *manifestCache) set(key string, entry renderedManifests) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.entries[key] = entry
+}
+
+func manifestCacheKey(chartspec *shipper.Chart, it *shipper.InstallationTarget) string {
+	return fmt.Sprintf("%s/%s/%s", it.Namespace, it.Labels[shipper.AppLabel], chartspec.Name)
+}
@@ -0,0 +1,149 @@
+package installation
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/client"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	WarmingUp = "WarmingUp"
+
+	// PrePullerLabel selects the pods of the DaemonSet pulling the images
+	// of an InstallationTarget.
+	PrePullerLabel = "shipper-prepull"
+)
+
+// DefaultPrePullerPauseImage is the image the pre-puller pods keep running
+// once they've pulled the chart's images, until the DaemonSet is removed,
+// unless the controller is given another one.
+const DefaultPrePullerPauseImage = "k8s.gcr.io/pause:3.1"
+
+var daemonSetGVK = appsv1.SchemeGroupVersion.WithKind("DaemonSet")
+
+// prePullImages makes sure the images of the Deployments in objects are
+// present on every node, by running a DaemonSet with one init container per
+// image. It returns true once they all are, after removing the DaemonSet,
+// and otherwise a message saying how far along the pull is.
+func prePullImages(
+	appsClient client.AppsClient,
+	it *shipper.InstallationTarget,
+	objects []runtime.Object,
+	pauseImage string,
+) (bool, string, error) {
+	desired := buildPrePuller(it, objects, pauseImage)
+	if desired == nil {
+		return true, "", nil
+	}
+
+	ds, err := appsClient.GetDaemonSet(it.Namespace, desired.Name)
+	if errors.IsNotFound(err) {
+		_, err := appsClient.CreateDaemonSet(desired)
+		if err != nil {
+			return false, "", shippererrors.NewKubeclientCreateError(desired, err).
+				WithKind(daemonSetGVK)
+		}
+
+		return false, "pulling images", nil
+	} else if err != nil {
+		return false, "", shippererrors.NewKubeclientGetError(it.Namespace, desired.Name, err).
+			WithKind(daemonSetGVK)
+	}
+
+	status := ds.Status
+	if status.ObservedGeneration == 0 || status.ObservedGeneration < ds.Generation ||
+		status.NumberReady < status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("pulled images on %d/%d nodes",
+			status.NumberReady, status.DesiredNumberScheduled), nil
+	}
+
+	err = appsClient.DeleteDaemonSet(it.Namespace, ds.Name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return false, "", shippererrors.NewKubeclientDeleteError(it.Namespace, ds.Name, err).
+			WithKind(daemonSetGVK)
+	}
+
+	return true, "", nil
+}
+
+// buildPrePuller returns the DaemonSet pulling the images of the Deployments
+// in objects, or nil if there are none.
+func buildPrePuller(it *shipper.InstallationTarget, objects []runtime.Object, pauseImage string) *appsv1.DaemonSet {
+	seen := map[string]struct{}{}
+	var images []string
+	for _, obj := range objects {
+		d, ok := obj.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+
+		podSpec := d.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			if _, ok := seen[c.Image]; ok || c.Image == "" {
+				continue
+			}
+			seen[c.Image] = struct{}{}
+			images = append(images, c.Image)
+		}
+	}
+
+	if len(images) == 0 {
+		return nil
+	}
+
+	sort.Strings(images)
+
+	initContainers := make([]corev1.Container, 0, len(images))
+	for i, image := range images {
+		initContainers = append(initContainers, corev1.Container{
+			Name:    fmt.Sprintf("prepull-%d", i),
+			Image:   image,
+			Command: []string{"sh", "-c", "true"},
+		})
+	}
+
+	podLabels := map[string]string{PrePullerLabel: it.Name}
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-prepull", it.Name),
+			Namespace: it.Namespace,
+			Labels:    it.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: shipper.SchemeGroupVersion.String(),
+					Kind:       "InstallationTarget",
+					Name:       it.Name,
+					UID:        it.UID,
+				},
+			},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					InitContainers: initContainers,
+					Containers: []corev1.Container{
+						{
+							Name:  "pause",
+							Image: pauseImage,
+						},
+					},
+					Tolerations: []corev1.Toleration{
+						{Operator: corev1.TolerationOpExists},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package installation
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+// installationTargetInFlight tells installation targets that are still
+// being installed, and need to be synced as soon as possible for their
+// release to move on, from the ones that are only being revisited.
+// Installation targets don't record the generation they observed, but their
+// spec hardly ever changes once they're installed.
+func installationTargetInFlight(lister shipperlisters.InstallationTargetLister) func(string) bool {
+	return func(key string) bool {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return true
+		}
+
+		it, err := lister.InstallationTargets(namespace).Get(name)
+		if err != nil {
+			// Deleted targets are quick to sync, and better
+			// forgotten soon.
+			return true
+		}
+
+		ready, _ := targetutil.IsReady(it.Status.Conditions)
+		return !ready
+	}
+}
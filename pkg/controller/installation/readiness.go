@@ -0,0 +1,188 @@
+package installation
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+const (
+	ObjectsNotReady = "ObjectsNotReady"
+
+	// maxUnhealthyObjects is how many objects that aren't ready the
+	// Healthy condition names, so its message stays readable.
+	maxUnhealthyObjects = 5
+)
+
+// objectReady tells if an object installed from a chart is ready for the
+// objects after it in the install order, and why not if it isn't. Kinds
+// without a notion of readiness are ready as soon as they exist, unless they
+// have a Ready condition.
+func objectReady(obj *unstructured.Unstructured) (bool, string) {
+	observedGeneration, ok, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if ok && observedGeneration < obj.GetGeneration() {
+		return false, "its controller hasn't observed its latest spec yet"
+	}
+
+	switch obj.GetKind() {
+	case "Deployment", "ReplicaSet", "ReplicationController":
+		if hasCondition(obj, "Available") && !conditionTrue(obj, "Available") {
+			return false, "it isn't Available"
+		}
+
+		replicas := specReplicas(obj)
+		if updated, ok := statusInt64(obj, "updatedReplicas"); ok && updated < replicas {
+			return false, fmt.Sprintf("%d out of %d replicas updated", updated, replicas)
+		}
+		if available, _ := statusInt64(obj, "availableReplicas"); available < replicas {
+			return false, fmt.Sprintf("%d out of %d replicas available", available, replicas)
+		}
+	case "StatefulSet":
+		replicas := specReplicas(obj)
+		if ready, _ := statusInt64(obj, "readyReplicas"); ready < replicas {
+			return false, fmt.Sprintf("%d out of %d replicas ready", ready, replicas)
+		}
+	case "DaemonSet":
+		desired, _ := statusInt64(obj, "desiredNumberScheduled")
+		if available, _ := statusInt64(obj, "numberAvailable"); available < desired {
+			return false, fmt.Sprintf("%d out of %d pods available", available, desired)
+		}
+	case "Job":
+		if conditionTrue(obj, "Failed") {
+			return false, "it failed"
+		}
+		if !conditionTrue(obj, "Complete") {
+			return false, "it hasn't completed yet"
+		}
+	case "Namespace":
+		if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "Active" {
+			return false, fmt.Sprintf("it is in phase %q", phase)
+		}
+	case "Service":
+		serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		if serviceType == "LoadBalancer" && !hasLoadBalancerAddress(obj) {
+			return false, "it has no load balancer address yet"
+		}
+	case "Ingress":
+		if !hasLoadBalancerAddress(obj) {
+			return false, "it has no load balancer address yet"
+		}
+	case "PersistentVolumeClaim":
+		if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "Bound" {
+			return false, fmt.Sprintf("it is in phase %q", phase)
+		}
+	default:
+		if hasCondition(obj, "Ready") && !conditionTrue(obj, "Ready") {
+			return false, "its Ready condition isn't True"
+		}
+	}
+
+	return true, ""
+}
+
+func hasLoadBalancerAddress(obj *unstructured.Unstructured) bool {
+	ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	return len(ingress) > 0
+}
+
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !ok {
+		// Kubernetes defaults replicas to 1.
+		return 1
+	}
+
+	return replicas
+}
+
+func statusInt64(obj *unstructured.Unstructured, field string) (int64, bool) {
+	value, ok, _ := unstructured.NestedInt64(obj.Object, "status", field)
+	return value, ok
+}
+
+func findCondition(obj *unstructured.Unstructured, condType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == condType {
+			return cond
+		}
+	}
+
+	return nil
+}
+
+func hasCondition(obj *unstructured.Unstructured, condType string) bool {
+	return findCondition(obj, condType) != nil
+}
+
+func conditionTrue(obj *unstructured.Unstructured, condType string) bool {
+	cond := findCondition(obj, condType)
+	return cond != nil && cond["status"] == "True"
+}
+
+// unhealthyObjects returns a description of every object the last install
+// left in the cluster that isn't ready, as of when it was installed.
+// Services also need to have endpoints, as nothing can reach them otherwise.
+func (i *Installer) unhealthyObjects(client kubernetes.Interface) ([]string, error) {
+	var unhealthy []string
+	for _, obj := range i.installed {
+		ready, msg := objectReady(obj)
+		if ready && obj.GetKind() == "Service" {
+			var err error
+			ready, msg, err = serviceHasEndpoints(client, obj)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !ready {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s %q: %s", obj.GetKind(), obj.GetName(), msg))
+		}
+	}
+
+	return unhealthy, nil
+}
+
+func serviceHasEndpoints(client kubernetes.Interface, svc *unstructured.Unstructured) (bool, string, error) {
+	serviceType, _, _ := unstructured.NestedString(svc.Object, "spec", "type")
+	selector, _, _ := unstructured.NestedMap(svc.Object, "spec", "selector")
+	if serviceType == "ExternalName" || len(selector) == 0 {
+		// Endpoints for these are up to whoever set them up.
+		return true, "", nil
+	}
+
+	endpoints, err := client.CoreV1().Endpoints(svc.GetNamespace()).Get(svc.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, "it has no endpoints", nil
+	} else if err != nil {
+		return false, "", shippererrors.NewKubeclientGetError(svc.GetNamespace(), svc.GetName(), err).
+			WithCoreV1Kind("Endpoints")
+	}
+
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, "", nil
+		}
+	}
+
+	return false, "it has no ready endpoints", nil
+}
+
+// healthMessage summarizes the objects that aren't ready for the Healthy
+// condition.
+func healthMessage(unhealthy []string) string {
+	if len(unhealthy) <= maxUnhealthyObjects {
+		return strings.Join(unhealthy, "; ")
+	}
+
+	return fmt.Sprintf("%s; and %d more",
+		strings.Join(unhealthy[:maxUnhealthyObjects], "; "),
+		len(unhealthy)-maxUnhealthyObjects)
+}
@@ -0,0 +1,109 @@
+package installation
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjectReady(t *testing.T) {
+	tests := []struct {
+		name  string
+		obj   map[string]interface{}
+		ready bool
+	}{
+		{
+			name: "deployment with every replica available",
+			obj: map[string]interface{}{
+				"kind":   "Deployment",
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			},
+			ready: true,
+		},
+		{
+			name: "deployment with no replicas",
+			obj: map[string]interface{}{
+				"kind": "Deployment",
+				"spec": map[string]interface{}{"replicas": int64(0)},
+			},
+			ready: true,
+		},
+		{
+			name: "deployment still rolling out",
+			obj: map[string]interface{}{
+				"kind":   "Deployment",
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"updatedReplicas": int64(1), "availableReplicas": int64(2)},
+			},
+			ready: false,
+		},
+		{
+			name: "deployment with an outdated status",
+			obj: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(0)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1)},
+			},
+			ready: false,
+		},
+		{
+			name: "failed job",
+			obj: map[string]interface{}{
+				"kind": "Job",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Failed", "status": "True"},
+					},
+				},
+			},
+			ready: false,
+		},
+		{
+			name: "ingress without an address",
+			obj: map[string]interface{}{
+				"kind": "Ingress",
+			},
+			ready: false,
+		},
+		{
+			name: "ingress with an address",
+			obj: map[string]interface{}{
+				"kind": "Ingress",
+				"status": map[string]interface{}{
+					"loadBalancer": map[string]interface{}{
+						"ingress": []interface{}{
+							map[string]interface{}{"ip": "10.0.0.1"},
+						},
+					},
+				},
+			},
+			ready: true,
+		},
+		{
+			name:  "config map",
+			obj:   map[string]interface{}{"kind": "ConfigMap"},
+			ready: true,
+		},
+		{
+			name: "custom resource that isn't ready",
+			obj: map[string]interface{}{
+				"kind": "Certificate",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False"},
+					},
+				},
+			},
+			ready: false,
+		},
+	}
+
+	for _, tt := range tests {
+		ready, msg := objectReady(&unstructured.Unstructured{Object: tt.obj})
+		if ready != tt.ready {
+			t.Errorf("%s: expected ready to be %t, got %t (%s)", tt.name, tt.ready, ready, msg)
+		}
+	}
+}
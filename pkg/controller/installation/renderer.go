@@ -1,7 +1,10 @@
 package installation
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -9,11 +12,12 @@ import (
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	kubescheme "k8s.io/client-go/kubernetes/scheme"
 )
 
 type kubeobj interface {
@@ -22,11 +26,98 @@ type kubeobj interface {
 	SetLabels(map[string]string)
 }
 
+// RenderedChart holds the objects a single chart of an installation target
+// rendered to.
+type RenderedChart struct {
+	Name    string
+	Version string
+	Objects []runtime.Object
+}
+
 func FetchAndRenderChart(
 	chartFetcher shipperrepo.ChartFetcher,
 	it *shipper.InstallationTarget,
 ) ([]runtime.Object, error) {
-	chart, err := chartFetcher(&it.Spec.Chart)
+	charts, err := FetchAndRenderCharts(chartFetcher, it)
+	if err != nil {
+		return nil, err
+	}
+
+	return chartObjects(charts), nil
+}
+
+// FetchAndRenderCharts renders the main chart of an installation target and
+// then each of its additional charts, in order. A target is only ever
+// installed once all of its charts render, so on error the charts rendered
+// before the one that failed are returned along with it.
+func FetchAndRenderCharts(
+	chartFetcher shipperrepo.ChartFetcher,
+	it *shipper.InstallationTarget,
+) ([]RenderedChart, error) {
+	return renderCharts(func(chartspec *shipper.Chart, it *shipper.InstallationTarget, values shipper.ChartValues) ([]string, error) {
+		return fetchAndRenderManifests(chartFetcher, chartspec, it, values)
+	}, it)
+}
+
+func renderCharts(render manifestRenderer, it *shipper.InstallationTarget) ([]RenderedChart, error) {
+	manifests, err := render(&it.Spec.Chart, it, it.Spec.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := prepareObjects(it, manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	charts := make([]RenderedChart, 0, len(it.Spec.AdditionalCharts)+1)
+	charts = append(charts, RenderedChart{
+		Name:    it.Spec.Chart.Name,
+		Version: it.Spec.Chart.Version,
+		Objects: objects,
+	})
+
+	for _, additional := range it.Spec.AdditionalCharts {
+		manifests, err := render(&additional.Chart, it, additional.Values)
+		if err != nil {
+			return charts, err
+		}
+
+		objects, err := prepareAdditionalObjects(it, additional.Name, manifests)
+		if err != nil {
+			return charts, err
+		}
+
+		charts = append(charts, RenderedChart{
+			Name:    additional.Name,
+			Version: additional.Chart.Version,
+			Objects: objects,
+		})
+	}
+
+	if err := checkDuplicateObjects(charts); err != nil {
+		return charts, err
+	}
+
+	return charts, nil
+}
+
+func chartObjects(charts []RenderedChart) []runtime.Object {
+	var objects []runtime.Object
+	for _, chart := range charts {
+		objects = append(objects, chart.Objects...)
+	}
+
+	return objects
+}
+
+func fetchAndRenderManifests(
+	chartFetcher shipperrepo.ChartFetcher,
+	chartspec *shipper.Chart,
+	it *shipper.InstallationTarget,
+	values shipper.ChartValues,
+) ([]string, error) {
+	chart, err := chartFetcher(chartspec)
 	if err != nil {
 		return nil, shippererrors.NewRenderManifestError(err)
 	}
@@ -35,14 +126,14 @@ func FetchAndRenderChart(
 		chart,
 		it.GetName(),
 		it.GetNamespace(),
-		&it.Spec.Values,
+		&values,
 	)
 
 	if err != nil {
 		return nil, shippererrors.NewRenderManifestError(err)
 	}
 
-	return prepareObjects(it, manifests)
+	return manifests, nil
 }
 
 func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runtime.Object, error) {
@@ -50,24 +141,53 @@ func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runti
 		shipper.InstallationTargetOwnerLabel: it.Name,
 	})
 
+	isJobWorkload := it.Spec.WorkloadKind == shipper.WorkloadKindJob
+
 	var (
 		allServices          []*corev1.Service
 		productionLBServices []*corev1.Service
+		deployments          []*appsv1.Deployment
+		configObjects        []runtime.Object
 	)
 
 	preparedObjects := make([]runtime.Object, 0, len(manifests))
 	for _, manifest := range manifests {
-		decodedObj, _, err :=
-			kubescheme.Codecs.
-				UniversalDeserializer().
-				Decode([]byte(manifest), nil, nil)
+		decodedObj, _, err := shipperchart.Decode(manifest)
 
 		if err != nil {
 			return nil, shippererrors.NewDecodeManifestError("error decoding manifest: %s", err)
 		}
 
 		switch obj := decodedObj.(type) {
+		case *batchv1.Job:
+			// Step hooks are run by the release controller when
+			// the strategy gets to them, not installed.
+			if _, ok := obj.Annotations[shipper.StepHookAnnotation]; ok {
+				continue
+			}
+
+			if isJobWorkload {
+				// Just like Deployments, Jobs can't be
+				// changed much once created, so every
+				// release needs its own.
+				if !strings.Contains(obj.Name, it.Name) {
+					return nil, shippererrors.NewInvalidChartError(
+						fmt.Sprintf("Job %q has invalid name."+
+							" The name of the Job should be"+
+							" templated with {{.Release.Name}}.",
+							obj.Name),
+					)
+				}
+
+				decodedObj = patchJob(obj)
+			}
 		case *appsv1.Deployment:
+			if isJobWorkload {
+				return nil, shippererrors.NewInvalidChartError(
+					fmt.Sprintf("Deployment %q can't be part of a %s workload",
+						obj.Name, shipper.WorkloadKindJob))
+			}
+
 			// We need the Deployment in the chart to have a unique
 			// name, meaning that different installations need to
 			// generate Deployments with different names,
@@ -85,7 +205,14 @@ func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runti
 				)
 			}
 
+			if err := validatePodLabels(obj); err != nil {
+				return nil, err
+			}
+
 			decodedObj = patchDeployment(obj, shipperLabels)
+			deployments = append(deployments, obj)
+		case *corev1.ConfigMap, *corev1.Secret:
+			configObjects = append(configObjects, obj)
 		case *corev1.Service:
 			allServices = append(allServices, obj)
 
@@ -110,24 +237,137 @@ func prepareObjects(it *shipper.InstallationTarget, manifests []string) ([]runti
 		productionLBServices = allServices
 	}
 
-	// If, after all, we still can not identify a single Service which will
-	// be the production LB, there is nothing else to do rather than bail
-	// out
-	if len(productionLBServices) != 1 {
+	// If, after all, we still can not identify any Service which will be
+	// the production LB, there is nothing else to do rather than bail
+	// out, unless the release runs a Job, which gets no traffic. Charts
+	// are allowed to expose several production LB Services (e.g. a
+	// frontend and a grpc one), as long as they all select the same pods:
+	// traffic is shifted for all of them at once.
+	if len(productionLBServices) == 0 && !isJobWorkload {
 		return nil, shippererrors.NewInvalidChartError(
 			fmt.Sprintf(
-				"one and only one v1.Service object with label %q is required, but %d found instead",
-				shipper.LBLabel, len(productionLBServices)))
+				"at least one v1.Service object with label %q is required, but 0 found instead",
+				shipper.LBLabel))
 	}
 
-	err := patchLBService(it, productionLBServices[0])
-	if err != nil {
+	for _, svc := range productionLBServices {
+		if err := patchLBService(it, svc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateLBServiceSelectors(productionLBServices); err != nil {
 		return nil, err
 	}
 
+	if it.Spec.ImageOverride != nil {
+		for _, d := range deployments {
+			err := overrideImage(d, it.Spec.ImageOverride)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v, ok := it.Labels[shipper.ConfigChecksumLabel]; ok && v == shipper.True {
+		checksum, err := computeConfigChecksum(configObjects)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range deployments {
+			injectConfigChecksum(d, checksum)
+		}
+	}
+
 	return preparedObjects, nil
 }
 
+// prepareAdditionalObjects labels the objects an additional chart rendered
+// to like those of the main chart. A release's capacity, traffic and step
+// hooks all come from its main chart, so additional charts can't have
+// Deployments, production LB Services or step hooks of their own.
+func prepareAdditionalObjects(it *shipper.InstallationTarget, chartName string, manifests []string) ([]runtime.Object, error) {
+	shipperLabels := labels.Merge(labels.Set(it.Labels), labels.Set{
+		shipper.InstallationTargetOwnerLabel: it.Name,
+	})
+
+	preparedObjects := make([]runtime.Object, 0, len(manifests))
+	for _, manifest := range manifests {
+		decodedObj, _, err := shipperchart.Decode(manifest)
+
+		if err != nil {
+			return nil, shippererrors.NewDecodeManifestError("error decoding manifest: %s", err)
+		}
+
+		switch obj := decodedObj.(type) {
+		case *appsv1.Deployment:
+			return nil, shippererrors.NewInvalidChartError(
+				fmt.Sprintf("additional chart %q has Deployment %q, but only"+
+					" the release's main chart can have Deployments",
+					chartName, obj.Name))
+		case *corev1.Service:
+			if obj.Labels[shipper.LBLabel] == shipper.LBForProduction {
+				return nil, shippererrors.NewInvalidChartError(
+					fmt.Sprintf("additional chart %q has Service %q with label %s=%s,"+
+						" but only the release's main chart can have production LB Services",
+						chartName, obj.Name, shipper.LBLabel, shipper.LBForProduction))
+			}
+		case *batchv1.Job:
+			if _, ok := obj.Annotations[shipper.StepHookAnnotation]; ok {
+				return nil, shippererrors.NewInvalidChartError(
+					fmt.Sprintf("additional chart %q has step hook Job %q, but only"+
+						" the release's main chart can have step hooks",
+						chartName, obj.Name))
+			}
+		}
+
+		obj := decodedObj.(kubeobj)
+		obj.SetLabels(labels.Merge(
+			obj.GetLabels(),
+			shipperLabels,
+		))
+
+		preparedObjects = append(preparedObjects, obj)
+	}
+
+	return preparedObjects, nil
+}
+
+// checkDuplicateObjects makes sure no two charts render the same object, as
+// installing them would have one silently overwrite the other.
+func checkDuplicateObjects(charts []RenderedChart) error {
+	seen := make(map[string]string)
+	for _, chart := range charts {
+		for _, obj := range chart.Objects {
+			objMeta, err := meta.Accessor(obj)
+			if err != nil {
+				return shippererrors.NewRenderManifestError(err)
+			}
+
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			key := fmt.Sprintf("%s/%s", gvk.Kind, objMeta.GetName())
+			if other, ok := seen[key]; ok && other != chart.Name {
+				return shippererrors.NewInvalidChartError(
+					fmt.Sprintf("charts %q and %q both have %s", other, chart.Name, key))
+			}
+			seen[key] = chart.Name
+		}
+	}
+
+	return nil
+}
+
+// patchJob installs a Job without running any of its pods, like Deployments
+// are installed without replicas, so the capacity controller decides how
+// many run at once.
+func patchJob(j *batchv1.Job) runtime.Object {
+	parallelism := int32(0)
+	j.Spec.Parallelism = &parallelism
+
+	return j
+}
+
 func patchDeployment(d *appsv1.Deployment, labelsToInject map[string]string) runtime.Object {
 	replicas := int32(0)
 	d.Spec.Replicas = &replicas
@@ -147,6 +387,9 @@ func patchDeployment(d *appsv1.Deployment, labelsToInject map[string]string) run
 	d.Spec.Selector = newSelector
 
 	podTemplateLabels := d.Spec.Template.Labels
+	if podTemplateLabels == nil {
+		podTemplateLabels = make(map[string]string)
+	}
 	for k, v := range labelsToInject {
 		podTemplateLabels[k] = v
 	}
@@ -155,6 +398,103 @@ func patchDeployment(d *appsv1.Deployment, labelsToInject map[string]string) run
 	return d
 }
 
+// validatePodLabels checks that a Deployment leaves PodTrafficStatusLabel to
+// Shipper. Pods created with it would get traffic before Shipper decides
+// they should, and selecting on it would orphan pods as soon as Shipper
+// changes it.
+func validatePodLabels(d *appsv1.Deployment) error {
+	if _, ok := d.Spec.Template.Labels[shipper.PodTrafficStatusLabel]; ok {
+		return shippererrors.NewInvalidChartError(
+			fmt.Sprintf("Deployment %q sets label %q on its pods, but it is managed by Shipper",
+				d.Name, shipper.PodTrafficStatusLabel))
+	}
+
+	if d.Spec.Selector != nil {
+		if _, ok := d.Spec.Selector.MatchLabels[shipper.PodTrafficStatusLabel]; ok {
+			return shippererrors.NewInvalidChartError(
+				fmt.Sprintf("Deployment %q selects pods by label %q, but it is managed by Shipper",
+					d.Name, shipper.PodTrafficStatusLabel))
+		}
+
+		for _, expr := range d.Spec.Selector.MatchExpressions {
+			if expr.Key == shipper.PodTrafficStatusLabel {
+				return shippererrors.NewInvalidChartError(
+					fmt.Sprintf("Deployment %q selects pods by label %q, but it is managed by Shipper",
+						d.Name, shipper.PodTrafficStatusLabel))
+			}
+		}
+	}
+
+	return nil
+}
+
+// overrideImage replaces the image of the container in a Deployment
+// targeted by an image override, or of its first container if the override
+// names none, leaving sidecars alone. Overriding a container that doesn't
+// exist is an error, as it would silently roll out the chart's original
+// image.
+func overrideImage(d *appsv1.Deployment, override *shipper.ImageOverride) error {
+	containers := d.Spec.Template.Spec.Containers
+	if override.Container == "" && len(containers) > 0 {
+		containers[0].Image = override.Image
+		return nil
+	}
+
+	for i := range containers {
+		if containers[i].Name == override.Container {
+			containers[i].Image = override.Image
+			return nil
+		}
+	}
+
+	return shippererrors.NewInvalidChartError(
+		fmt.Sprintf("image override refers to container %q, but Deployment %q has no such container",
+			override.Container, d.Name))
+}
+
+// computeConfigChecksum returns a checksum of the contents of all ConfigMaps
+// and Secrets rendered by a chart. Injecting it into the pod template of
+// Deployments makes config-only changes roll pods like any other change.
+func computeConfigChecksum(objects []runtime.Object) (string, error) {
+	contents := make(map[string]interface{})
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *corev1.ConfigMap:
+			contents["ConfigMap/"+o.Name] = []interface{}{o.Data, o.BinaryData}
+		case *corev1.Secret:
+			contents["Secret/"+o.Name] = []interface{}{o.Data, o.StringData}
+		}
+	}
+
+	keys := make([]string, 0, len(contents))
+	for k := range contents {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		b, err := json.Marshal(contents[k])
+		if err != nil {
+			return "", shippererrors.NewRenderManifestError(err)
+		}
+
+		h.Write([]byte(k))
+		h.Write(b)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func injectConfigChecksum(d *appsv1.Deployment, checksum string) {
+	annotations := d.Spec.Template.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[shipper.ConfigChecksumAnnotation] = checksum
+	d.Spec.Template.SetAnnotations(annotations)
+}
+
 func patchLBService(it *shipper.InstallationTarget, s *corev1.Service) error {
 	if relName, ok := s.Spec.Selector[shipper.HelmReleaseLabel]; ok {
 		v, ok := it.Labels[shipper.HelmWorkaroundLabel]
@@ -179,6 +519,20 @@ func patchLBService(it *shipper.InstallationTarget, s *corev1.Service) error {
 		}
 	}
 
+	// Production LB Services send traffic to the pods of every release
+	// of an application, so they can't select pods of a single one.
+	for _, key := range []string{
+		shipper.ReleaseLabel,
+		shipper.ReleaseEnvironmentHashLabel,
+		shipper.InstallationTargetOwnerLabel,
+	} {
+		if _, ok := s.Spec.Selector[key]; ok {
+			return shippererrors.NewInvalidChartError(
+				fmt.Sprintf("Service %q selects pods by label %q, which would keep Shipper"+
+					" from shifting traffic between releases", s.Name, key))
+		}
+	}
+
 	s.Labels[shipper.LBLabel] = shipper.LBForProduction
 
 	// Make sure service selector is safely defined
@@ -190,3 +544,26 @@ func patchLBService(it *shipper.InstallationTarget, s *corev1.Service) error {
 
 	return nil
 }
+
+// validateLBServiceSelectors makes sure that all production LB Services
+// select the same pods. Their traffic weights are linked, and a pod only
+// counts as ready once it's ready in all of their Endpoints, so a Service
+// selecting only some of the pods would keep a rollout from ever
+// progressing.
+func validateLBServiceSelectors(services []*corev1.Service) error {
+	if len(services) < 2 {
+		return nil
+	}
+
+	for _, svc := range services[1:] {
+		if !labels.Equals(svc.Spec.Selector, services[0].Spec.Selector) {
+			return shippererrors.NewInvalidChartError(
+				fmt.Sprintf("production LB Services %q and %q select different pods."+
+					" Shipper shifts traffic for all production LB Services at once,"+
+					" so they must all have the same selector",
+					services[0].Name, svc.Name))
+		}
+	}
+
+	return nil
+}
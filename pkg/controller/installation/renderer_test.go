@@ -5,9 +5,12 @@ import (
 	"regexp"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/helm/pkg/proto/hapi/chart"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
@@ -91,6 +94,59 @@ func TestRendererMultiService(t *testing.T) {
 	}
 }
 
+// TestRendererMultiServiceMultiLB tests that the renderer accepts charts with
+// more than one service labeled as production LB, and that all of them get
+// their selectors set so traffic can be shifted for them together.
+func TestRendererMultiServiceMultiLB(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "multi-service-multi-lb"))
+
+	objects, err := FetchAndRenderChart(shippertesting.LocalFetchChart, it)
+	if err != nil {
+		t.Fatalf("expected rendered chart, got error instead: %s", err.Error())
+	}
+
+	svcName := fmt.Sprintf("%s-%s", shippertesting.TestApp, reviewsChartName)
+	for _, name := range []string{svcName, svcName + "-grpc"} {
+		err = validatePrimaryService(objects, name)
+		if err != nil {
+			t.Fatalf("chart failed to render a valid primary service %q: %s", name, err.Error())
+		}
+	}
+
+	err = validateSecondaryService(objects, svcName+"-staging")
+	if err != nil {
+		t.Fatalf("chart failed to render a valid secondary service: %s", err.Error())
+	}
+}
+
+// TestValidateLBServiceSelectors tests that production LB Services are only
+// accepted when they all select the same pods, since traffic is shifted for
+// all of them at once.
+func TestValidateLBServiceSelectors(t *testing.T) {
+	newService := func(name string, selector map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.ServiceSpec{Selector: selector},
+		}
+	}
+
+	frontend := newService("frontend", map[string]string{"app": "reviews-api", "component": "server"})
+	grpc := newService("grpc", map[string]string{"component": "server", "app": "reviews-api"})
+	admin := newService("admin", map[string]string{"app": "reviews-api", "component": "admin"})
+
+	if err := validateLBServiceSelectors([]*corev1.Service{frontend, grpc}); err != nil {
+		t.Fatalf("expected services with the same selector to be valid, got error instead: %s", err)
+	}
+
+	err := validateLBServiceSelectors([]*corev1.Service{frontend, grpc, admin})
+	if _, ok := err.(shippererrors.InvalidChartError); !ok {
+		t.Fatalf("expected services with different selectors to fail with InvalidChartError, got %v instead", err)
+	}
+}
+
 // TestRendererBrokenChartTarball tests if the renderer returns an error for a
 // chart that points to a broken tarball.
 func TestRendererBrokenChartTarball(t *testing.T) {
@@ -165,7 +221,7 @@ func TestRendererMultiServiceNoLB(t *testing.T) {
 	}
 
 	expected := fmt.Sprintf(
-		`one and only one v1.Service object with label %q is required, but 0 found instead`,
+		`at least one v1.Service object with label %q is required, but 0 found instead`,
 		shipper.LBLabel)
 
 	if err.Error() != expected {
@@ -196,6 +252,411 @@ func TestRendererServiceWithReleaseNoWorkaround(t *testing.T) {
 	}
 }
 
+// TestRendererConfigChecksum tests that the renderer injects a checksum of
+// the chart's ConfigMaps and Secrets in the Deployment's pod template when
+// asked to, and that the checksum changes when only the config changes.
+func TestRendererConfigChecksum(t *testing.T) {
+	renderChecksum := func(enabled bool, values shipper.ChartValues) (string, bool) {
+		it := buildInstallationTarget(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			buildChart(reviewsChartName, "with-config"))
+		it.Spec.Values = values
+		if enabled {
+			it.Labels[shipper.ConfigChecksumLabel] = shipper.True
+		}
+
+		objects, err := FetchAndRenderChart(shippertesting.LocalFetchChart, it)
+		if err != nil {
+			t.Fatalf("expected rendered chart, got error instead: %s", err.Error())
+		}
+
+		for _, obj := range objects {
+			if d, ok := obj.(*appsv1.Deployment); ok {
+				checksum, ok := d.Spec.Template.Annotations[shipper.ConfigChecksumAnnotation]
+				return checksum, ok
+			}
+		}
+
+		t.Fatal("chart did not render a Deployment")
+		return "", false
+	}
+
+	if _, ok := renderChecksum(false, nil); ok {
+		t.Fatalf("expected no %q annotation when checksums are not enabled", shipper.ConfigChecksumAnnotation)
+	}
+
+	defaultChecksum, ok := renderChecksum(true, nil)
+	if !ok {
+		t.Fatalf("expected %q annotation when checksums are enabled", shipper.ConfigChecksumAnnotation)
+	}
+
+	if checksum, _ := renderChecksum(true, nil); checksum != defaultChecksum {
+		t.Fatalf("expected checksum to be stable, got %q and %q", defaultChecksum, checksum)
+	}
+
+	configValues := shipper.ChartValues{
+		"config": map[string]interface{}{"logLevel": "debug", "password": "hunter2"},
+	}
+	if checksum, _ := renderChecksum(true, configValues); checksum == defaultChecksum {
+		t.Fatal("expected checksum to change when the ConfigMap changes")
+	}
+
+	secretValues := shipper.ChartValues{
+		"config": map[string]interface{}{"logLevel": "info", "password": "hunter3"},
+	}
+	if checksum, _ := renderChecksum(true, secretValues); checksum == defaultChecksum {
+		t.Fatal("expected checksum to change when the Secret changes")
+	}
+}
+
+// TestRendererImageOverride tests that the renderer replaces the image of
+// the Deployment's containers when an image override is present, and that it
+// refuses to render when the override targets a container that doesn't exist.
+func TestRendererImageOverride(t *testing.T) {
+	image := "nginx:hotfix"
+	tests := []struct {
+		container string
+		expectErr bool
+	}{
+		{"", false},
+		{reviewsChartName, false},
+		{"does-not-exist", true},
+	}
+
+	for _, test := range tests {
+		it := buildInstallationTarget(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			buildChart(reviewsChartName, "0.0.1"))
+		it.Spec.ImageOverride = &shipper.ImageOverride{
+			Container: test.container,
+			Image:     image,
+		}
+
+		objects, err := FetchAndRenderChart(shippertesting.LocalFetchChart, it)
+		if test.expectErr {
+			if _, ok := err.(shippererrors.InvalidChartError); !ok {
+				t.Fatalf("container %q: expected InvalidChartError, got %v instead", test.container, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("container %q: expected rendered chart, got error instead: %s", test.container, err)
+		}
+
+		for _, obj := range objects {
+			d, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				continue
+			}
+
+			c := d.Spec.Template.Spec.Containers[0]
+			if c.Image != image {
+				t.Fatalf("container %q: expected image %q, got %q", c.Name, image, c.Image)
+			}
+		}
+	}
+}
+
+// TestOverrideImageSidecars tests that image overrides leave sidecars alone:
+// an override without a container only replaces the image of the first one.
+func TestOverrideImageSidecars(t *testing.T) {
+	tests := []struct {
+		container string
+		expected  []string
+	}{
+		{"", []string{"app:hotfix", "proxy:1.0"}},
+		{"proxy", []string{"app:1.0", "app:hotfix"}},
+	}
+
+	for _, test := range tests {
+		d := &appsv1.Deployment{}
+		d.Name = "reviews-api"
+		d.Spec.Template.Spec.Containers = []corev1.Container{
+			{Name: "app", Image: "app:1.0"},
+			{Name: "proxy", Image: "proxy:1.0"},
+		}
+
+		err := overrideImage(d, &shipper.ImageOverride{Container: test.container, Image: "app:hotfix"})
+		if err != nil {
+			t.Fatalf("container %q: unexpected error: %s", test.container, err)
+		}
+
+		for i, c := range d.Spec.Template.Spec.Containers {
+			if c.Image != test.expected[i] {
+				t.Errorf("container %q: expected container %q to have image %q, got %q",
+					test.container, c.Name, test.expected[i], c.Image)
+			}
+		}
+	}
+}
+
+// TestRendererImageOverrideReusesManifests tests that installation targets
+// that only override the image of their incumbent are rendered from the
+// incumbent's manifests, without fetching the chart again.
+func TestRendererImageOverrideReusesManifests(t *testing.T) {
+	fetches := 0
+	fetchChart := func(chartspec *shipper.Chart) (*chart.Chart, error) {
+		fetches++
+		return shippertesting.LocalFetchChart(chartspec)
+	}
+
+	render := newManifestCache().renderer(fetchChart)
+
+	incumbent := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+	incumbent.Name = "test-app-incumbent"
+
+	if _, err := renderCharts(render, incumbent); err != nil {
+		t.Fatalf("expected incumbent to render, got error instead: %s", err)
+	}
+
+	contender := incumbent.DeepCopy()
+	contender.Name = "test-app-contender"
+	contender.Spec.ImageOverride = &shipper.ImageOverride{Image: "nginx:hotfix"}
+
+	charts, err := renderCharts(render, contender)
+	if err != nil {
+		t.Fatalf("expected contender to render, got error instead: %s", err)
+	}
+
+	if fetches != 1 {
+		t.Fatalf("expected the chart to be fetched once, got %d fetches", fetches)
+	}
+
+	expected, err := FetchAndRenderChart(shippertesting.LocalFetchChart, contender)
+	if err != nil {
+		t.Fatalf("expected contender to render, got error instead: %s", err)
+	}
+
+	if eq, diff := shippertesting.DeepEqualDiff(expected, chartObjects(charts)); !eq {
+		t.Fatalf("objects rendered from cached manifests differ from expected:\n%s", diff)
+	}
+
+	// Anything else but the image changing means the chart has to be
+	// rendered again.
+	contender.Spec.Values = shipper.ChartValues{"replicas": float64(3)}
+	if _, err := renderCharts(render, contender); err != nil {
+		t.Fatalf("expected contender to render, got error instead: %s", err)
+	}
+
+	if fetches != 2 {
+		t.Fatalf("expected the chart to be fetched again for new values, got %d fetches", fetches)
+	}
+}
+
+// TestRendererPodLabelContract tests that Shipper's identity labels end up on
+// the pods of every Deployment, even when the chart gives them no labels,
+// and that charts meddling with the labels Shipper relies on are rejected.
+func TestRendererPodLabelContract(t *testing.T) {
+	service := fmt.Sprintf(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: %s
+  labels:
+    shipper-lb: production
+spec:
+  selector:
+    %%s
+  ports:
+  - port: 80
+`, shippertesting.TestApp)
+
+	deployment := fmt.Sprintf(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+spec:
+  selector:
+    matchLabels:
+      %%s
+  template:
+    metadata:
+      %%s
+    spec:
+      containers:
+      - name: app
+        image: nginx
+`, shippertesting.TestApp)
+
+	tests := []struct {
+		name        string
+		svcSelector string
+		dSelector   string
+		podMeta     string
+		expectErr   bool
+	}{
+		{"pods without labels", "app: reviews", "app: reviews", "annotations: {}", false},
+		{"pods with traffic status", "app: reviews", "app: reviews", "labels: {shipper-traffic-status: enabled}", true},
+		{"deployment selecting traffic status", "app: reviews", "shipper-traffic-status: enabled", "labels: {app: reviews}", true},
+		{"service selecting a release", "shipper-release: reviews-1", "app: reviews", "labels: {app: reviews}", true},
+	}
+
+	for _, test := range tests {
+		it := buildInstallationTarget(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			buildChart(reviewsChartName, "0.0.1"))
+		it.Labels[shipper.ReleaseLabel] = it.Name
+
+		manifests := []string{
+			fmt.Sprintf(service, test.svcSelector),
+			fmt.Sprintf(deployment, test.dSelector, test.podMeta),
+		}
+
+		objects, err := prepareObjects(it, manifests)
+		if test.expectErr {
+			if _, ok := err.(shippererrors.InvalidChartError); !ok {
+				t.Errorf("%s: expected InvalidChartError, got %v instead", test.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: expected rendered chart, got error instead: %s", test.name, err)
+		}
+
+		for _, obj := range objects {
+			d, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				continue
+			}
+
+			for _, key := range []string{shipper.AppLabel, shipper.ReleaseLabel, shipper.InstallationTargetOwnerLabel} {
+				if d.Spec.Template.Labels[key] == "" {
+					t.Errorf("%s: expected pods to have label %q, got %v", test.name, key, d.Spec.Template.Labels)
+				}
+			}
+		}
+	}
+}
+
+// TestRendererAdditionalCharts tests that additional charts are rendered
+// along with the main chart, with their own values and the same labels.
+func TestRendererAdditionalCharts(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart(reviewsChartName, "0.0.1"))
+	it.Spec.AdditionalCharts = []shipper.AdditionalChart{
+		{
+			Name:   "config",
+			Chart:  buildChart("shared-config", "0.1.0"),
+			Values: shipper.ChartValues{"logLevel": "debug"},
+		},
+	}
+
+	charts, err := FetchAndRenderCharts(shippertesting.LocalFetchChart, it)
+	if err != nil {
+		t.Fatalf("expected rendered charts, got error instead: %s", err)
+	}
+
+	if len(charts) != 2 || charts[0].Name != reviewsChartName || charts[1].Name != "config" {
+		t.Fatalf("expected the main chart and then %q to be rendered, got %v", "config", charts)
+	}
+
+	cmName := fmt.Sprintf("%s-shared-config", shippertesting.TestApp)
+	obj := findKubeObject(charts[1].Objects, "ConfigMap", cmName)
+	if obj == nil {
+		t.Fatalf("expected additional chart to render ConfigMap %q", cmName)
+	}
+
+	cm := obj.(*corev1.ConfigMap)
+	if cm.Data["logLevel"] != "debug" {
+		t.Errorf("expected additional chart to be rendered with its own values, got %v", cm.Data)
+	}
+
+	if owner := cm.Labels[shipper.InstallationTargetOwnerLabel]; owner != it.Name {
+		t.Errorf("expected label %s=%s, got %q", shipper.InstallationTargetOwnerLabel, it.Name, owner)
+	}
+}
+
+// TestRendererInvalidAdditionalCharts tests that additional charts can't
+// have Deployments, nor render objects another chart renders.
+func TestRendererInvalidAdditionalCharts(t *testing.T) {
+	tests := []struct {
+		name             string
+		additionalCharts []shipper.AdditionalChart
+		rendered         int
+	}{
+		{
+			name: "additional chart with a Deployment",
+			additionalCharts: []shipper.AdditionalChart{
+				{Name: "reviews", Chart: buildChart(reviewsChartName, "0.0.1")},
+			},
+			rendered: 1,
+		},
+		{
+			name: "duplicate objects",
+			additionalCharts: []shipper.AdditionalChart{
+				{Name: "config", Chart: buildChart("shared-config", "0.1.0")},
+				{Name: "more-config", Chart: buildChart("shared-config", "0.1.0")},
+			},
+			rendered: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		it := buildInstallationTarget(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			buildChart(reviewsChartName, "0.0.1"))
+		it.Spec.AdditionalCharts = tt.additionalCharts
+
+		charts, err := FetchAndRenderCharts(shippertesting.LocalFetchChart, it)
+		if _, ok := err.(shippererrors.InvalidChartError); !ok {
+			t.Errorf("%s: expected InvalidChartError, got %v instead", tt.name, err)
+			continue
+		}
+
+		if len(charts) != tt.rendered {
+			t.Errorf("%s: expected %d charts to be rendered, got %d", tt.name, tt.rendered, len(charts))
+		}
+	}
+}
+
+// TestRendererJobWorkload tests that a release running a Job doesn't need a
+// production LB Service, that its Job is installed without any parallelism
+// for the capacity controller to scale, and that it can't have Deployments.
+func TestRendererJobWorkload(t *testing.T) {
+	it := buildInstallationTarget(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		buildChart("batch-job", "0.1.0"))
+	it.Spec.WorkloadKind = shipper.WorkloadKindJob
+
+	objects, err := FetchAndRenderChart(shippertesting.LocalFetchChart, it)
+	if err != nil {
+		t.Fatalf("expected rendered chart, got error instead: %s", err)
+	}
+
+	jobName := fmt.Sprintf("%s-batch-job", it.Name)
+	obj := findKubeObject(objects, "Job", jobName)
+	if obj == nil {
+		t.Fatalf("expected chart to render Job %q", jobName)
+	}
+
+	job := obj.(*batchv1.Job)
+	if job.Spec.Parallelism == nil || *job.Spec.Parallelism != 0 {
+		t.Errorf("expected Job to be installed with a parallelism of 0, got %v", job.Spec.Parallelism)
+	}
+	if *job.Spec.Completions != 8 {
+		t.Errorf("expected Job to keep its completions, got %d", *job.Spec.Completions)
+	}
+
+	it.Spec.Chart = buildChart(reviewsChartName, "0.0.1")
+	_, err = FetchAndRenderChart(shippertesting.LocalFetchChart, it)
+	if _, ok := err.(shippererrors.InvalidChartError); !ok {
+		t.Fatalf("expected InvalidChartError for a Job workload with a Deployment, got %v instead", err)
+	}
+}
+
 func validatePrimaryService(objects []runtime.Object, name string) error {
 	svcObj := findKubeObject(objects, "Service", name)
 	if svcObj == nil {
@@ -0,0 +1,103 @@
+package installation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+type syncedState struct {
+	hash string
+	at   time.Time
+}
+
+// observedState is everything that, if changed, makes a sync of an
+// InstallationTarget worth doing in full.
+type observedState struct {
+	Labels      map[string]string
+	Spec        shipper.InstallationTargetSpec
+	Deployments []string
+	Services    []string
+}
+
+// stateHash returns a hash of it and the resource versions of the
+// Deployments and Services of its release, as the controller's informers
+// see them.
+func (c *Controller) stateHash(it *shipper.InstallationTarget) (string, error) {
+	state := observedState{
+		Labels: it.Labels,
+		Spec:   it.Spec,
+	}
+
+	selector := labels.Set{shipper.ReleaseLabel: it.Labels[shipper.ReleaseLabel]}.AsSelector()
+
+	deployments, err := c.deploymentsLister.Deployments(it.Namespace).List(selector)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range deployments {
+		state.Deployments = append(state.Deployments, d.Name+"@"+d.ResourceVersion)
+	}
+	sort.Strings(state.Deployments)
+
+	services, err := c.servicesLister.Services(it.Namespace).List(selector)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range services {
+		state.Services = append(state.Services, s.Name+"@"+s.ResourceVersion)
+	}
+	sort.Strings(state.Services)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canSkipSync tells if the last full sync of the target at key saw the same
+// state hash less than fullResyncPeriod ago, and left it ready and healthy.
+func (c *Controller) canSkipSync(key, hash string, it *shipper.InstallationTarget) bool {
+	if c.fullResyncPeriod <= 0 || hash == "" {
+		return false
+	}
+
+	c.syncedStatesMu.Lock()
+	last, ok := c.syncedStates[key]
+	c.syncedStatesMu.Unlock()
+
+	if !ok || last.hash != hash || time.Since(last.at) >= c.fullResyncPeriod {
+		return false
+	}
+
+	healthy := targetutil.GetTargetCondition(it.Status.Conditions, shipper.TargetConditionTypeHealthy)
+	ready, _ := targetutil.IsReady(it.Status.Conditions)
+
+	return ready && healthy != nil && healthy.Status == corev1.ConditionTrue
+}
+
+// recordSyncedState remembers the state hash of a full sync of the target at
+// key, or forgets it if hash is empty.
+func (c *Controller) recordSyncedState(key, hash string) {
+	c.syncedStatesMu.Lock()
+	defer c.syncedStatesMu.Unlock()
+
+	if hash == "" {
+		delete(c.syncedStates, key)
+		return
+	}
+
+	c.syncedStates[key] = syncedState{hash: hash, at: time.Now()}
+}
@@ -0,0 +1,123 @@
+package installation
+
+import (
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// zoneSpreadWeight is the weight of the anti-affinity spreading pods
+// across zones. It's the highest there is, so that it wins over whatever
+// preferences the chart has.
+const zoneSpreadWeight = 100
+
+// zoneLabels are the node labels zones are told apart by when a release
+// doesn't say, in order of preference.
+var zoneLabels = []string{shipper.ZoneLabel, shipper.DeprecatedZoneLabel}
+
+// spreadAcrossZones makes the pods of the Deployments in objects avoid the
+// zones of this cluster that have more of the release's pods than others
+// already. Clusters whose nodes are all in the same zone are left alone,
+// as there's nothing to spread pods across.
+func (c *Controller) spreadAcrossZones(it *shipper.InstallationTarget, objects []runtime.Object) error {
+	nodes, err := c.nodesLister.List(labels.Everything())
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			corev1.SchemeGroupVersion.WithKind("Node"), "", labels.Everything(), err)
+	}
+
+	topologyKey, zones := clusterZones(nodes, it.Spec.TopologySpread.TopologyKey)
+	if len(zones) < 2 {
+		klog.V(4).Infof("Not spreading pods of InstallationTarget %s/%s, as the cluster has %d zones",
+			it.Namespace, it.Name, len(zones))
+		return nil
+	}
+
+	for _, obj := range objects {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			spreadPods(&d.Spec.Template.Spec, it.Labels[shipper.ReleaseLabel], topologyKey)
+		}
+	}
+
+	return nil
+}
+
+// clusterZones returns the node label zones are told apart by in a cluster
+// with nodes, which is topologyKey unless it's empty, and the zones nodes
+// are in.
+func clusterZones(nodes []*corev1.Node, topologyKey string) (string, []string) {
+	candidates := zoneLabels
+	if topologyKey != "" {
+		candidates = []string{topologyKey}
+	}
+
+	for _, key := range candidates {
+		seen := make(map[string]struct{})
+		for _, node := range nodes {
+			if zone, ok := node.Labels[key]; ok {
+				seen[zone] = struct{}{}
+			}
+		}
+
+		if len(seen) == 0 {
+			continue
+		}
+
+		zones := make([]string, 0, len(seen))
+		for zone := range seen {
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+
+		return key, zones
+	}
+
+	return candidates[0], nil
+}
+
+// spreadPods has pods prefer nodes in topologyKey domains with fewer pods
+// of releaseName than the others, unless they already have an
+// anti-affinity of their own on topologyKey.
+func spreadPods(spec *corev1.PodSpec, releaseName, topologyKey string) {
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.PodAntiAffinity == nil {
+		spec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	antiAffinity := spec.Affinity.PodAntiAffinity
+
+	for _, term := range antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey == topologyKey {
+			return
+		}
+	}
+	for _, term := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if term.PodAffinityTerm.TopologyKey == topologyKey {
+			return
+		}
+	}
+
+	antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: zoneSpreadWeight,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						shipper.ReleaseLabel: releaseName,
+					},
+				},
+				TopologyKey: topologyKey,
+			},
+		},
+	)
+}
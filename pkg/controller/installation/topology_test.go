@@ -0,0 +1,127 @@
+package installation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func TestClusterZones(t *testing.T) {
+	tests := []struct {
+		name        string
+		nodes       []*corev1.Node
+		topologyKey string
+		expectedKey string
+		expected    []string
+	}{
+		{
+			name: "zone label",
+			nodes: []*corev1.Node{
+				buildNode("a", map[string]string{shipper.ZoneLabel: "zone-b"}),
+				buildNode("b", map[string]string{shipper.ZoneLabel: "zone-a"}),
+				buildNode("c", map[string]string{shipper.ZoneLabel: "zone-a"}),
+			},
+			expectedKey: shipper.ZoneLabel,
+			expected:    []string{"zone-a", "zone-b"},
+		},
+		{
+			name: "deprecated zone label",
+			nodes: []*corev1.Node{
+				buildNode("a", map[string]string{shipper.DeprecatedZoneLabel: "zone-a"}),
+				buildNode("b", map[string]string{shipper.DeprecatedZoneLabel: "zone-b"}),
+			},
+			expectedKey: shipper.DeprecatedZoneLabel,
+			expected:    []string{"zone-a", "zone-b"},
+		},
+		{
+			name: "custom topology key",
+			nodes: []*corev1.Node{
+				buildNode("a", map[string]string{shipper.ZoneLabel: "zone-a", "rack": "1"}),
+				buildNode("b", map[string]string{shipper.ZoneLabel: "zone-a", "rack": "2"}),
+			},
+			topologyKey: "rack",
+			expectedKey: "rack",
+			expected:    []string{"1", "2"},
+		},
+		{
+			name:        "no zones",
+			nodes:       []*corev1.Node{buildNode("a", nil)},
+			expectedKey: shipper.ZoneLabel,
+			expected:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		key, zones := clusterZones(tt.nodes, tt.topologyKey)
+		if key != tt.expectedKey {
+			t.Errorf("%s: expected topology key %q, got %q", tt.name, tt.expectedKey, key)
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, zones)
+		if !eq {
+			t.Errorf("%s: unexpected zones:\n%s", tt.name, diff)
+		}
+	}
+}
+
+// TestSpreadPods verifies that pods are made to prefer zones with fewer
+// pods of their release, unless the chart spreads them on its own.
+func TestSpreadPods(t *testing.T) {
+	spec := &corev1.PodSpec{}
+	spreadPods(spec, "test-app-deadbeef-0", shipper.ZoneLabel)
+
+	expected := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: zoneSpreadWeight,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								shipper.ReleaseLabel: "test-app-deadbeef-0",
+							},
+						},
+						TopologyKey: shipper.ZoneLabel,
+					},
+				},
+			},
+		},
+	}
+	eq, diff := shippertesting.DeepEqualDiff(expected, spec.Affinity)
+	if !eq {
+		t.Errorf("unexpected affinity:\n%s", diff)
+	}
+
+	chartAffinity := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "test-app"},
+					},
+					TopologyKey: shipper.ZoneLabel,
+				},
+			},
+		},
+	}
+	spec = &corev1.PodSpec{Affinity: chartAffinity.DeepCopy()}
+	spreadPods(spec, "test-app-deadbeef-0", shipper.ZoneLabel)
+
+	eq, diff = shippertesting.DeepEqualDiff(chartAffinity, spec.Affinity)
+	if !eq {
+		t.Errorf("expected the chart's own anti-affinity to be left alone:\n%s", diff)
+	}
+}
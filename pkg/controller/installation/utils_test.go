@@ -1,6 +1,8 @@
 package installation
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -8,6 +10,7 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 )
 
 const (
@@ -29,12 +32,25 @@ var (
 		Type:   shipper.TargetConditionTypeReady,
 		Status: corev1.ConditionUnknown,
 	}
+	TargetConditionHealthy = shipper.TargetCondition{
+		Type:   shipper.TargetConditionTypeHealthy,
+		Status: corev1.ConditionTrue,
+	}
+	TargetConditionHealthyUnknown = shipper.TargetCondition{
+		Type:   shipper.TargetConditionTypeHealthy,
+		Status: corev1.ConditionUnknown,
+	}
 
 	SuccessStatus = shipper.InstallationTargetStatus{
 		Conditions: []shipper.TargetCondition{
+			TargetConditionHealthy,
 			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonConverged, 1, 1),
 			TargetConditionReady,
 		},
+		Charts: []shipper.ChartInstallationStatus{
+			{Name: nginxChartName, Version: "0.1.0", Installed: true},
+		},
 	}
 
 	apiResourceList = []*metav1.APIResourceList{
@@ -61,6 +77,15 @@ var (
 	}
 )
 
+func buildProgressingCondition(status corev1.ConditionStatus, reason string, installed, charts int) shipper.TargetCondition {
+	return shipper.TargetCondition{
+		Type:    shipper.TargetConditionTypeProgressing,
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf("%d/%d charts installed", installed, charts),
+	}
+}
+
 func newFixture(objects []runtime.Object) *shippertesting.ControllerTestFixture {
 	f := shippertesting.NewControllerTestFixture()
 	f.InitializeDiscovery(apiResourceList)
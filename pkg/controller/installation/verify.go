@@ -0,0 +1,115 @@
+package installation
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/registry"
+)
+
+// podSpecPaths are where pod specs live in the objects that run pods.
+var podSpecPaths = [][]string{
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// verifyImages returns an error for the first image in objects that comes
+// from a registry that isn't allowed, or that the image verifier rejects.
+func (c *Controller) verifyImages(objects []runtime.Object) error {
+	if len(c.allowedRegistries) == 0 && c.imageVerifier == nil {
+		return nil
+	}
+
+	images, err := objectImages(objects)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if !registryAllowed(image, c.allowedRegistries) {
+			return shippererrors.NewImageRegistryNotAllowedError(image)
+		}
+	}
+
+	if c.imageVerifier == nil {
+		return nil
+	}
+
+	for _, image := range images {
+		if err := c.imageVerifier.Verify(image); err != nil {
+			return shippererrors.NewImageVerificationError(image, err)
+		}
+	}
+
+	return nil
+}
+
+// objectImages returns the images of every container and init container
+// run by objects, sorted.
+func objectImages(objects []runtime.Object) ([]string, error) {
+	seen := map[string]struct{}{}
+	for _, obj := range objects {
+		u, err := toUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		paths := podSpecPaths
+		if u.GetKind() == "Pod" {
+			paths = [][]string{{"spec"}}
+		}
+
+		for _, path := range paths {
+			podSpec, ok, _ := unstructured.NestedMap(u.Object, path...)
+			if !ok {
+				continue
+			}
+
+			for _, field := range []string{"initContainers", "containers"} {
+				containers, _, _ := unstructured.NestedSlice(podSpec, field)
+				for _, c := range containers {
+					container, ok := c.(map[string]interface{})
+					if !ok {
+						continue
+					}
+
+					if image, ok := container["image"].(string); ok {
+						seen[image] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	return images, nil
+}
+
+func registryAllowed(image string, allowedRegistries []string) bool {
+	if len(allowedRegistries) == 0 {
+		return true
+	}
+
+	repository, err := registry.Repository(image)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range allowedRegistries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if repository == allowed || strings.HasPrefix(repository, allowed+"/") {
+			return true
+		}
+	}
+
+	return false
+}
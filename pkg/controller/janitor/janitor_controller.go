@@ -9,7 +9,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -21,9 +20,13 @@ import (
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
@@ -44,6 +47,10 @@ type Controller struct {
 	workqueue workqueue.RateLimitingInterface
 
 	recorder record.EventRecorder
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
 }
 
 func NewController(
@@ -51,6 +58,7 @@ func NewController(
 	store clusterclientstore.Interface,
 	informerFactory shipperinformers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	drainTimeout time.Duration,
 ) *Controller {
 	shipperv1alpha1 := informerFactory.Shipper().V1alpha1()
 	releaseInformer := shipperv1alpha1.Releases()
@@ -66,12 +74,14 @@ func NewController(
 		clusterLister:  clusterInformer.Lister(),
 		clustersSynced: clusterInformer.Informer().HasSynced,
 
-		workqueue: workqueue.NewNamedRateLimitingQueue(
+		workqueue: shipperworkqueue.NewNamedRateLimitingQueue(
 			shipperworkqueue.NewDefaultControllerRateLimiter(),
 			"janitor_controller",
 		),
 
 		recorder: recorder,
+
+		drainTimeout: drainTimeout,
 	}
 
 	releaseInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -79,7 +89,7 @@ func NewController(
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			controller.enqueue(newObj)
 		},
-		DeleteFunc: controller.enqueue,
+		DeleteFunc: objectutil.OnDelete(controller.enqueue),
 	})
 
 	store.AddSubscriptionCallback(func(kubeInformerFactory kubeinformers.SharedInformerFactory, shipperInformerFactory shipperinformers.SharedInformerFactory) {
@@ -92,7 +102,7 @@ func NewController(
 	store.AddEventHandlerCallback(func(kubeInformerFactory kubeinformers.SharedInformerFactory, shipperInformerFactory shipperinformers.SharedInformerFactory) {
 		eventHandler := cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.enqueue,
-			DeleteFunc: controller.enqueue,
+			DeleteFunc: objectutil.OnDelete(controller.enqueue),
 		}
 
 		shipperv1alpha1 := shipperInformerFactory.Shipper().V1alpha1()
@@ -120,18 +130,14 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Janitor controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(4).Info("Started Janitor controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -154,11 +160,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "Release", key)
 	err := c.syncHandler(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Release", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error running garbage collection for Release %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -220,6 +230,10 @@ func (c *Controller) syncHandler(key string) error {
 		klog.V(4).Infof("Successfully removed orphaned objects for Release %q in cluster %s", key, cluster)
 	}
 
+	if rel != nil {
+		return c.endIncumbentStandby(rel)
+	}
+
 	return nil
 }
 
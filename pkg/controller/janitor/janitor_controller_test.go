@@ -10,6 +10,7 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 )
 
 const (
@@ -158,6 +159,7 @@ func runController(f *shippertesting.ControllerTestFixture) {
 		f.ClusterClientStore,
 		f.ShipperInformerFactory,
 		f.Recorder,
+		shutdown.DefaultDrainTimeout,
 	)
 
 	stopCh := make(chan struct{})
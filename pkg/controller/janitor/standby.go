@@ -0,0 +1,125 @@
+package janitor
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// scaleToZeroPatch scales a capacity target to zero.
+var scaleToZeroPatch = []byte(`{"spec":{"percent":0}}`)
+
+// endIncumbentStandby ends the standby of the incumbent of rel's
+// application, when rel is either the incumbent or the contender, once the
+// contender has been complete for the standby's retention. The incumbent is
+// then annotated with it, which makes the release controller stop keeping
+// it on standby, and its capacity targets are scaled to zero.
+func (c *Controller) endIncumbentStandby(rel *shipper.Release) error {
+	appName, err := objectutil.GetApplicationLabel(rel)
+	if err != nil {
+		return err
+	}
+
+	releases, err := c.releaseLister.Releases(rel.Namespace).ReleasesForApplication(appName)
+	if err != nil {
+		return err
+	}
+	if len(releases) < 2 {
+		return nil
+	}
+
+	prev, succ, err := releaseutil.GetSiblingReleases(rel, releases)
+	if err != nil {
+		return err
+	}
+
+	incumbent, contender := prev, rel
+	if succ != nil {
+		_, succSucc, err := releaseutil.GetSiblingReleases(succ, releases)
+		if err != nil {
+			return err
+		}
+
+		// Only the release right before the head is ever on standby.
+		if succSucc != nil {
+			return nil
+		}
+
+		incumbent, contender = rel, succ
+	}
+
+	if incumbent == nil || contender.Spec.Environment.Strategy == nil {
+		return nil
+	}
+
+	standby := contender.Spec.Environment.Strategy.IncumbentStandby
+	if standby == nil {
+		return nil
+	}
+	if _, ended := incumbent.Annotations[shipper.StandbyEndedAnnotation]; ended {
+		return nil
+	}
+
+	// A contender moved back from its last step is being rolled back, and
+	// needs its incumbent.
+	if !releaseutil.IsLastStrategyStep(contender) {
+		return nil
+	}
+
+	complete := releaseutil.GetReleaseCondition(contender.Status, shipper.ReleaseConditionTypeComplete)
+	if complete == nil || complete.Status != corev1.ConditionTrue {
+		return nil
+	}
+
+	now := time.Now()
+	remaining := complete.LastTransitionTime.Add(standby.Retention.Duration).Sub(now)
+	if remaining > 0 {
+		c.workqueue.AddAfter(objectutil.MetaKey(incumbent), remaining)
+		return nil
+	}
+
+	incumbent = incumbent.DeepCopy()
+	if incumbent.Annotations == nil {
+		incumbent.Annotations = map[string]string{}
+	}
+	incumbent.Annotations[shipper.StandbyEndedAnnotation] = now.UTC().Format(time.RFC3339)
+
+	_, err = c.clientset.ShipperV1alpha1().Releases(incumbent.Namespace).Update(incumbent)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(incumbent, err).
+			WithShipperKind("Release")
+	}
+
+	for _, clusterName := range releaseutil.GetSelectedClusters(incumbent) {
+		clusterClientsets, err := c.store.GetApplicationClusterClientset(clusterName, AgentName)
+		if err != nil {
+			return err
+		}
+
+		_, err = clusterClientsets.GetShipperClient().ShipperV1alpha1().
+			CapacityTargets(incumbent.Namespace).
+			Patch(incumbent.Name, types.MergePatchType, scaleToZeroPatch)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return shippererrors.NewKubeclientPatchError(incumbent.Namespace, incumbent.Name, err).
+				WithShipperKind("CapacityTarget")
+		}
+	}
+
+	c.recorder.Eventf(
+		incumbent,
+		corev1.EventTypeNormal,
+		shipperevents.IncumbentStandbyEnded,
+		"standby ended %s after %q completed, scaled down to zero",
+		standby.Retention.Duration, contender.Name,
+	)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package janitor
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// TestIncumbentStandbyEnds verifies that incumbents kept on standby are
+// scaled down to zero once their contender has been complete for the
+// standby's retention, and not before, unless their contender was rolled
+// back from its last step since.
+func TestIncumbentStandbyEnds(t *testing.T) {
+	tests := []struct {
+		name           string
+		completedSince time.Duration
+		targetStep     int32
+		ended          bool
+	}{
+		{"retention over", 2 * time.Hour, 1, true},
+		{"still retained", 30 * time.Minute, 1, false},
+		{"rolled back", 2 * time.Hour, 0, false},
+	}
+
+	for _, tt := range tests {
+		incumbent := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "incumbent", []string{clusterA})
+		incumbent.Annotations[shipper.ReleaseGenerationAnnotation] = "0"
+
+		contender := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "contender", []string{clusterA})
+		contender.Annotations[shipper.ReleaseGenerationAnnotation] = "1"
+		contender.Spec.TargetStep = tt.targetStep
+		contender.Spec.Environment.Strategy = &shipper.RolloutStrategy{
+			Steps: []shipper.RolloutStrategyStep{{Name: "staging"}, {Name: "full on"}},
+			IncumbentStandby: &shipper.IncumbentStandby{
+				Capacity:  intstr.FromString("5%"),
+				Retention: metav1.Duration{Duration: time.Hour},
+			},
+		}
+		contender.Status.Conditions = []shipper.ReleaseCondition{
+			{
+				Type:               shipper.ReleaseConditionTypeComplete,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-tt.completedSince)),
+			},
+		}
+
+		it, tr, ct := shippertesting.BuildTargetObjectsForRelease(incumbent)
+		ct.Spec.Percent = 5
+
+		f := shippertesting.NewManagementControllerTestFixture(
+			[]runtime.Object{buildCluster(clusterA), incumbent, contender},
+			map[string][]runtime.Object{
+				clusterA: []runtime.Object{it, ct, tr},
+			},
+		)
+
+		runController(f)
+
+		object, err := f.ShipperClient.Tracker().Get(
+			shipper.SchemeGroupVersion.WithResource("releases"), incumbent.Namespace, incumbent.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, ended := object.(*shipper.Release).Annotations[shipper.StandbyEndedAnnotation]
+		if ended != tt.ended {
+			t.Errorf("%s: expected standby to have ended: %t, got %t", tt.name, tt.ended, ended)
+		}
+
+		object, err = f.Clusters[clusterA].ShipperClient.Tracker().Get(
+			shipper.SchemeGroupVersion.WithResource("capacitytargets"), incumbent.Namespace, incumbent.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := int32(5)
+		if tt.ended {
+			expected = 0
+		}
+		if percent := object.(*shipper.CapacityTarget).Spec.Percent; percent != expected {
+			t.Errorf("%s: expected incumbent to be at %d%% capacity, got %d%%", tt.name, expected, percent)
+		}
+	}
+}
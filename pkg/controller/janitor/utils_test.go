@@ -18,7 +18,8 @@ func buildRelease(
 			Namespace: namespace,
 			Name:      fmt.Sprintf("%s-%s", app, name),
 			Annotations: map[string]string{
-				shipper.ReleaseClustersAnnotation: clustersStr,
+				shipper.ReleaseClustersAnnotation:   clustersStr,
+				shipper.ReleaseGenerationAnnotation: "0",
 			},
 			Labels: map[string]string{
 				shipper.AppLabel: app,
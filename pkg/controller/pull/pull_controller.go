@@ -0,0 +1,441 @@
+package pull
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
+)
+
+const (
+	AgentName = "pull-controller"
+)
+
+// Controller is the agent of a cluster in pull mode. It keeps the
+// installation, capacity and traffic targets in the cluster in sync with
+// their copies in the management cluster, and reports their status back.
+// Targets share the name of their release, so they're synced together,
+// keyed by it.
+type Controller struct {
+	clientset     shipperclient.Interface
+	mgmtClientset shipperclient.Interface
+
+	installationTargetLister shipperlisters.InstallationTargetLister
+	capacityTargetLister     shipperlisters.CapacityTargetLister
+	trafficTargetLister      shipperlisters.TrafficTargetLister
+
+	mgmtInstallationTargetLister shipperlisters.InstallationTargetLister
+	mgmtCapacityTargetLister     shipperlisters.CapacityTargetLister
+	mgmtTrafficTargetLister      shipperlisters.TrafficTargetLister
+
+	cacheSyncs []cache.InformerSynced
+
+	workqueue workqueue.RateLimitingInterface
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+}
+
+// NewController returns a new pull controller. mgmtClientset and
+// mgmtInformerFactory are for the copies of the targets in the management
+// cluster, so they must go through a clientset from pull.NewClientset.
+func NewController(
+	clientset shipperclient.Interface,
+	informerFactory shipperinformers.SharedInformerFactory,
+	mgmtClientset shipperclient.Interface,
+	mgmtInformerFactory shipperinformers.SharedInformerFactory,
+	drainTimeout time.Duration,
+) *Controller {
+	shipperv1alpha1 := informerFactory.Shipper().V1alpha1()
+	itInformer := shipperv1alpha1.InstallationTargets()
+	ctInformer := shipperv1alpha1.CapacityTargets()
+	ttInformer := shipperv1alpha1.TrafficTargets()
+
+	mgmtv1alpha1 := mgmtInformerFactory.Shipper().V1alpha1()
+	mgmtItInformer := mgmtv1alpha1.InstallationTargets()
+	mgmtCtInformer := mgmtv1alpha1.CapacityTargets()
+	mgmtTtInformer := mgmtv1alpha1.TrafficTargets()
+
+	controller := &Controller{
+		clientset:     clientset,
+		mgmtClientset: mgmtClientset,
+
+		installationTargetLister: itInformer.Lister(),
+		capacityTargetLister:     ctInformer.Lister(),
+		trafficTargetLister:      ttInformer.Lister(),
+
+		mgmtInstallationTargetLister: mgmtItInformer.Lister(),
+		mgmtCapacityTargetLister:     mgmtCtInformer.Lister(),
+		mgmtTrafficTargetLister:      mgmtTtInformer.Lister(),
+
+		workqueue: shipperworkqueue.NewNamedRateLimitingQueue(
+			shipperworkqueue.NewDefaultControllerRateLimiter(),
+			"pull_controller",
+		),
+
+		drainTimeout: drainTimeout,
+	}
+
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			controller.enqueue(newObj)
+		},
+		DeleteFunc: objectutil.OnDelete(controller.enqueue),
+	}
+
+	informers := []cache.SharedIndexInformer{
+		itInformer.Informer(),
+		ctInformer.Informer(),
+		ttInformer.Informer(),
+		mgmtItInformer.Informer(),
+		mgmtCtInformer.Informer(),
+		mgmtTtInformer.Informer(),
+	}
+	for _, informer := range informers {
+		informer.AddEventHandler(eventHandler)
+		controller.cacheSyncs = append(controller.cacheSyncs, informer.HasSynced)
+	}
+
+	return controller
+}
+
+func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.workqueue.ShutDown()
+
+	klog.V(2).Info("Starting Pull controller")
+	defer klog.V(2).Info("Shutting down Pull controller")
+
+	if ok := cache.WaitForCacheSync(stopCh, c.cacheSyncs...); !ok {
+		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
+		return
+	}
+
+	workers := shutdown.NewWorkers("Pull controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
+
+	klog.V(4).Info("Started Pull controller")
+
+	<-stopCh
+
+	workers.Drain(c.drainTimeout)
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	obj, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+
+	defer c.workqueue.Done(obj)
+
+	var (
+		key string
+		ok  bool
+	)
+
+	if key, ok = obj.(string); !ok {
+		c.workqueue.Forget(obj)
+		runtime.HandleError(fmt.Errorf("invalid object key (will retry: false): %#v", obj))
+		return true
+	}
+
+	shouldRetry := false
+	span := tracing.StartSync(AgentName, "Release", key)
+	err := c.syncHandler(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Release", key, err)
+
+	if err != nil {
+		shouldRetry = shippererrors.ShouldRetry(err)
+		runtime.HandleError(fmt.Errorf("error pulling targets for Release %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
+	}
+
+	if shouldRetry {
+		c.workqueue.AddRateLimited(key)
+		return true
+	}
+
+	c.workqueue.Forget(obj)
+	klog.V(4).Infof("Successfully pulled targets for Release %q", key)
+
+	return true
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	c.workqueue.Add(key)
+}
+
+func (c *Controller) syncHandler(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return shippererrors.NewUnrecoverableError(err)
+	}
+
+	if err := c.syncInstallationTarget(namespace, name); err != nil {
+		return err
+	}
+
+	if err := c.syncCapacityTarget(namespace, name); err != nil {
+		return err
+	}
+
+	return c.syncTrafficTarget(namespace, name)
+}
+
+func (c *Controller) syncInstallationTarget(namespace, name string) error {
+	mirror, err := c.mgmtInstallationTargetLister.InstallationTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("InstallationTarget")
+	}
+
+	it, err := c.installationTargetLister.InstallationTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("InstallationTarget")
+	}
+
+	client := c.clientset.ShipperV1alpha1().InstallationTargets(namespace)
+
+	if mirror == nil {
+		if it == nil || !isPulled(it) {
+			return nil
+		}
+
+		klog.V(4).Infof("InstallationTarget \"%s/%s\" is gone from the management cluster; deleting it", namespace, name)
+		err := client.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return shippererrors.NewKubeclientDeleteError(namespace, name, err).
+				WithShipperKind("InstallationTarget")
+		}
+
+		return nil
+	}
+
+	if it == nil {
+		it = &shipper.InstallationTarget{
+			ObjectMeta: pulledObjectMeta(mirror.ObjectMeta),
+			Spec:       mirror.Spec,
+		}
+
+		_, err := client.Create(it)
+		if err != nil {
+			return shippererrors.NewKubeclientCreateError(it, err)
+		}
+
+		// There's no status to report until the target is synced.
+		return nil
+	}
+
+	if !inSync(it.ObjectMeta, mirror.ObjectMeta) || !reflect.DeepEqual(it.Spec, mirror.Spec) {
+		it = it.DeepCopy()
+		it.Labels = mirror.Labels
+		it.Annotations = pulledAnnotations(mirror.ObjectMeta)
+		it.Spec = mirror.Spec
+
+		updated, err := client.Update(it)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(it, err)
+		}
+		it = updated
+	}
+
+	if reflect.DeepEqual(it.Status, mirror.Status) {
+		return nil
+	}
+
+	mirror = mirror.DeepCopy()
+	mirror.Status = it.Status
+
+	// InstallationTargets have no status subresource.
+	_, err = c.mgmtClientset.ShipperV1alpha1().InstallationTargets(namespace).Update(mirror)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(mirror, err)
+	}
+
+	return nil
+}
+
+func (c *Controller) syncCapacityTarget(namespace, name string) error {
+	mirror, err := c.mgmtCapacityTargetLister.CapacityTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("CapacityTarget")
+	}
+
+	ct, err := c.capacityTargetLister.CapacityTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("CapacityTarget")
+	}
+
+	client := c.clientset.ShipperV1alpha1().CapacityTargets(namespace)
+
+	if mirror == nil {
+		if ct == nil || !isPulled(ct) {
+			return nil
+		}
+
+		klog.V(4).Infof("CapacityTarget \"%s/%s\" is gone from the management cluster; deleting it", namespace, name)
+		err := client.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return shippererrors.NewKubeclientDeleteError(namespace, name, err).
+				WithShipperKind("CapacityTarget")
+		}
+
+		return nil
+	}
+
+	if ct == nil {
+		ct = &shipper.CapacityTarget{
+			ObjectMeta: pulledObjectMeta(mirror.ObjectMeta),
+			Spec:       mirror.Spec,
+		}
+
+		_, err := client.Create(ct)
+		if err != nil {
+			return shippererrors.NewKubeclientCreateError(ct, err)
+		}
+
+		return nil
+	}
+
+	specInSync := reflect.DeepEqual(ct.Spec, mirror.Spec)
+	if !inSync(ct.ObjectMeta, mirror.ObjectMeta) || !specInSync {
+		ct = ct.DeepCopy()
+		ct.Labels = mirror.Labels
+		ct.Annotations = pulledAnnotations(mirror.ObjectMeta)
+		ct.Spec = mirror.Spec
+
+		updated, err := client.Update(ct)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(ct, err)
+		}
+		ct = updated
+	}
+
+	status := ct.Status.DeepCopy()
+	status.ObservedGeneration = observedGeneration(
+		specInSync, ct.ObjectMeta, ct.Status.ObservedGeneration,
+		mirror.ObjectMeta, mirror.Status.ObservedGeneration)
+
+	if reflect.DeepEqual(*status, mirror.Status) {
+		return nil
+	}
+
+	mirror = mirror.DeepCopy()
+	mirror.Status = *status
+
+	_, err = c.mgmtClientset.ShipperV1alpha1().CapacityTargets(namespace).UpdateStatus(mirror)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(mirror, err)
+	}
+
+	return nil
+}
+
+func (c *Controller) syncTrafficTarget(namespace, name string) error {
+	mirror, err := c.mgmtTrafficTargetLister.TrafficTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("TrafficTarget")
+	}
+
+	tt, err := c.trafficTargetLister.TrafficTargets(namespace).Get(name)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(namespace, name, err).
+			WithShipperKind("TrafficTarget")
+	}
+
+	client := c.clientset.ShipperV1alpha1().TrafficTargets(namespace)
+
+	if mirror == nil {
+		if tt == nil || !isPulled(tt) {
+			return nil
+		}
+
+		klog.V(4).Infof("TrafficTarget \"%s/%s\" is gone from the management cluster; deleting it", namespace, name)
+		err := client.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return shippererrors.NewKubeclientDeleteError(namespace, name, err).
+				WithShipperKind("TrafficTarget")
+		}
+
+		return nil
+	}
+
+	if tt == nil {
+		tt = &shipper.TrafficTarget{
+			ObjectMeta: pulledObjectMeta(mirror.ObjectMeta),
+			Spec:       mirror.Spec,
+		}
+
+		_, err := client.Create(tt)
+		if err != nil {
+			return shippererrors.NewKubeclientCreateError(tt, err)
+		}
+
+		return nil
+	}
+
+	specInSync := reflect.DeepEqual(tt.Spec, mirror.Spec)
+	if !inSync(tt.ObjectMeta, mirror.ObjectMeta) || !specInSync {
+		tt = tt.DeepCopy()
+		tt.Labels = mirror.Labels
+		tt.Annotations = pulledAnnotations(mirror.ObjectMeta)
+		tt.Spec = mirror.Spec
+
+		updated, err := client.Update(tt)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(tt, err)
+		}
+		tt = updated
+	}
+
+	status := tt.Status.DeepCopy()
+	status.ObservedGeneration = observedGeneration(
+		specInSync, tt.ObjectMeta, tt.Status.ObservedGeneration,
+		mirror.ObjectMeta, mirror.Status.ObservedGeneration)
+
+	if reflect.DeepEqual(*status, mirror.Status) {
+		return nil
+	}
+
+	mirror = mirror.DeepCopy()
+	mirror.Status = *status
+
+	_, err = c.mgmtClientset.ShipperV1alpha1().TrafficTargets(namespace).UpdateStatus(mirror)
+	if err != nil {
+		return shippererrors.NewKubeclientUpdateError(mirror, err)
+	}
+
+	return nil
+}
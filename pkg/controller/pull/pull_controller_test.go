@@ -0,0 +1,247 @@
+package pull
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	"github.com/bookingcom/shipper/pkg/pull"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+)
+
+const (
+	testCluster = "cluster-a"
+	testRelease = "test-release"
+)
+
+type fixture struct {
+	t *testing.T
+
+	client     *shipperfake.Clientset
+	mgmtClient *shipperfake.Clientset
+}
+
+func runController(t *testing.T, objects, mgmtObjects []runtime.Object) *fixture {
+	f := &fixture{
+		t:          t,
+		client:     shipperfake.NewSimpleClientset(objects...),
+		mgmtClient: shipperfake.NewSimpleClientset(mgmtObjects...),
+	}
+
+	const noResyncPeriod time.Duration = 0
+	mgmtClientset := pull.NewClientset(f.mgmtClient, testCluster)
+	informerFactory := shipperinformers.NewSharedInformerFactory(f.client, noResyncPeriod)
+	mgmtInformerFactory := shipperinformers.NewSharedInformerFactory(mgmtClientset, noResyncPeriod)
+
+	c := NewController(f.client, informerFactory, mgmtClientset, mgmtInformerFactory, shutdown.DefaultDrainTimeout)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	informerFactory.Start(stopCh)
+	mgmtInformerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+	mgmtInformerFactory.WaitForCacheSync(stopCh)
+
+	key := shippertesting.TestNamespace + "/" + testRelease
+	if err := c.syncHandler(key); err != nil {
+		t.Fatalf("unexpected error syncing %q: %s", key, err)
+	}
+
+	return f
+}
+
+func buildMirrorMeta(generation int64) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:       pull.MirrorName(testRelease, testCluster),
+		Namespace:  shippertesting.TestNamespace,
+		Generation: generation,
+		Labels: map[string]string{
+			shipper.ReleaseLabel:     testRelease,
+			shipper.PullClusterLabel: testCluster,
+		},
+	}
+}
+
+func buildLocalMeta(generation int64) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:       testRelease,
+		Namespace:  shippertesting.TestNamespace,
+		Generation: generation,
+		Labels: map[string]string{
+			shipper.ReleaseLabel: testRelease,
+		},
+		Annotations: map[string]string{
+			shipper.PulledAnnotation: "true",
+		},
+	}
+}
+
+// TestPullCreatesTargets verifies that targets meant for the cluster are
+// created in it, as they are in the management cluster.
+func TestPullCreatesTargets(t *testing.T) {
+	mgmtObjects := []runtime.Object{
+		&shipper.InstallationTarget{ObjectMeta: buildMirrorMeta(1)},
+		&shipper.CapacityTarget{
+			ObjectMeta: buildMirrorMeta(1),
+			Spec:       shipper.CapacityTargetSpec{Percent: 50},
+		},
+		&shipper.TrafficTarget{
+			ObjectMeta: buildMirrorMeta(1),
+			Spec:       shipper.TrafficTargetSpec{Weight: 10},
+		},
+		// Copies for other clusters are none of this one's business.
+		&shipper.InstallationTarget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pull.MirrorName("other-release", "cluster-b"),
+				Namespace: shippertesting.TestNamespace,
+				Labels:    map[string]string{shipper.PullClusterLabel: "cluster-b"},
+			},
+		},
+	}
+
+	f := runController(t, nil, mgmtObjects)
+
+	v1alpha1 := f.client.ShipperV1alpha1()
+	ct, err := v1alpha1.CapacityTargets(shippertesting.TestNamespace).Get(testRelease, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected capacity target to be created: %s", err)
+	}
+
+	if ct.Spec.Percent != 50 {
+		t.Errorf("expected capacity target for 50 percent, got %d", ct.Spec.Percent)
+	}
+
+	if _, ok := ct.Labels[shipper.PullClusterLabel]; ok {
+		t.Errorf("expected capacity target not to be labelled with its cluster")
+	}
+
+	if _, ok := ct.Annotations[shipper.PulledAnnotation]; !ok {
+		t.Errorf("expected capacity target to be annotated as pulled")
+	}
+
+	tt, err := v1alpha1.TrafficTargets(shippertesting.TestNamespace).Get(testRelease, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected traffic target to be created: %s", err)
+	}
+
+	if tt.Spec.Weight != 10 {
+		t.Errorf("expected traffic target for weight 10, got %d", tt.Spec.Weight)
+	}
+
+	its, err := v1alpha1.InstallationTargets(shippertesting.TestNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error listing installation targets: %s", err)
+	}
+
+	if len(its.Items) != 1 || its.Items[0].Name != testRelease {
+		t.Errorf("expected only installation target %q to be created, got %v", testRelease, its.Items)
+	}
+}
+
+// TestPullReportsStatus verifies that the status of targets is reported to
+// the management cluster, for the generation of their copies there they
+// have synced.
+func TestPullReportsStatus(t *testing.T) {
+	spec := shipper.CapacityTargetSpec{Percent: 100}
+	mirror := &shipper.CapacityTarget{
+		ObjectMeta: buildMirrorMeta(2),
+		Spec:       spec,
+		Status:     shipper.CapacityTargetStatus{ObservedGeneration: 1},
+	}
+	ct := &shipper.CapacityTarget{
+		ObjectMeta: buildLocalMeta(5),
+		Spec:       spec,
+		Status: shipper.CapacityTargetStatus{
+			ObservedGeneration: 5,
+			AvailableReplicas:  3,
+			AchievedPercent:    100,
+		},
+	}
+
+	f := runController(t, []runtime.Object{ct}, []runtime.Object{mirror})
+
+	got, err := f.mgmtClient.ShipperV1alpha1().CapacityTargets(shippertesting.TestNamespace).
+		Get(mirror.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting capacity target: %s", err)
+	}
+
+	if got.Status.ObservedGeneration != mirror.Generation {
+		t.Errorf("expected observed generation %d, got %d", mirror.Generation, got.Status.ObservedGeneration)
+	}
+
+	if got.Status.AchievedPercent != 100 || got.Status.AvailableReplicas != 3 {
+		t.Errorf("expected status of capacity target to be reported, got %+v", got.Status)
+	}
+}
+
+// TestPullKeepsObservedGenerationUntilSynced verifies that the status of
+// targets whose spec just changed isn't taken as the status of the new
+// spec.
+func TestPullKeepsObservedGenerationUntilSynced(t *testing.T) {
+	mirror := &shipper.TrafficTarget{
+		ObjectMeta: buildMirrorMeta(2),
+		Spec:       shipper.TrafficTargetSpec{Weight: 100},
+		Status:     shipper.TrafficTargetStatus{ObservedGeneration: 1},
+	}
+	tt := &shipper.TrafficTarget{
+		ObjectMeta: buildLocalMeta(1),
+		Spec:       shipper.TrafficTargetSpec{Weight: 0},
+		Status: shipper.TrafficTargetStatus{
+			ObservedGeneration: 1,
+			AchievedTraffic:    0,
+		},
+	}
+
+	f := runController(t, []runtime.Object{tt}, []runtime.Object{mirror})
+
+	local, err := f.client.ShipperV1alpha1().TrafficTargets(shippertesting.TestNamespace).
+		Get(testRelease, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting traffic target: %s", err)
+	}
+
+	if local.Spec.Weight != 100 {
+		t.Errorf("expected traffic target to be updated to weight 100, got %d", local.Spec.Weight)
+	}
+
+	got, err := f.mgmtClient.ShipperV1alpha1().TrafficTargets(shippertesting.TestNamespace).
+		Get(mirror.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting traffic target: %s", err)
+	}
+
+	if got.Status.ObservedGeneration != 1 {
+		t.Errorf("expected observed generation to stay at 1, got %d", got.Status.ObservedGeneration)
+	}
+}
+
+// TestPullDeletesTargets verifies that targets pulled from the management
+// cluster are deleted once they're gone from it, and that others are left
+// alone.
+func TestPullDeletesTargets(t *testing.T) {
+	it := &shipper.InstallationTarget{ObjectMeta: buildLocalMeta(1)}
+
+	ct := &shipper.CapacityTarget{ObjectMeta: buildLocalMeta(1)}
+	ct.Annotations = nil
+
+	f := runController(t, []runtime.Object{it, ct}, nil)
+
+	v1alpha1 := f.client.ShipperV1alpha1()
+	_, err := v1alpha1.InstallationTargets(shippertesting.TestNamespace).Get(testRelease, metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("expected pulled installation target to be deleted")
+	}
+
+	_, err = v1alpha1.CapacityTargets(shippertesting.TestNamespace).Get(testRelease, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("expected capacity target that wasn't pulled to be left alone: %s", err)
+	}
+}
@@ -0,0 +1,59 @@
+package pull
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// pulledObjectMeta returns the metadata of a new target pulled from mirror.
+func pulledObjectMeta(mirror metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        mirror.Name,
+		Namespace:   mirror.Namespace,
+		Labels:      mirror.Labels,
+		Annotations: pulledAnnotations(mirror),
+	}
+}
+
+// pulledAnnotations returns the annotations of mirror, marked as pulled, so
+// only targets this controller created are deleted along with their copies
+// in the management cluster.
+func pulledAnnotations(mirror metav1.ObjectMeta) map[string]string {
+	annotations := make(map[string]string, len(mirror.Annotations)+1)
+	for k, v := range mirror.Annotations {
+		annotations[k] = v
+	}
+	annotations[shipper.PulledAnnotation] = "true"
+
+	return annotations
+}
+
+func isPulled(obj metav1.Object) bool {
+	_, ok := obj.GetAnnotations()[shipper.PulledAnnotation]
+	return ok
+}
+
+// inSync tells whether the metadata of a pulled target is the one of its
+// copy in the management cluster.
+func inSync(local, mirror metav1.ObjectMeta) bool {
+	return labels.Equals(local.Labels, mirror.Labels) &&
+		labels.Equals(local.Annotations, pulledAnnotations(mirror))
+}
+
+// observedGeneration returns the generation of a copy in the management
+// cluster that the status of its target speaks for. That's the copy's
+// current one only once the target has the same spec and has been synced
+// since it last changed. Until then, the copy keeps the one it had.
+func observedGeneration(
+	specInSync bool,
+	local metav1.ObjectMeta, localObservedGeneration int64,
+	mirror metav1.ObjectMeta, mirrorObservedGeneration int64,
+) int64 {
+	if specInSync && localObservedGeneration >= local.Generation {
+		return mirror.Generation
+	}
+
+	return mirrorObservedGeneration
+}
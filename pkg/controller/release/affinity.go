@@ -0,0 +1,95 @@
+package release
+
+import (
+	"sort"
+	"strings"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// filterClustersByAffinity masks a cluster list according to the release's
+// application affinity rules: only clusters where every affine Application
+// currently runs are kept, and clusters hosting any Release of an
+// anti-affine Application are dropped.
+func filterClustersByAffinity(
+	releaseLister shipperlisters.ReleaseLister,
+	rel *shipper.Release,
+	clusters []*shipper.Cluster,
+) ([]*shipper.Cluster, error) {
+	requirements := rel.Spec.Environment.ClusterRequirements
+	if len(requirements.ApplicationAffinity) == 0 && len(requirements.ApplicationAntiAffinity) == 0 {
+		return clusters, nil
+	}
+
+	allowed := map[string]bool{}
+	for _, cluster := range clusters {
+		allowed[cluster.Name] = true
+	}
+
+	for _, app := range requirements.ApplicationAffinity {
+		rels, err := applicationReleases(releaseLister, rel.Namespace, app)
+		if err != nil {
+			return nil, err
+		}
+
+		// The most recent Release with clusters is where the
+		// Application is headed, so that is what we follow.
+		var appClusters []string
+		for _, appRel := range releaseutil.SortByGenerationDescending(rels) {
+			if appClusters = releaseutil.GetSelectedClusters(appRel); len(appClusters) > 0 {
+				break
+			}
+		}
+
+		if len(appClusters) == 0 {
+			return nil, shippererrors.NewUnsatisfiedApplicationAffinityError(app)
+		}
+
+		sort.Strings(appClusters)
+		for name := range allowed {
+			if i := sort.SearchStrings(appClusters, name); i == len(appClusters) || appClusters[i] != name {
+				allowed[name] = false
+			}
+		}
+	}
+
+	for _, app := range requirements.ApplicationAntiAffinity {
+		rels, err := applicationReleases(releaseLister, rel.Namespace, app)
+		if err != nil {
+			return nil, err
+		}
+
+		// Older Releases may still have pods around, so all of them
+		// count.
+		for _, appRel := range rels {
+			for _, name := range releaseutil.GetSelectedClusters(appRel) {
+				allowed[name] = false
+			}
+		}
+	}
+
+	filtered := make([]*shipper.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if allowed[cluster.Name] {
+			filtered = append(filtered, cluster)
+		}
+	}
+
+	return filtered, nil
+}
+
+// applicationReleases lists the Releases of an Application referred to as
+// either "<name>" in namespace, or "<namespace>/<name>".
+func applicationReleases(
+	releaseLister shipperlisters.ReleaseLister,
+	namespace, app string,
+) ([]*shipper.Release, error) {
+	if parts := strings.SplitN(app, "/", 2); len(parts) == 2 {
+		namespace, app = parts[0], parts[1]
+	}
+
+	return releaseLister.Releases(namespace).ReleasesForApplication(app)
+}
@@ -0,0 +1,110 @@
+package release
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestFilterClustersByAffinity(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, rel := range []*shipper.Release{
+		buildAffinityRelease(shippertesting.TestNamespace, "database", "database-0", "0", "cluster-0,cluster-1"),
+		buildAffinityRelease(shippertesting.TestNamespace, "database", "database-1", "1", "cluster-1,cluster-2"),
+		buildAffinityRelease("batch", "crunch", "crunch-0", "0", "cluster-2"),
+	} {
+		indexer.Add(rel)
+	}
+	lister := shipperlisters.NewReleaseLister(indexer)
+
+	clusters := []*shipper.Cluster{
+		generateClusterForTestCase(0, shipper.ClusterSpec{}),
+		generateClusterForTestCase(1, shipper.ClusterSpec{}),
+		generateClusterForTestCase(2, shipper.ClusterSpec{}),
+	}
+
+	tests := []struct {
+		name         string
+		affinity     []string
+		antiAffinity []string
+		expected     []string
+	}{
+		{
+			name:     "affinity follows the latest release",
+			affinity: []string{"database"},
+			expected: []string{"cluster-1", "cluster-2"},
+		},
+		{
+			name:         "anti-affinity across namespaces",
+			affinity:     []string{"database"},
+			antiAffinity: []string{"batch/crunch"},
+			expected:     []string{"cluster-1"},
+		},
+		{
+			name:         "anti-affinity counts older releases",
+			antiAffinity: []string{"database"},
+			expected:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		rel := generateReleaseForTestCase(shipper.ClusterRequirements{
+			ApplicationAffinity:     tt.affinity,
+			ApplicationAntiAffinity: tt.antiAffinity,
+		})
+
+		filtered, err := filterClustersByAffinity(lister, rel, clusters)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.name, err)
+		}
+
+		names := make([]string, 0, len(filtered))
+		for _, cluster := range filtered {
+			names = append(names, cluster.Name)
+		}
+
+		if len(names) != len(tt.expected) {
+			t.Errorf("%s: expected clusters %v, got %v", tt.name, tt.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expected[i] {
+				t.Errorf("%s: expected clusters %v, got %v", tt.name, tt.expected, names)
+				break
+			}
+		}
+	}
+
+	rel := generateReleaseForTestCase(shipper.ClusterRequirements{
+		ApplicationAffinity: []string{"not-scheduled-yet"},
+	})
+	_, err := filterClustersByAffinity(lister, rel, clusters)
+	if _, ok := err.(shippererrors.UnsatisfiedApplicationAffinityError); !ok {
+		t.Fatalf("expected an UnsatisfiedApplicationAffinityError, got %v", err)
+	}
+	if !shippererrors.ShouldRetry(err) {
+		t.Fatalf("expected unsatisfied affinity to be retried")
+	}
+}
+
+func buildAffinityRelease(namespace, app, name, generation, clusters string) *shipper.Release {
+	return &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				shipper.AppLabel: app,
+			},
+			Annotations: map[string]string{
+				shipper.ReleaseGenerationAnnotation: generation,
+				shipper.ReleaseClustersAnnotation:   clusters,
+			},
+		},
+	}
+}
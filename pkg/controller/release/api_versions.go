@@ -0,0 +1,131 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
+	"github.com/bookingcom/shipper/pkg/client"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+)
+
+// filterClustersByAPIVersions leaves out the clusters that serve the kind of
+// some object in rel's chart in neither its API version nor a compatible
+// one, so rel doesn't get scheduled on clusters it can't be installed in.
+// Clusters whose APIs can't be discovered, such as the ones in pull mode,
+// are kept: their installation targets report any missing kind instead.
+func (c *Controller) filterClustersByAPIVersions(rel *shipper.Release, clusters []*shipper.Cluster) ([]*shipper.Cluster, error) {
+	// Simulations don't talk to clusters.
+	if c.store == nil {
+		return clusters, nil
+	}
+
+	chart, err := c.chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderChartForRel(chart, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := requiredKinds(rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	capable := make([]*shipper.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		clientsets, err := c.store.GetApplicationClusterClientset(cluster.Name, AgentName)
+		if err != nil {
+			capable = append(capable, cluster)
+			continue
+		}
+
+		missing, err := missingKinds(clientsets.GetKubeClient().Discovery(), required)
+		if err != nil {
+			klog.V(4).Infof("Could not discover the APIs of cluster %q: %s", cluster.Name, err)
+			capable = append(capable, cluster)
+			continue
+		}
+
+		if len(missing) > 0 {
+			c.recorder.Eventf(rel, corev1.EventTypeWarning, shipperevents.ClusterMissingAPIs,
+				"cluster %q does not serve %s", cluster.Name, strings.Join(missing, ", "))
+			continue
+		}
+
+		capable = append(capable, cluster)
+	}
+
+	return capable, nil
+}
+
+// requiredKinds returns the kinds of the objects in rendered, leaving out
+// the ones the chart's own CustomResourceDefinitions define.
+func requiredKinds(rendered []string) ([]schema.GroupVersionKind, error) {
+	kinds := make(map[schema.GroupVersionKind]struct{})
+	custom := make(map[schema.GroupKind]struct{})
+	for _, manifest := range rendered {
+		obj, gvk, err := shipperchart.Decode(manifest)
+		if err != nil {
+			return nil, shippererrors.NewDecodeManifestError("error decoding manifest: %s", err)
+		}
+
+		kinds[*gvk] = struct{}{}
+
+		if crd, ok := obj.(*unstructured.Unstructured); ok && shipperchart.IsCustomResourceDefinition(*gvk) {
+			group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+			custom[schema.GroupKind{Group: group, Kind: kind}] = struct{}{}
+		}
+	}
+
+	required := make([]schema.GroupVersionKind, 0, len(kinds))
+	for gvk := range kinds {
+		if _, ok := custom[gvk.GroupKind()]; !ok {
+			required = append(required, gvk)
+		}
+	}
+
+	sort.Slice(required, func(i, j int) bool {
+		return required[i].String() < required[j].String()
+	})
+
+	return required, nil
+}
+
+// missingKinds returns the kinds in required that the API server behind
+// discoveryClient serves in no compatible version. API servers always serve the
+// core group, so an empty discovery is taken as one that isn't available,
+// rather than as a cluster serving nothing.
+func missingKinds(discoveryClient discovery.DiscoveryInterface, required []schema.GroupVersionKind) ([]string, error) {
+	served, err := client.ServedGroupVersions(discoveryClient)
+	if err != nil {
+		return nil, err
+	} else if len(served) == 0 {
+		return nil, fmt.Errorf("discovery returned no API groups")
+	}
+
+	var missing []string
+	for _, gvk := range required {
+		_, ok, err := client.ServedGroupVersionKind(discoveryClient, gvk)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			missing = append(missing, fmt.Sprintf("%s in %s", gvk.Kind, gvk.GroupVersion()))
+		}
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,92 @@
+package release
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+var coreResourceList = &metav1.APIResourceList{
+	GroupVersion: "v1",
+	APIResources: []metav1.APIResource{
+		{Kind: "Service", Namespaced: true, Name: "services"},
+		{Kind: "ConfigMap", Namespaced: true, Name: "configmaps"},
+	},
+}
+
+func appsResourceList(groupVersion string) *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: groupVersion,
+		APIResources: []metav1.APIResource{
+			{Kind: "Deployment", Namespaced: true, Name: "deployments"},
+		},
+	}
+}
+
+// TestFilterClustersByAPIVersions verifies that releases are only scheduled
+// on clusters serving every kind in their chart, in its own API version or a
+// compatible one, and that clusters whose APIs are unknown are kept.
+func TestFilterClustersByAPIVersions(t *testing.T) {
+	store := shippertesting.NewFakeClusterClientStore()
+
+	discovery := map[string][]*metav1.APIResourceList{
+		"modern":  {coreResourceList, appsResourceList("apps/v1")},
+		"legacy":  {coreResourceList, appsResourceList("apps/v1beta2")},
+		"ancient": {coreResourceList, appsResourceList("extensions/v1beta1")},
+		"unknown": nil,
+	}
+
+	var clusters []*shipper.Cluster
+	for _, name := range []string{"modern", "legacy", "ancient", "unknown"} {
+		cluster := shippertesting.NewNamedFakeCluster(name)
+		cluster.InitializeDiscovery(discovery[name])
+		store.AddCluster(cluster)
+		clusters = append(clusters, buildCluster(name))
+	}
+
+	// Clusters the store has no clients for, such as the ones in pull
+	// mode, are kept too.
+	clusters = append(clusters, buildCluster("pull"))
+
+	recorder := record.NewFakeRecorder(42)
+	c := &Controller{
+		chartFetcher: shippertesting.LocalFetchChart,
+		store:        store,
+		recorder:     recorder,
+	}
+
+	rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "0", 1)
+	capable, err := c.filterClustersByAPIVersions(rel, clusters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, cluster := range capable {
+		names = append(names, cluster.Name)
+	}
+
+	expected := []string{"modern", "legacy", "unknown", "pull"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected clusters %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected clusters %v, got %v", expected, names)
+		}
+	}
+
+	select {
+	case event := <-recorder.Events:
+		expectedEvent := `Warning ClusterMissingAPIs cluster "ancient" does not serve Deployment in apps/v1`
+		if event != expectedEvent {
+			t.Fatalf("expected event %q, got %q", expectedEvent, event)
+		}
+	default:
+		t.Fatalf("expected an event for cluster %q", "ancient")
+	}
+}
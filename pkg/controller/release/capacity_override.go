@@ -0,0 +1,117 @@
+package release
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/diff"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// capacityOverrideFor returns the FleetCapacityOverride in effect for rel, if
+// any. When several apply, the one asking for the most capacity wins, so
+// an override never takes away capacity another one was created to add.
+func (c *Controller) capacityOverrideFor(rel *shipper.Release) (*shipper.FleetCapacityOverride, error) {
+	overrides, err := c.capacityOverrideLister.FleetCapacityOverrides(rel.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("FleetCapacityOverride"),
+			rel.Namespace, labels.Everything(), err)
+	}
+
+	appName := rel.Labels[shipper.AppLabel]
+
+	var effective *shipper.FleetCapacityOverride
+	for _, override := range overrides {
+		if !capacityOverrideApplies(override, appName) {
+			continue
+		}
+
+		if effective == nil || override.Spec.Percent > effective.Spec.Percent ||
+			(override.Spec.Percent == effective.Spec.Percent && override.Name < effective.Name) {
+			effective = override
+		}
+	}
+
+	return effective, nil
+}
+
+func capacityOverrideApplies(override *shipper.FleetCapacityOverride, appName string) bool {
+	if len(override.Spec.Applications) == 0 {
+		return true
+	}
+
+	for _, app := range override.Spec.Applications {
+		if app == appName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overriddenCapacity returns the capacity a release the strategy wants at
+// capacityWeight is scaled to. Releases the strategy scaled down to nothing
+// are left alone: the override is for the ones serving traffic.
+func overriddenCapacity(override *int32, capacityWeight int32) int32 {
+	if override == nil || capacityWeight == 0 {
+		return capacityWeight
+	}
+
+	return *override
+}
+
+// setCapacityOverrideCondition reports on rel whether its capacity is being
+// overridden. Releases that never were don't get the condition at all.
+func setCapacityOverrideCondition(rel *shipper.Release, override *shipper.FleetCapacityOverride) diff.Diff {
+	if override == nil {
+		if releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeCapacityOverride) == nil {
+			return nil
+		}
+
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeCapacityOverride,
+			corev1.ConditionFalse,
+			"",
+			"",
+		)
+		return releaseutil.SetReleaseCondition(&rel.Status, *condition)
+	}
+
+	condition := releaseutil.NewReleaseCondition(
+		shipper.ReleaseConditionTypeCapacityOverride,
+		corev1.ConditionTrue,
+		shipper.FleetCapacityOverrideReason,
+		fmt.Sprintf("capacity set to %d%% by %s/%s: %s",
+			override.Spec.Percent, override.Namespace, override.Name, override.Spec.Message),
+	)
+	return releaseutil.SetReleaseCondition(&rel.Status, *condition)
+}
+
+// enqueueReleasesFromCapacityOverride enqueues every release an override
+// applies to, so they're scaled as soon as it's created, and given their
+// capacity back as soon as it's removed.
+func (c *Controller) enqueueReleasesFromCapacityOverride(obj interface{}) {
+	override, ok := obj.(*shipper.FleetCapacityOverride)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.FleetCapacityOverride: %#v", obj))
+		return
+	}
+
+	releases, err := c.releaseLister.Releases(override.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		if capacityOverrideApplies(override, rel.Labels[shipper.AppLabel]) {
+			c.enqueueRelease(rel)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package release
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func buildCapacityOverride(namespace, name string, percent int32, apps ...string) *shipper.FleetCapacityOverride {
+	return &shipper.FleetCapacityOverride{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: shipper.FleetCapacityOverrideSpec{
+			Percent:      percent,
+			Applications: apps,
+			Message:      "Simple test capacity override",
+			Author: shipper.RolloutBlockAuthor{
+				Type: "user",
+				Name: "testUser",
+			},
+		},
+	}
+}
+
+func TestCapacityOverrideFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []*shipper.FleetCapacityOverride
+		expected  string
+	}{
+		{
+			"no overrides",
+			nil,
+			"",
+		},
+		{
+			"whole namespace",
+			[]*shipper.FleetCapacityOverride{
+				buildCapacityOverride(shippertesting.TestNamespace, "all", 100),
+			},
+			"all",
+		},
+		{
+			"other application",
+			[]*shipper.FleetCapacityOverride{
+				buildCapacityOverride(shippertesting.TestNamespace, "other", 100, "other-app"),
+			},
+			"",
+		},
+		{
+			"other namespace",
+			[]*shipper.FleetCapacityOverride{
+				buildCapacityOverride("other-namespace", "all", 100),
+			},
+			"",
+		},
+		{
+			"most capacity wins",
+			[]*shipper.FleetCapacityOverride{
+				buildCapacityOverride(shippertesting.TestNamespace, "all", 20),
+				buildCapacityOverride(shippertesting.TestNamespace, "app", 80, "other-app", shippertesting.TestApp),
+			},
+			"app",
+		},
+	}
+
+	for _, tt := range tests {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+		for _, override := range tt.overrides {
+			indexer.Add(override)
+		}
+
+		c := &Controller{
+			capacityOverrideLister: shipperlisters.NewFleetCapacityOverrideLister(indexer),
+		}
+
+		rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "override", 1)
+		override, err := c.capacityOverrideFor(rel)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.name, err)
+		}
+
+		var name string
+		if override != nil {
+			name = override.Name
+		}
+
+		if name != tt.expected {
+			t.Errorf("%s: expected override %q, got %q", tt.name, tt.expected, name)
+		}
+	}
+}
+
+func TestStrategyExecutorCapacityOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		override *int32
+		expected int32
+	}{
+		{"no override", nil, 1},
+		{"scale up", pint32(100), 100},
+		{"scale down", pint32(0), 0},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			"override",
+			1,
+		)
+
+		achievedStep := StepStaging
+		it, trafficTarget, capacityTarget := buildAssociatedObjectsWithStatus(rel, nil, &achievedStep)
+		capacityTarget.Spec.Percent = 50
+
+		executor, err := NewStrategyExecutor(vanguard.DeepCopy(), StepStaging)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		executor.capacityOverride = tt.override
+
+		curr := &releaseInfo{
+			release:            rel,
+			installationTarget: it,
+			trafficTarget:      trafficTarget,
+			capacityTarget:     capacityTarget,
+		}
+
+		_, patches := executor.Execute(nil, curr, nil)
+
+		var percent *int32
+		for _, patch := range patches {
+			if ctPatch, ok := patch.(*CapacityTargetSpecPatch); ok {
+				percent = &ctPatch.NewSpec.Percent
+			}
+		}
+
+		if percent == nil {
+			t.Errorf("%s: expected a capacity target patch", tt.name)
+		} else if *percent != tt.expected {
+			t.Errorf("%s: expected capacity %d, got %d", tt.name, tt.expected, *percent)
+		}
+	}
+}
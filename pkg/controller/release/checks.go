@@ -1,6 +1,8 @@
 package release
 
 import (
+	"k8s.io/apimachinery/pkg/api/equality"
+
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 )
@@ -37,14 +39,19 @@ func checkCapacity(
 func checkTraffic(
 	tt *shipper.TrafficTarget,
 	stepTrafficWeight uint32,
+	stepShadowWeight uint32,
+	bucketing *shipper.ExperimentBucketing,
 ) (
 	bool,
 	*shipper.TrafficTargetSpec,
 	string,
 ) {
-	if tt.Spec.Weight != stepTrafficWeight {
+	if tt.Spec.Weight != stepTrafficWeight || tt.Spec.ShadowWeight != stepShadowWeight ||
+		!equality.Semantic.DeepEqual(tt.Spec.Bucketing, bucketing) {
 		newSpec := &shipper.TrafficTargetSpec{
-			Weight: stepTrafficWeight,
+			Weight:       stepTrafficWeight,
+			ShadowWeight: stepShadowWeight,
+			Bucketing:    bucketing,
 		}
 
 		return false, newSpec, "patches pending"
@@ -1,11 +1,14 @@
 package release
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
 // computeTargetClusters picks out the clusters from the given list which match
@@ -31,6 +34,15 @@ func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster)
 	}
 
 	prefList := buildPrefList(app, clusterList)
+	if rel.Spec.Environment.ClusterRequirements.Scheduler == shipper.ClusterSchedulerModeCostAware {
+		sortByCost(prefList)
+	}
+
+	spread := rel.Spec.Environment.ClusterRequirements.Spread
+	if spread != nil && spread.Strategy == shipper.ClusterSpreadPinned {
+		prefList = pinnedClusters(spread, prefList)
+	}
+
 	// This algo could probably build up hashes instead of doing linear searches,
 	// but these data sets are so tiny (1-20 items) that it'd only be useful for
 	// readability.
@@ -48,6 +60,14 @@ func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster)
 				continue
 			}
 
+			if !releaseutil.ClusterAllowsNamespace(cluster, rel.Namespace) {
+				continue
+			}
+
+			if !clusterRunsPlatforms(cluster, rel.Spec.Environment.ClusterRequirements.Platforms) {
+				continue
+			}
+
 			if cluster.Spec.Region == region.Name {
 				matchedRegion++
 				capabilityMatch := 0
@@ -93,6 +113,24 @@ func computeTargetClusters(rel *shipper.Release, clusterList []*shipper.Cluster)
 		}
 	}
 
+	if spread != nil && spread.Strategy == shipper.ClusterSpreadPinned {
+		for _, p := range spread.Pinned {
+			found := false
+			for _, cluster := range resClusters {
+				if cluster.Name == p.Name {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, shippererrors.NewInvalidClusterSpreadError(
+					"pinned cluster %q does not exist, is not schedulable, or does not satisfy clusterRequirements",
+					p.Name)
+			}
+		}
+	}
+
 	sort.Slice(resClusters, func(i, j int) bool {
 		return resClusters[i].Name < resClusters[j].Name
 	})
@@ -113,5 +151,102 @@ func validateClusterRequirements(requirements shipper.ClusterRequirements) error
 		seenCapabilities[capability] = struct{}{}
 	}
 
-	return nil
+	seenPlatforms := map[string]struct{}{}
+	for _, platform := range requirements.Platforms {
+		if _, ok := seenPlatforms[platform]; ok {
+			return shippererrors.NewDuplicatePlatformRequirementError(platform)
+		}
+		seenPlatforms[platform] = struct{}{}
+	}
+
+	return validateClusterSpread(requirements.Spread)
+}
+
+// clusterPlatforms returns the platforms the nodes of a cluster run, from its
+// "platform/<os>/<arch>" capabilities.
+func clusterPlatforms(cluster *shipper.Cluster) []string {
+	var platforms []string
+	for _, capability := range cluster.Spec.Capabilities {
+		if strings.HasPrefix(capability, shipper.ClusterPlatformCapability) {
+			platforms = append(platforms, strings.TrimPrefix(capability, shipper.ClusterPlatformCapability))
+		}
+	}
+
+	if len(platforms) == 0 {
+		return []string{shipper.DefaultPlatform}
+	}
+
+	return platforms
+}
+
+// clusterRunsPlatforms returns whether pods able to run on any of platforms
+// can be scheduled on every node of a cluster. No platforms means no
+// requirement at all.
+func clusterRunsPlatforms(cluster *shipper.Cluster, platforms []string) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+
+	for _, clusterPlatform := range clusterPlatforms(cluster) {
+		found := false
+		for _, platform := range platforms {
+			if platform == clusterPlatform {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortByCost reorders a preference list so cheaper clusters come first. The
+// sort is stable, so clusters with the same cost keep their preference list
+// order, and every region and capability requirement still masks the list
+// exactly as it does for the default scheduler.
+func sortByCost(prefList []*shipper.Cluster) {
+	sort.SliceStable(prefList, func(i, j int) bool {
+		a, b := prefList[i].Spec.Scheduler, prefList[j].Spec.Scheduler
+
+		if a.CostTier == nil || b.CostTier == nil {
+			if a.CostTier != nil || b.CostTier != nil {
+				return a.CostTier != nil
+			}
+		} else if *a.CostTier != *b.CostTier {
+			return *a.CostTier < *b.CostTier
+		}
+
+		if a.PricePerReplicaHour == nil || b.PricePerReplicaHour == nil {
+			return a.PricePerReplicaHour != nil && b.PricePerReplicaHour == nil
+		}
+
+		return a.PricePerReplicaHour.Cmp(*b.PricePerReplicaHour) < 0
+	})
+}
+
+// describeCostAwareChoice explains which cost hints led the CostAware
+// scheduler to pick clusters, so it can be recorded in the release status.
+func describeCostAwareChoice(clusters []*shipper.Cluster) string {
+	descriptions := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		tier, price := "none", "unknown"
+		if cluster.Spec.Scheduler.CostTier != nil {
+			tier = fmt.Sprintf("%d", *cluster.Spec.Scheduler.CostTier)
+		}
+		if cluster.Spec.Scheduler.PricePerReplicaHour != nil {
+			price = cluster.Spec.Scheduler.PricePerReplicaHour.String()
+		}
+
+		descriptions = append(descriptions, fmt.Sprintf(
+			"%s (region %s, cost tier %s, price per replica hour %s)",
+			cluster.Name, cluster.Spec.Region, tier, price))
+	}
+
+	return fmt.Sprintf(
+		"picked the cheapest clusters satisfying region and capability requirements: %s",
+		strings.Join(descriptions, ", "))
 }
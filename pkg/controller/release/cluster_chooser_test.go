@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -276,6 +277,137 @@ func TestComputeTargetClusters(t *testing.T) {
 	)
 }
 
+// TestComputeTargetClustersNamespaces checks that clusters restricting the
+// namespaces they accept releases from are only picked for those namespaces.
+func TestComputeTargetClustersNamespaces(t *testing.T) {
+	reqs := shipper.ClusterRequirements{
+		Regions: []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(2)}},
+	}
+
+	spec := shipper.ClusterSpec{Region: shippertesting.TestRegion}
+	open := generateClusterForTestCase(0, spec)
+	restricted := generateClusterForTestCase(1, spec)
+	restricted.Annotations = map[string]string{
+		shipper.ClusterNamespacesAnnotation: "team-a-*, payments",
+	}
+	clusters := []*shipper.Cluster{open, restricted}
+
+	for _, ns := range []string{"team-a-frontend", "payments"} {
+		release := generateReleaseForTestCase(reqs)
+		release.Namespace = ns
+		if _, err := computeTargetClusters(release, clusters); err != nil {
+			t.Errorf("expected namespace %q to be scheduled on both clusters, got error: %s", ns, err)
+		}
+	}
+
+	release := generateReleaseForTestCase(reqs)
+	release.Namespace = "team-b-frontend"
+	_, err := computeTargetClusters(release, clusters)
+	if err == nil {
+		t.Fatalf("expected namespace %q not to be scheduled on restricted cluster", release.Namespace)
+	}
+}
+
+// TestComputeTargetClustersCostAware checks that the CostAware scheduler picks
+// the cheapest clusters, by tier first and price second, and only among those
+// that satisfy capability requirements.
+func TestComputeTargetClustersCostAware(t *testing.T) {
+	price := func(p string) *resource.Quantity {
+		q := resource.MustParse(p)
+		return &q
+	}
+
+	reqs := requirements{
+		Regions:      []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(2)}},
+		Capabilities: []string{"gpu"},
+		Scheduler:    shipper.ClusterSchedulerModeCostAware,
+	}
+
+	computeClusterTestCase(t, "cost aware picks cheapest capable clusters",
+		reqs,
+		clusters{
+			{
+				Region:       shippertesting.TestRegion,
+				Capabilities: []string{"gpu"},
+				Scheduler:    shipper.ClusterSchedulerSettings{CostTier: pint32(2)},
+			},
+			{
+				Region:       shippertesting.TestRegion,
+				Capabilities: []string{"gpu"},
+				Scheduler:    shipper.ClusterSchedulerSettings{CostTier: pint32(1), PricePerReplicaHour: price("0.05")},
+			},
+			{
+				Region:       shippertesting.TestRegion,
+				Capabilities: []string{},
+				Scheduler:    shipper.ClusterSchedulerSettings{CostTier: pint32(0)},
+			},
+			{
+				Region:       shippertesting.TestRegion,
+				Capabilities: []string{"gpu"},
+				Scheduler:    shipper.ClusterSchedulerSettings{CostTier: pint32(1), PricePerReplicaHour: price("0.02")},
+			},
+			{
+				Region:       shippertesting.TestRegion,
+				Capabilities: []string{"gpu"},
+			},
+		},
+		expected{"cluster-1", "cluster-3"},
+		passingCase,
+	)
+}
+
+// TestComputeTargetClustersPlatforms checks that releases asking for
+// platforms are only scheduled on clusters whose nodes all run one of them,
+// and that clusters without platform capabilities run linux/amd64.
+func TestComputeTargetClustersPlatforms(t *testing.T) {
+	clusterSpecs := clusters{
+		{Region: shippertesting.TestRegion, Capabilities: []string{}},
+		{Region: shippertesting.TestRegion, Capabilities: []string{"platform/linux/arm64"}},
+		{Region: shippertesting.TestRegion, Capabilities: []string{"platform/linux/amd64", "platform/linux/arm64"}},
+		{Region: shippertesting.TestRegion, Capabilities: []string{"platform/windows/amd64"}},
+	}
+
+	computeClusterTestCase(t, "arm64 only",
+		requirements{
+			Regions:   []shipper.RegionRequirement{{Name: shippertesting.TestRegion}},
+			Platforms: []string{"linux/arm64"},
+		},
+		clusterSpecs,
+		expected{"cluster-1"},
+		passingCase,
+	)
+
+	computeClusterTestCase(t, "mixed architecture cluster",
+		requirements{
+			Regions:   []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(3)}},
+			Platforms: []string{"linux/amd64", "linux/arm64"},
+		},
+		clusterSpecs,
+		expected{"cluster-0", "cluster-1", "cluster-2"},
+		passingCase,
+	)
+
+	computeClusterTestCase(t, "not enough windows clusters",
+		requirements{
+			Regions:   []shipper.RegionRequirement{{Name: shippertesting.TestRegion, Replicas: pint32(2)}},
+			Platforms: []string{"windows/amd64"},
+		},
+		clusterSpecs,
+		expected{},
+		errorCase,
+	)
+
+	computeClusterTestCase(t, "duplicate platforms",
+		requirements{
+			Regions:   []shipper.RegionRequirement{{Name: shippertesting.TestRegion}},
+			Platforms: []string{"linux/amd64", "linux/amd64"},
+		},
+		clusterSpecs,
+		expected{},
+		errorCase,
+	)
+}
+
 func generateClusterForTestCase(name int, spec shipper.ClusterSpec) *shipper.Cluster {
 	return &shipper.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,215 @@
+package release
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+)
+
+// CompletionNotifier is told about releases that completed their rollout,
+// for systems outside of Shipper, such as CMDBs and deploy trackers, to
+// rely on. Notifiers are called in the background, and must give up once
+// ctx is done.
+type CompletionNotifier interface {
+	NotifyCompletion(ctx gocontext.Context, completion Completion) error
+}
+
+// Completion is the payload sent to completion webhooks when a release
+// completes its rollout.
+type Completion struct {
+	Namespace   string    `json:"namespace"`
+	Application string    `json:"application"`
+	Release     string    `json:"release"`
+	Version     string    `json:"version"`
+	CompletedAt time.Time `json:"completedAt"`
+	// Clusters are the clusters the release's Deployments were
+	// annotated in, which leaves out the ones that were unavailable.
+	Clusters []string `json:"clusters"`
+}
+
+// HTTPCompletionNotifier posts completions to a webhook.
+type HTTPCompletionNotifier struct {
+	url    func() string
+	client *http.Client
+}
+
+var _ CompletionNotifier = (*HTTPCompletionNotifier)(nil)
+
+// NewHTTPCompletionNotifier returns a notifier that posts completions to
+// whatever url returns at the time, or drops them if it returns an empty
+// string, so the webhook can change while controllers run.
+func NewHTTPCompletionNotifier(url func() string) *HTTPCompletionNotifier {
+	return &HTTPCompletionNotifier{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+// NotifyCompletion posts completion to the webhook as JSON.
+func (n *HTTPCompletionNotifier) NotifyCompletion(ctx gocontext.Context, completion Completion) error {
+	url := n.url()
+	if url == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, url)
+	}
+
+	return nil
+}
+
+// finishRelease is the finishing pass of a release that completed its
+// rollout. It annotates the release's Deployments in clusters, its
+// Application and the release itself with what completed and when, and
+// tells the completion notifier, if there is one, in the background.
+// Releases annotated already are left alone, so this happens once per
+// release, as long as the release is updated afterwards.
+func (c *Controller) finishRelease(rel *shipper.Release, clusterKubeClients map[string]kubernetes.Interface) error {
+	if _, ok := rel.Annotations[shipper.CompletedAtAnnotation]; ok {
+		return nil
+	}
+
+	relKey := objectutil.MetaKey(rel)
+	completion := Completion{
+		Namespace:   rel.Namespace,
+		Application: rel.Labels[shipper.AppLabel],
+		Release:     rel.Name,
+		Version:     rel.Spec.Environment.Chart.Version,
+		CompletedAt: time.Now().UTC().Truncate(time.Second),
+		Clusters:    []string{},
+	}
+
+	annotations := map[string]string{
+		shipper.CompletedReleaseAnnotation: completion.Release,
+		shipper.CompletedVersionAnnotation: completion.Version,
+		shipper.CompletedAtAnnotation:      completion.CompletedAt.Format(time.RFC3339),
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return shippererrors.NewUnrecoverableError(err)
+	}
+
+	for clusterName := range clusterKubeClients {
+		// The kube clients of clusters in pull mode are for the
+		// management cluster, so their Deployments are out of reach.
+		cluster, err := c.clusterLister.Get(clusterName)
+		if err != nil || cluster.Spec.PullMode {
+			continue
+		}
+
+		completion.Clusters = append(completion.Clusters, clusterName)
+	}
+	sort.Strings(completion.Clusters)
+
+	for _, clusterName := range completion.Clusters {
+		err := annotateDeployments(clusterKubeClients[clusterName], rel, patch)
+		if err != nil {
+			return shippererrors.NewReleaseCompletionError(relKey, err)
+		}
+	}
+
+	_, err = c.clientset.ShipperV1alpha1().Applications(rel.Namespace).
+		Patch(completion.Application, types.MergePatchType, patch)
+	if err != nil && !errors.IsNotFound(err) {
+		return shippererrors.NewReleaseCompletionError(relKey,
+			shippererrors.NewKubeclientPatchError(rel.Namespace, completion.Application, err).
+				WithShipperKind("Application"))
+	}
+
+	if rel.Annotations == nil {
+		rel.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		rel.Annotations[k] = v
+	}
+
+	c.recorder.Eventf(
+		rel,
+		corev1.EventTypeNormal,
+		shipperevents.ReleaseCompleted,
+		"release completed in clusters %v",
+		completion.Clusters,
+	)
+
+	if c.completionNotifier != nil {
+		go c.notifyCompletion(rel.DeepCopy(), completion)
+	}
+
+	return nil
+}
+
+// notifyCompletion tells the completion notifier about completion, giving
+// it up to c.completionTimeout. It's not retried if it fails, as rel is
+// annotated with its completion already by then.
+func (c *Controller) notifyCompletion(rel *shipper.Release, completion Completion) {
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), c.completionTimeout)
+	defer cancel()
+
+	err := c.completionNotifier.NotifyCompletion(ctx, completion)
+	if err != nil {
+		err = shippererrors.NewReleaseCompletionError(objectutil.MetaKey(rel), err)
+		klog.Warning(err)
+		c.recorder.Event(rel, corev1.EventTypeWarning, shipperevents.ReleaseCompletionFailed, err.Error())
+	}
+}
+
+func annotateDeployments(kubeClient kubernetes.Interface, rel *shipper.Release, patch []byte) error {
+	gvk := appsv1.SchemeGroupVersion.WithKind("Deployment")
+	selector := labels.Set{shipper.ReleaseLabel: rel.Name}.AsSelector()
+	deployments, err := kubeClient.AppsV1().Deployments(rel.Namespace).
+		List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return shippererrors.NewKubeclientListError(gvk, rel.Namespace, selector, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		_, err := kubeClient.AppsV1().Deployments(deployment.Namespace).
+			Patch(deployment.Name, types.MergePatchType, patch)
+		if err != nil && !errors.IsNotFound(err) {
+			return shippererrors.NewKubeclientPatchError(deployment.Namespace, deployment.Name, err).
+				WithKind(gvk)
+		}
+	}
+
+	return nil
+}
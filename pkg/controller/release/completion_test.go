@@ -0,0 +1,254 @@
+package release
+
+import (
+	gocontext "context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// fakeCompletionNotifier sends the completions it's told about to
+// completions, and answers them with err once unblock is closed.
+type fakeCompletionNotifier struct {
+	completions chan Completion
+	unblock     chan struct{}
+	err         error
+}
+
+func newFakeCompletionNotifier() *fakeCompletionNotifier {
+	unblock := make(chan struct{})
+	close(unblock)
+
+	return &fakeCompletionNotifier{
+		completions: make(chan Completion, 10),
+		unblock:     unblock,
+	}
+}
+
+func (n *fakeCompletionNotifier) NotifyCompletion(ctx gocontext.Context, completion Completion) error {
+	n.completions <- completion
+
+	select {
+	case <-n.unblock:
+		return n.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *fakeCompletionNotifier) expectCompletion(t *testing.T) Completion {
+	t.Helper()
+
+	select {
+	case completion := <-n.completions:
+		return completion
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected completion to be posted, but it wasn't")
+		return Completion{}
+	}
+}
+
+// TestCompletionIsReported verifies that releases that complete their
+// rollout get their Deployments, Application and themselves annotated
+// with it, and are posted to the completion notifier.
+func TestCompletionIsReported(t *testing.T) {
+	f, rel, app, deployment := buildCompletedReleaseFixture()
+
+	notifier := newFakeCompletionNotifier()
+	runControllerWithNotifier(f, nil, notifier)
+
+	completion := notifier.expectCompletion(t)
+	if completion.Release != rel.Name || completion.Version != "0.0.1" ||
+		len(completion.Clusters) != 1 || completion.Clusters[0] != "cluster-a" {
+		t.Errorf("unexpected completion %+v", completion)
+	}
+	if len(notifier.completions) != 0 {
+		t.Errorf("expected completion to be posted once, got %d more times", len(notifier.completions))
+	}
+
+	expectAnnotated := func(kind string, annotations map[string]string) {
+		t.Helper()
+
+		if annotations[shipper.CompletedReleaseAnnotation] != rel.Name ||
+			annotations[shipper.CompletedVersionAnnotation] != "0.0.1" ||
+			annotations[shipper.CompletedAtAnnotation] == "" {
+			t.Errorf("expected %s to be annotated with the release's completion, got %v", kind, annotations)
+		}
+	}
+
+	object, err := f.ShipperClient.Tracker().Get(
+		shipper.SchemeGroupVersion.WithResource("releases"), rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectAnnotated("Release", object.(*shipper.Release).Annotations)
+
+	object, err = f.ShipperClient.Tracker().Get(
+		shipper.SchemeGroupVersion.WithResource("applications"), app.Namespace, app.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectAnnotated("Application", object.(*shipper.Application).Annotations)
+
+	object, err = f.Clusters["cluster-a"].KubeClient.Tracker().Get(
+		appsv1.SchemeGroupVersion.WithResource("deployments"), deployment.Namespace, deployment.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectAnnotated("Deployment", object.(*appsv1.Deployment).Annotations)
+}
+
+// TestCompletionDoesNotWaitForNotifier verifies that releases are
+// annotated with their completion while the completion notifier is still
+// busy, and that it failing is reported with an event, without finishing
+// the release all over again.
+func TestCompletionDoesNotWaitForNotifier(t *testing.T) {
+	f, rel, _, _ := buildCompletedReleaseFixture()
+
+	notifier := newFakeCompletionNotifier()
+	notifier.unblock = make(chan struct{})
+	notifier.err = fmt.Errorf("webhook is down")
+	runControllerWithNotifier(f, nil, notifier)
+
+	notifier.expectCompletion(t)
+
+	object, err := f.ShipperClient.Tracker().Get(
+		shipper.SchemeGroupVersion.WithResource("releases"), rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := object.(*shipper.Release).Annotations[shipper.CompletedAtAnnotation]; !ok {
+		t.Fatalf("expected release to be annotated before the notifier answered, got %v",
+			object.(*shipper.Release).Annotations)
+	}
+
+	close(notifier.unblock)
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-f.Recorder.Events:
+			if strings.HasPrefix(event, "Warning "+shipperevents.ReleaseCompletionFailed) &&
+				strings.Contains(event, "webhook is down") {
+				if len(notifier.completions) != 0 {
+					t.Errorf("expected completion to be posted once, got %d more times", len(notifier.completions))
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatalf("expected a %s event", shipperevents.ReleaseCompletionFailed)
+		}
+	}
+}
+
+// TestHTTPCompletionNotifier verifies that completions are posted to the
+// URL the notifier is given at the time, not at all without one, and that
+// slow webhooks are given up on.
+func TestHTTPCompletionNotifier(t *testing.T) {
+	var mut sync.Mutex
+	var bodies []string
+	slow := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-slow
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		mut.Lock()
+		bodies = append(bodies, r.URL.Path+" "+string(body))
+		mut.Unlock()
+	}))
+	defer server.Close()
+	// The slow handler has to return before the server can close.
+	defer close(slow)
+
+	url := ""
+	notifier := NewHTTPCompletionNotifier(func() string { return url })
+	completion := Completion{Release: "test-release", Clusters: []string{}}
+
+	if err := notifier.NotifyCompletion(gocontext.Background(), completion); err != nil {
+		t.Fatalf("expected no error without a URL, got %s", err)
+	}
+
+	url = server.URL + "/webhook"
+	if err := notifier.NotifyCompletion(gocontext.Background(), completion); err != nil {
+		t.Fatal(err)
+	}
+
+	url = server.URL + "/slow"
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := notifier.NotifyCompletion(ctx, completion); err == nil {
+		t.Fatal("expected an error from a webhook that doesn't answer in time")
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+	if len(bodies) != 1 || !strings.HasPrefix(bodies[0], "/webhook ") ||
+		!strings.Contains(bodies[0], `"release":"test-release"`) {
+		t.Errorf("expected completion to be posted to /webhook only, got %q", bodies)
+	}
+}
+
+// buildCompletedReleaseFixture returns a fixture with a release that
+// completed its rollout in cluster-a, its Application and its Deployment.
+func buildCompletedReleaseFixture() (
+	*shippertesting.ControllerTestFixture,
+	*shipper.Release,
+	*shipper.Application,
+	*appsv1.Deployment,
+) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"completed",
+		1,
+	)
+	achievedStep := StepFullOn
+	rel.Spec.TargetStep = achievedStep
+
+	app := &shipper.Application{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: shippertesting.TestNamespace,
+			Name:      shippertesting.TestApp,
+		},
+	}
+
+	cluster := buildCluster("cluster-a")
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, app, cluster},
+		map[string][]runtime.Object{
+			cluster.Name: []runtime.Object{it, ct, tt},
+		},
+	)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: shippertesting.TestNamespace,
+			Name:      rel.Name,
+			Labels: map[string]string{
+				shipper.ReleaseLabel: rel.Name,
+			},
+		},
+	}
+	f.Clusters[cluster.Name].KubeClient.Tracker().Add(deployment)
+
+	return f, rel, app, deployment
+}
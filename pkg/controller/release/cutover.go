@@ -0,0 +1,287 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+)
+
+// cutOver points the Ingresses and hostnames of cutover at the Service of
+// rel in every cluster it's available in, and tears down the Ingresses of
+// the releases before it. Every part of it is idempotent, so it's simply
+// done again when any of it fails. Clusters in pull mode are left out, as
+// their objects are out of reach.
+func (c *Controller) cutOver(
+	rel *shipper.Release,
+	cutover *shipper.RolloutCutover,
+	clusterKubeClients map[string]kubernetes.Interface,
+	clusterShipperClients map[string]shipperclientset.Interface,
+) error {
+	var clusters []string
+	for clusterName := range clusterKubeClients {
+		cluster, err := c.clusterLister.Get(clusterName)
+		if err != nil || cluster.Spec.PullMode {
+			continue
+		}
+
+		clusters = append(clusters, clusterName)
+	}
+	sort.Strings(clusters)
+
+	for _, clusterName := range clusters {
+		err := cutOverInCluster(
+			clusterKubeClients[clusterName],
+			clusterShipperClients[clusterName],
+			rel, cutover)
+		if err != nil {
+			return shippererrors.NewCutoverError(objectutil.MetaKey(rel), clusterName, err)
+		}
+	}
+
+	c.recorder.Eventf(
+		rel,
+		corev1.EventTypeNormal,
+		shipperevents.ReleaseCutOver,
+		"ingresses %v and hostnames %v cut over in clusters %v",
+		cutover.Ingresses, cutover.Hostnames, clusters,
+	)
+
+	return nil
+}
+
+func cutOverInCluster(
+	kubeClient kubernetes.Interface,
+	shipperClient shipperclientset.Interface,
+	rel *shipper.Release,
+	cutover *shipper.RolloutCutover,
+) error {
+	appName := rel.Labels[shipper.AppLabel]
+	appSelector := labels.Set{shipper.AppLabel: appName}.AsSelector()
+
+	services, err := kubeClient.CoreV1().Services(rel.Namespace).
+		List(metav1.ListOptions{LabelSelector: appSelector.String()})
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			corev1.SchemeGroupVersion.WithKind("Service"), rel.Namespace, appSelector, err)
+	}
+
+	svc, err := releaseService(rel, services.Items)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range cutover.Ingresses {
+		ingress, err := kubeClient.NetworkingV1beta1().Ingresses(rel.Namespace).
+			Get(name, metav1.GetOptions{})
+		if err != nil {
+			return shippererrors.NewKubeclientGetError(rel.Namespace, name, err).
+				WithKind(networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"))
+		}
+
+		if !pointIngressAt(ingress, svc.Name) {
+			continue
+		}
+
+		_, err = kubeClient.NetworkingV1beta1().Ingresses(rel.Namespace).Update(ingress)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(ingress, err).
+				WithKind(networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"))
+		}
+	}
+
+	// The release's Service gets the hostnames before the others lose
+	// them, so that DNS records always point somewhere.
+	if len(cutover.Hostnames) > 0 {
+		others := make([]*corev1.Service, 0, len(services.Items))
+		for i := range services.Items {
+			if services.Items[i].Name != svc.Name {
+				others = append(others, &services.Items[i])
+			}
+		}
+
+		for _, s := range append([]*corev1.Service{svc}, others...) {
+			if !moveHostnames(s, cutover.Hostnames, s == svc) {
+				continue
+			}
+
+			_, err := kubeClient.CoreV1().Services(s.Namespace).Update(s)
+			if err != nil {
+				return shippererrors.NewKubeclientUpdateError(s, err).
+					WithCoreV1Kind("Service")
+			}
+		}
+	}
+
+	return tearDownIngresses(kubeClient, shipperClient, rel, cutover, appSelector)
+}
+
+// releaseService returns the Service of rel among services, which is the
+// only one labeled with it that has its name in its own.
+func releaseService(rel *shipper.Release, services []corev1.Service) (*corev1.Service, error) {
+	var found []*corev1.Service
+	for i, svc := range services {
+		if svc.Labels[shipper.ReleaseLabel] == rel.Name && strings.Contains(svc.Name, rel.Name) {
+			found = append(found, &services[i])
+		}
+	}
+
+	if len(found) != 1 {
+		return nil, fmt.Errorf(
+			"expected exactly one Service named after release %q, found %d", rel.Name, len(found))
+	}
+
+	return found[0], nil
+}
+
+// pointIngressAt sends everything ingress routes to the Service named
+// serviceName, on the same ports, and returns whether it changed anything.
+func pointIngressAt(ingress *networkingv1beta1.Ingress, serviceName string) bool {
+	changed := false
+	point := func(backend *networkingv1beta1.IngressBackend) {
+		if backend.ServiceName != serviceName {
+			backend.ServiceName = serviceName
+			changed = true
+		}
+	}
+
+	if ingress.Spec.Backend != nil {
+		point(ingress.Spec.Backend)
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for i := range rule.HTTP.Paths {
+			point(&rule.HTTP.Paths[i].Backend)
+		}
+	}
+
+	return changed
+}
+
+// moveHostnames adds hostnames to the external-dns hostnames of svc, or
+// takes them out if add is false, and returns whether it changed anything.
+func moveHostnames(svc *corev1.Service, hostnames []string, add bool) bool {
+	var current []string
+	if v := svc.Annotations[shipper.ExternalDNSHostnameAnnotation]; v != "" {
+		current = strings.Split(v, ",")
+	}
+
+	moving := make(map[string]bool, len(hostnames))
+	for _, hostname := range hostnames {
+		moving[hostname] = true
+	}
+
+	var updated []string
+	for _, hostname := range current {
+		if !moving[hostname] {
+			updated = append(updated, hostname)
+		}
+	}
+	if add {
+		updated = append(updated, hostnames...)
+	}
+
+	if strings.Join(updated, ",") == strings.Join(current, ",") {
+		return false
+	}
+
+	if len(updated) == 0 {
+		delete(svc.Annotations, shipper.ExternalDNSHostnameAnnotation)
+		return true
+	}
+
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	svc.Annotations[shipper.ExternalDNSHostnameAnnotation] = strings.Join(updated, ",")
+
+	return true
+}
+
+// tearDownIngresses deletes the Ingresses the charts of the releases before
+// rel installed. Their installation targets are annotated first, so that
+// the installation controller doesn't put them back.
+func tearDownIngresses(
+	kubeClient kubernetes.Interface,
+	shipperClient shipperclientset.Interface,
+	rel *shipper.Release,
+	cutover *shipper.RolloutCutover,
+	appSelector labels.Selector,
+) error {
+	its, err := shipperClient.ShipperV1alpha1().InstallationTargets(rel.Namespace).
+		List(metav1.ListOptions{LabelSelector: appSelector.String()})
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("InstallationTarget"), rel.Namespace, appSelector, err)
+	}
+
+	for _, it := range its.Items {
+		cutOverTo, ok := it.Annotations[shipper.CutOverToAnnotation]
+		if it.Name == rel.Name {
+			// A release cut over from before is the one being cut
+			// over to now, so it gets its Ingresses back.
+			if !ok {
+				continue
+			}
+			delete(it.Annotations, shipper.CutOverToAnnotation)
+		} else {
+			if cutOverTo == rel.Name {
+				continue
+			}
+			if it.Annotations == nil {
+				it.Annotations = map[string]string{}
+			}
+			it.Annotations[shipper.CutOverToAnnotation] = rel.Name
+		}
+
+		_, err := shipperClient.ShipperV1alpha1().InstallationTargets(it.Namespace).Update(&it)
+		if err != nil {
+			return shippererrors.NewKubeclientUpdateError(&it, err).
+				WithShipperKind("InstallationTarget")
+		}
+	}
+
+	ingresses, err := kubeClient.NetworkingV1beta1().Ingresses(rel.Namespace).
+		List(metav1.ListOptions{LabelSelector: appSelector.String()})
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"), rel.Namespace, appSelector, err)
+	}
+
+	keep := make(map[string]bool, len(cutover.Ingresses))
+	for _, name := range cutover.Ingresses {
+		keep[name] = true
+	}
+
+	for _, ingress := range ingresses.Items {
+		owner := ingress.Labels[shipper.ReleaseLabel]
+		if owner == "" || owner == rel.Name || keep[ingress.Name] {
+			continue
+		}
+
+		err := kubeClient.NetworkingV1beta1().Ingresses(ingress.Namespace).
+			Delete(ingress.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return shippererrors.NewKubeclientDeleteError(ingress.Namespace, ingress.Name, err).
+				WithKind(networkingv1beta1.SchemeGroupVersion.WithKind("Ingress"))
+		}
+	}
+
+	return nil
+}
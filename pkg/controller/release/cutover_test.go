@@ -0,0 +1,162 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// TestCutover verifies that a release with a cutover that gets all the
+// traffic has shared Ingresses and hostnames pointed at its own Service,
+// and the Ingresses of the releases before it torn down, before it
+// completes.
+func TestCutover(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"contender",
+		1,
+	)
+	achievedStep := StepFullOn
+	rel.Spec.TargetStep = achievedStep
+
+	strategy := *rel.Spec.Environment.Strategy
+	strategy.Cutover = &shipper.RolloutCutover{
+		Ingresses: []string{"reviews"},
+		Hostnames: []string{"reviews.example.com"},
+	}
+	rel.Spec.Environment.Strategy = &strategy
+
+	incumbentName := shippertesting.TestApp + "-incumbent"
+	appLabels := func(relName string) map[string]string {
+		return map[string]string{
+			shipper.AppLabel:     shippertesting.TestApp,
+			shipper.ReleaseLabel: relName,
+		}
+	}
+
+	cluster := buildCluster("cluster-a")
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+	incumbentIt := it.DeepCopy()
+	incumbentIt.Name = incumbentName
+	incumbentIt.Labels = appLabels(incumbentName)
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, cluster},
+		map[string][]runtime.Object{
+			cluster.Name: []runtime.Object{it, incumbentIt, ct, tt},
+		},
+	)
+
+	kubeClient := f.Clusters[cluster.Name].KubeClient
+	for _, obj := range []runtime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: shippertesting.TestNamespace,
+				Name:      rel.Name,
+				Labels:    appLabels(rel.Name),
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: shippertesting.TestNamespace,
+				Name:      incumbentName,
+				Labels:    appLabels(incumbentName),
+				Annotations: map[string]string{
+					shipper.ExternalDNSHostnameAnnotation: "reviews.example.com,incumbent.example.com",
+				},
+			},
+		},
+		&networkingv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: shippertesting.TestNamespace,
+				Name:      "reviews",
+			},
+			Spec: networkingv1beta1.IngressSpec{
+				Rules: []networkingv1beta1.IngressRule{
+					{
+						Host: "reviews.example.com",
+						IngressRuleValue: networkingv1beta1.IngressRuleValue{
+							HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+								Paths: []networkingv1beta1.HTTPIngressPath{
+									{Backend: networkingv1beta1.IngressBackend{ServiceName: incumbentName}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		&networkingv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: shippertesting.TestNamespace,
+				Name:      incumbentName,
+				Labels:    appLabels(incumbentName),
+			},
+		},
+	} {
+		kubeClient.Tracker().Add(obj)
+	}
+
+	runController(f, nil)
+
+	object, err := f.ShipperClient.Tracker().Get(
+		shipper.SchemeGroupVersion.WithResource("releases"), rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !releaseutil.ReleaseComplete(object.(*shipper.Release)) {
+		t.Errorf("expected release to complete once it was cut over to")
+	}
+
+	object, err = kubeClient.Tracker().Get(
+		networkingv1beta1.SchemeGroupVersion.WithResource("ingresses"), rel.Namespace, "reviews")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := object.(*networkingv1beta1.Ingress).Spec.Rules[0].HTTP.Paths[0].Backend
+	if backend.ServiceName != rel.Name {
+		t.Errorf("expected Ingress to be pointed at Service %q, got %q", rel.Name, backend.ServiceName)
+	}
+
+	expectHostnames := func(svcName, expected string) {
+		t.Helper()
+
+		object, err := kubeClient.Tracker().Get(
+			corev1.SchemeGroupVersion.WithResource("services"), rel.Namespace, svcName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hostnames := object.(*corev1.Service).Annotations[shipper.ExternalDNSHostnameAnnotation]
+		if hostnames != expected {
+			t.Errorf("expected Service %q to have hostnames %q, got %q", svcName, expected, hostnames)
+		}
+	}
+	expectHostnames(rel.Name, "reviews.example.com")
+	expectHostnames(incumbentName, "incumbent.example.com")
+
+	_, err = kubeClient.Tracker().Get(
+		networkingv1beta1.SchemeGroupVersion.WithResource("ingresses"), rel.Namespace, incumbentName)
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the incumbent's Ingress to be torn down, got %v", err)
+	}
+
+	object, err = f.Clusters[cluster.Name].ShipperClient.Tracker().Get(
+		shipper.SchemeGroupVersion.WithResource("installationtargets"), rel.Namespace, incumbentName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cutOverTo := object.(*shipper.InstallationTarget).Annotations[shipper.CutOverToAnnotation]
+	if cutOverTo != rel.Name {
+		t.Errorf("expected the incumbent's InstallationTarget to be cut over to %q, got %q", rel.Name, cutOverTo)
+	}
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// clusterStepProgress returns how far along, from 0 to 1, a release is in
+// reaching a strategy step in a single cluster. Installation, capacity and
+// traffic weigh the same, and releases without traffic have all there is.
+func clusterStepProgress(relinfo *releaseInfo, step shipper.RolloutStrategyStep) float64 {
+	var installation float64
+	if ready, _ := checkInstallation(relinfo.installationTarget); ready {
+		installation = 1
+	}
+
+	capacity := progressTowards(
+		float64(relinfo.capacityTarget.Status.AchievedPercent),
+		float64(step.Capacity.Contender))
+	traffic := 1.0
+	if relinfo.trafficTarget != nil {
+		traffic = progressTowards(
+			float64(relinfo.trafficTarget.Status.AchievedTraffic),
+			float64(step.Traffic.Contender))
+	}
+
+	return (installation + capacity + traffic) / 3
+}
+
+func progressTowards(achieved, target float64) float64 {
+	if target <= 0 {
+		return 1
+	}
+
+	return math.Min(achieved/target, 1)
+}
+
+// estimateETA extrapolates when a release will complete its target step from
+// the rate at which it progressed since the step started. The whole rollout
+// is then estimated by assuming each remaining step takes as long as the
+// previous ones did on average, including the time spent waiting for
+// someone to advance them. The estimates are kept as they are until progress
+// changes.
+func estimateETA(
+	rel *shipper.Release,
+	targetStep int32,
+	numSteps int,
+	progress float64,
+	now time.Time,
+) *shipper.ReleaseETA {
+	progressPercent := int32(math.Floor(progress * 100))
+
+	eta := rel.Status.ETA
+	if eta == nil || eta.Step != targetStep {
+		return &shipper.ReleaseETA{
+			Step:                targetStep,
+			StepStartedAt:       metav1.NewTime(now),
+			InitialStepProgress: progressPercent,
+			StepProgress:        progressPercent,
+		}
+	}
+
+	if eta.StepProgress == progressPercent {
+		// Estimates only change along with progress. Recomputing them
+		// from now would have the release updated on every sync.
+		return eta
+	}
+
+	eta = eta.DeepCopy()
+	eta.StepProgress = progressPercent
+	eta.StepCompletion = nil
+	eta.RolloutCompletion = nil
+
+	elapsed := now.Sub(eta.StepStartedAt.Time)
+	progressed := progressPercent - eta.InitialStepProgress
+	if progressed <= 0 || elapsed <= 0 {
+		// Nothing has moved yet, so there's no rate to extrapolate
+		// from.
+		return eta
+	}
+
+	remaining := time.Duration(float64(elapsed) * float64(100-progressPercent) / float64(progressed))
+	stepCompletion := now.Add(remaining)
+	eta.StepCompletion = &metav1.Time{Time: stepCompletion}
+
+	remainingSteps := int32(numSteps) - 1 - targetStep
+	if remainingSteps <= 0 {
+		eta.RolloutCompletion = eta.StepCompletion
+		return eta
+	}
+
+	var stepDuration time.Duration
+	if targetStep > 0 && !rel.CreationTimestamp.IsZero() {
+		stepDuration = eta.StepStartedAt.Sub(rel.CreationTimestamp.Time) / time.Duration(targetStep)
+	} else {
+		stepDuration = stepCompletion.Sub(eta.StepStartedAt.Time)
+	}
+
+	eta.RolloutCompletion = &metav1.Time{Time: stepCompletion.Add(stepDuration * time.Duration(remainingSteps))}
+
+	return eta
+}
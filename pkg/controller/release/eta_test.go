@@ -0,0 +1,119 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestEstimateETAStartsStep(t *testing.T) {
+	now := time.Now()
+	rel := &shipper.Release{}
+
+	eta := estimateETA(rel, 1, 3, 0.25, now)
+
+	if eta.Step != 1 || !eta.StepStartedAt.Time.Equal(now) {
+		t.Fatalf("expected step 1 to start now, got %+v", eta)
+	}
+
+	if eta.InitialStepProgress != 25 || eta.StepProgress != 25 {
+		t.Fatalf("expected progress to be 25%%, got %+v", eta)
+	}
+
+	if eta.StepCompletion != nil || eta.RolloutCompletion != nil {
+		t.Fatalf("expected no estimates without any progress, got %+v", eta)
+	}
+}
+
+func TestEstimateETAExtrapolates(t *testing.T) {
+	created := time.Date(2019, 8, 21, 12, 0, 0, 0, time.UTC)
+	stepStarted := created.Add(20 * time.Minute)
+	now := stepStarted.Add(5 * time.Minute)
+
+	rel := &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: shipper.ReleaseStatus{
+			ETA: &shipper.ReleaseETA{
+				Step:                1,
+				StepStartedAt:       metav1.NewTime(stepStarted),
+				InitialStepProgress: 0,
+			},
+		},
+	}
+
+	// 50% in 5 minutes leaves 5 more minutes for this step. Step 0
+	// took 20 minutes, so the last step is expected to take as long.
+	eta := estimateETA(rel, 1, 3, 0.5, now)
+
+	expectedStep := now.Add(5 * time.Minute)
+	if eta.StepCompletion == nil || !eta.StepCompletion.Time.Equal(expectedStep) {
+		t.Fatalf("expected step to complete at %s, got %+v", expectedStep, eta.StepCompletion)
+	}
+
+	expectedRollout := expectedStep.Add(20 * time.Minute)
+	if eta.RolloutCompletion == nil || !eta.RolloutCompletion.Time.Equal(expectedRollout) {
+		t.Fatalf("expected rollout to complete at %s, got %+v", expectedRollout, eta.RolloutCompletion)
+	}
+}
+
+func TestEstimateETALastStep(t *testing.T) {
+	stepStarted := time.Date(2019, 8, 21, 12, 0, 0, 0, time.UTC)
+	now := stepStarted.Add(time.Minute)
+
+	rel := &shipper.Release{
+		Status: shipper.ReleaseStatus{
+			ETA: &shipper.ReleaseETA{
+				Step:                2,
+				StepStartedAt:       metav1.NewTime(stepStarted),
+				InitialStepProgress: 20,
+			},
+		},
+	}
+
+	eta := estimateETA(rel, 2, 3, 0.6, now)
+
+	expected := now.Add(time.Minute)
+	if eta.StepCompletion == nil || !eta.StepCompletion.Time.Equal(expected) {
+		t.Fatalf("expected step to complete at %s, got %+v", expected, eta.StepCompletion)
+	}
+
+	if eta.RolloutCompletion == nil || !eta.RolloutCompletion.Time.Equal(expected) {
+		t.Fatalf("expected rollout to complete with the last step at %s, got %+v", expected, eta.RolloutCompletion)
+	}
+}
+
+// TestEstimateETAKeptWithoutProgress verifies that a sync without any
+// progress since the last one leaves the estimates as they were, so the
+// release isn't updated on every sync.
+func TestEstimateETAKeptWithoutProgress(t *testing.T) {
+	created := time.Date(2019, 8, 21, 12, 0, 0, 0, time.UTC)
+	stepStarted := created.Add(20 * time.Minute)
+	now := stepStarted.Add(5 * time.Minute)
+
+	rel := &shipper.Release{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: shipper.ReleaseStatus{
+			ETA: &shipper.ReleaseETA{
+				Step:                1,
+				StepStartedAt:       metav1.NewTime(stepStarted),
+				InitialStepProgress: 0,
+			},
+		},
+	}
+
+	rel.Status.ETA = estimateETA(rel, 1, 3, 0.5, now)
+	expected := rel.Status.ETA.DeepCopy()
+
+	eta := estimateETA(rel, 1, 3, 0.5, now.Add(time.Minute))
+	if !equality.Semantic.DeepEqual(expected, eta) {
+		t.Fatalf("expected estimates to be kept as %+v, got %+v", expected, eta)
+	}
+}
@@ -0,0 +1,216 @@
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+const (
+	hookPhasePre  = "pre"
+	hookPhasePost = "post"
+
+	// Job names end up in the job-name label of their pods, so they need
+	// to fit in a label value.
+	maxHookJobNameLength = 63
+)
+
+var jobGVK = batchv1.SchemeGroupVersion.WithKind("Job")
+
+// runStepHooks makes sure the Jobs for hooks have been created in a cluster,
+// and returns nil once all of them have completed. While any of them is
+// still running, it returns a StepHookPendingError, and a StepHookFailedError
+// as soon as one of them fails.
+func (c *Controller) runStepHooks(
+	relinfo *releaseInfo,
+	step int32,
+	phase string,
+	hooks []shipper.StepHook,
+	clusterName string,
+	kubeClient kubernetes.Interface,
+) error {
+	rel := relinfo.release
+	relKey := objectutil.MetaKey(rel)
+
+	var templates map[string]*batchv1.Job
+	for _, hook := range hooks {
+		spec := hook.Job
+		var template *batchv1.Job
+		if hook.Template != "" {
+			if templates == nil {
+				var err error
+				templates, err = c.renderHookTemplates(rel, clusterName, kubeClient)
+				if err != nil {
+					return err
+				}
+			}
+
+			var ok bool
+			template, ok = templates[hook.Template]
+			if !ok {
+				return shippererrors.NewStepHookFailedError(relKey, step, hook.Name, clusterName,
+					fmt.Sprintf("chart has no Job with annotation %s=%s", shipper.StepHookAnnotation, hook.Template))
+			}
+			spec = &template.Spec
+		}
+
+		job := buildHookJob(relinfo, step, phase, hook.Name, spec)
+		if template != nil {
+			job.Labels = labels.Merge(template.Labels, job.Labels)
+			job.Annotations = template.Annotations
+		}
+
+		jobs := kubeClient.BatchV1().Jobs(job.Namespace)
+		existing, err := jobs.Get(job.Name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			if _, err := jobs.Create(job); err != nil {
+				return shippererrors.NewKubeclientCreateError(job, err).WithKind(jobGVK)
+			}
+
+			c.recorder.Eventf(rel, corev1.EventTypeNormal, shipperevents.StepHookCreated,
+				"Created Job %q for %s-step hook %q in cluster %q", job.Name, phase, hook.Name, clusterName)
+
+			return shippererrors.NewStepHookPendingError(relKey, step, hook.Name, clusterName)
+		} else if err != nil {
+			return shippererrors.NewKubeclientGetError(job.Namespace, job.Name, err).WithKind(jobGVK)
+		}
+
+		if complete, failed, msg := jobOutcome(existing); failed {
+			return shippererrors.NewStepHookFailedError(relKey, step, hook.Name, clusterName, msg)
+		} else if !complete {
+			return shippererrors.NewStepHookPendingError(relKey, step, hook.Name, clusterName)
+		}
+	}
+
+	return nil
+}
+
+// renderHookTemplates returns the Jobs in a release's chart that are marked
+// as step hooks, rendered for a cluster, keyed by the value of their
+// StepHookAnnotation.
+func (c *Controller) renderHookTemplates(
+	rel *shipper.Release,
+	clusterName string,
+	kubeClient kubernetes.Interface,
+) (map[string]*batchv1.Job, error) {
+	chart, err := c.chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := valuesource.NewResolver(kubeClient).Resolve(
+		rel.Namespace,
+		releaseutil.GetClusterValues(rel, clusterName),
+		rel.Spec.Environment.ValuesFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := shipperchart.Render(chart, rel.Name, rel.Namespace, &values)
+	if err != nil {
+		return nil, shippererrors.NewBrokenChartSpecError(&rel.Spec.Environment.Chart, err)
+	}
+
+	templates := make(map[string]*batchv1.Job)
+	for _, manifest := range manifests {
+		obj, _, err := kubescheme.Codecs.UniversalDeserializer().Decode([]byte(manifest), nil, nil)
+		if err != nil {
+			return nil, shippererrors.NewDecodeManifestError("error decoding manifest: %s", err)
+		}
+
+		job, ok := obj.(*batchv1.Job)
+		if !ok {
+			continue
+		}
+
+		if name, ok := job.Annotations[shipper.StepHookAnnotation]; ok {
+			templates[name] = job
+		}
+	}
+
+	return templates, nil
+}
+
+func buildHookJob(relinfo *releaseInfo, step int32, phase, hookName string, spec *batchv1.JobSpec) *batchv1.Job {
+	rel := relinfo.release
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      hookJobName(rel.Name, step, phase, hookName),
+			Namespace: rel.Namespace,
+			Labels: labels.Merge(rel.Labels, labels.Set{
+				shipper.StepHookLabel: hookName,
+			}),
+		},
+		Spec: *spec.DeepCopy(),
+	}
+
+	// Jobs go away together with the rest of the release in the
+	// application cluster.
+	if it := relinfo.installationTarget; it != nil {
+		job.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: shipper.SchemeGroupVersion.String(),
+				Kind:       "InstallationTarget",
+				Name:       it.Name,
+				UID:        it.UID,
+			},
+		}
+	}
+
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	return job
+}
+
+func hookJobName(relName string, step int32, phase, hookName string) string {
+	name := fmt.Sprintf("%s-%d-%s-%s", relName, step, phase, hookName)
+	if len(name) <= maxHookJobNameLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:8]
+
+	return fmt.Sprintf("%s-%s", name[:maxHookJobNameLength-len(suffix)-1], suffix)
+}
+
+func jobOutcome(job *batchv1.Job) (complete, failed bool, msg string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return true, false, ""
+		case batchv1.JobFailed:
+			return false, true, cond.Message
+		}
+	}
+
+	return false, false, ""
+}
+
+// stepRunsInCluster tells whether Shipper needs to reach into target
+// clusters itself for step, to run its hooks or probes.
+func stepRunsInCluster(step shipper.RolloutStrategyStep) bool {
+	return len(step.PreHooks) > 0 || len(step.PostHooks) > 0 || len(step.Probes) > 0
+}
@@ -0,0 +1,137 @@
+package release
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+const (
+	IncumbentFloorHeld = "IncumbentFloorHeld"
+)
+
+// incumbentCapacityPercent returns the capacity percentage that keeps the
+// incumbent at capacity, rounding absolute replica counts up.
+func incumbentCapacityPercent(capacity intstr.IntOrString, incumbent *releaseInfo) int32 {
+	if capacity.Type == intstr.String {
+		percent, err := intstr.GetValueFromIntOrPercent(&capacity, 100, true)
+		if err != nil {
+			return 0
+		}
+		return clampPercent(int32(percent))
+	}
+
+	total := incumbent.capacityTarget.Spec.TotalReplicaCount
+	if total <= 0 {
+		return 0
+	}
+
+	replicas := capacity.IntVal
+	return clampPercent((replicas*100 + total - 1) / total)
+}
+
+func clampPercent(percent int32) int32 {
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// remainingSoak returns how much longer the contender needs to keep all the
+// traffic before the incumbent can go below its floor, and whether it has
+// had all the traffic at all.
+func remainingSoak(floor *shipper.IncumbentFloor, contender *shipper.Release, now time.Time) (time.Duration, bool) {
+	since := contender.Status.FullTrafficSince
+	if since == nil {
+		return floor.SoakDuration.Duration, false
+	}
+
+	remaining := since.Add(floor.SoakDuration.Duration).Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, true
+}
+
+// applyIncumbentFloor raises the capacity the incumbent is scaled down to
+// up to the strategy's floor while the contender hasn't soaked at full
+// traffic. It returns the capacity to use, and a message if the floor is
+// holding the incumbent up.
+func applyIncumbentFloor(
+	floor *shipper.IncumbentFloor,
+	capacityWeight int32,
+	incumbent, contender *releaseInfo,
+	now time.Time,
+) (int32, string) {
+	if floor == nil || contender == nil {
+		return capacityWeight, ""
+	}
+
+	// The floor only ever keeps the incumbent from going down. It never
+	// scales it back up, e.g. for releases that were already scaled down
+	// before it was set.
+	floorPercent := incumbentCapacityPercent(floor.Capacity, incumbent)
+	if current := incumbent.capacityTarget.Spec.Percent; current < floorPercent {
+		floorPercent = current
+	}
+
+	if capacityWeight >= floorPercent {
+		return capacityWeight, ""
+	}
+
+	remaining, fullTraffic := remainingSoak(floor, contender.release, now)
+	if fullTraffic && remaining == 0 {
+		return capacityWeight, ""
+	}
+
+	var msg string
+	if fullTraffic {
+		msg = fmt.Sprintf(
+			"keeping incumbent at %d%% capacity for another %s, until contender has had all traffic for %s",
+			floorPercent, remaining.Round(time.Second), floor.SoakDuration.Duration)
+	} else {
+		msg = fmt.Sprintf(
+			"keeping incumbent at %d%% capacity until contender has had all traffic for %s",
+			floorPercent, floor.SoakDuration.Duration)
+	}
+
+	return floorPercent, msg
+}
+
+// givesAllTraffic tells whether a strategy step hands all the traffic over
+// to the contender, which is when it starts soaking.
+func givesAllTraffic(step shipper.RolloutStrategyStep) bool {
+	return step.Traffic.Incumbent == 0 && step.Traffic.Contender > 0
+}
+
+// updateFullTrafficSince records when a head release got all the traffic in
+// every cluster, and forgets it as soon as it doesn't have it anymore.
+func updateFullTrafficSince(
+	rel *shipper.Release,
+	step shipper.RolloutStrategyStep,
+	clusterConditions map[string]conditions.StrategyConditionsMap,
+) {
+	fullTraffic := givesAllTraffic(step) && len(clusterConditions) > 0
+	for _, cond := range clusterConditions {
+		if !cond.IsTrue(shipper.StrategyConditionContenderAchievedTraffic) {
+			fullTraffic = false
+			break
+		}
+	}
+
+	if !fullTraffic {
+		rel.Status.FullTrafficSince = nil
+	} else if rel.Status.FullTrafficSince == nil {
+		now := metav1.Now()
+		rel.Status.FullTrafficSince = &now
+	}
+}
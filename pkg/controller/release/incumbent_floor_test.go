@@ -0,0 +1,59 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestApplyIncumbentFloor(t *testing.T) {
+	now := time.Now()
+	soak := time.Hour
+
+	tests := []struct {
+		name     string
+		capacity intstr.IntOrString
+		current  int32
+		since    *time.Time
+		expected int32
+		held     bool
+	}{
+		{"percent floor before full traffic", intstr.FromString("50%"), 100, nil, 50, true},
+		{"replica floor rounds up", intstr.FromInt(1), 100, nil, 25, true},
+		{"still soaking", intstr.FromString("50%"), 100, timePtr(now.Add(-soak / 2)), 50, true},
+		{"done soaking", intstr.FromString("50%"), 100, timePtr(now.Add(-soak)), 0, false},
+		{"never scales back up", intstr.FromString("50%"), 0, nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		incumbent := buildCapacityReleaseInfo("incumbent", tt.current, 4)
+
+		contender := buildCapacityReleaseInfo("contender", 0, 4)
+		if tt.since != nil {
+			since := metav1.NewTime(*tt.since)
+			contender.release.Status.FullTrafficSince = &since
+		}
+
+		floor := &shipper.IncumbentFloor{
+			Capacity:     tt.capacity,
+			SoakDuration: metav1.Duration{Duration: soak},
+		}
+
+		capacity, msg := applyIncumbentFloor(floor, 0, incumbent, contender, now)
+		if capacity != tt.expected {
+			t.Errorf("%s: expected capacity %d, got %d", tt.name, tt.expected, capacity)
+		}
+
+		if held := msg != ""; held != tt.held {
+			t.Errorf("%s: expected incumbent to be held: %t, got message %q", tt.name, tt.held, msg)
+		}
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
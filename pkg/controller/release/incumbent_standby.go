@@ -0,0 +1,52 @@
+package release
+
+import (
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// applyIncumbentStandby raises the capacity the incumbent is scaled down to
+// up to the strategy's standby capacity. Like the floor, it never scales
+// the incumbent back up.
+func applyIncumbentStandby(
+	standby *shipper.IncumbentStandby,
+	capacityWeight int32,
+	incumbent *releaseInfo,
+) int32 {
+	if standby == nil {
+		return capacityWeight
+	}
+
+	standbyPercent := incumbentCapacityPercent(standby.Capacity, incumbent)
+	if current := incumbent.capacityTarget.Spec.Percent; current < standbyPercent {
+		standbyPercent = current
+	}
+
+	if capacityWeight >= standbyPercent {
+		return capacityWeight
+	}
+
+	return standbyPercent
+}
+
+// onStandby tells whether an incumbent is still kept on standby by the
+// strategy of the release that followed it, which is until the janitor ends
+// it.
+func onStandby(strategy *shipper.RolloutStrategy, incumbent *shipper.Release) bool {
+	if strategy.IncumbentStandby == nil || incumbent == nil {
+		return false
+	}
+
+	_, ended := incumbent.Annotations[shipper.StandbyEndedAnnotation]
+	return !ended
+}
+
+// instantRollingBack tells whether rel is being rolled back to incumbent by
+// `shipperctl rollback --instant`, which lasts until it's back at its first
+// step.
+func instantRollingBack(rel, incumbent *shipper.Release) bool {
+	if incumbent == nil || rel.Spec.TargetStep != 0 {
+		return false
+	}
+
+	return rel.Annotations[shipper.InstantRollbackAnnotation] == incumbent.Name
+}
@@ -0,0 +1,84 @@
+package release
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestApplyIncumbentStandby(t *testing.T) {
+	incumbentRel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "incumbent", 20)
+
+	tests := []struct {
+		name     string
+		capacity intstr.IntOrString
+		weight   int32
+		current  int32
+		expected int32
+	}{
+		{"percent standby", intstr.FromString("5%"), 0, 100, 5},
+		{"replica standby rounds up", intstr.FromInt(3), 0, 100, 15},
+		{"step gives more than standby", intstr.FromString("5%"), 50, 100, 50},
+		{"never scales back up", intstr.FromString("5%"), 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		_, _, incumbentCT := buildAssociatedObjects(incumbentRel, nil)
+		incumbentCT.Spec.Percent = tt.current
+		incumbentCT.Spec.TotalReplicaCount = 20
+		incumbent := &releaseInfo{release: incumbentRel, capacityTarget: incumbentCT}
+
+		standby := &shipper.IncumbentStandby{Capacity: tt.capacity}
+		if capacity := applyIncumbentStandby(standby, tt.weight, incumbent); capacity != tt.expected {
+			t.Errorf("%s: expected capacity %d, got %d", tt.name, tt.expected, capacity)
+		}
+	}
+
+	if capacity := applyIncumbentStandby(nil, 0, nil); capacity != 0 {
+		t.Errorf("expected incumbents off standby to be scaled down, got capacity %d", capacity)
+	}
+}
+
+// TestOnStandby verifies that incumbents are kept on standby until the
+// janitor ends it.
+func TestOnStandby(t *testing.T) {
+	strategy := &shipper.RolloutStrategy{
+		IncumbentStandby: &shipper.IncumbentStandby{Capacity: intstr.FromString("5%")},
+	}
+	incumbent := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "incumbent", 1)
+
+	if !onStandby(strategy, incumbent) {
+		t.Errorf("expected incumbent to be on standby")
+	}
+
+	if onStandby(&shipper.RolloutStrategy{}, incumbent) {
+		t.Errorf("expected incumbent not to be on standby without a strategy keeping it on one")
+	}
+
+	incumbent.Annotations[shipper.StandbyEndedAnnotation] = "2020-01-01T00:00:00Z"
+	if onStandby(strategy, incumbent) {
+		t.Errorf("expected incumbent not to be on standby once it ended")
+	}
+}
+
+func TestInstantRollingBack(t *testing.T) {
+	incumbent := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "incumbent", 1)
+	rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "contender", 1)
+	rel.Annotations[shipper.InstantRollbackAnnotation] = incumbent.Name
+
+	if !instantRollingBack(rel, incumbent) {
+		t.Errorf("expected release to be rolling back to its incumbent")
+	}
+
+	if instantRollingBack(rel, nil) {
+		t.Errorf("expected release without an incumbent not to be rolling back")
+	}
+
+	rel.Spec.TargetStep = 1
+	if instantRollingBack(rel, incumbent) {
+		t.Errorf("expected release moved past its first step not to be rolling back anymore")
+	}
+}
@@ -0,0 +1,93 @@
+package release
+
+import (
+	"fmt"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	MaxTotalCapacityHeld = "MaxTotalCapacityHeld"
+)
+
+// applyMaxTotalCapacity lowers the capacity the contender is scaled up to
+// so that, together with what the incumbent still has, it stays within the
+// cluster's maxTotalCapacityPercent. It never scales the contender back
+// down. It returns the capacity to use, and a message if the contender is
+// being held back.
+func applyMaxTotalCapacity(
+	maxTotal *int32,
+	capacityWeight int32,
+	contender, incumbent *releaseInfo,
+) (int32, string) {
+	if maxTotal == nil || incumbent == nil {
+		return capacityWeight, ""
+	}
+
+	incumbentPercent := incumbent.capacityTarget.Spec.Percent
+	allowedPercent := *maxTotal - incumbentPercent
+	if current := contender.capacityTarget.Spec.Percent; allowedPercent < current {
+		allowedPercent = current
+	}
+
+	if capacityWeight <= allowedPercent {
+		return capacityWeight, ""
+	}
+
+	msg := fmt.Sprintf(
+		"keeping contender at %d%% capacity until incumbent is scaled down: it's at %d%%, and the cluster fits at most %d%% in total",
+		allowedPercent, incumbentPercent, *maxTotal)
+
+	return allowedPercent, msg
+}
+
+// totalCapacityHold is how a contender is held back to fit in a cluster's
+// maxTotalCapacityPercent next to its incumbent.
+type totalCapacityHold struct {
+	// capacity is the capacity the contender is held at.
+	capacity int32
+	msg      string
+}
+
+// holdForMaxTotalCapacity returns how the contender is held back in the
+// step, or nil if it isn't.
+func holdForMaxTotalCapacity(
+	maxTotal *int32,
+	strategyStep shipper.RolloutStrategyStep,
+	contender, incumbent *releaseInfo,
+) *totalCapacityHold {
+	capacity, msg := applyMaxTotalCapacity(
+		maxTotal, strategyStep.Capacity.Contender, contender, incumbent)
+	if msg == "" {
+		return nil
+	}
+
+	return &totalCapacityHold{capacity: capacity, msg: msg}
+}
+
+// heldTrafficWeights returns the traffic weights of the contender and the
+// incumbent while the contender is held at capacity: the contender only
+// gets as much of the step's traffic as it has capacity for, and the
+// incumbent keeps the rest.
+func heldTrafficWeights(strategyStep shipper.RolloutStrategyStep, capacity int32) (int32, int32) {
+	contender := strategyStep.Traffic.Contender
+	total := contender + strategyStep.Traffic.Incumbent
+	if capped := total * capacity / 100; capped < contender {
+		contender = capped
+	}
+
+	return contender, total - contender
+}
+
+// heldIncumbentCapacity returns the capacity the incumbent needs for the
+// traffic it keeps while the contender is held at capacity.
+func heldIncumbentCapacity(strategyStep shipper.RolloutStrategyStep, capacity int32) int32 {
+	_, incumbent := heldTrafficWeights(strategyStep, capacity)
+	total := strategyStep.Traffic.Contender + strategyStep.Traffic.Incumbent
+	if total <= 0 {
+		return 0
+	}
+
+	// Rounding up keeps more capacity around rather than less.
+	return clampPercent((incumbent*100 + total - 1) / total)
+}
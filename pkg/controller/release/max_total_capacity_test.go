@@ -0,0 +1,42 @@
+package release
+
+import (
+	"testing"
+)
+
+func TestApplyMaxTotalCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTotal  int32
+		weight    int32
+		incumbent int32
+		current   int32
+		expected  int32
+		held      bool
+	}{
+		{"fits", 150, 50, 100, 0, 50, false},
+		{"no headroom left", 120, 50, 100, 0, 20, true},
+		{"incumbent scaled down", 120, 50, 70, 20, 50, false},
+		{"incumbent partly scaled down", 120, 100, 50, 50, 70, true},
+		{"never scales back down", 100, 50, 100, 10, 10, true},
+		{"scaling down", 100, 0, 100, 50, 0, false},
+	}
+
+	for _, tt := range tests {
+		incumbent := buildCapacityReleaseInfo("incumbent", tt.incumbent, 10)
+		contender := buildCapacityReleaseInfo("contender", tt.current, 10)
+
+		capacity, msg := applyMaxTotalCapacity(&tt.maxTotal, tt.weight, contender, incumbent)
+		if capacity != tt.expected {
+			t.Errorf("%s: expected capacity %d, got %d", tt.name, tt.expected, capacity)
+		}
+
+		if held := msg != ""; held != tt.held {
+			t.Errorf("%s: expected contender to be held: %t, got message %q", tt.name, tt.held, msg)
+		}
+	}
+
+	if capacity, _ := applyMaxTotalCapacity(nil, 50, nil, nil); capacity != 50 {
+		t.Errorf("expected clusters without maxTotalCapacityPercent to scale the contender up, got capacity %d", capacity)
+	}
+}
@@ -0,0 +1,58 @@
+package release
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	MaxUnavailableHeld = "MaxUnavailableHeld"
+)
+
+// applyMaxUnavailable raises the capacity the incumbent is scaled down to
+// for as long as the available replicas of the contender don't make up for
+// the ones the incumbent would lose beyond the step's maxUnavailable. Like
+// the floor, it never scales the incumbent back up. It returns the capacity
+// to use, and a message if the incumbent is being held up.
+func applyMaxUnavailable(
+	maxUnavailable *intstr.IntOrString,
+	capacityWeight int32,
+	incumbent, contender *releaseInfo,
+) (int32, string) {
+	if maxUnavailable == nil || contender == nil {
+		return capacityWeight, ""
+	}
+
+	total := incumbent.capacityTarget.Spec.TotalReplicaCount
+	if total <= 0 {
+		return capacityWeight, ""
+	}
+
+	// Rounding down keeps more replicas around rather than fewer.
+	unavailable, err := intstr.GetValueFromIntOrPercent(maxUnavailable, int(total), false)
+	if err != nil {
+		return capacityWeight, ""
+	}
+
+	available := contender.capacityTarget.Status.AvailableReplicas
+	needed := total - int32(unavailable) - available
+	if needed <= 0 {
+		return capacityWeight, ""
+	}
+
+	heldPercent := clampPercent((needed*100 + total - 1) / total)
+	if current := incumbent.capacityTarget.Spec.Percent; current < heldPercent {
+		heldPercent = current
+	}
+
+	if capacityWeight >= heldPercent {
+		return capacityWeight, ""
+	}
+
+	msg := fmt.Sprintf(
+		"keeping incumbent at %d%% capacity until contender has more available replicas: it has %d, and at most %s of %d replicas may be unavailable",
+		heldPercent, available, maxUnavailable.String(), total)
+
+	return heldPercent, msg
+}
@@ -0,0 +1,45 @@
+package release
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestApplyMaxUnavailable(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxUnavailable intstr.IntOrString
+		weight         int32
+		current        int32
+		available      int32
+		expected       int32
+		held           bool
+	}{
+		{"contender not available yet", intstr.FromString("10%"), 50, 100, 0, 90, true},
+		{"contender partly available", intstr.FromInt(1), 50, 100, 3, 60, true},
+		{"contender available", intstr.FromString("10%"), 50, 100, 5, 50, false},
+		{"step keeps more", intstr.FromString("50%"), 50, 100, 0, 50, false},
+		{"never scales back up", intstr.FromString("10%"), 0, 40, 0, 40, true},
+		{"already scaled down", intstr.FromString("10%"), 0, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		incumbent := buildCapacityReleaseInfo("incumbent", tt.current, 10)
+		contender := buildCapacityReleaseInfo("contender", 0, 10)
+		contender.capacityTarget.Status.AvailableReplicas = tt.available
+
+		capacity, msg := applyMaxUnavailable(&tt.maxUnavailable, tt.weight, incumbent, contender)
+		if capacity != tt.expected {
+			t.Errorf("%s: expected capacity %d, got %d", tt.name, tt.expected, capacity)
+		}
+
+		if held := msg != ""; held != tt.held {
+			t.Errorf("%s: expected incumbent to be held: %t, got message %q", tt.name, tt.held, msg)
+		}
+	}
+
+	if capacity, _ := applyMaxUnavailable(nil, 0, nil, nil); capacity != 0 {
+		t.Errorf("expected steps without maxUnavailable to scale the incumbent down, got capacity %d", capacity)
+	}
+}
@@ -1,6 +1,8 @@
 package release
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -12,9 +14,14 @@ import (
 )
 
 func (c *Controller) migrateTargetObjects(relName, namespace string) error {
-	selector := labels.Set{shipper.ReleaseLabel: relName}.AsSelector()
+	// The copies of target objects kept for clusters in pull mode aren't
+	// leftovers to migrate.
+	selector, err := labels.Parse(fmt.Sprintf("%s=%s,!%s", shipper.ReleaseLabel, relName, shipper.PullClusterLabel))
+	if err != nil {
+		return shippererrors.NewUnrecoverableError(err)
+	}
 
-	err := c.migrateCapacityTargets(relName, namespace, selector)
+	err = c.migrateCapacityTargets(relName, namespace, selector)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+package release
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// checkImagePlatforms returns an error unless every image in rel's chart has
+// a manifest for each of the platforms rel asks for.
+func (c *Controller) checkImagePlatforms(rel *shipper.Release) error {
+	platforms := rel.Spec.Environment.ClusterRequirements.Platforms
+	if c.imageInspector == nil || len(platforms) == 0 {
+		return nil
+	}
+
+	chart, err := c.chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return err
+	}
+
+	images, err := extractImagesFromChartForRel(chart, rel)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		available, err := c.imageInspector.Platforms(image)
+		if err != nil {
+			return shippererrors.NewImageInspectionError(image, err)
+		}
+
+		if missing := missingPlatforms(platforms, available); len(missing) > 0 {
+			return shippererrors.NewImagePlatformsError(image, missing)
+		}
+	}
+
+	return nil
+}
+
+// extractImagesFromChartForRel returns the images of the containers of the
+// Deployments and Jobs in rel's chart, sorted, with the release's image
+// override applied.
+func extractImagesFromChartForRel(chart *helmchart.Chart, rel *shipper.Release) ([]string, error) {
+	rendered, err := renderChartForRel(chart, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	add := func(containers []corev1.Container, override *shipper.ImageOverride) {
+		for _, container := range containers {
+			image := container.Image
+			if override != nil && (override.Container == "" || override.Container == container.Name) {
+				image = override.Image
+			}
+
+			seen[image] = struct{}{}
+		}
+	}
+
+	for _, deployment := range shipperchart.GetDeployments(rendered) {
+		add(deployment.Spec.Template.Spec.InitContainers, nil)
+		add(deployment.Spec.Template.Spec.Containers, rel.Spec.Environment.ImageOverride)
+	}
+
+	for _, job := range shipperchart.GetJobs(rendered) {
+		add(job.Spec.Template.Spec.InitContainers, nil)
+		add(job.Spec.Template.Spec.Containers, nil)
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	return images, nil
+}
+
+// missingPlatforms returns the platforms in required that aren't in
+// available.
+func missingPlatforms(required, available []string) []string {
+	has := make(map[string]struct{}, len(available))
+	for _, platform := range available {
+		has[platform] = struct{}{}
+	}
+
+	var missing []string
+	for _, platform := range required {
+		if _, ok := has[platform]; !ok {
+			missing = append(missing, platform)
+		}
+	}
+
+	return missing
+}
+
+// renderChartForRel renders chart with rel's values. Values coming from
+// secret stores are only read in application clusters, so they're left as
+// placeholders.
+func renderChartForRel(chart *helmchart.Chart, rel *shipper.Release) ([]string, error) {
+	values := valuesource.WithPlaceholders(
+		releaseutil.EnvironmentValues(&rel.Spec.Environment),
+		rel.Spec.Environment.ValuesFrom)
+
+	rendered, err := shipperchart.Render(chart, rel.Name, rel.Namespace, &values)
+	if err != nil {
+		return nil, shippererrors.NewBrokenChartSpecError(
+			&rel.Spec.Environment.Chart,
+			err,
+		)
+	}
+
+	return rendered, nil
+}
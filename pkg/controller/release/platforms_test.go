@@ -0,0 +1,67 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+type fakeImageInspector map[string][]string
+
+func (f fakeImageInspector) Platforms(image string) ([]string, error) {
+	return f[image], nil
+}
+
+// TestCheckImagePlatforms verifies that releases asking for platforms are
+// only let through when every image in their chart, after the image
+// override, has a manifest for all of them.
+func TestCheckImagePlatforms(t *testing.T) {
+	c := &Controller{
+		chartFetcher: shippertesting.LocalFetchChart,
+		imageInspector: fakeImageInspector{
+			"alpine:latest":      {"linux/amd64", "linux/arm64"},
+			"example.com/hotfix": {"linux/amd64"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		platforms []string
+		override  *shipper.ImageOverride
+		missing   bool
+	}{
+		{
+			name:      "all platforms available",
+			platforms: []string{"linux/arm64", "linux/amd64"},
+		},
+		{
+			name:      "platform missing",
+			platforms: []string{"linux/arm64", "windows/amd64"},
+			missing:   true,
+		},
+		{
+			name:      "platform missing from override",
+			platforms: []string{"linux/arm64"},
+			override:  &shipper.ImageOverride{Image: "example.com/hotfix"},
+			missing:   true,
+		},
+		{
+			name: "no platforms asked for",
+		},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "0", 1)
+		rel.Spec.Environment.ClusterRequirements.Platforms = tt.platforms
+		rel.Spec.Environment.ImageOverride = tt.override
+
+		err := c.checkImagePlatforms(rel)
+		if _, ok := err.(shippererrors.ImagePlatformsError); ok != tt.missing {
+			t.Errorf("%s: expected missing platforms to be %t, got error %v", tt.name, tt.missing, err)
+		} else if !tt.missing && err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/policy"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+)
+
+// checkPolicies evaluates every Policy that applies to rel at stage, and
+// returns an error for the first one rel violates.
+func (c *Controller) checkPolicies(rel *shipper.Release, clusters []string, stage shipper.PolicyStage) error {
+	policies, err := c.policiesFor(rel, stage)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+
+	if c.policyEvaluator == nil {
+		return shippererrors.NewPolicyEvaluationError(objectutil.MetaKey(policies[0]),
+			fmt.Errorf("no policy engine is configured"))
+	}
+
+	input := policy.Input{
+		Stage:      stage,
+		Release:    rel,
+		Clusters:   clusters,
+		TargetStep: rel.Spec.TargetStep,
+	}
+
+	if stage == shipper.PolicyStageInstallation {
+		input.Manifests, err = c.renderManifests(rel)
+		if err != nil {
+			return err
+		}
+	}
+
+	relKey := objectutil.MetaKey(rel)
+	for _, p := range policies {
+		policyKey := objectutil.MetaKey(p)
+		violations, err := c.policyEvaluator.Evaluate(p, input)
+		if err != nil {
+			return shippererrors.NewPolicyEvaluationError(policyKey, err)
+		}
+
+		if len(violations) > 0 {
+			return shippererrors.NewPolicyViolationError(relKey, policyKey, stage, violations)
+		}
+	}
+
+	return nil
+}
+
+// policiesFor returns the Policies in rel's namespace and in the global
+// policy namespace that apply to rel at stage, sorted by key.
+func (c *Controller) policiesFor(rel *shipper.Release, stage shipper.PolicyStage) ([]*shipper.Policy, error) {
+	namespaces := []string{rel.Namespace}
+	if rel.Namespace != shipper.GlobalPolicyNamespace {
+		namespaces = append(namespaces, shipper.GlobalPolicyNamespace)
+	}
+
+	appName := rel.Labels[shipper.AppLabel]
+
+	var policies []*shipper.Policy
+	for _, namespace := range namespaces {
+		list, err := c.policyLister.Policies(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, shippererrors.NewKubeclientListError(
+				shipper.SchemeGroupVersion.WithKind("Policy"),
+				namespace, labels.Everything(), err)
+		}
+
+		for _, p := range list {
+			if policyApplies(p, appName) && policyEvaluatedAt(p, stage) {
+				policies = append(policies, p)
+			}
+		}
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return objectutil.MetaKey(policies[i]) < objectutil.MetaKey(policies[j])
+	})
+
+	return policies, nil
+}
+
+func policyApplies(p *shipper.Policy, appName string) bool {
+	if len(p.Spec.Applications) == 0 {
+		return true
+	}
+
+	for _, app := range p.Spec.Applications {
+		if app == appName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func policyEvaluatedAt(p *shipper.Policy, stage shipper.PolicyStage) bool {
+	if len(p.Spec.Stages) == 0 {
+		return true
+	}
+
+	for _, s := range p.Spec.Stages {
+		if s == stage {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderManifests returns the objects rendered from rel's chart.
+func (c *Controller) renderManifests(rel *shipper.Release) ([]map[string]interface{}, error) {
+	chart, err := c.chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderChartForRel(chart, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []map[string]interface{}
+	for _, doc := range rendered {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		var manifest map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &manifest); err != nil {
+			return nil, shippererrors.NewBrokenChartSpecError(&rel.Spec.Environment.Chart, err)
+		}
+
+		if manifest != nil {
+			manifests = append(manifests, manifest)
+		}
+	}
+
+	return manifests, nil
+}
+
+// enqueueReleasesFromPolicy enqueues every release a Policy applies to, so
+// it's enforced as soon as it's created, and releases it blocked are let
+// through as soon as it's changed or removed.
+func (c *Controller) enqueueReleasesFromPolicy(obj interface{}) {
+	p, ok := obj.(*shipper.Policy)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.Policy: %#v", obj))
+		return
+	}
+
+	namespace := p.Namespace
+	if namespace == shipper.GlobalPolicyNamespace {
+		namespace = ""
+	}
+
+	releases, err := c.releaseLister.Releases(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		if policyApplies(p, rel.Labels[shipper.AppLabel]) {
+			c.enqueueRelease(rel)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package release
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/policy"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// fakePolicyEvaluator denies the releases of the applications it has
+// violations for, and remembers what it was asked to evaluate.
+type fakePolicyEvaluator struct {
+	violations map[string][]string
+	inputs     []policy.Input
+}
+
+func (f *fakePolicyEvaluator) Evaluate(p *shipper.Policy, input policy.Input) ([]string, error) {
+	f.inputs = append(f.inputs, input)
+	return f.violations[input.Release.Labels[shipper.AppLabel]], nil
+}
+
+func buildPolicy(namespace, name string, stages []shipper.PolicyStage, apps ...string) *shipper.Policy {
+	return &shipper.Policy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: shipper.PolicySpec{
+			Stages:       stages,
+			Applications: apps,
+			OPA:          &shipper.OPAPolicy{Path: "shipper/deny"},
+		},
+	}
+}
+
+// TestPolicyViolationBlocksInstallation tests that a Release violating a
+// global Policy is blocked before it's installed, and that the policy is
+// evaluated against the objects rendered from its chart.
+func TestPolicyViolationBlocksInstallation(t *testing.T) {
+	evaluator := &fakePolicyEvaluator{
+		violations: map[string][]string{
+			shippertesting.TestApp: {"images must not use the latest tag"},
+		},
+	}
+
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"violates-policy",
+		1,
+	)
+	p := buildPolicy(shipper.GlobalPolicyNamespace, "no-latest", nil)
+
+	cluster := buildCluster("cluster-a")
+	mgmtClusterObjects := []runtime.Object{rel, p, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{},
+	}
+
+	msg := fmt.Sprintf(
+		"Release \"%s/%s\" violates policy \"%s/%s\" at stage Installation: images must not use the latest tag",
+		rel.Namespace, rel.Name, p.Namespace, p.Name,
+	)
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			{
+				Type:    shipper.ReleaseConditionTypeBlocked,
+				Status:  corev1.ConditionTrue,
+				Reason:  shipper.PolicyViolationReason,
+				Message: msg,
+			},
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:    shipper.ReleaseConditionTypeStrategyExecuted,
+				Status:  corev1.ConditionFalse,
+				Reason:  shipper.PolicyViolationReason,
+				Message: msg,
+			},
+		},
+	}
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		mgmtClusterObjects, appClusterObjects)
+	runReleaseControllerTestWithFixture(t, f,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		},
+		evaluator,
+	)
+
+	if len(evaluator.inputs) == 0 {
+		t.Fatalf("expected policy to be evaluated")
+	}
+
+	input := evaluator.inputs[0]
+	if input.Stage != shipper.PolicyStageInstallation {
+		t.Errorf("expected policy to be evaluated at stage %s, got %s", shipper.PolicyStageInstallation, input.Stage)
+	}
+	if len(input.Manifests) == 0 {
+		t.Errorf("expected policy to be evaluated against the chart's objects")
+	}
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	_, err := f.Clusters[cluster.Name].ShipperClient.Tracker().Get(itGVR, rel.Namespace, rel.Name)
+	if err == nil {
+		t.Errorf("expected release violating a policy not to be installed")
+	}
+}
+
+// TestPoliciesFor tests that only the Policies for a release's application
+// and for the stage it's at are evaluated.
+func TestPoliciesFor(t *testing.T) {
+	installation := []shipper.PolicyStage{shipper.PolicyStageInstallation}
+	stepAdvancement := []shipper.PolicyStage{shipper.PolicyStageStepAdvancement}
+
+	tests := []struct {
+		name     string
+		policy   *shipper.Policy
+		stage    shipper.PolicyStage
+		expected bool
+	}{
+		{"whole namespace", buildPolicy(shippertesting.TestNamespace, "p", nil), shipper.PolicyStageInstallation, true},
+		{"global", buildPolicy(shipper.GlobalPolicyNamespace, "p", nil), shipper.PolicyStageStepAdvancement, true},
+		{"other namespace", buildPolicy("other-namespace", "p", nil), shipper.PolicyStageInstallation, false},
+		{"application", buildPolicy(shippertesting.TestNamespace, "p", nil, shippertesting.TestApp), shipper.PolicyStageInstallation, true},
+		{"other application", buildPolicy(shippertesting.TestNamespace, "p", nil, "other-app"), shipper.PolicyStageInstallation, false},
+		{"stage", buildPolicy(shippertesting.TestNamespace, "p", installation), shipper.PolicyStageInstallation, true},
+		{"other stage", buildPolicy(shippertesting.TestNamespace, "p", stepAdvancement), shipper.PolicyStageInstallation, false},
+	}
+
+	for _, tt := range tests {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+		indexer.Add(tt.policy)
+
+		c := &Controller{
+			policyLister: shipperlisters.NewPolicyLister(indexer),
+		}
+
+		rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "policy", 1)
+		policies, err := c.policiesFor(rel, tt.stage)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.name, err)
+		}
+
+		if applies := len(policies) > 0; applies != tt.expected {
+			t.Errorf("%s: expected policy to apply to be %t, got %t", tt.name, tt.expected, applies)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package release
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// releaseInFlight tells releases that are rolling out, and need to be
+// synced as soon as possible to move through their steps, from complete
+// ones that are only being revisited. A complete release whose target step
+// was changed is rolling out again, even before its conditions say so.
+func releaseInFlight(lister shipperlisters.ReleaseLister) func(string) bool {
+	return func(key string) bool {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return true
+		}
+
+		rel, err := lister.Releases(namespace).Get(name)
+		if err != nil {
+			return true
+		}
+
+		achieved := rel.Status.AchievedStep
+		return !releaseutil.ReleaseComplete(rel) || achieved == nil || achieved.Step != rel.Spec.TargetStep
+	}
+}
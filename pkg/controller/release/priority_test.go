@@ -0,0 +1,72 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+func TestReleaseInFlight(t *testing.T) {
+	complete := func(rel *shipper.Release) {
+		rel.Status.AchievedStep = &shipper.AchievedStep{Step: 2, Name: "full on"}
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "", "")
+		releaseutil.SetReleaseCondition(&rel.Status, *condition)
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(*shipper.Release)
+		expected bool
+	}{
+		{
+			"rolling out",
+			func(rel *shipper.Release) {
+				rel.Status.AchievedStep = &shipper.AchievedStep{Step: 1, Name: "50/50"}
+			},
+			true,
+		},
+		{
+			"complete",
+			func(rel *shipper.Release) {
+				rel.Spec.TargetStep = 2
+				complete(rel)
+			},
+			false,
+		},
+		{
+			"rolled back",
+			func(rel *shipper.Release) {
+				rel.Spec.TargetStep = 0
+				complete(rel)
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "priority", 1)
+		tt.modify(rel)
+
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		indexer.Add(rel)
+		inFlight := releaseInFlight(shipperlisters.NewReleaseLister(indexer))
+
+		if got := inFlight(objectutil.MetaKey(rel)); got != tt.expected {
+			t.Errorf("%s: expected in flight to be %t, got %t", tt.name, tt.expected, got)
+		}
+	}
+
+	inFlight := releaseInFlight(shipperlisters.NewReleaseLister(
+		cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})))
+	if !inFlight(shippertesting.TestNamespace + "/deleted") {
+		t.Errorf("expected deleted release to be in flight")
+	}
+}
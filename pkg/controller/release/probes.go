@@ -0,0 +1,263 @@
+package release
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+const (
+	ProbesFailed = "ProbesFailed"
+
+	// maxProbedPods caps how many of the contender's pods are probed in
+	// each cluster, so large releases don't flood the API server proxy.
+	maxProbedPods = 3
+
+	probeTimeout = 10 * time.Second
+)
+
+// probePod sends a probe's request to a pod through the API server proxy, and
+// returns the status code it responded with, or 0 if it didn't.
+var probePod = func(
+	client kubernetes.Interface,
+	pod *corev1.Pod,
+	probe shipper.StepProbe,
+) (int, error) {
+	scheme := probe.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	var statusCode int
+	err := client.CoreV1().RESTClient().Get().
+		Namespace(pod.Namespace).
+		Resource("pods").
+		SubResource("proxy").
+		Name(utilnet.JoinSchemeNamePort(scheme, pod.Name, strconv.Itoa(int(probe.Port)))).
+		Suffix(probe.Path).
+		Timeout(probeTimeout).
+		Do().
+		StatusCode(&statusCode).
+		Error()
+
+	if statusCode != 0 {
+		// Any response is a result. Comparing it to the expected
+		// status is up to the caller.
+		return statusCode, nil
+	}
+
+	return 0, err
+}
+
+// gateTrafficOnProbes runs a step's probes against the contender's pods in a
+// cluster whenever patches would give it more traffic. Unless they all pass,
+// it holds back every traffic patch, so the incumbent doesn't lose traffic
+// either, and marks the contender as not having achieved traffic.
+func gateTrafficOnProbes(
+	relinfo *releaseInfo,
+	step int32,
+	probes []shipper.StepProbe,
+	clusterName string,
+	kubeClient kubernetes.Interface,
+	cond conditions.StrategyConditionsMap,
+	patches []StrategyPatch,
+) ([]StrategyPatch, []shipper.ProbeResult, error) {
+	if len(probes) == 0 || !raisesContenderTraffic(relinfo, patches) {
+		return patches, nil, nil
+	}
+
+	results, err := runProbes(relinfo.release, step, probes, clusterName, kubeClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var failed *shipper.ProbeResult
+	for i := range results {
+		if !results[i].Passed {
+			failed = &results[i]
+			break
+		}
+	}
+
+	if failed == nil {
+		return patches, results, nil
+	}
+
+	msg := fmt.Sprintf("probe %q failed", failed.Name)
+	if failed.Pod != "" {
+		msg = fmt.Sprintf("%s on pod %q", msg, failed.Pod)
+	}
+	msg = fmt.Sprintf("%s: %s", msg, failed.Message)
+
+	cond.SetFalse(
+		shipper.StrategyConditionContenderAchievedTraffic,
+		conditions.StrategyConditionsUpdate{
+			Reason:             ProbesFailed,
+			Message:            msg,
+			Step:               step,
+			LastTransitionTime: time.Now(),
+		},
+	)
+
+	gated := make([]StrategyPatch, 0, len(patches))
+	for _, patch := range patches {
+		if _, ok := patch.(*TrafficTargetSpecPatch); !ok {
+			gated = append(gated, patch)
+		}
+	}
+
+	return gated, results, nil
+}
+
+func raisesContenderTraffic(relinfo *releaseInfo, patches []StrategyPatch) bool {
+	for _, patch := range patches {
+		ttPatch, ok := patch.(*TrafficTargetSpecPatch)
+		if !ok || ttPatch.IsEmpty() || ttPatch.Name != relinfo.release.Name {
+			continue
+		}
+
+		if relinfo.trafficTarget == nil || ttPatch.NewSpec.Weight > relinfo.trafficTarget.Spec.Weight {
+			return true
+		}
+	}
+
+	return false
+}
+
+func runProbes(
+	rel *shipper.Release,
+	step int32,
+	probes []shipper.StepProbe,
+	clusterName string,
+	kubeClient kubernetes.Interface,
+) ([]shipper.ProbeResult, error) {
+	selector := labels.Set{shipper.ReleaseLabel: rel.Name}.AsSelector()
+	podList, err := kubeClient.CoreV1().Pods(rel.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, shippererrors.NewKubeclientListError(
+			corev1.SchemeGroupVersion.WithKind("Pod"),
+			rel.Namespace, selector, err)
+	}
+
+	var pods []*corev1.Pod
+	for i := range podList.Items {
+		if len(pods) == maxProbedPods {
+			break
+		}
+
+		if isPodReady(&podList.Items[i]) {
+			pods = append(pods, &podList.Items[i])
+		}
+	}
+
+	var results []shipper.ProbeResult
+	for _, probe := range probes {
+		if len(pods) == 0 {
+			results = append(results, shipper.ProbeResult{
+				Cluster: clusterName,
+				Step:    step,
+				Name:    probe.Name,
+				Message: "no ready pods to probe",
+			})
+			continue
+		}
+
+		for _, pod := range pods {
+			results = append(results, probeResult(kubeClient, pod, probe, step, clusterName))
+		}
+	}
+
+	return results, nil
+}
+
+func probeResult(
+	kubeClient kubernetes.Interface,
+	pod *corev1.Pod,
+	probe shipper.StepProbe,
+	step int32,
+	clusterName string,
+) shipper.ProbeResult {
+	result := shipper.ProbeResult{
+		Cluster: clusterName,
+		Step:    step,
+		Name:    probe.Name,
+		Pod:     pod.Name,
+	}
+
+	expectedStatus := int(probe.ExpectedStatus)
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+
+	start := time.Now()
+	statusCode, err := probePod(kubeClient, pod, probe)
+	latency := time.Since(start)
+
+	result.StatusCode = int32(statusCode)
+	result.Latency = latency.Round(time.Millisecond).String()
+
+	switch {
+	case err != nil:
+		result.Message = err.Error()
+	case statusCode != expectedStatus:
+		result.Message = fmt.Sprintf("expected status %d, got %d", expectedStatus, statusCode)
+	case probe.LatencySLO != nil && latency > probe.LatencySLO.Duration:
+		result.Message = fmt.Sprintf("took longer than %s", probe.LatencySLO.Duration)
+	default:
+		result.Passed = true
+	}
+
+	return result
+}
+
+// mergeProbeResults replaces the results for the clusters that were just
+// probed, keeping the latest results of the others.
+func mergeProbeResults(
+	current []shipper.ProbeResult,
+	latest map[string][]shipper.ProbeResult,
+) []shipper.ProbeResult {
+	if len(latest) == 0 {
+		return current
+	}
+
+	var merged []shipper.ProbeResult
+	for _, result := range current {
+		if _, ok := latest[result.Cluster]; !ok {
+			merged = append(merged, result)
+		}
+	}
+
+	for _, results := range latest {
+		merged = append(merged, results...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Cluster < merged[j].Cluster
+	})
+
+	return merged
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+func TestGateTrafficOnProbes(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"probes",
+		1,
+	)
+	it, tt, ct := buildAssociatedObjects(rel, nil)
+	relinfo := &releaseInfo{
+		release:            rel,
+		installationTarget: it,
+		trafficTarget:      tt,
+		capacityTarget:     ct,
+	}
+
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "probes-pod",
+			Namespace: rel.Namespace,
+			Labels:    map[string]string{shipper.ReleaseLabel: rel.Name},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+
+	probes := []shipper.StepProbe{{Name: "health", Path: "/health", Port: 8080}}
+
+	tests := []struct {
+		name       string
+		pods       []runtime.Object
+		statusCode int
+		passed     bool
+		message    string
+	}{
+		{"passing probe", []runtime.Object{readyPod}, 200, true, ""},
+		{"unexpected status", []runtime.Object{readyPod}, 503, false, "expected status 200, got 503"},
+		{"no ready pods", nil, 200, false, "no ready pods to probe"},
+	}
+
+	defer func(orig func(kubernetes.Interface, *corev1.Pod, shipper.StepProbe) (int, error)) {
+		probePod = orig
+	}(probePod)
+
+	for _, test := range tests {
+		probePod = func(kubernetes.Interface, *corev1.Pod, shipper.StepProbe) (int, error) {
+			return test.statusCode, nil
+		}
+
+		patches := []StrategyPatch{
+			&CapacityTargetSpecPatch{Name: rel.Name, NewSpec: &shipper.CapacityTargetSpec{Percent: 50}},
+			&TrafficTargetSpecPatch{Name: rel.Name, NewSpec: &shipper.TrafficTargetSpec{Weight: 50}},
+		}
+
+		cond := conditions.NewStrategyConditions()
+		gated, results, err := gateTrafficOnProbes(
+			relinfo, 1, probes, "cluster-a", kubefake.NewSimpleClientset(test.pods...), cond, patches)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.name, err)
+		}
+
+		if len(results) != 1 || results[0].Passed != test.passed || results[0].Message != test.message {
+			t.Errorf("%s: unexpected probe results %+v", test.name, results)
+		}
+
+		expectedPatches := 2
+		if !test.passed {
+			expectedPatches = 1
+		}
+		if len(gated) != expectedPatches {
+			t.Errorf("%s: expected %d patches, got %d", test.name, expectedPatches, len(gated))
+		}
+
+		_, ok := cond.GetCondition(shipper.StrategyConditionContenderAchievedTraffic)
+		if ok == test.passed {
+			t.Errorf("%s: expected traffic condition to be set only when probes fail", test.name)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package release
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -12,8 +13,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -26,13 +27,20 @@ import (
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/clusterclientstore"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/policy"
+	"github.com/bookingcom/shipper/pkg/registry"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	"github.com/bookingcom/shipper/pkg/util/conditions"
 	"github.com/bookingcom/shipper/pkg/util/diff"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 	rolloutblock "github.com/bookingcom/shipper/pkg/util/rolloutblock"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
@@ -40,8 +48,11 @@ const (
 	AgentName = "release-controller"
 
 	ClustersChosen          = "ClustersChosen"
+	ClusterUnavailable      = "ClusterUnavailable"
 	InternalError           = "InternalError"
 	StrategyExecutionFailed = "StrategyExecutionFailed"
+	ExperimentRunning       = "ExperimentRunning"
+	WaitingForApproval      = "WaitingForApproval"
 )
 
 // Controller is a Kubernetes controller whose role is to pick up a newly created
@@ -61,6 +72,15 @@ type Controller struct {
 	rolloutBlockLister shipperlisters.RolloutBlockLister
 	rolloutBlockSynced cache.InformerSynced
 
+	capacityOverrideLister shipperlisters.FleetCapacityOverrideLister
+	capacityOverrideSynced cache.InformerSynced
+
+	rolloutQuotaLister shipperlisters.RolloutQuotaLister
+	rolloutQuotaSynced cache.InformerSynced
+
+	policyLister shipperlisters.PolicyLister
+	policySynced cache.InformerSynced
+
 	workqueue workqueue.RateLimitingInterface
 
 	chartFetcher shipperrepo.ChartFetcher
@@ -70,6 +90,26 @@ type Controller struct {
 	trafficTargetLister      shipperlisters.TrafficTargetLister      // Deprecated
 	capacityTargetLister     shipperlisters.CapacityTargetLister     // Deprecated
 	installationTargetLister shipperlisters.InstallationTargetLister // Deprecated
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+
+	// imageInspector is optional. When set, the images of releases asking for
+	// platforms in their cluster requirements are looked up in their registries
+	// before any clusters are chosen, and releases with images missing any of
+	// those platforms don't get any.
+	imageInspector registry.PlatformInspector
+
+	// policyEvaluator is what Policies are evaluated with. Without it,
+	// releases any Policy applies to are held back, as there's nothing to tell
+	// whether they violate it.
+	policyEvaluator policy.Evaluator
+
+	// completionNotifier is optional. When set, it's told about every
+	// release that completes its rollout, for up to completionTimeout.
+	completionNotifier CompletionNotifier
+	completionTimeout  time.Duration
 }
 
 type releaseInfo struct {
@@ -85,17 +125,35 @@ type listers struct {
 	trafficTargetLister      shipperlisters.TrafficTargetLister
 }
 
+// Options are how a Release controller rolls releases out, on top of the
+// clients and informers it works with. They're described on the fields of
+// Controller they end up in.
+type Options struct {
+	DrainTimeout time.Duration
+	// LowPriorityMaxWait is how long releases that aren't rolling out
+	// wait for in-flight ones to be synced at most.
+	LowPriorityMaxWait time.Duration
+	ImageInspector     registry.PlatformInspector
+	PolicyEvaluator    policy.Evaluator
+	CompletionNotifier CompletionNotifier
+	CompletionTimeout  time.Duration
+}
+
 func NewController(
 	clientset shipperclient.Interface,
 	store clusterclientstore.Interface,
 	informerFactory shipperinformers.SharedInformerFactory,
 	chartFetcher shipperrepo.ChartFetcher,
 	recorder record.EventRecorder,
+	opts Options,
 ) *Controller {
 
 	releaseInformer := informerFactory.Shipper().V1alpha1().Releases()
 	clusterInformer := informerFactory.Shipper().V1alpha1().Clusters()
 	rolloutBlockInformer := informerFactory.Shipper().V1alpha1().RolloutBlocks()
+	capacityOverrideInformer := informerFactory.Shipper().V1alpha1().FleetCapacityOverrides()
+	rolloutQuotaInformer := informerFactory.Shipper().V1alpha1().RolloutQuotas()
+	policyInformer := informerFactory.Shipper().V1alpha1().Policies()
 
 	// Deprecated
 	trafficTargetInformer := informerFactory.Shipper().V1alpha1().TrafficTargets()
@@ -115,9 +173,22 @@ func NewController(
 		rolloutBlockLister: rolloutBlockInformer.Lister(),
 		rolloutBlockSynced: rolloutBlockInformer.Informer().HasSynced,
 
-		workqueue: workqueue.NewNamedRateLimitingQueue(
-			shipperworkqueue.NewDefaultControllerRateLimiter(),
-			"release_controller_releases",
+		capacityOverrideLister: capacityOverrideInformer.Lister(),
+		capacityOverrideSynced: capacityOverrideInformer.Informer().HasSynced,
+
+		rolloutQuotaLister: rolloutQuotaInformer.Lister(),
+		rolloutQuotaSynced: rolloutQuotaInformer.Informer().HasSynced,
+
+		policyLister: policyInformer.Lister(),
+		policySynced: policyInformer.Informer().HasSynced,
+
+		workqueue: shipperworkqueue.NewPriorityQueue(
+			shipperworkqueue.NewNamedRateLimitingQueue(
+				shipperworkqueue.NewDefaultControllerRateLimiter(),
+				"release_controller_releases",
+			),
+			releaseInFlight(releaseInformer.Lister()),
+			opts.LowPriorityMaxWait,
 		),
 
 		chartFetcher: chartFetcher,
@@ -128,6 +199,15 @@ func NewController(
 		trafficTargetLister:      trafficTargetInformer.Lister(),
 		capacityTargetLister:     capacityTargetInformer.Lister(),
 		installationTargetLister: installationTargetInformer.Lister(),
+
+		drainTimeout: opts.DrainTimeout,
+
+		imageInspector: opts.ImageInspector,
+
+		policyEvaluator: opts.PolicyEvaluator,
+
+		completionNotifier: opts.CompletionNotifier,
+		completionTimeout:  opts.CompletionTimeout,
 	}
 
 	releaseInformer.Informer().AddEventHandler(
@@ -135,13 +215,67 @@ func NewController(
 			AddFunc: controller.enqueueReleaseAndNeighbours,
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.enqueueReleaseAndNeighbours(newObj)
+				controller.enqueueDependentReleases(newObj)
+
+				// A rollout completing makes room for the ones
+				// waiting for a RolloutQuota, and one leaving the
+				// queue moves the rest up.
+				oldRel, oldOk := oldObj.(*shipper.Release)
+				newRel, newOk := newObj.(*shipper.Release)
+				if oldOk && newOk &&
+					((!releaseutil.ReleaseComplete(oldRel) && releaseutil.ReleaseComplete(newRel)) ||
+						(waitingForRolloutQuota(oldRel) && !waitingForRolloutQuota(newRel))) {
+					controller.enqueueReleasesWaitingForRolloutQuota(newRel.Namespace)
+				}
 			},
-			DeleteFunc: controller.enqueueReleaseAndNeighbours,
+			DeleteFunc: objectutil.OnDelete(func(obj interface{}) {
+				controller.enqueueReleaseAndNeighbours(obj)
+				if rel, ok := obj.(*shipper.Release); ok {
+					controller.enqueueReleasesWaitingForRolloutQuota(rel.Namespace)
+				}
+			}),
 		})
 
 	rolloutBlockInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			DeleteFunc: controller.enqueueReleaseFromRolloutBlock,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueReleaseFromRolloutBlock),
+		})
+
+	clusterInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldCluster, oldOk := oldObj.(*shipper.Cluster)
+				newCluster, newOk := newObj.(*shipper.Cluster)
+				if oldOk && newOk && oldCluster.Spec.TrafficDisabled != newCluster.Spec.TrafficDisabled {
+					controller.enqueueReleasesFromCluster(newCluster)
+				}
+			},
+		})
+
+	capacityOverrideInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueReleasesFromCapacityOverride,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				controller.enqueueReleasesFromCapacityOverride(newObj)
+			},
+			DeleteFunc: objectutil.OnDelete(controller.enqueueReleasesFromCapacityOverride),
+		})
+
+	rolloutQuotaInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				controller.enqueueReleasesFromRolloutQuota(newObj)
+			},
+			DeleteFunc: objectutil.OnDelete(controller.enqueueReleasesFromRolloutQuota),
+		})
+
+	policyInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: controller.enqueueReleasesFromPolicy,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				controller.enqueueReleasesFromPolicy(newObj)
+			},
+			DeleteFunc: objectutil.OnDelete(controller.enqueueReleasesFromPolicy),
 		})
 
 	eventHandler := cache.ResourceEventHandlerFuncs{
@@ -149,7 +283,7 @@ func NewController(
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			controller.enqueueReleaseFromAssociatedObject(newObj)
 		},
-		DeleteFunc: controller.enqueueReleaseFromAssociatedObject,
+		DeleteFunc: objectutil.OnDelete(controller.enqueueReleaseFromAssociatedObject),
 	}
 
 	store.AddSubscriptionCallback(func(kubeInformerFactory kubeinformers.SharedInformerFactory, shipperInformerFactory shipperinformers.SharedInformerFactory) {
@@ -182,23 +316,22 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 		c.releasesSynced,
 		c.clustersSynced,
 		c.rolloutBlockSynced,
+		c.capacityOverrideSynced,
+		c.rolloutQuotaSynced,
+		c.policySynced,
 	); !ok {
 		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Release controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(4).Info("Started Release controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 // processNextWorkItem pops an element from the head of the workqueue and
@@ -224,11 +357,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
-	err := c.syncHandler(key)
+	span := tracing.StartSync(AgentName, "Release", key)
+	err := c.syncHandler(span, key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Release", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing Release %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -245,7 +382,7 @@ func (c *Controller) processNextWorkItem() bool {
 // syncHandler processes release keys one-by-one. This stage assigns a set of
 // chosen clusters, creates required associated objects and executes the
 // strategy.
-func (c *Controller) syncHandler(key string) error {
+func (c *Controller) syncHandler(span *tracing.Span, key string) error {
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		return shippererrors.NewUnrecoverableError(err)
@@ -266,11 +403,13 @@ func (c *Controller) syncHandler(key string) error {
 		return nil
 	}
 
-	rel, err := c.processRelease(initialRel.DeepCopy())
+	rel, err := c.processRelease(span, initialRel.DeepCopy())
 
 	if !equality.Semantic.DeepEqual(initialRel, rel) {
+		updateSpan := span.StartChild("Update Release", tracing.String("shipper.key", key))
 		_, err := c.clientset.ShipperV1alpha1().Releases(namespace).
 			Update(rel)
+		updateSpan.End(err)
 		if err != nil {
 			return shippererrors.NewKubeclientUpdateError(rel, err).
 				WithShipperKind("Release")
@@ -280,14 +419,29 @@ func (c *Controller) syncHandler(key string) error {
 	return err
 }
 
-func (c *Controller) processRelease(rel *shipper.Release) (*shipper.Release, error) {
+func (c *Controller) processRelease(span *tracing.Span, rel *shipper.Release) (*shipper.Release, error) {
 	diff := diffutil.NewMultiDiff()
 	defer func() {
 		if !diff.IsEmpty() {
-			c.recorder.Event(rel, corev1.EventTypeNormal, "ReleaseConditionChanged", diff.String())
+			c.recorder.Event(rel, corev1.EventTypeNormal, shipperevents.ReleaseConditionChanged, diff.String())
 		}
 	}()
 
+	// Releases created by hand may name a strategy preset instead of
+	// listing steps. Filling them in here keeps the rest of the
+	// controller working on steps alone.
+	if err := releaseutil.ResolveStrategyPreset(rel.Spec.Environment.Strategy); err != nil {
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeStrategyExecuted,
+			corev1.ConditionFalse,
+			shippererrors.Reason(err),
+			err.Error(),
+		)
+		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+
+		return rel, err
+	}
+
 	rolloutBlocked, events, err := rolloutblock.BlocksRollout(c.rolloutBlockLister, rel)
 	for _, ev := range events {
 		c.recorder.Event(rel, ev.Type, ev.Reason, ev.Message)
@@ -307,6 +461,10 @@ func (c *Controller) processRelease(rel *shipper.Release) (*shipper.Release, err
 		)
 		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
 
+		if rel.Status.AchievedStep == nil {
+			diff.Append(setQueuedCondition(rel, shipper.RolloutBlockReason, msg))
+		}
+
 		return rel, err
 	}
 
@@ -320,7 +478,7 @@ func (c *Controller) processRelease(rel *shipper.Release) (*shipper.Release, err
 
 	rel, clusterNames, err := c.chooseClusters(rel)
 	if err != nil {
-		reason := reasonForReleaseCondition(err)
+		reason := shippererrors.Reason(err)
 		condition := releaseutil.NewReleaseCondition(
 			shipper.ReleaseConditionTypeClustersChosen,
 			corev1.ConditionFalse,
@@ -353,12 +511,39 @@ func (c *Controller) processRelease(rel *shipper.Release) (*shipper.Release, err
 		return rel, err
 	}
 
-	rel, err = c.executeStrategyOnClusters(rel, clusterNames, diff)
+	rel, err = c.executeStrategyOnClusters(span, rel, clusterNames, diff)
+	switch err := err.(type) {
+	case shippererrors.RolloutQuotaExceededError:
+		diff.Append(setQueuedCondition(rel, err.Reason(), err.Error()))
+	case shippererrors.RolloutQuotaUnsatisfiableError:
+		// Releases that can't ever fit don't hold up the ones behind them.
+		diff.Append(setQueuedCondition(rel, "", ""))
+	}
 	if err != nil {
+		reason := StrategyExecutionFailed
+		switch err.(type) {
+		case shippererrors.StepNotApprovedError:
+			reason = WaitingForApproval
+		case shippererrors.StepHookPendingError, shippererrors.StepHookFailedError,
+			shippererrors.DependencyNotCompleteError, shippererrors.PolicyEvaluationError,
+			shippererrors.PullClusterStepUnsupportedError, shippererrors.CutoverError:
+			reason = shippererrors.Reason(err)
+		case shippererrors.PolicyViolationError, shippererrors.RolloutQuotaExceededError,
+			shippererrors.RolloutQuotaUnsatisfiableError:
+			reason = shippererrors.Reason(err)
+			blockedCond := releaseutil.NewReleaseCondition(
+				shipper.ReleaseConditionTypeBlocked,
+				corev1.ConditionTrue,
+				reason,
+				err.Error(),
+			)
+			diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *blockedCond))
+		}
+
 		releaseStrategyExecutedCond := releaseutil.NewReleaseCondition(
 			shipper.ReleaseConditionTypeStrategyExecuted,
 			corev1.ConditionFalse,
-			StrategyExecutionFailed,
+			reason,
 			err.Error(),
 		)
 		diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *releaseStrategyExecutedCond))
@@ -382,6 +567,7 @@ func (c *Controller) processRelease(rel *shipper.Release) (*shipper.Release, err
 // clusters, as the old "scheduling" will become either choosing clusters or
 // executing strategy, with nothing in between.
 func (c *Controller) executeStrategyOnClusters(
+	span *tracing.Span,
 	rel *shipper.Release,
 	clusters []string,
 	diff *diff.MultiDiff,
@@ -420,18 +606,156 @@ func (c *Controller) executeStrategyOnClusters(
 		targetStep = succ.Spec.TargetStep
 	}
 
+	// Steps requiring approval are held back until someone approves them.
+	// Until then, target objects are left as they were for the previous
+	// step.
+	if isHead && !releaseutil.StepApproved(rel, strategy, targetStep) {
+		return rel, shippererrors.NewStepNotApprovedError(
+			objectutil.MetaKey(rel),
+			targetStep,
+			strategy.Steps[targetStep].Approvers,
+		)
+	}
+
+	// Releases depending on other applications don't move past the
+	// step they've achieved until those are done rolling out.
+	advancing := rel.Status.AchievedStep == nil || rel.Status.AchievedStep.Step < targetStep
+	if isHead && targetStep > 0 && advancing {
+		if err := c.checkDependencies(rel); err != nil {
+			return rel, err
+		}
+	}
+
+	// Policies are evaluated for as long as the release hasn't been
+	// installed, and before it moves to each step after that.
+	if isHead && rel.Status.AchievedStep == nil {
+		if err := c.checkPolicies(rel, clusters, shipper.PolicyStageInstallation); err != nil {
+			return rel, err
+		}
+	}
+	if isHead && targetStep > 0 && advancing {
+		if err := c.checkPolicies(rel, clusters, shipper.PolicyStageStepAdvancement); err != nil {
+			return rel, err
+		}
+	}
+
+	// Releases aren't installed either until the rollout quotas of their
+	// namespace have room for them. Once they do, they're never held back
+	// again.
+	if isHead && rel.Status.AchievedStep == nil && !rolloutAdmitted(rel) {
+		if err := c.checkRolloutQuotas(rel, clusters); err != nil {
+			return rel, err
+		}
+	}
+
+	// Past this point, the head isn't waiting to start rolling out
+	// anymore. Errors before it leave it in its place in the queue.
+	if isHead {
+		diff.Append(setQueuedCondition(rel, "", ""))
+	}
+
 	executor, err := NewStrategyExecutor(strategy, targetStep)
 	if err != nil {
 		return rel, err
 	}
 
+	// Only the release right before the head is kept on standby.
+	if isHead {
+		executor.onStandby = onStandby(strategy, prev)
+	} else if strategy.IncumbentStandby != nil {
+		_, succSucc, err := c.getSiblingReleases(succ)
+		if err != nil {
+			return rel, err
+		}
+		executor.onStandby = succSucc == nil && onStandby(strategy, rel)
+	}
+	executor.instantRollback = isHead && instantRollingBack(rel, prev)
+
+	override, err := c.capacityOverrideFor(rel)
+	if err != nil {
+		return rel, err
+	}
+	diff.Append(setCapacityOverrideCondition(rel, override))
+	if override != nil {
+		executor.capacityOverride = &override.Spec.Percent
+	}
+
+	// Hooks only run while the head release is moving to a step. Once
+	// it's achieved, their Jobs might be long gone and shouldn't be
+	// created again.
+	step := strategy.Steps[targetStep]
+	stepAchieved := rel.Status.AchievedStep != nil && rel.Status.AchievedStep.Step == targetStep
+	runHooks := isHead && !stepAchieved
+	var preHooks []shipper.StepHook
+	if runHooks {
+		preHooks = step.PreHooks
+	}
+
 	clusterConditions := make(map[string]conditions.StrategyConditionsMap)
+	clusterReleaseInfos := make(map[string]*releaseInfo)
+	clusterKubeClients := make(map[string]kubernetes.Interface)
+	clusterShipperClients := make(map[string]shipperclientset.Interface)
+	probeResults := make(map[string][]shipper.ProbeResult)
+
+	// Clusters Shipper can't execute the strategy on are tolerated as
+	// long as there are no more of them than the strategy allows. They
+	// are reported as not having achieved installation, and left behind
+	// until they recover.
+	maxUnavailable := maxUnavailableClusters(strategy, len(clusters))
+	failedClusters := 0
+	tolerateClusterError := func(clusterName string, err error) bool {
+		if failedClusters >= maxUnavailable {
+			return false
+		}
+
+		failedClusters++
+		clusterConditions[clusterName] = unavailableClusterConditions(targetStep, err)
+		return true
+	}
+
+	// Sequential rollouts only move a cluster through the step once the
+	// ones before it have achieved it, leaving the rest as they were.
+	sequential := isHead && isSequentialRollout(rel)
+	if sequential {
+		clusters, err = c.orderClustersForRollout(rel, clusters)
+		if err != nil {
+			return rel, err
+		}
+	}
+	blockingCluster := ""
+
+	progress := 1.0
+	var progressSum float64
 	for _, clusterName := range clusters {
+		if blockingCluster != "" {
+			clusterConditions[clusterName] = waitingForClusterConditions(targetStep, blockingCluster)
+			continue
+		}
+
 		clusterClientsets, err := c.store.GetApplicationClusterClientset(clusterName, AgentName)
 		if err != nil {
+			c.recorder.Eventf(rel, corev1.EventTypeWarning, shipperevents.ClusterNotReady,
+				"cluster %q: %s", clusterName, err)
+			if tolerateClusterError(clusterName, err) {
+				continue
+			}
 			return rel, err
 		}
 
+		cluster, err := c.clusterLister.Get(clusterName)
+		if err != nil {
+			return rel, shippererrors.NewKubeclientGetError("", clusterName, err).
+				WithShipperKind("Cluster")
+		}
+
+		// Clusters in pull mode are only reachable through the targets
+		// their agent syncs, so there's no running hooks or probes in
+		// them.
+		if cluster.Spec.PullMode && runHooks && stepRunsInCluster(step) {
+			return rel, shippererrors.NewPullClusterStepUnsupportedError(
+				objectutil.MetaKey(rel), step.Name, clusterName)
+		}
+
 		informerFactory := clusterClientsets.GetShipperInformerFactory()
 		shipperv1alpha1 := informerFactory.Shipper().V1alpha1()
 		listers := listers{
@@ -440,22 +764,117 @@ func (c *Controller) executeStrategyOnClusters(
 			trafficTargetLister:      shipperv1alpha1.TrafficTargets().Lister(),
 		}
 
-		clusterConditions[clusterName], err = c.executeReleaseStrategyForCluster(
+		executor.maxTotalCapacity = cluster.Spec.MaxTotalCapacityPercent
+
+		var relinfo *releaseInfo
+		var clusterProbeResults []shipper.ProbeResult
+		clusterSpan := span.StartChild("Execute strategy on cluster", tracing.String("shipper.cluster", clusterName))
+		clusterConditions[clusterName], relinfo, clusterProbeResults, err = c.executeReleaseStrategyForCluster(
+			clusterSpan,
 			rel.DeepCopy(),
 			prev, succ,
 			clusterClientsets.GetShipperClient(),
+			clusterClientsets.GetKubeClient(),
 			executor,
-			listers)
+			preHooks,
+			listers,
+			clusterName,
+			getClusterTrafficBackend(cluster),
+			cluster.Spec.TrafficDisabled)
+		clusterSpan.End(err)
 		if err != nil {
+			if tolerateClusterError(clusterName, err) {
+				continue
+			}
 			return rel, err
 		}
+
+		clusterReleaseInfos[clusterName] = relinfo
+		if clusterProbeResults != nil {
+			probeResults[clusterName] = clusterProbeResults
+		}
+		clusterKubeClients[clusterName] = clusterClientsets.GetKubeClient()
+		clusterShipperClients[clusterName] = clusterClientsets.GetShipperClient()
+
+		// A rollout is only as far along as its slowest cluster.
+		clusterProgress := clusterStepProgress(relinfo, strategy.Steps[targetStep])
+		progress = math.Min(progress, clusterProgress)
+		progressSum += clusterProgress
+
+		if sequential && !newClusterStepState(isHead, clusterConditions[clusterName]).achieved() {
+			blockingCluster = clusterName
+		}
+	}
+
+	// Unless clusters roll out one after the other, in which case the
+	// ones still waiting their turn haven't made any progress.
+	if sequential && len(clusters) > 0 {
+		progress = progressSum / float64(len(clusters))
 	}
 
 	isLastStep := int(targetStep) == len(strategy.Steps)-1
 	stepComplete, strategyStatus := consolidateStrategyStatus(
-		isHead, isLastStep, clusterConditions)
+		isHead, isLastStep, maxUnavailable, clusterConditions)
+
+	var stepHistory []shipper.ReleaseStepHistory
+	if rel.Status.Strategy != nil {
+		stepHistory = rel.Status.Strategy.StepHistory
+	}
+	if isHead {
+		now := time.Now()
+		if releaseutil.ConditionsShouldDiscardTimestamps {
+			now = time.Time{}
+		}
+		stepHistory = recordStepHistory(stepHistory, targetStep, step.Name, stepComplete, now)
+	}
+	strategyStatus.StepHistory = stepHistory
+	recordAchievedTraffic(strategyStatus, rel.Status.Strategy, clusterReleaseInfos)
 
 	rel.Status.Strategy = strategyStatus
+	rel.Status.Probes = mergeProbeResults(rel.Status.Probes, probeResults)
+
+	if floor := strategy.IncumbentFloor; isHead && floor != nil {
+		updateFullTrafficSince(rel, step, clusterConditions)
+
+		// An incumbent held up by its floor needs to be let go as soon
+		// as the contender is done soaking, without waiting for a
+		// resync.
+		if prev != nil && !stepComplete {
+			if remaining, ok := remainingSoak(floor, rel, time.Now()); ok && remaining > 0 {
+				c.workqueue.AddAfter(objectutil.MetaKey(rel), remaining)
+			}
+		}
+	}
+
+	if stepComplete && runHooks && len(step.PostHooks) > 0 {
+		unavailable := make(map[string]bool)
+		for _, clusterName := range strategyStatus.UnavailableClusters {
+			unavailable[clusterName] = true
+		}
+
+		for _, clusterName := range clusters {
+			if unavailable[clusterName] {
+				continue
+			}
+
+			err := c.runStepHooks(
+				clusterReleaseInfos[clusterName],
+				targetStep,
+				hookPhasePost,
+				step.PostHooks,
+				clusterName,
+				clusterKubeClients[clusterName])
+			if err != nil {
+				return rel, err
+			}
+		}
+	}
+
+	if isHead && !stepComplete {
+		rel.Status.ETA = estimateETA(rel, targetStep, len(strategy.Steps), progress, time.Now())
+	} else {
+		rel.Status.ETA = nil
+	}
 
 	if stepComplete {
 		prevStep := rel.Status.AchievedStep
@@ -478,13 +897,43 @@ func (c *Controller) executeStrategyOnClusters(
 			c.recorder.Eventf(
 				rel,
 				corev1.EventTypeNormal,
-				"StrategyApplied",
+				shipperevents.StepAchieved,
 				"step [%d] finished",
 				achievedStep,
 			)
 		}
 
-		if isLastStep {
+		if executor.instantRollback {
+			delete(rel.Annotations, shipper.InstantRollbackAnnotation)
+			c.recorder.Eventf(
+				rel,
+				corev1.EventTypeNormal,
+				shipperevents.InstantRollbackCompleted,
+				"rolled back to %q",
+				prev.Name,
+			)
+		}
+
+		if isLastStep && isHead && strategy.Experiment != nil {
+			// Experiments keep their traffic split until the next
+			// release, and never complete.
+			condition := releaseutil.NewReleaseCondition(
+				shipper.ReleaseConditionTypeComplete,
+				corev1.ConditionFalse,
+				ExperimentRunning,
+				"the release is a long-running experiment, roll out another release to end it",
+			)
+			diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+		} else if isLastStep {
+			// Cutting over is part of the last step, so the
+			// release only completes once it's done.
+			if cutover := strategy.Cutover; isHead && cutover != nil && !releaseutil.ReleaseComplete(rel) {
+				if err := c.cutOver(rel, cutover, clusterKubeClients, clusterShipperClients); err != nil {
+					c.recorder.Event(rel, corev1.EventTypeWarning, shipperevents.CutoverFailed, err.Error())
+					return rel, err
+				}
+			}
+
 			condition := releaseutil.NewReleaseCondition(
 				shipper.ReleaseConditionTypeComplete,
 				corev1.ConditionTrue,
@@ -492,6 +941,15 @@ func (c *Controller) executeStrategyOnClusters(
 				"",
 			)
 			diff.Append(releaseutil.SetReleaseCondition(&rel.Status, *condition))
+
+			// Failing to report completion doesn't make the
+			// release any less complete, so it's only retried.
+			if isHead {
+				if err := c.finishRelease(rel, clusterKubeClients); err != nil {
+					c.recorder.Event(rel, corev1.EventTypeWarning, shipperevents.ReleaseCompletionFailed, err.Error())
+					c.workqueue.AddRateLimited(objectutil.MetaKey(rel))
+				}
+			}
 		}
 	}
 
@@ -499,12 +957,18 @@ func (c *Controller) executeStrategyOnClusters(
 }
 
 func (c *Controller) executeReleaseStrategyForCluster(
+	span *tracing.Span,
 	rel *shipper.Release,
 	prev, succ *shipper.Release,
 	appClusterClientset shipperclientset.Interface,
+	appClusterKubeClient kubernetes.Interface,
 	executor *StrategyExecutor,
+	preHooks []shipper.StepHook,
 	listers listers,
-) (conditions.StrategyConditionsMap, error) {
+	clusterName string,
+	trafficBackend string,
+	trafficDisabled bool,
+) (conditions.StrategyConditionsMap, *releaseInfo, []shipper.ProbeResult, error) {
 	var err error
 	var relinfoPrev, relinfoSucc *releaseInfo
 
@@ -513,34 +977,59 @@ func (c *Controller) executeReleaseStrategyForCluster(
 		listers,
 		c.chartFetcher,
 		c.recorder,
+		trafficBackend,
+		trafficDisabled,
+		clusterName,
 	)
 
-	relinfo, err := scheduler.ScheduleRelease(rel)
+	relinfo, err := scheduler.ScheduleRelease(span, rel)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+
+	// Pre-step hooks run once the release is installed, so they can use
+	// its config, but before it gets any capacity or traffic.
+	if len(preHooks) > 0 {
+		err := c.runStepHooks(relinfo, executor.step, hookPhasePre, preHooks, clusterName, appClusterKubeClient)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	if prev != nil {
 		relinfoPrev, err = c.buildReleaseInfo(prev, listers)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	if succ != nil {
 		relinfoSucc, err = c.buildReleaseInfo(succ, listers)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	conditions, patches := executor.Execute(relinfoPrev, relinfo, relinfoSucc)
 
+	var probeResults []shipper.ProbeResult
+	if succ == nil {
+		probes := executor.strategy.Steps[executor.step].Probes
+		patches, probeResults, err = gateTrafficOnProbes(
+			relinfo, executor.step, probes, clusterName, appClusterKubeClient, conditions, patches)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	for _, patch := range patches {
 		namespace := relinfo.release.Namespace
 		name, gvk, b := patch.PatchSpec()
 		shipperv1alpha1 := appClusterClientset.ShipperV1alpha1()
 
+		patchSpan := span.StartChild("Patch "+gvk.Kind,
+			tracing.String("shipper.key", namespace+"/"+name))
+
 		var err error
 		switch gvk.Kind {
 		case "CapacityTarget":
@@ -551,17 +1040,19 @@ func (c *Controller) executeReleaseStrategyForCluster(
 			err = fmt.Errorf(
 				"invalid strategy patch. shipper doesn't know how to patch GVK %s",
 				gvk.Kind)
-			return nil, shippererrors.NewUnrecoverableError(err)
+			patchSpan.End(err)
+			return nil, nil, nil, shippererrors.NewUnrecoverableError(err)
 		}
 
+		patchSpan.End(err)
 		if err != nil {
-			return nil, shippererrors.
+			return nil, nil, nil, shippererrors.
 				NewKubeclientPatchError(namespace, name, err).
 				WithKind(gvk)
 		}
 	}
 
-	return conditions, nil
+	return conditions, relinfo, probeResults, nil
 }
 
 func (c *Controller) chooseClusters(rel *shipper.Release) (*shipper.Release, []string, error) {
@@ -570,6 +1061,10 @@ func (c *Controller) chooseClusters(rel *shipper.Release) (*shipper.Release, []s
 		return rel, releaseClusters, nil
 	}
 
+	if err := c.checkImagePlatforms(rel); err != nil {
+		return rel, nil, err
+	}
+
 	selector := labels.Everything()
 	allClusters, err := c.clusterLister.List(selector)
 	if err != nil {
@@ -579,16 +1074,78 @@ func (c *Controller) chooseClusters(rel *shipper.Release) (*shipper.Release, []s
 
 	}
 
+	allClusters, err = filterClustersByAffinity(c.releaseLister, rel, allClusters)
+	if err != nil {
+		return rel, nil, err
+	}
+
+	allClusters, err = c.filterClustersByAPIVersions(rel, allClusters)
+	if err != nil {
+		return rel, nil, err
+	}
+
 	selectedClusters, err := computeTargetClusters(rel, allClusters)
 	if err != nil {
 		return rel, nil, err
 	}
 
+	if spread := rel.Spec.Environment.ClusterRequirements.Spread; spread != nil {
+		selectedClusters, err = c.spreadReplicas(rel, spread, selectedClusters)
+		if err != nil {
+			return rel, nil, err
+		}
+	}
+
 	setReleaseClusters(rel, selectedClusters)
 
+	if rel.Spec.Environment.ValuesOverlays != nil {
+		setReleaseClusterValues(rel)
+	}
+
+	if mode := rel.Spec.Environment.ClusterRequirements.Scheduler; mode == shipper.ClusterSchedulerModeCostAware {
+		rel.Status.Scheduling = &shipper.ReleaseScheduling{
+			Mode:      mode,
+			Rationale: describeCostAwareChoice(selectedClusters),
+		}
+	}
+
 	return rel, releaseutil.GetSelectedClusters(rel), nil
 }
 
+// spreadReplicas records how the chart's replica count is split between
+// the selected clusters, and returns the clusters that got any replicas.
+func (c *Controller) spreadReplicas(
+	rel *shipper.Release,
+	spread *shipper.ClusterSpread,
+	clusters []*shipper.Cluster,
+) ([]*shipper.Cluster, error) {
+	app, err := objectutil.GetApplicationLabel(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spreads split the environment's replica count, before any cluster
+	// overlays.
+	values := releaseutil.EnvironmentValues(&rel.Spec.Environment)
+	replicaCount, err := fetchChartAndExtractReplicaCount(c.chartFetcher, rel, values)
+	if err != nil {
+		c.recorder.Event(rel, corev1.EventTypeWarning, shipperevents.ChartFetchFailed, err.Error())
+		return nil, err
+	}
+
+	replicas := spreadReplicas(app, spread, clusters, replicaCount)
+	setReleaseClusterReplicas(rel, replicas)
+
+	spreadClusters := make([]*shipper.Cluster, 0, len(replicas))
+	for _, cluster := range clusters {
+		if _, ok := replicas[cluster.Name]; ok {
+			spreadClusters = append(spreadClusters, cluster)
+		}
+	}
+
+	return spreadClusters, nil
+}
+
 // buildReleaseInfo returns a release and it's associated objects fetched from
 // the lister interface. If some of them could not be found, it returns a
 // corresponding error.
@@ -611,10 +1168,14 @@ func (c *Controller) buildReleaseInfo(
 			WithShipperKind("CapacityTarget")
 	}
 
-	trafficTarget, err := listers.trafficTargetLister.TrafficTargets(ns).Get(name)
-	if err != nil {
-		return nil, shippererrors.NewKubeclientGetError(ns, name, err).
-			WithShipperKind("TrafficTarget")
+	// Releases that run a Job have no traffic target.
+	var trafficTarget *shipper.TrafficTarget
+	if !releaseutil.RunsToCompletion(rel) {
+		trafficTarget, err = listers.trafficTargetLister.TrafficTargets(ns).Get(name)
+		if err != nil {
+			return nil, shippererrors.NewKubeclientGetError(ns, name, err).
+				WithShipperKind("TrafficTarget")
+		}
 	}
 
 	return &releaseInfo{
@@ -702,6 +1263,25 @@ func (c *Controller) enqueueReleaseFromRolloutBlock(obj interface{}) {
 	}
 }
 
+// enqueueReleasesFromCluster enqueues every release scheduled on cluster, so
+// taking it in and out of traffic applies to all of them right away.
+func (c *Controller) enqueueReleasesFromCluster(cluster *shipper.Cluster) {
+	releases, err := c.releaseLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		for _, clusterName := range releaseutil.GetSelectedClusters(rel) {
+			if clusterName == cluster.Name {
+				c.enqueueRelease(rel)
+				break
+			}
+		}
+	}
+}
+
 func (c *Controller) enqueueReleaseFromAssociatedObject(obj interface{}) {
 	kubeobj, ok := obj.(metav1.Object)
 	if !ok {
@@ -725,6 +1305,59 @@ func (c *Controller) enqueueReleaseFromAssociatedObject(obj interface{}) {
 	c.enqueueReleaseAndNeighbours(rel)
 }
 
+// checkDependencies returns an error for the first application rel depends
+// on whose latest release is not complete.
+func (c *Controller) checkDependencies(rel *shipper.Release) error {
+	relKey := objectutil.MetaKey(rel)
+	for _, dependency := range rel.Spec.Environment.DependsOn {
+		releases, err := c.releaseLister.Releases(rel.Namespace).ReleasesForApplication(dependency)
+		if err != nil {
+			return err
+		}
+
+		if len(releases) == 0 {
+			return shippererrors.NewDependencyNotCompleteError(relKey, dependency, "it has no releases")
+		}
+
+		latest := releaseutil.SortByGenerationDescending(releases)[0]
+		if !releaseutil.ReleaseComplete(latest) {
+			return shippererrors.NewDependencyNotCompleteError(relKey, dependency,
+				"release %q is not complete", latest.Name)
+		}
+	}
+
+	return nil
+}
+
+// enqueueDependentReleases enqueues the releases that depend on the
+// application of rel, so they can move on as soon as it completes.
+func (c *Controller) enqueueDependentReleases(obj interface{}) {
+	rel, ok := obj.(*shipper.Release)
+	if !ok || rel == nil {
+		return
+	}
+
+	appName, ok := rel.Labels[shipper.AppLabel]
+	if !ok {
+		return
+	}
+
+	releases, err := c.releaseLister.Releases(rel.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, dependent := range releases {
+		for _, dependency := range dependent.Spec.Environment.DependsOn {
+			if dependency == appName {
+				c.enqueueRelease(dependent)
+				break
+			}
+		}
+	}
+}
+
 func (c *Controller) getSiblingReleases(rel *shipper.Release) (*shipper.Release, *shipper.Release, error) {
 	releases, err := c.applicationReleases(rel)
 	if err != nil {
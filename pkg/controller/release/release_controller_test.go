@@ -6,13 +6,19 @@ import (
 	"testing"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/policy"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
 	"github.com/bookingcom/shipper/pkg/util/conditions"
 	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 const (
@@ -101,16 +107,23 @@ func TestRolloutsBlocked(t *testing.T) {
 	mgmtClusterObjects := []runtime.Object{rel, rb}
 	appClusterObjects := map[string][]runtime.Object{}
 
+	msg := fmt.Sprintf(
+		"rollout block(s) with name(s) %s/%s exist",
+		shippertesting.TestNamespace, rb.Name,
+	)
 	expectedStatus := shipper.ReleaseStatus{
 		Conditions: []shipper.ReleaseCondition{
 			{
-				Type:   shipper.ReleaseConditionTypeBlocked,
-				Status: corev1.ConditionTrue,
-				Reason: shipper.RolloutBlockReason,
-				Message: fmt.Sprintf(
-					"rollout block(s) with name(s) %s/%s exist",
-					shippertesting.TestNamespace, rb.Name,
-				),
+				Type:    shipper.ReleaseConditionTypeBlocked,
+				Status:  corev1.ConditionTrue,
+				Reason:  shipper.RolloutBlockReason,
+				Message: msg,
+			},
+			{
+				Type:    shipper.ReleaseConditionTypeQueued,
+				Status:  corev1.ConditionTrue,
+				Reason:  shipper.RolloutBlockReason,
+				Message: msg,
 			},
 		},
 	}
@@ -209,6 +222,51 @@ func TestClusterUnreachable(t *testing.T) {
 		})
 }
 
+// TestClusterNotReady tests that a Release will not progress when one of
+// its clusters is known, but not ready yet.
+func TestClusterNotReady(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"not-ready",
+		1,
+	)
+
+	clusterName := "cluster-a"
+	rel.Annotations[shipper.ReleaseClustersAnnotation] = clusterName
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel},
+		map[string][]runtime.Object{clusterName: nil},
+	)
+	notReadyErr := shippererrors.NewClusterNotReadyError(clusterName)
+	f.ClusterClientStore.SetClusterError(clusterName, notReadyErr)
+
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{clusterName}),
+			{
+				Type:    shipper.ReleaseConditionTypeStrategyExecuted,
+				Status:  corev1.ConditionFalse,
+				Reason:  StrategyExecutionFailed,
+				Message: notReadyErr.Error(),
+			},
+		},
+	}
+
+	runReleaseControllerTestWithFixture(t, f,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{clusterName},
+			},
+		},
+		nil,
+	)
+}
+
 // TestInvalidStrategy tests that a Release will not progress when it
 // can't execute its strategy.
 func TestInvalidStrategy(t *testing.T) {
@@ -258,6 +316,180 @@ func TestInvalidStrategy(t *testing.T) {
 		})
 }
 
+// TestStepRequiresApproval tests that a Release will not progress to a step
+// requiring approval until that step has been approved.
+func TestStepRequiresApproval(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"requires-approval",
+		1,
+	)
+
+	// The strategy is shared between tests, so we can't modify
+	// it in place.
+	rel.Spec.Environment.Strategy = rel.Spec.Environment.Strategy.DeepCopy()
+	rel.Spec.TargetStep = StepVanguard
+	rel.Spec.Environment.Strategy.Steps[StepVanguard].ApprovalRequired = true
+	rel.Spec.Environment.Strategy.Steps[StepVanguard].Approvers = []string{"alice", "group:sre"}
+
+	cluster := buildCluster("cluster-a")
+	mgmtClusterObjects := []runtime.Object{rel, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{},
+	}
+
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:   shipper.ReleaseConditionTypeStrategyExecuted,
+				Status: corev1.ConditionFalse,
+				Reason: WaitingForApproval,
+				Message: fmt.Sprintf(
+					"Release \"%s/%s\" is waiting for approval of step %d by one of: alice, group:sre",
+					rel.Namespace, rel.Name, StepVanguard,
+				),
+			},
+		},
+	}
+
+	runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+}
+
+// TestStepWaitsForDependency tests that a Release will not progress past the
+// step it achieved while the latest release of an application it depends on
+// is not complete.
+func TestStepWaitsForDependency(t *testing.T) {
+	dependency := buildRelease(
+		shippertesting.TestNamespace,
+		"api",
+		"incomplete",
+		1,
+	)
+
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"waits-for-dependency",
+		1,
+	)
+	rel.Spec.TargetStep = StepVanguard
+	rel.Spec.Environment.DependsOn = []string{dependency.Labels[shipper.AppLabel]}
+
+	cluster := buildCluster("cluster-a")
+	mgmtClusterObjects := []runtime.Object{rel, dependency, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{},
+	}
+
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:   shipper.ReleaseConditionTypeStrategyExecuted,
+				Status: corev1.ConditionFalse,
+				Reason: "WaitingForDependency",
+				Message: fmt.Sprintf(
+					"Release \"%s/%s\" is waiting for application \"api\": release %q is not complete",
+					rel.Namespace, rel.Name, dependency.Name,
+				),
+			},
+		},
+	}
+
+	runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+}
+
+// TestApprovedStep tests that a Release progresses normally to a step
+// requiring approval once it has been approved.
+func TestApprovedStep(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"approved-step",
+		1,
+	)
+
+	targetStep := StepVanguard
+	achievedStep := StepVanguard
+
+	// The strategy is shared between tests, so we can't modify
+	// it in place.
+	rel.Spec.Environment.Strategy = rel.Spec.Environment.Strategy.DeepCopy()
+	rel.Spec.TargetStep = targetStep
+	rel.Spec.Environment.Strategy.Steps[StepVanguard].ApprovalRequired = true
+	rel.Spec.Approvals = []shipper.ReleaseApproval{
+		{Step: StepVanguard, Approver: "alice"},
+	}
+
+	cluster := buildCluster("cluster-a")
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+
+	mgmtClusterObjects := []runtime.Object{rel, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{it, ct, tt},
+	}
+
+	expectedStatus := shipper.ReleaseStatus{
+		AchievedStep: &shipper.AchievedStep{
+			Step: achievedStep,
+			Name: rel.Spec.Environment.Strategy.Steps[achievedStep].Name,
+		},
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			ReleaseConditionStrategyExecuted,
+		},
+		Strategy: &shipper.ReleaseStrategyStatus{
+			Clusters: []shipper.ClusterStrategyStatus{
+				{
+					Name:            cluster.Name,
+					AchievedTraffic: puint32(0),
+					Conditions: stepify(achievedStep, []shipper.ReleaseStrategyCondition{
+						StrategyConditionContenderAchievedCapacity,
+						StrategyConditionContenderAchievedInstallation,
+						StrategyConditionContenderAchievedTraffic,
+					}),
+				},
+			},
+			State: StateWaitingForCommand,
+			ClustersConverged: &shipper.ClustersConverged{
+				Installation: "1/1",
+				Capacity:     "1/1",
+				Traffic:      "1/1",
+			},
+			StepHistory: achievedStepHistory(
+				achievedStep, rel.Spec.Environment.Strategy.Steps[achievedStep].Name),
+		},
+	}
+
+	runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+}
+
 // TestIntermediateStep tests that a Release will have all of its conditions
 // set appropriately for an intermediate achieved step (as in, not a final
 // step). This expects most conditions to be true, except for Blocked and
@@ -298,7 +530,8 @@ func TestIntermediateStep(t *testing.T) {
 		Strategy: &shipper.ReleaseStrategyStatus{
 			Clusters: []shipper.ClusterStrategyStatus{
 				{
-					Name: cluster.Name,
+					Name:            cluster.Name,
+					AchievedTraffic: puint32(0),
 					Conditions: stepify(achievedStep, []shipper.ReleaseStrategyCondition{
 						StrategyConditionContenderAchievedCapacity,
 						StrategyConditionContenderAchievedInstallation,
@@ -307,6 +540,13 @@ func TestIntermediateStep(t *testing.T) {
 				},
 			},
 			State: StateWaitingForCommand,
+			ClustersConverged: &shipper.ClustersConverged{
+				Installation: "1/1",
+				Capacity:     "1/1",
+				Traffic:      "1/1",
+			},
+			StepHistory: achievedStepHistory(
+				achievedStep, rel.Spec.Environment.Strategy.Steps[achievedStep].Name),
 		},
 	}
 
@@ -360,7 +600,86 @@ func TestLastStep(t *testing.T) {
 		Strategy: &shipper.ReleaseStrategyStatus{
 			Clusters: []shipper.ClusterStrategyStatus{
 				{
-					Name: cluster.Name,
+					Name:            cluster.Name,
+					AchievedTraffic: puint32(0),
+					Conditions: stepify(achievedStep, []shipper.ReleaseStrategyCondition{
+						StrategyConditionContenderAchievedCapacity,
+						StrategyConditionContenderAchievedInstallation,
+						StrategyConditionContenderAchievedTraffic,
+					}),
+				},
+			},
+			State: StateWaitingForNone,
+			ClustersConverged: &shipper.ClustersConverged{
+				Installation: "1/1",
+				Capacity:     "1/1",
+				Traffic:      "1/1",
+			},
+			StepHistory: achievedStepHistory(
+				achievedStep, rel.Spec.Environment.Strategy.Steps[achievedStep].Name),
+		},
+	}
+
+	runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+}
+
+// TestLastStepExperiment tests that a release running an experiment is not
+// marked as complete once it achieves its last step, and that its traffic
+// target is told how to bucket users.
+func TestLastStepExperiment(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"last-step-experiment",
+		1,
+	)
+
+	bucketing := shipper.ExperimentBucketing{Header: "X-User-Id"}
+	strategy := *rel.Spec.Environment.Strategy
+	strategy.Experiment = &shipper.RolloutExperiment{Bucketing: bucketing}
+	rel.Spec.Environment.Strategy = &strategy
+
+	targetStep := StepFullOn
+	achievedStep := StepFullOn
+	rel.Spec.TargetStep = targetStep
+
+	cluster := buildCluster("cluster-a")
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+	tt.Spec.Bucketing = bucketing.DeepCopy()
+
+	mgmtClusterObjects := []runtime.Object{rel, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{it, ct, tt},
+	}
+
+	expectedStatus := shipper.ReleaseStatus{
+		AchievedStep: &shipper.AchievedStep{
+			Step: achievedStep,
+			Name: rel.Spec.Environment.Strategy.Steps[achievedStep].Name,
+		},
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:    shipper.ReleaseConditionTypeComplete,
+				Status:  corev1.ConditionFalse,
+				Reason:  ExperimentRunning,
+				Message: "the release is a long-running experiment, roll out another release to end it",
+			},
+			ReleaseConditionStrategyExecuted,
+		},
+		Strategy: &shipper.ReleaseStrategyStatus{
+			Clusters: []shipper.ClusterStrategyStatus{
+				{
+					Name:            cluster.Name,
+					AchievedTraffic: puint32(0),
 					Conditions: stepify(achievedStep, []shipper.ReleaseStrategyCondition{
 						StrategyConditionContenderAchievedCapacity,
 						StrategyConditionContenderAchievedInstallation,
@@ -369,6 +688,13 @@ func TestLastStep(t *testing.T) {
 				},
 			},
 			State: StateWaitingForNone,
+			ClustersConverged: &shipper.ClustersConverged{
+				Installation: "1/1",
+				Capacity:     "1/1",
+				Traffic:      "1/1",
+			},
+			StepHistory: achievedStepHistory(
+				achievedStep, rel.Spec.Environment.Strategy.Steps[achievedStep].Name),
 		},
 	}
 
@@ -444,6 +770,149 @@ func TestIncumbentNotOnLastStep(t *testing.T) {
 		})
 }
 
+// TestPreStepHook tests that a Release won't start a step with a pre-step
+// hook until the hook's Job has completed in the application cluster.
+func TestPreStepHook(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"pre-step-hook",
+		1,
+	)
+	rel.Spec.TargetStep = StepVanguard
+	rel.Spec.Environment.Strategy = vanguard.DeepCopy()
+	rel.Spec.Environment.Strategy.Steps[StepVanguard].PreHooks = []shipper.StepHook{
+		{
+			Name: "warm-cache",
+			Job: &batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "warm", Image: "busybox"}},
+					},
+				},
+			},
+		},
+	}
+
+	achievedStep := StepStaging
+	cluster := buildCluster("cluster-a")
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+
+	mgmtClusterObjects := []runtime.Object{rel, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{it, ct, tt},
+	}
+
+	relKey := fmt.Sprintf("%s/%s", rel.Namespace, rel.Name)
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:   shipper.ReleaseConditionTypeStrategyExecuted,
+				Status: corev1.ConditionFalse,
+				Reason: "WaitingForHooks",
+				Message: fmt.Sprintf(
+					"Release %q is waiting for hook %q of step %d to complete in cluster %q",
+					relKey, "warm-cache", StepVanguard, cluster.Name),
+			},
+		},
+	}
+
+	f := runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+
+	jobName := fmt.Sprintf("%s-%d-pre-warm-cache", rel.Name, StepVanguard)
+	job, err := f.Clusters[cluster.Name].KubeClient.BatchV1().Jobs(rel.Namespace).
+		Get(jobName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected hook Job %q to be created: %s", jobName, err)
+	}
+
+	if job.Labels[shipper.StepHookLabel] != "warm-cache" {
+		t.Errorf("expected hook Job to be labelled with its hook, got %v", job.Labels)
+	}
+
+	ct, err = f.Clusters[cluster.Name].ShipperClient.ShipperV1alpha1().
+		CapacityTargets(rel.Namespace).Get(rel.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get CapacityTarget: %s", err)
+	}
+
+	if ct.Spec.Percent != 0 {
+		t.Errorf("expected capacity to be left alone while hooks run, got %d percent", ct.Spec.Percent)
+	}
+}
+
+// TestPullClusterStepHook tests that a Release with a step hook fails in
+// clusters in pull mode, as Shipper can't run hooks there.
+func TestPullClusterStepHook(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"pull-cluster-step-hook",
+		1,
+	)
+	rel.Spec.TargetStep = StepVanguard
+	rel.Spec.Environment.Strategy = vanguard.DeepCopy()
+	rel.Spec.Environment.Strategy.Steps[StepVanguard].PreHooks = []shipper.StepHook{
+		{Name: "warm-cache", Job: &batchv1.JobSpec{}},
+	}
+
+	achievedStep := StepStaging
+	cluster := buildCluster("cluster-a")
+	cluster.Spec.PullMode = true
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, []*shipper.Cluster{cluster}, &achievedStep)
+
+	mgmtClusterObjects := []runtime.Object{rel, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{it, ct, tt},
+	}
+
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			ReleaseConditionUnblocked,
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:   shipper.ReleaseConditionTypeStrategyExecuted,
+				Status: corev1.ConditionFalse,
+				Reason: "PullClusterStepUnsupported",
+				Message: fmt.Sprintf(
+					"step %q of release %q has hooks or probes, which cluster %q can't run as it's in pull mode",
+					rel.Spec.Environment.Strategy.Steps[StepVanguard].Name,
+					fmt.Sprintf("%s/%s", rel.Namespace, rel.Name), cluster.Name),
+			},
+		},
+	}
+
+	runReleaseControllerTest(t, mgmtClusterObjects, appClusterObjects,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		})
+}
+
+// achievedStepHistory is the step history of a release that achieved step
+// on its first sync, with timestamps discarded.
+func achievedStepHistory(step int32, name string) []shipper.ReleaseStepHistory {
+	return []shipper.ReleaseStepHistory{
+		{
+			Step:       step,
+			Name:       name,
+			AchievedAt: &metav1.Time{},
+		},
+	}
+}
+
 func stepify(step int32, conditions []shipper.ReleaseStrategyCondition) []shipper.ReleaseStrategyCondition {
 	for i, _ := range conditions {
 		conditions[i].Step = step
@@ -457,11 +926,20 @@ func runReleaseControllerTest(
 	mgmtClusterObjects []runtime.Object,
 	appClusterObjects map[string][]runtime.Object,
 	expectations []releaseControllerTestExpectation,
-) {
+) *shippertesting.ControllerTestFixture {
 	f := shippertesting.NewManagementControllerTestFixture(
 		mgmtClusterObjects, appClusterObjects)
 
-	runController(f)
+	return runReleaseControllerTestWithFixture(t, f, expectations, nil)
+}
+
+func runReleaseControllerTestWithFixture(
+	t *testing.T,
+	f *shippertesting.ControllerTestFixture,
+	expectations []releaseControllerTestExpectation,
+	policyEvaluator policy.Evaluator,
+) *shippertesting.ControllerTestFixture {
+	runController(f, policyEvaluator)
 
 	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
 	for _, expectation := range expectations {
@@ -495,15 +973,32 @@ func runReleaseControllerTest(
 			continue
 		}
 	}
+
+	return f
+}
+
+func runController(f *shippertesting.ControllerTestFixture, policyEvaluator policy.Evaluator) {
+	runControllerWithNotifier(f, policyEvaluator, nil)
 }
 
-func runController(f *shippertesting.ControllerTestFixture) {
+func runControllerWithNotifier(
+	f *shippertesting.ControllerTestFixture,
+	policyEvaluator policy.Evaluator,
+	completionNotifier CompletionNotifier,
+) {
 	controller := NewController(
 		f.ShipperClient,
 		f.ClusterClientStore,
 		f.ShipperInformerFactory,
 		shippertesting.LocalFetchChart,
 		f.Recorder,
+		Options{
+			DrainTimeout:       shutdown.DefaultDrainTimeout,
+			LowPriorityMaxWait: shipperworkqueue.DefaultLowPriorityMaxWait,
+			PolicyEvaluator:    policyEvaluator,
+			CompletionNotifier: completionNotifier,
+			CompletionTimeout:  time.Minute,
+		},
 	)
 
 	stopCh := make(chan struct{})
@@ -520,3 +1015,128 @@ func runController(f *shippertesting.ControllerTestFixture) {
 		}
 	}
 }
+
+func TestConsolidateStrategyStatusToleratesUnavailableClusters(t *testing.T) {
+	achieved := func() conditions.StrategyConditionsMap {
+		cond := conditions.NewStrategyConditions()
+		for _, ct := range []shipper.StrategyConditionType{
+			shipper.StrategyConditionContenderAchievedInstallation,
+			shipper.StrategyConditionContenderAchievedCapacity,
+			shipper.StrategyConditionContenderAchievedTraffic,
+		} {
+			cond.SetTrue(ct, conditions.StrategyConditionsUpdate{Step: 1})
+		}
+		return cond
+	}
+
+	tests := []struct {
+		name           string
+		maxUnavailable int
+		allDown        bool
+		unavailable    []string
+		complete       bool
+	}{
+		{"no tolerance", 0, false, nil, false},
+		{"within tolerance", 1, false, []string{"cluster-c"}, true},
+		{"everything unavailable", 3, true, nil, false},
+	}
+
+	for _, tt := range tests {
+		clusterConditions := map[string]conditions.StrategyConditionsMap{
+			"cluster-a": achieved(),
+			"cluster-b": achieved(),
+			"cluster-c": unavailableClusterConditions(1, fmt.Errorf("cluster is down")),
+		}
+		if tt.allDown {
+			for name := range clusterConditions {
+				clusterConditions[name] = unavailableClusterConditions(1, fmt.Errorf("cluster is down"))
+			}
+		}
+
+		complete, status := consolidateStrategyStatus(false, false, tt.maxUnavailable, clusterConditions)
+		if complete != tt.complete {
+			t.Errorf("%s: expected step complete to be %t, got %t", tt.name, tt.complete, complete)
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.unavailable, status.UnavailableClusters)
+		if !eq {
+			t.Errorf("%s: unexpected unavailable clusters:\n%s", tt.name, diff)
+		}
+	}
+}
+
+// TestConsolidateStrategyStatusCountsClustersConverged verifies that the
+// strategy status counts the clusters each kind of target has converged in,
+// leaving out the ones the strategy didn't get as far as.
+func TestConsolidateStrategyStatusCountsClustersConverged(t *testing.T) {
+	installed := conditions.NewStrategyConditions()
+	installed.SetTrue(shipper.StrategyConditionContenderAchievedInstallation, conditions.StrategyConditionsUpdate{Step: 1})
+	installed.SetFalse(shipper.StrategyConditionContenderAchievedCapacity, conditions.StrategyConditionsUpdate{Step: 1})
+
+	scaled := conditions.NewStrategyConditions()
+	scaled.SetTrue(shipper.StrategyConditionContenderAchievedInstallation, conditions.StrategyConditionsUpdate{Step: 1})
+	scaled.SetTrue(shipper.StrategyConditionContenderAchievedCapacity, conditions.StrategyConditionsUpdate{Step: 1})
+	scaled.SetFalse(shipper.StrategyConditionContenderAchievedTraffic, conditions.StrategyConditionsUpdate{Step: 1})
+
+	clusterConditions := map[string]conditions.StrategyConditionsMap{
+		"cluster-a": installed,
+		"cluster-b": scaled,
+		"cluster-c": unavailableClusterConditions(1, fmt.Errorf("cluster is down")),
+	}
+
+	_, status := consolidateStrategyStatus(false, false, 0, clusterConditions)
+
+	expected := &shipper.ClustersConverged{
+		Installation: "2/3",
+		Capacity:     "1/3",
+		Traffic:      "0/3",
+	}
+	eq, diff := shippertesting.DeepEqualDiff(expected, status.ClustersConverged)
+	if !eq {
+		t.Errorf("unexpected clusters converged:\n%s", diff)
+	}
+}
+
+// TestRecordAchievedTraffic verifies that the strategy status records the
+// traffic weight traffic targets report in each cluster, none for clusters
+// out of traffic, and the last one known for clusters that couldn't be
+// looked at.
+func TestRecordAchievedTraffic(t *testing.T) {
+	serving := buildReleaseInfo("serving", 50, 50)
+	serving.trafficTarget.Status.AchievedTraffic = 40
+
+	disabled := buildReleaseInfo("disabled", 50, 50)
+	disabled.trafficTarget.Spec.TrafficDisabled = true
+	disabled.trafficTarget.Status.AchievedTraffic = 50
+
+	prev := &shipper.ReleaseStrategyStatus{
+		Clusters: []shipper.ClusterStrategyStatus{
+			{Name: "cluster-c", AchievedTraffic: puint32(100)},
+		},
+	}
+	status := &shipper.ReleaseStrategyStatus{
+		Clusters: []shipper.ClusterStrategyStatus{
+			{Name: "cluster-a"},
+			{Name: "cluster-b"},
+			{Name: "cluster-c"},
+			{Name: "cluster-d"},
+		},
+	}
+
+	recordAchievedTraffic(status, prev, map[string]*releaseInfo{
+		"cluster-a": serving,
+		"cluster-b": disabled,
+	})
+
+	expected := []shipper.ClusterStrategyStatus{
+		{Name: "cluster-a", AchievedTraffic: puint32(40)},
+		{Name: "cluster-b", AchievedTraffic: puint32(0)},
+		{Name: "cluster-c", AchievedTraffic: puint32(100)},
+		{Name: "cluster-d"},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, status.Clusters)
+	if !eq {
+		t.Errorf("unexpected cluster statuses:\n%s", diff)
+	}
+}
@@ -0,0 +1,98 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+const (
+	WaitingForCluster = "WaitingForCluster"
+)
+
+func isSequentialRollout(rel *shipper.Release) bool {
+	order := rel.Spec.Environment.ClusterRequirements.RolloutOrder
+	return order != nil && order.Mode == shipper.ClusterRolloutSequential
+}
+
+// orderClustersForRollout sorts the clusters of a release rolling out
+// sequentially, in the order they should achieve each step.
+func (c *Controller) orderClustersForRollout(rel *shipper.Release, clusterNames []string) ([]string, error) {
+	clusters := make([]*shipper.Cluster, 0, len(clusterNames))
+	for _, name := range clusterNames {
+		cluster, err := c.clusterLister.Get(name)
+		if err != nil {
+			return nil, shippererrors.NewKubeclientGetError("", name, err).
+				WithShipperKind("Cluster")
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	return sortClustersForRollout(rel.Spec.Environment.ClusterRequirements.RolloutOrder, clusters), nil
+}
+
+// sortClustersForRollout puts the clusters explicitly listed in order first,
+// in the order they're listed, followed by the rest by their rolloutPriority
+// and then by name.
+func sortClustersForRollout(order *shipper.ClusterRolloutOrder, clusters []*shipper.Cluster) []string {
+	explicit := make(map[string]int, len(order.Clusters))
+	for i, name := range order.Clusters {
+		if _, ok := explicit[name]; !ok {
+			explicit[name] = i
+		}
+	}
+
+	sorted := make([]*shipper.Cluster, len(clusters))
+	copy(sorted, clusters)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+
+		ia, aListed := explicit[a.Name]
+		ib, bListed := explicit[b.Name]
+		if aListed || bListed {
+			if aListed && bListed {
+				return ia < ib
+			}
+			return aListed
+		}
+
+		pa, pb := a.Spec.Scheduler.RolloutPriority, b.Spec.Scheduler.RolloutPriority
+		if (pa == nil) != (pb == nil) {
+			return pa != nil
+		}
+		if pa != nil && *pa != *pb {
+			return *pa < *pb
+		}
+
+		return a.Name < b.Name
+	})
+
+	names := make([]string, 0, len(sorted))
+	for _, cluster := range sorted {
+		names = append(names, cluster.Name)
+	}
+
+	return names
+}
+
+// waitingForClusterConditions reports a cluster of a sequential rollout that
+// is left untouched until the cluster before it achieves the step.
+func waitingForClusterConditions(step int32, clusterName string) conditions.StrategyConditionsMap {
+	cond := conditions.NewStrategyConditions()
+	cond.SetFalse(
+		shipper.StrategyConditionContenderAchievedCapacity,
+		conditions.StrategyConditionsUpdate{
+			Reason:             WaitingForCluster,
+			Message:            fmt.Sprintf("waiting for cluster %q to achieve the step", clusterName),
+			Step:               step,
+			LastTransitionTime: time.Now(),
+		},
+	)
+
+	return cond
+}
@@ -0,0 +1,125 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestSortClustersForRollout(t *testing.T) {
+	withPriority := func(name string, priority int32) *shipper.Cluster {
+		cluster := buildCluster(name)
+		cluster.Spec.Scheduler.RolloutPriority = &priority
+		return cluster
+	}
+
+	clusters := []*shipper.Cluster{
+		buildCluster("cluster-a"),
+		withPriority("cluster-b", 2),
+		withPriority("cluster-c", 1),
+		buildCluster("cluster-d"),
+	}
+
+	tests := []struct {
+		name     string
+		explicit []string
+		expected []string
+	}{
+		{"by priority", nil, []string{"cluster-c", "cluster-b", "cluster-a", "cluster-d"}},
+		{"explicit list first", []string{"cluster-d", "cluster-b"}, []string{"cluster-d", "cluster-b", "cluster-c", "cluster-a"}},
+	}
+
+	for _, tt := range tests {
+		order := &shipper.ClusterRolloutOrder{
+			Mode:     shipper.ClusterRolloutSequential,
+			Clusters: tt.explicit,
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, sortClustersForRollout(order, clusters))
+		if !eq {
+			t.Errorf("%s: unexpected order:\n%s", tt.name, diff)
+		}
+	}
+}
+
+// TestSequentialRollout checks that a release rolling out sequentially leaves
+// a cluster alone until the cluster before it has achieved the step.
+func TestSequentialRollout(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"sequential",
+		2,
+	)
+	rel.Spec.TargetStep = StepVanguard
+	rel.Spec.Environment.ClusterRequirements.RolloutOrder = &shipper.ClusterRolloutOrder{
+		Mode:     shipper.ClusterRolloutSequential,
+		Clusters: []string{"cluster-b"},
+	}
+
+	achievedStep := StepStaging
+	clusterA := buildCluster("cluster-a")
+	clusterB := buildCluster("cluster-b")
+	clusters := []*shipper.Cluster{clusterA, clusterB}
+
+	mgmtClusterObjects := []runtime.Object{rel, clusterA, clusterB}
+	appClusterObjects := map[string][]runtime.Object{}
+	for _, cluster := range clusters {
+		it, tt, ct := buildAssociatedObjectsWithStatus(rel, clusters, &achievedStep)
+		appClusterObjects[cluster.Name] = []runtime.Object{it, ct, tt}
+	}
+
+	// cluster-b goes first, and its pods never become ready.
+	ct := appClusterObjects[clusterB.Name][1].(*shipper.CapacityTarget)
+	ct.Status.Conditions = []shipper.TargetCondition{
+		TargetConditionOperational,
+		{Type: shipper.TargetConditionTypeReady, Status: corev1.ConditionFalse},
+	}
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		mgmtClusterObjects, appClusterObjects)
+	runController(f, nil)
+
+	for _, tt := range []struct {
+		cluster string
+		percent int32
+	}{
+		{clusterB.Name, rel.Spec.Environment.Strategy.Steps[StepVanguard].Capacity.Contender},
+		{clusterA.Name, 0},
+	} {
+		ct, err := f.Clusters[tt.cluster].ShipperClient.ShipperV1alpha1().
+			CapacityTargets(rel.Namespace).Get(rel.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("could not get CapacityTarget in cluster %q: %s", tt.cluster, err)
+		}
+
+		if ct.Spec.Percent != tt.percent {
+			t.Errorf("expected capacity in cluster %q to be %d percent, got %d",
+				tt.cluster, tt.percent, ct.Spec.Percent)
+		}
+	}
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatalf("could not get Release: %s", err)
+	}
+
+	for _, cluster := range object.(*shipper.Release).Status.Strategy.Clusters {
+		if cluster.Name != clusterA.Name {
+			continue
+		}
+
+		for _, cond := range cluster.Conditions {
+			if cond.Type == shipper.StrategyConditionContenderAchievedCapacity &&
+				(cond.Status != corev1.ConditionFalse || cond.Reason != WaitingForCluster) {
+				t.Errorf("expected cluster %q to be waiting for its turn, got %+v", cluster.Name, cond)
+			}
+		}
+	}
+}
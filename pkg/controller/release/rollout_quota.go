@@ -0,0 +1,270 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	"github.com/bookingcom/shipper/pkg/util/diff"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+// checkRolloutQuotas returns an error while rel waits in the rollout queue
+// of its namespace: for the releases ahead of it, or for the first
+// RolloutQuota it would take over once it's first in line. Releases asking
+// for more replicas than a quota ever lets through don't wait in the queue
+// at all. Releases that fit in all of them are annotated with the replicas
+// they ask for in clusters, which counts them against the quotas from then
+// on.
+func (c *Controller) checkRolloutQuotas(rel *shipper.Release, clusters []string) error {
+	quotas, err := c.rolloutQuotaLister.RolloutQuotas(rel.Namespace).List(labels.Everything())
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("RolloutQuota"),
+			rel.Namespace, labels.Everything(), err)
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	releases, err := c.releaseLister.Releases(rel.Namespace).List(labels.Everything())
+	if err != nil {
+		return shippererrors.NewKubeclientListError(
+			shipper.SchemeGroupVersion.WithKind("Release"),
+			rel.Namespace, labels.Everything(), err)
+	}
+
+	var replicas int32
+	for _, clusterName := range clusters {
+		clusterReplicas, err := clusterReplicaCount(c.chartFetcher, rel, clusterName)
+		if err != nil {
+			return err
+		}
+		replicas += clusterReplicas
+	}
+
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Name < quotas[j].Name
+	})
+
+	relKey := objectutil.MetaKey(rel)
+	for _, quota := range quotas {
+		if max := quota.Spec.MaxReplicas; max != nil && replicas > *max {
+			return shippererrors.NewRolloutQuotaUnsatisfiableError(
+				relKey, objectutil.MetaKey(quota), replicas, *max)
+		}
+	}
+
+	ahead := rolloutQueueAhead(rel, releases)
+	if len(ahead) > 0 {
+		keys := make([]string, 0, len(ahead))
+		for _, r := range ahead {
+			keys = append(keys, strconv.Quote(objectutil.MetaKey(r)))
+		}
+
+		return shippererrors.NewRolloutQuotaExceededError(relKey, len(ahead)+1,
+			fmt.Sprintf("waiting for %s ahead of it", strings.Join(keys, ", ")))
+	}
+
+	rollouts := rolloutsInFlight(rel, releases)
+	for _, quota := range quotas {
+		if msg := rolloutQuotaExceeded(quota, replicas, rollouts); msg != "" {
+			return shippererrors.NewRolloutQuotaExceededError(relKey, 1,
+				fmt.Sprintf("waiting for rollout quota %q: %s", objectutil.MetaKey(quota), msg))
+		}
+	}
+
+	if rel.Annotations == nil {
+		rel.Annotations = map[string]string{}
+	}
+	rel.Annotations[shipper.RolloutQuotaReplicasAnnotation] = strconv.Itoa(int(replicas))
+
+	return nil
+}
+
+// rolloutAdmitted tells whether rel was let through by the rollout quotas
+// of its namespace already.
+func rolloutAdmitted(rel *shipper.Release) bool {
+	_, ok := rel.Annotations[shipper.RolloutQuotaReplicasAnnotation]
+	return ok
+}
+
+// rolloutsInFlight returns the replicas asked for by the release rolling
+// out in each application of releases, other than rel's. A release is
+// rolling out when it's the latest of its application, it hasn't completed,
+// and it was either let through by a quota or installed before there was
+// one. Only the replicas of the former are known.
+func rolloutsInFlight(rel *shipper.Release, releases []*shipper.Release) map[string]int32 {
+	rollouts := make(map[string]int32)
+	for appName, head := range otherApplicationHeads(rel, releases) {
+		if releaseutil.ReleaseComplete(head) {
+			continue
+		}
+
+		annotation, admitted := head.Annotations[shipper.RolloutQuotaReplicasAnnotation]
+		if !admitted && head.Status.AchievedStep == nil {
+			continue
+		}
+
+		replicas, _ := strconv.Atoi(annotation)
+		rollouts[appName] = int32(replicas)
+	}
+
+	return rollouts
+}
+
+// rolloutQueueAhead returns the releases waiting in the rollout queue ahead
+// of rel, in order. Only the latest release of each application can be
+// waiting, so no application ever holds more than one place in the queue,
+// and releases are let through in the order they were created.
+func rolloutQueueAhead(rel *shipper.Release, releases []*shipper.Release) []*shipper.Release {
+	var ahead []*shipper.Release
+	for _, head := range otherApplicationHeads(rel, releases) {
+		if waitingForRolloutQuota(head) && queuedBefore(head, rel) {
+			ahead = append(ahead, head)
+		}
+	}
+
+	sort.Slice(ahead, func(i, j int) bool {
+		return queuedBefore(ahead[i], ahead[j])
+	})
+
+	return ahead
+}
+
+func queuedBefore(a, b *shipper.Release) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+
+	return a.Name < b.Name
+}
+
+// otherApplicationHeads returns the latest release of each application of
+// releases, other than rel's.
+func otherApplicationHeads(rel *shipper.Release, releases []*shipper.Release) map[string]*shipper.Release {
+	heads := make(map[string]*shipper.Release)
+	for _, r := range releaseutil.SortByGenerationAscending(releases) {
+		appName, ok := r.Labels[shipper.AppLabel]
+		if !ok || appName == rel.Labels[shipper.AppLabel] {
+			continue
+		}
+
+		heads[appName] = r
+	}
+
+	return heads
+}
+
+// rolloutQuotaExceeded returns why a release asking for replicas doesn't
+// fit in quota next to rollouts, or an empty string if it does.
+func rolloutQuotaExceeded(quota *shipper.RolloutQuota, replicas int32, rollouts map[string]int32) string {
+	if max := quota.Spec.MaxConcurrentRollouts; max != nil && int32(len(rollouts)) >= *max {
+		return fmt.Sprintf("%d of at most %d applications are rolling out already", len(rollouts), *max)
+	}
+
+	if max := quota.Spec.MaxReplicas; max != nil {
+		var total int32
+		for _, r := range rollouts {
+			total += r
+		}
+
+		if total+replicas > *max {
+			return fmt.Sprintf(
+				"it asks for %d replicas, and releases rolling out ask for %d already, out of at most %d",
+				replicas, total, *max)
+		}
+	}
+
+	return ""
+}
+
+// waitingForRolloutQuota tells whether rel waits in the rollout queue of
+// its namespace.
+func waitingForRolloutQuota(rel *shipper.Release) bool {
+	cond := releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeQueued)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason == shipper.RolloutQuotaExceededReason
+}
+
+// setQueuedCondition reports on rel whether it waits to start rolling out,
+// and why. Releases that never waited don't get the condition at all.
+func setQueuedCondition(rel *shipper.Release, reason, msg string) diff.Diff {
+	if reason == "" {
+		cond := releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeQueued)
+		if cond == nil || cond.Status == corev1.ConditionFalse {
+			return nil
+		}
+
+		condition := releaseutil.NewReleaseCondition(
+			shipper.ReleaseConditionTypeQueued,
+			corev1.ConditionFalse,
+			"",
+			"",
+		)
+		return releaseutil.SetReleaseCondition(&rel.Status, *condition)
+	}
+
+	condition := releaseutil.NewReleaseCondition(
+		shipper.ReleaseConditionTypeQueued,
+		corev1.ConditionTrue,
+		reason,
+		msg,
+	)
+	return releaseutil.SetReleaseCondition(&rel.Status, *condition)
+}
+
+// enqueueReleasesWaitingForRolloutQuota enqueues the releases in namespace
+// that wait in its rollout queue, so they start rolling out as soon as
+// there's room for them, and their place in the queue is kept up to date.
+func (c *Controller) enqueueReleasesWaitingForRolloutQuota(namespace string) {
+	releases, err := c.releaseLister.Releases(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		if waitingForRolloutQuota(rel) {
+			c.enqueueRelease(rel)
+		}
+	}
+}
+
+// rolloutQuotaUnsatisfiable tells whether rel is blocked for asking for
+// more replicas than a rollout quota of its namespace ever lets through.
+func rolloutQuotaUnsatisfiable(rel *shipper.Release) bool {
+	cond := releaseutil.GetReleaseCondition(rel.Status, shipper.ReleaseConditionTypeBlocked)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason == shipper.RolloutQuotaUnsatisfiableReason
+}
+
+// enqueueReleasesFromRolloutQuota enqueues the releases waiting for a
+// RolloutQuota, or blocked by it, when it's changed or removed.
+func (c *Controller) enqueueReleasesFromRolloutQuota(obj interface{}) {
+	quota, ok := obj.(*shipper.RolloutQuota)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("not a shipper.RolloutQuota: %#v", obj))
+		return
+	}
+
+	c.enqueueReleasesWaitingForRolloutQuota(quota.Namespace)
+
+	releases, err := c.releaseLister.Releases(quota.Namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error fetching releases: %s", err))
+		return
+	}
+
+	for _, rel := range releases {
+		if rolloutQuotaUnsatisfiable(rel) {
+			c.enqueueRelease(rel)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package release
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+)
+
+func buildRolloutQuota(name string, maxRollouts, maxReplicas *int32) *shipper.RolloutQuota {
+	return &shipper.RolloutQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: shippertesting.TestNamespace,
+		},
+		Spec: shipper.RolloutQuotaSpec{
+			MaxConcurrentRollouts: maxRollouts,
+			MaxReplicas:           maxReplicas,
+		},
+	}
+}
+
+// TestRolloutQuotaHoldsInstallation tests that a Release is held back,
+// without being installed, while its namespace has as many applications
+// rolling out as its RolloutQuota allows.
+func TestRolloutQuotaHoldsInstallation(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"waiting",
+		1,
+	)
+
+	rollingOut := buildRelease(shippertesting.TestNamespace, "other-app", "rolling-out", 1)
+	rollingOut.Annotations[shipper.RolloutQuotaReplicasAnnotation] = "1"
+
+	quota := buildRolloutQuota("team", pint32(1), nil)
+
+	cluster := buildCluster("cluster-a")
+	mgmtClusterObjects := []runtime.Object{rel, rollingOut, quota, cluster}
+	appClusterObjects := map[string][]runtime.Object{
+		cluster.Name: []runtime.Object{},
+	}
+
+	msg := fmt.Sprintf(
+		"Release \"%s/%s\" is number 1 in the rollout queue of its namespace, waiting for rollout quota \"%s/%s\": 1 of at most 1 applications are rolling out already",
+		rel.Namespace, rel.Name, quota.Namespace, quota.Name,
+	)
+	expectedStatus := shipper.ReleaseStatus{
+		Conditions: []shipper.ReleaseCondition{
+			{
+				Type:    shipper.ReleaseConditionTypeBlocked,
+				Status:  corev1.ConditionTrue,
+				Reason:  shipper.RolloutQuotaExceededReason,
+				Message: msg,
+			},
+			ReleaseConditionClustersChosen([]string{cluster.Name}),
+			{
+				Type:    shipper.ReleaseConditionTypeQueued,
+				Status:  corev1.ConditionTrue,
+				Reason:  shipper.RolloutQuotaExceededReason,
+				Message: msg,
+			},
+			{
+				Type:    shipper.ReleaseConditionTypeStrategyExecuted,
+				Status:  corev1.ConditionFalse,
+				Reason:  shipper.RolloutQuotaExceededReason,
+				Message: msg,
+			},
+		},
+	}
+
+	f := shippertesting.NewManagementControllerTestFixture(
+		mgmtClusterObjects, appClusterObjects)
+	runReleaseControllerTestWithFixture(t, f,
+		[]releaseControllerTestExpectation{
+			{
+				release:  rel,
+				status:   expectedStatus,
+				clusters: []string{cluster.Name},
+			},
+		},
+		nil,
+	)
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	_, err := f.Clusters[cluster.Name].ShipperClient.Tracker().Get(itGVR, rel.Namespace, rel.Name)
+	if err == nil {
+		t.Errorf("expected release waiting for a rollout quota not to be installed")
+	}
+}
+
+// TestRolloutQuotaAdmitsRelease tests that a Release that fits in its
+// namespace's RolloutQuota is installed, and annotated with the replicas it
+// asks for.
+func TestRolloutQuotaAdmitsRelease(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"admitted",
+		1,
+	)
+	quota := buildRolloutQuota("team", pint32(1), pint32(12))
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, quota, cluster},
+		map[string][]runtime.Object{cluster.Name: []runtime.Object{}},
+	)
+	runController(f, nil)
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := object.(*shipper.Release).Annotations[shipper.RolloutQuotaReplicasAnnotation]
+	if replicas != "12" {
+		t.Errorf("expected release to be admitted with the 12 replicas of its chart, got %q", replicas)
+	}
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	_, err = f.Clusters[cluster.Name].ShipperClient.Tracker().Get(itGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Errorf("expected release fitting in its rollout quota to be installed: %s", err)
+	}
+}
+
+// TestRolloutQuotaUnsatisfiable tests that a Release asking for more
+// replicas than its namespace's RolloutQuota ever lets through is blocked,
+// and leaves the rollout queue instead of holding up the ones behind it.
+func TestRolloutQuotaUnsatisfiable(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"too-big",
+		1,
+	)
+	rel.Status.Conditions = []shipper.ReleaseCondition{
+		{
+			Type:   shipper.ReleaseConditionTypeQueued,
+			Status: corev1.ConditionTrue,
+			Reason: shipper.RolloutQuotaExceededReason,
+		},
+	}
+
+	quota := buildRolloutQuota("team", nil, pint32(5))
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, quota, cluster},
+		map[string][]runtime.Object{cluster.Name: []runtime.Object{}},
+	)
+	runController(f, nil)
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := object.(*shipper.Release)
+
+	expected := fmt.Sprintf(
+		"Release \"%s/%s\" asks for 12 replicas, more than the at most 5 of rollout quota \"%s/%s\": it can't roll out unless the quota is raised",
+		rel.Namespace, rel.Name, quota.Namespace, quota.Name,
+	)
+	cond := releaseutil.GetReleaseCondition(updated.Status, shipper.ReleaseConditionTypeBlocked)
+	if cond == nil || cond.Status != corev1.ConditionTrue ||
+		cond.Reason != shipper.RolloutQuotaUnsatisfiableReason || cond.Message != expected {
+		t.Errorf("expected release to be blocked with message %q, got %+v", expected, cond)
+	}
+
+	if waitingForRolloutQuota(updated) {
+		t.Errorf("expected release that can't ever fit in its rollout quota to leave the rollout queue")
+	}
+
+	itGVR := shipper.SchemeGroupVersion.WithResource("installationtargets")
+	_, err = f.Clusters[cluster.Name].ShipperClient.Tracker().Get(itGVR, rel.Namespace, rel.Name)
+	if err == nil {
+		t.Errorf("expected release that can't ever fit in its rollout quota not to be installed")
+	}
+}
+
+// TestRolloutQueuePosition tests that a Release waits for the ones queued
+// before it, and reports its place in the queue.
+func TestRolloutQueuePosition(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"queued",
+		1,
+	)
+	rel.CreationTimestamp = metav1.Now()
+
+	rollingOut := buildRelease(shippertesting.TestNamespace, "other-app", "rolling-out", 1)
+	rollingOut.Annotations[shipper.RolloutQuotaReplicasAnnotation] = "1"
+
+	first := buildRelease(shippertesting.TestNamespace, "first-app", "queued", 1)
+	first.CreationTimestamp = metav1.NewTime(rel.CreationTimestamp.Add(-time.Hour))
+	first.Status.Conditions = []shipper.ReleaseCondition{
+		{
+			Type:   shipper.ReleaseConditionTypeQueued,
+			Status: corev1.ConditionTrue,
+			Reason: shipper.RolloutQuotaExceededReason,
+		},
+	}
+
+	quota := buildRolloutQuota("team", pint32(1), nil)
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, rollingOut, first, quota, cluster},
+		map[string][]runtime.Object{cluster.Name: []runtime.Object{}},
+	)
+	runController(f, nil)
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf(
+		"Release \"%s/%s\" is number 2 in the rollout queue of its namespace, waiting for \"%s/%s\" ahead of it",
+		rel.Namespace, rel.Name, first.Namespace, first.Name,
+	)
+	cond := releaseutil.GetReleaseCondition(object.(*shipper.Release).Status, shipper.ReleaseConditionTypeQueued)
+	if cond == nil || cond.Status != corev1.ConditionTrue || cond.Message != expected {
+		t.Errorf("expected release to be queued with message %q, got %+v", expected, cond)
+	}
+}
+
+// TestRolloutQueueKeptOnError tests that a Release waiting in the rollout
+// queue keeps its place in it when it fails for anything but its quotas.
+func TestRolloutQueueKeptOnError(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"queued",
+		1,
+	)
+
+	// The strategy is shared between tests, so we can't modify
+	// it in place.
+	rel.Spec.Environment.Strategy = rel.Spec.Environment.Strategy.DeepCopy()
+	rel.Spec.Environment.Strategy.Steps[StepStaging].ApprovalRequired = true
+
+	queued := shipper.ReleaseCondition{
+		Type:    shipper.ReleaseConditionTypeQueued,
+		Status:  corev1.ConditionTrue,
+		Reason:  shipper.RolloutQuotaExceededReason,
+		Message: "waiting",
+	}
+	rel.Status.Conditions = []shipper.ReleaseCondition{queued}
+
+	quota := buildRolloutQuota("team", pint32(1), nil)
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, quota, cluster},
+		map[string][]runtime.Object{cluster.Name: []runtime.Object{}},
+	)
+	runController(f, nil)
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated := object.(*shipper.Release)
+	cond := releaseutil.GetReleaseCondition(updated.Status, shipper.ReleaseConditionTypeQueued)
+	if cond == nil || cond.Status != corev1.ConditionTrue || cond.Reason != queued.Reason {
+		t.Errorf("expected release to keep its place in the rollout queue, got %+v", cond)
+	}
+
+	cond = releaseutil.GetReleaseCondition(updated.Status, shipper.ReleaseConditionTypeStrategyExecuted)
+	if cond == nil || cond.Reason != WaitingForApproval {
+		t.Errorf("expected release to be waiting for approval, got %+v", cond)
+	}
+}
+
+// TestRolloutQueueLeftOnAdmission tests that a Release waiting in the
+// rollout queue leaves it once its quotas have room for it.
+func TestRolloutQueueLeftOnAdmission(t *testing.T) {
+	rel := buildRelease(
+		shippertesting.TestNamespace,
+		shippertesting.TestApp,
+		"admitted",
+		1,
+	)
+	rel.Status.Conditions = []shipper.ReleaseCondition{
+		{
+			Type:   shipper.ReleaseConditionTypeQueued,
+			Status: corev1.ConditionTrue,
+			Reason: shipper.RolloutQuotaExceededReason,
+		},
+	}
+
+	quota := buildRolloutQuota("team", pint32(1), nil)
+
+	cluster := buildCluster("cluster-a")
+	f := shippertesting.NewManagementControllerTestFixture(
+		[]runtime.Object{rel, quota, cluster},
+		map[string][]runtime.Object{cluster.Name: []runtime.Object{}},
+	)
+	runController(f, nil)
+
+	relGVR := shipper.SchemeGroupVersion.WithResource("releases")
+	object, err := f.ShipperClient.Tracker().Get(relGVR, rel.Namespace, rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cond := releaseutil.GetReleaseCondition(object.(*shipper.Release).Status, shipper.ReleaseConditionTypeQueued)
+	if cond == nil || cond.Status != corev1.ConditionFalse {
+		t.Errorf("expected release to leave the rollout queue, got %+v", cond)
+	}
+}
+
+func TestRolloutQueueAhead(t *testing.T) {
+	now := time.Now()
+	build := func(app string, age time.Duration, waiting bool) *shipper.Release {
+		rel := buildRelease(shippertesting.TestNamespace, app, "rel", 1)
+		rel.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		if waiting {
+			rel.Status.Conditions = []shipper.ReleaseCondition{
+				{
+					Type:   shipper.ReleaseConditionTypeQueued,
+					Status: corev1.ConditionTrue,
+					Reason: shipper.RolloutQuotaExceededReason,
+				},
+			}
+		}
+
+		return rel
+	}
+
+	rel := build(shippertesting.TestApp, time.Minute, false)
+	second := build("second", 2*time.Minute, true)
+	first := build("first", 3*time.Minute, true)
+	releases := []*shipper.Release{
+		rel,
+		second,
+		first,
+		build("behind", 0, true),
+		build("not-waiting", time.Hour, false),
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(
+		[]*shipper.Release{first, second}, rolloutQueueAhead(rel, releases))
+	if !eq {
+		t.Errorf("unexpected releases ahead in the queue:\n%s", diff)
+	}
+}
+
+func TestRolloutsInFlight(t *testing.T) {
+	build := func(app, name, generation string, admitted string, achieved, complete bool) *shipper.Release {
+		rel := buildRelease(shippertesting.TestNamespace, app, name, 1)
+		rel.Annotations[shipper.ReleaseGenerationAnnotation] = generation
+		if admitted != "" {
+			rel.Annotations[shipper.RolloutQuotaReplicasAnnotation] = admitted
+		}
+		if achieved {
+			rel.Status.AchievedStep = &shipper.AchievedStep{Step: 0}
+		}
+		if complete {
+			rel.Status.Conditions = []shipper.ReleaseCondition{
+				{Type: shipper.ReleaseConditionTypeComplete, Status: corev1.ConditionTrue},
+			}
+		}
+
+		return rel
+	}
+
+	rel := build(shippertesting.TestApp, "new", "2", "", false, false)
+	releases := []*shipper.Release{
+		rel,
+		// The release's own application doesn't count.
+		build(shippertesting.TestApp, "old", "1", "3", true, false),
+		// Only the latest release of an application does.
+		build("admitted", "old", "1", "", true, false),
+		build("admitted", "new", "2", "4", false, false),
+		build("installed-before-quota", "a", "1", "", true, false),
+		build("complete", "a", "1", "5", true, true),
+		build("waiting", "a", "1", "", false, false),
+	}
+
+	expected := map[string]int32{
+		"admitted":               4,
+		"installed-before-quota": 0,
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, rolloutsInFlight(rel, releases))
+	if !eq {
+		t.Errorf("unexpected rollouts in flight:\n%s", diff)
+	}
+}
+
+func TestRolloutQuotaExceeded(t *testing.T) {
+	rollouts := map[string]int32{"a": 4, "b": 6}
+
+	tests := []struct {
+		name     string
+		quota    *shipper.RolloutQuota
+		replicas int32
+		exceeded bool
+	}{
+		{"no limits", buildRolloutQuota("q", nil, nil), 100, false},
+		{"room for a rollout", buildRolloutQuota("q", pint32(3), nil), 1, false},
+		{"no room for a rollout", buildRolloutQuota("q", pint32(2), nil), 1, true},
+		{"room for replicas", buildRolloutQuota("q", nil, pint32(15)), 5, false},
+		{"no room for replicas", buildRolloutQuota("q", nil, pint32(15)), 6, true},
+	}
+
+	for _, tt := range tests {
+		msg := rolloutQuotaExceeded(tt.quota, tt.replicas, rollouts)
+		if exceeded := msg != ""; exceeded != tt.exceeded {
+			t.Errorf("%s: expected quota to be exceeded: %t, got message %q", tt.name, tt.exceeded, msg)
+		}
+	}
+}
@@ -1,25 +1,36 @@
 package release
 
 import (
+	"encoding/json"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperchart "github.com/bookingcom/shipper/pkg/chart"
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
 	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
 type Scheduler struct {
-	clientset    shipperclientset.Interface
-	listers      listers
-	chartFetcher shipperrepo.ChartFetcher
-	recorder     record.EventRecorder
+	clientset       shipperclientset.Interface
+	listers         listers
+	chartFetcher    shipperrepo.ChartFetcher
+	recorder        record.EventRecorder
+	trafficBackend  string
+	trafficDisabled bool
+	clusterName     string
 }
 
 func NewScheduler(
@@ -27,34 +38,64 @@ func NewScheduler(
 	listers listers,
 	chartFetcher shipperrepo.ChartFetcher,
 	recorder record.EventRecorder,
+	trafficBackend string,
+	trafficDisabled bool,
+	clusterName string,
 ) *Scheduler {
 	return &Scheduler{
-		clientset:    clientset,
-		listers:      listers,
-		chartFetcher: chartFetcher,
-		recorder:     recorder,
+		clientset:       clientset,
+		listers:         listers,
+		chartFetcher:    chartFetcher,
+		recorder:        recorder,
+		trafficBackend:  trafficBackend,
+		trafficDisabled: trafficDisabled,
+		clusterName:     clusterName,
+	}
+}
+
+// getClusterTrafficBackend returns the traffic backend a cluster wants its
+// traffic targets to use. The cluster's traffic backend annotation takes
+// precedence over a "traffic-backend/<name>" capability. An empty string
+// means the default backend.
+func getClusterTrafficBackend(cluster *shipper.Cluster) string {
+	if backend, ok := cluster.Annotations[shipper.ClusterTrafficBackendAnnotation]; ok {
+		return backend
+	}
+
+	for _, capability := range cluster.Spec.Capabilities {
+		if strings.HasPrefix(capability, shipper.ClusterTrafficBackendCapability) {
+			return strings.TrimPrefix(capability, shipper.ClusterTrafficBackendCapability)
+		}
 	}
+
+	return ""
 }
 
-func (s *Scheduler) ScheduleRelease(rel *shipper.Release) (*releaseInfo, error) {
-	replicaCount, err := s.fetchChartAndExtractReplicaCount(rel)
+func (s *Scheduler) ScheduleRelease(span *tracing.Span, rel *shipper.Release) (*releaseInfo, error) {
+	replicaCount, workloads, err := s.fetchChartAndExtractWorkloads(rel)
 	if err != nil {
+		s.recorder.Event(rel, corev1.EventTypeWarning, shipperevents.ChartFetchFailed, err.Error())
 		return nil, err
 	}
 
 	releaseErrors := shippererrors.NewMultiError()
 
-	it, err := s.createInstallationTarget(rel)
+	it, err := s.createInstallationTarget(span, rel)
 	if err != nil {
 		releaseErrors.Append(err)
 	}
 
-	tt, err := s.createTrafficTarget(rel)
-	if err != nil {
-		releaseErrors.Append(err)
+	// Jobs don't get traffic, so there's nothing for a traffic target
+	// to do.
+	var tt *shipper.TrafficTarget
+	if !releaseutil.RunsToCompletion(rel) {
+		tt, err = s.createTrafficTarget(span, rel)
+		if err != nil {
+			releaseErrors.Append(err)
+		}
 	}
 
-	ct, err := s.createCapacityTarget(rel, replicaCount)
+	ct, err := s.createCapacityTarget(span, rel, replicaCount, workloads)
 	if err != nil {
 		releaseErrors.Append(err)
 	}
@@ -71,7 +112,7 @@ func (s *Scheduler) ScheduleRelease(rel *shipper.Release) (*releaseInfo, error)
 	}, nil
 }
 
-func (s *Scheduler) createInstallationTarget(rel *shipper.Release) (*shipper.InstallationTarget, error) {
+func (s *Scheduler) createInstallationTarget(span *tracing.Span, rel *shipper.Release) (*shipper.InstallationTarget, error) {
 	it, err := s.listers.installationTargetLister.InstallationTargets(rel.GetNamespace()).Get(rel.GetName())
 	if err != nil {
 		if !errors.IsNotFound(err) {
@@ -85,13 +126,25 @@ func (s *Scheduler) createInstallationTarget(rel *shipper.Release) (*shipper.Ins
 				Labels:    rel.Labels,
 			},
 			Spec: shipper.InstallationTargetSpec{
-				Chart:       rel.Spec.Environment.Chart,
-				Values:      rel.Spec.Environment.Values,
-				CanOverride: true,
+				Chart:         rel.Spec.Environment.Chart,
+				Values:        releaseutil.GetClusterValues(rel, s.clusterName),
+				ImageOverride: rel.Spec.Environment.ImageOverride,
+				PrePullImages: rel.Spec.Environment.PrePullImages,
+				ValuesFrom:    rel.Spec.Environment.ValuesFrom,
+				CanOverride:   true,
+
+				TopologySpread: rel.Spec.Environment.TopologySpread,
+
+				AdditionalCharts:  rel.Spec.Environment.AdditionalCharts,
+				ReadinessBarriers: rel.Spec.Environment.ReadinessBarriers,
+				WorkloadKind:      rel.Spec.Environment.WorkloadKind,
+				Adopt:             rel.Spec.Adopt,
 			},
 		}
 
+		apiSpan := span.StartChild("Create InstallationTarget", tracing.String("shipper.key", objectutil.MetaKey(it)))
 		updIt, err := s.clientset.ShipperV1alpha1().InstallationTargets(rel.GetNamespace()).Create(it)
+		apiSpan.End(err)
 		if err != nil {
 			return nil, shippererrors.NewKubeclientCreateError(it, err)
 		}
@@ -99,7 +152,7 @@ func (s *Scheduler) createInstallationTarget(rel *shipper.Release) (*shipper.Ins
 		s.recorder.Eventf(
 			rel,
 			corev1.EventTypeNormal,
-			"ReleaseScheduled",
+			shipperevents.ReleaseScheduled,
 			"Created InstallationTarget %q",
 			objectutil.MetaKey(updIt),
 		)
@@ -110,7 +163,7 @@ func (s *Scheduler) createInstallationTarget(rel *shipper.Release) (*shipper.Ins
 	return it, nil
 }
 
-func (s *Scheduler) createCapacityTarget(rel *shipper.Release, totalReplicaCount int32) (*shipper.CapacityTarget, error) {
+func (s *Scheduler) createCapacityTarget(span *tracing.Span, rel *shipper.Release, totalReplicaCount int32, workloads []shipper.CapacityTargetWorkload) (*shipper.CapacityTarget, error) {
 	ct, err := s.listers.capacityTargetLister.CapacityTargets(rel.GetNamespace()).Get(rel.GetName())
 	if err != nil {
 		if !errors.IsNotFound(err) {
@@ -124,11 +177,18 @@ func (s *Scheduler) createCapacityTarget(rel *shipper.Release, totalReplicaCount
 				Labels:    rel.Labels,
 			},
 			Spec: shipper.CapacityTargetSpec{
+				Application:       rel.Labels[shipper.AppLabel],
+				Release:           rel.Name,
 				TotalReplicaCount: totalReplicaCount,
+				Workloads:         workloads,
+				ScaleDownPolicy:   rel.Spec.Environment.ScaleDownPolicy,
+				WorkloadKind:      rel.Spec.Environment.WorkloadKind,
 			},
 		}
 
+		apiSpan := span.StartChild("Create CapacityTarget", tracing.String("shipper.key", objectutil.MetaKey(ct)))
 		updCt, err := s.clientset.ShipperV1alpha1().CapacityTargets(rel.GetNamespace()).Create(ct)
+		apiSpan.End(err)
 		if err != nil {
 			return nil, shippererrors.NewKubeclientCreateError(ct, err)
 		}
@@ -136,7 +196,7 @@ func (s *Scheduler) createCapacityTarget(rel *shipper.Release, totalReplicaCount
 		s.recorder.Eventf(
 			rel,
 			corev1.EventTypeNormal,
-			"ReleaseScheduled",
+			shipperevents.ReleaseScheduled,
 			"Created CapacityTarget %q",
 			objectutil.MetaKey(updCt),
 		)
@@ -147,7 +207,7 @@ func (s *Scheduler) createCapacityTarget(rel *shipper.Release, totalReplicaCount
 	return ct, nil
 }
 
-func (s *Scheduler) createTrafficTarget(rel *shipper.Release) (*shipper.TrafficTarget, error) {
+func (s *Scheduler) createTrafficTarget(span *tracing.Span, rel *shipper.Release) (*shipper.TrafficTarget, error) {
 	tt, err := s.listers.trafficTargetLister.TrafficTargets(rel.GetNamespace()).Get(rel.GetName())
 	if err != nil {
 		if !errors.IsNotFound(err) {
@@ -159,9 +219,15 @@ func (s *Scheduler) createTrafficTarget(rel *shipper.Release) (*shipper.TrafficT
 				Namespace: rel.Namespace,
 				Labels:    rel.Labels,
 			},
+			Spec: shipper.TrafficTargetSpec{
+				Backend:         s.trafficBackend,
+				TrafficDisabled: s.trafficDisabled,
+			},
 		}
 
+		apiSpan := span.StartChild("Create TrafficTarget", tracing.String("shipper.key", objectutil.MetaKey(tt)))
 		updTt, err := s.clientset.ShipperV1alpha1().TrafficTargets(rel.GetNamespace()).Create(tt)
+		apiSpan.End(err)
 		if err != nil {
 			return nil, shippererrors.NewKubeclientCreateError(tt, err)
 		}
@@ -169,7 +235,7 @@ func (s *Scheduler) createTrafficTarget(rel *shipper.Release) (*shipper.TrafficT
 		s.recorder.Eventf(
 			rel,
 			corev1.EventTypeNormal,
-			"ReleaseScheduled",
+			shipperevents.ReleaseScheduled,
 			"Created TrafficTarget %q",
 			objectutil.MetaKey(updTt),
 		)
@@ -177,34 +243,139 @@ func (s *Scheduler) createTrafficTarget(rel *shipper.Release) (*shipper.TrafficT
 		return updTt, nil
 	}
 
+	if tt.Spec.TrafficDisabled != s.trafficDisabled || tt.Spec.Backend != s.trafficBackend {
+		return s.patchTrafficTarget(span, tt)
+	}
+
 	return tt, nil
 }
 
-func (s *Scheduler) fetchChartAndExtractReplicaCount(rel *shipper.Release) (int32, error) {
-	chart, err := s.chartFetcher(&rel.Spec.Environment.Chart)
+// patchTrafficTarget brings an existing traffic target in line with its
+// cluster: its traffic backend, and whether it's in or out of maintenance.
+// The strategy never touches these, so they're patched on their own.
+func (s *Scheduler) patchTrafficTarget(span *tracing.Span, tt *shipper.TrafficTarget) (*shipper.TrafficTarget, error) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"backend":         s.trafficBackend,
+			"trafficDisabled": s.trafficDisabled,
+		},
+	}
+	b, _ := json.Marshal(patch)
+
+	apiSpan := span.StartChild("Patch TrafficTarget", tracing.String("shipper.key", objectutil.MetaKey(tt)))
+	updTt, err := s.clientset.ShipperV1alpha1().TrafficTargets(tt.Namespace).Patch(tt.Name, types.MergePatchType, b)
+	apiSpan.End(err)
 	if err != nil {
-		return 0, err
+		return nil, shippererrors.NewKubeclientPatchError(tt.Namespace, tt.Name, err).
+			WithShipperKind("TrafficTarget")
+	}
+
+	return updTt, nil
+}
+
+func (s *Scheduler) fetchChartAndExtractWorkloads(rel *shipper.Release) (int32, []shipper.CapacityTargetWorkload, error) {
+	return clusterWorkloads(s.chartFetcher, rel, s.clusterName)
+}
+
+// clusterReplicaCount returns the replica count of rel in a cluster, adding
+// up all of its Deployments.
+func clusterReplicaCount(chartFetcher shipperrepo.ChartFetcher, rel *shipper.Release, clusterName string) (int32, error) {
+	replicas, _, err := clusterWorkloads(chartFetcher, rel, clusterName)
+	return replicas, err
+}
+
+// clusterWorkloads returns the replica count of rel in a cluster: the one
+// recorded for it when replicas were spread, if any, or the one its chart
+// renders with the cluster's values otherwise. Charts with more than one
+// Deployment also get each of them as a capacity target workload, and their
+// replica count is the sum of theirs. For releases that run a Job, it's the
+// Job's parallelism.
+func clusterWorkloads(chartFetcher shipperrepo.ChartFetcher, rel *shipper.Release, clusterName string) (int32, []shipper.CapacityTargetWorkload, error) {
+	if replicas, ok := releaseutil.GetClusterReplicas(rel)[clusterName]; ok {
+		return replicas, nil, nil
 	}
 
-	replicas, err := extractReplicasFromChartForRel(chart, rel)
+	if releaseutil.RunsToCompletion(rel) {
+		parallelism, err := fetchChartAndExtractJobParallelism(chartFetcher, rel, releaseutil.GetClusterValues(rel, clusterName))
+		return parallelism, nil, err
+	}
+
+	workloads, err := fetchChartAndExtractWorkloads(chartFetcher, rel, releaseutil.GetClusterValues(rel, clusterName))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(workloads) == 1 {
+		return workloads[0].TotalReplicaCount, nil, nil
+	}
+
+	var replicas int32
+	for _, workload := range workloads {
+		replicas += workload.TotalReplicaCount
+	}
+
+	return replicas, workloads, nil
+}
+
+// fetchChartAndExtractReplicaCount returns the replica count of the only
+// Deployment in rel's chart.
+func fetchChartAndExtractReplicaCount(
+	chartFetcher shipperrepo.ChartFetcher,
+	rel *shipper.Release,
+	values shipper.ChartValues,
+) (int32, error) {
+	workloads, err := fetchChartAndExtractWorkloads(chartFetcher, rel, values)
 	if err != nil {
 		return 0, err
 	}
 
-	return int32(replicas), nil
+	if len(workloads) != 1 {
+		return 0, shippererrors.NewWrongChartDeploymentsError(
+			&rel.Spec.Environment.Chart,
+			"exactly 1",
+			len(workloads),
+		)
+	}
+
+	return workloads[0].TotalReplicaCount, nil
 }
 
-func extractReplicasFromChartForRel(chart *helmchart.Chart, rel *shipper.Release) (int32, error) {
-	applicationName, err := objectutil.GetApplicationLabel(rel)
+func fetchChartAndExtractWorkloads(
+	chartFetcher shipperrepo.ChartFetcher,
+	rel *shipper.Release,
+	values shipper.ChartValues,
+) ([]shipper.CapacityTargetWorkload, error) {
+	chart, err := chartFetcher(&rel.Spec.Environment.Chart)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractWorkloadsFromChartForRel(chart, rel, values)
+}
+
+// fetchChartAndExtractJobParallelism returns the parallelism of the only
+// Job in rel's chart, step hooks aside.
+func fetchChartAndExtractJobParallelism(
+	chartFetcher shipperrepo.ChartFetcher,
+	rel *shipper.Release,
+	values shipper.ChartValues,
+) (int32, error) {
+	chart, err := chartFetcher(&rel.Spec.Environment.Chart)
 	if err != nil {
 		return 0, err
 	}
 
+	return extractJobParallelismFromChartForRel(chart, rel, values)
+}
+
+func extractJobParallelismFromChartForRel(chart *helmchart.Chart, rel *shipper.Release, values shipper.ChartValues) (int32, error) {
+	values = valuesource.WithPlaceholders(values, rel.Spec.Environment.ValuesFrom)
+
 	rendered, err := shipperchart.Render(
 		chart,
-		applicationName,
+		rel.Name,
 		rel.Namespace,
-		&rel.Spec.Environment.Values)
+		&values)
 
 	if err != nil {
 		return 0, shippererrors.NewBrokenChartSpecError(
@@ -213,20 +384,68 @@ func extractReplicasFromChartForRel(chart *helmchart.Chart, rel *shipper.Release
 		)
 	}
 
+	jobs := shipperchart.GetJobs(rendered)
+	if len(jobs) != 1 {
+		return 0, shippererrors.NewWrongChartJobsError(
+			&rel.Spec.Environment.Chart,
+			len(jobs),
+		)
+	}
+
+	// Jobs default to a parallelism of 1 when it's unspecified. See
+	// k8s.io/api/batch/v1/types.go's JobSpec.
+	if parallelism := jobs[0].Spec.Parallelism; parallelism != nil {
+		return *parallelism, nil
+	}
+
+	return 1, nil
+}
+
+func extractWorkloadsFromChartForRel(chart *helmchart.Chart, rel *shipper.Release, values shipper.ChartValues) ([]shipper.CapacityTargetWorkload, error) {
+	// Secrets are only ever read in application clusters, at install
+	// time, so they can't change the replica count.
+	values = valuesource.WithPlaceholders(values, rel.Spec.Environment.ValuesFrom)
+
+	// The chart is rendered under the release's name, just like the
+	// installation controller does, so Deployments get the names they'll
+	// be installed with.
+	rendered, err := shipperchart.Render(
+		chart,
+		rel.Name,
+		rel.Namespace,
+		&values)
+
+	if err != nil {
+		return nil, shippererrors.NewBrokenChartSpecError(
+			&rel.Spec.Environment.Chart,
+			err,
+		)
+	}
+
 	deployments := shipperchart.GetDeployments(rendered)
-	if len(deployments) != 1 {
-		return 0, shippererrors.NewWrongChartDeploymentsError(
+	if len(deployments) == 0 {
+		return nil, shippererrors.NewWrongChartDeploymentsError(
 			&rel.Spec.Environment.Chart,
+			"at least 1",
 			len(deployments),
 		)
 	}
 
-	replicas := deployments[0].Spec.Replicas
-	// Deployments default to 1 replica when replicas is nil or unspecified. See
-	// k8s.io/api/apps/v1/types.go's DeploymentSpec.
-	if replicas == nil {
-		return 1, nil
+	workloads := make([]shipper.CapacityTargetWorkload, 0, len(deployments))
+	for _, deployment := range deployments {
+		// Deployments default to 1 replica when replicas is nil or
+		// unspecified. See k8s.io/api/apps/v1/types.go's
+		// DeploymentSpec.
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		workloads = append(workloads, shipper.CapacityTargetWorkload{
+			Name:              deployment.Name,
+			TotalReplicaCount: replicas,
+		})
 	}
 
-	return int32(*replicas), nil
+	return workloads, nil
 }
@@ -1,15 +1,20 @@
 package release
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
 )
 
@@ -43,7 +48,10 @@ func newScheduler(
 		clientset,
 		listers,
 		shippertesting.LocalFetchChart,
-		record.NewFakeRecorder(42))
+		record.NewFakeRecorder(42),
+		"",
+		false,
+		shippertesting.TestCluster)
 
 	stopCh := make(chan struct{})
 	defer close(stopCh)
@@ -65,10 +73,175 @@ func TestCreateAssociatedObjects(t *testing.T) {
 	expectedActions := buildExpectedActions(release, clusters)
 
 	c, clientset := newScheduler(nil)
-	if _, err := c.ScheduleRelease(release); err != nil {
+	if _, err := c.ScheduleRelease(nil, release); err != nil {
 		t.Fatal(err)
 	}
 
 	filteredActions := shippertesting.FilterActions(clientset.Actions())
 	shippertesting.CheckActions(expectedActions, filteredActions, t)
 }
+
+// TestGetClusterTrafficBackend checks that clusters can choose a traffic
+// backend either through an annotation or a capability, and that the
+// annotation takes precedence.
+func TestGetClusterTrafficBackend(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		capabilities []string
+		expected     string
+	}{
+		{
+			name:     "no preference",
+			expected: "",
+		},
+		{
+			name: "annotation",
+			annotations: map[string]string{
+				shipper.ClusterTrafficBackendAnnotation: shipper.TrafficBackendExternalLB,
+			},
+			expected: shipper.TrafficBackendExternalLB,
+		},
+		{
+			name:         "capability",
+			capabilities: []string{"gpu", shipper.ClusterTrafficBackendCapability + shipper.TrafficBackendIstio},
+			expected:     shipper.TrafficBackendIstio,
+		},
+		{
+			name: "annotation takes precedence over capability",
+			annotations: map[string]string{
+				shipper.ClusterTrafficBackendAnnotation: shipper.TrafficBackendPodLabel,
+			},
+			capabilities: []string{shipper.ClusterTrafficBackendCapability + shipper.TrafficBackendIstio},
+			expected:     shipper.TrafficBackendPodLabel,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := buildCluster("minikube-a")
+		cluster.Annotations = tt.annotations
+		cluster.Spec.Capabilities = append(cluster.Spec.Capabilities, tt.capabilities...)
+
+		backend := getClusterTrafficBackend(cluster)
+		if backend != tt.expected {
+			t.Errorf("%s: expected traffic backend %q, got %q", tt.name, tt.expected, backend)
+		}
+	}
+}
+
+// TestScheduleReleaseTrafficDisabled checks that taking a cluster out of
+// traffic, and putting it back, is reflected on existing traffic targets.
+func TestScheduleReleaseTrafficDisabled(t *testing.T) {
+	clusters := []*shipper.Cluster{buildCluster("minikube-a")}
+	release := buildReleaseForSchedulerTest(clusters)
+	it, tt, ct := buildAssociatedObjects(release, clusters)
+
+	c, clientset := newScheduler([]runtime.Object{it, tt, ct})
+
+	for _, disabled := range []bool{true, false} {
+		c.trafficDisabled = disabled
+
+		relinfo, err := c.ScheduleRelease(nil, release)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if relinfo.trafficTarget.Spec.TrafficDisabled != disabled {
+			t.Errorf("expected returned TrafficTarget to have trafficDisabled %t", disabled)
+		}
+
+		updTt, err := clientset.ShipperV1alpha1().TrafficTargets(tt.Namespace).Get(tt.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updTt.Spec.TrafficDisabled != disabled {
+			t.Errorf("expected TrafficTarget to have trafficDisabled %t", disabled)
+		}
+
+		// The scheduler works off listers, which the fake clientset
+		// doesn't keep up to date on its own.
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+		indexer.Add(updTt)
+		c.listers.trafficTargetLister = shipperlisters.NewTrafficTargetLister(indexer)
+	}
+}
+
+// TestScheduleReleaseTrafficBackend checks that a change of the traffic
+// backend a cluster wants is reflected on existing traffic targets.
+func TestScheduleReleaseTrafficBackend(t *testing.T) {
+	clusters := []*shipper.Cluster{buildCluster("minikube-a")}
+	release := buildReleaseForSchedulerTest(clusters)
+	it, tt, ct := buildAssociatedObjects(release, clusters)
+
+	c, clientset := newScheduler([]runtime.Object{it, tt, ct})
+
+	for _, backend := range []string{shipper.TrafficBackendExternalLB, shipper.TrafficBackendIstio, ""} {
+		c.trafficBackend = backend
+
+		relinfo, err := c.ScheduleRelease(nil, release)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if relinfo.trafficTarget.Spec.Backend != backend {
+			t.Errorf("expected returned TrafficTarget to have backend %q, got %q", backend, relinfo.trafficTarget.Spec.Backend)
+		}
+
+		updTt, err := clientset.ShipperV1alpha1().TrafficTargets(tt.Namespace).Get(tt.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updTt.Spec.Backend != backend {
+			t.Errorf("expected TrafficTarget to have backend %q, got %q", backend, updTt.Spec.Backend)
+		}
+
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+		indexer.Add(updTt)
+		c.listers.trafficTargetLister = shipperlisters.NewTrafficTargetLister(indexer)
+	}
+}
+
+// TestExtractWorkloadsFromChart checks that every Deployment in a chart
+// becomes a capacity target workload, under the name it'll be installed
+// with.
+func TestExtractWorkloadsFromChart(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-%s
+spec:
+  %s
+`
+	chart := &helmchart.Chart{
+		Metadata: &helmchart.Metadata{Name: "multi", Version: "0.0.1"},
+		Templates: []*helmchart.Template{
+			{Name: "templates/web.yaml", Data: []byte(fmt.Sprintf(deployment, "web", "replicas: {{ .Values.replicaCount }}"))},
+			{Name: "templates/worker.yaml", Data: []byte(fmt.Sprintf(deployment, "worker", "paused: false"))},
+		},
+		Values: &helmchart.Config{},
+	}
+
+	release := buildReleaseForSchedulerTest(nil)
+	values := shipper.ChartValues{"replicaCount": float64(3)}
+
+	workloads, err := extractWorkloadsFromChartForRel(chart, release, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []shipper.CapacityTargetWorkload{
+		{Name: release.Name + "-web", TotalReplicaCount: 3},
+		{Name: release.Name + "-worker", TotalReplicaCount: 1},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, workloads)
+	if !eq {
+		t.Fatalf("unexpected workloads:\n%s", diff)
+	}
+}
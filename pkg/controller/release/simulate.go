@@ -0,0 +1,126 @@
+package release
+
+import (
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
+	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
+	"github.com/bookingcom/shipper/pkg/util/replicas"
+)
+
+// Simulation is what rolling out a release would do, worked out without
+// creating or changing any object.
+type Simulation struct {
+	// Clusters are the clusters the release would be scheduled on.
+	Clusters []string
+	Steps    []SimulatedStep
+}
+
+type SimulatedStep struct {
+	Name string
+	// ContenderWeight and IncumbentWeight are the traffic weights the
+	// releases get in every cluster.
+	ContenderWeight int32
+	IncumbentWeight int32
+	Clusters        []SimulatedClusterStep
+}
+
+type SimulatedClusterStep struct {
+	Name              string
+	ContenderReplicas int32
+	IncumbentReplicas int32
+}
+
+// Simulate chooses clusters for rel among clusters as the release controller
+// would, and works out the replicas and traffic weights the contender and
+// the incumbent, if any, get in each of them at every step of rel's
+// strategy. releases are the releases in rel's namespace, for application
+// affinities. Incumbent floors and the APIs clusters serve are not taken
+// into account.
+func Simulate(
+	chartFetcher shipperrepo.ChartFetcher,
+	rel, incumbent *shipper.Release,
+	clusters []*shipper.Cluster,
+	releases []*shipper.Release,
+) (*Simulation, error) {
+	clusterIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, cluster := range clusters {
+		if err := clusterIndexer.Add(cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	releaseIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	for _, r := range releases {
+		if err := releaseIndexer.Add(r); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Controller{
+		clusterLister: shipperlisters.NewClusterLister(clusterIndexer),
+		releaseLister: shipperlisters.NewReleaseLister(releaseIndexer),
+		chartFetcher:  chartFetcher,
+		recorder:      &record.FakeRecorder{},
+	}
+
+	rel, clusterNames, err := c.chooseClusters(rel.DeepCopy())
+	if err != nil {
+		return nil, err
+	}
+
+	contenderTotals := make(map[string]int32, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		contenderTotals[clusterName], err = clusterReplicaCount(chartFetcher, rel, clusterName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The incumbent is also scaled down on clusters the contender isn't
+	// scheduled on.
+	allClusters := append([]string{}, clusterNames...)
+	incumbentTotals := make(map[string]int32)
+	if incumbent != nil {
+		for _, clusterName := range releaseutil.GetSelectedClusters(incumbent) {
+			incumbentTotals[clusterName], err = clusterReplicaCount(chartFetcher, incumbent, clusterName)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := contenderTotals[clusterName]; !ok {
+				allClusters = append(allClusters, clusterName)
+			}
+		}
+	}
+
+	simulation := &Simulation{Clusters: clusterNames}
+	for _, step := range rel.Spec.Environment.Strategy.Steps {
+		simulated := SimulatedStep{
+			Name:            step.Name,
+			ContenderWeight: step.Traffic.Contender,
+			IncumbentWeight: step.Traffic.Incumbent,
+		}
+
+		for _, clusterName := range allClusters {
+			simulated.Clusters = append(simulated.Clusters, SimulatedClusterStep{
+				Name:              clusterName,
+				ContenderReplicas: percentOf(contenderTotals[clusterName], step.Capacity.Contender),
+				IncumbentReplicas: percentOf(incumbentTotals[clusterName], step.Capacity.Incumbent),
+			})
+		}
+
+		simulation.Steps = append(simulation.Steps, simulated)
+	}
+
+	return simulation, nil
+}
+
+func percentOf(total, percent int32) int32 {
+	return int32(replicas.CalculateDesiredReplicaCount(uint(total), float64(percent)))
+}
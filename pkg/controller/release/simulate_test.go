@@ -0,0 +1,73 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestSimulate(t *testing.T) {
+	incumbent := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "incumbent", 2)
+	incumbent.Annotations[shipper.ReleaseClustersAnnotation] = "cluster-a,cluster-b"
+	incumbent.Spec.ClusterReplicas = []shipper.ClusterReplicas{
+		{Name: "cluster-a", Replicas: 4},
+		{Name: "cluster-b", Replicas: 4},
+	}
+
+	rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, "contender", 1)
+	original := rel.DeepCopy()
+
+	clusters := []*shipper.Cluster{buildCluster("cluster-a")}
+	releases := []*shipper.Release{incumbent}
+
+	simulation, err := Simulate(shippertesting.LocalFetchChart, rel, incumbent, clusters, releases)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(simulation.Clusters) != 1 || simulation.Clusters[0] != "cluster-a" {
+		t.Fatalf("expected release to be scheduled on cluster-a, got %v", simulation.Clusters)
+	}
+
+	// The simple chart has 12 replicas.
+	expected := []SimulatedStep{
+		{
+			Name:            "staging",
+			ContenderWeight: 0,
+			IncumbentWeight: 100,
+			Clusters: []SimulatedClusterStep{
+				{Name: "cluster-a", ContenderReplicas: 1, IncumbentReplicas: 4},
+				{Name: "cluster-b", ContenderReplicas: 0, IncumbentReplicas: 4},
+			},
+		},
+		{
+			Name:            "50/50",
+			ContenderWeight: 50,
+			IncumbentWeight: 50,
+			Clusters: []SimulatedClusterStep{
+				{Name: "cluster-a", ContenderReplicas: 6, IncumbentReplicas: 2},
+				{Name: "cluster-b", ContenderReplicas: 0, IncumbentReplicas: 2},
+			},
+		},
+		{
+			Name:            "full on",
+			ContenderWeight: 100,
+			IncumbentWeight: 0,
+			Clusters: []SimulatedClusterStep{
+				{Name: "cluster-a", ContenderReplicas: 12, IncumbentReplicas: 0},
+				{Name: "cluster-b", ContenderReplicas: 0, IncumbentReplicas: 0},
+			},
+		},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, simulation.Steps)
+	if !eq {
+		t.Errorf("simulated steps differ from expected:\n%s", diff)
+	}
+
+	eq, diff = shippertesting.DeepEqualDiff(original, rel)
+	if !eq {
+		t.Errorf("simulating must not modify the release:\n%s", diff)
+	}
+}
@@ -0,0 +1,183 @@
+package release
+
+import (
+	"sort"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// validateClusterSpread checks that a spread carries the settings its
+// strategy needs.
+func validateClusterSpread(spread *shipper.ClusterSpread) error {
+	if spread == nil {
+		return nil
+	}
+
+	switch spread.Strategy {
+	case shipper.ClusterSpreadEven, shipper.ClusterSpreadWeighted:
+	case shipper.ClusterSpreadBinPack:
+		if spread.MaxReplicasPerCluster == nil || *spread.MaxReplicasPerCluster < 1 {
+			return shippererrors.NewInvalidClusterSpreadError(
+				"strategy %s requires maxReplicasPerCluster of at least 1", spread.Strategy)
+		}
+	case shipper.ClusterSpreadPinned:
+		if len(spread.Pinned) == 0 {
+			return shippererrors.NewInvalidClusterSpreadError(
+				"strategy %s requires at least one pinned cluster", spread.Strategy)
+		}
+	default:
+		return shippererrors.NewInvalidClusterSpreadError(
+			"unknown strategy %q", spread.Strategy)
+	}
+
+	return nil
+}
+
+// pinnedClusters filters a preference list down to the clusters pinned by
+// spread, keeping their order.
+func pinnedClusters(spread *shipper.ClusterSpread, prefList []*shipper.Cluster) []*shipper.Cluster {
+	pinned := make(map[string]struct{}, len(spread.Pinned))
+	for _, p := range spread.Pinned {
+		pinned[p.Name] = struct{}{}
+	}
+
+	clusters := make([]*shipper.Cluster, 0, len(spread.Pinned))
+	for _, cluster := range prefList {
+		if _, ok := pinned[cluster.Name]; ok {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters
+}
+
+// spreadReplicas splits replicaCount between the clusters of each region
+// according to spread, returning how many replicas each cluster should run.
+// Clusters are considered in the application's preference list order, which
+// decides who gets remainders and which clusters BinPack fills first.
+// Clusters BinPack does not need are left out of the result.
+func spreadReplicas(
+	app string,
+	spread *shipper.ClusterSpread,
+	clusters []*shipper.Cluster,
+	replicaCount int32,
+) map[string]int32 {
+	replicas := make(map[string]int32, len(clusters))
+
+	if spread.Strategy == shipper.ClusterSpreadPinned {
+		for _, p := range spread.Pinned {
+			if p.Replicas != nil {
+				replicas[p.Name] = *p.Replicas
+			} else {
+				replicas[p.Name] = replicaCount
+			}
+		}
+		return replicas
+	}
+
+	byRegion := map[string][]*shipper.Cluster{}
+	for _, cluster := range clusters {
+		byRegion[cluster.Spec.Region] = append(byRegion[cluster.Spec.Region], cluster)
+	}
+
+	for _, regionClusters := range byRegion {
+		prefList := buildPrefList(app, regionClusters)
+
+		var shares []int32
+		switch spread.Strategy {
+		case shipper.ClusterSpreadWeighted:
+			shares = spreadWeighted(prefList, replicaCount)
+		case shipper.ClusterSpreadBinPack:
+			shares = spreadBinPack(len(prefList), replicaCount, *spread.MaxReplicasPerCluster)
+		default:
+			shares = spreadEven(len(prefList), replicaCount)
+		}
+
+		for i, cluster := range prefList {
+			if spread.Strategy == shipper.ClusterSpreadBinPack && shares[i] == 0 {
+				continue
+			}
+			replicas[cluster.Name] = shares[i]
+		}
+	}
+
+	return replicas
+}
+
+func spreadEven(n int, replicaCount int32) []int32 {
+	shares := make([]int32, n)
+	for i := range shares {
+		shares[i] = replicaCount / int32(n)
+		if int32(i) < replicaCount%int32(n) {
+			shares[i]++
+		}
+	}
+
+	return shares
+}
+
+// spreadWeighted splits replicas in proportion to cluster weights, handing
+// out what is left after rounding down to the clusters with the largest
+// fractional share.
+func spreadWeighted(prefList []*shipper.Cluster, replicaCount int32) []int32 {
+	weights := make([]int64, len(prefList))
+	var totalWeight int64
+	for i, cluster := range prefList {
+		weights[i] = defaultClusterWeight
+		if cluster.Spec.Scheduler.Weight != nil {
+			weights[i] = int64(*cluster.Spec.Scheduler.Weight)
+		}
+		totalWeight += weights[i]
+	}
+
+	if totalWeight == 0 {
+		return spreadEven(len(prefList), replicaCount)
+	}
+
+	shares := make([]int32, len(prefList))
+	remainders := make([]int64, len(prefList))
+	assigned := int32(0)
+	for i, weight := range weights {
+		shares[i] = int32(int64(replicaCount) * weight / totalWeight)
+		remainders[i] = int64(replicaCount) * weight % totalWeight
+		assigned += shares[i]
+	}
+
+	order := make([]int, len(prefList))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+
+	for i := 0; assigned < replicaCount; i++ {
+		shares[order[i%len(order)]]++
+		assigned++
+	}
+
+	return shares
+}
+
+// spreadBinPack fills clusters up to maxPerCluster one after the other. If
+// there are not enough clusters to stay under the limit, the excess is spread
+// evenly over all of them.
+func spreadBinPack(n int, replicaCount, maxPerCluster int32) []int32 {
+	shares := make([]int32, n)
+	remaining := replicaCount
+	for i := range shares {
+		if remaining < maxPerCluster {
+			shares[i] = remaining
+		} else {
+			shares[i] = maxPerCluster
+		}
+		remaining -= shares[i]
+	}
+
+	for i, extra := range spreadEven(n, remaining) {
+		shares[i] += extra
+	}
+
+	return shares
+}
@@ -0,0 +1,95 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestSpreadReplicas(t *testing.T) {
+	clusterWithWeight := func(name, region string, weight int32) *shipper.Cluster {
+		cluster := generateClusterForTestCase(0, shipper.ClusterSpec{
+			Region:    region,
+			Scheduler: shipper.ClusterSchedulerSettings{Weight: pint32(weight)},
+		})
+		cluster.Name = name
+		return cluster
+	}
+
+	clusters := []*shipper.Cluster{
+		clusterWithWeight("a", "eu", 100),
+		clusterWithWeight("b", "eu", 300),
+		clusterWithWeight("c", "us", 100),
+	}
+
+	tests := []struct {
+		name     string
+		spread   shipper.ClusterSpread
+		replicas int32
+		expected map[string]int32
+	}{
+		{
+			name:     "even splits replicas within each region",
+			spread:   shipper.ClusterSpread{Strategy: shipper.ClusterSpreadEven},
+			replicas: 10,
+			expected: map[string]int32{"a": 5, "b": 5, "c": 10},
+		},
+		{
+			name:     "weighted splits replicas by cluster weight",
+			spread:   shipper.ClusterSpread{Strategy: shipper.ClusterSpreadWeighted},
+			replicas: 8,
+			expected: map[string]int32{"a": 2, "b": 6, "c": 8},
+		},
+		{
+			name: "bin pack leaves out unneeded clusters",
+			spread: shipper.ClusterSpread{
+				Strategy:              shipper.ClusterSpreadBinPack,
+				MaxReplicasPerCluster: pint32(10),
+			},
+			replicas: 4,
+		},
+		{
+			name: "pinned uses listed replicas and defaults to the chart's",
+			spread: shipper.ClusterSpread{
+				Strategy: shipper.ClusterSpreadPinned,
+				Pinned: []shipper.PinnedCluster{
+					{Name: "a", Replicas: pint32(1)},
+					{Name: "c"},
+				},
+			},
+			replicas: 4,
+			expected: map[string]int32{"a": 1, "c": 4},
+		},
+	}
+
+	for _, tt := range tests {
+		replicas := spreadReplicas("test-application", &tt.spread, clusters, tt.replicas)
+
+		// Bin packing picks clusters in preference list order, so
+		// only check totals per region and the number of clusters
+		// used.
+		if tt.spread.Strategy == shipper.ClusterSpreadBinPack {
+			var eu int32
+			for _, name := range []string{"a", "b"} {
+				eu += replicas[name]
+			}
+			if eu != tt.replicas || replicas["c"] != tt.replicas || len(replicas) != 2 {
+				t.Errorf("%s: expected one cluster per region holding %d replicas, got %v", tt.name, tt.replicas, replicas)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(replicas, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, replicas)
+		}
+	}
+}
+
+func TestSpreadBinPackOverflow(t *testing.T) {
+	shares := spreadBinPack(3, 10, 3)
+	expected := []int32{4, 3, 3}
+	if !reflect.DeepEqual(shares, expected) {
+		t.Errorf("expected %v, got %v", expected, shares)
+	}
+}
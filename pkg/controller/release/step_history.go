@@ -0,0 +1,40 @@
+package release
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// recordStepHistory adds an entry to history when a release is asked to move
+// to a step other than the one it was last working on, and marks the step as
+// achieved the first time it is. Going back to an earlier step, like on
+// rollbacks, starts a new entry rather than reusing the old one, so the
+// history reads as a timeline.
+func recordStepHistory(
+	history []shipper.ReleaseStepHistory,
+	step int32,
+	name string,
+	achieved bool,
+	now time.Time,
+) []shipper.ReleaseStepHistory {
+	history = append([]shipper.ReleaseStepHistory(nil), history...)
+
+	if len(history) == 0 || history[len(history)-1].Step != step {
+		history = append(history, shipper.ReleaseStepHistory{
+			Step:      step,
+			Name:      name,
+			StartedAt: metav1.NewTime(now),
+		})
+	}
+
+	last := &history[len(history)-1]
+	if achieved && last.AchievedAt == nil {
+		achievedAt := metav1.NewTime(now)
+		last.AchievedAt = &achievedAt
+	}
+
+	return history
+}
@@ -0,0 +1,53 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestRecordStepHistory(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) metav1.Time {
+		return metav1.NewTime(start.Add(time.Duration(minutes) * time.Minute))
+	}
+	atp := func(minutes int) *metav1.Time {
+		t := at(minutes)
+		return &t
+	}
+
+	syncs := []struct {
+		step     int32
+		achieved bool
+	}{
+		{0, false},
+		{0, true},
+		{0, true},
+		{1, false},
+		{1, true},
+		// Rolling back to the first step starts it over.
+		{0, false},
+		{0, true},
+	}
+
+	var history []shipper.ReleaseStepHistory
+	for i, sync := range syncs {
+		name := vanguard.Steps[sync.step].Name
+		history = recordStepHistory(history, sync.step, name, sync.achieved, at(i).Time)
+	}
+
+	expected := []shipper.ReleaseStepHistory{
+		{Step: 0, Name: "staging", StartedAt: at(0), AchievedAt: atp(1)},
+		{Step: 1, Name: "50/50", StartedAt: at(3), AchievedAt: atp(4)},
+		{Step: 0, Name: "staging", StartedAt: at(5), AchievedAt: atp(6)},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, history)
+	if !eq {
+		t.Fatalf("unexpected step history:\n%s", diff)
+	}
+}
@@ -23,16 +23,38 @@ const (
 )
 
 type context struct {
-	release *shipper.Release
-	step    int32
-	isHead  bool
+	release        *shipper.Release
+	step           int32
+	isHead         bool
+	incumbentFloor *shipper.IncumbentFloor
+
+	// incumbentStandby is the standby the incumbent is kept on, if it's
+	// still on one.
+	incumbentStandby *shipper.IncumbentStandby
+
+	// bucketing is how the traffic of experiments is split between
+	// releases, if the strategy is one.
+	bucketing *shipper.ExperimentBucketing
+
+	// capacityOverride is the capacity a FleetCapacityOverride scales
+	// releases to, if there's one.
+	capacityOverride *int32
+
+	// totalCapacityHold is how the contender is held back to fit next
+	// to its incumbent in the cluster, if it is.
+	totalCapacityHold *totalCapacityHold
 }
 
 func (ctx *context) Copy() *context {
 	return &context{
-		release: ctx.release,
-		step:    ctx.step,
-		isHead:  ctx.isHead,
+		release:           ctx.release,
+		step:              ctx.step,
+		isHead:            ctx.isHead,
+		incumbentFloor:    ctx.incumbentFloor,
+		incumbentStandby:  ctx.incumbentStandby,
+		bucketing:         ctx.bucketing,
+		capacityOverride:  ctx.capacityOverride,
+		totalCapacityHold: ctx.totalCapacityHold,
 	}
 }
 
@@ -62,8 +84,21 @@ func (p *Pipeline) Process(strategyStep shipper.RolloutStrategyStep, cond condit
 }
 
 type StrategyExecutor struct {
-	strategy *shipper.RolloutStrategy
-	step     int32
+	strategy         *shipper.RolloutStrategy
+	step             int32
+	capacityOverride *int32
+
+	// onStandby is whether the incumbent is kept on the strategy's
+	// standby.
+	onStandby bool
+
+	// instantRollback is whether the head is being rolled back to its
+	// incumbent all at once.
+	instantRollback bool
+
+	// maxTotalCapacity is the maxTotalCapacityPercent of the cluster the
+	// strategy is being executed in.
+	maxTotalCapacity *int32
 }
 
 func NewStrategyExecutor(strategy *shipper.RolloutStrategy, step int32) (*StrategyExecutor, error) {
@@ -101,34 +136,90 @@ func (e *StrategyExecutor) Execute(prev, curr, succ *releaseInfo) (conditions.St
 	}
 
 	ctx := &context{
-		release: curr.release,
-		step:    e.step,
-		isHead:  isHead,
+		release:          curr.release,
+		step:             e.step,
+		isHead:           isHead,
+		incumbentFloor:   e.strategy.IncumbentFloor,
+		capacityOverride: e.capacityOverride,
+	}
+	if e.strategy.Experiment != nil {
+		ctx.bucketing = &e.strategy.Experiment.Bucketing
+	}
+	if e.onStandby {
+		ctx.incumbentStandby = e.strategy.IncumbentStandby
 	}
 
+	strategyStep := e.strategy.Steps[e.step]
+
 	pipeline := NewPipeline()
 	pipeline.Enqueue(genInstallationEnforcer(ctx, curr, succ))
 
-	if isHead {
+	// An instant rollback scales the incumbent up first, then moves all
+	// the traffic back to it at once, and only then scales the contender
+	// down, whatever the order of the step.
+	if hasTail && e.instantRollback {
+		prevctx := ctx.Copy()
+		prevctx.isHead = false
+		pipeline.Enqueue(genCapacityEnforcer(prevctx, prev, curr))
+		pipeline.Enqueue(genParallelEnforcer(
+			genTrafficEnforcer(prevctx, prev, curr),
+			genTrafficEnforcer(ctx, curr, succ),
+		))
 		pipeline.Enqueue(genCapacityEnforcer(ctx, curr, succ))
-		pipeline.Enqueue(genTrafficEnforcer(ctx, curr, succ))
+
+		return pipeline.Process(strategyStep, conditions.NewStrategyConditions())
+	}
+
+	if hasTail {
+		ctx.totalCapacityHold = holdForMaxTotalCapacity(
+			e.maxTotalCapacity, strategyStep, curr, prev)
+	}
+
+	// A contender held back to fit in the cluster always gets its capacity
+	// before its traffic, so that it never gets more traffic than it can
+	// handle, whatever the order of the step.
+	held := ctx.totalCapacityHold != nil
+
+	var enforcers []PipelineStep
+	if isHead {
+		capacityEnforcer := genCapacityEnforcer(ctx, curr, succ)
+		trafficEnforcer := genTrafficEnforcer(ctx, curr, succ)
+		if strategyStep.Order == shipper.RolloutStrategyStepTrafficFirst && !held {
+			enforcers = append(enforcers, trafficEnforcer, capacityEnforcer)
+		} else {
+			enforcers = append(enforcers, capacityEnforcer, trafficEnforcer)
+		}
 		if hasTail {
 			// This is the moment where a contender is performing a look-behind.
 			// Incumbent's context is completely identical to it's successor
-			// except that it's not the head of the chain anymore.
+			// except that it's not the head of the chain anymore. The
+			// incumbent always gives away traffic before capacity, no
+			// matter the order: it should never receive more traffic than
+			// it can handle.
 			prevctx := ctx.Copy()
 			prevctx.isHead = false
-			pipeline.Enqueue(genTrafficEnforcer(prevctx, prev, curr))
-			pipeline.Enqueue(genCapacityEnforcer(prevctx, prev, curr))
+			enforcers = append(enforcers,
+				genTrafficEnforcer(prevctx, prev, curr),
+				genCapacityEnforcer(prevctx, prev, curr),
+			)
 		}
 	} else {
-		pipeline.Enqueue(genTrafficEnforcer(ctx, curr, succ))
-		pipeline.Enqueue(genCapacityEnforcer(ctx, curr, succ))
+		enforcers = append(enforcers,
+			genTrafficEnforcer(ctx, curr, succ),
+			genCapacityEnforcer(ctx, curr, succ),
+		)
+	}
+
+	if strategyStep.Order == shipper.RolloutStrategyStepParallel && !held {
+		pipeline.Enqueue(genParallelEnforcer(enforcers...))
+	} else {
+		for _, enforcer := range enforcers {
+			pipeline.Enqueue(enforcer)
+		}
 	}
 
 	var releaseStrategyConditions []shipper.ReleaseStrategyCondition
 	cond := conditions.NewStrategyConditions(releaseStrategyConditions...)
-	strategyStep := e.strategy.Steps[e.step]
 
 	return pipeline.Process(strategyStep, cond)
 }
@@ -163,6 +254,25 @@ func genInstallationEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep
 	}
 }
 
+// genParallelEnforcer runs all the enforcers it's given, collecting their
+// patches, instead of stopping at the first one that hasn't achieved its
+// target. The pipeline only continues once every enforcer has.
+func genParallelEnforcer(enforcers ...PipelineStep) PipelineStep {
+	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch) {
+		var patches []StrategyPatch
+		var cont PipelineContinuation = PipelineContinue
+		for _, enforcer := range enforcers {
+			stepcont, steppatches := enforcer(strategyStep, cond)
+			patches = append(patches, steppatches...)
+			if stepcont == PipelineBreak {
+				cont = PipelineBreak
+			}
+		}
+
+		return cont, patches
+	}
+}
+
 func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch) {
 		var condType shipper.StrategyConditionType
@@ -175,11 +285,29 @@ func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 		} else {
 			condType = shipper.StrategyConditionIncumbentAchievedCapacity
 		}
+		var floorMsg, heldMsg, totalMsg string
+		hold := ctx.totalCapacityHold
 		if isHead {
 			capacityWeight = strategyStep.Capacity.Contender
+			if hold != nil {
+				capacityWeight, totalMsg = hold.capacity, hold.msg
+			}
 		} else {
-			capacityWeight = strategyStep.Capacity.Incumbent
+			capacityWeight, floorMsg = applyIncumbentFloor(
+				ctx.incumbentFloor, strategyStep.Capacity.Incumbent, curr, succ, time.Now())
+			capacityWeight = applyIncumbentStandby(ctx.incumbentStandby, capacityWeight, curr)
+			capacityWeight, heldMsg = applyMaxUnavailable(
+				strategyStep.MaxUnavailable, capacityWeight, curr, succ)
+
+			// The incumbent keeps the capacity for the traffic it keeps
+			// while the contender is held back.
+			if hold != nil {
+				if needed := heldIncumbentCapacity(strategyStep, hold.capacity); capacityWeight < needed {
+					capacityWeight = needed
+				}
+			}
 		}
+		capacityWeight = overriddenCapacity(ctx.capacityOverride, capacityWeight)
 
 		if achieved, newSpec, reason := checkCapacity(curr.capacityTarget, capacityWeight); !achieved {
 			klog.Infof("Release %q %s", objectutil.MetaKey(curr.release), "hasn't achieved capacity yet")
@@ -206,6 +334,56 @@ func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 			return PipelineBreak, patches
 		}
 
+		// Once the contender has all the traffic, the step can't be
+		// achieved before the incumbent is allowed below its floor.
+		if floorMsg != "" && givesAllTraffic(strategyStep) {
+			cond.SetFalse(
+				condType,
+				conditions.StrategyConditionsUpdate{
+					Reason:             IncumbentFloorHeld,
+					Message:            floorMsg,
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+				},
+			)
+
+			return PipelineBreak, nil
+		}
+
+		// The step can't be achieved either while the incumbent is kept
+		// up for the contender to make up for it.
+		if heldMsg != "" {
+			cond.SetFalse(
+				condType,
+				conditions.StrategyConditionsUpdate{
+					Reason:             MaxUnavailableHeld,
+					Message:            heldMsg,
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+				},
+			)
+
+			return PipelineBreak, nil
+		}
+
+		// A contender held back to fit in the cluster can't achieve the
+		// step, but the rest of it goes on with the traffic the contender
+		// has capacity for, so that the incumbent gets scaled down and
+		// makes room for it.
+		if totalMsg != "" {
+			cond.SetFalse(
+				condType,
+				conditions.StrategyConditionsUpdate{
+					Reason:             MaxTotalCapacityHeld,
+					Message:            totalMsg,
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+				},
+			)
+
+			return PipelineContinue, nil
+		}
+
 		klog.Infof("Release %q %s", objectutil.MetaKey(curr.release), "has achieved capacity")
 
 		cond.SetTrue(
@@ -225,7 +403,7 @@ func genCapacityEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 func genTrafficEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 	return func(strategyStep shipper.RolloutStrategyStep, cond conditions.StrategyConditionsMap) (PipelineContinuation, []StrategyPatch) {
 		var condType shipper.StrategyConditionType
-		var trafficWeight int32
+		var trafficWeight, shadowWeight int32
 		isHead := succ == nil
 		isInitiator := releasesIdentical(ctx.release, curr.release)
 
@@ -236,11 +414,36 @@ func genTrafficEnforcer(ctx *context, curr, succ *releaseInfo) PipelineStep {
 		}
 		if isHead {
 			trafficWeight = strategyStep.Traffic.Contender
+			// Only the contender gets traffic mirrored to it.
+			shadowWeight = strategyStep.ShadowTraffic
 		} else {
 			trafficWeight = strategyStep.Traffic.Incumbent
 		}
+		if hold := ctx.totalCapacityHold; hold != nil {
+			contenderWeight, incumbentWeight := heldTrafficWeights(strategyStep, hold.capacity)
+			if isHead {
+				trafficWeight = contenderWeight
+			} else {
+				trafficWeight = incumbentWeight
+			}
+		}
+
+		// Jobs don't get any traffic, so there's none to shift.
+		if releaseutil.RunsToCompletion(curr.release) {
+			cond.SetTrue(
+				condType,
+				conditions.StrategyConditionsUpdate{
+					Step:               ctx.step,
+					LastTransitionTime: time.Now(),
+					Message:            "",
+					Reason:             "",
+				},
+			)
+
+			return PipelineContinue, nil
+		}
 
-		if achieved, newSpec, reason := checkTraffic(curr.trafficTarget, uint32(trafficWeight)); !achieved {
+		if achieved, newSpec, reason := checkTraffic(curr.trafficTarget, uint32(trafficWeight), uint32(shadowWeight), ctx.bucketing); !achieved {
 			klog.Infof("Release %q %s", objectutil.MetaKey(curr.release), "hasn't achieved traffic yet")
 
 			cond.SetFalse(
@@ -0,0 +1,230 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestStrategyExecutorStepOrder(t *testing.T) {
+	tests := []struct {
+		order    shipper.RolloutStrategyStepOrder
+		expected []string
+	}{
+		{"", []string{"CapacityTarget"}},
+		{shipper.RolloutStrategyStepCapacityFirst, []string{"CapacityTarget"}},
+		{shipper.RolloutStrategyStepTrafficFirst, []string{"TrafficTarget"}},
+		{shipper.RolloutStrategyStepParallel, []string{"CapacityTarget", "TrafficTarget"}},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease(
+			shippertesting.TestNamespace,
+			shippertesting.TestApp,
+			"step-order",
+			1,
+		)
+
+		achievedStep := StepStaging
+		it, trafficTarget, capacityTarget := buildAssociatedObjectsWithStatus(rel, nil, &achievedStep)
+
+		strategy := vanguard.DeepCopy()
+		strategy.Steps[StepVanguard].Order = tt.order
+
+		executor, err := NewStrategyExecutor(strategy, StepVanguard)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		curr := &releaseInfo{
+			release:            rel,
+			installationTarget: it,
+			trafficTarget:      trafficTarget,
+			capacityTarget:     capacityTarget,
+		}
+
+		_, patches := executor.Execute(nil, curr, nil)
+
+		var patched []string
+		for _, patch := range patches {
+			switch patch.(type) {
+			case *CapacityTargetSpecPatch:
+				patched = append(patched, "CapacityTarget")
+			case *TrafficTargetSpecPatch:
+				patched = append(patched, "TrafficTarget")
+			}
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, patched)
+		if !eq {
+			t.Errorf("unexpected patches for order %q:\n%s", tt.order, diff)
+		}
+	}
+}
+
+// TestStrategyExecutorInstantRollback verifies that an instant rollback
+// scales the incumbent up before moving any traffic, moves the traffic of
+// both releases at once, and only then scales the contender down.
+func TestStrategyExecutorInstantRollback(t *testing.T) {
+	incumbent := buildReleaseInfo("incumbent", 0, 0)
+	contender := buildReleaseInfo("contender", 100, 100)
+
+	executor, err := NewStrategyExecutor(vanguard.DeepCopy(), StepStaging)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	executor.instantRollback = true
+
+	expectPatches := func(expected ...string) {
+		t.Helper()
+
+		_, patches := executor.Execute(incumbent, contender, nil)
+		eq, diff := shippertesting.DeepEqualDiff(expected, describePatches(patches))
+		if !eq {
+			t.Errorf("unexpected patches:\n%s", diff)
+		}
+	}
+
+	incumbentName := incumbent.release.Name
+	contenderName := contender.release.Name
+
+	expectPatches("CapacityTarget/" + incumbentName + "/100")
+
+	incumbent.capacityTarget.Spec.Percent = 100
+	expectPatches("TrafficTarget/"+incumbentName+"/100", "TrafficTarget/"+contenderName+"/0")
+
+	incumbent.trafficTarget.Spec.Weight = 100
+	contender.trafficTarget.Spec.Weight = 0
+	expectPatches("CapacityTarget/" + contenderName + "/1")
+}
+
+// TestStrategyExecutorMaxTotalCapacity verifies that a contender that
+// doesn't fit in the cluster next to its incumbent is scaled up as far as
+// it does, gets no more traffic than it has capacity for, and that the
+// incumbent gives up traffic and then capacity to make room for the rest of
+// it.
+func TestStrategyExecutorMaxTotalCapacity(t *testing.T) {
+	incumbent := buildReleaseInfo("incumbent", 100, 100)
+	contender := buildReleaseInfo("contender", 1, 0)
+
+	executor, err := NewStrategyExecutor(vanguard.DeepCopy(), StepVanguard)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	maxTotal := int32(120)
+	executor.maxTotalCapacity = &maxTotal
+
+	byName := map[string]*releaseInfo{
+		incumbent.release.Name: incumbent,
+		contender.release.Name: contender,
+	}
+
+	var patched []string
+	for i := 0; i < 20; i++ {
+		_, patches := executor.Execute(incumbent, contender, nil)
+		if len(patches) == 0 {
+			break
+		}
+		patched = append(patched, describePatches(patches)...)
+
+		for _, patch := range patches {
+			switch p := patch.(type) {
+			case *CapacityTargetSpecPatch:
+				byName[p.Name].capacityTarget.Spec = *p.NewSpec
+			case *TrafficTargetSpecPatch:
+				byName[p.Name].trafficTarget.Spec = *p.NewSpec
+			}
+		}
+
+		// The step's traffic adds up to 100, so weights are percentages.
+		if traffic, capacity := contender.trafficTarget.Spec.Weight, contender.capacityTarget.Spec.Percent; int32(traffic) > capacity {
+			t.Fatalf("contender got %d%% traffic with only %d%% capacity, after patches %v", traffic, capacity, patched)
+		}
+	}
+
+	incumbentName := incumbent.release.Name
+	contenderName := contender.release.Name
+	expected := []string{
+		"CapacityTarget/" + contenderName + "/20",
+		"TrafficTarget/" + contenderName + "/20",
+		"TrafficTarget/" + incumbentName + "/80",
+		"CapacityTarget/" + incumbentName + "/80",
+		"CapacityTarget/" + contenderName + "/40",
+		"TrafficTarget/" + contenderName + "/40",
+		"TrafficTarget/" + incumbentName + "/60",
+		"CapacityTarget/" + incumbentName + "/60",
+		"CapacityTarget/" + contenderName + "/50",
+		"TrafficTarget/" + contenderName + "/50",
+		"TrafficTarget/" + incumbentName + "/50",
+		"CapacityTarget/" + incumbentName + "/50",
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, patched)
+	if !eq {
+		t.Errorf("unexpected patches:\n%s", diff)
+	}
+}
+
+// TestStrategyExecutorMaxTotalCapacityOrder verifies that a contender held
+// back to fit in the cluster gets its capacity before its traffic, whatever
+// the order of the step.
+func TestStrategyExecutorMaxTotalCapacityOrder(t *testing.T) {
+	orders := []shipper.RolloutStrategyStepOrder{
+		shipper.RolloutStrategyStepTrafficFirst,
+		shipper.RolloutStrategyStepParallel,
+	}
+
+	for _, order := range orders {
+		incumbent := buildReleaseInfo("incumbent", 100, 100)
+		contender := buildReleaseInfo("contender", 1, 0)
+
+		strategy := vanguard.DeepCopy()
+		strategy.Steps[StepVanguard].Order = order
+
+		executor, err := NewStrategyExecutor(strategy, StepVanguard)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		maxTotal := int32(120)
+		executor.maxTotalCapacity = &maxTotal
+
+		_, patches := executor.Execute(incumbent, contender, nil)
+		expected := []string{"CapacityTarget/" + contender.release.Name + "/20"}
+		eq, diff := shippertesting.DeepEqualDiff(expected, describePatches(patches))
+		if !eq {
+			t.Errorf("unexpected patches for order %q:\n%s", order, diff)
+		}
+	}
+}
+
+// TestHeldTrafficWeights verifies that a contender held back at capacity
+// only gets the share of the step's traffic it has capacity for.
+func TestHeldTrafficWeights(t *testing.T) {
+	tests := []struct {
+		name      string
+		traffic   shipper.RolloutStrategyStepValue
+		capacity  int32
+		contender int32
+		incumbent int32
+		needed    int32
+	}{
+		{"held below its traffic", shipper.RolloutStrategyStepValue{Incumbent: 50, Contender: 50}, 20, 20, 80, 80},
+		{"enough for its traffic", shipper.RolloutStrategyStepValue{Incumbent: 90, Contender: 10}, 20, 10, 90, 90},
+		{"weights not adding up to 100", shipper.RolloutStrategyStepValue{Incumbent: 1, Contender: 1}, 20, 0, 2, 100},
+		{"no traffic", shipper.RolloutStrategyStepValue{}, 20, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		step := shipper.RolloutStrategyStep{Traffic: tt.traffic}
+		contender, incumbent := heldTrafficWeights(step, tt.capacity)
+		if contender != tt.contender || incumbent != tt.incumbent {
+			t.Errorf("%s: expected traffic weights %d/%d, got %d/%d",
+				tt.name, tt.contender, tt.incumbent, contender, incumbent)
+		}
+
+		if needed := heldIncumbentCapacity(step, tt.capacity); needed != tt.needed {
+			t.Errorf("%s: expected incumbent to need %d%% capacity, got %d%%", tt.name, tt.needed, needed)
+		}
+	}
+}
@@ -4,45 +4,15 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
-	shippererrors "github.com/bookingcom/shipper/pkg/errors"
 	"github.com/bookingcom/shipper/pkg/util/conditions"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
-func reasonForReleaseCondition(err error) string {
-	switch err.(type) {
-	case shippererrors.NoRegionsSpecifiedError:
-		return "NoRegionsSpecified"
-	case shippererrors.NotEnoughClustersInRegionError:
-		return "NotEnoughClustersInRegion"
-	case shippererrors.NotEnoughCapableClustersInRegionError:
-		return "NotEnoughCapableClustersInRegion"
-
-	case shippererrors.DuplicateCapabilityRequirementError:
-		return "DuplicateCapabilityRequirement"
-
-	case shippererrors.ChartFetchFailureError:
-		return "ChartFetchFailure"
-	case shippererrors.BrokenChartSpecError:
-		return "BrokenChartSpec"
-	case shippererrors.WrongChartDeploymentsError:
-		return "WrongChartDeployments"
-	case shippererrors.RolloutBlockError:
-		return "RolloutBlock"
-	case shippererrors.ChartRepoInternalError:
-		return "ChartRepoInternal"
-	case shippererrors.NoCachedChartRepoIndexError:
-		return "NoCachedChartRepoIndex"
-	}
-
-	if shippererrors.IsKubeclientError(err) {
-		return "FailedAPICall"
-	}
-
-	return fmt.Sprintf("unknown error %T! tell Shipper devs to classify it", err)
-}
-
 func setReleaseClusters(rel *shipper.Release, clusters []*shipper.Cluster) {
 	clusterNames := make([]string, 0, len(clusters))
 	memo := make(map[string]struct{})
@@ -57,28 +27,53 @@ func setReleaseClusters(rel *shipper.Release, clusters []*shipper.Cluster) {
 	rel.Annotations[shipper.ReleaseClustersAnnotation] = strings.Join(clusterNames, ",")
 }
 
+func setReleaseClusterReplicas(rel *shipper.Release, replicas map[string]int32) {
+	clusterReplicas := make([]shipper.ClusterReplicas, 0, len(replicas))
+	for cluster, count := range replicas {
+		clusterReplicas = append(clusterReplicas, shipper.ClusterReplicas{
+			Name:     cluster,
+			Replicas: count,
+		})
+	}
+	sort.Slice(clusterReplicas, func(i, j int) bool {
+		return clusterReplicas[i].Name < clusterReplicas[j].Name
+	})
+	rel.Spec.ClusterReplicas = clusterReplicas
+}
+
+// setReleaseClusterValues records the values the release's chart is
+// rendered with in each of its selected clusters.
+func setReleaseClusterValues(rel *shipper.Release) {
+	clusters := releaseutil.GetSelectedClusters(rel)
+	clusterValues := make([]shipper.ClusterValues, 0, len(clusters))
+	for _, clusterName := range clusters {
+		clusterValues = append(clusterValues, shipper.ClusterValues{
+			Name:   clusterName,
+			Values: releaseutil.ClusterValues(&rel.Spec.Environment, clusterName),
+		})
+	}
+	rel.Spec.ClusterValues = clusterValues
+}
+
 func consolidateStrategyStatus(
 	isHead, isLastStep bool,
+	maxUnavailable int,
 	clusterConditions map[string]conditions.StrategyConditionsMap,
 ) (
 	bool,
 	*shipper.ReleaseStrategyStatus,
 ) {
-	waitingForInstallation := false
-	waitingForCapacity := false
-	waitingForTraffic := false
+	clusterStates := make(map[string]clusterStepState, len(clusterConditions))
+	var unavailable []string
 
 	newClusterStatuses := make(
 		[]shipper.ClusterStrategyStatus, 0, len(clusterConditions))
 
 	for clusterName, conditions := range clusterConditions {
-		waitingForInstallation = waitingForInstallation || conditions.IsFalse(shipper.StrategyConditionContenderAchievedInstallation)
-		waitingForCapacity = waitingForCapacity || conditions.IsFalse(shipper.StrategyConditionContenderAchievedCapacity)
-		waitingForTraffic = waitingForTraffic || conditions.IsFalse(shipper.StrategyConditionContenderAchievedTraffic)
-
-		if isHead {
-			waitingForCapacity = waitingForCapacity || conditions.IsFalse(shipper.StrategyConditionIncumbentAchievedCapacity)
-			waitingForTraffic = waitingForTraffic || conditions.IsFalse(shipper.StrategyConditionIncumbentAchievedTraffic)
+		state := newClusterStepState(isHead, conditions)
+		clusterStates[clusterName] = state
+		if !state.achieved() {
+			unavailable = append(unavailable, clusterName)
 		}
 
 		clusterStatus := shipper.ClusterStrategyStatus{
@@ -89,6 +84,24 @@ func consolidateStrategyStatus(
 	}
 
 	sort.Sort(byClusterName(newClusterStatuses))
+	sort.Strings(unavailable)
+
+	// A step can't be achieved without converging anywhere, no matter
+	// how many clusters the strategy allows to be unavailable.
+	tolerated := len(unavailable) > 0 &&
+		len(unavailable) <= maxUnavailable &&
+		len(unavailable) < len(clusterConditions)
+
+	waitingForInstallation := false
+	waitingForCapacity := false
+	waitingForTraffic := false
+	if !tolerated {
+		for _, state := range clusterStates {
+			waitingForInstallation = waitingForInstallation || state.waitingForInstallation
+			waitingForCapacity = waitingForCapacity || state.waitingForCapacity
+			waitingForTraffic = waitingForTraffic || state.waitingForTraffic
+		}
+	}
 
 	stepComplete := !waitingForInstallation && !waitingForCapacity && !waitingForTraffic
 	waitingForCommand := stepComplete && !isLastStep
@@ -99,10 +112,133 @@ func consolidateStrategyStatus(
 		WaitingForCommand:      boolToStrategyState(waitingForCommand),
 	}
 
-	return stepComplete, &shipper.ReleaseStrategyStatus{
-		Clusters: newClusterStatuses,
-		State:    state,
+	strategyStatus := &shipper.ReleaseStrategyStatus{
+		Clusters:          newClusterStatuses,
+		State:             state,
+		ClustersConverged: countClustersConverged(isHead, clusterConditions),
+	}
+	if tolerated {
+		strategyStatus.UnavailableClusters = unavailable
 	}
+
+	return stepComplete, strategyStatus
+}
+
+// recordAchievedTraffic records in status the traffic weight the traffic
+// target of each cluster in relinfos reports serving. Clusters whose
+// targets couldn't be looked at keep the weight recorded for them in prev.
+func recordAchievedTraffic(
+	status, prev *shipper.ReleaseStrategyStatus,
+	relinfos map[string]*releaseInfo,
+) {
+	recorded := make(map[string]*uint32)
+	if prev != nil {
+		for _, clusterStatus := range prev.Clusters {
+			recorded[clusterStatus.Name] = clusterStatus.AchievedTraffic
+		}
+	}
+
+	for i := range status.Clusters {
+		clusterStatus := &status.Clusters[i]
+		relinfo, ok := relinfos[clusterStatus.Name]
+		if !ok || relinfo.trafficTarget == nil {
+			clusterStatus.AchievedTraffic = recorded[clusterStatus.Name]
+			continue
+		}
+
+		// Clusters out of traffic don't publish any weight to load
+		// balancers, whatever their targets achieved inside them.
+		var achieved uint32
+		if tt := relinfo.trafficTarget; !tt.Spec.TrafficDisabled {
+			achieved = tt.Status.AchievedTraffic
+		}
+		clusterStatus.AchievedTraffic = &achieved
+	}
+}
+
+// countClustersConverged counts the clusters each kind of target has
+// converged in. Targets whose conditions aren't known yet, as happens when
+// the strategy didn't get as far as them, haven't.
+func countClustersConverged(isHead bool, clusterConditions map[string]conditions.StrategyConditionsMap) *shipper.ClustersConverged {
+	var installation, capacity, traffic int
+	for _, cond := range clusterConditions {
+		state := newClusterStepState(isHead, cond)
+		if cond.IsTrue(shipper.StrategyConditionContenderAchievedInstallation) {
+			installation++
+		}
+		if cond.IsTrue(shipper.StrategyConditionContenderAchievedCapacity) && !state.waitingForCapacity {
+			capacity++
+		}
+		if cond.IsTrue(shipper.StrategyConditionContenderAchievedTraffic) && !state.waitingForTraffic {
+			traffic++
+		}
+	}
+
+	total := len(clusterConditions)
+	return &shipper.ClustersConverged{
+		Installation: fmt.Sprintf("%d/%d", installation, total),
+		Capacity:     fmt.Sprintf("%d/%d", capacity, total),
+		Traffic:      fmt.Sprintf("%d/%d", traffic, total),
+	}
+}
+
+// clusterStepState is what a release is waiting for in a single cluster to
+// achieve its target step.
+type clusterStepState struct {
+	waitingForInstallation bool
+	waitingForCapacity     bool
+	waitingForTraffic      bool
+}
+
+func newClusterStepState(isHead bool, cond conditions.StrategyConditionsMap) clusterStepState {
+	state := clusterStepState{
+		waitingForInstallation: cond.IsFalse(shipper.StrategyConditionContenderAchievedInstallation),
+		waitingForCapacity:     cond.IsFalse(shipper.StrategyConditionContenderAchievedCapacity),
+		waitingForTraffic:      cond.IsFalse(shipper.StrategyConditionContenderAchievedTraffic),
+	}
+
+	if isHead {
+		state.waitingForCapacity = state.waitingForCapacity || cond.IsFalse(shipper.StrategyConditionIncumbentAchievedCapacity)
+		state.waitingForTraffic = state.waitingForTraffic || cond.IsFalse(shipper.StrategyConditionIncumbentAchievedTraffic)
+	}
+
+	return state
+}
+
+func (s clusterStepState) achieved() bool {
+	return !s.waitingForInstallation && !s.waitingForCapacity && !s.waitingForTraffic
+}
+
+// maxUnavailableClusters is how many of a release's clusters its strategy
+// allows to not converge.
+func maxUnavailableClusters(strategy *shipper.RolloutStrategy, clusters int) int {
+	if strategy.MaxUnavailableClusters == nil {
+		return 0
+	}
+
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(strategy.MaxUnavailableClusters, clusters, false)
+	if err != nil {
+		return 0
+	}
+
+	return maxUnavailable
+}
+
+// unavailableClusterConditions reports a cluster the strategy couldn't be
+// executed on as not having achieved installation.
+func unavailableClusterConditions(step int32, err error) conditions.StrategyConditionsMap {
+	cond := conditions.NewStrategyConditions()
+	cond.SetFalse(
+		shipper.StrategyConditionContenderAchievedInstallation,
+		conditions.StrategyConditionsUpdate{
+			Reason:             ClusterUnavailable,
+			Message:            err.Error(),
+			Step:               step,
+			LastTransitionTime: time.Now(),
+		},
+	)
+
+	return cond
 }
 
 func boolToStrategyState(b bool) shipper.StrategyState {
@@ -46,6 +46,10 @@ func pint32(i int32) *int32 {
 	return &i
 }
 
+func puint32(i uint32) *uint32 {
+	return &i
+}
+
 func buildRelease(
 	namespace, app, name string,
 	replicaCount int32,
@@ -175,3 +179,50 @@ func buildExpectedActions(release *shipper.Release, clusters []*shipper.Cluster)
 		),
 	}
 }
+
+// buildReleaseInfo builds a release of the test application with target
+// objects that have achieved their specs: its capacity target at capacity,
+// and its traffic target at weight.
+func buildReleaseInfo(name string, capacity int32, weight uint32) *releaseInfo {
+	rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, name, 1)
+
+	achievedStep := int32(0)
+	it, tt, ct := buildAssociatedObjectsWithStatus(rel, nil, &achievedStep)
+	ct.Spec.Percent = capacity
+	tt.Spec.Weight = weight
+
+	return &releaseInfo{
+		release:            rel,
+		installationTarget: it,
+		trafficTarget:      tt,
+		capacityTarget:     ct,
+	}
+}
+
+// buildCapacityReleaseInfo builds a release of the test application with
+// only a capacity target, at percent of totalReplicas.
+func buildCapacityReleaseInfo(name string, percent, totalReplicas int32) *releaseInfo {
+	rel := buildRelease(shippertesting.TestNamespace, shippertesting.TestApp, name, totalReplicas)
+
+	_, _, ct := buildAssociatedObjects(rel, nil)
+	ct.Spec.Percent = percent
+	ct.Spec.TotalReplicaCount = totalReplicas
+
+	return &releaseInfo{release: rel, capacityTarget: ct}
+}
+
+// describePatches describes each of patches as the kind and name of the
+// object it patches, and the capacity or traffic weight it patches it to.
+func describePatches(patches []StrategyPatch) []string {
+	var described []string
+	for _, patch := range patches {
+		switch p := patch.(type) {
+		case *CapacityTargetSpecPatch:
+			described = append(described, fmt.Sprintf("CapacityTarget/%s/%d", p.Name, p.NewSpec.Percent))
+		case *TrafficTargetSpecPatch:
+			described = append(described, fmt.Sprintf("TrafficTarget/%s/%d", p.Name, p.NewSpec.Weight))
+		}
+	}
+
+	return described
+}
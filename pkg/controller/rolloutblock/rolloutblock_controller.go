@@ -7,7 +7,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -17,8 +16,13 @@ import (
 	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 	"github.com/bookingcom/shipper/pkg/util/rolloutblock"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
@@ -48,6 +52,10 @@ type Controller struct {
 	rolloutblockWorkqueue workqueue.RateLimitingInterface
 	releaseWorkqueue      workqueue.RateLimitingInterface
 	applicationWorkqueue  workqueue.RateLimitingInterface
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
 }
 
 // NewController returns a new RolloutBlock controller.
@@ -55,6 +63,7 @@ func NewController(
 	shipperClientset clientset.Interface,
 	informerFactory shipperinformers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	drainTimeout time.Duration,
 ) *Controller {
 	applicationInformer := informerFactory.Shipper().V1alpha1().Applications()
 	releaseInformer := informerFactory.Shipper().V1alpha1().Releases()
@@ -75,18 +84,20 @@ func NewController(
 		rolloutBlockLister: rolloutBlockInformer.Lister(),
 		rolloutBlockSynced: rolloutBlockInformer.Informer().HasSynced,
 
-		rolloutblockWorkqueue: workqueue.NewNamedRateLimitingQueue(
+		rolloutblockWorkqueue: shipperworkqueue.NewNamedRateLimitingQueue(
 			shipperworkqueue.NewDefaultControllerRateLimiter(),
 			"rolloutblock_controller_rolloutblocks",
 		),
-		releaseWorkqueue: workqueue.NewNamedRateLimitingQueue(
+		releaseWorkqueue: shipperworkqueue.NewNamedRateLimitingQueue(
 			shipperworkqueue.NewDefaultControllerRateLimiter(),
 			"rolloutblock_controller_releases",
 		),
-		applicationWorkqueue: workqueue.NewNamedRateLimitingQueue(
+		applicationWorkqueue: shipperworkqueue.NewNamedRateLimitingQueue(
 			shipperworkqueue.NewDefaultControllerRateLimiter(),
 			"rolloutblock_controller_applications",
 		),
+
+		drainTimeout: drainTimeout,
 	}
 
 	klog.Info("Setting up event handlers")
@@ -97,7 +108,7 @@ func NewController(
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.onUpdateRelease(oldObj, newObj)
 			},
-			DeleteFunc: controller.enqueueReleaseBlock,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueReleaseBlock),
 		})
 
 	applicationInformer.Informer().AddEventHandler(
@@ -106,13 +117,13 @@ func NewController(
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.enqueueApplicationBlock(newObj)
 			},
-			DeleteFunc: controller.enqueueApplicationBlock,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueApplicationBlock),
 		})
 
 	rolloutBlockInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.onAddRolloutBlock,
-			DeleteFunc: controller.onDeleteRolloutBlock,
+			DeleteFunc: objectutil.OnDelete(controller.onDeleteRolloutBlock),
 		})
 
 	return controller
@@ -227,30 +238,16 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runApplicationWorker, time.Second, stopCh)
-		go wait.Until(c.runReleaseWorker, time.Second, stopCh)
-		go wait.Until(c.runRolloutBlockWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("RolloutBlock controller")
+	workers.Start(c.applicationWorkqueue, threadiness, c.processNextApplicationWorkItem, stopCh)
+	workers.Start(c.releaseWorkqueue, threadiness, c.processNextReleaseWorkItem, stopCh)
+	workers.Start(c.rolloutblockWorkqueue, threadiness, c.processNextRolloutBlockWorkItem, stopCh)
 
 	klog.V(4).Info("Started RolloutBlock controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runApplicationWorker() {
-	for c.processNextApplicationWorkItem() {
-	}
-}
-
-func (c *Controller) runReleaseWorker() {
-	for c.processNextReleaseWorkItem() {
-	}
-}
-
-func (c *Controller) runRolloutBlockWorker() {
-	for c.processNextRolloutBlockWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextApplicationWorkItem() bool {
@@ -273,11 +270,15 @@ func (c *Controller) processNextApplicationWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "Application", key)
 	err := c.syncApplication(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Application", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing Application %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -312,11 +313,15 @@ func (c *Controller) processNextReleaseWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "Release", key)
 	err := c.syncRelease(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "Release", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing Release %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -351,7 +356,10 @@ func (c *Controller) processNextRolloutBlockWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "RolloutBlock", key)
 	err := c.syncRolloutBlock(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "RolloutBlock", key, err)
 
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -360,6 +368,7 @@ func (c *Controller) processNextRolloutBlockWorkItem() bool {
 		}
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing RolloutBlock %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -15,6 +15,7 @@ import (
 	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 )
 
 const (
@@ -28,7 +29,7 @@ func (f *fixture) newController() (*Controller, shipperinformers.SharedInformerF
 	const noResyncPeriod time.Duration = 0
 	shipperInformerFactory := shipperinformers.NewSharedInformerFactory(f.client, noResyncPeriod)
 
-	controller := NewController(f.client, shipperInformerFactory, record.NewFakeRecorder(42))
+	controller := NewController(f.client, shipperInformerFactory, record.NewFakeRecorder(42), shutdown.DefaultDrainTimeout)
 	return controller, shipperInformerFactory
 }
 
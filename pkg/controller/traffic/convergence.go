@@ -0,0 +1,34 @@
+package traffic
+
+import (
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+// observeConvergence keeps track of when traffic targets stopped being ready,
+// and records how long they took to converge once they're ready again.
+func (c *Controller) observeConvergence(key string, tt *shipper.TrafficTarget) {
+	ready, _ := targetutil.IsReady(tt.Status.Conditions)
+
+	c.convergingSinceMutex.Lock()
+	defer c.convergingSinceMutex.Unlock()
+
+	since, converging := c.convergingSince[key]
+	switch {
+	case ready && converging:
+		shippermetrics.TrafficConvergenceDuration.WithLabelValues(c.clusterName).
+			Observe(time.Since(since).Seconds())
+		delete(c.convergingSince, key)
+	case !ready && !converging:
+		c.convergingSince[key] = time.Now()
+	}
+}
+
+func (c *Controller) forgetConvergence(key string) {
+	c.convergingSinceMutex.Lock()
+	delete(c.convergingSince, key)
+	c.convergingSinceMutex.Unlock()
+}
@@ -0,0 +1,53 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+// TestObserveConvergence verifies that the time traffic targets take to
+// become ready again is recorded once, when they do.
+func TestObserveConvergence(t *testing.T) {
+	c := &Controller{
+		convergingSince: make(map[string]time.Time),
+		clusterName:     "convergence-test",
+	}
+
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 10)
+	key := tt.Namespace + "/" + tt.Name
+
+	for _, status := range []corev1.ConditionStatus{
+		corev1.ConditionTrue,
+		corev1.ConditionFalse,
+		corev1.ConditionFalse,
+		corev1.ConditionTrue,
+		corev1.ConditionTrue,
+	} {
+		tt.Status.Conditions = []shipper.TargetCondition{
+			{Type: shipper.TargetConditionTypeOperational, Status: corev1.ConditionTrue},
+			{Type: shipper.TargetConditionTypeReady, Status: status},
+		}
+		c.observeConvergence(key, tt)
+	}
+
+	if _, ok := c.convergingSince[key]; ok {
+		t.Errorf("expected converged TrafficTarget to be forgotten")
+	}
+
+	metric := &dto.Metric{}
+	observer := shippermetrics.TrafficConvergenceDuration.WithLabelValues(c.clusterName)
+	if err := observer.(interface{ Write(*dto.Metric) error }).Write(metric); err != nil {
+		t.Fatalf("could not read histogram: %s", err)
+	}
+
+	if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected 1 convergence to be observed, got %d", count)
+	}
+}
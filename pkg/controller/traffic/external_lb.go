@@ -0,0 +1,92 @@
+package traffic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// ExternalLoadBalancer is a traffic backend that shifts traffic outside of
+// the application cluster, such as a global load balancer or weighted DNS
+// records sitting in front of several clusters. It is informed of the weight
+// each release has achieved in this cluster, so that north-south traffic can
+// be split across clusters and regions at the edge, of the share of traffic
+// to mirror to it, and of how to keep users on it during experiments.
+// Implementations fill in the cluster of weight themselves.
+type ExternalLoadBalancer interface {
+	SetWeight(ctx context.Context, weight ExternalWeight) error
+}
+
+// ExternalWeight is the payload sent to an external load balancer adapter
+// every time the weight of a release changes in a cluster.
+type ExternalWeight struct {
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	Application string `json:"application"`
+	Release     string `json:"release"`
+	Weight      uint32 `json:"weight"`
+	// ShadowWeight is the percentage of the application's traffic
+	// to mirror to the release, throwing its responses away.
+	ShadowWeight uint32 `json:"shadowWeight"`
+	// Bucketing is set while the release is part of an experiment, for
+	// the load balancer to always send a user to the same release.
+	Bucketing *shipper.ExperimentBucketing `json:"bucketing,omitempty"`
+}
+
+// HTTPExternalLoadBalancer talks to an adapter over HTTP. The adapter is
+// responsible for translating weights into the provider specific
+// configuration (Route53 weighted records, GCLB backend service weights, F5
+// pool ratios and so on), which keeps cloud provider SDKs out of Shipper.
+type HTTPExternalLoadBalancer struct {
+	url     string
+	cluster string
+	timeout time.Duration
+	client  *http.Client
+}
+
+var _ ExternalLoadBalancer = (*HTTPExternalLoadBalancer)(nil)
+
+func NewHTTPExternalLoadBalancer(url, cluster string, timeout time.Duration) *HTTPExternalLoadBalancer {
+	return &HTTPExternalLoadBalancer{
+		url:     url,
+		cluster: cluster,
+		timeout: timeout,
+		client:  &http.Client{},
+	}
+}
+
+// SetWeight posts weight to the adapter, giving up when ctx is done or after
+// the load balancer's timeout, whichever comes first.
+func (lb *HTTPExternalLoadBalancer) SetWeight(ctx context.Context, weight ExternalWeight) error {
+	weight.Cluster = lb.cluster
+	body, err := json.Marshal(weight)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lb.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lb.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := lb.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, lb.url)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package traffic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestHTTPExternalLoadBalancer(t *testing.T) {
+	var received []ExternalWeight
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var weight ExternalWeight
+		if err := json.NewDecoder(r.Body).Decode(&weight); err != nil {
+			t.Fatalf("could not decode request body: %s", err)
+		}
+		received = append(received, weight)
+	}))
+	defer server.Close()
+
+	lb := NewHTTPExternalLoadBalancer(server.URL, shippertesting.TestCluster, time.Second)
+	weight := ExternalWeight{
+		Namespace:    shippertesting.TestNamespace,
+		Application:  shippertesting.TestApp,
+		Release:      ttName,
+		Weight:       42,
+		ShadowWeight: 10,
+		Bucketing:    &shipper.ExperimentBucketing{Cookie: "experiment"},
+	}
+	err := lb.SetWeight(context.Background(), weight)
+	if err != nil {
+		t.Fatalf("unexpected error setting weight: %s", err)
+	}
+
+	weight.Cluster = shippertesting.TestCluster
+	expected := []ExternalWeight{weight}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, received)
+	if !eq {
+		t.Fatalf("external load balancer received unexpected weights:\n%s", diff)
+	}
+}
+
+func TestHTTPExternalLoadBalancerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	lb := NewHTTPExternalLoadBalancer(server.URL, shippertesting.TestCluster, time.Second)
+	err := lb.SetWeight(context.Background(), ExternalWeight{
+		Namespace:   shippertesting.TestNamespace,
+		Application: shippertesting.TestApp,
+		Release:     ttName,
+		Weight:      42,
+	})
+	if err == nil {
+		t.Fatal("expected error setting weight, got none")
+	}
+}
+
+type fakeExternalLoadBalancer struct {
+	weights       []uint32
+	shadowWeights []uint32
+	bucketings    []*shipper.ExperimentBucketing
+}
+
+func (lb *fakeExternalLoadBalancer) SetWeight(_ context.Context, weight ExternalWeight) error {
+	lb.weights = append(lb.weights, weight.Weight)
+	lb.shadowWeights = append(lb.shadowWeights, weight.ShadowWeight)
+	lb.bucketings = append(lb.bucketings, weight.Bucketing)
+	return nil
+}
+
+// TestPublishExternalWeightTrafficDisabled verifies that traffic targets in
+// clusters out of traffic publish no weight, and publish their achieved
+// weight again once the cluster is back in.
+func TestPublishExternalWeightTrafficDisabled(t *testing.T) {
+	lb := &fakeExternalLoadBalancer{}
+	c := &Controller{
+		externalLB:       lb,
+		publishedWeights: make(map[string]ExternalWeight),
+	}
+
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 50)
+	tt.Spec.Backend = shipper.TrafficBackendExternalLB
+
+	for _, disabled := range []bool{false, true, true, false} {
+		tt.Spec.TrafficDisabled = disabled
+		err := c.publishExternalWeight(tt, shippertesting.TestApp, ttName, 50)
+		if err != nil {
+			t.Fatalf("unexpected error publishing weight: %s", err)
+		}
+	}
+
+	expected := []uint32{50, 0, 50}
+	eq, diff := shippertesting.DeepEqualDiff(expected, lb.weights)
+	if !eq {
+		t.Fatalf("external load balancer received unexpected weights:\n%s", diff)
+	}
+}
+
+// TestPublishExternalWeightShadowTraffic verifies that changes to the traffic
+// mirrored to a release are published even if its weight doesn't change,
+// and that clusters out of traffic get none mirrored either.
+func TestPublishExternalWeightShadowTraffic(t *testing.T) {
+	lb := &fakeExternalLoadBalancer{}
+	c := &Controller{
+		externalLB:       lb,
+		publishedWeights: make(map[string]ExternalWeight),
+	}
+
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 0)
+	tt.Spec.Backend = shipper.TrafficBackendExternalLB
+
+	for _, step := range []struct {
+		shadowWeight uint32
+		disabled     bool
+	}{{0, false}, {10, false}, {10, false}, {10, true}, {0, false}} {
+		tt.Spec.ShadowWeight = step.shadowWeight
+		tt.Spec.TrafficDisabled = step.disabled
+		err := c.publishExternalWeight(tt, shippertesting.TestApp, ttName, 0)
+		if err != nil {
+			t.Fatalf("unexpected error publishing weight: %s", err)
+		}
+	}
+
+	expected := []uint32{0, 10, 0}
+	eq, diff := shippertesting.DeepEqualDiff(expected, lb.shadowWeights)
+	if !eq {
+		t.Fatalf("external load balancer received unexpected shadow weights:\n%s", diff)
+	}
+}
+
+// TestPublishExternalWeightBucketing verifies that a release joining or
+// leaving an experiment is published even if its weight doesn't change.
+func TestPublishExternalWeightBucketing(t *testing.T) {
+	lb := &fakeExternalLoadBalancer{}
+	c := &Controller{
+		externalLB:       lb,
+		publishedWeights: make(map[string]ExternalWeight),
+	}
+
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 50)
+	tt.Spec.Backend = shipper.TrafficBackendExternalLB
+
+	bucketing := &shipper.ExperimentBucketing{Header: "X-User-Id"}
+	for _, b := range []*shipper.ExperimentBucketing{nil, bucketing, bucketing.DeepCopy(), nil} {
+		tt.Spec.Bucketing = b
+		err := c.publishExternalWeight(tt, shippertesting.TestApp, ttName, 50)
+		if err != nil {
+			t.Fatalf("unexpected error publishing weight: %s", err)
+		}
+	}
+
+	expected := []*shipper.ExperimentBucketing{nil, bucketing, nil}
+	eq, diff := shippertesting.DeepEqualDiff(expected, lb.bucketings)
+	if !eq {
+		t.Fatalf("external load balancer received unexpected bucketing:\n%s", diff)
+	}
+}
+
+type blockingExternalLoadBalancer struct {
+	release string
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func (lb *blockingExternalLoadBalancer) SetWeight(_ context.Context, weight ExternalWeight) error {
+	if weight.Release == lb.release {
+		close(lb.entered)
+		<-lb.unblock
+	}
+	return nil
+}
+
+// TestPublishExternalWeightSlowLoadBalancer verifies that a load balancer
+// taking its time to set the weight of a release doesn't hold up publishing
+// the weights of others.
+func TestPublishExternalWeightSlowLoadBalancer(t *testing.T) {
+	lb := &blockingExternalLoadBalancer{
+		release: "slow-release",
+		entered: make(chan struct{}),
+		unblock: make(chan struct{}),
+	}
+	defer close(lb.unblock)
+
+	c := &Controller{
+		externalLB:       lb,
+		publishedWeights: make(map[string]ExternalWeight),
+	}
+
+	slow := buildTrafficTarget(shippertesting.TestApp, lb.release, 50)
+	slow.Spec.Backend = shipper.TrafficBackendExternalLB
+	go c.publishExternalWeight(slow, shippertesting.TestApp, lb.release, 50)
+	<-lb.entered
+
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 50)
+	tt.Spec.Backend = shipper.TrafficBackendExternalLB
+
+	done := make(chan error)
+	go func() {
+		done <- c.publishExternalWeight(tt, shippertesting.TestApp, ttName, 50)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error publishing weight: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("publishing a weight was held up by a slow load balancer")
+	}
+}
@@ -0,0 +1,262 @@
+package traffic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// knativeServiceLabel is put by Knative on every Revision, naming the
+// Knative Service it belongs to.
+const knativeServiceLabel = "serving.knative.dev/service"
+
+var (
+	knativeServiceGVK  = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}
+	knativeRevisionGVK = schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Revision"}
+
+	knativeServiceGVR  = knativeServiceGVK.GroupVersion().WithResource("services")
+	knativeRevisionGVR = knativeRevisionGVK.GroupVersion().WithResource("revisions")
+)
+
+type knativeTrafficEntry struct {
+	RevisionName string `json:"revisionName"`
+	Percent      int64  `json:"percent"`
+}
+
+type knativeTrafficStatus struct {
+	ready                 bool
+	achievedTrafficWeight uint32
+	message               string
+}
+
+// shiftKnativeTraffic programs the traffic block of an application's Knative
+// Service, giving the revision of every release its share of the weights of
+// all traffic targets in the cluster. A release's revision is the newest one
+// of the Service labelled with its name, which Knative copies over from the
+// Service's revision template.
+func (c *Controller) shiftKnativeTraffic(
+	namespace, appName, releaseName string,
+	weights releaseWeights,
+) (knativeTrafficStatus, error) {
+	appSelector := labels.Set{shipper.AppLabel: appName}.AsSelector()
+	listOptions := metav1.ListOptions{LabelSelector: appSelector.String()}
+
+	services, err := c.knativeClient.Resource(knativeServiceGVR).Namespace(namespace).List(listOptions)
+	if err != nil {
+		return knativeTrafficStatus{}, shippererrors.NewKubeclientListError(
+			knativeServiceGVK, namespace, appSelector, err)
+	}
+
+	if l := len(services.Items); l != 1 {
+		return knativeTrafficStatus{}, shippererrors.NewUnexpectedObjectCountFromSelectorError(
+			appSelector, knativeServiceGVK, 1, l)
+	}
+	service := &services.Items[0]
+
+	revisionList, err := c.knativeClient.Resource(knativeRevisionGVR).Namespace(namespace).List(listOptions)
+	if err != nil {
+		return knativeTrafficStatus{}, shippererrors.NewKubeclientListError(
+			knativeRevisionGVK, namespace, appSelector, err)
+	}
+
+	revisions := releaseRevisions(service.GetName(), revisionList.Items)
+
+	var missing []string
+	for release, weight := range weights {
+		if _, ok := revisions[release]; weight > 0 && !ok {
+			missing = append(missing, release)
+		}
+	}
+	sort.Strings(missing)
+
+	desired := knativeTrafficPercents(weights, revisions)
+	if desired == nil {
+		// Knative Services need all of their traffic to go somewhere,
+		// so we leave it alone until some release is given weight.
+		return knativeTrafficStatus{ready: len(missing) == 0}, nil
+	}
+
+	specTraffic, err := knativeTraffic(service, "spec")
+	if err != nil {
+		return knativeTrafficStatus{}, err
+	}
+
+	if !reflect.DeepEqual(specTraffic, desired) {
+		patch, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"traffic": desired,
+			},
+		})
+		if err != nil {
+			return knativeTrafficStatus{}, shippererrors.NewUnrecoverableError(err)
+		}
+
+		_, err = c.knativeClient.Resource(knativeServiceGVR).Namespace(namespace).
+			Patch(service.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return knativeTrafficStatus{}, shippererrors.NewKubeclientPatchError(
+				namespace, service.GetName(), err).WithKind(knativeServiceGVK)
+		}
+
+		return knativeTrafficStatus{message: "traffic block of Knative Service updated"}, nil
+	}
+
+	statusTraffic, err := knativeTraffic(service, "status")
+	if err != nil {
+		return knativeTrafficStatus{}, err
+	}
+
+	var totalWeight uint32
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	var achievedPercent int64
+	for _, entry := range statusTraffic {
+		if entry.RevisionName == revisions[releaseName] {
+			achievedPercent += entry.Percent
+		}
+	}
+
+	status := knativeTrafficStatus{
+		achievedTrafficWeight: uint32(math.Round(float64(achievedPercent) * float64(totalWeight) / 100)),
+	}
+
+	observedGeneration, _, _ := unstructured.NestedInt64(service.Object, "status", "observedGeneration")
+	switch {
+	case observedGeneration != service.GetGeneration() || !reflect.DeepEqual(statusTraffic, desired):
+		status.message = "Knative Service has not yet routed traffic as requested"
+	case len(missing) > 0:
+		status.message = fmt.Sprintf(
+			"no Knative revision found for releases %s", strings.Join(missing, ", "))
+	default:
+		status.ready = true
+	}
+
+	return status, nil
+}
+
+// releaseRevisions returns the name of the newest revision of the Knative
+// Service named serviceName for each release.
+func releaseRevisions(serviceName string, revisions []unstructured.Unstructured) map[string]string {
+	newest := make(map[string]*unstructured.Unstructured)
+	for i := range revisions {
+		rev := &revisions[i]
+		labels := rev.GetLabels()
+		release, ok := labels[shipper.ReleaseLabel]
+		if !ok || labels[knativeServiceLabel] != serviceName {
+			continue
+		}
+
+		ts := rev.GetCreationTimestamp()
+		if prev, ok := newest[release]; ok {
+			prevTs := prev.GetCreationTimestamp()
+			if ts.Before(&prevTs) || (ts.Equal(&prevTs) && rev.GetName() < prev.GetName()) {
+				continue
+			}
+		}
+
+		newest[release] = rev
+	}
+
+	names := make(map[string]string, len(newest))
+	for release, rev := range newest {
+		names[release] = rev.GetName()
+	}
+
+	return names
+}
+
+// knativeTrafficPercents splits 100 percent of traffic between the revisions
+// of releases in proportion to their weights, handing what's left after
+// rounding down to the releases with the largest remainders. Releases with no
+// weight or no revision are left out. It returns nil if no release can be
+// given any traffic.
+func knativeTrafficPercents(weights releaseWeights, revisions map[string]string) []knativeTrafficEntry {
+	var releases []string
+	var totalWeight uint32
+	for release, weight := range weights {
+		if _, ok := revisions[release]; weight > 0 && ok {
+			releases = append(releases, release)
+			totalWeight += weight
+		}
+	}
+
+	if totalWeight == 0 {
+		return nil
+	}
+
+	sort.Strings(releases)
+
+	entries := make([]knativeTrafficEntry, len(releases))
+	remainders := make([]uint64, len(releases))
+	var assigned int64
+	for i, release := range releases {
+		share := uint64(weights[release]) * 100
+		entries[i] = knativeTrafficEntry{
+			RevisionName: revisions[release],
+			Percent:      int64(share / uint64(totalWeight)),
+		}
+		remainders[i] = share % uint64(totalWeight)
+		assigned += entries[i].Percent
+	}
+
+	order := make([]int, len(releases))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+
+	for i := 0; assigned < 100; i++ {
+		entries[order[i%len(order)]].Percent++
+		assigned++
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RevisionName < entries[j].RevisionName
+	})
+
+	return entries
+}
+
+// knativeTraffic reads the traffic block in either the spec or the status of
+// a Knative Service. Entries are sorted by revision, so blocks can be
+// compared no matter how Knative orders them.
+func knativeTraffic(service *unstructured.Unstructured, field string) ([]knativeTrafficEntry, error) {
+	traffic, ok, err := unstructured.NestedSlice(service.Object, field, "traffic")
+	if err != nil {
+		return nil, shippererrors.NewUnrecoverableError(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(traffic)
+	if err != nil {
+		return nil, shippererrors.NewUnrecoverableError(err)
+	}
+
+	var entries []knativeTrafficEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, shippererrors.NewUnrecoverableError(err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].RevisionName < entries[j].RevisionName
+	})
+
+	return entries, nil
+}
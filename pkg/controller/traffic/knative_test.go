@@ -0,0 +1,180 @@
+package traffic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+const (
+	knativeIncumbent = "test-app-incumbent"
+	knativeContender = "test-app-contender"
+)
+
+func buildKnativeService(generation, observedGeneration int64, spec, status []interface{}) *unstructured.Unstructured {
+	svc := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec":   map[string]interface{}{"traffic": spec},
+			"status": map[string]interface{}{"traffic": status, "observedGeneration": observedGeneration},
+		},
+	}
+	svc.SetGroupVersionKind(knativeServiceGVK)
+	svc.SetNamespace(shippertesting.TestNamespace)
+	svc.SetName(shippertesting.TestApp)
+	svc.SetGeneration(generation)
+	svc.SetLabels(map[string]string{shipper.AppLabel: shippertesting.TestApp})
+
+	return svc
+}
+
+func buildKnativeRevision(release string) *unstructured.Unstructured {
+	rev := &unstructured.Unstructured{}
+	rev.SetGroupVersionKind(knativeRevisionGVK)
+	rev.SetNamespace(shippertesting.TestNamespace)
+	rev.SetName(release)
+	rev.SetLabels(map[string]string{
+		shipper.AppLabel:     shippertesting.TestApp,
+		shipper.ReleaseLabel: release,
+		knativeServiceLabel:  shippertesting.TestApp,
+	})
+
+	return rev
+}
+
+func knativeTrafficBlock(entries ...knativeTrafficEntry) []interface{} {
+	block := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		block = append(block, map[string]interface{}{
+			"revisionName": e.RevisionName,
+			"percent":      e.Percent,
+		})
+	}
+
+	return block
+}
+
+// TestKnativeTrafficShift verifies that the traffic block of a Knative
+// Service is programmed after the weights of the releases in the cluster,
+// and that a traffic target is only ready once Knative routes traffic as
+// asked.
+func TestKnativeTrafficShift(t *testing.T) {
+	incumbentOnly := knativeTrafficBlock(knativeTrafficEntry{knativeIncumbent, 100})
+	split := []knativeTrafficEntry{{knativeContender, 25}, {knativeIncumbent, 75}}
+
+	tests := []struct {
+		name     string
+		service  *unstructured.Unstructured
+		expected knativeTrafficStatus
+		traffic  []knativeTrafficEntry
+	}{
+		{
+			name:    "traffic block updated",
+			service: buildKnativeService(1, 1, incumbentOnly, incumbentOnly),
+			expected: knativeTrafficStatus{
+				message: "traffic block of Knative Service updated",
+			},
+			traffic: split,
+		},
+		{
+			name:    "traffic not routed yet",
+			service: buildKnativeService(2, 1, knativeTrafficBlock(split...), incumbentOnly),
+			expected: knativeTrafficStatus{
+				message: "Knative Service has not yet routed traffic as requested",
+			},
+			traffic: split,
+		},
+		{
+			name:    "traffic routed",
+			service: buildKnativeService(2, 2, knativeTrafficBlock(split...), knativeTrafficBlock(split...)),
+			expected: knativeTrafficStatus{
+				ready:                 true,
+				achievedTrafficWeight: 1,
+			},
+			traffic: split,
+		},
+	}
+
+	for _, tt := range tests {
+		objects := []runtime.Object{
+			tt.service,
+			buildKnativeRevision(knativeIncumbent),
+			buildKnativeRevision(knativeContender),
+		}
+		c := &Controller{
+			knativeClient: fakedynamic.NewSimpleDynamicClient(scheme.Scheme, objects...),
+		}
+
+		weights := releaseWeights{knativeIncumbent: 3, knativeContender: 1}
+		status, err := c.shiftKnativeTraffic(shippertesting.TestNamespace, shippertesting.TestApp, knativeContender, weights)
+		if err != nil {
+			t.Fatalf("%s: unexpected error shifting traffic: %s", tt.name, err)
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, status)
+		if !eq {
+			t.Errorf("%s: unexpected traffic status:\n%s", tt.name, diff)
+		}
+
+		svc, err := c.knativeClient.Resource(knativeServiceGVR).Namespace(shippertesting.TestNamespace).
+			Get(shippertesting.TestApp, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: could not get Knative Service: %s", tt.name, err)
+		}
+
+		traffic, err := knativeTraffic(svc, "spec")
+		if err != nil {
+			t.Fatalf("%s: could not read traffic block: %s", tt.name, err)
+		}
+
+		eq, diff = shippertesting.DeepEqualDiff(tt.traffic, traffic)
+		if !eq {
+			t.Errorf("%s: unexpected traffic block:\n%s", tt.name, diff)
+		}
+	}
+}
+
+func TestKnativeTrafficPercents(t *testing.T) {
+	revisions := map[string]string{"a": "a-rev", "b": "b-rev", "c": "c-rev"}
+
+	tests := []struct {
+		name     string
+		weights  releaseWeights
+		expected []knativeTrafficEntry
+	}{
+		{
+			name:     "no weight",
+			weights:  releaseWeights{"a": 0, "b": 0},
+			expected: nil,
+		},
+		{
+			name:     "uneven split",
+			weights:  releaseWeights{"a": 1, "b": 2},
+			expected: []knativeTrafficEntry{{"a-rev", 33}, {"b-rev", 67}},
+		},
+		{
+			name:     "remainder handed out",
+			weights:  releaseWeights{"a": 1, "b": 1, "c": 1},
+			expected: []knativeTrafficEntry{{"a-rev", 34}, {"b-rev", 33}, {"c-rev", 33}},
+		},
+		{
+			name:     "release without revision",
+			weights:  releaseWeights{"a": 1, "d": 1},
+			expected: []knativeTrafficEntry{{"a-rev", 100}},
+		},
+	}
+
+	for _, tt := range tests {
+		percents := knativeTrafficPercents(tt.weights, revisions)
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, percents)
+		if !eq {
+			t.Errorf("%s: unexpected traffic percents:\n%s", tt.name, diff)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package traffic
+
+import (
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+var networkPolicyGVK = networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy")
+
+// syncNetworkPolicy creates the isolation NetworkPolicy for tt's release when
+// it has no weight, and removes it once it does.
+func (c *Controller) syncNetworkPolicy(tt *shipper.TrafficTarget, appName, releaseName string) error {
+	name := networkPolicyName(releaseName)
+	isolate := c.isolateContenders && tt.Spec.Weight == 0
+
+	existing, err := c.networkPoliciesLister.NetworkPolicies(tt.Namespace).Get(name)
+	if err != nil && !errors.IsNotFound(err) {
+		return shippererrors.NewKubeclientGetError(tt.Namespace, name, err).
+			WithKind(networkPolicyGVK)
+	}
+
+	exists := err == nil
+	networkPolicies := c.kubeClient.NetworkingV1().NetworkPolicies(tt.Namespace)
+
+	if isolate && !exists {
+		policy := buildNetworkPolicy(tt, appName, releaseName)
+		_, err := networkPolicies.Create(policy)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return shippererrors.NewKubeclientCreateError(policy, err).
+				WithKind(networkPolicyGVK)
+		}
+	} else if !isolate && exists {
+		if existing.Labels[shipper.ReleaseLabel] != releaseName {
+			// Not ours: someone happened to pick the same name.
+			return nil
+		}
+
+		err := networkPolicies.Delete(name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return shippererrors.NewKubeclientDeleteError(tt.Namespace, name, err).
+				WithKind(networkPolicyGVK)
+		}
+	}
+
+	return nil
+}
+
+func networkPolicyName(releaseName string) string {
+	return fmt.Sprintf("%s-isolation", releaseName)
+}
+
+// buildNetworkPolicy returns a NetworkPolicy that only lets pods in the same
+// namespace reach the pods of a release. Kubernetes allows any traffic a
+// policy selecting the pod allows, so this only closes off paths that no
+// other policy opens up.
+func buildNetworkPolicy(tt *shipper.TrafficTarget, appName, releaseName string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName(releaseName),
+			Namespace: tt.Namespace,
+			Labels: map[string]string{
+				shipper.AppLabel:     appName,
+				shipper.ReleaseLabel: releaseName,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: shipper.SchemeGroupVersion.String(),
+					Kind:       "TrafficTarget",
+					Name:       tt.Name,
+					UID:        tt.UID,
+				},
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					shipper.AppLabel:     appName,
+					shipper.ReleaseLabel: releaseName,
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{}},
+					},
+				},
+			},
+		},
+	}
+}
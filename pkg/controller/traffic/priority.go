@@ -0,0 +1,30 @@
+package traffic
+
+import (
+	"k8s.io/client-go/tools/cache"
+
+	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+)
+
+// trafficTargetInFlight tells traffic targets that are shifting traffic,
+// and need to be synced as soon as possible for their release to move on,
+// from the ones that are only being revisited.
+func trafficTargetInFlight(lister listers.TrafficTargetLister) func(string) bool {
+	return func(key string) bool {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return true
+		}
+
+		tt, err := lister.TrafficTargets(namespace).Get(name)
+		if err != nil {
+			// Deleted targets are quick to sync, and better
+			// forgotten soon.
+			return true
+		}
+
+		ready, _ := targetutil.IsReady(tt.Status.Conditions)
+		return !ready || tt.Generation != tt.Status.ObservedGeneration
+	}
+}
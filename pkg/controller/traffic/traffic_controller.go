@@ -1,8 +1,11 @@
 package traffic
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -10,10 +13,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -23,10 +27,16 @@ import (
 	shipperclient "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/debug"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+	"github.com/bookingcom/shipper/pkg/tracing"
 	diffutil "github.com/bookingcom/shipper/pkg/util/diff"
 	"github.com/bookingcom/shipper/pkg/util/filters"
+	"github.com/bookingcom/shipper/pkg/util/index"
 	objectutil "github.com/bookingcom/shipper/pkg/util/object"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
@@ -34,14 +44,23 @@ import (
 const (
 	AgentName = "traffic-controller"
 
-	InProgress         = "InProgress"
-	InternalError      = "InternalError"
-	PodsNotInEndpoints = "PodsNotInEndpoints"
-	PodsNotReady       = "PodsNotReady"
-
-	TrafficTargetConditionChanged = "TrafficTargetConditionChanged"
+	Draining                    = "Draining"
+	ExternalLoadBalancerFailed  = "ExternalLoadBalancerFailed"
+	InProgress                  = "InProgress"
+	InternalError               = "InternalError"
+	KnativeTrafficFailed        = "KnativeTrafficFailed"
+	PodsNotInEndpoints          = "PodsNotInEndpoints"
+	PodsNotReady                = "PodsNotReady"
+	ShadowTrafficNotSupported   = "ShadowTrafficNotSupported"
+	StickyBucketingNotSupported = "StickyBucketingNotSupported"
+	TrafficBackendNotAvailable  = "TrafficBackendNotAvailable"
 )
 
+// knativeRecheckPeriod is how long a TrafficTarget using the knative backend
+// waits before checking again whether Knative routed traffic as asked, as
+// nothing tells us when it does.
+const knativeRecheckPeriod = 5 * time.Second
+
 // Controller is the controller implementation for TrafficTarget resources.
 type Controller struct {
 	shipperClient shipperclient.Interface
@@ -50,8 +69,8 @@ type Controller struct {
 	trafficTargetsLister listers.TrafficTargetLister
 	trafficTargetsSynced cache.InformerSynced
 
-	podsLister corelisters.PodLister
-	podsSynced cache.InformerSynced
+	podsIndexer cache.Indexer
+	podsSynced  cache.InformerSynced
 
 	servicesLister corelisters.ServiceLister
 	servicesSynced cache.InformerSynced
@@ -59,8 +78,59 @@ type Controller struct {
 	endpointsLister corelisters.EndpointsLister
 	endpointsSynced cache.InformerSynced
 
+	networkPoliciesLister networkinglisters.NetworkPolicyLister
+	networkPoliciesSynced cache.InformerSynced
+
 	workqueue workqueue.RateLimitingInterface
 	recorder  record.EventRecorder
+
+	// externalLB is optional. When set, the weight achieved by each
+	// release in this cluster is also published to it.
+	externalLB ExternalLoadBalancer
+
+	// knativeClient is optional. When set, traffic targets using the
+	// knative backend program the traffic of Knative Services with it.
+	knativeClient dynamic.Interface
+
+	publishedWeightsMutex sync.Mutex
+	publishedWeights      map[string]ExternalWeight
+
+	convergingSinceMutex sync.Mutex
+	convergingSince      map[string]time.Time
+
+	// drainTimeout is how long to wait for in-flight syncs to finish
+	// when shutting down.
+	drainTimeout time.Duration
+
+	// drainPeriod is how long a TrafficTarget waits after taking pods out of
+	// traffic before it reports Ready, so requests they were serving can finish
+	// before the strategy moves on and those pods are scaled down. Disabled if
+	// zero.
+	drainPeriod time.Duration
+
+	// isolateContenders makes the controller keep a NetworkPolicy around for
+	// every release that isn't supposed to get any traffic yet, so its pods can
+	// only be reached from their own namespace until the strategy gets to its
+	// first traffic step.
+	isolateContenders bool
+
+	// clusterName labels the convergence metrics of this cluster.
+	clusterName string
+}
+
+// Options are how a TrafficTarget controller shifts traffic, on top of
+// the clients and informers it works with. They're described on the
+// fields of Controller they end up in.
+type Options struct {
+	ExternalLB        ExternalLoadBalancer
+	KnativeClient     dynamic.Interface
+	DrainTimeout      time.Duration
+	DrainPeriod       time.Duration
+	IsolateContenders bool
+	ClusterName       string
+	// LowPriorityMaxWait is how long traffic targets that aren't rolling
+	// out wait for in-flight ones to be synced at most.
+	LowPriorityMaxWait time.Duration
 }
 
 // NewController returns a new TrafficTarget controller.
@@ -70,11 +140,13 @@ func NewController(
 	shipperClient shipperclient.Interface,
 	shipperInformerFactory informers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	opts Options,
 ) *Controller {
 	trafficTargetInformer := shipperInformerFactory.Shipper().V1alpha1().TrafficTargets()
 	podsInformer := kubeInformerFactory.Core().V1().Pods()
 	servicesInformer := kubeInformerFactory.Core().V1().Services()
 	endpointsInformer := kubeInformerFactory.Core().V1().Endpoints()
+	networkPoliciesInformer := kubeInformerFactory.Networking().V1().NetworkPolicies()
 
 	controller := &Controller{
 		shipperClient: shipperClient,
@@ -83,8 +155,8 @@ func NewController(
 		trafficTargetsLister: trafficTargetInformer.Lister(),
 		trafficTargetsSynced: trafficTargetInformer.Informer().HasSynced,
 
-		podsLister: podsInformer.Lister(),
-		podsSynced: podsInformer.Informer().HasSynced,
+		podsIndexer: podsInformer.Informer().GetIndexer(),
+		podsSynced:  podsInformer.Informer().HasSynced,
 
 		servicesLister: servicesInformer.Lister(),
 		servicesSynced: servicesInformer.Informer().HasSynced,
@@ -92,8 +164,32 @@ func NewController(
 		endpointsLister: endpointsInformer.Lister(),
 		endpointsSynced: endpointsInformer.Informer().HasSynced,
 
-		workqueue: workqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "traffic_controller_traffictargets"),
-		recorder:  recorder,
+		networkPoliciesLister: networkPoliciesInformer.Lister(),
+		networkPoliciesSynced: networkPoliciesInformer.Informer().HasSynced,
+
+		workqueue: shipperworkqueue.NewPriorityQueue(
+			shipperworkqueue.NewNamedRateLimitingQueue(shipperworkqueue.NewDefaultControllerRateLimiter(), "traffic_controller_traffictargets"),
+			trafficTargetInFlight(trafficTargetInformer.Lister()),
+			opts.LowPriorityMaxWait,
+		),
+		recorder: recorder,
+
+		externalLB:       opts.ExternalLB,
+		knativeClient:    opts.KnativeClient,
+		publishedWeights: make(map[string]ExternalWeight),
+		convergingSince:  make(map[string]time.Time),
+
+		drainTimeout: opts.DrainTimeout,
+
+		drainPeriod: opts.DrainPeriod,
+
+		isolateContenders: opts.IsolateContenders,
+
+		clusterName: opts.ClusterName,
+	}
+
+	if err := index.AddIndexers(podsInformer.Informer()); err != nil {
+		runtime.HandleError(fmt.Errorf("failed to add pod indexers: %s", err))
 	}
 
 	trafficTargetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -101,7 +197,7 @@ func NewController(
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueAllTrafficTargets(new)
 		},
-		DeleteFunc: controller.enqueueAllTrafficTargets,
+		DeleteFunc: objectutil.OnDelete(controller.enqueueAllTrafficTargets),
 	})
 
 	// an event on an Endpoints object enqueues all traffic targets for an
@@ -115,7 +211,7 @@ func NewController(
 		FilterFunc: filters.BelongsToApp,
 		Handler: cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.enqueueAllTrafficTargets,
-			DeleteFunc: controller.enqueueAllTrafficTargets,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueAllTrafficTargets),
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				controller.enqueueAllTrafficTargets(newObj)
 			},
@@ -126,7 +222,7 @@ func NewController(
 		FilterFunc: filters.BelongsToRelease,
 		Handler: cache.ResourceEventHandlerFuncs{
 			AddFunc:    controller.enqueueTrafficTargetFromPod,
-			DeleteFunc: controller.enqueueTrafficTargetFromPod,
+			DeleteFunc: objectutil.OnDelete(controller.enqueueTrafficTargetFromPod),
 		},
 	})
 
@@ -144,23 +240,19 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) {
 	klog.V(2).Info("Starting Traffic controller")
 	defer klog.V(2).Info("Shutting down Traffic controller")
 
-	if ok := cache.WaitForCacheSync(stopCh, c.trafficTargetsSynced); !ok {
+	if ok := cache.WaitForCacheSync(stopCh, c.trafficTargetsSynced, c.networkPoliciesSynced); !ok {
 		runtime.HandleError(fmt.Errorf("failed to wait for caches to sync"))
 		return
 	}
 
-	for i := 0; i < threadiness; i++ {
-		go wait.Until(c.runWorker, time.Second, stopCh)
-	}
+	workers := shutdown.NewWorkers("Traffic controller")
+	workers.Start(c.workqueue, threadiness, c.processNextWorkItem, stopCh)
 
 	klog.V(4).Info("Started Traffic controller")
 
 	<-stopCh
-}
 
-func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
-	}
+	workers.Drain(c.drainTimeout)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -183,11 +275,15 @@ func (c *Controller) processNextWorkItem() bool {
 	}
 
 	shouldRetry := false
+	span := tracing.StartSync(AgentName, "TrafficTarget", key)
 	err := c.syncHandler(key)
+	span.End(err)
+	debug.ObserveSync(AgentName, "TrafficTarget", key, err)
 
 	if err != nil {
 		shouldRetry = shippererrors.ShouldRetry(err)
 		runtime.HandleError(fmt.Errorf("error syncing TrafficTarget %q (will retry: %t): %s", key, shouldRetry, err.Error()))
+		shippermetrics.ObserveSyncError(AgentName, err)
 	}
 
 	if shouldRetry {
@@ -212,6 +308,10 @@ func (c *Controller) syncHandler(key string) error {
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			klog.V(3).Infof("TrafficTarget %q has been deleted", key)
+			c.publishedWeightsMutex.Lock()
+			delete(c.publishedWeights, key)
+			c.publishedWeightsMutex.Unlock()
+			c.forgetConvergence(key)
 			return nil
 		}
 
@@ -219,9 +319,19 @@ func (c *Controller) syncHandler(key string) error {
 			WithShipperKind("TrafficTarget")
 	}
 
+	// Visit the target again later even if nothing changes, so its
+	// status shows it is not stale.
+	c.workqueue.AddAfter(key, targetutil.SyncHeartbeatPeriod)
+
 	tt, err := c.processTrafficTarget(initialTT.DeepCopy())
+	if err != nil {
+		c.recorder.Event(tt, corev1.EventTypeWarning, shipperevents.TrafficShiftFailed, err.Error())
+	}
+
+	c.observeConvergence(key, tt)
 
-	if !reflect.DeepEqual(initialTT, tt) {
+	changed := !reflect.DeepEqual(initialTT, tt)
+	if targetutil.RecordSync(&tt.Status.TargetSyncStatus, changed) {
 		if _, err := c.shipperClient.ShipperV1alpha1().TrafficTargets(namespace).UpdateStatus(tt); err != nil {
 			return shippererrors.NewKubeclientUpdateError(tt, err).
 				WithShipperKind("TrafficTarget")
@@ -257,9 +367,11 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 
 		tt.Status.ObservedGeneration = tt.Generation
 		tt.Status.AchievedTraffic = achievedTraffic
+		tt.Status.Conditions = targetutil.SetProgressing(tt.Status.Conditions,
+			fmt.Sprintf("achieved traffic weight %d", achievedTraffic))
 
 		if !diff.IsEmpty() {
-			c.recorder.Event(tt, corev1.EventTypeNormal, TrafficTargetConditionChanged, diff.String())
+			c.recorder.Event(tt, corev1.EventTypeNormal, shipperevents.TrafficTargetConditionChanged, diff.String())
 		}
 	}()
 
@@ -279,6 +391,42 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 		return tt, err
 	}
 
+	err = c.checkTrafficBackend(tt.Spec.Backend)
+	if err != nil {
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionFalse,
+			TrafficBackendNotAvailable,
+			err.Error(),
+		)
+
+		return tt, err
+	}
+
+	err = checkShadowTraffic(tt)
+	if err != nil {
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionFalse,
+			ShadowTrafficNotSupported,
+			err.Error(),
+		)
+
+		return tt, err
+	}
+
+	err = checkBucketing(tt)
+	if err != nil {
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionFalse,
+			StickyBucketingNotSupported,
+			err.Error(),
+		)
+
+		return tt, err
+	}
+
 	appSelector := labels.Set{shipper.AppLabel: appName}.AsSelector()
 	allTTs, err := c.trafficTargetsLister.TrafficTargets(tt.Namespace).List(appSelector)
 	if err != nil {
@@ -299,6 +447,50 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 		return tt, err
 	}
 
+	if tt.Spec.Backend == shipper.TrafficBackendKnative {
+		knativeStatus, err := c.shiftKnativeTraffic(tt.Namespace, appName, releaseName, releaseWeights)
+		if err != nil {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				KnativeTrafficFailed,
+				err.Error(),
+			)
+
+			return tt, err
+		}
+
+		operationalCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeOperational,
+			corev1.ConditionTrue,
+			"",
+			"",
+		)
+
+		// achievedTraffic is used by the defer at the top of this func
+		achievedTraffic = knativeStatus.achievedTrafficWeight
+
+		if knativeStatus.ready {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionTrue,
+				"",
+				"",
+			)
+		} else {
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				InProgress,
+				knativeStatus.message,
+			)
+
+			c.workqueue.AddAfter(objectutil.MetaKey(tt), knativeRecheckPeriod)
+		}
+
+		return tt, nil
+	}
+
 	appPods, endpoints, err := c.getClusterObjects(tt)
 	if err != nil {
 		operationalCond = targetutil.NewTargetCondition(
@@ -318,6 +510,20 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 		"",
 	)
 
+	// The NetworkPolicy comes off before any pods are shifted into
+	// traffic, so they can be reached as soon as they are.
+	err = c.syncNetworkPolicy(tt, appName, releaseName)
+	if err != nil {
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			InternalError,
+			err.Error(),
+		)
+
+		return tt, err
+	}
+
 	trafficStatus := buildTrafficShiftingStatus(
 		appName, releaseName,
 		releaseWeights,
@@ -326,7 +532,37 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 	// achievedTraffic is used by the defer at the top of this func
 	achievedTraffic = trafficStatus.achievedTrafficWeight
 
+	err = c.publishExternalWeight(tt, appName, releaseName, achievedTraffic)
+	if err != nil {
+		readyCond = targetutil.NewTargetCondition(
+			shipper.TargetConditionTypeReady,
+			corev1.ConditionFalse,
+			ExternalLoadBalancerFailed,
+			err.Error(),
+		)
+
+		return tt, err
+	}
+
 	if trafficStatus.ready {
+		if remaining := drainRemaining(tt.Status.DrainingSince, c.drainPeriod); remaining > 0 {
+			msg := fmt.Sprintf(
+				"waiting %s for in-flight requests to drain from pods taken out of traffic",
+				remaining.Round(time.Second))
+			readyCond = targetutil.NewTargetCondition(
+				shipper.TargetConditionTypeReady,
+				corev1.ConditionFalse,
+				Draining,
+				msg,
+			)
+
+			c.workqueue.AddAfter(objectutil.MetaKey(tt), remaining)
+
+			return tt, nil
+		}
+
+		tt.Status.DrainingSince = nil
+
 		readyCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeReady,
 			corev1.ConditionTrue,
@@ -353,6 +589,11 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 			return tt, err
 		}
 
+		if c.drainPeriod > 0 && len(trafficStatus.podsToShift[shipper.Disabled]) > 0 {
+			now := metav1.Now()
+			tt.Status.DrainingSince = &now
+		}
+
 		readyCond = targetutil.NewTargetCondition(
 			shipper.TargetConditionTypeReady,
 			corev1.ConditionFalse,
@@ -390,10 +631,20 @@ func (c *Controller) processTrafficTarget(tt *shipper.TrafficTarget) (*shipper.T
 	return tt, nil
 }
 
-func (c *Controller) getClusterObjects(tt *shipper.TrafficTarget) ([]*corev1.Pod, *corev1.Endpoints, error) {
+// drainRemaining returns how much longer pods taken out of traffic at
+// drainingSince need to drain for to have drained for drainPeriod.
+func drainRemaining(drainingSince *metav1.Time, drainPeriod time.Duration) time.Duration {
+	if drainingSince == nil || drainPeriod <= 0 {
+		return 0
+	}
+
+	return drainingSince.Add(drainPeriod).Sub(time.Now())
+}
+
+func (c *Controller) getClusterObjects(tt *shipper.TrafficTarget) ([]*corev1.Pod, []*corev1.Endpoints, error) {
 	appName, _ := objectutil.GetApplicationLabel(tt)
 	appSelector := labels.Set{shipper.AppLabel: appName}.AsSelector()
-	appPods, err := c.podsLister.Pods(tt.Namespace).List(appSelector)
+	appPods, err := index.PodsForApplication(c.podsIndexer, tt.Namespace, appName, appSelector)
 	if err != nil {
 		return nil, nil, shippererrors.NewKubeclientListError(
 			corev1.SchemeGroupVersion.WithKind("Pod"),
@@ -411,23 +662,146 @@ func (c *Controller) getClusterObjects(tt *shipper.TrafficTarget) ([]*corev1.Pod
 			serviceGVK, tt.Namespace, serviceSelector, err)
 	}
 
-	if len(services) != 1 {
+	if len(services) == 0 {
 		err := shippererrors.NewUnexpectedObjectCountFromSelectorError(
 			serviceSelector, serviceGVK, 1, len(services))
 		return nil, nil, err
 	}
 
-	svc := services[0]
+	// All production services select the same pods, so traffic is
+	// shifted for all of them at once. We still need to look at the
+	// endpoints of every one of them to figure out whether pods are
+	// actually ready to receive traffic everywhere.
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
 
-	endpoints, err := c.endpointsLister.Endpoints(svc.Namespace).Get(svc.Name)
-	if err != nil {
-		return nil, nil, shippererrors.NewKubeclientGetError(svc.Namespace, svc.Name, err).
-			WithCoreV1Kind("Endpoints")
+	endpoints := make([]*corev1.Endpoints, 0, len(services))
+	for _, svc := range services {
+		ep, err := c.endpointsLister.Endpoints(svc.Namespace).Get(svc.Name)
+		if err != nil {
+			return nil, nil, shippererrors.NewKubeclientGetError(svc.Namespace, svc.Name, err).
+				WithCoreV1Kind("Endpoints")
+		}
+
+		endpoints = append(endpoints, ep)
 	}
 
 	return appPods, endpoints, nil
 }
 
+// checkTrafficBackend returns an error if the traffic backend requested by a
+// traffic target can't be handled by this controller. Pod labels are always
+// used to shift traffic inside the cluster, so the only other backends we
+// support are an external load balancer and Knative, and only when they're
+// configured.
+func (c *Controller) checkTrafficBackend(backend string) error {
+	switch backend {
+	case "", shipper.TrafficBackendPodLabel:
+		return nil
+	case shipper.TrafficBackendExternalLB:
+		if c.externalLB != nil {
+			return nil
+		}
+	case shipper.TrafficBackendKnative:
+		if c.knativeClient != nil {
+			return nil
+		}
+	}
+
+	return shippererrors.NewTrafficBackendNotAvailableError(backend)
+}
+
+// checkShadowTraffic returns an error if a traffic target asks for traffic to
+// be mirrored to its release, and its backend can't do it. Only external
+// load balancers are told to, and it's up to them to mirror requests.
+func checkShadowTraffic(tt *shipper.TrafficTarget) error {
+	if tt.Spec.ShadowWeight == 0 || tt.Spec.Backend == shipper.TrafficBackendExternalLB {
+		return nil
+	}
+
+	backend := tt.Spec.Backend
+	if backend == "" {
+		backend = shipper.TrafficBackendPodLabel
+	}
+
+	return shippererrors.NewShadowTrafficNotSupportedError(backend)
+}
+
+// checkBucketing returns an error if a traffic target is part of an
+// experiment, and its backend can't keep users on the same release. Pod
+// labels and service meshes split traffic per request, so only external
+// load balancers can bucket users.
+func checkBucketing(tt *shipper.TrafficTarget) error {
+	if tt.Spec.Bucketing == nil || tt.Spec.Backend == shipper.TrafficBackendExternalLB {
+		return nil
+	}
+
+	backend := tt.Spec.Backend
+	if backend == "" {
+		backend = shipper.TrafficBackendPodLabel
+	}
+
+	return shippererrors.NewStickyBucketingNotSupportedError(backend)
+}
+
+// publishExternalWeight informs the external load balancer of the weight
+// achieved by a release in this cluster, and of how much traffic to mirror
+// to it and how to bucket users, if the traffic target asks for it. Weights are only published when
+// they change, to avoid hammering the load balancer on every resync.
+func (c *Controller) publishExternalWeight(tt *shipper.TrafficTarget, appName, releaseName string, weight uint32) error {
+	key := objectutil.MetaKey(tt)
+
+	if tt.Spec.Backend != shipper.TrafficBackendExternalLB {
+		// The backend may have been switched away from the load
+		// balancer, which is then told again if it's switched back.
+		c.publishedWeightsMutex.Lock()
+		delete(c.publishedWeights, key)
+		c.publishedWeightsMutex.Unlock()
+		return nil
+	}
+
+	// Clusters out of traffic for maintenance keep shifting traffic
+	// between their releases, so they're ready to take it back as soon
+	// as they're put back in, but the load balancer is told to send
+	// them none.
+	toPublish := ExternalWeight{
+		Namespace:    tt.Namespace,
+		Application:  appName,
+		Release:      releaseName,
+		Weight:       weight,
+		ShadowWeight: tt.Spec.ShadowWeight,
+		Bucketing:    tt.Spec.Bucketing,
+	}
+	if tt.Spec.TrafficDisabled {
+		toPublish.Weight = 0
+		toPublish.ShadowWeight = 0
+		toPublish.Bucketing = nil
+	}
+
+	c.publishedWeightsMutex.Lock()
+	published, ok := c.publishedWeights[key]
+	c.publishedWeightsMutex.Unlock()
+
+	if ok && reflect.DeepEqual(published, toPublish) {
+		return nil
+	}
+
+	// The mutex isn't held while talking to the load balancer, so a slow
+	// one only holds up the worker syncing this traffic target. No other
+	// worker syncs it meanwhile, so its published weight can't change.
+	err := c.externalLB.SetWeight(context.Background(), toPublish)
+	if err != nil {
+		return shippererrors.NewExternalLoadBalancerError(tt.Namespace, releaseName, err)
+	}
+
+	c.publishedWeightsMutex.Lock()
+	c.publishedWeights[key] = toPublish
+	c.publishedWeightsMutex.Unlock()
+
+	return nil
+}
+
 // enqueueTrafficTarget takes a TrafficTarget resource and converts it into a
 // namespace/name string which is then put onto the work queue. This method
 // should *not* be passed resources of any type other than TrafficTarget.
@@ -15,7 +15,9 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	"github.com/bookingcom/shipper/pkg/util/shutdown"
 	targetutil "github.com/bookingcom/shipper/pkg/util/target"
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
 )
 
 const (
@@ -47,6 +49,7 @@ func TestSuccess(t *testing.T) {
 				pods:          podStatus{withTraffic: podCount},
 			},
 		},
+		0,
 	)
 }
 
@@ -91,6 +94,7 @@ func TestMultipleTrafficTargets(t *testing.T) {
 	// the circumstances.
 	foobarAStatus := buildSuccessStatus(foobarA.Spec)
 	foobarAStatus.AchievedTraffic = 50
+	foobarAStatus.Conditions[1] = buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonConverged, 50)
 	foobarBStatus := buildSuccessStatus(foobarB.Spec)
 	foobarBStatus.AchievedTraffic = 40
 
@@ -108,6 +112,7 @@ func TestMultipleTrafficTargets(t *testing.T) {
 				pods:          podsForFoobarB,
 			},
 		},
+		0,
 	)
 }
 
@@ -164,6 +169,7 @@ func TestTrafficShiftingWithPodsNotReady(t *testing.T) {
 				Type:   shipper.TargetConditionTypeOperational,
 				Status: corev1.ConditionTrue,
 			},
+			buildProgressingCondition(corev1.ConditionTrue, targetutil.ProgressingReasonInProgress, 7),
 			{
 				Type:    shipper.TargetConditionTypeReady,
 				Status:  corev1.ConditionFalse,
@@ -182,13 +188,197 @@ func TestTrafficShiftingWithPodsNotReady(t *testing.T) {
 				pods:          podStatus{withTraffic: len(pods)},
 			},
 		},
+		0,
 	)
 }
 
+// TestMultipleServices verifies that the traffic controller shifts traffic for
+// applications exposing more than one production service, and that it reports
+// readiness based on the endpoints of all of them.
+func TestMultipleServices(t *testing.T) {
+	podCount := 2
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 10)
+
+	grpcService := buildService(shippertesting.TestApp)
+	grpcService.Name = fmt.Sprintf("%s-grpc", grpcService.Name)
+	grpcEndpoints := buildEndpoints(shippertesting.TestApp)
+	grpcEndpoints.Name = grpcService.Name
+
+	objects := buildWorldWithPods(shippertesting.TestApp, ttName, podCount, noTraffic)
+	objects = append(objects, grpcService, grpcEndpoints)
+
+	runTrafficControllerTest(t,
+		objects,
+		[]trafficTargetTestExpectation{
+			{
+				trafficTarget: tt,
+				status:        buildSuccessStatus(tt.Spec),
+				pods:          podStatus{withTraffic: podCount},
+			},
+		},
+		0,
+	)
+}
+
+// TestUnavailableBackend verifies that the traffic controller refuses to
+// shift traffic for traffic targets requesting a backend it doesn't know how
+// to handle, and that it reports it clearly.
+func TestUnavailableBackend(t *testing.T) {
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 10)
+	tt.Spec.Backend = shipper.TrafficBackendIstio
+
+	status := shipper.TrafficTargetStatus{
+		Conditions: []shipper.TargetCondition{
+			{
+				Type:    shipper.TargetConditionTypeOperational,
+				Status:  corev1.ConditionFalse,
+				Reason:  TrafficBackendNotAvailable,
+				Message: `traffic backend "istio" is not available in this cluster`,
+			},
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonNotOperational, 0),
+			{
+				Type:   shipper.TargetConditionTypeReady,
+				Status: corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	runTrafficControllerTest(t,
+		buildWorldWithPods(shippertesting.TestApp, ttName, 1, noTraffic),
+		[]trafficTargetTestExpectation{
+			{
+				trafficTarget: tt,
+				status:        status,
+				pods:          podStatus{withoutTraffic: 1},
+			},
+		},
+		0,
+	)
+}
+
+// TestShadowTrafficNotSupported verifies that the traffic controller refuses
+// to shift traffic for traffic targets asking for traffic to be mirrored to
+// their release with a backend that can't do it.
+func TestShadowTrafficNotSupported(t *testing.T) {
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 0)
+	tt.Spec.ShadowWeight = 10
+
+	status := shipper.TrafficTargetStatus{
+		Conditions: []shipper.TargetCondition{
+			{
+				Type:    shipper.TargetConditionTypeOperational,
+				Status:  corev1.ConditionFalse,
+				Reason:  ShadowTrafficNotSupported,
+				Message: `traffic backend "pod-label" can not mirror traffic`,
+			},
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonNotOperational, 0),
+			{
+				Type:   shipper.TargetConditionTypeReady,
+				Status: corev1.ConditionUnknown,
+			},
+		},
+	}
+
+	runTrafficControllerTest(t,
+		buildWorldWithPods(shippertesting.TestApp, ttName, 1, noTraffic),
+		[]trafficTargetTestExpectation{
+			{
+				trafficTarget: tt,
+				status:        status,
+				pods:          podStatus{withoutTraffic: 1},
+			},
+		},
+		0,
+	)
+}
+
+// TestDrainPeriod verifies that the traffic controller waits for the drain
+// period before reporting a traffic target that took pods out of traffic as
+// ready.
+func TestDrainPeriod(t *testing.T) {
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 0)
+
+	f := shippertesting.NewControllerTestFixture()
+	for _, object := range buildWorldWithPods(shippertesting.TestApp, ttName, 1, withTraffic) {
+		f.KubeClient.Tracker().Add(object)
+	}
+	f.ShipperClient.Tracker().Add(tt)
+
+	runController(f, time.Hour, false)
+
+	ttGVR := shipper.SchemeGroupVersion.WithResource("traffictargets")
+	object, err := f.ShipperClient.Tracker().Get(ttGVR, tt.Namespace, tt.Name)
+	if err != nil {
+		t.Fatalf("could not Get TrafficTarget: %s", err)
+	}
+
+	actualTT := object.(*shipper.TrafficTarget)
+	if actualTT.Status.DrainingSince == nil {
+		t.Fatalf("expected TrafficTarget to be draining")
+	}
+
+	readyCond := targetutil.GetTargetCondition(actualTT.Status.Conditions, shipper.TargetConditionTypeReady)
+	if readyCond == nil || readyCond.Status != corev1.ConditionFalse || readyCond.Reason != Draining {
+		t.Fatalf("expected Ready condition to be False with reason %q, got %+v", Draining, readyCond)
+	}
+
+	assertPodTraffic(t, actualTT, f.FakeCluster, podStatus{withoutTraffic: 1})
+}
+
+// TestDrainPeriodOver verifies that the traffic controller reports traffic
+// targets as ready once their drain period is over.
+func TestDrainPeriodOver(t *testing.T) {
+	tt := buildTrafficTarget(shippertesting.TestApp, ttName, 0)
+	drainingSince := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	tt.Status.DrainingSince = &drainingSince
+
+	runTrafficControllerTest(t,
+		buildWorldWithPods(shippertesting.TestApp, ttName, 1, noTraffic),
+		[]trafficTargetTestExpectation{
+			{
+				trafficTarget: tt,
+				status:        buildSuccessStatus(tt.Spec),
+				pods:          podStatus{withoutTraffic: 1},
+			},
+		},
+		time.Hour,
+	)
+}
+
+// TestIsolateContenders verifies that the traffic controller keeps a
+// NetworkPolicy for releases with no weight, and removes it once they get
+// some.
+func TestIsolateContenders(t *testing.T) {
+	contender := buildTrafficTarget(shippertesting.TestApp, "contender", 0)
+	incumbent := buildTrafficTarget(shippertesting.TestApp, "incumbent", 100)
+
+	f := shippertesting.NewControllerTestFixture()
+	objects := buildWorldWithPods(shippertesting.TestApp, incumbent.Name, 1, withTraffic)
+	objects = addPodsToList(objects, buildPods(shippertesting.TestApp, contender.Name, 1, noTraffic))
+	objects = append(objects, buildNetworkPolicy(incumbent, shippertesting.TestApp, incumbent.Name))
+	for _, object := range objects {
+		f.KubeClient.Tracker().Add(object)
+	}
+	f.ShipperClient.Tracker().Add(contender)
+	f.ShipperClient.Tracker().Add(incumbent)
+
+	runController(f, 0, true)
+
+	networkPolicies := f.KubeClient.NetworkingV1().NetworkPolicies(shippertesting.TestNamespace)
+	if _, err := networkPolicies.Get(networkPolicyName(contender.Name), metav1.GetOptions{}); err != nil {
+		t.Errorf("expected contender to be isolated: %s", err)
+	}
+
+	if _, err := networkPolicies.Get(networkPolicyName(incumbent.Name), metav1.GetOptions{}); err == nil {
+		t.Errorf("expected incumbent not to be isolated")
+	}
+}
+
 func runTrafficControllerTest(
 	t *testing.T,
 	objects []runtime.Object,
 	expectations []trafficTargetTestExpectation,
+	drainPeriod time.Duration,
 ) {
 	f := shippertesting.NewControllerTestFixture()
 
@@ -200,7 +390,7 @@ func runTrafficControllerTest(
 		f.ShipperClient.Tracker().Add(expectation.trafficTarget)
 	}
 
-	runController(f)
+	runController(f, drainPeriod, false)
 
 	ttGVR := shipper.SchemeGroupVersion.WithResource("traffictargets")
 	for _, expectation := range expectations {
@@ -288,13 +478,19 @@ func assertPodTraffic(
 	}
 }
 
-func runController(f *shippertesting.ControllerTestFixture) {
+func runController(f *shippertesting.ControllerTestFixture, drainPeriod time.Duration, isolateContenders bool) {
 	controller := NewController(
 		f.KubeClient,
 		f.KubeInformerFactory,
 		f.ShipperClient,
 		f.ShipperInformerFactory,
 		f.Recorder,
+		Options{
+			DrainTimeout:       shutdown.DefaultDrainTimeout,
+			DrainPeriod:        drainPeriod,
+			IsolateContenders:  isolateContenders,
+			LowPriorityMaxWait: shipperworkqueue.DefaultLowPriorityMaxWait,
+		},
 	)
 
 	stopCh := make(chan struct{})
@@ -309,20 +505,22 @@ func runController(f *shippertesting.ControllerTestFixture) {
 	if err != nil {
 		panic(fmt.Sprintf("can't list endpoints: %s", err))
 	}
-	if len(endpointsList) != 1 {
-		panic(fmt.Sprintf("expected a single endpoint, got %d", len(endpointsList)))
+	if len(endpointsList) == 0 {
+		panic("expected at least one endpoint, got none")
 	}
 
 	var mutex sync.Mutex
-	endpoints := endpointsList[0]
 	handlerFn := func(pod *corev1.Pod) {
 		mutex.Lock()
 		defer mutex.Unlock()
 
-		endpoints = shiftPodInEndpoints(pod, endpoints)
-		_, err = kubeclient.CoreV1().Endpoints(endpoints.Namespace).Update(endpoints)
-		if err != nil {
-			panic(fmt.Sprintf("can't update endpoints: %s", err))
+		for i, endpoints := range endpointsList {
+			endpoints = shiftPodInEndpoints(pod, endpoints)
+			_, err = kubeclient.CoreV1().Endpoints(endpoints.Namespace).Update(endpoints)
+			if err != nil {
+				panic(fmt.Sprintf("can't update endpoints: %s", err))
+			}
+			endpointsList[i] = endpoints
 		}
 	}
 
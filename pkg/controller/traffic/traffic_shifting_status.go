@@ -30,11 +30,13 @@ type trafficShiftingStatus struct {
 // ready according to the state of the Endpoints object, and the currently
 // achieved weight for a release. If the current state is different from the
 // desired one, it also returns which pods need to receive which labels to move
-// forward.
+// forward. When an application exposes more than one production Service, a
+// pod is only considered ready once it is ready in the Endpoints of all of
+// them.
 func buildTrafficShiftingStatus(
 	appName, releaseName string,
 	releaseTargetWeights releaseWeights,
-	endpoints *corev1.Endpoints,
+	endpoints []*corev1.Endpoints,
 	appPods []*corev1.Pod,
 ) trafficShiftingStatus {
 	releaseSelector := labels.Set(map[string]string{
@@ -126,10 +128,10 @@ func buildPodsToShift(
 // summarizePods returns an aggregated summary of the current state of pods:
 // which pods are labeled to receive (or not receive) traffic, how many belong
 // to the specified release, and how many are ready according to the Endpoints
-// object.
+// objects.
 func summarizePods(
 	pods []*corev1.Pod,
-	endpoints *corev1.Endpoints,
+	endpoints []*corev1.Endpoints,
 	releaseSelector labels.Selector,
 ) (map[string][]*corev1.Pod, int, int, int) {
 	podsInRelease := make(map[string]struct{})
@@ -154,25 +156,41 @@ func summarizePods(
 		podsByTrafficStatus[v] = append(podsByTrafficStatus[v], pod)
 	}
 
-	podReadiness := make(map[string]bool)
-	for _, subset := range endpoints.Subsets {
-		markAddressReadiness(podReadiness, subset.Addresses, true)
-		markAddressReadiness(podReadiness, subset.NotReadyAddresses, false)
+	// podReadiness is keyed by pod name, and holds the readiness of that
+	// pod in each of the Endpoints objects it has been seen in.
+	podReadiness := make(map[string][]bool)
+	for _, ep := range endpoints {
+		epReadiness := make(map[string]bool)
+		for _, subset := range ep.Subsets {
+			markAddressReadiness(epReadiness, subset.Addresses, true)
+			markAddressReadiness(epReadiness, subset.NotReadyAddresses, false)
+		}
+
+		for podName, podReady := range epReadiness {
+			podReadiness[podName] = append(podReadiness[podName], podReady)
+		}
 	}
 
 	podsReady := 0
 	podsNotReady := 0
-	for podName, podReady := range podReadiness {
+	for podName, readiness := range podReadiness {
 		_, belongsToRelease := podsInRelease[podName]
 
 		if !belongsToRelease {
 			continue
 		}
 
-		if podReady {
-			podsReady++
-		} else {
+		podReady := true
+		for _, ready := range readiness {
+			podReady = podReady && ready
+		}
+
+		if !podReady {
 			podsNotReady++
+		} else if len(readiness) == len(endpoints) {
+			// A pod that is ready but hasn't made it to all
+			// Endpoints yet is neither ready nor not ready.
+			podsReady++
 		}
 	}
 
@@ -200,14 +218,17 @@ func markAddressReadiness(
 }
 
 /*
-	Transform a list of each release's traffic target object :
-	[
-		{ tt-reviewsapi-1: 90 },
-		{ tt-reviewsapi-2: 5 },
-		{ tt-reviewsapi-3: 5 },
-	]
-
-	Into a map of weight per release:
+Transform a list of each release's traffic target object :
+[
+
+	{ tt-reviewsapi-1: 90 },
+	{ tt-reviewsapi-2: 5 },
+	{ tt-reviewsapi-3: 5 },
+
+]
+
+Into a map of weight per release:
+
 	{
 		reviewsapi-1: 90,
 		reviewsapi-2: 5,
@@ -229,7 +229,42 @@ func TestTrafficShiftingPodsLabeledButNotReady(t *testing.T) {
 	trafficStatus := buildTrafficShiftingStatus(
 		shippertesting.TestApp, releaseName,
 		releaseWeights{releaseName: releaseWeight},
-		endpoints, appPods,
+		[]*corev1.Endpoints{endpoints}, appPods,
+	)
+
+	assertTrafficShiftingStatusExpectation(t, releaseName,
+		trafficShiftingStatusTestExpectation{
+			Release:               release{weight: releaseWeight},
+			Ready:                 false,
+			AchievedTrafficWeight: 5,
+			PodsLabeled:           2,
+			PodsReady:             1,
+		}, trafficStatus)
+}
+
+// TestTrafficShiftingMultipleEndpoints verifies that, when an application has
+// more than one production service, pods are only considered ready once they
+// made it to the endpoints of every one of them.
+func TestTrafficShiftingMultipleEndpoints(t *testing.T) {
+	releaseName := "foobar"
+	releaseWeight := uint32(10)
+
+	appPods := buildPods(shippertesting.TestApp, releaseName, 2, withTraffic)
+
+	frontendEndpoints := buildEndpoints(shippertesting.TestApp)
+	for _, pod := range appPods {
+		frontendEndpoints = shiftPodInEndpoints(pod, frontendEndpoints)
+	}
+
+	// The grpc service has only seen one of the pods so far.
+	grpcEndpoints := buildEndpoints(shippertesting.TestApp)
+	grpcEndpoints.Name = fmt.Sprintf("%s-grpc", grpcEndpoints.Name)
+	grpcEndpoints = shiftPodInEndpoints(appPods[0], grpcEndpoints)
+
+	trafficStatus := buildTrafficShiftingStatus(
+		shippertesting.TestApp, releaseName,
+		releaseWeights{releaseName: releaseWeight},
+		[]*corev1.Endpoints{frontendEndpoints, grpcEndpoints}, appPods,
 	)
 
 	assertTrafficShiftingStatusExpectation(t, releaseName,
@@ -263,7 +298,7 @@ func TestTrafficShiftingUnmanagedPods(t *testing.T) {
 	trafficStatus := buildTrafficShiftingStatus(
 		shippertesting.TestApp, releaseName,
 		releaseWeights{releaseName: releaseWeight},
-		endpoints, appPods,
+		[]*corev1.Endpoints{endpoints}, appPods,
 	)
 
 	assertTrafficShiftingStatusExpectation(t, releaseName,
@@ -326,7 +361,7 @@ func runBuildTestTrafficShiftingStatus(
 		trafficStatus := buildTrafficShiftingStatus(
 			shippertesting.TestApp, relName,
 			releaseWeights,
-			endpoints, appPods,
+			[]*corev1.Endpoints{endpoints}, appPods,
 		)
 
 		assertTrafficShiftingStatusExpectation(t, relName, expectation, trafficStatus)
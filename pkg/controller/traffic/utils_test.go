@@ -5,6 +5,7 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -77,7 +78,7 @@ func shiftPodInEndpoints(pod *corev1.Pod, endpoints *corev1.Endpoints) *corev1.E
 			},
 		})
 	} else if !podGetsTraffic && addressIndex >= 0 {
-		addresses = append(addresses[:addressIndex], addresses[:addressIndex+1]...)
+		addresses = append(addresses[:addressIndex], addresses[addressIndex+1:]...)
 	}
 
 	if ready {
@@ -110,11 +111,21 @@ func buildSuccessStatus(spec shipper.TrafficTargetSpec) shipper.TrafficTargetSta
 		AchievedTraffic: spec.Weight,
 		Conditions: []shipper.TargetCondition{
 			TargetConditionOperational,
+			buildProgressingCondition(corev1.ConditionFalse, targetutil.ProgressingReasonConverged, spec.Weight),
 			TargetConditionReady,
 		},
 	}
 }
 
+func buildProgressingCondition(status corev1.ConditionStatus, reason string, achievedTraffic uint32) shipper.TargetCondition {
+	return shipper.TargetCondition{
+		Type:    shipper.TargetConditionTypeProgressing,
+		Status:  status,
+		Reason:  reason,
+		Message: fmt.Sprintf("achieved traffic weight %d", achievedTraffic),
+	}
+}
+
 func buildService(app string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -34,7 +34,44 @@ var Application = &apiextensionv1beta1.CustomResourceDefinition{
 							"template",
 						},
 						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
-							"template": environmentValidation,
+							"template": partialEnvironmentValidation,
+							"adopt":    adoptionValidation,
+							"revisionHistoryLimit": apiextensionv1beta1.JSONSchemaProps{
+								Type:     "integer",
+								Nullable: true,
+							},
+							"templateRef": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Required: []string{
+									"name",
+								},
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"name": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+									"parameters": apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										AdditionalProperties: &apiextensionv1beta1.JSONSchemaPropsOrBool{
+											Schema: &apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+						},
+						// Applications following an ApplicationTemplate
+						// only need the parts of their template that
+						// aren't in it.
+						AnyOf: []apiextensionv1beta1.JSONSchemaProps{
+							{
+								Required: []string{"templateRef"},
+							},
+							{
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"template": completeEnvironmentValidation,
+								},
+							},
 						},
 					},
 				},
@@ -47,6 +84,12 @@ var Application = &apiextensionv1beta1.CustomResourceDefinition{
 				Description: "The application's latest release.",
 				JSONPath:    ".status.history[-1]",
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Phase",
+				Type:        "string",
+				Description: "How far the application's latest release is in being shipped.",
+				JSONPath:    ".status.phase",
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Rolling Out",
 				Type:        "string",
@@ -0,0 +1,56 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ApplicationDefault = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "applicationdefaults.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "applicationdefaults",
+			Singular:   "applicationdefault",
+			Kind:       "ApplicationDefault",
+			ShortNames: []string{"appdef"},
+			Categories: []string{"all", "shipper"},
+		},
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"strategy":            environmentValidation.Properties["strategy"],
+							"clusterRequirements": environmentValidation.Properties["clusterRequirements"],
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Preset",
+				Type:        "string",
+				Description: "The strategy preset of applications that don't have a strategy.",
+				JSONPath:    ".spec.strategy.preset",
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Age",
+				Type:        "date",
+				Description: "The defaults' age.",
+				JSONPath:    ".metadata.creationTimestamp",
+			},
+		},
+	},
+}
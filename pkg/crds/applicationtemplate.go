@@ -0,0 +1,89 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ApplicationTemplate = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "applicationtemplates.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "applicationtemplates",
+			Singular:   "applicationtemplate",
+			Kind:       "ApplicationTemplate",
+			ShortNames: []string{"apptmpl"},
+			Categories: []string{"all", "shipper"},
+		},
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Required: []string{
+							"template",
+						},
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"parameters": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										Required: []string{
+											"name",
+										},
+										Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+											"name": apiextensionv1beta1.JSONSchemaProps{
+												Type:    "string",
+												Pattern: `^[A-Za-z0-9_.-]+$`,
+											},
+											"description": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+											"default": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+							"template": partialEnvironmentValidation,
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Chart",
+				Type:        "string",
+				Description: "The chart of the applications following this template, unless they set their own.",
+				JSONPath:    ".spec.template.chart.name",
+				Priority:    0,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Parameters",
+				Type:        "string",
+				Description: "The names of the parameters applications following this template fill in.",
+				JSONPath:    ".spec.parameters[*].name",
+				Priority:    1,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Age",
+				Type:        "date",
+				Description: "The template's age.",
+				JSONPath:    ".metadata.creationTimestamp",
+			},
+		},
+	},
+}
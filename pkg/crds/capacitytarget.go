@@ -47,6 +47,36 @@ var CapacityTarget = &apiextensionv1beta1.CustomResourceDefinition{
 								Type:    "integer",
 								Minimum: &zero,
 							},
+							"application": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"release": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"workloads": apiextensionv1beta1.JSONSchemaProps{
+								Type:     "array",
+								Nullable: true,
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										Required: []string{
+											"name",
+											"totalReplicaCount",
+										},
+										Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+											"name": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+											"totalReplicaCount": apiextensionv1beta1.JSONSchemaProps{
+												Type:    "integer",
+												Minimum: &zero,
+											},
+										},
+									},
+								},
+							},
+							"scaleDownPolicy": scaleDownPolicyValidation,
+							"workloadKind":    workloadKindValidation,
 							"clusters": apiextensionv1beta1.JSONSchemaProps{
 								Type:     "array",
 								Nullable: true,
@@ -66,6 +96,10 @@ var CapacityTarget = &apiextensionv1beta1.CustomResourceDefinition{
 												Minimum: &zero,
 												Maximum: &hundred,
 											},
+											"totalReplicaCount": apiextensionv1beta1.JSONSchemaProps{
+												Type:    "integer",
+												Minimum: &zero,
+											},
 										},
 									},
 								},
@@ -94,6 +128,12 @@ var CapacityTarget = &apiextensionv1beta1.CustomResourceDefinition{
 				Description: "Reason for the capacity target to not be ready or operational.",
 				JSONPath:    `.status.conditions[?(.status=="False")].message`,
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Last Sync",
+				Type:        "date",
+				Description: "When the capacity target was last synced by its controller.",
+				JSONPath:    ".status.lastSyncTime",
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Age",
 				Type:        "date",
@@ -0,0 +1,77 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ChartRepository = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "chartrepositories.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "chartrepositories",
+			Singular:   "chartrepository",
+			Kind:       "ChartRepository",
+			ShortNames: []string{"chartrepo"},
+			Categories: []string{"shipper"},
+		},
+		Scope: apiextensionv1beta1.ClusterScoped,
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Required: []string{
+							"url",
+						},
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"url": apiextensionv1beta1.JSONSchemaProps{
+								Type:    "string",
+								Pattern: `^https?://`,
+							},
+							"caBundle": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"secretName": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"indexRefreshInterval": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "URL",
+				Type:        "string",
+				Description: "The URL of the chart repository.",
+				JSONPath:    ".spec.url",
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Secret",
+				Type:        "string",
+				Description: "The Secret with the credentials of the chart repository.",
+				JSONPath:    ".spec.secretName",
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Age",
+				Type:        "date",
+				Description: "The chart repository's age.",
+				JSONPath:    ".metadata.creationTimestamp",
+			},
+		},
+	},
+}
@@ -50,6 +50,20 @@ var Cluster = &apiextensionv1beta1.CustomResourceDefinition{
 									},
 								},
 							},
+							"trafficDisabled": apiextensionv1beta1.JSONSchemaProps{
+								Type: "boolean",
+							},
+							"pullMode": apiextensionv1beta1.JSONSchemaProps{
+								Type: "boolean",
+							},
+							"operationTimeout": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"maxTotalCapacityPercent": apiextensionv1beta1.JSONSchemaProps{
+								Type:             "integer",
+								Minimum:          &hundred,
+								ExclusiveMinimum: true,
+							},
 							"scheduler": apiextensionv1beta1.JSONSchemaProps{
 								Type: "object",
 								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
@@ -62,6 +76,13 @@ var Cluster = &apiextensionv1beta1.CustomResourceDefinition{
 									"identity": apiextensionv1beta1.JSONSchemaProps{
 										Type: "string",
 									},
+									"costTier": apiextensionv1beta1.JSONSchemaProps{
+										Type: "integer",
+									},
+									"pricePerReplicaHour": apiextensionv1beta1.JSONSchemaProps{},
+									"rolloutPriority": apiextensionv1beta1.JSONSchemaProps{
+										Type: "integer",
+									},
 								},
 							},
 						},
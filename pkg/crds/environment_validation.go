@@ -13,25 +13,7 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 		"values",
 	},
 	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
-		"chart": apiextensionv1beta1.JSONSchemaProps{
-			Type: "object",
-			Required: []string{
-				"name",
-				"version",
-				"repoUrl",
-			},
-			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
-				"name": apiextensionv1beta1.JSONSchemaProps{
-					Type: "string",
-				},
-				"version": apiextensionv1beta1.JSONSchemaProps{
-					Type: "string",
-				},
-				"repoUrl": apiextensionv1beta1.JSONSchemaProps{
-					Type: "string",
-				},
-			},
-		},
+		"chart": chartValidation,
 		"clusterRequirements": apiextensionv1beta1.JSONSchemaProps{
 			Type: "object",
 			Required: []string{
@@ -54,14 +36,210 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 						},
 					},
 				},
+				"scheduler": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+					Enum: []apiextensionv1beta1.JSON{
+						apiextensionv1beta1.JSON{Raw: []byte(`"CostAware"`)},
+					},
+				},
+				"applicationAffinity": apiextensionv1beta1.JSONSchemaProps{
+					Type: "array",
+					Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+						Schema: &apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"applicationAntiAffinity": apiextensionv1beta1.JSONSchemaProps{
+					Type: "array",
+					Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+						Schema: &apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"platforms": apiextensionv1beta1.JSONSchemaProps{
+					Type: "array",
+					Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+						Schema: &apiextensionv1beta1.JSONSchemaProps{
+							Type:    "string",
+							Pattern: `^[a-z0-9]+/[a-z0-9]+$`,
+						},
+					},
+				},
+				"rolloutOrder": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"mode",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"mode": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+							Enum: []apiextensionv1beta1.JSON{
+								apiextensionv1beta1.JSON{Raw: []byte(`"Parallel"`)},
+								apiextensionv1beta1.JSON{Raw: []byte(`"Sequential"`)},
+							},
+						},
+						"clusters": apiextensionv1beta1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionv1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+					},
+				},
+				"spread": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"strategy",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"strategy": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+							Enum: []apiextensionv1beta1.JSON{
+								apiextensionv1beta1.JSON{Raw: []byte(`"Even"`)},
+								apiextensionv1beta1.JSON{Raw: []byte(`"Weighted"`)},
+								apiextensionv1beta1.JSON{Raw: []byte(`"BinPack"`)},
+								apiextensionv1beta1.JSON{Raw: []byte(`"Pinned"`)},
+							},
+						},
+						"maxReplicasPerCluster": apiextensionv1beta1.JSONSchemaProps{
+							Type:    "integer",
+							Minimum: &one,
+						},
+						"pinned": apiextensionv1beta1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionv1beta1.JSONSchemaProps{
+									Type: "object",
+									Required: []string{
+										"name",
+									},
+									Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+										"name": apiextensionv1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+										"replicas": apiextensionv1beta1.JSONSchemaProps{
+											Type:    "integer",
+											Minimum: &zero,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 		"strategy": apiextensionv1beta1.JSONSchemaProps{
 			Type: "object",
-			Required: []string{
-				"steps",
-			},
 			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"preset": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+					Enum: []apiextensionv1beta1.JSON{
+						apiextensionv1beta1.JSON{Raw: []byte(`"vanguard"`)},
+						apiextensionv1beta1.JSON{Raw: []byte(`"vanguard-4-step"`)},
+						apiextensionv1beta1.JSON{Raw: []byte(`"big-bang"`)},
+						apiextensionv1beta1.JSON{Raw: []byte(`"traffic-first"`)},
+					},
+				},
+				"partialFinalStep": apiextensionv1beta1.JSONSchemaProps{
+					Type: "boolean",
+				},
+				"experiment": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"bucketing",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"bucketing": experimentBucketingValidation,
+					},
+				},
+				"strategyRef": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"name",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"name": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"namespace": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"generation": apiextensionv1beta1.JSONSchemaProps{
+							Type: "integer",
+						},
+					},
+				},
+				"maxUnavailableClusters": apiextensionv1beta1.JSONSchemaProps{
+					XIntOrString: true,
+					AnyOf: []apiextensionv1beta1.JSONSchemaProps{
+						{Type: "integer"},
+						{Type: "string"},
+					},
+				},
+				"incumbentFloor": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"capacity",
+						"soakDuration",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"capacity": apiextensionv1beta1.JSONSchemaProps{
+							XIntOrString: true,
+							AnyOf: []apiextensionv1beta1.JSONSchemaProps{
+								{Type: "integer"},
+								{Type: "string"},
+							},
+						},
+						"soakDuration": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"incumbentStandby": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"capacity",
+						"retention",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"capacity": apiextensionv1beta1.JSONSchemaProps{
+							XIntOrString: true,
+							AnyOf: []apiextensionv1beta1.JSONSchemaProps{
+								{Type: "integer"},
+								{Type: "string"},
+							},
+						},
+						"retention": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"cutover": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"ingresses": apiextensionv1beta1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionv1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+						"hostnames": apiextensionv1beta1.JSONSchemaProps{
+							Type: "array",
+							Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionv1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+							},
+						},
+					},
+				},
 				"steps": apiextensionv1beta1.JSONSchemaProps{
 					Type: "array",
 					Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
@@ -112,6 +290,45 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 										},
 									},
 								},
+								"approvalRequired": apiextensionv1beta1.JSONSchemaProps{
+									Type: "boolean",
+								},
+								"approvers": apiextensionv1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+										Schema: &apiextensionv1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
+								"order": apiextensionv1beta1.JSONSchemaProps{
+									Type: "string",
+									Enum: []apiextensionv1beta1.JSON{
+										apiextensionv1beta1.JSON{Raw: []byte(`"capacityFirst"`)},
+										apiextensionv1beta1.JSON{Raw: []byte(`"trafficFirst"`)},
+										apiextensionv1beta1.JSON{Raw: []byte(`"parallel"`)},
+									},
+								},
+								"preHooks":  stepHooksValidation,
+								"postHooks": stepHooksValidation,
+								"probes": apiextensionv1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+										Schema: &stepProbeValidation,
+									},
+								},
+								"shadowTraffic": apiextensionv1beta1.JSONSchemaProps{
+									Type:    "integer",
+									Minimum: &zero,
+									Maximum: &hundred,
+								},
+								"maxUnavailable": apiextensionv1beta1.JSONSchemaProps{
+									XIntOrString: true,
+									AnyOf: []apiextensionv1beta1.JSONSchemaProps{
+										{Type: "integer"},
+										{Type: "string"},
+									},
+								},
 							},
 						},
 					},
@@ -121,5 +338,311 @@ var environmentValidation = apiextensionv1beta1.JSONSchemaProps{
 		"values": apiextensionv1beta1.JSONSchemaProps{
 			Type: "object",
 		},
+		"imageOverride": imageOverrideValidation,
+		"prePullImages": apiextensionv1beta1.JSONSchemaProps{
+			Type: "boolean",
+		},
+		"topologySpread": topologySpreadValidation,
+		"readinessBarriers": apiextensionv1beta1.JSONSchemaProps{
+			Type: "array",
+			Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+			},
+		},
+		"valuesOverlays": apiextensionv1beta1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"environment": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"environments": valuesOverlayListValidation,
+				"clusters":     valuesOverlayListValidation,
+			},
+		},
+		"valuesFrom":       valuesFromValidation,
+		"additionalCharts": additionalChartsValidation,
+		"dependsOn": apiextensionv1beta1.JSONSchemaProps{
+			Type: "array",
+			Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+			},
+		},
+		"scaleDownPolicy": scaleDownPolicyValidation,
+		"workloadKind":    workloadKindValidation,
 	},
 }
+
+var chartValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Required: []string{
+		"name",
+		"version",
+		"repoUrl",
+	},
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"name": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"version": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"repoUrl": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"digest": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+	},
+}
+
+var additionalChartsValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "array",
+	Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+		Schema: &apiextensionv1beta1.JSONSchemaProps{
+			Type: "object",
+			Required: []string{
+				"name",
+				"chart",
+			},
+			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"name": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"chart": chartValidation,
+				"values": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+				},
+			},
+		},
+	},
+}
+
+var valuesOverlayListValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "array",
+	Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+		Schema: &apiextensionv1beta1.JSONSchemaProps{
+			Type: "object",
+			Required: []string{
+				"name",
+				"values",
+			},
+			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"name": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"values": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+				},
+			},
+		},
+	},
+}
+
+var stepHooksValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "array",
+	Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+		Schema: &apiextensionv1beta1.JSONSchemaProps{
+			Type:     "object",
+			Required: []string{"name"},
+			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"name": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"template": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"job": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+				},
+			},
+		},
+	},
+}
+
+var stepProbeValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type:     "object",
+	Required: []string{"name", "path", "port"},
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"name": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"path": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"port": apiextensionv1beta1.JSONSchemaProps{
+			Type: "integer",
+		},
+		"scheme": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+			Enum: []apiextensionv1beta1.JSON{
+				apiextensionv1beta1.JSON{Raw: []byte(`"http"`)},
+				apiextensionv1beta1.JSON{Raw: []byte(`"https"`)},
+			},
+		},
+		"expectedStatus": apiextensionv1beta1.JSONSchemaProps{
+			Type: "integer",
+		},
+		"latencySLO": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+	},
+}
+
+var imageOverrideValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Required: []string{
+		"image",
+	},
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"container": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"image": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+	},
+}
+
+var topologySpreadValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"topologyKey": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+	},
+}
+
+var valuesFromValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "array",
+	Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+		Schema: &apiextensionv1beta1.JSONSchemaProps{
+			Type: "object",
+			Required: []string{
+				"path",
+			},
+			Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+				"path": apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+				"secretKeyRef": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"name",
+						"key",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"name": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"key": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+				"external": apiextensionv1beta1.JSONSchemaProps{
+					Type: "object",
+					Required: []string{
+						"store",
+						"key",
+					},
+					Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+						"store": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"key": apiextensionv1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+var scaleDownPolicyValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "string",
+	Enum: []apiextensionv1beta1.JSON{
+		apiextensionv1beta1.JSON{Raw: []byte(`"ReplicaCount"`)},
+		apiextensionv1beta1.JSON{Raw: []byte(`"UnhealthyFirst"`)},
+	},
+}
+
+var experimentBucketingValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"header": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"cookie": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+	},
+}
+
+var workloadKindValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "string",
+	Enum: []apiextensionv1beta1.JSON{
+		apiextensionv1beta1.JSON{Raw: []byte(`"Service"`)},
+		apiextensionv1beta1.JSON{Raw: []byte(`"Job"`)},
+	},
+}
+
+var adoptionValidation = apiextensionv1beta1.JSONSchemaProps{
+	Type: "object",
+	Required: []string{
+		"deployment",
+	},
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"deployment": apiextensionv1beta1.JSONSchemaProps{
+			Type: "string",
+		},
+		"services": apiextensionv1beta1.JSONSchemaProps{
+			Type: "array",
+			Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+				Schema: &apiextensionv1beta1.JSONSchemaProps{
+					Type: "string",
+				},
+			},
+		},
+	},
+}
+
+// partialEnvironmentValidation is environmentValidation without the fields
+// it requires, for environments that are completed by layering others over
+// them, like the ones of ApplicationTemplates and of the applications
+// following them.
+var partialEnvironmentValidation = withoutRequired(environmentValidation)
+
+// completeEnvironmentValidation requires the fields environmentValidation
+// does, as an addition to partialEnvironmentValidation, except for those an
+// ApplicationDefault can fill in.
+var completeEnvironmentValidation = apiextensionv1beta1.JSONSchemaProps{
+	Required: []string{
+		"chart",
+		"values",
+	},
+	Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+		"chart": apiextensionv1beta1.JSONSchemaProps{
+			Required: chartValidation.Required,
+		},
+	},
+}
+
+func withoutRequired(env apiextensionv1beta1.JSONSchemaProps) apiextensionv1beta1.JSONSchemaProps {
+	properties := make(map[string]apiextensionv1beta1.JSONSchemaProps, len(env.Properties))
+	for k, v := range env.Properties {
+		properties[k] = v
+	}
+
+	chart := properties["chart"]
+	chart.Required = nil
+	properties["chart"] = chart
+
+	env.Required = nil
+	env.Properties = properties
+	return env
+}
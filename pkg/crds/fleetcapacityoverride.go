@@ -0,0 +1,106 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var FleetCapacityOverride = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "fleetcapacityoverrides.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "fleetcapacityoverrides",
+			Singular:   "fleetcapacityoverride",
+			Kind:       "FleetCapacityOverride",
+			ShortNames: []string{"fco"},
+			Categories: []string{"all", "shipper"},
+		},
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Required: []string{
+							"percent",
+							"message",
+							"author",
+						},
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"percent": apiextensionv1beta1.JSONSchemaProps{
+								Type:    "integer",
+								Minimum: &zero,
+								Maximum: &hundred,
+							},
+							"applications": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"message": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"author": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Required: []string{
+									"type",
+									"name",
+								},
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"type": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+									"name": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Percent",
+				Type:        "integer",
+				Description: "The capacity overridden releases are scaled to.",
+				JSONPath:    ".spec.percent",
+				Priority:    0,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Message",
+				Type:        "string",
+				Description: "The reason for this capacity override.",
+				JSONPath:    ".spec.message",
+				Priority:    0,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Applications",
+				Type:        "string",
+				Description: "The applications this override applies to. All of them in the namespace when empty.",
+				JSONPath:    ".spec.applications",
+				Priority:    1,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Author Name",
+				Type:        "string",
+				Description: "The author name of this capacity override.",
+				JSONPath:    ".spec.author.name",
+				Priority:    1,
+			},
+		},
+	},
+}
@@ -0,0 +1,65 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// ManagementClusterCRDs are the CRDs Shipper uses in the management cluster.
+var ManagementClusterCRDs = []*apiextensionv1beta1.CustomResourceDefinition{
+	ShipperConfig,
+	ChartRepository,
+	Cluster,
+	RolloutBlock,
+	FleetCapacityOverride,
+	RolloutQuota,
+	Policy,
+	ApplicationTemplate,
+	ApplicationDefault,
+	Strategy,
+	Application,
+	Release,
+}
+
+// ApplicationClusterCRDs are the CRDs Shipper uses in application clusters.
+// ChartRepository is in both, as charts are fetched in both.
+var ApplicationClusterCRDs = []*apiextensionv1beta1.CustomResourceDefinition{
+	ChartRepository,
+	InstallationTarget,
+	CapacityTarget,
+	TrafficTarget,
+}
+
+// Install creates the CRDs that don't exist yet, and updates the spec of the
+// ones that do, so their schemas are always the ones this version of Shipper
+// was built with.
+func Install(client apiextensionclientset.Interface, crds []*apiextensionv1beta1.CustomResourceDefinition) error {
+	for _, crd := range crds {
+		if err := install(client, crd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func install(client apiextensionclientset.Interface, crd *apiextensionv1beta1.CustomResourceDefinition) error {
+	crdClient := client.ApiextensionsV1beta1().CustomResourceDefinitions()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existingCrd, err := crdClient.Get(crd.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			_, err = crdClient.Create(crd)
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		existingCrd.Spec = crd.Spec
+		_, err = crdClient.Update(existingCrd)
+		return err
+	})
+}
@@ -0,0 +1,50 @@
+package crds
+
+import (
+	"testing"
+
+	apiextensionfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInstallCreatesAndUpdatesCRDs(t *testing.T) {
+	outdated := Release.DeepCopy()
+	outdated.Spec.Validation = nil
+
+	client := apiextensionfake.NewSimpleClientset(outdated)
+
+	if err := Install(client, ManagementClusterCRDs); err != nil {
+		t.Fatalf("unexpected error installing CRDs: %s", err)
+	}
+
+	crdClient := client.ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, crd := range ManagementClusterCRDs {
+		installed, err := crdClient.Get(crd.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("expected CRD %q to be installed: %s", crd.Name, err)
+			continue
+		}
+
+		if !equality.Semantic.DeepEqual(installed.Spec, crd.Spec) {
+			t.Errorf("expected CRD %q to have the spec Shipper was built with", crd.Name)
+		}
+	}
+
+	managementCRDs := make(map[string]bool)
+	for _, crd := range ManagementClusterCRDs {
+		managementCRDs[crd.Name] = true
+	}
+
+	// Some CRDs, like ChartRepository, are used in both kinds of
+	// cluster.
+	for _, crd := range ApplicationClusterCRDs {
+		if managementCRDs[crd.Name] {
+			continue
+		}
+
+		if _, err := crdClient.Get(crd.Name, metav1.GetOptions{}); err == nil {
+			t.Errorf("expected CRD %q not to be installed in the management cluster", crd.Name)
+		}
+	}
+}
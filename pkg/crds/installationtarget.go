@@ -38,12 +38,24 @@ var InstallationTarget = &apiextensionv1beta1.CustomResourceDefinition{
 				Description: "Whether the installation target is ready.",
 				JSONPath:    `.status.conditions[?(.type=="Ready")].status`,
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Healthy",
+				Type:        "string",
+				Description: "Whether the objects of the installation target are ready.",
+				JSONPath:    `.status.conditions[?(.type=="Healthy")].status`,
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Reason",
 				Type:        "string",
 				Description: "Reason for the installation target to not be ready or operational.",
 				JSONPath:    `.status.conditions[?(.status=="False")].message`,
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Last Sync",
+				Type:        "date",
+				Description: "When the installation target was last synced by its controller.",
+				JSONPath:    ".status.lastSyncTime",
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Age",
 				Type:        "date",
@@ -71,11 +83,29 @@ var InstallationTarget = &apiextensionv1beta1.CustomResourceDefinition{
 									"name":    apiextensionv1beta1.JSONSchemaProps{Type: "string"},
 									"version": apiextensionv1beta1.JSONSchemaProps{Type: "string"},
 									"repoUrl": apiextensionv1beta1.JSONSchemaProps{Type: "string"},
+									"digest":  apiextensionv1beta1.JSONSchemaProps{Type: "string"},
 								},
 							},
 							"values": apiextensionv1beta1.JSONSchemaProps{
 								Type: "object",
 							},
+							"imageOverride": imageOverrideValidation,
+							"prePullImages": apiextensionv1beta1.JSONSchemaProps{
+								Type: "boolean",
+							},
+							"topologySpread": topologySpreadValidation,
+							"readinessBarriers": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"valuesFrom":       valuesFromValidation,
+							"additionalCharts": additionalChartsValidation,
+							"workloadKind":     workloadKindValidation,
+							"adopt":            adoptionValidation,
 							"clusters": apiextensionv1beta1.JSONSchemaProps{
 								Type:     "array",
 								Nullable: true,
@@ -3,5 +3,6 @@ package crds
 // we need to take pointers to them in the validation definitions
 var (
 	zero    = 0.0
+	one     = 1.0
 	hundred = 100.0
 )
@@ -0,0 +1,98 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var Policy = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "policies.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "policies",
+			Singular:   "policy",
+			Kind:       "Policy",
+			ShortNames: []string{"pol"},
+			Categories: []string{"all", "shipper"},
+		},
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Required: []string{
+							"opa",
+						},
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"stages": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+										Enum: []apiextensionv1beta1.JSON{
+											apiextensionv1beta1.JSON{Raw: []byte(`"Installation"`)},
+											apiextensionv1beta1.JSON{Raw: []byte(`"StepAdvancement"`)},
+										},
+									},
+								},
+							},
+							"applications": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"opa": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Required: []string{
+									"path",
+								},
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"path": apiextensionv1beta1.JSONSchemaProps{
+										Type:    "string",
+										Pattern: `^[A-Za-z0-9_]+(/[A-Za-z0-9_]+)*$`,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "OPA Path",
+				Type:        "string",
+				Description: "The path of the rule evaluated in Open Policy Agent.",
+				JSONPath:    ".spec.opa.path",
+				Priority:    0,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Stages",
+				Type:        "string",
+				Description: "The points of a rollout this policy is evaluated at. All of them when empty.",
+				JSONPath:    ".spec.stages",
+				Priority:    1,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Applications",
+				Type:        "string",
+				Description: "The applications this policy applies to. All of them in the namespace when empty.",
+				JSONPath:    ".spec.applications",
+				Priority:    1,
+			},
+		},
+	},
+}
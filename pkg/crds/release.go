@@ -40,6 +40,74 @@ var Release = &apiextensionv1beta1.CustomResourceDefinition{
 								Minimum: &zero,
 							},
 							"environment": environmentValidation,
+							"adopt":       adoptionValidation,
+							"clusterReplicas": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										Required: []string{
+											"name",
+											"replicas",
+										},
+										Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+											"name": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+											"replicas": apiextensionv1beta1.JSONSchemaProps{
+												Type:    "integer",
+												Minimum: &zero,
+											},
+										},
+									},
+								},
+							},
+							"clusterValues": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										Required: []string{
+											"name",
+											"values",
+										},
+										Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+											"name": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+											"values": apiextensionv1beta1.JSONSchemaProps{
+												Type: "object",
+											},
+										},
+									},
+								},
+							},
+							"approvals": apiextensionv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextensionv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextensionv1beta1.JSONSchemaProps{
+										Type: "object",
+										Required: []string{
+											"step",
+											"approver",
+											"approvedAt",
+										},
+										Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+											"step": apiextensionv1beta1.JSONSchemaProps{
+												Type:    "integer",
+												Minimum: &zero,
+											},
+											"approver": apiextensionv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+											"approvedAt": apiextensionv1beta1.JSONSchemaProps{
+												Type:   "string",
+												Format: "date-time",
+											},
+										},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -58,6 +126,12 @@ var Release = &apiextensionv1beta1.CustomResourceDefinition{
 				Description: "The current achieved step for a release as defined in the rollout strategy.",
 				JSONPath:    ".status.achievedStep.name",
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "ETA",
+				Type:        "string",
+				Description: "When the release is estimated to finish rolling out.",
+				JSONPath:    ".status.eta.rolloutCompletion",
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Clusters",
 				Type:        "string",
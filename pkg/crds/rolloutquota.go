@@ -0,0 +1,64 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var RolloutQuota = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "rolloutquotas.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "rolloutquotas",
+			Singular:   "rolloutquota",
+			Kind:       "RolloutQuota",
+			ShortNames: []string{"rq"},
+			Categories: []string{"all", "shipper"},
+		},
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"maxConcurrentRollouts": apiextensionv1beta1.JSONSchemaProps{
+								Type:    "integer",
+								Minimum: &zero,
+							},
+							"maxReplicas": apiextensionv1beta1.JSONSchemaProps{
+								Type:    "integer",
+								Minimum: &zero,
+							},
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Max Rollouts",
+				Type:        "integer",
+				Description: "How many applications can roll out at once.",
+				JSONPath:    ".spec.maxConcurrentRollouts",
+				Priority:    0,
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Max Replicas",
+				Type:        "integer",
+				Description: "How many replicas rolling out releases can ask for together.",
+				JSONPath:    ".spec.maxReplicas",
+				Priority:    0,
+			},
+		},
+	},
+}
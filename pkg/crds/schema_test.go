@@ -0,0 +1,108 @@
+package crds
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// TestSchemasCoverTypes makes sure the schema of every CRD has every field of
+// the spec of its type, so none of them goes unvalidated.
+func TestSchemasCoverTypes(t *testing.T) {
+	tests := []struct {
+		crd  *apiextensionv1beta1.CustomResourceDefinition
+		spec interface{}
+	}{
+		{Application, shipper.ApplicationSpec{}},
+		{Release, shipper.ReleaseSpec{}},
+		{Cluster, shipper.ClusterSpec{}},
+		{RolloutBlock, shipper.RolloutBlockSpec{}},
+		{FleetCapacityOverride, shipper.FleetCapacityOverrideSpec{}},
+		{RolloutQuota, shipper.RolloutQuotaSpec{}},
+		{Policy, shipper.PolicySpec{}},
+		{ApplicationTemplate, shipper.ApplicationTemplateSpec{}},
+		{ApplicationDefault, shipper.ApplicationDefaultSpec{}},
+		{Strategy, shipper.RolloutStrategy{}},
+		{ShipperConfig, shipper.ShipperConfigSpec{}},
+		{ChartRepository, shipper.ChartRepositorySpec{}},
+		{InstallationTarget, shipper.InstallationTargetSpec{}},
+		{CapacityTarget, shipper.CapacityTargetSpec{}},
+		{TrafficTarget, shipper.TrafficTargetSpec{}},
+	}
+
+	for _, tt := range tests {
+		schema := tt.crd.Spec.Validation.OpenAPIV3Schema.Properties["spec"]
+		for _, missing := range missingFields(reflect.TypeOf(tt.spec), schema, ".spec") {
+			t.Errorf("CRD %s has no schema for %s", tt.crd.Name, missing)
+		}
+	}
+}
+
+func missingFields(t reflect.Type, schema apiextensionv1beta1.JSONSchemaProps, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types with their own encoding, like durations and quantities,
+	// aren't objects in the schema.
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return nil
+		}
+		return missingFields(t.Elem(), *schema.Items.Schema, path+"[]")
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	// Objects without properties take anything.
+	if len(schema.Properties) == 0 {
+		return nil
+	}
+
+	var missing []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && field.Anonymous {
+			missing = append(missing, missingFields(field.Type, schema, path)...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldPath := path + "." + name
+		fieldSchema, ok := schema.Properties[name]
+		if !ok {
+			missing = append(missing, fieldPath)
+			continue
+		}
+
+		missing = append(missing, missingFields(field.Type, fieldSchema, fieldPath)...)
+	}
+
+	return missing
+}
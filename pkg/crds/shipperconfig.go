@@ -0,0 +1,92 @@
+package crds
+
+import (
+	apiextensionv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var ShipperConfig = &apiextensionv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{
+		Name: "shipperconfigs.shipper.booking.com",
+	},
+	Spec: apiextensionv1beta1.CustomResourceDefinitionSpec{
+		Group: "shipper.booking.com",
+		Versions: []apiextensionv1beta1.CustomResourceDefinitionVersion{
+			apiextensionv1beta1.CustomResourceDefinitionVersion{
+				Name:    "v1alpha1",
+				Served:  true,
+				Storage: true,
+			},
+		},
+		Names: apiextensionv1beta1.CustomResourceDefinitionNames{
+			Plural:     "shipperconfigs",
+			Singular:   "shipperconfig",
+			Kind:       "ShipperConfig",
+			ShortNames: []string{"shipcfg"},
+			Categories: []string{"shipper"},
+		},
+		Scope: apiextensionv1beta1.ClusterScoped,
+		Validation: &apiextensionv1beta1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionv1beta1.JSONSchemaProps{
+				Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+					"spec": apiextensionv1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+							"events": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"verbosity": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+										Enum: []apiextensionv1beta1.JSON{
+											apiextensionv1beta1.JSON{Raw: []byte(`"all"`)},
+											apiextensionv1beta1.JSON{Raw: []byte(`"warnings"`)},
+											apiextensionv1beta1.JSON{Raw: []byte(`"none"`)},
+										},
+									},
+									"dedupInterval": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"defaultStrategy": environmentValidation.Properties["strategy"],
+							"gitopsInterval": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"chartRepositories": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"maxConcurrentRequests": apiextensionv1beta1.JSONSchemaProps{
+										Type:    "integer",
+										Minimum: &zero,
+									},
+								},
+							},
+							"notifications": apiextensionv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextensionv1beta1.JSONSchemaProps{
+									"completionWebhookURL": apiextensionv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		AdditionalPrinterColumns: []apiextensionv1beta1.CustomResourceColumnDefinition{
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Events",
+				Type:        "string",
+				Description: "Which events controllers emit.",
+				JSONPath:    ".spec.events.verbosity",
+			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Age",
+				Type:        "date",
+				Description: "The config's age.",
+				JSONPath:    ".metadata.creationTimestamp",
+			},
+		},
+	},
+}
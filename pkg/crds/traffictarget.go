@@ -47,6 +47,12 @@ var TrafficTarget = &apiextensionv1beta1.CustomResourceDefinition{
 				Description: "Reason for the traffic target to not be ready or operational.",
 				JSONPath:    `.status.conditions[?(.status=="False")].message`,
 			},
+			apiextensionv1beta1.CustomResourceColumnDefinition{
+				Name:        "Last Sync",
+				Type:        "date",
+				Description: "When the traffic target was last synced by its controller.",
+				JSONPath:    ".status.lastSyncTime",
+			},
 			apiextensionv1beta1.CustomResourceColumnDefinition{
 				Name:        "Age",
 				Type:        "date",
@@ -67,6 +73,22 @@ var TrafficTarget = &apiextensionv1beta1.CustomResourceDefinition{
 								Type:    "integer",
 								Minimum: &zero,
 							},
+							"backend": apiextensionv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+							"shadowWeight": apiextensionv1beta1.JSONSchemaProps{
+								Type:    "integer",
+								Minimum: &zero,
+								Maximum: &hundred,
+							},
+							"trafficDisabled": apiextensionv1beta1.JSONSchemaProps{
+								Type: "boolean",
+							},
+							"bucketing": apiextensionv1beta1.JSONSchemaProps{
+								Type:       "object",
+								Nullable:   true,
+								Properties: experimentBucketingValidation.Properties,
+							},
 							"clusters": apiextensionv1beta1.JSONSchemaProps{
 								Type:     "array",
 								Nullable: true,
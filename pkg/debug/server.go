@@ -0,0 +1,48 @@
+// Package debug serves endpoints to look into a running Shipper: the Go
+// profiler, what's sitting in each controller's workqueue and which objects
+// controllers are failing to sync.
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+
+	"k8s.io/klog"
+
+	shipperworkqueue "github.com/bookingcom/shipper/pkg/workqueue"
+)
+
+// NewHandler returns a handler for all debug endpoints:
+//
+//  /debug/pprof/  the Go profiler
+//  /debug/queues  the items in every controller's workqueue
+//  /debug/errors  the last sync error of every object failing to sync
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/debug/queues", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, shipperworkqueue.Snapshot())
+	})
+	mux.HandleFunc("/debug/errors", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, SyncErrors())
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		klog.Warningf("could not write debug response: %s", err)
+	}
+}
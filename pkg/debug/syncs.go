@@ -0,0 +1,75 @@
+package debug
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// SyncError is the last error a controller ran into while syncing an
+// object.
+type SyncError struct {
+	Kind   string    `json:"kind"`
+	Key    string    `json:"key"`
+	Error  string    `json:"error"`
+	Reason string    `json:"reason"`
+	Retry  bool      `json:"retry"`
+	Time   time.Time `json:"time"`
+}
+
+var (
+	syncErrorsMut sync.Mutex
+	syncErrors    = map[string]map[string]SyncError{}
+)
+
+// ObserveSync records the outcome of a controller syncing the object of kind
+// behind key. Errors are kept until the object syncs successfully.
+func ObserveSync(controller, kind, key string, err error) {
+	syncErrorsMut.Lock()
+	defer syncErrorsMut.Unlock()
+
+	id := kind + "/" + key
+	if err == nil {
+		delete(syncErrors[controller], id)
+		return
+	}
+
+	if syncErrors[controller] == nil {
+		syncErrors[controller] = map[string]SyncError{}
+	}
+
+	syncErrors[controller][id] = SyncError{
+		Kind:   kind,
+		Key:    key,
+		Error:  err.Error(),
+		Reason: shippererrors.Reason(err),
+		Retry:  shippererrors.ShouldRetry(err),
+		Time:   time.Now(),
+	}
+}
+
+// SyncErrors lists the objects each controller is failing to sync, with the
+// last error it ran into for each of them.
+func SyncErrors() map[string][]SyncError {
+	syncErrorsMut.Lock()
+	defer syncErrorsMut.Unlock()
+
+	all := make(map[string][]SyncError, len(syncErrors))
+	for controller, errs := range syncErrors {
+		list := make([]SyncError, 0, len(errs))
+		for _, err := range errs {
+			list = append(list, err)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Kind != list[j].Kind {
+				return list[i].Kind < list[j].Kind
+			}
+			return list[i].Key < list[j].Key
+		})
+		all[controller] = list
+	}
+
+	return all
+}
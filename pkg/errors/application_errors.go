@@ -12,6 +12,10 @@ func (e *ApplicationAnnotationError) Error() string {
 	return fmt.Sprintf(`error decoding annotation %q in application %q: %s`, e.annotationName, e.appName, e.err)
 }
 
+func (e *ApplicationAnnotationError) Reason() string {
+	return "ApplicationAnnotation"
+}
+
 func NewApplicationAnnotationError(appName, annotationName string, err error) error {
 	return &ApplicationAnnotationError{appName: appName, annotationName: annotationName, err: err}
 }
@@ -20,3 +24,73 @@ func IsApplicationAnnotationNotFoundError(err error) bool {
 	_, ok := err.(*ApplicationAnnotationError)
 	return ok
 }
+
+type InvalidRollbackTargetError struct {
+	appName     string
+	releaseName string
+	reason      string
+}
+
+func (e *InvalidRollbackTargetError) Error() string {
+	return fmt.Sprintf("can not roll application %q back to release %q: %s", e.appName, e.releaseName, e.reason)
+}
+
+func (e *InvalidRollbackTargetError) ShouldRetry() bool {
+	return false
+}
+
+func (e *InvalidRollbackTargetError) Reason() string {
+	return "InvalidRollbackTarget"
+}
+
+func NewInvalidRollbackTargetError(appName, releaseName, reason string) error {
+	return &InvalidRollbackTargetError{appName: appName, releaseName: releaseName, reason: reason}
+}
+
+type ApplicationTemplateError struct {
+	appName      string
+	templateName string
+	err          error
+}
+
+func (e *ApplicationTemplateError) Error() string {
+	return fmt.Sprintf("could not render template %q for application %q: %s", e.templateName, e.appName, e.err)
+}
+
+// ShouldRetry is false, as applications are synced again as soon as the
+// templates they follow change.
+func (e *ApplicationTemplateError) ShouldRetry() bool {
+	return false
+}
+
+func (e *ApplicationTemplateError) Reason() string {
+	return "TemplateRenderFailed"
+}
+
+func NewApplicationTemplateError(appName, templateName string, err error) error {
+	return &ApplicationTemplateError{appName: appName, templateName: templateName, err: err}
+}
+
+type StrategyRefError struct {
+	appName      string
+	strategyName string
+	err          error
+}
+
+func (e *StrategyRefError) Error() string {
+	return fmt.Sprintf("could not resolve strategy %q for application %q: %s", e.strategyName, e.appName, e.err)
+}
+
+// ShouldRetry is false, as applications are synced again as soon as the
+// strategies they refer to change.
+func (e *StrategyRefError) ShouldRetry() bool {
+	return false
+}
+
+func (e *StrategyRefError) Reason() string {
+	return "StrategyResolutionFailed"
+}
+
+func NewStrategyRefError(appName, strategyName string, err error) error {
+	return &StrategyRefError{appName: appName, strategyName: strategyName, err: err}
+}
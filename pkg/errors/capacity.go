@@ -14,7 +14,68 @@ func (e CapacityInProgressError) ShouldRetry() bool {
 	return true
 }
 
+func (e CapacityInProgressError) Reason() string {
+	return "CapacityInProgress"
+}
+
 func NewCapacityInProgressError(ctName string) CapacityInProgressError {
 	return CapacityInProgressError(fmt.Sprintf("capacity target %s in progress",
 		ctName))
 }
+
+type InvalidCapacityTargetSpecError struct {
+	msg string
+}
+
+func (e InvalidCapacityTargetSpecError) Error() string {
+	return fmt.Sprintf("invalid CapacityTarget spec: %s", e.msg)
+}
+
+func (e InvalidCapacityTargetSpecError) ShouldRetry() bool {
+	return false
+}
+
+func (e InvalidCapacityTargetSpecError) Reason() string {
+	return "InvalidCapacityTargetSpec"
+}
+
+func NewInvalidCapacityTargetSpecError(format string, args ...interface{}) InvalidCapacityTargetSpecError {
+	return InvalidCapacityTargetSpecError{
+		msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// ForeignDeploymentError is returned when a Deployment matches the labels of
+// a release, but wasn't installed by Shipper for it. Such Deployments are
+// never scaled.
+type ForeignDeploymentError struct {
+	deployment string
+	release    string
+}
+
+func (e ForeignDeploymentError) Error() string {
+	return fmt.Sprintf("Deployment %q has the labels of release %q, but was not installed by Shipper for it",
+		e.deployment, e.release)
+}
+
+// ShouldRetry is false, as capacity targets are synced again as soon as
+// their Deployments change.
+func (e ForeignDeploymentError) ShouldRetry() bool {
+	return false
+}
+
+func (e ForeignDeploymentError) Reason() string {
+	return "ForeignDeployment"
+}
+
+func IsForeignDeploymentError(err error) bool {
+	_, ok := err.(ForeignDeploymentError)
+	return ok
+}
+
+func NewForeignDeploymentError(deployment, release string) ForeignDeploymentError {
+	return ForeignDeploymentError{
+		deployment: deployment,
+		release:    release,
+	}
+}
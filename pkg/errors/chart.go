@@ -38,6 +38,10 @@ func (e ChartFetchFailureError) ShouldRetry() bool {
 	return true
 }
 
+func (e ChartFetchFailureError) Reason() string {
+	return "ChartFetchFailure"
+}
+
 func NewChartFetchFailureError(chartspec *shipper.Chart, err error) ChartFetchFailureError {
 	return ChartFetchFailureError{
 		ChartError: newChartError(chartspec),
@@ -45,6 +49,35 @@ func NewChartFetchFailureError(chartspec *shipper.Chart, err error) ChartFetchFa
 	}
 }
 
+type ChartDigestMismatchError struct {
+	ChartError
+	expected string
+	actual   string
+}
+
+func (e ChartDigestMismatchError) Error() string {
+	return fmt.Sprintf(
+		"chart [name: %q, version: %q, repo: %q] has digest %q, but the release was created with %q",
+		e.chartName, e.chartVersion, e.chartRepo,
+		e.actual, e.expected)
+}
+
+func (e ChartDigestMismatchError) ShouldRetry() bool {
+	return false
+}
+
+func (e ChartDigestMismatchError) Reason() string {
+	return "ChartDigestMismatch"
+}
+
+func NewChartDigestMismatchError(chartspec *shipper.Chart, actual string) ChartDigestMismatchError {
+	return ChartDigestMismatchError{
+		ChartError: newChartError(chartspec),
+		expected:   chartspec.Digest,
+		actual:     actual,
+	}
+}
+
 type BrokenChartSpecError struct {
 	chartspec *shipper.Chart
 	err       error
@@ -64,6 +97,10 @@ func (e BrokenChartSpecError) ShouldRetry() bool {
 	return false
 }
 
+func (e BrokenChartSpecError) Reason() string {
+	return "BrokenChartSpec"
+}
+
 func NewBrokenChartSpecError(chartspec *shipper.Chart, err error) BrokenChartSpecError {
 	return BrokenChartSpecError{
 		chartspec: chartspec,
@@ -90,6 +127,10 @@ func (e BrokenChartVersionError) ShouldRetry() bool {
 	return false
 }
 
+func (e BrokenChartVersionError) Reason() string {
+	return "BrokenChartVersion"
+}
+
 func NewBrokenChartVersionError(cv *repo.ChartVersion, err error) BrokenChartVersionError {
 	return BrokenChartVersionError{
 		cv:  cv,
@@ -99,14 +140,16 @@ func NewBrokenChartVersionError(cv *repo.ChartVersion, err error) BrokenChartVer
 
 type WrongChartDeploymentsError struct {
 	ChartError
+	expected        string
 	deploymentCount int
 }
 
 func (e WrongChartDeploymentsError) Error() string {
 	return fmt.Sprintf(
-		"chart %s-%s should have exactly 1 Deployment object, but it has %d",
+		"chart %s-%s should have %s Deployment object, but it has %d",
 		e.chartName,
 		e.chartVersion,
+		e.expected,
 		e.deploymentCount,
 	)
 }
@@ -115,13 +158,52 @@ func (e WrongChartDeploymentsError) ShouldRetry() bool {
 	return false
 }
 
-func NewWrongChartDeploymentsError(chartspec *shipper.Chart, deploymentCount int) WrongChartDeploymentsError {
+func (e WrongChartDeploymentsError) Reason() string {
+	return "WrongChartDeployments"
+}
+
+// NewWrongChartDeploymentsError reports a chart with deploymentCount
+// Deployments, when it should have the expected number of them, e.g.
+// "exactly 1" or "at least 1".
+func NewWrongChartDeploymentsError(chartspec *shipper.Chart, expected string, deploymentCount int) WrongChartDeploymentsError {
 	return WrongChartDeploymentsError{
 		ChartError:      newChartError(chartspec),
+		expected:        expected,
 		deploymentCount: deploymentCount,
 	}
 }
 
+type WrongChartJobsError struct {
+	ChartError
+	jobCount int
+}
+
+func (e WrongChartJobsError) Error() string {
+	return fmt.Sprintf(
+		"chart %s-%s should have exactly 1 Job object for a Job workload, but it has %d",
+		e.chartName,
+		e.chartVersion,
+		e.jobCount,
+	)
+}
+
+func (e WrongChartJobsError) ShouldRetry() bool {
+	return false
+}
+
+func (e WrongChartJobsError) Reason() string {
+	return "WrongChartJobs"
+}
+
+// NewWrongChartJobsError reports a chart for a Job workload with jobCount
+// Jobs, step hooks aside, when it should have exactly one.
+func NewWrongChartJobsError(chartspec *shipper.Chart, jobCount int) WrongChartJobsError {
+	return WrongChartJobsError{
+		ChartError: newChartError(chartspec),
+		jobCount:   jobCount,
+	}
+}
+
 type RenderManifestError struct {
 	err error
 }
@@ -134,6 +216,10 @@ func (e RenderManifestError) ShouldRetry() bool {
 	return false
 }
 
+func (e RenderManifestError) Reason() string {
+	return "RenderManifest"
+}
+
 func NewRenderManifestError(err error) RenderManifestError {
 	return RenderManifestError{err}
 }
@@ -155,6 +241,10 @@ func (e ChartVersionResolveError) ShouldRetry() bool {
 	return true
 }
 
+func (e ChartVersionResolveError) Reason() string {
+	return "ChartVersionResolve"
+}
+
 func NewChartVersionResolveError(chartspec *shipper.Chart, err error) ChartVersionResolveError {
 	return ChartVersionResolveError{
 		ChartError: newChartError(chartspec),
@@ -178,6 +268,10 @@ func (e ChartDataCorruptionError) ShouldRetry() bool {
 	return false
 }
 
+func (e ChartDataCorruptionError) Reason() string {
+	return "ChartDataCorruption"
+}
+
 func NewChartDataCorruptionError(cv *repo.ChartVersion, err error) ChartDataCorruptionError {
 	return ChartDataCorruptionError{
 		ChartError: ChartError{
@@ -204,6 +298,10 @@ func (e NoCachedChartRepoIndexError) ShouldRetry() bool {
 	return true
 }
 
+func (e NoCachedChartRepoIndexError) Reason() string {
+	return "NoCachedChartRepoIndex"
+}
+
 func NewNoCachedChartRepoIndexError(err error) NoCachedChartRepoIndexError {
 	return NoCachedChartRepoIndexError{err: err}
 }
@@ -223,6 +321,10 @@ func (e ChartRepoIndexError) ShouldRetry() bool {
 	return true
 }
 
+func (e ChartRepoIndexError) Reason() string {
+	return "ChartRepoIndex"
+}
+
 func NewChartRepoIndexError(err error) ChartRepoIndexError {
 	return ChartRepoIndexError{err: err}
 }
@@ -242,6 +344,10 @@ func (e ChartRepoInternalError) ShouldRetry() bool {
 	return true
 }
 
+func (e ChartRepoInternalError) Reason() string {
+	return "ChartRepoInternal"
+}
+
 func NewChartRepoInternalError(err error) ChartRepoInternalError {
 	return ChartRepoInternalError{
 		err: err,
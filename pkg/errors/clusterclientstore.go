@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"time"
 )
 
 type ClusterNotInStoreError struct {
@@ -16,6 +17,10 @@ func (e ClusterNotInStoreError) ShouldRetry() bool {
 	return true
 }
 
+func (e ClusterNotInStoreError) Reason() string {
+	return "ClusterNotInStore"
+}
+
 func NewClusterNotInStoreError(clusterName string) error {
 	return ClusterNotInStoreError{clusterName: clusterName}
 }
@@ -37,6 +42,10 @@ func (e ClusterNotReadyError) ShouldRetry() bool {
 	return true
 }
 
+func (e ClusterNotReadyError) Reason() string {
+	return "ClusterNotReady"
+}
+
 func NewClusterNotReadyError(clusterName string) error {
 	return ClusterNotReadyError{clusterName: clusterName}
 }
@@ -59,6 +68,10 @@ func (e ClusterClientBuildError) ShouldRetry() bool {
 	return true
 }
 
+func (e ClusterClientBuildError) Reason() string {
+	return "ClusterClientBuild"
+}
+
 func NewClusterClientBuild(clusterName string, err error) error {
 	return ClusterClientBuildError{
 		clusterName: clusterName,
@@ -66,9 +79,41 @@ func NewClusterClientBuild(clusterName string, err error) error {
 	}
 }
 
+// ClusterCircuitOpenError is returned for clusters whose API server failed
+// too many calls in a row, until their cooldown is over.
+type ClusterCircuitOpenError struct {
+	clusterName string
+	until       time.Time
+}
+
+func (e ClusterCircuitOpenError) Error() string {
+	return fmt.Sprintf("cluster %q is failing too many calls; not calling it again until %s",
+		e.clusterName, e.until.Format(time.RFC3339))
+}
+
+func (e ClusterCircuitOpenError) ShouldRetry() bool {
+	return true
+}
+
+func (e ClusterCircuitOpenError) Reason() string {
+	return "CircuitOpen"
+}
+
+func NewClusterCircuitOpenError(clusterName string, until time.Time) error {
+	return ClusterCircuitOpenError{
+		clusterName: clusterName,
+		until:       until,
+	}
+}
+
+func IsClusterCircuitOpenError(err error) bool {
+	_, ok := err.(ClusterCircuitOpenError)
+	return ok
+}
+
 func IsClusterClientStoreError(err error) bool {
 	switch err.(type) {
-	case ClusterNotReadyError, ClusterNotInStoreError, ClusterClientBuildError:
+	case ClusterNotReadyError, ClusterNotInStoreError, ClusterClientBuildError, ClusterCircuitOpenError:
 		return true
 	}
 
@@ -23,6 +23,10 @@ func (e UnexpectedObjectCountFromSelectorError) ShouldRetry() bool {
 	return false
 }
 
+func (e UnexpectedObjectCountFromSelectorError) Reason() string {
+	return "UnexpectedObjectCount"
+}
+
 func NewUnexpectedObjectCountFromSelectorError(
 	selector labels.Selector,
 	gvk schema.GroupVersionKind,
@@ -51,6 +55,10 @@ func (e WrongOwnerReferenceError) ShouldRetry() bool {
 	return false
 }
 
+func (e WrongOwnerReferenceError) Reason() string {
+	return "WrongOwnerReference"
+}
+
 func IsWrongOwnerReferenceError(err error) bool {
 	_, ok := err.(WrongOwnerReferenceError)
 	return ok
@@ -75,6 +83,10 @@ func (e InvalidChartError) ShouldRetry() bool {
 	return false
 }
 
+func (e InvalidChartError) Reason() string {
+	return "InvalidChart"
+}
+
 func IsInvalidChartError(err error) bool {
 	_, ok := err.(InvalidChartError)
 	return ok
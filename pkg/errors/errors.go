@@ -38,6 +38,26 @@ func ShouldRetry(err error) bool {
 	return true
 }
 
+// ReasonAware is an error that can describe itself with a short, stable,
+// CamelCase reason, suitable for condition reasons and metric labels.
+type ReasonAware interface {
+	Reason() string
+}
+
+// UnknownReason is the reason for errors that haven't been classified yet.
+const UnknownReason = "Unknown"
+
+// Reason returns a machine-readable reason for err. It trusts err.Reason if
+// err implements ReasonAware, and returns UnknownReason otherwise.
+func Reason(err error) string {
+	reasonAware, ok := err.(ReasonAware)
+	if ok {
+		return reasonAware.Reason()
+	}
+
+	return UnknownReason
+}
+
 // RecoverableError is a generic error that will cause an action to be retried.
 // It mostly behaves like any other error that doesn't implement the RetryAware
 // interface, but by using it we signal that this is an error that we're
@@ -119,6 +139,16 @@ func (e *MultiError) Flatten() error {
 	}
 }
 
+// Reason returns the reason of the only error in the collection, or
+// "MultipleErrors" if there is more than one.
+func (e *MultiError) Reason() string {
+	if len(e.Errors) == 1 {
+		return Reason(e.Errors[0])
+	}
+
+	return "MultipleErrors"
+}
+
 // ShouldRetry returns true when at least one error in the collection
 // should be retried, and false otherwise.
 func (e *MultiError) ShouldRetry() bool {
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func makeRetriable() error {
@@ -45,3 +47,31 @@ func TestMultiError_ShouldRetry(t *testing.T) {
 		t.Error("expected multierror without any retriable errors to be non-retriable")
 	}
 }
+
+func TestReason(t *testing.T) {
+	single := NewMultiError()
+	single.Append(NewNoRegionsSpecifiedError())
+
+	multiple := NewMultiError()
+	multiple.Append(NewNoRegionsSpecifiedError())
+	multiple.Append(makeRetriable())
+
+	errors := []struct {
+		err      error
+		expected string
+	}{
+		{errors.New("generic error"), UnknownReason},
+		{makeRetriable(), UnknownReason},
+		{NewNoRegionsSpecifiedError(), "NoRegionsSpecified"},
+		{NewKubeclientDiscoverError(schema.GroupVersion{}, errors.New("boom")), "FailedAPICall"},
+		{single, "NoRegionsSpecified"},
+		{multiple, "MultipleErrors"},
+	}
+
+	for _, tt := range errors {
+		reason := Reason(tt.err)
+		if reason != tt.expected {
+			t.Errorf("expected error %T to have reason %q, got %q", tt.err, tt.expected, reason)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type DecodeManifestError struct {
@@ -18,6 +19,10 @@ func (e DecodeManifestError) ShouldRetry() bool {
 	return false
 }
 
+func (e DecodeManifestError) Reason() string {
+	return "DecodeManifest"
+}
+
 func NewDecodeManifestError(format string, args ...interface{}) DecodeManifestError {
 	return DecodeManifestError{fmt.Errorf(format, args...)}
 }
@@ -39,6 +44,10 @@ func (e ConvertUnstructuredError) ShouldRetry() bool {
 	return false
 }
 
+func (e ConvertUnstructuredError) Reason() string {
+	return "ConvertUnstructured"
+}
+
 func NewConvertUnstructuredError(format string, args ...interface{}) ConvertUnstructuredError {
 	return ConvertUnstructuredError{fmt.Errorf(format, args...)}
 }
@@ -64,3 +73,203 @@ func (e InstallationTargetOwnershipError) Error() string {
 func (e InstallationTargetOwnershipError) ShouldRetry() bool {
 	return false
 }
+
+func (e InstallationTargetOwnershipError) Reason() string {
+	return "InstallationTargetOwnership"
+}
+
+type ValueSourceError struct {
+	path  string
+	err   error
+	retry bool
+}
+
+func (e ValueSourceError) Error() string {
+	return fmt.Sprintf("failed to resolve chart value %q: %s", e.path, e.err)
+}
+
+func (e ValueSourceError) ShouldRetry() bool {
+	return e.retry
+}
+
+func (e ValueSourceError) Reason() string {
+	return "ValueSource"
+}
+
+// NewValueSourceError reports a value that could not be read from its
+// source, which might work later, e.g. once its Secret is created.
+func NewValueSourceError(path string, err error) ValueSourceError {
+	return ValueSourceError{path: path, err: err, retry: true}
+}
+
+// NewInvalidValueSourceError reports a value source that can never be read.
+func NewInvalidValueSourceError(path string, format string, args ...interface{}) ValueSourceError {
+	return ValueSourceError{path: path, err: fmt.Errorf(format, args...), retry: false}
+}
+
+// CustomResourceDefinitionNotEstablishedError means a chart's
+// CustomResourceDefinition has been installed, but the API server doesn't
+// serve its custom resources yet.
+type CustomResourceDefinitionNotEstablishedError struct {
+	name string
+}
+
+func (e CustomResourceDefinitionNotEstablishedError) Error() string {
+	return fmt.Sprintf("waiting for CustomResourceDefinition %q to be established", e.name)
+}
+
+func (e CustomResourceDefinitionNotEstablishedError) ShouldRetry() bool {
+	return true
+}
+
+func (e CustomResourceDefinitionNotEstablishedError) Reason() string {
+	return "CustomResourceDefinitionNotEstablished"
+}
+
+func NewCustomResourceDefinitionNotEstablishedError(name string) CustomResourceDefinitionNotEstablishedError {
+	return CustomResourceDefinitionNotEstablishedError{name: name}
+}
+
+// ReadinessBarrierError means an object of a readiness barrier kind is not
+// ready yet, so objects that come after it in the install order have to wait.
+type ReadinessBarrierError struct {
+	kind    string
+	name    string
+	message string
+}
+
+func (e ReadinessBarrierError) Error() string {
+	return fmt.Sprintf("waiting for %s %q to be ready: %s", e.kind, e.name, e.message)
+}
+
+func (e ReadinessBarrierError) ShouldRetry() bool {
+	return true
+}
+
+func (e ReadinessBarrierError) Reason() string {
+	return "ReadinessBarrier"
+}
+
+func NewReadinessBarrierError(kind, name, message string) ReadinessBarrierError {
+	return ReadinessBarrierError{kind: kind, name: name, message: message}
+}
+
+// ChartHookPendingError means a Helm hook of a chart is still running, and
+// the installation can't go on until it completes.
+type ChartHookPendingError struct {
+	kind string
+	name string
+	hook string
+}
+
+func (e ChartHookPendingError) Error() string {
+	return fmt.Sprintf("waiting for %s hook %s %q to complete", e.hook, e.kind, e.name)
+}
+
+func (e ChartHookPendingError) ShouldRetry() bool {
+	return true
+}
+
+func (e ChartHookPendingError) Reason() string {
+	return "WaitingForHooks"
+}
+
+func NewChartHookPendingError(kind, name, hook string) ChartHookPendingError {
+	return ChartHookPendingError{kind: kind, name: name, hook: hook}
+}
+
+// ChartHookFailedError means a Helm hook of a chart failed. Hooks only ever
+// run once, so there's no point in retrying.
+type ChartHookFailedError struct {
+	kind string
+	name string
+	hook string
+	msg  string
+}
+
+func (e ChartHookFailedError) Error() string {
+	return fmt.Sprintf("%s hook %s %q failed: %s", e.hook, e.kind, e.name, e.msg)
+}
+
+func (e ChartHookFailedError) ShouldRetry() bool {
+	return false
+}
+
+func (e ChartHookFailedError) Reason() string {
+	return "ChartHookFailed"
+}
+
+func NewChartHookFailedError(kind, name, hook, msg string) ChartHookFailedError {
+	return ChartHookFailedError{kind: kind, name: name, hook: hook, msg: msg}
+}
+
+// ImageRegistryNotAllowedError means an image in a chart comes from a
+// registry that isn't allowed in the cluster. It won't come from anywhere
+// else until the release changes, so there's no point in retrying.
+type ImageRegistryNotAllowedError struct {
+	image string
+}
+
+func (e ImageRegistryNotAllowedError) Error() string {
+	return fmt.Sprintf("image %q does not come from an allowed registry", e.image)
+}
+
+func (e ImageRegistryNotAllowedError) ShouldRetry() bool {
+	return false
+}
+
+func (e ImageRegistryNotAllowedError) Reason() string {
+	return "ImageRegistryNotAllowed"
+}
+
+func NewImageRegistryNotAllowedError(image string) ImageRegistryNotAllowedError {
+	return ImageRegistryNotAllowedError{image: image}
+}
+
+// ImageVerificationError means the signature of an image in a chart could
+// not be verified. It's retried, as the image can still be signed, or its
+// registry might have been unreachable.
+type ImageVerificationError struct {
+	image string
+	err   error
+}
+
+func (e ImageVerificationError) Error() string {
+	return fmt.Sprintf("could not verify image %q: %s", e.image, e.err)
+}
+
+func (e ImageVerificationError) ShouldRetry() bool {
+	return true
+}
+
+func (e ImageVerificationError) Reason() string {
+	return "ImageVerificationFailed"
+}
+
+func NewImageVerificationError(image string, err error) ImageVerificationError {
+	return ImageVerificationError{image: image, err: err}
+}
+
+// APIVersionNotServedError means an application cluster serves the kind of
+// an object in a chart in neither its API version nor a compatible one. It
+// won't until the cluster is upgraded, so there's no point in retrying.
+type APIVersionNotServedError struct {
+	gvk schema.GroupVersionKind
+}
+
+func (e APIVersionNotServedError) Error() string {
+	return fmt.Sprintf("cluster does not serve %s in %s or any compatible version",
+		e.gvk.Kind, e.gvk.GroupVersion())
+}
+
+func (e APIVersionNotServedError) ShouldRetry() bool {
+	return false
+}
+
+func (e APIVersionNotServedError) Reason() string {
+	return "APIVersionNotServed"
+}
+
+func NewAPIVersionNotServedError(gvk schema.GroupVersionKind) APIVersionNotServedError {
+	return APIVersionNotServedError{gvk: gvk}
+}
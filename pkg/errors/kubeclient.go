@@ -69,6 +69,10 @@ func (e KubeclientError) ShouldRetry() bool {
 	return true
 }
 
+func (e KubeclientError) Reason() string {
+	return "FailedAPICall"
+}
+
 // WithKind returns a new KubeclientError associated with a
 // gvk.GroupVersionKind. All KubeclientErrors are expected to have this
 // property set, so error messages can be generated with enough information.
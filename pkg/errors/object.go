@@ -20,6 +20,10 @@ func (e MissingShipperLabelError) ShouldRetry() bool {
 	return false
 }
 
+func (e MissingShipperLabelError) Reason() string {
+	return "MissingShipperLabel"
+}
+
 func NewMissingShipperLabelError(obj metav1.Object, label string) MissingShipperLabelError {
 	return MissingShipperLabelError{
 		obj:   obj,
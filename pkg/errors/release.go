@@ -19,6 +19,10 @@ func (e ContenderNotFoundError) ShouldRetry() bool {
 	return true
 }
 
+func (e ContenderNotFoundError) Reason() string {
+	return "ContenderNotFound"
+}
+
 func IsContenderNotFoundError(err error) bool {
 	_, ok := err.(*ContenderNotFoundError)
 	return ok
@@ -40,6 +44,10 @@ func (e IncumbentNotFoundError) ShouldRetry() bool {
 	return true
 }
 
+func (e IncumbentNotFoundError) Reason() string {
+	return "IncumbentNotFound"
+}
+
 func IsIncumbentNotFoundError(err error) bool {
 	_, ok := err.(*IncumbentNotFoundError)
 	return ok
@@ -61,6 +69,10 @@ func (e MissingGenerationAnnotationError) ShouldRetry() bool {
 	return true
 }
 
+func (e MissingGenerationAnnotationError) Reason() string {
+	return "MissingGenerationAnnotation"
+}
+
 func IsMissingGenerationAnnotationError(err error) bool {
 	_, ok := err.(*MissingGenerationAnnotationError)
 	return ok
@@ -83,6 +95,10 @@ func (e *InvalidGenerationAnnotationError) ShouldRetry() bool {
 	return true
 }
 
+func (e *InvalidGenerationAnnotationError) Reason() string {
+	return "InvalidGenerationAnnotation"
+}
+
 func IsInvalidGenerationAnnotationError(err error) bool {
 	_, ok := err.(*InvalidGenerationAnnotationError)
 	return ok
@@ -102,6 +118,10 @@ func (e NoRegionsSpecifiedError) ShouldRetry() bool {
 	return false
 }
 
+func (e NoRegionsSpecifiedError) Reason() string {
+	return "NoRegionsSpecified"
+}
+
 func NewNoRegionsSpecifiedError() NoRegionsSpecifiedError {
 	return NoRegionsSpecifiedError{}
 }
@@ -120,6 +140,10 @@ func (e NotEnoughClustersInRegionError) ShouldRetry() bool {
 	return false
 }
 
+func (e NotEnoughClustersInRegionError) Reason() string {
+	return "NotEnoughClustersInRegion"
+}
+
 func NewNotEnoughClustersInRegionError(region string, required, available int) NotEnoughClustersInRegionError {
 	return NotEnoughClustersInRegionError{
 		region:    region,
@@ -147,6 +171,10 @@ func (e NotEnoughCapableClustersInRegionError) ShouldRetry() bool {
 	return false
 }
 
+func (e NotEnoughCapableClustersInRegionError) Reason() string {
+	return "NotEnoughCapableClustersInRegion"
+}
+
 func NewNotEnoughCapableClustersInRegionError(region string, capabilities []string, required, available int) error {
 	return NotEnoughCapableClustersInRegionError{
 		region:       region,
@@ -171,12 +199,146 @@ func (e DuplicateCapabilityRequirementError) ShouldRetry() bool {
 	return false
 }
 
+func (e DuplicateCapabilityRequirementError) Reason() string {
+	return "DuplicateCapabilityRequirement"
+}
+
 func NewDuplicateCapabilityRequirementError(capability string) DuplicateCapabilityRequirementError {
 	return DuplicateCapabilityRequirementError{
 		capability: capability,
 	}
 }
 
+type DuplicatePlatformRequirementError struct {
+	platform string
+}
+
+func (e DuplicatePlatformRequirementError) Error() string {
+	return fmt.Sprintf(
+		"Platform %q listed more than once in clusterRequirements",
+		e.platform,
+	)
+}
+
+func (e DuplicatePlatformRequirementError) ShouldRetry() bool {
+	return false
+}
+
+func (e DuplicatePlatformRequirementError) Reason() string {
+	return "DuplicatePlatformRequirement"
+}
+
+func NewDuplicatePlatformRequirementError(platform string) DuplicatePlatformRequirementError {
+	return DuplicatePlatformRequirementError{
+		platform: platform,
+	}
+}
+
+// ImagePlatformsError is returned when the images of a release don't have
+// manifests for all the platforms it asks to run on. It's retried, as the
+// missing manifests can still be pushed.
+type ImagePlatformsError struct {
+	image   string
+	missing []string
+}
+
+func (e ImagePlatformsError) Error() string {
+	return fmt.Sprintf(
+		"image %q has no manifest for platforms %s",
+		e.image, strings.Join(e.missing, ", "),
+	)
+}
+
+func (e ImagePlatformsError) ShouldRetry() bool {
+	return true
+}
+
+func (e ImagePlatformsError) Reason() string {
+	return "ImagePlatformsMissing"
+}
+
+func NewImagePlatformsError(image string, missing []string) ImagePlatformsError {
+	return ImagePlatformsError{
+		image:   image,
+		missing: missing,
+	}
+}
+
+// ImageInspectionError is returned when the registry of one of a release's
+// images can't tell which platforms it has manifests for.
+type ImageInspectionError struct {
+	image string
+	err   error
+}
+
+func (e ImageInspectionError) Error() string {
+	return fmt.Sprintf("failed to inspect image %q: %s", e.image, e.err)
+}
+
+func (e ImageInspectionError) ShouldRetry() bool {
+	return true
+}
+
+func (e ImageInspectionError) Reason() string {
+	return "ImageInspectionFailed"
+}
+
+func NewImageInspectionError(image string, err error) ImageInspectionError {
+	return ImageInspectionError{
+		image: image,
+		err:   err,
+	}
+}
+
+type InvalidClusterSpreadError struct {
+	msg string
+}
+
+func (e InvalidClusterSpreadError) Error() string {
+	return fmt.Sprintf("invalid clusterRequirements.spread: %s", e.msg)
+}
+
+func (e InvalidClusterSpreadError) ShouldRetry() bool {
+	return false
+}
+
+func (e InvalidClusterSpreadError) Reason() string {
+	return "InvalidClusterSpread"
+}
+
+func NewInvalidClusterSpreadError(format string, args ...interface{}) InvalidClusterSpreadError {
+	return InvalidClusterSpreadError{
+		msg: fmt.Sprintf(format, args...),
+	}
+}
+
+type UnsatisfiedApplicationAffinityError struct {
+	application string
+}
+
+func (e UnsatisfiedApplicationAffinityError) Error() string {
+	return fmt.Sprintf(
+		"Application %q, listed in applicationAffinity, has no Release scheduled on any cluster yet",
+		e.application,
+	)
+}
+
+// ShouldRetry is true because the other Application may be scheduled later
+// on.
+func (e UnsatisfiedApplicationAffinityError) ShouldRetry() bool {
+	return true
+}
+
+func (e UnsatisfiedApplicationAffinityError) Reason() string {
+	return "UnsatisfiedApplicationAffinity"
+}
+
+func NewUnsatisfiedApplicationAffinityError(application string) UnsatisfiedApplicationAffinityError {
+	return UnsatisfiedApplicationAffinityError{
+		application: application,
+	}
+}
+
 type InconsistentReleaseTargetStep struct {
 	relKey         string
 	gotTargetStep  int32
@@ -192,6 +354,10 @@ func (e InconsistentReleaseTargetStep) ShouldRetry() bool {
 	return false
 }
 
+func (e InconsistentReleaseTargetStep) Reason() string {
+	return "InconsistentReleaseTargetStep"
+}
+
 func NewInconsistentReleaseTargetStep(relKey string, gotTargetStep, wantTargetStep int32) InconsistentReleaseTargetStep {
 	return InconsistentReleaseTargetStep{
 		relKey:         relKey,
@@ -215,6 +381,10 @@ func (e MultipleTargetObjectsForReleaseError) ShouldRetry() bool {
 	return false
 }
 
+func (e MultipleTargetObjectsForReleaseError) Reason() string {
+	return "MultipleTargetObjectsForRelease"
+}
+
 func NewMultipleTargetObjectsForReleaseError(kind, ns, releaseName string) MultipleTargetObjectsForReleaseError {
 	return MultipleTargetObjectsForReleaseError{
 		ns:          ns,
@@ -222,3 +392,330 @@ func NewMultipleTargetObjectsForReleaseError(kind, ns, releaseName string) Multi
 		releaseName: releaseName,
 	}
 }
+
+type StepNotApprovedError struct {
+	relKey    string
+	step      int32
+	approvers []string
+}
+
+func (e StepNotApprovedError) Error() string {
+	if len(e.approvers) == 0 {
+		return fmt.Sprintf("Release %q is waiting for approval of step %d", e.relKey, e.step)
+	}
+
+	return fmt.Sprintf("Release %q is waiting for approval of step %d by one of: %s",
+		e.relKey, e.step, strings.Join(e.approvers, ", "))
+}
+
+func (e StepNotApprovedError) ShouldRetry() bool {
+	return false
+}
+
+func (e StepNotApprovedError) Reason() string {
+	return "StepNotApproved"
+}
+
+func NewStepNotApprovedError(relKey string, step int32, approvers []string) StepNotApprovedError {
+	return StepNotApprovedError{
+		relKey:    relKey,
+		step:      step,
+		approvers: approvers,
+	}
+}
+
+type DependencyNotCompleteError struct {
+	relKey     string
+	dependency string
+	msg        string
+}
+
+func (e DependencyNotCompleteError) Error() string {
+	return fmt.Sprintf("Release %q is waiting for application %q: %s", e.relKey, e.dependency, e.msg)
+}
+
+func (e DependencyNotCompleteError) ShouldRetry() bool {
+	return false
+}
+
+func (e DependencyNotCompleteError) Reason() string {
+	return "WaitingForDependency"
+}
+
+func NewDependencyNotCompleteError(relKey, dependency, format string, args ...interface{}) DependencyNotCompleteError {
+	return DependencyNotCompleteError{
+		relKey:     relKey,
+		dependency: dependency,
+		msg:        fmt.Sprintf(format, args...),
+	}
+}
+
+type InvalidRolloutStrategyError struct {
+	msg string
+}
+
+func (e InvalidRolloutStrategyError) Error() string {
+	return fmt.Sprintf("invalid strategy: %s", e.msg)
+}
+
+func (e InvalidRolloutStrategyError) ShouldRetry() bool {
+	return false
+}
+
+func (e InvalidRolloutStrategyError) Reason() string {
+	return "InvalidRolloutStrategy"
+}
+
+func NewInvalidRolloutStrategyError(format string, args ...interface{}) InvalidRolloutStrategyError {
+	return InvalidRolloutStrategyError{
+		msg: fmt.Sprintf(format, args...),
+	}
+}
+
+type StepHookPendingError struct {
+	relKey  string
+	step    int32
+	hook    string
+	cluster string
+}
+
+func (e StepHookPendingError) Error() string {
+	return fmt.Sprintf("Release %q is waiting for hook %q of step %d to complete in cluster %q",
+		e.relKey, e.hook, e.step, e.cluster)
+}
+
+func (e StepHookPendingError) ShouldRetry() bool {
+	return true
+}
+
+func (e StepHookPendingError) Reason() string {
+	return "WaitingForHooks"
+}
+
+func NewStepHookPendingError(relKey string, step int32, hook, cluster string) StepHookPendingError {
+	return StepHookPendingError{
+		relKey:  relKey,
+		step:    step,
+		hook:    hook,
+		cluster: cluster,
+	}
+}
+
+type StepHookFailedError struct {
+	relKey  string
+	step    int32
+	hook    string
+	cluster string
+	msg     string
+}
+
+func (e StepHookFailedError) Error() string {
+	return fmt.Sprintf("hook %q of step %d of Release %q failed in cluster %q: %s",
+		e.hook, e.step, e.relKey, e.cluster, e.msg)
+}
+
+func (e StepHookFailedError) ShouldRetry() bool {
+	return false
+}
+
+func (e StepHookFailedError) Reason() string {
+	return "HookFailed"
+}
+
+func NewStepHookFailedError(relKey string, step int32, hook, cluster, msg string) StepHookFailedError {
+	return StepHookFailedError{
+		relKey:  relKey,
+		step:    step,
+		hook:    hook,
+		cluster: cluster,
+		msg:     msg,
+	}
+}
+
+// PolicyViolationError means a release violates a Policy. It's retried, as
+// policies can change in the policy engine without Shipper hearing about
+// it.
+type PolicyViolationError struct {
+	relKey     string
+	policy     string
+	stage      shipper.PolicyStage
+	violations []string
+}
+
+func (e PolicyViolationError) Error() string {
+	return fmt.Sprintf("Release %q violates policy %q at stage %s: %s",
+		e.relKey, e.policy, e.stage, strings.Join(e.violations, "; "))
+}
+
+func (e PolicyViolationError) ShouldRetry() bool {
+	return true
+}
+
+func (e PolicyViolationError) Reason() string {
+	return shipper.PolicyViolationReason
+}
+
+func NewPolicyViolationError(relKey, policy string, stage shipper.PolicyStage, violations []string) PolicyViolationError {
+	return PolicyViolationError{
+		relKey:     relKey,
+		policy:     policy,
+		stage:      stage,
+		violations: violations,
+	}
+}
+
+type PolicyEvaluationError struct {
+	policy string
+	err    error
+}
+
+func (e PolicyEvaluationError) Error() string {
+	return fmt.Sprintf("could not evaluate policy %q: %s", e.policy, e.err)
+}
+
+func (e PolicyEvaluationError) ShouldRetry() bool {
+	return true
+}
+
+func (e PolicyEvaluationError) Reason() string {
+	return "PolicyEvaluationFailed"
+}
+
+func NewPolicyEvaluationError(policy string, err error) PolicyEvaluationError {
+	return PolicyEvaluationError{policy: policy, err: err}
+}
+
+// RolloutQuotaExceededError means a release waits in the rollout queue of
+// its namespace, either for a RolloutQuota to have room for it or for the
+// releases ahead of it. It's retried, as it's only waiting for other
+// rollouts to complete.
+type RolloutQuotaExceededError struct {
+	relKey   string
+	position int
+	msg      string
+}
+
+func (e RolloutQuotaExceededError) Error() string {
+	return fmt.Sprintf("Release %q is number %d in the rollout queue of its namespace, %s",
+		e.relKey, e.position, e.msg)
+}
+
+func (e RolloutQuotaExceededError) ShouldRetry() bool {
+	return true
+}
+
+func (e RolloutQuotaExceededError) Reason() string {
+	return shipper.RolloutQuotaExceededReason
+}
+
+func NewRolloutQuotaExceededError(relKey string, position int, msg string) RolloutQuotaExceededError {
+	return RolloutQuotaExceededError{
+		relKey:   relKey,
+		position: position,
+		msg:      msg,
+	}
+}
+
+// RolloutQuotaUnsatisfiableError means a release asks for more replicas
+// than a RolloutQuota of its namespace ever lets roll out at once. It's not
+// retried, as only a change to the quota can let it through.
+type RolloutQuotaUnsatisfiableError struct {
+	relKey   string
+	quotaKey string
+	replicas int32
+	max      int32
+}
+
+func (e RolloutQuotaUnsatisfiableError) Error() string {
+	return fmt.Sprintf(
+		"Release %q asks for %d replicas, more than the at most %d of rollout quota %q: it can't roll out unless the quota is raised",
+		e.relKey, e.replicas, e.max, e.quotaKey)
+}
+
+func (e RolloutQuotaUnsatisfiableError) ShouldRetry() bool {
+	return false
+}
+
+func (e RolloutQuotaUnsatisfiableError) Reason() string {
+	return shipper.RolloutQuotaUnsatisfiableReason
+}
+
+func NewRolloutQuotaUnsatisfiableError(relKey, quotaKey string, replicas, max int32) RolloutQuotaUnsatisfiableError {
+	return RolloutQuotaUnsatisfiableError{
+		relKey:   relKey,
+		quotaKey: quotaKey,
+		replicas: replicas,
+		max:      max,
+	}
+}
+
+type PullClusterStepUnsupportedError struct {
+	relKey      string
+	step        string
+	clusterName string
+}
+
+func (e PullClusterStepUnsupportedError) Error() string {
+	return fmt.Sprintf(
+		"step %q of release %q has hooks or probes, which cluster %q can't run as it's in pull mode",
+		e.step, e.relKey, e.clusterName)
+}
+
+func (e PullClusterStepUnsupportedError) ShouldRetry() bool {
+	return false
+}
+
+func (e PullClusterStepUnsupportedError) Reason() string {
+	return "PullClusterStepUnsupported"
+}
+
+func NewPullClusterStepUnsupportedError(relKey, step, clusterName string) PullClusterStepUnsupportedError {
+	return PullClusterStepUnsupportedError{
+		relKey:      relKey,
+		step:        step,
+		clusterName: clusterName,
+	}
+}
+
+type ReleaseCompletionError struct {
+	relKey string
+	err    error
+}
+
+func (e ReleaseCompletionError) Error() string {
+	return fmt.Sprintf("could not report completion of release %q: %s", e.relKey, e.err)
+}
+
+func (e ReleaseCompletionError) ShouldRetry() bool {
+	return true
+}
+
+func (e ReleaseCompletionError) Reason() string {
+	return "ReleaseCompletionFailed"
+}
+
+func NewReleaseCompletionError(relKey string, err error) ReleaseCompletionError {
+	return ReleaseCompletionError{relKey: relKey, err: err}
+}
+
+type CutoverError struct {
+	relKey      string
+	clusterName string
+	err         error
+}
+
+func (e CutoverError) Error() string {
+	return fmt.Sprintf("could not cut over to release %q in cluster %q: %s", e.relKey, e.clusterName, e.err)
+}
+
+func (e CutoverError) ShouldRetry() bool {
+	return true
+}
+
+func (e CutoverError) Reason() string {
+	return "CutoverFailed"
+}
+
+func NewCutoverError(relKey, clusterName string, err error) CutoverError {
+	return CutoverError{relKey: relKey, clusterName: clusterName, err: err}
+}
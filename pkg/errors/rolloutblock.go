@@ -17,6 +17,10 @@ func (e InvalidRolloutBlockOverrideError) ShouldRetry() bool {
 	return false
 }
 
+func (e InvalidRolloutBlockOverrideError) Reason() string {
+	return "InvalidRolloutBlockOverride"
+}
+
 func NewInvalidRolloutBlockOverrideError(invalidRolloutBlockName string) InvalidRolloutBlockOverrideError {
 	return InvalidRolloutBlockOverrideError{invalidRolloutBlockName}
 }
@@ -31,6 +35,10 @@ func (e RolloutBlockError) ShouldRetry() bool {
 	return false
 }
 
+func (e RolloutBlockError) Reason() string {
+	return "RolloutBlock"
+}
+
 func NewRolloutBlockError(invalidRolloutBlockName string) RolloutBlockError {
 	return RolloutBlockError(fmt.Sprintf("rollout block(s) with name(s) %s exist",
 		invalidRolloutBlockName))
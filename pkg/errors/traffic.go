@@ -19,6 +19,10 @@ func (e MultipleTrafficTargetsForReleaseError) ShouldRetry() bool {
 	return false
 }
 
+func (e MultipleTrafficTargetsForReleaseError) Reason() string {
+	return "MultipleTrafficTargetsForRelease"
+}
+
 func NewMultipleTrafficTargetsForReleaseError(ns, releaseName string, ttNames []string) MultipleTrafficTargetsForReleaseError {
 	return MultipleTrafficTargetsForReleaseError{
 		ns:          ns,
@@ -26,3 +30,96 @@ func NewMultipleTrafficTargetsForReleaseError(ns, releaseName string, ttNames []
 		ttNames:     ttNames,
 	}
 }
+
+type ExternalLoadBalancerError struct {
+	ns          string
+	releaseName string
+	err         error
+}
+
+func (e ExternalLoadBalancerError) Error() string {
+	return fmt.Sprintf(`failed to update external load balancer weight for release "%s/%s": %s`,
+		e.ns, e.releaseName, e.err)
+}
+
+func (e ExternalLoadBalancerError) ShouldRetry() bool {
+	return true
+}
+
+func (e ExternalLoadBalancerError) Reason() string {
+	return "ExternalLoadBalancer"
+}
+
+func NewExternalLoadBalancerError(ns, releaseName string, err error) ExternalLoadBalancerError {
+	return ExternalLoadBalancerError{
+		ns:          ns,
+		releaseName: releaseName,
+		err:         err,
+	}
+}
+
+type TrafficBackendNotAvailableError struct {
+	backend string
+}
+
+func (e TrafficBackendNotAvailableError) Error() string {
+	return fmt.Sprintf(`traffic backend %q is not available in this cluster`, e.backend)
+}
+
+func (e TrafficBackendNotAvailableError) ShouldRetry() bool {
+	return false
+}
+
+func (e TrafficBackendNotAvailableError) Reason() string {
+	return "TrafficBackendNotAvailable"
+}
+
+func NewTrafficBackendNotAvailableError(backend string) TrafficBackendNotAvailableError {
+	return TrafficBackendNotAvailableError{
+		backend: backend,
+	}
+}
+
+type ShadowTrafficNotSupportedError struct {
+	backend string
+}
+
+func (e ShadowTrafficNotSupportedError) Error() string {
+	return fmt.Sprintf(`traffic backend %q can not mirror traffic`, e.backend)
+}
+
+func (e ShadowTrafficNotSupportedError) ShouldRetry() bool {
+	return false
+}
+
+func (e ShadowTrafficNotSupportedError) Reason() string {
+	return "ShadowTrafficNotSupported"
+}
+
+func NewShadowTrafficNotSupportedError(backend string) ShadowTrafficNotSupportedError {
+	return ShadowTrafficNotSupportedError{
+		backend: backend,
+	}
+}
+
+type StickyBucketingNotSupportedError struct {
+	backend string
+}
+
+func (e StickyBucketingNotSupportedError) Error() string {
+	return fmt.Sprintf(`traffic backend %q can not keep users on the same release`, e.backend)
+}
+
+func (e StickyBucketingNotSupportedError) ShouldRetry() bool {
+	return false
+}
+
+func (e StickyBucketingNotSupportedError) Reason() string {
+	return "StickyBucketingNotSupported"
+}
+
+func NewStickyBucketingNotSupportedError(backend string) StickyBucketingNotSupportedError {
+	return StickyBucketingNotSupportedError{
+		backend: backend,
+	}
+}
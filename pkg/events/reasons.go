@@ -0,0 +1,82 @@
+// Package events holds the reasons of every Kubernetes Event Shipper emits.
+// Controllers must use these instead of ad-hoc strings, so alerting rules
+// built on event reasons work the same across controllers.
+package events
+
+// Condition changes. These are Normal events whose message is a diff of the
+// object's conditions.
+const (
+	ApplicationConditionChanged        = "ApplicationConditionChanged"
+	ReleaseConditionChanged            = "ReleaseConditionChanged"
+	InstallationTargetConditionChanged = "InstallationTargetConditionChanged"
+	CapacityTargetConditionChanged     = "CapacityTargetConditionChanged"
+	TrafficTargetConditionChanged      = "TrafficTargetConditionChanged"
+)
+
+// Rollout progress. These are Normal events.
+const (
+	// ApplicationSyncedFromGit is emitted when an Application is created
+	// or updated from a git repository.
+	ApplicationSyncedFromGit = "ApplicationSyncedFromGit"
+	// ReleaseScheduled is emitted when a target object is created for a
+	// Release in an application cluster.
+	ReleaseScheduled = "ReleaseScheduled"
+	// StepAchieved is emitted when a Release completes a strategy step.
+	StepAchieved = "StepAchieved"
+	// StepHookCreated is emitted when a Job for a strategy step hook is
+	// created in an application cluster.
+	StepHookCreated = "StepHookCreated"
+	// RolloutBlockOverridden is emitted when a rollout goes ahead in spite
+	// of rollout blocks, because they were overridden.
+	RolloutBlockOverridden = "RolloutBlockOverridden"
+	// ReleaseCompleted is emitted when a Release completes its rollout,
+	// once its objects and Application are annotated with it. The
+	// completion webhook, if any, is called afterwards.
+	ReleaseCompleted = "ReleaseCompleted"
+	// ReleaseCutOver is emitted when Ingresses and DNS records are pointed
+	// at the Service of a Release that got all the traffic.
+	ReleaseCutOver = "ReleaseCutOver"
+	// IncumbentStandbyEnded is emitted when an incumbent kept on standby
+	// after its contender completed is scaled down to zero.
+	IncumbentStandbyEnded = "IncumbentStandbyEnded"
+	// InstantRollbackCompleted is emitted when a Release rolled back with
+	// `shipperctl rollback --instant` gave all the traffic back to its
+	// incumbent.
+	InstantRollbackCompleted = "InstantRollbackCompleted"
+)
+
+// Failures. These are Warning events whose message is the error.
+const (
+	// RolloutBlocked is emitted when rollout blocks hold back a rollout.
+	RolloutBlocked = "RolloutBlocked"
+	// ClusterNotReady is emitted when an application cluster can't be
+	// reached.
+	ClusterNotReady = "ClusterNotReady"
+	// ChartFetchFailed is emitted when a Release's chart can't be fetched
+	// or read.
+	ChartFetchFailed = "ChartFetchFailed"
+	// ClusterMissingAPIs is emitted when a cluster is left out of the
+	// clusters of a Release because it doesn't serve the kind of some
+	// object in its chart.
+	ClusterMissingAPIs = "ClusterMissingAPIs"
+	// InstallationFailed is emitted when an InstallationTarget's chart
+	// can't be rendered or installed.
+	InstallationFailed = "InstallationFailed"
+	// CapacityChangeFailed is emitted when a CapacityTarget can't be
+	// brought to its desired capacity.
+	CapacityChangeFailed = "CapacityChangeFailed"
+	// TrafficShiftFailed is emitted when a TrafficTarget can't be brought
+	// to its desired traffic weight.
+	TrafficShiftFailed = "TrafficShiftFailed"
+	// ChartRepositoryInvalid is emitted when charts can't be fetched the
+	// way a ChartRepository says, such as when its CA bundle or client
+	// certificate can't be parsed.
+	ChartRepositoryInvalid = "ChartRepositoryInvalid"
+	// ReleaseCompletionFailed is emitted when the objects or Application
+	// of a Release that completed its rollout can't be annotated with it,
+	// or the completion webhook can't be called.
+	ReleaseCompletionFailed = "ReleaseCompletionFailed"
+	// CutoverFailed is emitted when Ingresses and DNS records can't be
+	// pointed at the Service of a Release that got all the traffic.
+	CutoverFailed = "CutoverFailed"
+)
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// Verbosity decides which events Shipper's controllers emit.
+type Verbosity string
+
+const (
+	// VerbosityAll emits every event.
+	VerbosityAll Verbosity = "all"
+	// VerbosityWarnings only emits Warning events.
+	VerbosityWarnings Verbosity = "warnings"
+	// VerbosityNone doesn't emit any events.
+	VerbosityNone Verbosity = "none"
+)
+
+const (
+	// DefaultDedupInterval is how long identical events for the same
+	// object are dropped for after the first one.
+	DefaultDedupInterval = 5 * time.Minute
+
+	// DefaultQPS and DefaultBurst bound how many events are written to
+	// the API server for each object. They're the same as client-go's
+	// defaults.
+	DefaultQPS   = 1. / 300.
+	DefaultBurst = 25
+
+	dedupCacheSize = 4096
+)
+
+// ParseVerbosity returns the Verbosity named by s.
+func ParseVerbosity(s string) (Verbosity, error) {
+	switch v := Verbosity(s); v {
+	case VerbosityAll, VerbosityWarnings, VerbosityNone:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown event verbosity %q, must be one of %q, %q or %q",
+			s, VerbosityAll, VerbosityWarnings, VerbosityNone)
+	}
+}
+
+// NewBroadcaster returns an event broadcaster that aggregates similar events
+// and lets each object have at most burst events written for it, then one
+// every 1/qps seconds.
+func NewBroadcaster(qps float32, burst int) record.EventBroadcaster {
+	return record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		QPS:       qps,
+		BurstSize: burst,
+	})
+}
+
+// recorder wraps an EventRecorder, dropping events below its verbosity and
+// events identical to one recorded for the same object less than
+// dedupInterval ago. Controllers sync every target every few minutes, and
+// most of those syncs have nothing new to say.
+type recorder struct {
+	recorder record.EventRecorder
+	recent   *cache.LRUExpireCache
+
+	mut           sync.RWMutex
+	verbosity     Verbosity
+	dedupInterval time.Duration
+}
+
+// ConfigurableRecorder is an EventRecorder whose verbosity and
+// deduplication can be changed while it's in use.
+type ConfigurableRecorder interface {
+	record.EventRecorder
+	Configure(verbosity Verbosity, dedupInterval time.Duration)
+}
+
+var _ ConfigurableRecorder = (*recorder)(nil)
+
+// NewRecorder returns an EventRecorder that passes events on to r, unless
+// they are below verbosity or duplicate an event recorded in the last
+// dedupInterval. A zero dedupInterval disables deduplication.
+func NewRecorder(r record.EventRecorder, verbosity Verbosity, dedupInterval time.Duration) ConfigurableRecorder {
+	return newRecorderWithClock(r, verbosity, dedupInterval, clock{})
+}
+
+func newRecorderWithClock(r record.EventRecorder, verbosity Verbosity, dedupInterval time.Duration, c cache.Clock) *recorder {
+	return &recorder{
+		recorder:      r,
+		verbosity:     verbosity,
+		dedupInterval: dedupInterval,
+		recent:        cache.NewLRUExpireCacheWithClock(dedupCacheSize, c),
+	}
+}
+
+// Configure makes r only pass on events that are at or above verbosity,
+// and that don't duplicate an event recorded in the last dedupInterval.
+func (r *recorder) Configure(verbosity Verbosity, dedupInterval time.Duration) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.verbosity = verbosity
+	r.dedupInterval = dedupInterval
+}
+
+func (r *recorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.shouldRecord(object, eventtype, reason, message) {
+		r.recorder.Event(object, eventtype, reason, message)
+	}
+}
+
+func (r *recorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *recorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.shouldRecord(object, eventtype, reason, message) {
+		r.recorder.PastEventf(object, timestamp, eventtype, reason, "%s", message)
+	}
+}
+
+func (r *recorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.shouldRecord(object, eventtype, reason, message) {
+		r.recorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+	}
+}
+
+func (r *recorder) shouldRecord(object runtime.Object, eventtype, reason, message string) bool {
+	r.mut.RLock()
+	verbosity, dedupInterval := r.verbosity, r.dedupInterval
+	r.mut.RUnlock()
+
+	switch verbosity {
+	case VerbosityNone:
+		return false
+	case VerbosityWarnings:
+		if eventtype != corev1.EventTypeWarning {
+			return false
+		}
+	}
+
+	if dedupInterval <= 0 {
+		return true
+	}
+
+	objMeta, err := meta.Accessor(object)
+	if err != nil {
+		// We can't tell which object this is about, so we can't
+		// tell if it's a duplicate either.
+		return true
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s/%s/%s",
+		objMeta.GetNamespace(), objMeta.GetName(), objMeta.GetUID(),
+		eventtype, reason, message)
+	if _, ok := r.recent.Get(key); ok {
+		return false
+	}
+
+	r.recent.Add(key, struct{}{}, dedupInterval)
+
+	return true
+}
+
+type clock struct{}
+
+func (clock) Now() time.Time { return time.Now() }
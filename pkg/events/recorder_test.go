@@ -0,0 +1,99 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestRecorderVerbosity(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		expected  int
+	}{
+		{VerbosityAll, 2},
+		{VerbosityWarnings, 1},
+		{VerbosityNone, 0},
+	}
+
+	for _, tt := range tests {
+		fakeRecorder := record.NewFakeRecorder(10)
+		r := NewRecorder(fakeRecorder, tt.verbosity, 0)
+
+		obj := newObject("foo")
+		r.Event(obj, corev1.EventTypeNormal, StepAchieved, "step 1 achieved")
+		r.Event(obj, corev1.EventTypeWarning, StepAchieved, "step 1 failed")
+
+		if got := len(fakeRecorder.Events); got != tt.expected {
+			t.Errorf("expected %d events with verbosity %q, got %d", tt.expected, tt.verbosity, got)
+		}
+	}
+}
+
+func TestRecorderDropsDuplicateEvents(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	clock := &fakeClock{now: time.Now()}
+	r := newRecorderWithClock(fakeRecorder, VerbosityAll, time.Minute, clock)
+
+	foo, bar := newObject("foo"), newObject("bar")
+
+	r.Event(foo, corev1.EventTypeNormal, StepAchieved, "step 1 achieved")
+	r.Eventf(foo, corev1.EventTypeNormal, StepAchieved, "step %d achieved", 1)
+	r.Event(bar, corev1.EventTypeNormal, StepAchieved, "step 1 achieved")
+	r.Event(foo, corev1.EventTypeNormal, StepAchieved, "step 2 achieved")
+
+	if got := len(fakeRecorder.Events); got != 3 {
+		t.Fatalf("expected the duplicate event to be dropped, got %d events", got)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	r.Event(foo, corev1.EventTypeNormal, StepAchieved, "step 1 achieved")
+
+	if got := len(fakeRecorder.Events); got != 4 {
+		t.Fatalf("expected the event to be recorded again after the dedup interval, got %d events", got)
+	}
+}
+
+func TestRecorderConfigure(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := NewRecorder(fakeRecorder, VerbosityNone, 0)
+
+	obj := newObject("foo")
+	r.Event(obj, corev1.EventTypeWarning, StepAchieved, "step 1 failed")
+
+	r.Configure(VerbosityWarnings, 0)
+	r.Event(obj, corev1.EventTypeNormal, StepAchieved, "step 1 achieved")
+	r.Event(obj, corev1.EventTypeWarning, StepAchieved, "step 1 failed")
+
+	if got := len(fakeRecorder.Events); got != 1 {
+		t.Fatalf("expected only the warning recorded after configuring to be recorded, got %d events", got)
+	}
+}
+
+func TestParseVerbosity(t *testing.T) {
+	if v, err := ParseVerbosity("warnings"); err != nil || v != VerbosityWarnings {
+		t.Errorf("expected %q to parse, got %q, %v", "warnings", v, err)
+	}
+
+	if _, err := ParseVerbosity("loud"); err == nil {
+		t.Errorf("expected an error for an unknown verbosity")
+	}
+}
+
+func newObject(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-namespace",
+		},
+	}
+}
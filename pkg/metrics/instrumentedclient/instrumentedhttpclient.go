@@ -6,6 +6,7 @@
 package instrumentedclient
 
 import (
+	"crypto/tls"
 	"io"
 	"net"
 	"net/http"
@@ -89,6 +90,24 @@ func NewClient() *http.Client {
 	}
 }
 
+// NewTLSClient returns a new instrumented http.Client with the same timeouts
+// as DefaultClient, that uses tlsConfig for its connections.
+func NewTLSClient(tlsConfig *tls.Config) *http.Client {
+	transport := httpTransport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: promhttp.InstrumentRoundTripperCounter(
+			reqCounter,
+			promhttp.InstrumentRoundTripperDuration(
+				reqDuration,
+				instrumentRoundTripperTrace(transport),
+			),
+		),
+		Timeout: HTTPRequestResponseTimeout,
+	}
+}
+
 // Get issues a GET request using DefaultClient.
 func Get(url string) (*http.Response, error) {
 	return DefaultClient.Get(url)
@@ -0,0 +1,18 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// CapacityConvergenceDuration is how long capacity targets took to achieve
+// their capacity after their spec changed, per cluster.
+var CapacityConvergenceDuration = prom.NewHistogramVec(
+	prom.HistogramOpts{
+		Namespace: ns,
+		Subsystem: "capacity",
+		Name:      "convergence_duration_seconds",
+		Help:      "How long CapacityTargets took to achieve their capacity after their spec changed",
+		Buckets:   prom.ExponentialBuckets(1, 2, 12),
+	},
+	[]string{"cluster"},
+)
@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const chartSubsys = "chart_repo"
+
+var (
+	// ChartCacheLookups counts charts looked up in the chart cache, by
+	// whether they were found there, had to be downloaded, or were being
+	// downloaded already for someone else.
+	ChartCacheLookups = prom.NewCounterVec(
+		prom.CounterOpts{
+			Namespace: ns,
+			Subsystem: chartSubsys,
+			Name:      "cache_lookups_total",
+			Help:      "The number of charts looked up in the chart cache, by result: hit, miss or coalesced",
+		},
+		[]string{"result"},
+	)
+
+	// ChartRepoRequestsInFlight is how many requests are being made to
+	// chart repositories at the moment.
+	ChartRepoRequestsInFlight = prom.NewGauge(
+		prom.GaugeOpts{
+			Namespace: ns,
+			Subsystem: chartSubsys,
+			Name:      "requests_in_flight",
+			Help:      "The number of requests being made to chart repositories",
+		},
+	)
+
+	// ChartRepoRequestsWaiting is how many requests to chart repositories
+	// are waiting for others to finish, because too many are in flight.
+	ChartRepoRequestsWaiting = prom.NewGauge(
+		prom.GaugeOpts{
+			Namespace: ns,
+			Subsystem: chartSubsys,
+			Name:      "requests_waiting",
+			Help:      "The number of requests to chart repositories waiting for others to finish",
+		},
+	)
+)
@@ -1,7 +1,8 @@
 package prometheus
 
 const (
-	ns         = "shipper"
-	wqSubsys   = "workqueue"
-	restSubsys = "rest_client"
+	ns             = "shipper"
+	wqSubsys       = "workqueue"
+	restSubsys     = "rest_client"
+	informerSubsys = "informer"
 )
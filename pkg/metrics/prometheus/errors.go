@@ -0,0 +1,29 @@
+package prometheus
+
+import (
+	"strconv"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// SyncErrors counts errors returned by controllers while syncing objects,
+// labeled by the reason of the error and whether it will be retried.
+var SyncErrors = prom.NewCounterVec(
+	prom.CounterOpts{
+		Namespace: ns,
+		Name:      "sync_errors_total",
+		Help:      "The number of errors controllers ran into while syncing objects",
+	},
+	[]string{"controller", "reason", "retry"},
+)
+
+// ObserveSyncError records an error returned by a controller's sync handler.
+func ObserveSyncError(controller string, err error) {
+	SyncErrors.WithLabelValues(
+		controller,
+		shippererrors.Reason(err),
+		strconv.FormatBool(shippererrors.ShouldRetry(err)),
+	).Inc()
+}
@@ -0,0 +1,80 @@
+package prometheus
+
+import (
+	"net/http"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheSyncDuration is how long it took the informers of an informer
+// factory to fill their caches for the first time, per cluster.
+var CacheSyncDuration = prom.NewGaugeVec(
+	prom.GaugeOpts{
+		Namespace: ns,
+		Subsystem: informerSubsys,
+		Name:      "cache_sync_duration_seconds",
+		Help:      "How long it took informers to fill their caches after they were started",
+	},
+	[]string{"cluster", "factory"},
+)
+
+// InformerRelists counts full lists done by informers, per cluster and
+// resource. Besides the one on startup, informers list everything again
+// when their watch falls too far behind, which is expensive on both ends.
+var InformerRelists = prom.NewCounterVec(
+	prom.CounterOpts{
+		Namespace: ns,
+		Subsystem: informerSubsys,
+		Name:      "relists_total",
+		Help:      "How many times informers listed all objects of a resource",
+	},
+	[]string{"cluster", "gvr"},
+)
+
+// InformerWatchErrors counts list and watch requests done by informers that
+// failed, per cluster and resource.
+var InformerWatchErrors = prom.NewCounterVec(
+	prom.CounterOpts{
+		Namespace: ns,
+		Subsystem: informerSubsys,
+		Name:      "watch_errors_total",
+		Help:      "How many list and watch requests done by informers failed",
+	},
+	[]string{"cluster", "gvr"},
+)
+
+// ObserveCacheSync records how long it took an informer factory to fill its
+// caches, given when it was started.
+func ObserveCacheSync(cluster, factory string, start time.Time) {
+	CacheSyncDuration.WithLabelValues(cluster, factory).Set(time.Since(start).Seconds())
+}
+
+// InstrumentInformerTransport returns a transport wrapper for the REST
+// clients of a cluster's informers, which counts their relists and failed
+// requests. Informers only ever list and watch, so any request without
+// watch=true is a list.
+func InstrumentInformerTransport(cluster string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &informerRoundTripper{cluster: cluster, rt: rt}
+	}
+}
+
+type informerRoundTripper struct {
+	cluster string
+	rt      http.RoundTripper
+}
+
+func (t *informerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	gvr := extractGVR(req.URL.Path)
+	if req.URL.Query().Get("watch") != "true" {
+		InformerRelists.WithLabelValues(t.cluster, gvr).Inc()
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		InformerWatchErrors.WithLabelValues(t.cluster, gvr).Inc()
+	}
+
+	return resp, err
+}
@@ -0,0 +1,17 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// TracingSpansDropped counts spans that were never exported, either because
+// too many were waiting to be, or because the collector couldn't be reached.
+var TracingSpansDropped = prom.NewCounterVec(
+	prom.CounterOpts{
+		Namespace: ns,
+		Subsystem: "tracing",
+		Name:      "spans_dropped_total",
+		Help:      "The number of spans that could not be exported",
+	},
+	[]string{"reason"},
+)
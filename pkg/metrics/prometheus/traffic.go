@@ -0,0 +1,19 @@
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// TrafficConvergenceDuration is how long traffic targets took to achieve the
+// weight they were asked for, per cluster. The clock starts the first time a
+// target is seen not ready, and stops once it's ready again.
+var TrafficConvergenceDuration = prom.NewHistogramVec(
+	prom.HistogramOpts{
+		Namespace: ns,
+		Subsystem: "traffic",
+		Name:      "convergence_duration_seconds",
+		Help:      "How long TrafficTargets took to achieve their desired weight",
+		Buckets:   prom.ExponentialBuckets(1, 2, 12),
+	},
+	[]string{"cluster"},
+)
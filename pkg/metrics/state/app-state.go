@@ -1,11 +1,16 @@
 package state
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	kubelisters "k8s.io/client-go/listers/core/v1"
 	klog "k8s.io/klog"
 
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 )
 
 var (
@@ -29,6 +34,41 @@ var (
 		[]string{"namespace"},
 		nil,
 	)
+
+	capacityTargetsNotReadyDesc = prometheus.NewDesc(
+		"shipper_capacity_targets_not_ready",
+		"Number of CapacityTarget objects that aren't ready, by reason",
+		[]string{"namespace", "reason", "cluster"},
+		nil,
+	)
+
+	capacitySadPodsDesc = prometheus.NewDesc(
+		"shipper_capacity_sad_pods",
+		"Number of sad pods CapacityTarget objects report",
+		[]string{"namespace", "app", "release", "cluster"},
+		nil,
+	)
+
+	capacityNotReadyDurationDesc = prometheus.NewDesc(
+		"shipper_capacity_not_ready_duration_seconds",
+		"How long CapacityTarget objects have not been ready for",
+		[]string{"namespace", "app", "release", "cluster"},
+		nil,
+	)
+
+	trafficDesiredWeightDesc = prometheus.NewDesc(
+		"shipper_traffic_desired_weight",
+		"Traffic weight TrafficTarget objects ask for",
+		[]string{"namespace", "app", "release", "cluster"},
+		nil,
+	)
+
+	trafficAchievedWeightDesc = prometheus.NewDesc(
+		"shipper_traffic_achieved_weight",
+		"Traffic weight TrafficTarget objects have achieved",
+		[]string{"namespace", "app", "release", "cluster"},
+		nil,
+	)
 )
 
 type AppMetrics struct {
@@ -37,18 +77,29 @@ type AppMetrics struct {
 	TtsLister shipperlisters.TrafficTargetLister
 
 	NssLister kubelisters.NamespaceLister
+
+	// ClusterName labels metrics about objects in this cluster, so
+	// they can be told apart once aggregated across clusters.
+	ClusterName string
 }
 
 func (ssm AppMetrics) Collect(ch chan<- prometheus.Metric) {
 	ssm.collectInstallationTargets(ch)
 	ssm.collectCapacityTargets(ch)
 	ssm.collectTrafficTargets(ch)
+	ssm.collectCapacityReadiness(ch)
+	ssm.collectTrafficWeights(ch)
 }
 
 func (ssm AppMetrics) Describe(ch chan<- *prometheus.Desc) {
 	ch <- itsDesc
 	ch <- ctsDesc
 	ch <- ttsDesc
+	ch <- capacityTargetsNotReadyDesc
+	ch <- capacitySadPodsDesc
+	ch <- capacityNotReadyDurationDesc
+	ch <- trafficDesiredWeightDesc
+	ch <- trafficAchievedWeightDesc
 }
 
 func (ssm AppMetrics) collectInstallationTargets(ch chan<- prometheus.Metric) {
@@ -140,3 +191,69 @@ func (ssm AppMetrics) collectTrafficTargets(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(ttsDesc, prometheus.GaugeValue, n, ns.Name)
 	}
 }
+
+func (ssm AppMetrics) collectCapacityReadiness(ch chan<- prometheus.Metric) {
+	cts, err := ssm.CtsLister.List(everything)
+	if err != nil {
+		klog.Warningf("collect CapacityTargets: %s", err)
+		return
+	}
+
+	type nsReason struct {
+		namespace string
+		reason    string
+	}
+	notReady := make(map[nsReason]float64)
+
+	now := time.Now()
+	for _, ct := range cts {
+		labels := []string{
+			ct.Namespace,
+			ct.Labels[shipper.AppLabel],
+			ct.Labels[shipper.ReleaseLabel],
+			ssm.ClusterName,
+		}
+
+		ch <- prometheus.MustNewConstMetric(capacitySadPodsDesc, prometheus.GaugeValue,
+			float64(len(ct.Status.SadPods)), labels...)
+
+		readyCond := targetutil.GetTargetCondition(ct.Status.Conditions, shipper.TargetConditionTypeReady)
+		if readyCond == nil || readyCond.Status == corev1.ConditionTrue {
+			continue
+		}
+
+		notReady[nsReason{ct.Namespace, readyCond.Reason}]++
+
+		if !readyCond.LastTransitionTime.IsZero() {
+			ch <- prometheus.MustNewConstMetric(capacityNotReadyDurationDesc, prometheus.GaugeValue,
+				now.Sub(readyCond.LastTransitionTime.Time).Seconds(), labels...)
+		}
+	}
+
+	for k, n := range notReady {
+		ch <- prometheus.MustNewConstMetric(capacityTargetsNotReadyDesc, prometheus.GaugeValue,
+			n, k.namespace, k.reason, ssm.ClusterName)
+	}
+}
+
+func (ssm AppMetrics) collectTrafficWeights(ch chan<- prometheus.Metric) {
+	tts, err := ssm.TtsLister.List(everything)
+	if err != nil {
+		klog.Warningf("collect TrafficTargets: %s", err)
+		return
+	}
+
+	for _, tt := range tts {
+		labels := []string{
+			tt.Namespace,
+			tt.Labels[shipper.AppLabel],
+			tt.Labels[shipper.ReleaseLabel],
+			ssm.ClusterName,
+		}
+
+		ch <- prometheus.MustNewConstMetric(trafficDesiredWeightDesc, prometheus.GaugeValue,
+			float64(tt.Spec.Weight), labels...)
+		ch <- prometheus.MustNewConstMetric(trafficAchievedWeightDesc, prometheus.GaugeValue,
+			float64(tt.Status.AchievedTraffic), labels...)
+	}
+}
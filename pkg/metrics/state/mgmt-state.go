@@ -51,6 +51,13 @@ var (
 		nil,
 	)
 
+	inventoryDesc = prometheus.NewDesc(
+		fqn("inventory"),
+		"Traffic weight of the Releases serving traffic, per cluster",
+		[]string{"namespace", "shipper_app", "release", "chart_version", "cluster", "region"},
+		nil,
+	)
+
 	rolloutblocksDesc = prometheus.NewDesc(
 		fqn("rolloutblocks"),
 		"Number of RolloutBlock objects",
@@ -78,6 +85,7 @@ type MgmtMetrics struct {
 func (ssm MgmtMetrics) Collect(ch chan<- prometheus.Metric) {
 	ssm.collectApplications(ch)
 	ssm.collectReleases(ch)
+	ssm.collectInventory(ch)
 	ssm.collectClusters(ch)
 	ssm.collectRolloutBlocks(ch)
 }
@@ -85,6 +93,7 @@ func (ssm MgmtMetrics) Collect(ch chan<- prometheus.Metric) {
 func (ssm MgmtMetrics) Describe(ch chan<- *prometheus.Desc) {
 	ch <- appsDesc
 	ch <- relsDesc
+	ch <- inventoryDesc
 	ch <- clustersDesc
 	ch <- rolloutblocksDesc
 }
@@ -136,6 +145,7 @@ func (ssm MgmtMetrics) collectReleases(ch chan<- prometheus.Metric) {
 	conditions := []shipper.ReleaseConditionType{
 		shipper.ReleaseConditionTypeComplete,
 		shipper.ReleaseConditionTypeBlocked,
+		shipper.ReleaseConditionTypeQueued,
 		shipper.ReleaseConditionTypeClustersChosen,
 		shipper.ReleaseConditionTypeStrategyExecuted,
 	}
@@ -193,6 +203,25 @@ func (ssm MgmtMetrics) collectReleases(ch chan<- prometheus.Metric) {
 	}
 }
 
+func (ssm MgmtMetrics) collectInventory(ch chan<- prometheus.Metric) {
+	rels, err := ssm.RelsLister.List(everything)
+	if err != nil {
+		klog.Warningf("collect Releases: %s", err)
+		return
+	}
+
+	clusters, err := ssm.ClustersLister.List(everything)
+	if err != nil {
+		klog.Warningf("collect Clusters: %s", err)
+		return
+	}
+
+	for _, entry := range releaseutil.Inventory(rels, clusters) {
+		ch <- prometheus.MustNewConstMetric(inventoryDesc, prometheus.GaugeValue, float64(entry.Weight),
+			entry.Namespace, entry.Application, entry.Release, entry.ChartVersion, entry.Cluster, entry.Region)
+	}
+}
+
 func (ssm MgmtMetrics) collectClusters(ch chan<- prometheus.Metric) {
 	clusters, err := ssm.ClustersLister.List(everything)
 	if err != nil {
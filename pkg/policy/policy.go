@@ -0,0 +1,118 @@
+// Package policy evaluates releases against organization policies held by a
+// policy engine, keeping the engine itself out of Shipper.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// Evaluator evaluates a policy, returning a message for each of its
+// violations.
+type Evaluator interface {
+	Evaluate(policy *shipper.Policy, input Input) ([]string, error)
+}
+
+// Input is what a policy is evaluated against.
+type Input struct {
+	Stage   shipper.PolicyStage `json:"stage"`
+	Release *shipper.Release    `json:"release"`
+	// Clusters are the clusters the release is scheduled on.
+	Clusters []string `json:"clusters"`
+	// TargetStep is the step the release is moving to.
+	TargetStep int32 `json:"targetStep"`
+	// Manifests are the objects rendered from the release's chart, only
+	// set for the Installation stage.
+	Manifests []map[string]interface{} `json:"manifests,omitempty"`
+}
+
+// OPAEvaluator is an Evaluator querying the data API of an Open Policy Agent
+// server, which is left to load the policies from bundles.
+type OPAEvaluator struct {
+	url    string
+	client *http.Client
+}
+
+var _ Evaluator = (*OPAEvaluator)(nil)
+
+func NewOPAEvaluator(url string, timeout time.Duration) *OPAEvaluator {
+	return &OPAEvaluator{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Evaluate queries the rule at the policy's path with input. Rules can
+// either be deny rules, returning a list of violation messages, or objects
+// with a msg field as conftest and Gatekeeper have them, or allow rules,
+// returning a boolean. Undefined rules have no violations.
+func (e *OPAEvaluator) Evaluate(policy *shipper.Policy, input Input) ([]string, error) {
+	if policy.Spec.OPA == nil {
+		return nil, fmt.Errorf("policy has no OPA rule")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/v1/data/%s", e.url, strings.Trim(policy.Spec.OPA.Path, "/"))
+	resp, err := e.client.Post(u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, u)
+	}
+
+	var decision struct {
+		Result interface{} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return nil, err
+	}
+
+	return violations(policy.Spec.OPA.Path, decision.Result)
+}
+
+// violations returns the violation messages in the result of a rule.
+func violations(path string, result interface{}) ([]string, error) {
+	switch r := result.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		if r {
+			return nil, nil
+		}
+		return []string{fmt.Sprintf("denied by %s", path)}, nil
+	case []interface{}:
+		var msgs []string
+		for _, v := range r {
+			if msg, ok := v.(string); ok {
+				msgs = append(msgs, msg)
+				continue
+			}
+
+			if obj, ok := v.(map[string]interface{}); ok {
+				if msg, ok := obj["msg"].(string); ok {
+					msgs = append(msgs, msg)
+					continue
+				}
+			}
+
+			b, _ := json.Marshal(v)
+			msgs = append(msgs, string(b))
+		}
+		return msgs, nil
+	default:
+		return nil, fmt.Errorf("rule %s returned a %T, expected a list of violations or a boolean", path, result)
+	}
+}
@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// TestOPAEvaluator verifies that the results of both deny and allow rules
+// are turned into violations, and that rules get the input they're
+// evaluated against.
+func TestOPAEvaluator(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     string
+		violations []string
+	}{
+		{"undefined", `{}`, nil},
+		{"no violations", `{"result": []}`, nil},
+		{"violation messages", `{"result": ["no latest tags", "too many replicas"]}`, []string{"no latest tags", "too many replicas"}},
+		{"violation objects", `{"result": [{"msg": "no latest tags"}]}`, []string{"no latest tags"}},
+		{"allowed", `{"result": true}`, nil},
+		{"not allowed", `{"result": false}`, []string{"denied by shipper/deny"}},
+	}
+
+	for _, tt := range tests {
+		var stage interface{}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/data/shipper/deny" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			var body struct {
+				Input map[string]interface{} `json:"input"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			stage = body.Input["stage"]
+
+			fmt.Fprint(w, tt.result)
+		}))
+
+		policy := &shipper.Policy{
+			ObjectMeta: metav1.ObjectMeta{Name: "deny"},
+			Spec: shipper.PolicySpec{
+				OPA: &shipper.OPAPolicy{Path: "shipper/deny"},
+			},
+		}
+
+		evaluator := NewOPAEvaluator(srv.URL+"/", time.Second)
+		violations, err := evaluator.Evaluate(policy, Input{Stage: shipper.PolicyStageInstallation})
+		srv.Close()
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+
+		if stage != string(shipper.PolicyStageInstallation) {
+			t.Errorf("%s: expected rule to be evaluated with stage %q, got %v", tt.name, shipper.PolicyStageInstallation, stage)
+		}
+
+		if !reflect.DeepEqual(violations, tt.violations) {
+			t.Errorf("%s: expected violations %q, got %q", tt.name, tt.violations, violations)
+		}
+	}
+}
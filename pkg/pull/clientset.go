@@ -0,0 +1,338 @@
+// Package pull lets clusters in pull mode get their target objects from
+// the management cluster instead of Shipper writing them into the cluster.
+//
+// Shipper keeps a copy of every installation, capacity and traffic target
+// meant for a cluster in pull mode in the management cluster, and an agent
+// running in the cluster syncs them with the ones in it, reporting their
+// status back. Both sides see these copies through the clientset returned
+// by NewClientset, which makes them look just like the targets in the
+// cluster itself.
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperclientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
+	shipperv1alpha1 "github.com/bookingcom/shipper/pkg/client/clientset/versioned/typed/shipper/v1alpha1"
+)
+
+// MirrorName returns the name of the copy in the management cluster of the
+// target called name in cluster. Copies are named after their clusters, as
+// they all live in the namespace of their release.
+func MirrorName(name, cluster string) string {
+	return fmt.Sprintf("%s.%s", name, cluster)
+}
+
+// Selector selects the copies of the targets meant for cluster.
+func Selector(cluster string) labels.Selector {
+	return labels.Set{shipper.PullClusterLabel: cluster}.AsSelector()
+}
+
+// NewClientset returns a clientset that manages the copies of the targets
+// meant for cluster through mgmt, a clientset for the management cluster,
+// as if they were the targets in cluster. Everything else is left to mgmt.
+func NewClientset(mgmt shipperclientset.Interface, cluster string) shipperclientset.Interface {
+	return &clientset{Interface: mgmt, cluster: cluster}
+}
+
+type clientset struct {
+	shipperclientset.Interface
+	cluster string
+}
+
+func (c *clientset) ShipperV1alpha1() shipperv1alpha1.ShipperV1alpha1Interface {
+	return &shipperV1alpha1{
+		ShipperV1alpha1Interface: c.Interface.ShipperV1alpha1(),
+		cluster:                  c.cluster,
+	}
+}
+
+type shipperV1alpha1 struct {
+	shipperv1alpha1.ShipperV1alpha1Interface
+	cluster string
+}
+
+func (c *shipperV1alpha1) InstallationTargets(namespace string) shipperv1alpha1.InstallationTargetInterface {
+	return &installationTargets{
+		client:  c.ShipperV1alpha1Interface.InstallationTargets(namespace),
+		mirrors: mirrors(c.cluster),
+	}
+}
+
+func (c *shipperV1alpha1) CapacityTargets(namespace string) shipperv1alpha1.CapacityTargetInterface {
+	return &capacityTargets{
+		client:  c.ShipperV1alpha1Interface.CapacityTargets(namespace),
+		mirrors: mirrors(c.cluster),
+	}
+}
+
+func (c *shipperV1alpha1) TrafficTargets(namespace string) shipperv1alpha1.TrafficTargetInterface {
+	return &trafficTargets{
+		client:  c.ShipperV1alpha1Interface.TrafficTargets(namespace),
+		mirrors: mirrors(c.cluster),
+	}
+}
+
+// mirrors translates between targets and their copies in the management
+// cluster.
+type mirrors string
+
+func (m mirrors) name(name string) string {
+	return MirrorName(name, string(m))
+}
+
+// toMirror makes meta, which must already be a copy, the metadata of the
+// copy of a target.
+func (m mirrors) toMirror(meta *metav1.ObjectMeta) {
+	meta.Name = m.name(meta.Name)
+
+	labels := make(map[string]string, len(meta.Labels)+1)
+	for k, v := range meta.Labels {
+		labels[k] = v
+	}
+	labels[shipper.PullClusterLabel] = string(m)
+	meta.Labels = labels
+}
+
+// fromMirror makes meta the metadata of the target a copy is of.
+func (m mirrors) fromMirror(meta *metav1.ObjectMeta) {
+	meta.Name = strings.TrimSuffix(meta.Name, "."+string(m))
+	delete(meta.Labels, shipper.PullClusterLabel)
+}
+
+func (m mirrors) listOptions(opts metav1.ListOptions) metav1.ListOptions {
+	selector := Selector(string(m)).String()
+	if opts.LabelSelector != "" {
+		selector = fmt.Sprintf("%s,%s", opts.LabelSelector, selector)
+	}
+	opts.LabelSelector = selector
+	return opts
+}
+
+// watch turns the events for copies in w into events for the targets they
+// are of.
+func (m mirrors) watch(w watch.Interface, err error) (watch.Interface, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+		if obj, ok := e.Object.(metav1.Object); ok && e.Type != watch.Error {
+			if obj.GetLabels()[shipper.PullClusterLabel] != string(m) {
+				return e, false
+			}
+
+			e.Object = e.Object.DeepCopyObject()
+			meta := e.Object.(metav1.Object)
+			meta.SetName(strings.TrimSuffix(obj.GetName(), "."+string(m)))
+
+			labels := meta.GetLabels()
+			delete(labels, shipper.PullClusterLabel)
+			meta.SetLabels(labels)
+		}
+
+		return e, true
+	}), nil
+}
+
+type installationTargets struct {
+	client shipperv1alpha1.InstallationTargetInterface
+	mirrors
+}
+
+func (c *installationTargets) result(it *shipper.InstallationTarget, err error) (*shipper.InstallationTarget, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	c.fromMirror(&it.ObjectMeta)
+	return it, nil
+}
+
+func (c *installationTargets) mirror(it *shipper.InstallationTarget) *shipper.InstallationTarget {
+	it = it.DeepCopy()
+	c.toMirror(&it.ObjectMeta)
+	return it
+}
+
+func (c *installationTargets) Create(it *shipper.InstallationTarget) (*shipper.InstallationTarget, error) {
+	return c.result(c.client.Create(c.mirror(it)))
+}
+
+func (c *installationTargets) Update(it *shipper.InstallationTarget) (*shipper.InstallationTarget, error) {
+	return c.result(c.client.Update(c.mirror(it)))
+}
+
+func (c *installationTargets) UpdateStatus(it *shipper.InstallationTarget) (*shipper.InstallationTarget, error) {
+	return c.result(c.client.UpdateStatus(c.mirror(it)))
+}
+
+func (c *installationTargets) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete(c.name(name), options)
+}
+
+func (c *installationTargets) DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	return c.client.DeleteCollection(options, c.listOptions(listOptions))
+}
+
+func (c *installationTargets) Get(name string, options metav1.GetOptions) (*shipper.InstallationTarget, error) {
+	return c.result(c.client.Get(c.name(name), options))
+}
+
+func (c *installationTargets) List(opts metav1.ListOptions) (*shipper.InstallationTargetList, error) {
+	list, err := c.client.List(c.listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		c.fromMirror(&list.Items[i].ObjectMeta)
+	}
+
+	return list, nil
+}
+
+func (c *installationTargets) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(c.client.Watch(c.listOptions(opts)))
+}
+
+func (c *installationTargets) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*shipper.InstallationTarget, error) {
+	return c.result(c.client.Patch(c.name(name), pt, data, subresources...))
+}
+
+type capacityTargets struct {
+	client shipperv1alpha1.CapacityTargetInterface
+	mirrors
+}
+
+func (c *capacityTargets) result(ct *shipper.CapacityTarget, err error) (*shipper.CapacityTarget, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	c.fromMirror(&ct.ObjectMeta)
+	return ct, nil
+}
+
+func (c *capacityTargets) mirror(ct *shipper.CapacityTarget) *shipper.CapacityTarget {
+	ct = ct.DeepCopy()
+	c.toMirror(&ct.ObjectMeta)
+	return ct
+}
+
+func (c *capacityTargets) Create(ct *shipper.CapacityTarget) (*shipper.CapacityTarget, error) {
+	return c.result(c.client.Create(c.mirror(ct)))
+}
+
+func (c *capacityTargets) Update(ct *shipper.CapacityTarget) (*shipper.CapacityTarget, error) {
+	return c.result(c.client.Update(c.mirror(ct)))
+}
+
+func (c *capacityTargets) UpdateStatus(ct *shipper.CapacityTarget) (*shipper.CapacityTarget, error) {
+	return c.result(c.client.UpdateStatus(c.mirror(ct)))
+}
+
+func (c *capacityTargets) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete(c.name(name), options)
+}
+
+func (c *capacityTargets) DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	return c.client.DeleteCollection(options, c.listOptions(listOptions))
+}
+
+func (c *capacityTargets) Get(name string, options metav1.GetOptions) (*shipper.CapacityTarget, error) {
+	return c.result(c.client.Get(c.name(name), options))
+}
+
+func (c *capacityTargets) List(opts metav1.ListOptions) (*shipper.CapacityTargetList, error) {
+	list, err := c.client.List(c.listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		c.fromMirror(&list.Items[i].ObjectMeta)
+	}
+
+	return list, nil
+}
+
+func (c *capacityTargets) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(c.client.Watch(c.listOptions(opts)))
+}
+
+func (c *capacityTargets) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*shipper.CapacityTarget, error) {
+	return c.result(c.client.Patch(c.name(name), pt, data, subresources...))
+}
+
+type trafficTargets struct {
+	client shipperv1alpha1.TrafficTargetInterface
+	mirrors
+}
+
+func (c *trafficTargets) result(tt *shipper.TrafficTarget, err error) (*shipper.TrafficTarget, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	c.fromMirror(&tt.ObjectMeta)
+	return tt, nil
+}
+
+func (c *trafficTargets) mirror(tt *shipper.TrafficTarget) *shipper.TrafficTarget {
+	tt = tt.DeepCopy()
+	c.toMirror(&tt.ObjectMeta)
+	return tt
+}
+
+func (c *trafficTargets) Create(tt *shipper.TrafficTarget) (*shipper.TrafficTarget, error) {
+	return c.result(c.client.Create(c.mirror(tt)))
+}
+
+func (c *trafficTargets) Update(tt *shipper.TrafficTarget) (*shipper.TrafficTarget, error) {
+	return c.result(c.client.Update(c.mirror(tt)))
+}
+
+func (c *trafficTargets) UpdateStatus(tt *shipper.TrafficTarget) (*shipper.TrafficTarget, error) {
+	return c.result(c.client.UpdateStatus(c.mirror(tt)))
+}
+
+func (c *trafficTargets) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete(c.name(name), options)
+}
+
+func (c *trafficTargets) DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error {
+	return c.client.DeleteCollection(options, c.listOptions(listOptions))
+}
+
+func (c *trafficTargets) Get(name string, options metav1.GetOptions) (*shipper.TrafficTarget, error) {
+	return c.result(c.client.Get(c.name(name), options))
+}
+
+func (c *trafficTargets) List(opts metav1.ListOptions) (*shipper.TrafficTargetList, error) {
+	list, err := c.client.List(c.listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		c.fromMirror(&list.Items[i].ObjectMeta)
+	}
+
+	return list, nil
+}
+
+func (c *trafficTargets) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.watch(c.client.Watch(c.listOptions(opts)))
+}
+
+func (c *trafficTargets) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*shipper.TrafficTarget, error) {
+	return c.result(c.client.Patch(c.name(name), pt, data, subresources...))
+}
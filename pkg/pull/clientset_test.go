@@ -0,0 +1,89 @@
+package pull
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+)
+
+const testNamespace = "test-namespace"
+
+// TestClientsetMirrorsTargets verifies that targets go through the
+// clientset as copies named and labelled after their cluster, and that
+// clusters only get to see their own.
+func TestClientsetMirrorsTargets(t *testing.T) {
+	mgmt := shipperfake.NewSimpleClientset()
+	clientset := NewClientset(mgmt, "cluster-a")
+	other := NewClientset(mgmt, "cluster-b")
+
+	ct := &shipper.CapacityTarget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "release",
+			Namespace: testNamespace,
+			Labels:    map[string]string{shipper.ReleaseLabel: "release"},
+		},
+		Spec: shipper.CapacityTargetSpec{Percent: 50},
+	}
+
+	created, err := clientset.ShipperV1alpha1().CapacityTargets(ct.Namespace).Create(ct)
+	if err != nil {
+		t.Fatalf("unexpected error creating capacity target: %s", err)
+	}
+
+	if created.Name != ct.Name {
+		t.Errorf("expected capacity target to be named %q, got %q", ct.Name, created.Name)
+	}
+
+	if _, ok := ct.Labels[shipper.PullClusterLabel]; ok {
+		t.Errorf("expected capacity target passed to Create not to be modified")
+	}
+
+	mirror, err := mgmt.ShipperV1alpha1().CapacityTargets(ct.Namespace).Get("release.cluster-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected copy of capacity target in management cluster: %s", err)
+	}
+
+	if cluster := mirror.Labels[shipper.PullClusterLabel]; cluster != "cluster-a" {
+		t.Errorf("expected copy of capacity target to be labelled with cluster %q, got %q", "cluster-a", cluster)
+	}
+
+	got, err := clientset.ShipperV1alpha1().CapacityTargets(ct.Namespace).Get(ct.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error getting capacity target: %s", err)
+	}
+
+	if _, ok := got.Labels[shipper.PullClusterLabel]; ok || got.Name != ct.Name {
+		t.Errorf("expected capacity target %q without cluster label, got %q with labels %v", ct.Name, got.Name, got.Labels)
+	}
+
+	list, err := clientset.ShipperV1alpha1().CapacityTargets(ct.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error listing capacity targets: %s", err)
+	}
+
+	if len(list.Items) != 1 || list.Items[0].Name != ct.Name {
+		t.Errorf("expected to list capacity target %q, got %v", ct.Name, list.Items)
+	}
+
+	list, err = other.ShipperV1alpha1().CapacityTargets(ct.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error listing capacity targets: %s", err)
+	}
+
+	if len(list.Items) != 0 {
+		t.Errorf("expected no capacity targets for another cluster, got %v", list.Items)
+	}
+
+	err = clientset.ShipperV1alpha1().CapacityTargets(ct.Namespace).Delete(ct.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error deleting capacity target: %s", err)
+	}
+
+	_, err = mgmt.ShipperV1alpha1().CapacityTargets(ct.Namespace).Get("release.cluster-a", metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("expected copy of capacity target to be deleted from management cluster")
+	}
+}
@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// ImageVerifier tells whether an image can be trusted.
+type ImageVerifier interface {
+	Verify(image string) error
+}
+
+// CosignVerifier is an ImageVerifier accepting images signed with cosign by
+// a given ECDSA key, with the signatures stored next to the image in its
+// repository, as cosign does by default.
+type CosignVerifier struct {
+	inspector *HTTPInspector
+	key       *ecdsa.PublicKey
+
+	// verified holds when images were last verified, so they're not
+	// looked up in their registry on every sync.
+	verifiedMutex sync.Mutex
+	verified      map[string]time.Time
+	cacheTTL      time.Duration
+}
+
+var _ ImageVerifier = (*CosignVerifier)(nil)
+
+// NewCosignVerifier returns a CosignVerifier for the PEM encoded public key
+// in pemKey. Images verified successfully aren't verified again for
+// cacheTTL.
+func NewCosignVerifier(pemKey []byte, timeout, cacheTTL time.Duration) (*CosignVerifier, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in cosign public key")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("cosign public key is a %T, not an ECDSA key", pub)
+	}
+
+	return &CosignVerifier{
+		inspector: NewHTTPInspector(timeout),
+		key:       key,
+		verified:  make(map[string]time.Time),
+		cacheTTL:  cacheTTL,
+	}, nil
+}
+
+type cosignManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+type cosignPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// Verify returns an error unless one of the cosign signatures of image was
+// made by the verifier's key, for the manifest image currently points to.
+func (v *CosignVerifier) Verify(image string) error {
+	v.verifiedMutex.Lock()
+	at, ok := v.verified[image]
+	v.verifiedMutex.Unlock()
+	if ok && time.Since(at) < v.cacheTTL {
+		return nil
+	}
+
+	ref, err := parseReference(image)
+	if err != nil {
+		return err
+	}
+
+	digest := ref.ref
+	if !strings.HasPrefix(digest, "sha256:") {
+		body, header, err := v.inspector.fetch(ref, "manifests/"+ref.ref, manifestMediaTypes)
+		if err != nil {
+			return err
+		}
+
+		digest = header.Get("Docker-Content-Digest")
+		if digest == "" {
+			sum := sha256.Sum256(body)
+			digest = "sha256:" + hex.EncodeToString(sum[:])
+		}
+	}
+
+	var m cosignManifest
+	sigTag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	if err := v.inspector.get(ref, "manifests/"+sigTag, mediaTypeOCIManifest, &m); err != nil {
+		return fmt.Errorf("no cosign signature found for %s: %s", digest, err)
+	}
+
+	for _, layer := range m.Layers {
+		sig, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+
+		payload, _, err := v.inspector.fetch(ref, "blobs/"+layer.Digest, "*/*")
+		if err != nil {
+			return err
+		}
+
+		if v.verifySignature(payload, layer.Digest, sig, digest) {
+			v.verifiedMutex.Lock()
+			v.verified[image] = time.Now()
+			v.verifiedMutex.Unlock()
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no cosign signature of %s was made by the trusted key", digest)
+}
+
+// verifySignature returns whether sig is the verifier's signature of
+// payload, and payload vouches for the manifest with digest.
+func (v *CosignVerifier) verifySignature(payload []byte, payloadDigest, sig, digest string) bool {
+	sum := sha256.Sum256(payload)
+	if payloadDigest != "sha256:"+hex.EncodeToString(sum[:]) {
+		return false
+	}
+
+	der, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	var rs struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &rs); err != nil || len(rest) > 0 {
+		return false
+	}
+
+	if !ecdsa.Verify(v.key, sum[:], rs.R, rs.S) {
+		return false
+	}
+
+	var p cosignPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return false
+	}
+
+	return p.Critical.Image.DockerManifestDigest == digest
+}
@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const imageDigest = "sha256:0123456789abcdef"
+
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, digest string) ([]byte, string, string) {
+	payload := []byte(fmt.Sprintf(
+		`{"critical": {"image": {"docker-manifest-digest": %q}, "type": "cosign container image signature"}}`,
+		digest))
+	sum := sha256.Sum256(payload)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatalf("could not sign payload: %s", err)
+	}
+
+	return payload, "sha256:" + hex.EncodeToString(sum[:]), base64.StdEncoding.EncodeToString(sig)
+}
+
+func publicKeyPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("could not marshal public key: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// TestCosignVerifier verifies that only images with a signature made by the
+// trusted key, for the manifest they point to, are accepted.
+func TestCosignVerifier(t *testing.T) {
+	trusted, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	untrusted, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	tests := []struct {
+		name     string
+		key      *ecdsa.PrivateKey
+		digest   string
+		verified bool
+	}{
+		{"signed by trusted key", trusted, imageDigest, true},
+		{"signed by untrusted key", untrusted, imageDigest, false},
+		{"signature for another manifest", trusted, "sha256:fedcba9876543210", false},
+	}
+
+	for _, tt := range tests {
+		payload, payloadDigest, sig := signPayload(t, tt.key, tt.digest)
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/app/manifests/v1":
+				w.Header().Set("Docker-Content-Digest", imageDigest)
+				fmt.Fprintf(w, `{"mediaType": %q}`, mediaTypeDockerManifest)
+			case "/v2/app/manifests/" + strings.Replace(imageDigest, ":", "-", 1) + ".sig":
+				fmt.Fprintf(w, `{"layers": [{"digest": %q, "annotations": {%q: %q}}]}`,
+					payloadDigest, cosignSignatureAnnotation, sig)
+			case "/v2/app/blobs/" + payloadDigest:
+				w.Write(payload)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		verifier, err := NewCosignVerifier(publicKeyPEM(t, trusted), time.Second, time.Minute)
+		if err != nil {
+			t.Fatalf("%s: could not build verifier: %s", tt.name, err)
+		}
+		verifier.inspector.client = srv.Client()
+
+		image := strings.TrimPrefix(srv.URL, "https://") + "/app:v1"
+		err = verifier.Verify(image)
+		if tt.verified && err != nil {
+			t.Errorf("%s: expected image to be verified, got error: %s", tt.name, err)
+		} else if !tt.verified && err == nil {
+			t.Errorf("%s: expected image not to be verified", tt.name)
+		}
+
+		srv.Close()
+	}
+}
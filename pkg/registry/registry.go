@@ -0,0 +1,308 @@
+// Package registry inspects images in container registries through the
+// Docker Registry HTTP API V2, which is also served by OCI registries.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var manifestMediaTypes = strings.Join([]string{
+	mediaTypeDockerManifestList,
+	mediaTypeOCIIndex,
+	mediaTypeDockerManifest,
+	mediaTypeOCIManifest,
+}, ", ")
+
+// PlatformInspector tells which platforms, as "<os>/<arch>", an image has
+// manifests for.
+type PlatformInspector interface {
+	Platforms(image string) ([]string, error)
+}
+
+// HTTPInspector is a PlatformInspector talking to registries over HTTPS. It
+// only knows how to authenticate anonymously, with the bearer tokens public
+// registries hand out for pulls.
+type HTTPInspector struct {
+	client *http.Client
+}
+
+var _ PlatformInspector = (*HTTPInspector)(nil)
+
+func NewHTTPInspector(timeout time.Duration) *HTTPInspector {
+	return &HTTPInspector{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type reference struct {
+	registry   string
+	repository string
+	// tag or digest
+	ref string
+}
+
+// parseReference splits an image into the registry it lives in, its
+// repository and its tag or digest, following the rules of the docker CLI:
+// images without a registry live in Docker Hub, and images without a tag are
+// tagged latest.
+func parseReference(image string) (reference, error) {
+	if image == "" {
+		return reference{}, fmt.Errorf("empty image reference")
+	}
+
+	name, ref := image, "latest"
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref = name[:i], name[i+1:]
+	}
+
+	registry := dockerHubRegistry
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, name = host, name[i+1:]
+		}
+	}
+
+	if registry == dockerHubRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	if name == "" || ref == "" {
+		return reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	return reference{registry: registry, repository: name, ref: ref}, nil
+}
+
+// Repository returns the repository of image, prefixed by the registry it
+// lives in, such as "gcr.io/project/app". Images in Docker Hub are prefixed
+// by "docker.io".
+func Repository(image string) (string, error) {
+	ref, err := parseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	registry := ref.registry
+	if registry == dockerHubRegistry {
+		registry = "docker.io"
+	}
+
+	return registry + "/" + ref.repository, nil
+}
+
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+func (p platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	// Set in manifest lists and image indexes.
+	Manifests []struct {
+		Platform *platform `json:"platform"`
+	} `json:"manifests"`
+	// Set in image manifests.
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// Platforms returns the platforms image has manifests for, sorted. Images
+// with a single manifest are looked up in their config, as the manifest
+// doesn't say.
+func (i *HTTPInspector) Platforms(image string) ([]string, error) {
+	ref, err := parseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	err = i.get(ref, "manifests/"+ref.ref, manifestMediaTypes, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	var platforms []string
+	switch m.MediaType {
+	case mediaTypeDockerManifestList, mediaTypeOCIIndex:
+		seen := map[string]struct{}{}
+		for _, entry := range m.Manifests {
+			if entry.Platform == nil {
+				// Attestations and other artifacts, not
+				// runnable images.
+				continue
+			}
+
+			p := entry.Platform.String()
+			if _, ok := seen[p]; !ok {
+				seen[p] = struct{}{}
+				platforms = append(platforms, p)
+			}
+		}
+	default:
+		if m.Config.Digest == "" {
+			return nil, fmt.Errorf("manifest of %q has no config", image)
+		}
+
+		var config platform
+		err := i.get(ref, "blobs/"+m.Config.Digest, "*/*", &config)
+		if err != nil {
+			return nil, err
+		}
+
+		platforms = []string{config.String()}
+	}
+
+	sort.Strings(platforms)
+
+	return platforms, nil
+}
+
+// get decodes the JSON document at path in ref's repository into out.
+func (i *HTTPInspector) get(ref reference, path, accept string, out interface{}) error {
+	body, _, err := i.fetch(ref, path, accept)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// fetch returns the document at path in ref's repository along with the
+// headers it was served with, fetching a token first if the registry asks
+// for one.
+func (i *HTTPInspector) fetch(ref reference, path, accept string) ([]byte, http.Header, error) {
+	u := fmt.Sprintf("https://%s/v2/%s/%s", ref.registry, ref.repository, path)
+
+	resp, err := i.do(u, accept, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("Www-Authenticate")
+		drain(resp.Body)
+
+		token, err := i.token(challenge)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err = i.do(u, accept, token)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	defer drain(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, u)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, resp.Header, nil
+}
+
+func (i *HTTPInspector) do(u, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", accept)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return i.client.Do(req)
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// token fetches an anonymous bearer token as asked by a Www-Authenticate
+// challenge.
+func (i *HTTPInspector) token(challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	realm, ok := params["realm"]
+	if !ok {
+		return "", fmt.Errorf("registry authentication challenge %q has no realm", challenge)
+	}
+
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if value, ok := params[key]; ok {
+			query.Set(key, value)
+		}
+	}
+
+	u := realm
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	resp, err := i.do(u, "application/json", "")
+	if err != nil {
+		return "", err
+	}
+	defer drain(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, realm)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+
+	return body.AccessToken, nil
+}
+
+func drain(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
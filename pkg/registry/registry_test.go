@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected reference
+	}{
+		{"nginx", reference{dockerHubRegistry, "library/nginx", "latest"}},
+		{"bitnami/nginx:1.19", reference{dockerHubRegistry, "bitnami/nginx", "1.19"}},
+		{"localhost:5000/app", reference{"localhost:5000", "app", "latest"}},
+		{"gcr.io/project/app:v1", reference{"gcr.io", "project/app", "v1"}},
+		{"gcr.io/project/app@sha256:abc", reference{"gcr.io", "project/app", "sha256:abc"}},
+	}
+
+	for _, tt := range tests {
+		ref, err := parseReference(tt.image)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.image, err)
+			continue
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, ref)
+		if !eq {
+			t.Errorf("%s: unexpected reference:\n%s", tt.image, diff)
+		}
+	}
+}
+
+func TestHTTPInspectorPlatforms(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:app:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret"}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("Www-Authenticate", fmt.Sprintf(
+				`Bearer realm="%s/token",service="registry",scope="repository:app:pull"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/app/manifests/multi":
+			fmt.Fprintf(w, `{"mediaType": %q, "manifests": [
+				{"platform": {"os": "linux", "architecture": "arm64"}},
+				{"platform": {"os": "linux", "architecture": "amd64"}},
+				{"annotations": {"vnd.docker.reference.type": "attestation-manifest"}}
+			]}`, mediaTypeOCIIndex)
+		case "/v2/app/manifests/single":
+			fmt.Fprintf(w, `{"mediaType": %q, "config": {"digest": "sha256:config"}}`, mediaTypeDockerManifest)
+		case "/v2/app/blobs/sha256:config":
+			fmt.Fprint(w, `{"os": "windows", "architecture": "amd64"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	inspector := &HTTPInspector{client: srv.Client()}
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{"multi", []string{"linux/amd64", "linux/arm64"}},
+		{"single", []string{"windows/amd64"}},
+	}
+
+	for _, tt := range tests {
+		platforms, err := inspector.Platforms(fmt.Sprintf("%s/app:%s", host, tt.tag))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tt.tag, err)
+		}
+
+		eq, diff := shippertesting.DeepEqualDiff(tt.expected, platforms)
+		if !eq {
+			t.Errorf("%s: unexpected platforms:\n%s", tt.tag, diff)
+		}
+	}
+
+	if _, err := inspector.Platforms(host + "/app:missing"); err == nil {
+		t.Fatal("expected error inspecting missing image, got none")
+	}
+}
@@ -0,0 +1,172 @@
+// Package shipperconfig keeps the configuration of the management
+// controllers in sync with their ShipperConfig, so that changing it takes
+// effect without restarting them.
+package shipperconfig
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+)
+
+// Config is the configuration controllers run with: the flags they were
+// started with, overridden by whatever their ShipperConfig sets.
+type Config struct {
+	EventVerbosity     shipperevents.Verbosity
+	EventDedupInterval time.Duration
+	// DefaultStrategy is shared by every caller of Get, and must not be
+	// modified.
+	DefaultStrategy      *shipper.RolloutStrategy
+	GitOpsInterval       time.Duration
+	ChartRepoMaxRequests int
+	CompletionWebhookURL string
+}
+
+// Store holds the current Config, and tells listeners about every change
+// to it.
+type Store struct {
+	defaults Config
+
+	mut       sync.RWMutex
+	current   Config
+	listeners []func(Config)
+}
+
+// NewStore returns a Store that starts out with defaults, and follows the
+// ShipperConfig named shipper.ShipperConfigName through informerFactory
+// once it's started. Deleting the ShipperConfig brings defaults back.
+func NewStore(defaults Config, informerFactory shipperinformers.SharedInformerFactory) *Store {
+	s := &Store{
+		defaults: defaults,
+		current:  defaults,
+	}
+
+	informer := informerFactory.Shipper().V1alpha1().ShipperConfigs().Informer()
+	informer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isShipperConfig,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				s.update(obj.(*shipper.ShipperConfig))
+			},
+			UpdateFunc: func(_, new interface{}) {
+				s.update(new.(*shipper.ShipperConfig))
+			},
+			DeleteFunc: func(obj interface{}) {
+				s.update(nil)
+			},
+		},
+	})
+
+	return s
+}
+
+// Get returns the current Config.
+func (s *Store) Get() Config {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	return s.current
+}
+
+// AddListener makes the Store call listener with the new Config every time
+// it changes.
+func (s *Store) AddListener(listener func(Config)) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *Store) update(config *shipper.ShipperConfig) {
+	var spec *shipper.ShipperConfigSpec
+	if config != nil {
+		spec = &config.Spec
+	}
+
+	current, err := Apply(s.defaults, spec)
+	if err != nil {
+		klog.Errorf("Ignoring ShipperConfig %q: %s", shipper.ShipperConfigName, err)
+		return
+	}
+
+	s.mut.Lock()
+	s.current = current
+	listeners := s.listeners
+	s.mut.Unlock()
+
+	klog.V(2).Infof("Controllers are now configured with ShipperConfig %q", shipper.ShipperConfigName)
+
+	for _, listener := range listeners {
+		listener(current)
+	}
+}
+
+// Apply returns defaults with the fields spec sets overridden. A nil spec
+// returns defaults as they are.
+func Apply(defaults Config, spec *shipper.ShipperConfigSpec) (Config, error) {
+	config := defaults
+	if spec == nil {
+		return config, nil
+	}
+
+	if events := spec.Events; events != nil {
+		if events.Verbosity != "" {
+			verbosity, err := shipperevents.ParseVerbosity(events.Verbosity)
+			if err != nil {
+				return Config{}, err
+			}
+			config.EventVerbosity = verbosity
+		}
+
+		if events.DedupInterval != nil {
+			config.EventDedupInterval = events.DedupInterval.Duration
+		}
+	}
+
+	if spec.DefaultStrategy != nil {
+		config.DefaultStrategy = spec.DefaultStrategy.DeepCopy()
+	}
+
+	if spec.GitOpsInterval != nil {
+		if spec.GitOpsInterval.Duration <= 0 {
+			return Config{}, fmt.Errorf("gitopsInterval must be positive, got %s", spec.GitOpsInterval.Duration)
+		}
+		config.GitOpsInterval = spec.GitOpsInterval.Duration
+	}
+
+	if repos := spec.ChartRepositories; repos != nil && repos.MaxConcurrentRequests != nil {
+		if *repos.MaxConcurrentRequests < 0 {
+			return Config{}, fmt.Errorf("chartRepositories.maxConcurrentRequests can't be negative, got %d", *repos.MaxConcurrentRequests)
+		}
+		config.ChartRepoMaxRequests = int(*repos.MaxConcurrentRequests)
+	}
+
+	if notifications := spec.Notifications; notifications != nil && notifications.CompletionWebhookURL != nil {
+		webhookURL := *notifications.CompletionWebhookURL
+		if webhookURL != "" {
+			u, err := url.Parse(webhookURL)
+			if err != nil {
+				return Config{}, fmt.Errorf("notifications.completionWebhookURL is invalid: %s", err)
+			}
+			if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return Config{}, fmt.Errorf("notifications.completionWebhookURL must be an http or https URL, got %q", webhookURL)
+			}
+		}
+		config.CompletionWebhookURL = webhookURL
+	}
+
+	return config, nil
+}
+
+func isShipperConfig(obj interface{}) bool {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	return err == nil && key == shipper.ShipperConfigName
+}
@@ -0,0 +1,256 @@
+package shipperconfig
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
+	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
+)
+
+var defaults = Config{
+	EventVerbosity:       shipperevents.VerbosityAll,
+	EventDedupInterval:   shipperevents.DefaultDedupInterval,
+	GitOpsInterval:       time.Minute,
+	ChartRepoMaxRequests: 10,
+}
+
+func pint32(i int32) *int32 {
+	return &i
+}
+
+func pstring(s string) *string {
+	return &s
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults *Config
+		spec     *shipper.ShipperConfigSpec
+		expected Config
+		err      bool
+	}{
+		{
+			name:     "no config",
+			spec:     nil,
+			expected: defaults,
+		},
+		{
+			name: "events",
+			spec: &shipper.ShipperConfigSpec{
+				Events: &shipper.EventsConfig{
+					Verbosity:     "warnings",
+					DedupInterval: &metav1.Duration{Duration: time.Hour},
+				},
+			},
+			expected: Config{
+				EventVerbosity:       shipperevents.VerbosityWarnings,
+				EventDedupInterval:   time.Hour,
+				GitOpsInterval:       defaults.GitOpsInterval,
+				ChartRepoMaxRequests: defaults.ChartRepoMaxRequests,
+			},
+		},
+		{
+			name: "default strategy and gitops interval",
+			spec: &shipper.ShipperConfigSpec{
+				DefaultStrategy: &shipper.RolloutStrategy{Preset: "big-bang"},
+				GitOpsInterval:  &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			expected: Config{
+				EventVerbosity:       defaults.EventVerbosity,
+				EventDedupInterval:   defaults.EventDedupInterval,
+				DefaultStrategy:      &shipper.RolloutStrategy{Preset: "big-bang"},
+				GitOpsInterval:       5 * time.Minute,
+				ChartRepoMaxRequests: defaults.ChartRepoMaxRequests,
+			},
+		},
+		{
+			name: "completion webhook url",
+			spec: &shipper.ShipperConfigSpec{
+				Notifications: &shipper.NotificationsConfig{
+					CompletionWebhookURL: pstring("https://example.com/completed"),
+				},
+			},
+			expected: Config{
+				EventVerbosity:       defaults.EventVerbosity,
+				EventDedupInterval:   defaults.EventDedupInterval,
+				GitOpsInterval:       defaults.GitOpsInterval,
+				ChartRepoMaxRequests: defaults.ChartRepoMaxRequests,
+				CompletionWebhookURL: "https://example.com/completed",
+			},
+		},
+		{
+			name: "completion webhook disabled",
+			defaults: &Config{
+				EventVerbosity:       defaults.EventVerbosity,
+				EventDedupInterval:   defaults.EventDedupInterval,
+				GitOpsInterval:       defaults.GitOpsInterval,
+				ChartRepoMaxRequests: defaults.ChartRepoMaxRequests,
+				CompletionWebhookURL: "https://example.com/completed",
+			},
+			spec: &shipper.ShipperConfigSpec{
+				Notifications: &shipper.NotificationsConfig{
+					CompletionWebhookURL: pstring(""),
+				},
+			},
+			expected: defaults,
+		},
+		{
+			name: "unlimited chart repository requests",
+			spec: &shipper.ShipperConfigSpec{
+				ChartRepositories: &shipper.ChartRepositoriesConfig{
+					MaxConcurrentRequests: pint32(0),
+				},
+			},
+			expected: Config{
+				EventVerbosity:       defaults.EventVerbosity,
+				EventDedupInterval:   defaults.EventDedupInterval,
+				GitOpsInterval:       defaults.GitOpsInterval,
+				ChartRepoMaxRequests: 0,
+			},
+		},
+		{
+			name: "unknown verbosity",
+			spec: &shipper.ShipperConfigSpec{
+				Events: &shipper.EventsConfig{Verbosity: "some"},
+			},
+			err: true,
+		},
+		{
+			name: "completion webhook url outside of http",
+			spec: &shipper.ShipperConfigSpec{
+				Notifications: &shipper.NotificationsConfig{
+					CompletionWebhookURL: pstring("ftp://example.com/completed"),
+				},
+			},
+			err: true,
+		},
+		{
+			name: "negative chart repository requests",
+			spec: &shipper.ShipperConfigSpec{
+				ChartRepositories: &shipper.ChartRepositoriesConfig{
+					MaxConcurrentRequests: pint32(-1),
+				},
+			},
+			err: true,
+		},
+		{
+			name: "zero gitops interval",
+			spec: &shipper.ShipperConfigSpec{
+				GitOpsInterval: &metav1.Duration{},
+			},
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := defaults
+			if tt.defaults != nil {
+				base = *tt.defaults
+			}
+
+			config, err := Apply(base, tt.spec)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected an error, got config %+v", config)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if config.EventVerbosity != tt.expected.EventVerbosity ||
+				config.EventDedupInterval != tt.expected.EventDedupInterval ||
+				config.GitOpsInterval != tt.expected.GitOpsInterval {
+				t.Errorf("expected config %+v, got %+v", tt.expected, config)
+			}
+
+			if (config.DefaultStrategy == nil) != (tt.expected.DefaultStrategy == nil) ||
+				(config.DefaultStrategy != nil && config.DefaultStrategy.Preset != tt.expected.DefaultStrategy.Preset) {
+				t.Errorf("expected default strategy %v, got %v", tt.expected.DefaultStrategy, config.DefaultStrategy)
+			}
+		})
+	}
+}
+
+// TestStoreFollowsShipperConfig verifies that the Store picks up changes to
+// the ShipperConfig, tells its listeners about them, and goes back to its
+// defaults when the ShipperConfig is deleted.
+func TestStoreFollowsShipperConfig(t *testing.T) {
+	client := shipperfake.NewSimpleClientset()
+	informerFactory := shipperinformers.NewSharedInformerFactory(client, 0)
+
+	store := NewStore(defaults, informerFactory)
+
+	changes := make(chan Config, 10)
+	store.AddListener(func(config Config) {
+		changes <- config
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	// ShipperConfigs with other names are ignored.
+	ignored := &shipper.ShipperConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-shipper"},
+		Spec: shipper.ShipperConfigSpec{
+			Events: &shipper.EventsConfig{Verbosity: "none"},
+		},
+	}
+	if _, err := client.ShipperV1alpha1().ShipperConfigs().Create(ignored); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &shipper.ShipperConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: shipper.ShipperConfigName},
+		Spec: shipper.ShipperConfigSpec{
+			Events: &shipper.EventsConfig{Verbosity: "warnings"},
+		},
+	}
+	if _, err := client.ShipperV1alpha1().ShipperConfigs().Create(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := waitForChange(t, changes).EventVerbosity; got != shipperevents.VerbosityWarnings {
+		t.Fatalf("expected verbosity %q, got %q", shipperevents.VerbosityWarnings, got)
+	}
+
+	if got := store.Get().EventVerbosity; got != shipperevents.VerbosityWarnings {
+		t.Fatalf("expected store to have verbosity %q, got %q", shipperevents.VerbosityWarnings, got)
+	}
+
+	if err := client.ShipperV1alpha1().ShipperConfigs().Delete(config.Name, &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := waitForChange(t, changes).EventVerbosity; got != defaults.EventVerbosity {
+		t.Fatalf("expected verbosity to go back to %q, got %q", defaults.EventVerbosity, got)
+	}
+}
+
+func waitForChange(t *testing.T, changes chan Config) Config {
+	var config Config
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		select {
+		case config = <-changes:
+			return true, nil
+		default:
+			return false, nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for the config to change")
+	}
+
+	return config
+}
@@ -12,6 +12,7 @@ import (
 	"k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	kubetesting "k8s.io/client-go/testing"
 
 	shipperfake "github.com/bookingcom/shipper/pkg/client/clientset/versioned/fake"
 	shipperinformers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
@@ -62,6 +63,18 @@ func (c *FakeCluster) AddMany(objects []runtime.Object) {
 	}
 }
 
+// InjectError makes every request with verb on resource to both the
+// Kubernetes and the Shipper clients of the cluster fail with err. Use "*"
+// to match any verb or resource.
+func (c *FakeCluster) InjectError(verb, resource string, err error) {
+	reactor := func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, err
+	}
+
+	c.KubeClient.PrependReactor(verb, resource, reactor)
+	c.ShipperClient.PrependReactor(verb, resource, reactor)
+}
+
 func (c *FakeCluster) InitializeDiscovery(resources []*v1.APIResourceList) {
 	fakeDiscovery := c.KubeClient.Discovery().(*fakediscovery.FakeDiscovery)
 	fakeDiscovery.Resources = resources
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"fmt"
+	"sync"
 
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -17,6 +18,11 @@ import (
 type FakeClusterClientStore struct {
 	clusters map[string]*FakeCluster
 
+	// clusterErrors are returned instead of the clientsets of clusters
+	// that tests want to look unreachable or not ready.
+	clusterErrors      map[string]error
+	clusterErrorsMutex sync.Mutex
+
 	subscriptionCallbacks []clusterclientstore.SubscriptionRegisterFunc
 	eventHandlerCallbacks []clusterclientstore.EventHandlerRegisterFunc
 }
@@ -25,7 +31,8 @@ var _ clusterclientstore.Interface = (*FakeClusterClientStore)(nil)
 
 func NewFakeClusterClientStore() *FakeClusterClientStore {
 	return &FakeClusterClientStore{
-		clusters: make(map[string]*FakeCluster),
+		clusters:      make(map[string]*FakeCluster),
+		clusterErrors: make(map[string]error),
 	}
 }
 
@@ -33,6 +40,21 @@ func (s *FakeClusterClientStore) AddCluster(c *FakeCluster) {
 	s.clusters[c.Name] = c
 }
 
+// SetClusterError makes GetApplicationClusterClientset return err for
+// clusterName, the way the real store does for clusters that aren't ready.
+// Setting a nil error makes the cluster available again.
+func (s *FakeClusterClientStore) SetClusterError(clusterName string, err error) {
+	s.clusterErrorsMutex.Lock()
+	defer s.clusterErrorsMutex.Unlock()
+
+	if err == nil {
+		delete(s.clusterErrors, clusterName)
+		return
+	}
+
+	s.clusterErrors[clusterName] = err
+}
+
 func (s *FakeClusterClientStore) AddSubscriptionCallback(c clusterclientstore.SubscriptionRegisterFunc) {
 	s.subscriptionCallbacks = append(s.subscriptionCallbacks, c)
 }
@@ -60,6 +82,13 @@ func (s *FakeClusterClientStore) Run(stopCh <-chan struct{}) {
 }
 
 func (s *FakeClusterClientStore) GetApplicationClusterClientset(clusterName, ua string) (clusterclientstore.ClientsetInterface, error) {
+	s.clusterErrorsMutex.Lock()
+	err := s.clusterErrors[clusterName]
+	s.clusterErrorsMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
 	if _, ok := s.clusters[clusterName]; !ok {
 		return nil, fmt.Errorf("no client for cluster %q", clusterName)
 	}
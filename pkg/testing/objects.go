@@ -21,9 +21,15 @@ func BuildTargetObjectsForRelease(release *shipper.Release) (*shipper.Installati
 	installationTarget := &shipper.InstallationTarget{
 		ObjectMeta: *objmeta.DeepCopy(),
 		Spec: shipper.InstallationTargetSpec{
-			CanOverride: true,
-			Chart:       release.Spec.Environment.Chart,
-			Values:      release.Spec.Environment.Values,
+			CanOverride:   true,
+			Chart:         release.Spec.Environment.Chart,
+			Values:        release.Spec.Environment.Values,
+			ImageOverride: release.Spec.Environment.ImageOverride,
+			PrePullImages: release.Spec.Environment.PrePullImages,
+
+			TopologySpread: release.Spec.Environment.TopologySpread,
+
+			ReadinessBarriers: release.Spec.Environment.ReadinessBarriers,
 		},
 	}
 
@@ -35,8 +41,11 @@ func BuildTargetObjectsForRelease(release *shipper.Release) (*shipper.Installati
 	capacityTarget := &shipper.CapacityTarget{
 		ObjectMeta: *objmeta.DeepCopy(),
 		Spec: shipper.CapacityTargetSpec{
+			Application:       release.Labels[shipper.AppLabel],
+			Release:           release.Name,
 			Percent:           0,
 			TotalReplicaCount: 12,
+			ScaleDownPolicy:   release.Spec.Environment.ScaleDownPolicy,
 		},
 	}
 
@@ -0,0 +1,185 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+)
+
+// Exporter sends spans somewhere they can be looked at.
+type Exporter interface {
+	Export(spans []*Span)
+}
+
+// OTLPExporter exports spans to an OpenTelemetry collector, using the JSON
+// encoding of OTLP over HTTP. This is all Shipper needs from OpenTelemetry,
+// which keeps its SDK out of Shipper.
+type OTLPExporter struct {
+	url      string
+	resource otlpResource
+	client   *http.Client
+}
+
+var _ Exporter = (*OTLPExporter)(nil)
+
+// NewOTLPExporter returns an exporter sending spans to the collector at
+// endpoint, such as http://otel-collector:4318, on behalf of serviceName.
+// attrs describe the process the spans come from.
+func NewOTLPExporter(endpoint, serviceName string, timeout time.Duration, attrs ...Attribute) *OTLPExporter {
+	resourceAttrs := []Attribute{String("service.name", serviceName)}
+	resourceAttrs = append(resourceAttrs, attrs...)
+
+	return &OTLPExporter{
+		url:      strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		resource: otlpResource{Attributes: toOTLPAttributes(resourceAttrs)},
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (e *OTLPExporter) Export(spans []*Span) {
+	if err := e.export(spans); err != nil {
+		klog.Warningf("could not export %d spans: %s", len(spans), err)
+		shippermetrics.TracingSpansDropped.WithLabelValues("export_failed").Add(float64(len(spans)))
+	}
+}
+
+func (e *OTLPExporter) export(spans []*Span) error {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, toOTLPSpan(span))
+	}
+
+	body, err := json.Marshal(otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: e.resource,
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/bookingcom/shipper"},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %q", resp.StatusCode, e.url)
+	}
+
+	return nil
+}
+
+// The types below are the parts of the OTLP/JSON encoding of
+// ExportTraceServiceRequest that Shipper fills in.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+
+	otlpStatusCodeOk    = 1
+	otlpStatusCodeError = 2
+)
+
+func toOTLPSpan(span *Span) otlpSpan {
+	s := otlpSpan{
+		TraceID:           hex.EncodeToString(span.traceID[:]),
+		SpanID:            hex.EncodeToString(span.spanID[:]),
+		Name:              span.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		Attributes:        toOTLPAttributes(span.attrs),
+		Status:            otlpStatus{Code: otlpStatusCodeOk},
+	}
+
+	if span.parentID != [8]byte{} {
+		s.ParentSpanID = hex.EncodeToString(span.parentID[:])
+	}
+
+	if span.err != nil {
+		s.Status = otlpStatus{
+			Code:    otlpStatusCodeError,
+			Message: span.err.Error(),
+		}
+	}
+
+	return s
+}
+
+func toOTLPAttributes(attrs []Attribute) []otlpAttribute {
+	otlpAttrs := make([]otlpAttribute, 0, len(attrs))
+	for _, attr := range attrs {
+		otlpAttrs = append(otlpAttrs, otlpAttribute{
+			Key:   attr.Key,
+			Value: otlpAnyValue{StringValue: attr.Value},
+		})
+	}
+
+	return otlpAttrs
+}
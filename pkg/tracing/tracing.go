@@ -0,0 +1,259 @@
+// Package tracing records what controllers spend their syncs on as spans,
+// and exports them to an OpenTelemetry collector over OTLP/HTTP. Tracing is
+// disabled until a Tracer is set with SetTracer, and starting and ending
+// spans costs next to nothing until then.
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shippermetrics "github.com/bookingcom/shipper/pkg/metrics/prometheus"
+)
+
+// Attribute is a key/value pair describing a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String builds an Attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation, part of a trace. All of its methods are safe
+// to call on a nil Span, which is what Start returns when tracing is
+// disabled, so callers never need to check.
+type Span struct {
+	tracer *Tracer
+
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+
+	// sampled is false for spans in traces that won't be exported. They're
+	// still tracked, so their children aren't sampled on their own.
+	sampled bool
+
+	name  string
+	start time.Time
+	end   time.Time
+	attrs []Attribute
+	err   error
+}
+
+// TraceID returns the hex encoded ID of the trace s belongs to.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// SetAttributes adds attrs to s.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.sampled {
+		return
+	}
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End marks s as finished, and failed if err is not nil. Spans are only
+// exported once they've ended.
+func (s *Span) End(err error) {
+	if s == nil || !s.sampled {
+		return
+	}
+
+	s.end = time.Now()
+	s.err = err
+	if err != nil {
+		s.attrs = append(s.attrs,
+			String("shipper.error.reason", shippererrors.Reason(err)),
+			String("shipper.error.retry", strconv.FormatBool(shippererrors.ShouldRetry(err))),
+		)
+	}
+	s.tracer.enqueue(s)
+}
+
+// Start starts a span called name, at the root of a new trace.
+func Start(name string, attrs ...Attribute) *Span {
+	t := globalTracer
+	if t == nil {
+		return nil
+	}
+
+	span := newSpan(t, name, attrs)
+	rand.Read(span.traceID[:])
+	span.sampled = t.shouldSample(span.traceID)
+
+	return span
+}
+
+// StartChild starts a span called name, as a child of s.
+func (s *Span) StartChild(name string, attrs ...Attribute) *Span {
+	if s == nil {
+		return nil
+	}
+
+	span := newSpan(s.tracer, name, attrs)
+	span.traceID = s.traceID
+	span.parentID = s.spanID
+	span.sampled = s.sampled
+
+	return span
+}
+
+func newSpan(t *Tracer, name string, attrs []Attribute) *Span {
+	span := &Span{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+		attrs:  attrs,
+	}
+	rand.Read(span.spanID[:])
+
+	return span
+}
+
+// StartSync starts the root span of a controller syncing the object of kind
+// behind key.
+func StartSync(controller, kind, key string) *Span {
+	return Start(
+		"Sync "+kind,
+		String("shipper.controller", controller),
+		String("shipper.kind", kind),
+		String("shipper.key", key),
+	)
+}
+
+// Tracer collects ended spans and hands them over to its exporter in
+// batches.
+type Tracer struct {
+	exporter    Exporter
+	sampleRatio float64
+
+	spanCh  chan *Span
+	flushCh chan chan struct{}
+}
+
+var globalTracer *Tracer
+
+const (
+	// MaxQueueSize is how many ended spans can wait to be exported. Spans
+	// ending while the queue is full are dropped.
+	MaxQueueSize = 2048
+
+	// MaxBatchSize is how many spans are exported at once.
+	MaxBatchSize = 512
+
+	// BatchTimeout is how long an ended span waits to be exported at most.
+	BatchTimeout = 5 * time.Second
+)
+
+// NewTracer returns a Tracer exporting spans of sampleRatio of all traces
+// through exporter. It's not used until it's passed to SetTracer.
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	return &Tracer{
+		exporter:    exporter,
+		sampleRatio: sampleRatio,
+		spanCh:      make(chan *Span, MaxQueueSize),
+		flushCh:     make(chan chan struct{}),
+	}
+}
+
+// SetTracer makes t the tracer all spans are started with. Tracing is
+// disabled if t is nil.
+func SetTracer(t *Tracer) {
+	globalTracer = t
+}
+
+// Run exports spans as they end, in batches. It never returns, so spans
+// ending while the process is shutting down are exported too, as long as
+// Flush is called last.
+func (t *Tracer) Run() {
+	ticker := time.NewTicker(BatchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, MaxBatchSize)
+	add := func(span *Span) {
+		batch = append(batch, span)
+		if len(batch) >= MaxBatchSize {
+			t.exporter.Export(batch)
+			batch = make([]*Span, 0, MaxBatchSize)
+		}
+	}
+	export := func() {
+		if len(batch) > 0 {
+			t.exporter.Export(batch)
+			batch = make([]*Span, 0, MaxBatchSize)
+		}
+	}
+
+	for {
+		select {
+		case span := <-t.spanCh:
+			add(span)
+		case <-ticker.C:
+			export()
+		case doneCh := <-t.flushCh:
+			for drained := false; !drained; {
+				select {
+				case span := <-t.spanCh:
+					add(span)
+				default:
+					drained = true
+				}
+			}
+			export()
+			close(doneCh)
+		}
+	}
+}
+
+// Flush waits until all spans that ended so far have been exported. It must
+// only be called while Run is running.
+func (t *Tracer) Flush() {
+	doneCh := make(chan struct{})
+	t.flushCh <- doneCh
+	<-doneCh
+}
+
+// Flush exports all spans that ended so far, if tracing is enabled.
+func Flush() {
+	if globalTracer != nil {
+		globalTracer.Flush()
+	}
+}
+
+func (t *Tracer) enqueue(span *Span) {
+	select {
+	case t.spanCh <- span:
+	default:
+		shippermetrics.TracingSpansDropped.WithLabelValues("queue_full").Inc()
+	}
+}
+
+// shouldSample decides whether a trace is exported, based on its ID alone,
+// so every span in it gets the same answer.
+func (t *Tracer) shouldSample(traceID [16]byte) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	} else if t.sampleRatio <= 0 {
+		return false
+	}
+
+	var x uint64
+	for _, b := range traceID[8:] {
+		x = x<<8 | uint64(b)
+	}
+
+	return x < uint64(t.sampleRatio*math.MaxUint64)
+}
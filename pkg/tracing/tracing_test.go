@@ -0,0 +1,199 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shippertesting "github.com/bookingcom/shipper/pkg/testing"
+)
+
+type fakeExporter struct {
+	spans []*Span
+}
+
+func (e *fakeExporter) Export(spans []*Span) {
+	e.spans = append(e.spans, spans...)
+}
+
+func runTracer(sampleRatio float64) (*fakeExporter, func()) {
+	exporter := &fakeExporter{}
+	tracer := NewTracer(exporter, sampleRatio)
+	SetTracer(tracer)
+	go tracer.Run()
+
+	return exporter, func() {
+		Flush()
+		SetTracer(nil)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	SetTracer(nil)
+
+	span := StartSync("controller", "Release", "namespace/name")
+	if span != nil {
+		t.Fatalf("expected no span with tracing disabled, got %v", span)
+	}
+
+	child := span.StartChild("child")
+	child.SetAttributes(String("key", "value"))
+	child.End(fmt.Errorf("error"))
+	span.End(nil)
+}
+
+func TestSpanNesting(t *testing.T) {
+	exporter, stop := runTracer(1)
+
+	root := StartSync("controller", "Release", "namespace/name")
+	cluster := root.StartChild("cluster")
+	call := cluster.StartChild("call")
+	call.End(fmt.Errorf("error"))
+	cluster.End(nil)
+	root.End(nil)
+
+	stop()
+
+	if len(exporter.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(exporter.spans))
+	}
+
+	for _, span := range []*Span{call, cluster} {
+		if span.traceID != root.traceID {
+			t.Errorf("expected span %q to be part of trace %s, got %s", span.name, root.TraceID(), span.TraceID())
+		}
+	}
+
+	if root.parentID != [8]byte{} {
+		t.Errorf("expected root span to have no parent")
+	}
+	if cluster.parentID != root.spanID {
+		t.Errorf("expected span %q to be a child of %q", cluster.name, root.name)
+	}
+	if call.parentID != cluster.spanID {
+		t.Errorf("expected span %q to be a child of %q", call.name, cluster.name)
+	}
+	if call.err == nil {
+		t.Errorf("expected span %q to have failed", call.name)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	exporter, stop := runTracer(0)
+
+	root := StartSync("controller", "Release", "namespace/name")
+	child := root.StartChild("child")
+	child.End(nil)
+	root.End(nil)
+
+	stop()
+
+	if len(exporter.spans) != 0 {
+		t.Fatalf("expected no spans to be exported, got %d", len(exporter.spans))
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received []otlpRequest
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("could not decode request body: %s", err)
+		}
+		path = r.URL.Path
+		received = append(received, req)
+	}))
+	defer server.Close()
+
+	root := &Span{
+		traceID: [16]byte{1},
+		spanID:  [8]byte{2},
+		name:    "Sync Release",
+		start:   time.Unix(1, 0),
+		end:     time.Unix(2, 0),
+		attrs:   []Attribute{String("shipper.key", "namespace/name")},
+	}
+	child := &Span{
+		traceID:  [16]byte{1},
+		spanID:   [8]byte{3},
+		parentID: [8]byte{2},
+		name:     "Patch CapacityTarget",
+		start:    time.Unix(1, 5),
+		end:      time.Unix(1, 10),
+		err:      fmt.Errorf("conflict"),
+	}
+
+	exporter := NewOTLPExporter(server.URL+"/", "shipper-mgmt", time.Second, String("shipper.cluster", shippertesting.TestCluster))
+	if err := exporter.export([]*Span{root, child}); err != nil {
+		t.Fatalf("unexpected error exporting spans: %s", err)
+	}
+
+	if path != "/v1/traces" {
+		t.Errorf("expected spans to be exported to /v1/traces, got %q", path)
+	}
+
+	expected := []otlpRequest{
+		{
+			ResourceSpans: []otlpResourceSpans{
+				{
+					Resource: otlpResource{
+						Attributes: []otlpAttribute{
+							{Key: "service.name", Value: otlpAnyValue{StringValue: "shipper-mgmt"}},
+							{Key: "shipper.cluster", Value: otlpAnyValue{StringValue: shippertesting.TestCluster}},
+						},
+					},
+					ScopeSpans: []otlpScopeSpans{
+						{
+							Scope: otlpScope{Name: "github.com/bookingcom/shipper"},
+							Spans: []otlpSpan{
+								{
+									TraceID:           "01000000000000000000000000000000",
+									SpanID:            "0200000000000000",
+									Name:              "Sync Release",
+									Kind:              otlpSpanKindInternal,
+									StartTimeUnixNano: "1000000000",
+									EndTimeUnixNano:   "2000000000",
+									Attributes: []otlpAttribute{
+										{Key: "shipper.key", Value: otlpAnyValue{StringValue: "namespace/name"}},
+									},
+									Status: otlpStatus{Code: otlpStatusCodeOk},
+								},
+								{
+									TraceID:           "01000000000000000000000000000000",
+									SpanID:            "0300000000000000",
+									ParentSpanID:      "0200000000000000",
+									Name:              "Patch CapacityTarget",
+									Kind:              otlpSpanKindInternal,
+									StartTimeUnixNano: "1000000005",
+									EndTimeUnixNano:   "1000000010",
+									Status:            otlpStatus{Code: otlpStatusCodeError, Message: "conflict"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	eq, diff := shippertesting.DeepEqualDiff(expected, received)
+	if !eq {
+		t.Fatalf("collector received unexpected spans:\n%s", diff)
+	}
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, "shipper-mgmt", time.Second)
+	if err := exporter.export([]*Span{{name: "span"}}); err == nil {
+		t.Fatal("expected error exporting spans, got none")
+	}
+}
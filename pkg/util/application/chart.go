@@ -7,8 +7,7 @@ import (
 	shipperrepo "github.com/bookingcom/shipper/pkg/chart/repo"
 )
 
-func ChartVersionResolved(app *shipper.Application) bool {
-	chartspec := app.Spec.Template.Chart
+func ChartVersionResolved(app *shipper.Application, chartspec *shipper.Chart) bool {
 	resVer, ok := app.Annotations[shipper.AppChartVersionResolvedAnnotation]
 	if !ok || resVer != chartspec.Version {
 		return false
@@ -22,15 +21,16 @@ func ChartVersionResolved(app *shipper.Application) bool {
 	return true
 }
 
-// This function modifies app object and populates it's annotations.
-// The changes are not saved immediately and are delegated to the caller.
-func ResolveChartVersion(app *shipper.Application, resolver shipperrepo.ChartVersionResolver) (*repo.ChartVersion, error) {
-	cv, err := resolver(&app.Spec.Template.Chart)
+// This function modifies chart, the chart of app's environment, and populates
+// app's annotations. The changes are not saved immediately and are delegated
+// to the caller.
+func ResolveChartVersion(app *shipper.Application, chart *shipper.Chart, resolver shipperrepo.ChartVersionResolver) (*repo.ChartVersion, error) {
+	cv, err := resolver(chart)
 	if err != nil {
 		return nil, err
 	}
-	rawVer := app.Spec.Template.Chart.Version
-	app.Spec.Template.Chart.Version = cv.Version
+	rawVer := chart.Version
+	chart.Version = cv.Version
 
 	UpdateChartNameAnnotation(app, cv.Name)
 	UpdateChartVersionResolvedAnnotation(app, cv.Version)
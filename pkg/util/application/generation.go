@@ -5,6 +5,7 @@ import (
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	"github.com/bookingcom/shipper/pkg/errors"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 )
 
 func GetHighestObservedGeneration(app *shipper.Application) (int, error) {
@@ -28,3 +29,21 @@ func SetHighestObservedGeneration(app *shipper.Application, generation int) {
 func CopyEnvironment(app *shipper.Application, rel *shipper.Release) {
 	app.Spec.Template = *(rel.Spec.Environment.DeepCopy())
 }
+
+// RollbackToRelease makes app ship rel's environment again. The application
+// controller then creates a new contender from it, which goes through the
+// strategy like any other release. Only releases of app that have completed
+// their rollout can be rolled back to.
+func RollbackToRelease(app *shipper.Application, rel *shipper.Release) error {
+	if rel.Namespace != app.Namespace || rel.Labels[shipper.AppLabel] != app.Name {
+		return errors.NewInvalidRollbackTargetError(app.Name, rel.Name, "release does not belong to the application")
+	}
+
+	if !releaseutil.ReleaseComplete(rel) {
+		return errors.NewInvalidRollbackTargetError(app.Name, rel.Name, "release has never completed its rollout")
+	}
+
+	CopyEnvironment(app, rel)
+
+	return nil
+}
@@ -6,6 +6,8 @@ const (
 
 	CreateReleaseFailed                 = "CreateReleaseFailed"
 	ChartVersionResolutionFailed        = "ChartVersionResolutionFailed"
+	TemplateRenderFailed                = "TemplateRenderFailed"
+	StrategyResolutionFailed            = "StrategyResolutionFailed"
 	BrokenReleaseGeneration             = "BrokenReleaseGeneration"
 	BrokenApplicationObservedGeneration = "BrokenApplicationObservedGeneration"
 )
@@ -5,10 +5,11 @@ import (
 	"k8s.io/klog"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	objectutil "github.com/bookingcom/shipper/pkg/util/object"
 )
 
 func BelongsToRelease(obj interface{}) bool {
-	kubeobj, ok := obj.(metav1.Object)
+	kubeobj, ok := objectutil.FromTombstone(obj).(metav1.Object)
 	if !ok {
 		klog.Warningf("Received something that's not a metav1.Object: %v", obj)
 		return false
@@ -20,7 +21,7 @@ func BelongsToRelease(obj interface{}) bool {
 }
 
 func BelongsToApp(obj interface{}) bool {
-	kubeobj, ok := obj.(metav1.Object)
+	kubeobj, ok := objectutil.FromTombstone(obj).(metav1.Object)
 	if !ok {
 		klog.Warningf("Received something that's not a metav1.Object: %v", obj)
 		return false
@@ -0,0 +1,97 @@
+// Package index provides informer indexes that let controllers look up the
+// objects belonging to a release or application without scanning every
+// object in a namespace.
+package index
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	// ByRelease indexes objects by namespace and Shipper release label.
+	ByRelease = "shipper-release"
+	// ByApplication indexes objects by namespace and Shipper application
+	// label.
+	ByApplication = "shipper-app"
+)
+
+// AddIndexers adds the ByRelease and ByApplication indexes to informer. It
+// is safe to call for an informer shared by several controllers, but, like
+// any indexer, only before the informer is started.
+func AddIndexers(informer cache.SharedIndexInformer) error {
+	existing := informer.GetIndexer().GetIndexers()
+
+	indexers := cache.Indexers{}
+	if _, ok := existing[ByRelease]; !ok {
+		indexers[ByRelease] = labelIndexFunc(shipper.ReleaseLabel)
+	}
+	if _, ok := existing[ByApplication]; !ok {
+		indexers[ByApplication] = labelIndexFunc(shipper.AppLabel)
+	}
+
+	if len(indexers) == 0 {
+		return nil
+	}
+
+	return informer.AddIndexers(indexers)
+}
+
+// PodsForRelease returns the pods in namespace labelled as belonging to
+// release and matching selector.
+func PodsForRelease(indexer cache.Indexer, namespace, release string, selector labels.Selector) ([]*corev1.Pod, error) {
+	return podsByIndex(indexer, ByRelease, namespace, release, selector)
+}
+
+// PodsForApplication returns the pods in namespace labelled as belonging to
+// app and matching selector.
+func PodsForApplication(indexer cache.Indexer, namespace, app string, selector labels.Selector) ([]*corev1.Pod, error) {
+	return podsByIndex(indexer, ByApplication, namespace, app, selector)
+}
+
+func podsByIndex(indexer cache.Indexer, index, namespace, value string, selector labels.Selector) ([]*corev1.Pod, error) {
+	objs, err := indexer.ByIndex(index, key(namespace, value))
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]*corev1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return nil, fmt.Errorf("expected a Pod in index %q, got %T", index, obj)
+		}
+
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+func labelIndexFunc(label string) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		object, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := object.GetLabels()[label]
+		if !ok {
+			return nil, nil
+		}
+
+		return []string{key(object.GetNamespace(), value)}, nil
+	}
+}
+
+func key(namespace, value string) string {
+	return namespace + "/" + value
+}
@@ -0,0 +1,74 @@
+package index
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestPodsForRelease(t *testing.T) {
+	informer := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0).
+		Core().V1().Pods().Informer()
+
+	// Adding indexes twice must work, since informers are shared
+	// between controllers.
+	for i := 0; i < 2; i++ {
+		if err := AddIndexers(informer); err != nil {
+			t.Fatalf("unexpected error adding indexers: %s", err)
+		}
+	}
+
+	indexer := informer.GetIndexer()
+	for _, pod := range []*corev1.Pod{
+		buildPod("ns", "a-0", "app", "rel-a", "web"),
+		buildPod("ns", "a-1", "app", "rel-a", "worker"),
+		buildPod("ns", "b-0", "app", "rel-b", "web"),
+		buildPod("other", "a-0", "app", "rel-a", "web"),
+	} {
+		indexer.Add(pod)
+	}
+
+	pods, err := PodsForRelease(indexer, "ns", "rel-a", labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pods) != 2 {
+		t.Fatalf("expected 2 pods for release, got %d", len(pods))
+	}
+
+	pods, err = PodsForRelease(indexer, "ns", "rel-a", labels.Set{"role": "web"}.AsSelector())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "a-0" {
+		t.Fatalf("expected only pod a-0 to match the selector, got %v", pods)
+	}
+
+	pods, err = PodsForApplication(indexer, "ns", "app", labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pods) != 3 {
+		t.Fatalf("expected 3 pods for application, got %d", len(pods))
+	}
+}
+
+func buildPod(namespace, name, app, release, role string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels: map[string]string{
+				shipper.AppLabel:     app,
+				shipper.ReleaseLabel: release,
+				"role":               role,
+			},
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package object
+
+import (
+	"k8s.io/client-go/tools/cache"
+)
+
+// FromTombstone returns the object obj stands for. Informers that miss the
+// deletion of an object, only to find out it's gone when they relist, hand
+// delete handlers a tombstone holding its last known state instead.
+func FromTombstone(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+
+	return obj
+}
+
+// OnDelete returns a delete handler that calls handler with the deleted
+// object, even when informers only have its tombstone.
+func OnDelete(handler func(interface{})) func(interface{}) {
+	return func(obj interface{}) {
+		handler(FromTombstone(obj))
+	}
+}
@@ -0,0 +1,53 @@
+package release
+
+import (
+	"strings"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const approverGroupPrefix = "group:"
+
+// StepApproved returns whether a strategy step can be executed: either it
+// does not require approval, or at least one approval has been recorded for
+// it.
+func StepApproved(rel *shipper.Release, strategy *shipper.RolloutStrategy, step int32) bool {
+	if strategy == nil || step < 0 || int(step) >= len(strategy.Steps) {
+		return true
+	}
+
+	if !strategy.Steps[step].ApprovalRequired {
+		return true
+	}
+
+	for _, approval := range rel.Spec.Approvals {
+		if approval.Step == step {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanApproveStep returns whether a user, belonging to groups, is allowed to
+// approve a strategy step.
+func CanApproveStep(step shipper.RolloutStrategyStep, user string, groups []string) bool {
+	if len(step.Approvers) == 0 {
+		return true
+	}
+
+	for _, approver := range step.Approvers {
+		if strings.HasPrefix(approver, approverGroupPrefix) {
+			group := strings.TrimPrefix(approver, approverGroupPrefix)
+			for _, g := range groups {
+				if g == group {
+					return true
+				}
+			}
+		} else if approver == user {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestCanApproveStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		approvers []string
+		user      string
+		groups    []string
+		expected  bool
+	}{
+		{"no approvers", nil, "bob", nil, true},
+		{"listed user", []string{"alice"}, "alice", nil, true},
+		{"unlisted user", []string{"alice"}, "bob", nil, false},
+		{"listed group", []string{"group:sre"}, "bob", []string{"dev", "sre"}, true},
+		{"unlisted group", []string{"group:sre"}, "bob", []string{"dev"}, false},
+		{"user named like a group", []string{"group:sre"}, "group:sre", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := shipper.RolloutStrategyStep{Approvers: tt.approvers}
+			if got := CanApproveStep(step, tt.user, tt.groups); got != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
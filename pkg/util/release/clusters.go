@@ -1,6 +1,9 @@
 package release
 
 import (
+	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
@@ -18,3 +21,79 @@ func GetSelectedClusters(rel *shipper.Release) []string {
 
 	return strings.Split(clusterAnnotation, ",")
 }
+
+// GetClusterReplicas returns how many replicas each cluster should run, as
+// decided by the release's cluster spread. Releases without a spread return
+// an empty map, meaning every cluster runs the chart's replica count.
+func GetClusterReplicas(rel *shipper.Release) map[string]int32 {
+	replicas := map[string]int32{}
+
+	if len(rel.Spec.ClusterReplicas) > 0 {
+		for _, cluster := range rel.Spec.ClusterReplicas {
+			replicas[cluster.Name] = cluster.Replicas
+		}
+		return replicas
+	}
+
+	// Releases scheduled before ClusterReplicas existed only have the
+	// split in an annotation.
+	annotation, ok := rel.Annotations[shipper.ReleaseClusterReplicasAnnotation]
+	if !ok || len(annotation) == 0 {
+		return replicas
+	}
+
+	for _, entry := range strings.Split(annotation, ",") {
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		count, err := strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		replicas[parts[0]] = int32(count)
+	}
+
+	return replicas
+}
+
+// ValidateClusterReplicas ensures that a release lists every cluster in its
+// ClusterReplicas at most once, with a replica count that makes sense.
+func ValidateClusterReplicas(rel *shipper.Release) error {
+	seen := make(map[string]struct{}, len(rel.Spec.ClusterReplicas))
+	for _, cluster := range rel.Spec.ClusterReplicas {
+		if cluster.Name == "" {
+			return fmt.Errorf("clusterReplicas entries need a cluster name")
+		}
+		if _, ok := seen[cluster.Name]; ok {
+			return fmt.Errorf("cluster %q is listed more than once in clusterReplicas", cluster.Name)
+		}
+		if cluster.Replicas < 0 {
+			return fmt.Errorf("cluster %q can not have %d replicas", cluster.Name, cluster.Replicas)
+		}
+		seen[cluster.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// ClusterAllowsNamespace returns whether releases in namespace can be
+// scheduled on cluster, according to its ClusterNamespacesAnnotation.
+// Clusters without the annotation allow every namespace.
+func ClusterAllowsNamespace(cluster *shipper.Cluster, namespace string) bool {
+	patterns, ok := cluster.Annotations[shipper.ClusterNamespacesAnnotation]
+	if !ok {
+		return true
+	}
+
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestGetClusterReplicas(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterReplicas []shipper.ClusterReplicas
+		annotation      string
+		expected        map[string]int32
+	}{
+		{
+			name:     "no spread",
+			expected: map[string]int32{},
+		},
+		{
+			name: "spec",
+			clusterReplicas: []shipper.ClusterReplicas{
+				{Name: "cluster-a", Replicas: 3},
+				{Name: "cluster-b", Replicas: 0},
+			},
+			annotation: "cluster-a:1",
+			expected:   map[string]int32{"cluster-a": 3, "cluster-b": 0},
+		},
+		{
+			name:       "annotation of a release scheduled before spec",
+			annotation: "cluster-a:2,cluster-b:bogus,cluster-c:1",
+			expected:   map[string]int32{"cluster-a": 2, "cluster-c": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease("test-namespace", "test-release", "0")
+		rel.Spec.ClusterReplicas = tt.clusterReplicas
+		if tt.annotation != "" {
+			rel.Annotations[shipper.ReleaseClusterReplicasAnnotation] = tt.annotation
+		}
+
+		if replicas := GetClusterReplicas(rel); !reflect.DeepEqual(replicas, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, replicas)
+		}
+	}
+}
+
+func TestValidateClusterReplicas(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterReplicas []shipper.ClusterReplicas
+		valid           bool
+	}{
+		{"empty", nil, true},
+		{"valid", []shipper.ClusterReplicas{{Name: "cluster-a", Replicas: 2}, {Name: "cluster-b", Replicas: 0}}, true},
+		{"no name", []shipper.ClusterReplicas{{Replicas: 2}}, false},
+		{"duplicate", []shipper.ClusterReplicas{{Name: "cluster-a", Replicas: 2}, {Name: "cluster-a", Replicas: 1}}, false},
+		{"negative", []shipper.ClusterReplicas{{Name: "cluster-a", Replicas: -1}}, false},
+	}
+
+	for _, tt := range tests {
+		rel := buildRelease("test-namespace", "test-release", "0")
+		rel.Spec.ClusterReplicas = tt.clusterReplicas
+
+		if err := ValidateClusterReplicas(rel); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
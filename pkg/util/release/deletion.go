@@ -0,0 +1,38 @@
+package release
+
+import (
+	"fmt"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// ReleaseRolledBack returns true if rel was moved back to, and has achieved,
+// the first step of its strategy, which is where aborted rollouts end up.
+func ReleaseRolledBack(rel *shipper.Release) bool {
+	return rel.Spec.TargetStep == 0 &&
+		rel.Status.AchievedStep != nil &&
+		rel.Status.AchievedStep.Step == 0
+}
+
+// ValidateDeletion ensures that rel can be deleted, given the current
+// contender of its application. A contender that hasn't completed its
+// rollout, nor been rolled back, is likely getting traffic the incumbent
+// can't take back instantly, so it's only deleted if it was annotated with
+// ReleaseForceDeleteAnnotation beforehand.
+func ValidateDeletion(rel, contender *shipper.Release) error {
+	if rel.Annotations[shipper.ReleaseForceDeleteAnnotation] == "true" {
+		return nil
+	}
+
+	if contender == nil || contender.Name != rel.Name {
+		return nil
+	}
+
+	if ReleaseComplete(rel) || ReleaseRolledBack(rel) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"release %q is still rolling out: roll it back to step 0 or let it complete first, or annotate it with %s=true to delete it anyway",
+		rel.Name, shipper.ReleaseForceDeleteAnnotation)
+}
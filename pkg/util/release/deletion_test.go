@@ -0,0 +1,78 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestValidateDeletion(t *testing.T) {
+	incumbent := buildRelease("test-namespace", "incumbent", "0")
+
+	tests := []struct {
+		name   string
+		modify func(rel *shipper.Release)
+		valid  bool
+	}{
+		{
+			"in flight",
+			func(rel *shipper.Release) {
+				rel.Spec.TargetStep = 1
+				rel.Status.AchievedStep = &shipper.AchievedStep{Step: 0}
+			},
+			false,
+		},
+		{
+			"not scheduled yet",
+			func(rel *shipper.Release) {},
+			false,
+		},
+		{
+			"rolled back",
+			func(rel *shipper.Release) {
+				rel.Status.AchievedStep = &shipper.AchievedStep{Step: 0}
+			},
+			true,
+		},
+		{
+			"rolling back",
+			func(rel *shipper.Release) {
+				rel.Status.AchievedStep = &shipper.AchievedStep{Step: 2}
+			},
+			false,
+		},
+		{
+			"complete",
+			func(rel *shipper.Release) {
+				rel.Spec.TargetStep = 2
+				rel.Status.AchievedStep = &shipper.AchievedStep{Step: 2}
+				SetReleaseCondition(&rel.Status, *NewReleaseCondition(shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "", ""))
+			},
+			true,
+		},
+		{
+			"forced",
+			func(rel *shipper.Release) {
+				rel.Spec.TargetStep = 1
+				rel.Annotations[shipper.ReleaseForceDeleteAnnotation] = "true"
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		contender := buildRelease("test-namespace", "contender", "1")
+		tt.modify(contender)
+
+		if err := ValidateDeletion(contender, contender); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+
+		// Only the contender is ever protected.
+		if err := ValidateDeletion(incumbent, contender); err != nil {
+			t.Errorf("%s: expected incumbent to be deletable, got error %v", tt.name, err)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package release
 
 import (
+	"fmt"
+	"reflect"
+
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shipperchart "github.com/bookingcom/shipper/pkg/chart"
 )
 
 func HasEmptyEnvironment(rel *shipper.Release) bool {
@@ -12,6 +16,76 @@ func HasEmptyEnvironment(rel *shipper.Release) bool {
 		len(rel.Spec.Environment.ClusterRequirements.Capabilities) == 0
 }
 
+// ValidateEnvironmentUpdate ensures that nothing deciding what a release
+// rolls out, and where, changes once it's created, since its target objects
+// were created from it and the strategy would lose track of them. Only
+// releases created with an empty environment can have it filled in, and only
+// Shipper's own resolution of a strategy preset into steps is allowed
+// otherwise. The clusters a release is scheduled on, and the replicas and
+// values chosen for each of them, can be set once.
+func ValidateEnvironmentUpdate(rel, oldRel *shipper.Release) error {
+	if !HasEmptyEnvironment(oldRel) {
+		env, oldEnv := rel.Spec.Environment.DeepCopy(), oldRel.Spec.Environment.DeepCopy()
+		ResolveStrategyPreset(oldEnv.Strategy)
+
+		if !reflect.DeepEqual(env.Strategy, oldEnv.Strategy) &&
+			!reflect.DeepEqual(env.Strategy, oldRel.Spec.Environment.Strategy) {
+			return fmt.Errorf("spec.environment.strategy can not be changed once a release is created")
+		}
+
+		env.Strategy, oldEnv.Strategy = nil, nil
+		if !reflect.DeepEqual(env, oldEnv) {
+			return fmt.Errorf("spec.environment can not be changed once a release is created, only spec.targetStep can")
+		}
+	}
+
+	oldClusters := oldRel.Annotations[shipper.ReleaseClustersAnnotation]
+	if oldClusters != "" && rel.Annotations[shipper.ReleaseClustersAnnotation] != oldClusters {
+		return fmt.Errorf("annotation %s can not be changed once set", shipper.ReleaseClustersAnnotation)
+	}
+
+	if len(oldRel.Spec.ClusterReplicas) > 0 &&
+		!reflect.DeepEqual(rel.Spec.ClusterReplicas, oldRel.Spec.ClusterReplicas) {
+		return fmt.Errorf("spec.clusterReplicas can not be changed once set")
+	}
+
+	if len(oldRel.Spec.ClusterValues) > 0 &&
+		!reflect.DeepEqual(rel.Spec.ClusterValues, oldRel.Spec.ClusterValues) {
+		return fmt.Errorf("spec.clusterValues can not be changed once set")
+	}
+
+	return nil
+}
+
+// ValidateIdentityLabels ensures that the labels identifying a release, which
+// the installer stamps on every object it renders and capacity targets select
+// Deployments by, name the release itself and don't change once set. oldRel
+// is nil for releases being created.
+func ValidateIdentityLabels(rel, oldRel *shipper.Release) error {
+	if name, ok := rel.Labels[shipper.ReleaseLabel]; ok && name != rel.Name {
+		return fmt.Errorf("label %s must be %q, not %q", shipper.ReleaseLabel, rel.Name, name)
+	}
+
+	if oldRel == nil {
+		return nil
+	}
+
+	for _, label := range []string{shipper.AppLabel, shipper.ReleaseLabel} {
+		old, ok := oldRel.Labels[label]
+		if ok && rel.Labels[label] != old {
+			return fmt.Errorf("label %s can not be changed once set", label)
+		}
+	}
+
+	return nil
+}
+
+// RunsToCompletion returns true if rel runs a Job instead of long-lived
+// Deployments, so it has no traffic to shift.
+func RunsToCompletion(rel *shipper.Release) bool {
+	return rel.Spec.Environment.WorkloadKind == shipper.WorkloadKindJob
+}
+
 func ReleaseAchievedTargetStep(rel *shipper.Release) bool {
 	if rel == nil || rel.Status.AchievedStep == nil {
 		return false
@@ -24,3 +98,77 @@ func IsLastStrategyStep(rel *shipper.Release) bool {
 	numSteps := len(rel.Spec.Environment.Strategy.Steps)
 	return targetStep == int32(numSteps-1)
 }
+
+// ValidateAdditionalCharts ensures that every additional chart of an
+// environment has a name no other chart of the environment uses, the main
+// chart included, so their installation statuses can be told apart.
+func ValidateAdditionalCharts(env *shipper.ReleaseEnvironment) error {
+	seen := map[string]struct{}{env.Chart.Name: struct{}{}}
+	for _, additional := range env.AdditionalCharts {
+		if additional.Name == "" {
+			return fmt.Errorf("additionalCharts entries need a name")
+		}
+		if _, ok := seen[additional.Name]; ok {
+			return fmt.Errorf("chart name %q is used more than once", additional.Name)
+		}
+		seen[additional.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// ValidateDependsOn ensures that an environment of application appName
+// depends on other applications, each at most once.
+func ValidateDependsOn(env *shipper.ReleaseEnvironment, appName string) error {
+	seen := make(map[string]struct{}, len(env.DependsOn))
+	for _, dependency := range env.DependsOn {
+		if dependency == "" {
+			return fmt.Errorf("dependsOn entries need to name an application")
+		}
+		if dependency == appName {
+			return fmt.Errorf("application %q can't depend on itself", appName)
+		}
+		if _, ok := seen[dependency]; ok {
+			return fmt.Errorf("%q is listed more than once in dependsOn", dependency)
+		}
+		seen[dependency] = struct{}{}
+	}
+
+	return nil
+}
+
+// ValidateReadinessBarriers ensures that every readiness barrier of an
+// environment is a kind with a place in the install order, as there would be
+// nothing left to install after any other kind.
+func ValidateReadinessBarriers(env *shipper.ReleaseEnvironment) error {
+	for _, kind := range env.ReadinessBarriers {
+		if shipperchart.InstallOrder.Index(kind) == len(shipperchart.InstallOrder) {
+			return fmt.Errorf("readinessBarriers: %q is not a kind Shipper knows how to order", kind)
+		}
+	}
+
+	return nil
+}
+
+// ValidateWorkloadKind ensures that an environment running a Job doesn't ask
+// for anything that only applies to Deployments.
+func ValidateWorkloadKind(env *shipper.ReleaseEnvironment) error {
+	if env.WorkloadKind != shipper.WorkloadKindJob {
+		return nil
+	}
+
+	if env.ClusterRequirements.Spread != nil {
+		return fmt.Errorf("clusterRequirements.spread can't be used with workloadKind %s", shipper.WorkloadKindJob)
+	}
+	if env.ImageOverride != nil {
+		return fmt.Errorf("imageOverride can't be used with workloadKind %s", shipper.WorkloadKindJob)
+	}
+	if env.PrePullImages {
+		return fmt.Errorf("prePullImages can't be used with workloadKind %s", shipper.WorkloadKindJob)
+	}
+	if env.TopologySpread != nil {
+		return fmt.Errorf("topologySpread can't be used with workloadKind %s", shipper.WorkloadKindJob)
+	}
+
+	return nil
+}
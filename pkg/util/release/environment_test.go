@@ -0,0 +1,284 @@
+package release
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestValidateAdditionalCharts(t *testing.T) {
+	tests := []struct {
+		name             string
+		additionalCharts []shipper.AdditionalChart
+		valid            bool
+	}{
+		{"no additional charts", nil, true},
+		{"valid", []shipper.AdditionalChart{{Name: "config"}, {Name: "cache"}}, true},
+		{"unnamed", []shipper.AdditionalChart{{}}, false},
+		{"duplicate name", []shipper.AdditionalChart{{Name: "config"}, {Name: "config"}}, false},
+		{"main chart's name", []shipper.AdditionalChart{{Name: "nginx"}}, false},
+	}
+
+	for _, tt := range tests {
+		env := &shipper.ReleaseEnvironment{
+			Chart:            shipper.Chart{Name: "nginx"},
+			AdditionalCharts: tt.additionalCharts,
+		}
+		if err := ValidateAdditionalCharts(env); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateDependsOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn []string
+		valid     bool
+	}{
+		{"no dependencies", nil, true},
+		{"valid", []string{"api", "db"}, true},
+		{"empty name", []string{""}, false},
+		{"itself", []string{"frontend"}, false},
+		{"duplicate", []string{"api", "api"}, false},
+	}
+
+	for _, tt := range tests {
+		env := &shipper.ReleaseEnvironment{DependsOn: tt.dependsOn}
+		if err := ValidateDependsOn(env, "frontend"); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateReadinessBarriers(t *testing.T) {
+	tests := []struct {
+		name     string
+		barriers []string
+		valid    bool
+	}{
+		{"no barriers", nil, true},
+		{"valid", []string{"Job", "Deployment"}, true},
+		{"unknown kind", []string{"Widget"}, false},
+	}
+
+	for _, tt := range tests {
+		env := &shipper.ReleaseEnvironment{ReadinessBarriers: tt.barriers}
+		if err := ValidateReadinessBarriers(env); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateEnvironmentUpdate(t *testing.T) {
+	buildRelease := func() *shipper.Release {
+		return &shipper.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{},
+			},
+			Spec: shipper.ReleaseSpec{
+				Environment: shipper.ReleaseEnvironment{
+					Chart:  shipper.Chart{Name: "nginx", Version: "0.0.1"},
+					Values: shipper.ChartValues{"replicaCount": float64(1)},
+					Strategy: &shipper.RolloutStrategy{
+						Preset: "vanguard",
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(rel, oldRel *shipper.Release)
+		valid  bool
+	}{
+		{
+			"target step",
+			func(rel, oldRel *shipper.Release) { rel.Spec.TargetStep = 2 },
+			true,
+		},
+		{
+			"chart",
+			func(rel, oldRel *shipper.Release) { rel.Spec.Environment.Chart.Version = "0.0.2" },
+			false,
+		},
+		{
+			"values",
+			func(rel, oldRel *shipper.Release) {
+				rel.Spec.Environment.Values = shipper.ChartValues{"replicaCount": float64(2)}
+			},
+			false,
+		},
+		{
+			"cluster requirements",
+			func(rel, oldRel *shipper.Release) {
+				rel.Spec.Environment.ClusterRequirements.Regions = []shipper.RegionRequirement{{Name: "eu"}}
+			},
+			false,
+		},
+		{
+			"strategy",
+			func(rel, oldRel *shipper.Release) { rel.Spec.Environment.Strategy.Preset = "canary" },
+			false,
+		},
+		{
+			"strategy preset resolved",
+			func(rel, oldRel *shipper.Release) { ResolveStrategyPreset(rel.Spec.Environment.Strategy) },
+			true,
+		},
+		{
+			"empty environment filled in",
+			func(rel, oldRel *shipper.Release) { oldRel.Spec.Environment = shipper.ReleaseEnvironment{} },
+			true,
+		},
+		{
+			"clusters chosen",
+			func(rel, oldRel *shipper.Release) {
+				rel.Annotations[shipper.ReleaseClustersAnnotation] = "a,b"
+				rel.Spec.ClusterReplicas = []shipper.ClusterReplicas{{Name: "a", Replicas: 1}}
+				rel.Spec.ClusterValues = []shipper.ClusterValues{{Name: "a"}}
+			},
+			true,
+		},
+		{
+			"clusters changed",
+			func(rel, oldRel *shipper.Release) {
+				oldRel.Annotations[shipper.ReleaseClustersAnnotation] = "a,b"
+				rel.Annotations[shipper.ReleaseClustersAnnotation] = "a,c"
+			},
+			false,
+		},
+		{
+			"cluster replicas changed",
+			func(rel, oldRel *shipper.Release) {
+				oldRel.Spec.ClusterReplicas = []shipper.ClusterReplicas{{Name: "a", Replicas: 1}}
+				rel.Spec.ClusterReplicas = []shipper.ClusterReplicas{{Name: "a", Replicas: 2}}
+			},
+			false,
+		},
+		{
+			"cluster values changed",
+			func(rel, oldRel *shipper.Release) {
+				oldRel.Spec.ClusterValues = []shipper.ClusterValues{{Name: "a"}}
+				rel.Spec.ClusterValues = nil
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		rel, oldRel := buildRelease(), buildRelease()
+		tt.modify(rel, oldRel)
+
+		if err := ValidateEnvironmentUpdate(rel, oldRel); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateIdentityLabels(t *testing.T) {
+	buildRelease := func() *shipper.Release {
+		return &shipper.Release{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "reviews-api-deadbeef-0",
+				Labels: map[string]string{
+					shipper.AppLabel:     "reviews-api",
+					shipper.ReleaseLabel: "reviews-api-deadbeef-0",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(rel, oldRel *shipper.Release)
+		valid  bool
+	}{
+		{
+			"unchanged",
+			func(rel, oldRel *shipper.Release) {},
+			true,
+		},
+		{
+			"release label set on an older release",
+			func(rel, oldRel *shipper.Release) { delete(oldRel.Labels, shipper.ReleaseLabel) },
+			true,
+		},
+		{
+			"release label naming another release",
+			func(rel, oldRel *shipper.Release) {
+				rel.Labels[shipper.ReleaseLabel] = "reviews-api-cafebabe-0"
+				oldRel.Labels[shipper.ReleaseLabel] = "reviews-api-cafebabe-0"
+			},
+			false,
+		},
+		{
+			"release label removed",
+			func(rel, oldRel *shipper.Release) { delete(rel.Labels, shipper.ReleaseLabel) },
+			false,
+		},
+		{
+			"app label changed",
+			func(rel, oldRel *shipper.Release) { rel.Labels[shipper.AppLabel] = "ratings-api" },
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		rel, oldRel := buildRelease(), buildRelease()
+		tt.modify(rel, oldRel)
+
+		if err := ValidateIdentityLabels(rel, oldRel); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+
+	rel := buildRelease()
+	rel.Labels[shipper.ReleaseLabel] = "reviews-api-cafebabe-0"
+	if err := ValidateIdentityLabels(rel, nil); err == nil {
+		t.Errorf("expected a new release labelled as another release to be rejected")
+	}
+}
+
+func TestValidateWorkloadKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   shipper.ReleaseEnvironment
+		valid bool
+	}{
+		{"service", shipper.ReleaseEnvironment{PrePullImages: true}, true},
+		{"job", shipper.ReleaseEnvironment{WorkloadKind: shipper.WorkloadKindJob}, true},
+		{
+			"job with spread",
+			shipper.ReleaseEnvironment{
+				WorkloadKind: shipper.WorkloadKindJob,
+				ClusterRequirements: shipper.ClusterRequirements{
+					Spread: &shipper.ClusterSpread{Strategy: shipper.ClusterSpreadEven},
+				},
+			},
+			false,
+		},
+		{
+			"job with image override",
+			shipper.ReleaseEnvironment{
+				WorkloadKind:  shipper.WorkloadKindJob,
+				ImageOverride: &shipper.ImageOverride{Image: "busybox:stable"},
+			},
+			false,
+		},
+		{
+			"job with pre-pulled images",
+			shipper.ReleaseEnvironment{WorkloadKind: shipper.WorkloadKindJob, PrePullImages: true},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateWorkloadKind(&tt.env); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package release
+
+import (
+	"sort"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// InventoryEntry is a release serving traffic in one of its clusters.
+type InventoryEntry struct {
+	Namespace    string `json:"namespace"`
+	Application  string `json:"application"`
+	Release      string `json:"release"`
+	ChartVersion string `json:"chartVersion"`
+	Cluster      string `json:"cluster"`
+	Region       string `json:"region"`
+	// Weight is the traffic weight the release achieved in the cluster,
+	// relative to the weights of the other releases of its application.
+	Weight int32 `json:"weight"`
+}
+
+// Inventory lists, for every application among releases, the releases that
+// serve traffic and the clusters they serve it in. What each release
+// serves in a cluster is the traffic weight the release controller last
+// saw its traffic target there report, so clusters out of traffic, ones
+// that haven't converged, and instant rollbacks are accounted for as they
+// are. Entries are sorted by namespace, application, cluster and release.
+func Inventory(releases []*shipper.Release, clusters []*shipper.Cluster) []InventoryEntry {
+	regions := make(map[string]string, len(clusters))
+	for _, cluster := range clusters {
+		regions[cluster.Name] = cluster.Spec.Region
+	}
+
+	var entries []InventoryEntry
+	for _, rel := range releases {
+		appName, ok := rel.Labels[shipper.AppLabel]
+		if !ok {
+			continue
+		}
+
+		weights := achievedTraffic(rel)
+		for _, clusterName := range GetSelectedClusters(rel) {
+			weight := weights[clusterName]
+			if weight <= 0 {
+				continue
+			}
+
+			entries = append(entries, InventoryEntry{
+				Namespace:    rel.Namespace,
+				Application:  appName,
+				Release:      rel.Name,
+				ChartVersion: rel.Spec.Environment.Chart.Version,
+				Cluster:      clusterName,
+				Region:       regions[clusterName],
+				Weight:       weight,
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Application != b.Application {
+			return a.Application < b.Application
+		}
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		return a.Release < b.Release
+	})
+
+	return entries
+}
+
+// achievedTraffic returns the traffic weight rel serves in each of the
+// clusters its traffic targets reported one for.
+func achievedTraffic(rel *shipper.Release) map[string]int32 {
+	weights := make(map[string]int32)
+	if rel.Status.Strategy == nil {
+		return weights
+	}
+
+	for _, clusterStatus := range rel.Status.Strategy.Clusters {
+		if clusterStatus.AchievedTraffic != nil {
+			weights[clusterStatus.Name] = int32(*clusterStatus.AchievedTraffic)
+		}
+	}
+
+	return weights
+}
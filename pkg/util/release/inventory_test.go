@@ -0,0 +1,73 @@
+package release
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestInventory(t *testing.T) {
+	release := func(app, name, generation, version string, traffic map[string]uint32) *shipper.Release {
+		rel := buildRelease("test-namespace", name, generation)
+		rel.Labels[shipper.AppLabel] = app
+		rel.Spec.Environment.Chart.Version = version
+
+		var clusterNames []string
+		status := &shipper.ReleaseStrategyStatus{}
+		for _, clusterName := range []string{"eu-1", "us-1"} {
+			weight, ok := traffic[clusterName]
+			if !ok {
+				continue
+			}
+
+			clusterNames = append(clusterNames, clusterName)
+			status.Clusters = append(status.Clusters, shipper.ClusterStrategyStatus{
+				Name:            clusterName,
+				AchievedTraffic: &weight,
+			})
+		}
+		rel.Annotations[shipper.ReleaseClustersAnnotation] = strings.Join(clusterNames, ",")
+		rel.Status.Strategy = status
+
+		return rel
+	}
+
+	neverSeen := buildRelease("test-namespace", "details-0", "0")
+	neverSeen.Labels[shipper.AppLabel] = "details"
+	neverSeen.Annotations[shipper.ReleaseClustersAnnotation] = "eu-1"
+
+	releases := []*shipper.Release{
+		// us-1 was tolerated as unavailable, and never moved on from
+		// the previous step.
+		release("reviews", "reviews-2", "2", "0.3.0", map[string]uint32{"eu-1": 50, "us-1": 0}),
+		release("reviews", "reviews-1", "1", "0.2.0", map[string]uint32{"eu-1": 50, "us-1": 100}),
+		release("reviews", "reviews-0", "0", "0.1.0", map[string]uint32{"eu-1": 0, "us-1": 0}),
+		// ratings-1 was rolled back instantly, after it achieved its
+		// last step.
+		release("ratings", "ratings-1", "1", "1.1.0", map[string]uint32{"us-1": 0}),
+		release("ratings", "ratings-0", "0", "1.0.0", map[string]uint32{"us-1": 100}),
+		// eu-1 is out of traffic.
+		release("search", "search-0", "0", "2.0.0", map[string]uint32{"eu-1": 0, "us-1": 100}),
+		neverSeen,
+	}
+	clusters := []*shipper.Cluster{
+		{Spec: shipper.ClusterSpec{Region: "eu"}},
+		{Spec: shipper.ClusterSpec{Region: "us"}},
+	}
+	clusters[0].Name = "eu-1"
+	clusters[1].Name = "us-1"
+
+	expected := []InventoryEntry{
+		{"test-namespace", "ratings", "ratings-0", "1.0.0", "us-1", "us", 100},
+		{"test-namespace", "reviews", "reviews-1", "0.2.0", "eu-1", "eu", 50},
+		{"test-namespace", "reviews", "reviews-2", "0.3.0", "eu-1", "eu", 50},
+		{"test-namespace", "reviews", "reviews-1", "0.2.0", "us-1", "us", 100},
+		{"test-namespace", "search", "search-0", "2.0.0", "us-1", "us", 100},
+	}
+
+	if inventory := Inventory(releases, clusters); !reflect.DeepEqual(inventory, expected) {
+		t.Errorf("expected inventory %v, got %v", expected, inventory)
+	}
+}
@@ -0,0 +1,78 @@
+package release
+
+import (
+	"sort"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+func presetStep(name string, capIncumbent, capContender, trafficIncumbent, trafficContender int32) shipper.RolloutStrategyStep {
+	return shipper.RolloutStrategyStep{
+		Name:     name,
+		Capacity: shipper.RolloutStrategyStepValue{Incumbent: capIncumbent, Contender: capContender},
+		Traffic:  shipper.RolloutStrategyStepValue{Incumbent: trafficIncumbent, Contender: trafficContender},
+	}
+}
+
+// strategyPresets are the built-in strategies that can be referred to by
+// name. Changing the steps of a preset changes the environment of every
+// Application using it, which makes them all roll out a new Release, so
+// presets must never change once released. Add new ones instead.
+var strategyPresets = map[string][]shipper.RolloutStrategyStep{
+	// vanguard tries the contender out with a single pod and no
+	// traffic, then sends it half of the traffic before going full on.
+	"vanguard": {
+		presetStep("staging", 100, 1, 100, 0),
+		presetStep("50/50", 50, 50, 50, 50),
+		presetStep("full on", 0, 100, 0, 100),
+	},
+	// vanguard-4-step is vanguard with a canary step at 10 percent.
+	"vanguard-4-step": {
+		presetStep("staging", 100, 1, 100, 0),
+		presetStep("canary", 90, 10, 90, 10),
+		presetStep("50/50", 50, 50, 50, 50),
+		presetStep("full on", 0, 100, 0, 100),
+	},
+	// big-bang replaces the incumbent with the contender in one go.
+	"big-bang": {
+		presetStep("full on", 0, 100, 0, 100),
+	},
+	// traffic-first brings the contender up to full capacity next to the
+	// incumbent before moving any traffic to it, so it never has to take
+	// traffic while scaling up.
+	"traffic-first": {
+		presetStep("full capacity", 100, 100, 100, 0),
+		presetStep("50/50", 100, 100, 50, 50),
+		presetStep("full on", 0, 100, 0, 100),
+	},
+}
+
+// StrategyPresets lists the names of the built-in strategies.
+func StrategyPresets() []string {
+	names := make([]string, 0, len(strategyPresets))
+	for name := range strategyPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveStrategyPreset fills in the steps of a strategy that names a preset
+// and has no steps of its own. Strategies with steps are left alone.
+func ResolveStrategyPreset(strategy *shipper.RolloutStrategy) error {
+	if strategy == nil || strategy.Preset == "" || len(strategy.Steps) > 0 {
+		return nil
+	}
+
+	steps, ok := strategyPresets[strategy.Preset]
+	if !ok {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"unknown preset %q, must be one of %v", strategy.Preset, StrategyPresets())
+	}
+
+	strategy.Steps = make([]shipper.RolloutStrategyStep, len(steps))
+	copy(strategy.Steps, steps)
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package release
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+func TestStrategyPresetsAreValid(t *testing.T) {
+	for _, name := range StrategyPresets() {
+		strategy := &shipper.RolloutStrategy{Preset: name}
+		if err := ResolveStrategyPreset(strategy); err != nil {
+			t.Fatalf("preset %q: unexpected error resolving: %s", name, err)
+		}
+
+		if err := ValidateStrategy(strategy); err != nil {
+			t.Errorf("preset %q is not a valid strategy: %s", name, err)
+		}
+	}
+}
+
+func TestResolveStrategyPreset(t *testing.T) {
+	strategy := &shipper.RolloutStrategy{
+		Preset: "big-bang",
+		Steps: []shipper.RolloutStrategyStep{
+			{Name: "custom"},
+		},
+	}
+	if err := ResolveStrategyPreset(strategy); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(strategy.Steps) != 1 || strategy.Steps[0].Name != "custom" {
+		t.Errorf("expected steps to be left alone, got %v", strategy.Steps)
+	}
+
+	strategy = &shipper.RolloutStrategy{Preset: "no-such-preset"}
+	err := ResolveStrategyPreset(strategy)
+	if _, ok := err.(shippererrors.InvalidRolloutStrategyError); !ok {
+		t.Errorf("expected an InvalidRolloutStrategyError, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"fmt"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/util/conditions"
+)
+
+// ValidateInstantRollback ensures that contender can be rolled back to
+// incumbent in a single move, which is only safe while incumbent is still
+// kept on the standby of contender's strategy, and is installed and healthy
+// in every one of its clusters. contender is expected to be the latest
+// release of the application, and incumbent the one right before it.
+func ValidateInstantRollback(incumbent, contender *shipper.Release) error {
+	if !ReleaseComplete(incumbent) {
+		return fmt.Errorf("release %q never completed its rollout", incumbent.Name)
+	}
+
+	strategy := contender.Spec.Environment.Strategy
+	if strategy == nil || strategy.IncumbentStandby == nil {
+		return fmt.Errorf("the strategy of release %q keeps no incumbent on standby", contender.Name)
+	}
+	if _, ended := incumbent.Annotations[shipper.StandbyEndedAnnotation]; ended {
+		return fmt.Errorf("the standby of release %q has already ended", incumbent.Name)
+	}
+
+	if contender.Spec.TargetStep == 0 {
+		return fmt.Errorf("release %q is already at its first step", contender.Name)
+	}
+
+	status := incumbent.Status.Strategy
+	if status == nil {
+		return fmt.Errorf("release %q has no strategy status", incumbent.Name)
+	}
+
+	clusters := GetSelectedClusters(incumbent)
+	if len(clusters) == 0 {
+		return fmt.Errorf("release %q isn't scheduled on any cluster", incumbent.Name)
+	}
+
+	unavailable := make(map[string]bool, len(status.UnavailableClusters))
+	for _, clusterName := range status.UnavailableClusters {
+		unavailable[clusterName] = true
+	}
+
+	for _, clusterName := range clusters {
+		if unavailable[clusterName] {
+			return fmt.Errorf("cluster %q of release %q is unavailable", clusterName, incumbent.Name)
+		}
+
+		var clusterStatus *shipper.ClusterStrategyStatus
+		for i := range status.Clusters {
+			if status.Clusters[i].Name == clusterName {
+				clusterStatus = &status.Clusters[i]
+				break
+			}
+		}
+		if clusterStatus == nil {
+			return fmt.Errorf("release %q has no status for cluster %q", incumbent.Name, clusterName)
+		}
+
+		cond := conditions.NewStrategyConditions(clusterStatus.Conditions...)
+		if !cond.IsTrue(
+			shipper.StrategyConditionContenderAchievedInstallation,
+			shipper.StrategyConditionContenderAchievedCapacity,
+		) {
+			return fmt.Errorf("release %q is not installed and at capacity in cluster %q",
+				incumbent.Name, clusterName)
+		}
+	}
+
+	return nil
+}
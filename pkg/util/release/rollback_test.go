@@ -0,0 +1,102 @@
+package release
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestValidateInstantRollback(t *testing.T) {
+	healthy := []shipper.ReleaseStrategyCondition{
+		{Type: shipper.StrategyConditionContenderAchievedInstallation, Status: corev1.ConditionTrue},
+		{Type: shipper.StrategyConditionContenderAchievedCapacity, Status: corev1.ConditionTrue},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(incumbent, contender *shipper.Release)
+		valid  bool
+	}{
+		{
+			"on standby and healthy",
+			func(incumbent, contender *shipper.Release) {},
+			true,
+		},
+		{
+			"incumbent never completed",
+			func(incumbent, contender *shipper.Release) {
+				incumbent.Status.Conditions = nil
+			},
+			false,
+		},
+		{
+			"no standby",
+			func(incumbent, contender *shipper.Release) {
+				contender.Spec.Environment.Strategy.IncumbentStandby = nil
+			},
+			false,
+		},
+		{
+			"standby ended",
+			func(incumbent, contender *shipper.Release) {
+				incumbent.Annotations[shipper.StandbyEndedAnnotation] = "2020-01-01T00:00:00Z"
+			},
+			false,
+		},
+		{
+			"contender at its first step",
+			func(incumbent, contender *shipper.Release) {
+				contender.Spec.TargetStep = 0
+			},
+			false,
+		},
+		{
+			"cluster unavailable",
+			func(incumbent, contender *shipper.Release) {
+				incumbent.Status.Strategy.UnavailableClusters = []string{"b"}
+			},
+			false,
+		},
+		{
+			"cluster without status",
+			func(incumbent, contender *shipper.Release) {
+				incumbent.Status.Strategy.Clusters = incumbent.Status.Strategy.Clusters[:1]
+			},
+			false,
+		},
+		{
+			"not at capacity",
+			func(incumbent, contender *shipper.Release) {
+				incumbent.Status.Strategy.Clusters[1].Conditions = healthy[:1]
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		incumbent := buildRelease("test-namespace", "incumbent", "0")
+		incumbent.Annotations[shipper.ReleaseClustersAnnotation] = "a,b"
+		SetReleaseCondition(&incumbent.Status, *NewReleaseCondition(shipper.ReleaseConditionTypeComplete, corev1.ConditionTrue, "", ""))
+		incumbent.Status.Strategy = &shipper.ReleaseStrategyStatus{
+			Clusters: []shipper.ClusterStrategyStatus{
+				{Name: "a", Conditions: healthy},
+				{Name: "b", Conditions: healthy},
+			},
+		}
+
+		contender := buildRelease("test-namespace", "contender", "1")
+		contender.Spec.TargetStep = 2
+		contender.Spec.Environment.Strategy = &shipper.RolloutStrategy{
+			IncumbentStandby: &shipper.IncumbentStandby{Capacity: intstr.FromString("5%")},
+		}
+
+		tt.modify(incumbent, contender)
+
+		if err := ValidateInstantRollback(incumbent, contender); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package release
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// ValidateStrategy checks that a strategy has steps, that no step gives more
+// than 100 percent of capacity or traffic to either release or has an unknown
+// order or malformed hooks or probes, that each step moves towards the
+// contender, and that the last step hands everything over to it unless the
+// strategy has PartialFinalStep set or is an experiment splitting traffic
+// between both releases. It also checks that MaxUnavailableClusters,
+// IncumbentFloor, IncumbentStandby and the MaxUnavailable of each step are
+// positive counts or percentages, and that strategies cutting over give the
+// contender all the traffic in the end.
+func ValidateStrategy(strategy *shipper.RolloutStrategy) error {
+	if len(strategy.Steps) == 0 {
+		return shippererrors.NewInvalidRolloutStrategyError("it has no steps")
+	}
+
+	if maxUnavailable := strategy.MaxUnavailableClusters; maxUnavailable != nil {
+		value, err := intstr.GetValueFromIntOrPercent(maxUnavailable, 100, false)
+		if err != nil || value < 0 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"maxUnavailableClusters must be a positive count or percentage, got %q",
+				maxUnavailable.String())
+		}
+	}
+
+	if floor := strategy.IncumbentFloor; floor != nil {
+		value, err := intstr.GetValueFromIntOrPercent(&floor.Capacity, 100, true)
+		if err != nil || value < 0 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"incumbentFloor.capacity must be a positive count or percentage, got %q",
+				floor.Capacity.String())
+		}
+
+		if floor.SoakDuration.Duration < 0 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"incumbentFloor.soakDuration must not be negative, got %s",
+				floor.SoakDuration.Duration)
+		}
+	}
+
+	if standby := strategy.IncumbentStandby; standby != nil {
+		value, err := intstr.GetValueFromIntOrPercent(&standby.Capacity, 100, true)
+		if err != nil || value < 0 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"incumbentStandby.capacity must be a positive count or percentage, got %q",
+				standby.Capacity.String())
+		}
+
+		if standby.Retention.Duration < 0 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"incumbentStandby.retention must not be negative, got %s",
+				standby.Retention.Duration)
+		}
+	}
+
+	for i, step := range strategy.Steps {
+		for _, v := range []struct {
+			name  string
+			value int32
+		}{
+			{"capacity.incumbent", step.Capacity.Incumbent},
+			{"capacity.contender", step.Capacity.Contender},
+			{"traffic.incumbent", step.Traffic.Incumbent},
+			{"traffic.contender", step.Traffic.Contender},
+			{"shadowTraffic", step.ShadowTraffic},
+		} {
+			if v.value < 0 || v.value > 100 {
+				return shippererrors.NewInvalidRolloutStrategyError(
+					"step %d (%q): %s must be between 0 and 100, got %d",
+					i, step.Name, v.name, v.value)
+			}
+		}
+
+		switch step.Order {
+		case "", shipper.RolloutStrategyStepCapacityFirst,
+			shipper.RolloutStrategyStepTrafficFirst, shipper.RolloutStrategyStepParallel:
+		default:
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q): unknown order %q", i, step.Name, step.Order)
+		}
+
+		if maxUnavailable := step.MaxUnavailable; maxUnavailable != nil {
+			value, err := intstr.GetValueFromIntOrPercent(maxUnavailable, 100, false)
+			if err != nil || value < 0 {
+				return shippererrors.NewInvalidRolloutStrategyError(
+					"step %d (%q): maxUnavailable must be a positive count or percentage, got %q",
+					i, step.Name, maxUnavailable.String())
+			}
+		}
+
+		if err := validateStepHooks(i, step); err != nil {
+			return err
+		}
+
+		if err := validateStepProbes(i, step); err != nil {
+			return err
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := strategy.Steps[i-1]
+		if step.Capacity.Contender < prev.Capacity.Contender ||
+			step.Traffic.Contender < prev.Traffic.Contender {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q) gives the contender less than the step before it", i, step.Name)
+		}
+		if step.Capacity.Incumbent > prev.Capacity.Incumbent ||
+			step.Traffic.Incumbent > prev.Traffic.Incumbent {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q) gives the incumbent more than the step before it", i, step.Name)
+		}
+	}
+
+	last := strategy.Steps[len(strategy.Steps)-1]
+	if cutover := strategy.Cutover; cutover != nil {
+		if err := validateCutover(strategy, last); err != nil {
+			return err
+		}
+	}
+
+	if strategy.Experiment != nil {
+		return validateExperiment(strategy.Experiment, last)
+	}
+
+	if !strategy.PartialFinalStep &&
+		(last.Capacity.Contender != 100 || last.Capacity.Incumbent != 0 ||
+			last.Traffic.Contender == 0 || last.Traffic.Incumbent != 0) {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"the last step (%q) must give all capacity and traffic to the contender, "+
+				"or the strategy must set partialFinalStep", last.Name)
+	}
+
+	return nil
+}
+
+// validateExperiment checks that an experiment buckets requests in one way,
+// and that its last step, which is kept for the whole experiment, splits
+// traffic between both releases.
+func validateExperiment(experiment *shipper.RolloutExperiment, last shipper.RolloutStrategyStep) error {
+	bucketing := experiment.Bucketing
+	if (bucketing.Header == "") == (bucketing.Cookie == "") {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"experiment bucketing must have exactly one of header or cookie")
+	}
+
+	if last.Traffic.Contender == 0 || last.Traffic.Incumbent == 0 {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"the last step (%q) of an experiment must give traffic to both releases", last.Name)
+	}
+
+	return nil
+}
+
+// validateCutover checks that a cutover has something to point at the
+// contender's Service, and that the strategy gets to a point where the
+// contender has all the traffic, which experiments never do.
+func validateCutover(strategy *shipper.RolloutStrategy, last shipper.RolloutStrategyStep) error {
+	cutover := strategy.Cutover
+	if len(cutover.Ingresses) == 0 && len(cutover.Hostnames) == 0 {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"cutover must have ingresses or hostnames")
+	}
+
+	for _, name := range append(cutover.Ingresses, cutover.Hostnames...) {
+		if name == "" {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"cutover must not have empty ingresses or hostnames")
+		}
+	}
+
+	if strategy.Experiment != nil {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"experiments never give the contender all the traffic, so they can't cut over")
+	}
+
+	if last.Traffic.Contender == 0 || last.Traffic.Incumbent != 0 {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"the last step (%q) must give all traffic to the contender to cut over to it", last.Name)
+	}
+
+	return nil
+}
+
+func validateStepHooks(i int, step shipper.RolloutStrategyStep) error {
+	seen := map[string]bool{}
+	for _, hook := range append(step.PreHooks, step.PostHooks...) {
+		if hook.Name == "" {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q) has a hook without a name", i, step.Name)
+		}
+
+		if seen[hook.Name] {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q) has more than one hook named %q", i, step.Name, hook.Name)
+		}
+		seen[hook.Name] = true
+
+		if (hook.Template == "") == (hook.Job == nil) {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q): hook %q must have exactly one of template or job",
+				i, step.Name, hook.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateStepProbes(i int, step shipper.RolloutStrategyStep) error {
+	for _, probe := range step.Probes {
+		if probe.Name == "" {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q) has a probe without a name", i, step.Name)
+		}
+
+		if probe.Port < 1 || probe.Port > 65535 {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q): probe %q has invalid port %d", i, step.Name, probe.Name, probe.Port)
+		}
+
+		if probe.Scheme != "" && probe.Scheme != "http" && probe.Scheme != "https" {
+			return shippererrors.NewInvalidRolloutStrategyError(
+				"step %d (%q): probe %q has unknown scheme %q", i, step.Name, probe.Name, probe.Scheme)
+		}
+	}
+
+	return nil
+}
+
+// ValidateStrategyRef checks that a strategy referring to a Strategy names
+// one and leaves everything else to it. The namespace and generation it was
+// copied from are only set by Shipper, in releases.
+func ValidateStrategyRef(strategy *shipper.RolloutStrategy) error {
+	ref := strategy.StrategyRef
+	if ref.Name == "" {
+		return shippererrors.NewInvalidRolloutStrategyError("strategyRef.name must not be empty")
+	}
+
+	expected := shipper.RolloutStrategy{
+		StrategyRef: &shipper.StrategyRef{Name: ref.Name},
+	}
+	if !reflect.DeepEqual(*strategy, expected) {
+		return shippererrors.NewInvalidRolloutStrategyError(
+			"strategyRef can not be combined with other fields")
+	}
+
+	return nil
+}
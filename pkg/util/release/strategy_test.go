@@ -0,0 +1,344 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+func TestValidateStrategy(t *testing.T) {
+	step := func(name string, capIncumbent, capContender, trafficIncumbent, trafficContender int32) shipper.RolloutStrategyStep {
+		return shipper.RolloutStrategyStep{
+			Name:     name,
+			Capacity: shipper.RolloutStrategyStepValue{Incumbent: capIncumbent, Contender: capContender},
+			Traffic:  shipper.RolloutStrategyStepValue{Incumbent: trafficIncumbent, Contender: trafficContender},
+		}
+	}
+
+	badMaxUnavailable := intstr.FromString("a third")
+	stepMaxUnavailable := intstr.FromString("10%")
+
+	tests := []struct {
+		name     string
+		strategy shipper.RolloutStrategy
+		valid    bool
+	}{
+		{
+			name: "vanguard",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				step("staging", 100, 1, 100, 0),
+				step("50/50", 50, 50, 50, 50),
+				step("full on", 0, 100, 0, 100),
+			}},
+			valid: true,
+		},
+		{
+			name:     "no steps",
+			strategy: shipper.RolloutStrategy{},
+		},
+		{
+			name: "capacity over 100",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				step("full on", 0, 101, 0, 100),
+			}},
+		},
+		{
+			name: "shadow traffic over 100",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:          "full on",
+					Capacity:      shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:       shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					ShadowTraffic: 150,
+				},
+			}},
+		},
+		{
+			name: "unknown order",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:     "full on",
+					Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Order:    "sideways",
+				},
+			}},
+		},
+		{
+			name: "hook without a job",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:     "full on",
+					Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					PreHooks: []shipper.StepHook{{Name: "migrate"}},
+				},
+			}},
+		},
+		{
+			name: "probe without a port",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:     "full on",
+					Capacity: shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:  shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Probes:   []shipper.StepProbe{{Name: "health", Path: "/health"}},
+				},
+			}},
+		},
+		{
+			name: "malformed maxUnavailableClusters",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				MaxUnavailableClusters: &badMaxUnavailable,
+			},
+		},
+		{
+			name: "malformed incumbentFloor",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				IncumbentFloor: &shipper.IncumbentFloor{
+					Capacity: intstr.FromString("half"),
+				},
+			},
+		},
+		{
+			name: "contender goes back",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				step("50/50", 50, 50, 50, 50),
+				step("staging", 100, 1, 100, 0),
+				step("full on", 0, 100, 0, 100),
+			}},
+		},
+		{
+			name: "incumbent keeps traffic",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				step("staging", 100, 1, 100, 0),
+				step("50/50", 50, 50, 50, 50),
+			}},
+		},
+		{
+			name: "partial final step is allowed when flagged",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("staging", 100, 1, 100, 0),
+					step("50/50", 50, 50, 50, 50),
+				},
+				PartialFinalStep: true,
+			},
+			valid: true,
+		},
+		{
+			name: "experiment splitting traffic",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("staging", 100, 1, 100, 0),
+					step("experiment", 100, 100, 90, 10),
+				},
+				Experiment: &shipper.RolloutExperiment{
+					Bucketing: shipper.ExperimentBucketing{Cookie: "bucket"},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "experiment handing everything over",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				Experiment: &shipper.RolloutExperiment{
+					Bucketing: shipper.ExperimentBucketing{Cookie: "bucket"},
+				},
+			},
+		},
+		{
+			name: "experiment without bucketing",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("experiment", 100, 100, 90, 10),
+				},
+				Experiment: &shipper.RolloutExperiment{},
+			},
+		},
+		{
+			name: "experiment bucketing by header and cookie",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("experiment", 100, 100, 90, 10),
+				},
+				Experiment: &shipper.RolloutExperiment{
+					Bucketing: shipper.ExperimentBucketing{Header: "X-User-Id", Cookie: "bucket"},
+				},
+			},
+		},
+		{
+			name: "step max unavailable",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:           "full on",
+					Capacity:       shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:        shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					MaxUnavailable: &stepMaxUnavailable,
+				},
+			}},
+			valid: true,
+		},
+		{
+			name: "step max unavailable not a percentage",
+			strategy: shipper.RolloutStrategy{Steps: []shipper.RolloutStrategyStep{
+				{
+					Name:           "full on",
+					Capacity:       shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					Traffic:        shipper.RolloutStrategyStepValue{Incumbent: 0, Contender: 100},
+					MaxUnavailable: &badMaxUnavailable,
+				},
+			}},
+		},
+		{
+			name: "incumbent standby",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				IncumbentStandby: &shipper.IncumbentStandby{
+					Capacity:  intstr.FromString("5%"),
+					Retention: metav1.Duration{Duration: time.Hour},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "incumbent standby with negative retention",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				IncumbentStandby: &shipper.IncumbentStandby{
+					Capacity:  intstr.FromInt(1),
+					Retention: metav1.Duration{Duration: -time.Hour},
+				},
+			},
+		},
+		{
+			name: "cutover",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				Cutover: &shipper.RolloutCutover{
+					Ingresses: []string{"reviews"},
+					Hostnames: []string{"reviews.example.com"},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "cutover with nothing to cut over",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("full on", 0, 100, 0, 100),
+				},
+				Cutover: &shipper.RolloutCutover{},
+			},
+		},
+		{
+			name: "cutover leaving traffic to the incumbent",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("canary", 100, 10, 90, 10),
+				},
+				PartialFinalStep: true,
+				Cutover: &shipper.RolloutCutover{
+					Hostnames: []string{"reviews.example.com"},
+				},
+			},
+		},
+		{
+			name: "cutover in an experiment",
+			strategy: shipper.RolloutStrategy{
+				Steps: []shipper.RolloutStrategyStep{
+					step("experiment", 100, 100, 90, 10),
+				},
+				Experiment: &shipper.RolloutExperiment{
+					Bucketing: shipper.ExperimentBucketing{Cookie: "bucket"},
+				},
+				Cutover: &shipper.RolloutCutover{
+					Ingresses: []string{"reviews"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateStrategy(&tt.strategy)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected no error, got %s", tt.name, err)
+		} else if !tt.valid {
+			if _, ok := err.(shippererrors.InvalidRolloutStrategyError); !ok {
+				t.Errorf("%s: expected an InvalidRolloutStrategyError, got %v", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestValidateStrategyRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy shipper.RolloutStrategy
+		valid    bool
+	}{
+		{
+			name: "reference only",
+			strategy: shipper.RolloutStrategy{
+				StrategyRef: &shipper.StrategyRef{Name: "slow"},
+			},
+			valid: true,
+		},
+		{
+			name: "no name",
+			strategy: shipper.RolloutStrategy{
+				StrategyRef: &shipper.StrategyRef{},
+			},
+		},
+		{
+			name: "reference with a preset",
+			strategy: shipper.RolloutStrategy{
+				Preset:      "vanguard",
+				StrategyRef: &shipper.StrategyRef{Name: "slow"},
+			},
+		},
+		{
+			name: "reference with a namespace",
+			strategy: shipper.RolloutStrategy{
+				StrategyRef: &shipper.StrategyRef{Name: "slow", Namespace: "strategies-global"},
+			},
+		},
+		{
+			name: "reference with a generation",
+			strategy: shipper.RolloutStrategy{
+				StrategyRef: &shipper.StrategyRef{Name: "slow", Generation: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateStrategyRef(&tt.strategy)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected no error, got %s", tt.name, err)
+		} else if !tt.valid {
+			if _, ok := err.(shippererrors.InvalidRolloutStrategyError); !ok {
+				t.Errorf("%s: expected an InvalidRolloutStrategyError, got %v", tt.name, err)
+			}
+		}
+	}
+}
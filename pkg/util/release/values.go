@@ -0,0 +1,146 @@
+package release
+
+import (
+	"fmt"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	"github.com/bookingcom/shipper/pkg/chart/valuesource"
+)
+
+// MergeValues merges layers of chart values in order, later layers taking
+// precedence. Maps are merged key by key, anything else, lists included, is
+// replaced. None of the layers is modified.
+func MergeValues(layers ...shipper.ChartValues) shipper.ChartValues {
+	merged := map[string]interface{}{}
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		layer = layer.DeepCopy()
+		mergeMaps(merged, layer)
+	}
+
+	return shipper.ChartValues(merged)
+}
+
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// EnvironmentValues returns an environment's base values with the overlay
+// for its environment merged over them.
+func EnvironmentValues(env *shipper.ReleaseEnvironment) shipper.ChartValues {
+	overlays := env.ValuesOverlays
+	if overlays == nil {
+		return env.Values
+	}
+
+	return MergeValues(env.Values, findOverlay(overlays.Environments, overlays.Environment))
+}
+
+// ClusterValues returns the values an environment renders its chart with in
+// a cluster: its base values, then the overlay for its environment, then the
+// overlay for the cluster.
+func ClusterValues(env *shipper.ReleaseEnvironment, clusterName string) shipper.ChartValues {
+	overlays := env.ValuesOverlays
+	if overlays == nil {
+		return env.Values
+	}
+
+	return MergeValues(
+		env.Values,
+		findOverlay(overlays.Environments, overlays.Environment),
+		findOverlay(overlays.Clusters, clusterName),
+	)
+}
+
+// GetClusterValues returns the values a release renders its chart with in a
+// cluster, as recorded when it was scheduled.
+func GetClusterValues(rel *shipper.Release, clusterName string) shipper.ChartValues {
+	for _, cluster := range rel.Spec.ClusterValues {
+		if cluster.Name == clusterName {
+			return cluster.Values
+		}
+	}
+
+	return ClusterValues(&rel.Spec.Environment, clusterName)
+}
+
+func findOverlay(overlays []shipper.ValuesOverlay, name string) shipper.ChartValues {
+	if name == "" {
+		return nil
+	}
+
+	for _, overlay := range overlays {
+		if overlay.Name == name {
+			return overlay.Values
+		}
+	}
+
+	return nil
+}
+
+// ValidateValuesFrom ensures that every value an environment reads from a
+// secret store has a path and exactly one source.
+func ValidateValuesFrom(env *shipper.ReleaseEnvironment) error {
+	for _, source := range env.ValuesFrom {
+		if err := valuesource.Validate(source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateValuesOverlays ensures that an environment's values overlays name
+// every environment and cluster at most once, and that the environment it
+// picks exists.
+func ValidateValuesOverlays(env *shipper.ReleaseEnvironment) error {
+	overlays := env.ValuesOverlays
+	if overlays == nil {
+		return nil
+	}
+
+	if err := validateOverlayNames("environments", overlays.Environments); err != nil {
+		return err
+	}
+
+	if err := validateOverlayNames("clusters", overlays.Clusters); err != nil {
+		return err
+	}
+
+	if overlays.Environment == "" {
+		return nil
+	}
+
+	for _, overlay := range overlays.Environments {
+		if overlay.Name == overlays.Environment {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("valuesOverlays has no overlay for environment %q", overlays.Environment)
+}
+
+func validateOverlayNames(field string, overlays []shipper.ValuesOverlay) error {
+	seen := make(map[string]struct{}, len(overlays))
+	for _, overlay := range overlays {
+		if overlay.Name == "" {
+			return fmt.Errorf("valuesOverlays.%s entries need a name", field)
+		}
+		if _, ok := seen[overlay.Name]; ok {
+			return fmt.Errorf("%q is listed more than once in valuesOverlays.%s", overlay.Name, field)
+		}
+		seen[overlay.Name] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func buildLayeredEnvironment() *shipper.ReleaseEnvironment {
+	return &shipper.ReleaseEnvironment{
+		Values: shipper.ChartValues{
+			"replicaCount": float64(2),
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "stable",
+			},
+			"args": []interface{}{"--base"},
+		},
+		ValuesOverlays: &shipper.ValuesOverlays{
+			Environment: "production",
+			Environments: []shipper.ValuesOverlay{
+				{
+					Name: "staging",
+					Values: shipper.ChartValues{
+						"replicaCount": float64(1),
+					},
+				},
+				{
+					Name: "production",
+					Values: shipper.ChartValues{
+						"replicaCount": float64(10),
+						"args":         []interface{}{"--production"},
+					},
+				},
+			},
+			Clusters: []shipper.ValuesOverlay{
+				{
+					Name: "cluster-a",
+					Values: shipper.ChartValues{
+						"image": map[string]interface{}{
+							"tag": "canary",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestClusterValues(t *testing.T) {
+	env := buildLayeredEnvironment()
+	original := env.DeepCopy()
+
+	tests := []struct {
+		cluster  string
+		expected shipper.ChartValues
+	}{
+		{
+			cluster: "cluster-a",
+			expected: shipper.ChartValues{
+				"replicaCount": float64(10),
+				"image": map[string]interface{}{
+					"repository": "nginx",
+					"tag":        "canary",
+				},
+				"args": []interface{}{"--production"},
+			},
+		},
+		{
+			cluster: "cluster-b",
+			expected: shipper.ChartValues{
+				"replicaCount": float64(10),
+				"image": map[string]interface{}{
+					"repository": "nginx",
+					"tag":        "stable",
+				},
+				"args": []interface{}{"--production"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		if values := ClusterValues(env, tt.cluster); !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.cluster, tt.expected, values)
+		}
+	}
+
+	if !reflect.DeepEqual(env, original) {
+		t.Errorf("merging values must not modify the environment")
+	}
+
+	if values := EnvironmentValues(env); !reflect.DeepEqual(values, tests[1].expected) {
+		t.Errorf("expected environment values %v, got %v", tests[1].expected, values)
+	}
+}
+
+func TestGetClusterValuesPrefersRecordedValues(t *testing.T) {
+	rel := buildRelease("test-namespace", "test-release", "0")
+	rel.Spec.Environment = *buildLayeredEnvironment()
+	recorded := shipper.ChartValues{"replicaCount": float64(3)}
+	rel.Spec.ClusterValues = []shipper.ClusterValues{
+		{Name: "cluster-a", Values: recorded},
+	}
+
+	if values := GetClusterValues(rel, "cluster-a"); !reflect.DeepEqual(values, recorded) {
+		t.Errorf("expected recorded values %v, got %v", recorded, values)
+	}
+
+	if values := GetClusterValues(rel, "cluster-b"); values["replicaCount"] != float64(10) {
+		t.Errorf("expected values merged from the environment for an unrecorded cluster, got %v", values)
+	}
+}
+
+func TestValidateValuesOverlays(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlays *shipper.ValuesOverlays
+		valid    bool
+	}{
+		{"no overlays", nil, true},
+		{"valid", buildLayeredEnvironment().ValuesOverlays, true},
+		{
+			"unknown environment",
+			&shipper.ValuesOverlays{Environment: "qa"},
+			false,
+		},
+		{
+			"duplicate cluster",
+			&shipper.ValuesOverlays{Clusters: []shipper.ValuesOverlay{{Name: "cluster-a"}, {Name: "cluster-a"}}},
+			false,
+		},
+		{
+			"unnamed environment",
+			&shipper.ValuesOverlays{Environments: []shipper.ValuesOverlay{{}}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		env := &shipper.ReleaseEnvironment{ValuesOverlays: tt.overlays}
+		if err := ValidateValuesOverlays(env); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
 	shipperlisters "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
 	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	shipperevents "github.com/bookingcom/shipper/pkg/events"
 )
 
 type RolloutBlockEvent struct {
@@ -48,7 +49,7 @@ func BlocksRollout(rolloutBlockLister shipperlisters.RolloutBlockLister, obj met
 		if len(overrides) > 0 {
 			events = append(events, RolloutBlockEvent{
 				corev1.EventTypeNormal,
-				"RolloutBlockOverridden",
+				shipperevents.RolloutBlockOverridden,
 				overrides.String()})
 		}
 
@@ -56,7 +57,7 @@ func BlocksRollout(rolloutBlockLister shipperlisters.RolloutBlockLister, obj met
 	} else {
 		events = append(events, RolloutBlockEvent{
 			corev1.EventTypeWarning,
-			"RolloutBlocked",
+			shipperevents.RolloutBlocked,
 			effectiveBlocks.String()})
 		return true, events, shippererrors.NewRolloutBlockError(effectiveBlocks.String())
 	}
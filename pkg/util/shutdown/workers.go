@@ -0,0 +1,87 @@
+// Package shutdown lets controllers stop without abandoning syncs halfway
+// through: once asked to stop, workers finish the item they are working on
+// and pick up no more, so no object is left with a half-updated status.
+package shutdown
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// DefaultDrainTimeout is how long controllers wait for in-flight syncs to
+// finish once they are asked to stop, unless told otherwise.
+const DefaultDrainTimeout = 30 * time.Second
+
+// Workers runs the workers of a controller and drains them on shutdown.
+type Workers struct {
+	name   string
+	wg     sync.WaitGroup
+	queues []workqueue.Interface
+}
+
+// NewWorkers returns a worker pool for the controller called name.
+func NewWorkers(name string) *Workers {
+	return &Workers{name: name}
+}
+
+// Start runs threadiness workers that call processNextWorkItem for items in
+// queue until stopCh is closed or the queue is shut down.
+func (w *Workers) Start(
+	queue workqueue.Interface,
+	threadiness int,
+	processNextWorkItem func() bool,
+	stopCh <-chan struct{},
+) {
+	w.queues = append(w.queues, queue)
+
+	for i := 0; i < threadiness; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+
+				if !processNextWorkItem() {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Drain shuts down the workers' queues, so they stop handing out items, and
+// waits up to timeout for the syncs in flight to finish. Items still queued
+// are dropped: informers will list them again on the next start.
+func (w *Workers) Drain(timeout time.Duration) {
+	start := time.Now()
+
+	// Shutting the queues down wakes up idle workers, which would
+	// otherwise wait for an item forever.
+	queued := 0
+	for _, queue := range w.queues {
+		queued += queue.Len()
+		queue.ShutDown()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		klog.Infof("%s: in-flight syncs finished in %s, dropped %d queued items",
+			w.name, time.Since(start).Round(time.Millisecond), queued)
+	case <-time.After(timeout):
+		klog.Warningf("%s: gave up waiting for in-flight syncs after %s, dropped %d queued items",
+			w.name, timeout, queued)
+	}
+}
@@ -0,0 +1,50 @@
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestDrainFinishesInFlightSyncs(t *testing.T) {
+	queue := workqueue.New()
+	stopCh := make(chan struct{})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	processed := 0
+
+	workers := NewWorkers("test")
+	workers.Start(queue, 1, func() bool {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return false
+		}
+		defer queue.Done(item)
+
+		if processed == 0 {
+			close(started)
+			<-release
+		}
+		processed++
+
+		return true
+	}, stopCh)
+
+	queue.Add("in-flight")
+	<-started
+	queue.Add("queued")
+
+	close(stopCh)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	workers.Drain(time.Minute)
+
+	if processed != 1 {
+		t.Fatalf("expected only the in-flight item to be processed, got %d", processed)
+	}
+}
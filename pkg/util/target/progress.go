@@ -0,0 +1,38 @@
+package target
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+const (
+	ProgressingReasonInProgress     = "InProgress"
+	ProgressingReasonConverged      = "Converged"
+	ProgressingReasonNotOperational = "NotOperational"
+)
+
+// SetProgressing sets the Progressing condition of a target from its
+// Operational and Ready conditions. It's True while the target is
+// operational but not ready yet, and False once it's ready or when it can't
+// make progress at all. Its message is progress, telling how far along the
+// target is, which is why its changes aren't worth an event of their own.
+func SetProgressing(conditions []shipper.TargetCondition, progress string) []shipper.TargetCondition {
+	status, reason := corev1.ConditionUnknown, ""
+
+	operational := GetTargetCondition(conditions, shipper.TargetConditionTypeOperational)
+	ready := GetTargetCondition(conditions, shipper.TargetConditionTypeReady)
+	switch {
+	case operational != nil && operational.Status == corev1.ConditionFalse:
+		status, reason = corev1.ConditionFalse, ProgressingReasonNotOperational
+	case ready != nil && ready.Status == corev1.ConditionTrue:
+		status, reason = corev1.ConditionFalse, ProgressingReasonConverged
+	case ready != nil && ready.Status == corev1.ConditionFalse:
+		status, reason = corev1.ConditionTrue, ProgressingReasonInProgress
+	}
+
+	conditions, _ = SetTargetCondition(conditions, NewTargetCondition(
+		shipper.TargetConditionTypeProgressing, status, reason, progress))
+
+	return conditions
+}
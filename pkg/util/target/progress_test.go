@@ -0,0 +1,43 @@
+package target
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestSetProgressing(t *testing.T) {
+	tests := []struct {
+		name        string
+		operational corev1.ConditionStatus
+		ready       corev1.ConditionStatus
+		status      corev1.ConditionStatus
+		reason      string
+	}{
+		{"in progress", corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionTrue, ProgressingReasonInProgress},
+		{"converged", corev1.ConditionTrue, corev1.ConditionTrue, corev1.ConditionFalse, ProgressingReasonConverged},
+		{"not operational", corev1.ConditionFalse, corev1.ConditionUnknown, corev1.ConditionFalse, ProgressingReasonNotOperational},
+		{"not synced yet", corev1.ConditionUnknown, corev1.ConditionUnknown, corev1.ConditionUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		conditions := []shipper.TargetCondition{
+			NewTargetCondition(shipper.TargetConditionTypeOperational, tt.operational, "", ""),
+			NewTargetCondition(shipper.TargetConditionTypeReady, tt.ready, "", ""),
+		}
+
+		conditions = SetProgressing(conditions, "1/2 done")
+
+		cond := GetTargetCondition(conditions, shipper.TargetConditionTypeProgressing)
+		if cond == nil {
+			t.Errorf("%s: expected a Progressing condition", tt.name)
+			continue
+		}
+
+		if cond.Status != tt.status || cond.Reason != tt.reason || cond.Message != "1/2 done" {
+			t.Errorf("%s: expected Progressing to be %s with reason %q, got %+v", tt.name, tt.status, tt.reason, cond)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package target
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+// SyncHeartbeatPeriod is how often target controllers revisit a target, and
+// record that they did in its status, when nothing else prompts them to.
+const SyncHeartbeatPeriod = 5 * time.Minute
+
+// RecordSync updates a target's sync times after a sync that did or did not
+// change its status, and returns whether the status needs to be written
+// back. Syncs that change nothing are only written once every
+// SyncHeartbeatPeriod, so heartbeats don't cause a write on every sync.
+func RecordSync(status *shipper.TargetSyncStatus, changed bool) bool {
+	if ConditionsShouldDiscardTimestamps {
+		return changed
+	}
+
+	now := metav1.Now()
+	if changed {
+		status.LastTransitionTime = &now
+		status.LastSyncTime = &now
+		return true
+	}
+
+	if status.LastSyncTime == nil || now.Sub(status.LastSyncTime.Time) >= SyncHeartbeatPeriod {
+		status.LastSyncTime = &now
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package target
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+)
+
+func TestRecordSync(t *testing.T) {
+	status := &shipper.TargetSyncStatus{}
+	if !RecordSync(status, true) {
+		t.Fatalf("expected a changed status to be written")
+	}
+	if status.LastTransitionTime == nil || status.LastSyncTime == nil {
+		t.Fatalf("expected both sync times to be set, got %+v", status)
+	}
+
+	transition := status.LastTransitionTime
+	if RecordSync(status, false) {
+		t.Fatalf("expected an unchanged status not to be written before the heartbeat is due")
+	}
+
+	stale := metav1.NewTime(time.Now().Add(-SyncHeartbeatPeriod))
+	status.LastSyncTime = &stale
+	if !RecordSync(status, false) {
+		t.Fatalf("expected an unchanged status to be written once the heartbeat is due")
+	}
+	if !status.LastSyncTime.After(stale.Time) {
+		t.Fatalf("expected the heartbeat to bump lastSyncTime")
+	}
+	if status.LastTransitionTime != transition {
+		t.Fatalf("expected a heartbeat not to change lastTransitionTime")
+	}
+}
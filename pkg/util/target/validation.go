@@ -0,0 +1,111 @@
+package target
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+// ValidateCapacityTargetSpec checks that percentages in a CapacityTarget
+// spec are within 0 and 100, that replica counts aren't negative, that the
+// release it scales is named in full with valid label values, and that
+// workloads and clusters are listed only once. If isKnownCluster is not nil,
+// every listed cluster must also be known to it.
+func ValidateCapacityTargetSpec(spec *shipper.CapacityTargetSpec, isKnownCluster func(string) (bool, error)) error {
+	if (spec.Application == "") != (spec.Release == "") {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"application and release must be set together, got %q and %q", spec.Application, spec.Release)
+	}
+
+	if errs := validation.IsValidLabelValue(spec.Application); len(errs) > 0 {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"application %q is not a valid label value: %s", spec.Application, strings.Join(errs, ", "))
+	}
+
+	if errs := validation.IsValidLabelValue(spec.Release); len(errs) > 0 {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"release %q is not a valid label value: %s", spec.Release, strings.Join(errs, ", "))
+	}
+
+	if spec.Percent < 0 || spec.Percent > 100 {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"percent must be between 0 and 100, got %d", spec.Percent)
+	}
+
+	if spec.TotalReplicaCount < 0 {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"totalReplicaCount can not be negative, got %d", spec.TotalReplicaCount)
+	}
+
+	seenWorkloads := make(map[string]struct{}, len(spec.Workloads))
+	for _, workload := range spec.Workloads {
+		if workload.Name == "" {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"workloads need a name")
+		}
+
+		if _, ok := seenWorkloads[workload.Name]; ok {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"workload %q is listed more than once", workload.Name)
+		}
+		seenWorkloads[workload.Name] = struct{}{}
+
+		if workload.TotalReplicaCount < 0 {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"totalReplicaCount for workload %q can not be negative, got %d", workload.Name, workload.TotalReplicaCount)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(spec.Clusters))
+	for _, cluster := range spec.Clusters {
+		if _, ok := seen[cluster.Name]; ok {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"cluster %q is listed more than once", cluster.Name)
+		}
+		seen[cluster.Name] = struct{}{}
+
+		if cluster.Percent < 0 || cluster.Percent > 100 {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"percent for cluster %q must be between 0 and 100, got %d", cluster.Name, cluster.Percent)
+		}
+
+		if cluster.TotalReplicaCount < 0 {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"totalReplicaCount for cluster %q can not be negative, got %d", cluster.Name, cluster.TotalReplicaCount)
+		}
+
+		if isKnownCluster == nil {
+			continue
+		}
+
+		known, err := isKnownCluster(cluster.Name)
+		if err != nil {
+			return err
+		}
+		if !known {
+			return shippererrors.NewInvalidCapacityTargetSpecError(
+				"cluster %q does not exist", cluster.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateCapacityTargetSpecUpdate checks that the release a CapacityTarget
+// scales doesn't change once it's set, so the target can't be pointed at
+// someone else's Deployments.
+func ValidateCapacityTargetSpecUpdate(spec, oldSpec *shipper.CapacityTargetSpec) error {
+	if oldSpec.Release == "" {
+		return nil
+	}
+
+	if spec.Application != oldSpec.Application || spec.Release != oldSpec.Release {
+		return shippererrors.NewInvalidCapacityTargetSpecError(
+			"application and release can not be changed once set")
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package target
+
+import (
+	"testing"
+
+	shipper "github.com/bookingcom/shipper/pkg/apis/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+)
+
+func TestValidateCapacityTargetSpec(t *testing.T) {
+	isKnownCluster := func(name string) (bool, error) {
+		return name != "unknown", nil
+	}
+
+	tests := []struct {
+		name  string
+		spec  shipper.CapacityTargetSpec
+		valid bool
+	}{
+		{
+			name:  "valid spec",
+			spec:  shipper.CapacityTargetSpec{Percent: 50, TotalReplicaCount: 10},
+			valid: true,
+		},
+		{
+			name: "percent over 100",
+			spec: shipper.CapacityTargetSpec{Percent: 101, TotalReplicaCount: 10},
+		},
+		{
+			name: "negative replica count",
+			spec: shipper.CapacityTargetSpec{Percent: 100, TotalReplicaCount: -1},
+		},
+		{
+			name: "workloads",
+			spec: shipper.CapacityTargetSpec{
+				Percent:           50,
+				TotalReplicaCount: 12,
+				Workloads: []shipper.CapacityTargetWorkload{
+					{Name: "web", TotalReplicaCount: 10},
+					{Name: "worker", TotalReplicaCount: 2},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "release",
+			spec: shipper.CapacityTargetSpec{
+				Application: "reviews-api",
+				Release:     "reviews-api-deadbeef-0",
+			},
+			valid: true,
+		},
+		{
+			name: "release without application",
+			spec: shipper.CapacityTargetSpec{Release: "reviews-api-deadbeef-0"},
+		},
+		{
+			name: "invalid release",
+			spec: shipper.CapacityTargetSpec{
+				Application: "reviews-api",
+				Release:     "reviews api",
+			},
+		},
+		{
+			name: "unnamed workload",
+			spec: shipper.CapacityTargetSpec{
+				Workloads: []shipper.CapacityTargetWorkload{{TotalReplicaCount: 1}},
+			},
+		},
+		{
+			name: "duplicate workloads",
+			spec: shipper.CapacityTargetSpec{
+				Workloads: []shipper.CapacityTargetWorkload{
+					{Name: "web", TotalReplicaCount: 1},
+					{Name: "web", TotalReplicaCount: 2},
+				},
+			},
+		},
+		{
+			name: "negative workload replica count",
+			spec: shipper.CapacityTargetSpec{
+				Workloads: []shipper.CapacityTargetWorkload{{Name: "web", TotalReplicaCount: -1}},
+			},
+		},
+		{
+			name: "duplicate clusters",
+			spec: shipper.CapacityTargetSpec{
+				Clusters: []shipper.ClusterCapacityTarget{
+					{Name: "a", Percent: 10},
+					{Name: "a", Percent: 20},
+				},
+			},
+		},
+		{
+			name: "cluster percent below 0",
+			spec: shipper.CapacityTargetSpec{
+				Clusters: []shipper.ClusterCapacityTarget{{Name: "a", Percent: -5}},
+			},
+		},
+		{
+			name: "unknown cluster",
+			spec: shipper.CapacityTargetSpec{
+				Clusters: []shipper.ClusterCapacityTarget{{Name: "unknown", Percent: 10}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCapacityTargetSpec(&tt.spec, isKnownCluster)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected no error, got %s", tt.name, err)
+		} else if !tt.valid {
+			if _, ok := err.(shippererrors.InvalidCapacityTargetSpecError); !ok {
+				t.Errorf("%s: expected an InvalidCapacityTargetSpecError, got %v", tt.name, err)
+			}
+		}
+	}
+
+	spec := shipper.CapacityTargetSpec{
+		Clusters: []shipper.ClusterCapacityTarget{{Name: "unknown", Percent: 10}},
+	}
+	if err := ValidateCapacityTargetSpec(&spec, nil); err != nil {
+		t.Errorf("expected clusters not to be checked without isKnownCluster, got %s", err)
+	}
+}
+
+func TestValidateCapacityTargetSpecUpdate(t *testing.T) {
+	release := shipper.CapacityTargetSpec{Application: "reviews-api", Release: "reviews-api-deadbeef-0"}
+
+	tests := []struct {
+		name    string
+		oldSpec shipper.CapacityTargetSpec
+		spec    shipper.CapacityTargetSpec
+		valid   bool
+	}{
+		{
+			name:    "release set on an older target",
+			oldSpec: shipper.CapacityTargetSpec{},
+			spec:    release,
+			valid:   true,
+		},
+		{
+			name:    "percent changed",
+			oldSpec: release,
+			spec: shipper.CapacityTargetSpec{
+				Application: release.Application,
+				Release:     release.Release,
+				Percent:     50,
+			},
+			valid: true,
+		},
+		{
+			name:    "release changed",
+			oldSpec: release,
+			spec:    shipper.CapacityTargetSpec{Application: "reviews-api", Release: "reviews-api-cafebabe-0"},
+		},
+		{
+			name:    "release removed",
+			oldSpec: release,
+			spec:    shipper.CapacityTargetSpec{},
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateCapacityTargetSpecUpdate(&tt.spec, &tt.oldSpec)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
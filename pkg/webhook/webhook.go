@@ -11,9 +11,12 @@ import (
 
 	admission "k8s.io/api/admission/v1beta1"
 	kubeclient "k8s.io/api/admission/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
@@ -21,7 +24,10 @@ import (
 	clientset "github.com/bookingcom/shipper/pkg/client/clientset/versioned"
 	informers "github.com/bookingcom/shipper/pkg/client/informers/externalversions"
 	listers "github.com/bookingcom/shipper/pkg/client/listers/shipper/v1alpha1"
+	shippererrors "github.com/bookingcom/shipper/pkg/errors"
+	releaseutil "github.com/bookingcom/shipper/pkg/util/release"
 	"github.com/bookingcom/shipper/pkg/util/rolloutblock"
+	targetutil "github.com/bookingcom/shipper/pkg/util/target"
 )
 
 const (
@@ -29,9 +35,18 @@ const (
 )
 
 type Webhook struct {
+	kubeClientset       kubernetes.Interface
 	shipperClientset    clientset.Interface
 	rolloutBlocksLister listers.RolloutBlockLister
 	rolloutBlocksSynced cache.InformerSynced
+	clusterLister       listers.ClusterLister
+	clustersSynced      cache.InformerSynced
+	applicationLister   listers.ApplicationLister
+	applicationsSynced  cache.InformerSynced
+	releaseLister       listers.ReleaseLister
+	releasesSynced      cache.InformerSynced
+	strategyLister      listers.StrategyLister
+	strategiesSynced    cache.InformerSynced
 
 	bindAddr string
 	bindPort string
@@ -48,15 +63,29 @@ var (
 
 func NewWebhook(
 	bindAddr, bindPort, tlsPrivateKeyFile, tlsCertFile string,
+	kubeClientset kubernetes.Interface,
 	shipperClientset clientset.Interface,
 	shipperInformerFactory informers.SharedInformerFactory,
 ) *Webhook {
 	rolloutBlocksInformer := shipperInformerFactory.Shipper().V1alpha1().RolloutBlocks()
+	clusterInformer := shipperInformerFactory.Shipper().V1alpha1().Clusters()
+	applicationInformer := shipperInformerFactory.Shipper().V1alpha1().Applications()
+	releaseInformer := shipperInformerFactory.Shipper().V1alpha1().Releases()
+	strategyInformer := shipperInformerFactory.Shipper().V1alpha1().Strategies()
 
 	return &Webhook{
+		kubeClientset:       kubeClientset,
 		shipperClientset:    shipperClientset,
 		rolloutBlocksLister: rolloutBlocksInformer.Lister(),
 		rolloutBlocksSynced: rolloutBlocksInformer.Informer().HasSynced,
+		clusterLister:       clusterInformer.Lister(),
+		clustersSynced:      clusterInformer.Informer().HasSynced,
+		applicationLister:   applicationInformer.Lister(),
+		applicationsSynced:  applicationInformer.Informer().HasSynced,
+		releaseLister:       releaseInformer.Lister(),
+		releasesSynced:      releaseInformer.Informer().HasSynced,
+		strategyLister:      strategyInformer.Lister(),
+		strategiesSynced:    strategyInformer.Informer().HasSynced,
 
 		bindAddr: bindAddr,
 		bindPort: bindPort,
@@ -74,7 +103,7 @@ func (c *Webhook) Run(stopCh <-chan struct{}) {
 		Handler: mux,
 	}
 
-	if !cache.WaitForCacheSync(stopCh, c.rolloutBlocksSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.rolloutBlocksSynced, c.clustersSynced, c.applicationsSynced, c.releasesSynced, c.strategiesSynced) {
 		klog.Fatalf("failed to wait for caches to sync")
 		return
 	}
@@ -172,6 +201,31 @@ func (c *Webhook) validateHandlerFunc(review *admission.AdmissionReview) *admiss
 	request := review.Request
 	var err error
 
+	if request.Operation == kubeclient.Delete {
+		if request.Kind.Kind == "Release" {
+			err = c.validateReleaseDeletion(request)
+		}
+	} else {
+		err = c.validateObject(request)
+	}
+
+	if err != nil {
+		return &admission.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return &admission.AdmissionResponse{
+		Allowed: true,
+	}
+}
+
+// validateObject validates an object being created or updated.
+func (c *Webhook) validateObject(request *admission.AdmissionRequest) error {
+	var err error
+
 	switch request.Kind.Kind {
 	case "Application":
 		var application shipper.Application
@@ -194,25 +248,39 @@ func (c *Webhook) validateHandlerFunc(review *admission.AdmissionReview) *admiss
 	case "CapacityTarget":
 		var capacityTarget shipper.CapacityTarget
 		err = json.Unmarshal(request.Object.Raw, &capacityTarget)
+		if err == nil {
+			err = c.validateCapacityTarget(request, capacityTarget)
+		}
 	case "TrafficTarget":
 		var trafficTarget shipper.TrafficTarget
 		err = json.Unmarshal(request.Object.Raw, &trafficTarget)
 	case "RolloutBlock":
 		var rolloutBlock shipper.RolloutBlock
 		err = json.Unmarshal(request.Object.Raw, &rolloutBlock)
-	}
-
-	if err != nil {
-		return &admission.AdmissionResponse{
-			Result: &metav1.Status{
-				Message: err.Error(),
-			},
+	case "ApplicationTemplate":
+		var tmpl, oldTmpl shipper.ApplicationTemplate
+		err = unmarshalObjects(request, &tmpl, &oldTmpl)
+		if err == nil {
+			err = c.validateStrategyAccess(request, tmpl.Spec.Template.Strategy, oldTmpl.Spec.Template.Strategy)
+		}
+	case "ApplicationDefault":
+		var defaults, oldDefaults shipper.ApplicationDefault
+		err = unmarshalObjects(request, &defaults, &oldDefaults)
+		if err == nil {
+			err = c.validateStrategyAccess(request, defaults.Spec.Strategy, oldDefaults.Spec.Strategy)
+		}
+	case "Strategy":
+		var strategy shipper.Strategy
+		err = json.Unmarshal(request.Object.Raw, &strategy)
+		if err == nil && strategy.Spec.StrategyRef != nil {
+			err = fmt.Errorf("strategies can not refer to other strategies")
+		}
+		if err == nil {
+			err = validateStrategy(&strategy.Spec, nil)
 		}
 	}
 
-	return &admission.AdmissionResponse{
-		Allowed: true,
-	}
+	return err
 }
 
 func (c *Webhook) validateRelease(request *admission.AdmissionRequest, release shipper.Release) error {
@@ -224,9 +292,42 @@ func (c *Webhook) validateRelease(request *admission.AdmissionRequest, release s
 	if err = rolloutblock.ValidateAnnotations(existingBlocks, overrides); err != nil {
 		return err
 	}
+	if err = c.validateReleaseClusters(release); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateClusterReplicas(&release); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateValuesOverlays(&release.Spec.Environment); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateValuesFrom(&release.Spec.Environment); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateAdditionalCharts(&release.Spec.Environment); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateDependsOn(&release.Spec.Environment, release.Labels[shipper.AppLabel]); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateReadinessBarriers(&release.Spec.Environment); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateWorkloadKind(&release.Spec.Environment); err != nil {
+		return err
+	}
 	switch request.Operation {
 	case kubeclient.Create:
 		err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+		if err == nil {
+			err = releaseutil.ValidateIdentityLabels(&release, nil)
+		}
+		if err == nil {
+			err = validateStrategy(release.Spec.Environment.Strategy, nil)
+		}
+		if err == nil {
+			err = validateApprovals(request, release, nil)
+		}
 	case kubeclient.Update:
 		var oldRelease shipper.Release
 		err = json.Unmarshal(request.OldObject.Raw, &oldRelease)
@@ -237,11 +338,249 @@ func (c *Webhook) validateRelease(request *admission.AdmissionRequest, release s
 		if !reflect.DeepEqual(release.Spec, oldRelease.Spec) {
 			err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
 		}
+		if err == nil {
+			err = releaseutil.ValidateEnvironmentUpdate(&release, &oldRelease)
+		}
+		if err == nil {
+			err = releaseutil.ValidateIdentityLabels(&release, &oldRelease)
+		}
+		if err == nil {
+			err = validateStrategy(release.Spec.Environment.Strategy, oldRelease.Spec.Environment.Strategy)
+		}
+		if err == nil {
+			err = validateApprovals(request, release, oldRelease.Spec.Approvals)
+		}
 	}
 
 	return err
 }
 
+// validateReleaseDeletion ensures that a contender is not deleted, by
+// accident, in the middle of its rollout. Releases of applications that are
+// gone, or being deleted, are left to the garbage collector.
+func (c *Webhook) validateReleaseDeletion(request *admission.AdmissionRequest) error {
+	var release *shipper.Release
+	if len(request.OldObject.Raw) > 0 {
+		release = &shipper.Release{}
+		if err := json.Unmarshal(request.OldObject.Raw, release); err != nil {
+			return err
+		}
+	} else {
+		// API servers older than 1.15 don't send the object being
+		// deleted.
+		var err error
+		release, err = c.releaseLister.Releases(request.Namespace).Get(request.Name)
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	appName, ok := release.Labels[shipper.AppLabel]
+	if !ok {
+		return nil
+	}
+
+	app, err := c.applicationLister.Applications(release.Namespace).Get(appName)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if app.DeletionTimestamp != nil {
+		return nil
+	}
+
+	contender, err := c.releaseLister.Releases(release.Namespace).ContenderForApplication(appName)
+	if shippererrors.IsContenderNotFoundError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return releaseutil.ValidateDeletion(release, contender)
+}
+
+// validateReleaseClusters ensures that a release is not scheduled, by hand,
+// on clusters that don't accept releases from its namespace.
+func (c *Webhook) validateReleaseClusters(release shipper.Release) error {
+	for _, clusterName := range releaseutil.GetSelectedClusters(&release) {
+		cluster, err := c.clusterLister.Get(clusterName)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !releaseutil.ClusterAllowsNamespace(cluster, release.Namespace) {
+			return fmt.Errorf("cluster %q does not accept releases from namespace %q", clusterName, release.Namespace)
+		}
+	}
+
+	return nil
+}
+
+// validateCapacityTarget ensures that a capacity target's spec makes sense,
+// only refers to clusters that exist, and keeps selecting the Deployments of
+// the same release.
+func (c *Webhook) validateCapacityTarget(request *admission.AdmissionRequest, ct shipper.CapacityTarget) error {
+	err := targetutil.ValidateCapacityTargetSpec(&ct.Spec, func(name string) (bool, error) {
+		_, err := c.clusterLister.Get(name)
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+	if err != nil || request.Operation != kubeclient.Update {
+		return err
+	}
+
+	var oldCT shipper.CapacityTarget
+	if err := json.Unmarshal(request.OldObject.Raw, &oldCT); err != nil {
+		return err
+	}
+
+	return targetutil.ValidateCapacityTargetSpecUpdate(&ct.Spec, &oldCT.Spec)
+}
+
+// validateStrategy ensures that a strategy makes sense. Objects created
+// before strategies were validated are only checked when their strategy
+// changes, so Shipper can keep updating them.
+func validateStrategy(strategy, oldStrategy *shipper.RolloutStrategy) error {
+	if strategy == nil || reflect.DeepEqual(strategy, oldStrategy) {
+		return nil
+	}
+
+	strategy = strategy.DeepCopy()
+	if err := releaseutil.ResolveStrategyPreset(strategy); err != nil {
+		return err
+	}
+
+	return releaseutil.ValidateStrategy(strategy)
+}
+
+// validateApplicationStrategy ensures that an application's strategy makes
+// sense. The ones referring to a Strategy get the rest of it from there when
+// releases are created.
+func validateApplicationStrategy(strategy, oldStrategy *shipper.RolloutStrategy) error {
+	if strategy == nil || strategy.StrategyRef == nil {
+		return validateStrategy(strategy, oldStrategy)
+	}
+
+	if reflect.DeepEqual(strategy, oldStrategy) {
+		return nil
+	}
+
+	return releaseutil.ValidateStrategyRef(strategy)
+}
+
+// validateStrategyAccess ensures that whoever makes a strategy refer to a
+// Strategy of the global strategy namespace is allowed to "use" it there.
+// Strategies of the object's own namespace are only guarded by the RBAC
+// rules of the namespace.
+func (c *Webhook) validateStrategyAccess(request *admission.AdmissionRequest, strategy, oldStrategy *shipper.RolloutStrategy) error {
+	if strategy == nil || strategy.StrategyRef == nil ||
+		request.Namespace == shipper.GlobalStrategyNamespace {
+		return nil
+	}
+
+	name := strategy.StrategyRef.Name
+	if oldStrategy != nil && oldStrategy.StrategyRef != nil && oldStrategy.StrategyRef.Name == name {
+		return nil
+	}
+
+	_, err := c.strategyLister.Strategies(request.Namespace).Get(name)
+	if err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(request.UserInfo.Extra))
+	for k, v := range request.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: shipper.GlobalStrategyNamespace,
+				Verb:      "use",
+				Group:     shipper.SchemeGroupVersion.Group,
+				Resource:  "strategies",
+				Name:      name,
+			},
+			User:   request.UserInfo.Username,
+			Groups: request.UserInfo.Groups,
+			UID:    request.UserInfo.UID,
+			Extra:  extra,
+		},
+	}
+
+	review, err = c.kubeClientset.AuthorizationV1().SubjectAccessReviews().Create(review)
+	if err != nil {
+		return err
+	}
+
+	if !review.Status.Allowed {
+		return fmt.Errorf("%q is not allowed to use strategy %q of namespace %q",
+			request.UserInfo.Username, name, shipper.GlobalStrategyNamespace)
+	}
+
+	return nil
+}
+
+// unmarshalObjects unmarshals the object of request into obj, and the one
+// it replaces into oldObj for updates.
+func unmarshalObjects(request *admission.AdmissionRequest, obj, oldObj interface{}) error {
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		return err
+	}
+
+	if request.Operation != kubeclient.Update {
+		return nil
+	}
+
+	return json.Unmarshal(request.OldObject.Raw, oldObj)
+}
+
+// validateApprovals ensures that existing approvals are never changed, and
+// that new ones are made by the requesting user on their own behalf, for a
+// step they are allowed to approve.
+func validateApprovals(request *admission.AdmissionRequest, release shipper.Release, oldApprovals []shipper.ReleaseApproval) error {
+	approvals := release.Spec.Approvals
+	if len(approvals) < len(oldApprovals) ||
+		!reflect.DeepEqual(approvals[:len(oldApprovals)], oldApprovals) {
+		return fmt.Errorf("existing approvals can not be modified or removed")
+	}
+
+	strategy := release.Spec.Environment.Strategy
+	user := request.UserInfo.Username
+	for _, approval := range approvals[len(oldApprovals):] {
+		if strategy == nil || approval.Step < 0 || int(approval.Step) >= len(strategy.Steps) {
+			return fmt.Errorf("can not approve step %d: no such step", approval.Step)
+		}
+
+		step := strategy.Steps[approval.Step]
+		if !step.ApprovalRequired {
+			return fmt.Errorf("can not approve step %d: it does not require approval", approval.Step)
+		}
+
+		if approval.Approver != user {
+			return fmt.Errorf("can not approve step %d on behalf of %q", approval.Step, approval.Approver)
+		}
+
+		if !releaseutil.CanApproveStep(step, user, request.UserInfo.Groups) {
+			return fmt.Errorf("%q is not allowed to approve step %d", user, approval.Step)
+		}
+	}
+
+	return nil
+}
+
 func (c *Webhook) validateApplication(request *admission.AdmissionRequest, application shipper.Application) error {
 	var err error
 	overrides, existingBlocks, err := rolloutblock.GetAllBlocks(c.rolloutBlocksLister, &application)
@@ -251,9 +590,33 @@ func (c *Webhook) validateApplication(request *admission.AdmissionRequest, appli
 	if err = rolloutblock.ValidateAnnotations(existingBlocks, overrides); err != nil {
 		return err
 	}
+	if err = releaseutil.ValidateValuesOverlays(&application.Spec.Template); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateValuesFrom(&application.Spec.Template); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateAdditionalCharts(&application.Spec.Template); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateDependsOn(&application.Spec.Template, application.Name); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateReadinessBarriers(&application.Spec.Template); err != nil {
+		return err
+	}
+	if err = releaseutil.ValidateWorkloadKind(&application.Spec.Template); err != nil {
+		return err
+	}
 	switch request.Operation {
 	case kubeclient.Create:
 		err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
+		if err == nil {
+			err = validateApplicationStrategy(application.Spec.Template.Strategy, nil)
+		}
+		if err == nil {
+			err = c.validateStrategyAccess(request, application.Spec.Template.Strategy, nil)
+		}
 	case kubeclient.Update:
 		var oldApp shipper.Application
 		err = json.Unmarshal(request.OldObject.Raw, &oldApp)
@@ -264,6 +627,12 @@ func (c *Webhook) validateApplication(request *admission.AdmissionRequest, appli
 		if !reflect.DeepEqual(application.Spec, oldApp.Spec) {
 			err = rolloutblock.ValidateBlocks(existingBlocks, overrides)
 		}
+		if err == nil {
+			err = validateApplicationStrategy(application.Spec.Template.Strategy, oldApp.Spec.Template.Strategy)
+		}
+		if err == nil {
+			err = c.validateStrategyAccess(request, application.Spec.Template.Strategy, oldApp.Spec.Template.Strategy)
+		}
 	}
 
 	return err
@@ -0,0 +1,166 @@
+package workqueue
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// ItemQueued is an item waiting for a worker to pick it up.
+	ItemQueued = "Queued"
+	// ItemDelayed is an item that will be queued once its delay, be it
+	// from rate limiting or an explicit AddAfter, runs out.
+	ItemDelayed = "Delayed"
+	// ItemProcessing is an item a worker is syncing right now.
+	ItemProcessing = "Processing"
+)
+
+// Item describes an item in a queue, as seen by Snapshot.
+type Item struct {
+	Key string `json:"key"`
+	// State is one of ItemQueued, ItemDelayed or ItemProcessing.
+	State string `json:"state"`
+	// Requeues is how many times in a row the item has been put back in
+	// the queue because its sync failed.
+	Requeues int `json:"requeues"`
+}
+
+var (
+	queuesMut sync.Mutex
+	queues    = map[string]*inspectableQueue{}
+)
+
+// NewNamedRateLimitingQueue works like its client-go counterpart, but the
+// queue it returns keeps track of its items so they can be listed with
+// Snapshot. A queue replaces any other queue with the same name.
+func NewNamedRateLimitingQueue(rateLimiter workqueue.RateLimiter, name string) workqueue.RateLimitingInterface {
+	q := &inspectableQueue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(rateLimiter, name),
+		items:                 map[interface{}]*itemState{},
+	}
+
+	queuesMut.Lock()
+	queues[name] = q
+	queuesMut.Unlock()
+
+	return q
+}
+
+// Snapshot lists the items in every queue created with
+// NewNamedRateLimitingQueue, keyed by queue name.
+func Snapshot() map[string][]Item {
+	queuesMut.Lock()
+	defer queuesMut.Unlock()
+
+	snapshot := make(map[string][]Item, len(queues))
+	for name, q := range queues {
+		snapshot[name] = q.snapshot()
+	}
+
+	return snapshot
+}
+
+type itemState struct {
+	state string
+	// Set when an item is added again while it's being processed, which
+	// client-go queues hold back until the worker is done with it.
+	requeued string
+}
+
+// inspectableQueue is a RateLimitingInterface that keeps track of the state
+// of its items. Delayed items are only known to be queued once a worker
+// picks them up, since client-go adds them to the queue internally.
+type inspectableQueue struct {
+	workqueue.RateLimitingInterface
+
+	mut   sync.Mutex
+	items map[interface{}]*itemState
+}
+
+func (q *inspectableQueue) track(item interface{}, state string) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	current, ok := q.items[item]
+	switch {
+	case !ok:
+		q.items[item] = &itemState{state: state}
+	case current.state == ItemProcessing:
+		if current.requeued != ItemQueued {
+			current.requeued = state
+		}
+	case current.state == ItemDelayed:
+		current.state = state
+	}
+}
+
+func (q *inspectableQueue) Add(item interface{}) {
+	q.track(item, ItemQueued)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *inspectableQueue) AddAfter(item interface{}, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	q.track(item, ItemDelayed)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *inspectableQueue) AddRateLimited(item interface{}) {
+	q.track(item, ItemDelayed)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+func (q *inspectableQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if shutdown {
+		return item, shutdown
+	}
+
+	q.mut.Lock()
+	q.items[item] = &itemState{state: ItemProcessing}
+	q.mut.Unlock()
+
+	return item, shutdown
+}
+
+func (q *inspectableQueue) Done(item interface{}) {
+	q.mut.Lock()
+	if current, ok := q.items[item]; ok && current.state == ItemProcessing {
+		if current.requeued != "" {
+			q.items[item] = &itemState{state: current.requeued}
+		} else {
+			delete(q.items, item)
+		}
+	}
+	q.mut.Unlock()
+
+	q.RateLimitingInterface.Done(item)
+}
+
+func (q *inspectableQueue) snapshot() []Item {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	items := make([]Item, 0, len(q.items))
+	for item, state := range q.items {
+		items = append(items, Item{
+			Key:      fmt.Sprintf("%v", item),
+			State:    state.state,
+			Requeues: q.NumRequeues(item),
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	return items
+}
@@ -0,0 +1,52 @@
+package workqueue
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestInspectableQueue(t *testing.T) {
+	queue := NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test_inspectable_queue")
+	defer queue.ShutDown()
+
+	queue.Add("ns/processing")
+	queue.Add("ns/queued")
+	queue.AddAfter("ns/delayed", time.Hour)
+
+	item, _ := queue.Get()
+	if item != "ns/processing" {
+		t.Fatalf("expected to get %q, got %q", "ns/processing", item)
+	}
+	queue.AddRateLimited(item)
+
+	expected := []Item{
+		{Key: "ns/delayed", State: ItemDelayed},
+		{Key: "ns/processing", State: ItemProcessing, Requeues: 1},
+		{Key: "ns/queued", State: ItemQueued},
+	}
+	if got := Snapshot()["test_inspectable_queue"]; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	// The item was put back while being processed, so it's waiting for
+	// its rate limit once done.
+	queue.Done(item)
+	expected[1].State = ItemDelayed
+	if got := Snapshot()["test_inspectable_queue"]; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	item, _ = queue.Get()
+	queue.Forget(item)
+	queue.Done(item)
+	expected = []Item{
+		{Key: "ns/delayed", State: ItemDelayed},
+		{Key: "ns/processing", State: ItemDelayed, Requeues: 1},
+	}
+	if got := Snapshot()["test_inspectable_queue"]; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,195 @@
+package workqueue
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// DefaultLowPriorityMaxWait is how long low priority items are held back at
+// most while there are high priority ones to process, unless configured
+// otherwise.
+const DefaultLowPriorityMaxWait = time.Minute
+
+type lowPriorityItem struct {
+	item  interface{}
+	added time.Time
+}
+
+type delayedItem struct {
+	readyAt time.Time
+	timer   *time.Timer
+}
+
+// priorityQueue is a RateLimitingInterface with two tiers. High priority
+// items go straight into the queue it wraps, while low priority ones wait on
+// the side until a worker would otherwise be idle, or until they've waited
+// for maxWait. Every item still goes through the wrapped queue
+// before it's processed, so an item is never processed by two workers at
+// once, whichever tier it was added to.
+type priorityQueue struct {
+	workqueue.RateLimitingInterface
+	isHighPriority func(key string) bool
+	maxWait        time.Duration
+
+	mut sync.Mutex
+	// low is in the order items were added. Items promoted to high
+	// priority since are left in it, and skipped once they're at its head.
+	low      []lowPriorityItem
+	lowAdded map[interface{}]time.Time
+	delayed  map[interface{}]*delayedItem
+	// getting is how many workers are waiting for an item.
+	getting int
+}
+
+// NewPriorityQueue wraps queue so that keys isHighPriority returns true for
+// are processed before the ones it returns false for. Priority is decided
+// when a key is added, or when its delay runs out if it's added with
+// AddAfter. Low priority keys are held back for maxWait at most. Keys added
+// with AddRateLimited are retries, and are not held back.
+func NewPriorityQueue(queue workqueue.RateLimitingInterface, isHighPriority func(key string) bool, maxWait time.Duration) workqueue.RateLimitingInterface {
+	return &priorityQueue{
+		RateLimitingInterface: queue,
+		isHighPriority:        isHighPriority,
+		maxWait:               maxWait,
+		lowAdded:              map[interface{}]time.Time{},
+		delayed:               map[interface{}]*delayedItem{},
+	}
+}
+
+func (q *priorityQueue) highPriority(item interface{}) bool {
+	key, ok := item.(string)
+	return !ok || q.isHighPriority(key)
+}
+
+func (q *priorityQueue) Add(item interface{}) {
+	// Priority functions look objects up in listers, so they're kept out
+	// of the lock.
+	high := q.highPriority(item)
+
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if q.ShuttingDown() {
+		return
+	}
+
+	if high {
+		delete(q.lowAdded, item)
+		q.RateLimitingInterface.Add(item)
+		return
+	}
+
+	if _, ok := q.lowAdded[item]; !ok {
+		now := time.Now()
+		q.low = append(q.low, lowPriorityItem{item: item, added: now})
+		q.lowAdded[item] = now
+		q.track(item, ItemQueued)
+	}
+
+	q.admit()
+}
+
+func (q *priorityQueue) AddAfter(item interface{}, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if q.ShuttingDown() {
+		return
+	}
+
+	// Just like client-go's delaying queues, an item waits for the
+	// shortest of its delays.
+	readyAt := time.Now().Add(duration)
+	if current, ok := q.delayed[item]; ok {
+		if !current.readyAt.After(readyAt) {
+			return
+		}
+		current.timer.Stop()
+	}
+
+	delayed := &delayedItem{readyAt: readyAt}
+	delayed.timer = time.AfterFunc(duration, func() {
+		q.mut.Lock()
+		if q.delayed[item] == delayed {
+			delete(q.delayed, item)
+		}
+		q.mut.Unlock()
+
+		q.Add(item)
+	})
+	q.delayed[item] = delayed
+	q.track(item, ItemDelayed)
+}
+
+func (q *priorityQueue) Get() (interface{}, bool) {
+	q.mut.Lock()
+	q.getting++
+	q.admit()
+	q.mut.Unlock()
+
+	item, shutdown := q.RateLimitingInterface.Get()
+
+	q.mut.Lock()
+	q.getting--
+	q.mut.Unlock()
+
+	return item, shutdown
+}
+
+func (q *priorityQueue) Len() int {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	return q.RateLimitingInterface.Len() + len(q.lowAdded)
+}
+
+func (q *priorityQueue) ShutDown() {
+	q.mut.Lock()
+	for _, delayed := range q.delayed {
+		delayed.timer.Stop()
+	}
+	q.delayed = map[interface{}]*delayedItem{}
+	q.low = nil
+	q.lowAdded = map[interface{}]time.Time{}
+	q.mut.Unlock()
+
+	q.RateLimitingInterface.ShutDown()
+}
+
+// admit moves low priority items into the wrapped queue for as long as there
+// are more workers waiting than items for them, and moves the ones that
+// waited long enough regardless. It must be called with q.mut held.
+func (q *priorityQueue) admit() {
+	now := time.Now()
+	for len(q.low) > 0 {
+		head := q.low[0]
+		if added, ok := q.lowAdded[head.item]; !ok || !added.Equal(head.added) {
+			q.low = q.low[1:]
+			continue
+		}
+
+		idle := q.getting > q.RateLimitingInterface.Len()
+		if !idle && now.Sub(head.added) < q.maxWait {
+			return
+		}
+
+		q.low = q.low[1:]
+		delete(q.lowAdded, head.item)
+		q.RateLimitingInterface.Add(head.item)
+	}
+}
+
+// track lets the wrapped queue list items it doesn't know about yet in
+// Snapshot, if it keeps track of them at all.
+func (q *priorityQueue) track(item interface{}, state string) {
+	if inspectable, ok := q.RateLimitingInterface.(*inspectableQueue); ok {
+		inspectable.track(item, state)
+	}
+}
@@ -0,0 +1,117 @@
+package workqueue
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func newTestPriorityQueue(maxWait time.Duration, high ...string) workqueue.RateLimitingInterface {
+	isHigh := map[string]bool{}
+	for _, key := range high {
+		isHigh[key] = true
+	}
+
+	return NewPriorityQueue(
+		workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		func(key string) bool { return isHigh[key] },
+		maxWait,
+	)
+}
+
+func expectItems(t *testing.T, queue workqueue.Interface, expected ...string) {
+	for _, key := range expected {
+		item, _ := queue.Get()
+		if item != key {
+			t.Fatalf("expected %q, got %q", key, item)
+		}
+		queue.Done(item)
+	}
+
+	if queue.Len() != 0 {
+		t.Fatalf("expected queue to be empty, got %d items", queue.Len())
+	}
+}
+
+func TestPriorityQueueHighPriorityFirst(t *testing.T) {
+	queue := newTestPriorityQueue(DefaultLowPriorityMaxWait, "ns/in-flight", "ns/other-in-flight")
+	defer queue.ShutDown()
+
+	queue.Add("ns/steady")
+	queue.Add("ns/in-flight")
+	queue.Add("ns/other-steady")
+	queue.Add("ns/other-in-flight")
+
+	if queue.Len() != 4 {
+		t.Fatalf("expected 4 items in queue, got %d", queue.Len())
+	}
+
+	expectItems(t, queue, "ns/in-flight", "ns/other-in-flight", "ns/steady", "ns/other-steady")
+}
+
+func TestPriorityQueueLowPriorityWhenIdle(t *testing.T) {
+	queue := newTestPriorityQueue(DefaultLowPriorityMaxWait)
+	defer queue.ShutDown()
+
+	got := make(chan interface{})
+	go func() {
+		item, _ := queue.Get()
+		got <- item
+	}()
+
+	// Give the worker a chance to start waiting before anything is
+	// queued.
+	time.Sleep(10 * time.Millisecond)
+	queue.Add("ns/steady")
+
+	select {
+	case item := <-got:
+		if item != "ns/steady" {
+			t.Fatalf("expected %q, got %q", "ns/steady", item)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle worker did not get low priority item")
+	}
+}
+
+func TestPriorityQueueLowPriorityMaxWait(t *testing.T) {
+	queue := newTestPriorityQueue(0, "ns/in-flight")
+	defer queue.ShutDown()
+
+	queue.Add("ns/steady")
+	queue.Add("ns/in-flight")
+
+	expectItems(t, queue, "ns/steady", "ns/in-flight")
+}
+
+func TestPriorityQueuePromotion(t *testing.T) {
+	isHigh := map[string]bool{}
+	queue := NewPriorityQueue(
+		workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		func(key string) bool { return isHigh[key] },
+		DefaultLowPriorityMaxWait,
+	)
+	defer queue.ShutDown()
+
+	queue.Add("ns/first")
+	queue.Add("ns/second")
+
+	isHigh["ns/second"] = true
+	queue.Add("ns/second")
+
+	expectItems(t, queue, "ns/second", "ns/first")
+}
+
+func TestPriorityQueueAddAfter(t *testing.T) {
+	queue := newTestPriorityQueue(DefaultLowPriorityMaxWait, "ns/in-flight")
+	defer queue.ShutDown()
+
+	queue.AddAfter("ns/steady", 10*time.Millisecond)
+	queue.AddAfter("ns/in-flight", 10*time.Millisecond)
+	queue.AddAfter("ns/in-flight", time.Hour)
+
+	time.Sleep(50 * time.Millisecond)
+
+	expectItems(t, queue, "ns/in-flight", "ns/steady")
+}